@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"wox/ai"
@@ -112,33 +111,8 @@ func readFileCallback(ctx context.Context, args map[string]any) (common.ToolResu
 }
 
 // resolveToolPath resolves a path relative to the process working directory.
-// It expands ~ to the home directory and handles absolute paths directly.
-// Shared by all file-based tools in this package.
+// Shared by all file-based tools in this package; the approval allowlist in
+// package ai resolves paths the same way.
 func resolveToolPath(input string) string {
-	input = strings.TrimSpace(input)
-	if input == "" {
-		input = "."
-	}
-
-	if strings.HasPrefix(input, "~") {
-		home, err := os.UserHomeDir()
-		if err == nil {
-			if input == "~" {
-				return home
-			}
-			if strings.HasPrefix(input, "~/") {
-				return filepath.Join(home, input[2:])
-			}
-		}
-	}
-
-	if filepath.IsAbs(input) {
-		return filepath.Clean(input)
-	}
-
-	cwd, err := os.Getwd()
-	if err != nil {
-		return filepath.Clean(input)
-	}
-	return filepath.Clean(filepath.Join(cwd, input))
-}
\ No newline at end of file
+	return ai.ResolveToolPath(input)
+}
@@ -60,24 +60,32 @@ func askUserCallback(ctx context.Context, args map[string]any) (common.ToolResul
 		return common.ToolResult{}, fmt.Errorf("question is required")
 	}
 
-	options := parseAIQuestionOptions(args["options"])
+	answer, err := AskUser(ctx, question, parseAIQuestionOptions(args["options"]))
+	if err != nil {
+		return common.ToolResult{}, err
+	}
+	return common.ToolResult{Text: answer}, nil
+}
+
+// AskUser shows a question card in the chat UI and blocks until the user
+// answers or ctx is done. Tool approval uses it for the approve/deny card.
+func AskUser(ctx context.Context, question string, options []common.AIQuestionOption) (string, error) {
+	if SendAIQuestionHook == nil {
+		return "", fmt.Errorf("ask_user is not available: UI hook not configured")
+	}
 
 	questionId := uuid.NewString()
 	responseCh := make(chan string, 1)
 	pendingQuestions.Store(questionId, responseCh)
 	defer pendingQuestions.Delete(questionId)
 
-	if SendAIQuestionHook != nil {
-		SendAIQuestionHook(ctx, questionId, question, options)
-	} else {
-		return common.ToolResult{}, fmt.Errorf("ask_user is not available: UI hook not configured")
-	}
+	SendAIQuestionHook(ctx, questionId, question, options)
 
 	select {
 	case answer := <-responseCh:
-		return common.ToolResult{Text: answer}, nil
+		return answer, nil
 	case <-ctx.Done():
-		return common.ToolResult{}, fmt.Errorf("ask_user cancelled: %w", ctx.Err())
+		return "", fmt.Errorf("ask_user cancelled: %w", ctx.Err())
 	}
 }
 
@@ -2,8 +2,15 @@ package ai
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 	"wox/common"
 	"wox/util"
@@ -11,15 +18,67 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/samber/lo"
 	"github.com/tmc/langchaingo/jsonschema"
 )
 
-var mcpSessions = util.NewHashMap[string, *mcp.ClientSession]()
+const mcpOperationTimeout = 30 * time.Second
+
+// mcpResourceMaxChars keeps one attached resource from filling the context window.
+const mcpResourceMaxChars = 64 * 1024
+
+// mcpConnection is an open session together with the transport settings it was
+// opened with, so a settings change reconnects instead of reusing a stale session.
+type mcpConnection struct {
+	key     string
+	session *mcp.ClientSession
+	cancel  context.CancelFunc
+}
+
+var mcpConnections = util.NewHashMap[string, *mcpConnection]()
+
+// mcpConnectionsMutex guards short updates of mcpConnections. Connecting can
+// take up to mcpOperationTimeout, so it is serialized per server by
+// mcpConnectLocks instead, and a slow server does not block the others.
+var mcpConnectionsMutex sync.Mutex
+var mcpConnectLocks = util.NewHashMap[string, *sync.Mutex]()
 var mcpTools = util.NewHashMap[string, []common.MCPTool]()
+var mcpHealth = util.NewHashMap[string, common.MCPServerHealth]()
+
+// mcpConnectionKey covers the fields that change how Wox connects. Name,
+// Disabled and ApprovalPolicy are left out so editing them keeps the session.
+func mcpConnectionKey(config common.AIChatMCPServerConfig) string {
+	return strings.Join([]string{
+		string(config.Type),
+		config.Command,
+		strings.Join(config.EnvironmentVariables, "\n"),
+		config.Url,
+		strings.Join(config.Headers, "\n"),
+		config.BearerToken,
+	}, "\x00")
+}
 
 func getMCPSession(ctx context.Context, config common.AIChatMCPServerConfig) (*mcp.ClientSession, error) {
-	if session, ok := mcpSessions.Load(config.Name); ok {
-		return session, nil
+	connectLock, _ := mcpConnectLocks.LoadOrStore(config.Name, &sync.Mutex{})
+	connectLock.Lock()
+	defer connectLock.Unlock()
+
+	key := mcpConnectionKey(config)
+	mcpConnectionsMutex.Lock()
+	if connection, ok := mcpConnections.Load(config.Name); ok {
+		if connection.key == key {
+			mcpConnectionsMutex.Unlock()
+			return connection.session, nil
+		}
+		util.GetLogger().Info(ctx, fmt.Sprintf("MCP server %s settings changed, reconnecting", config.Name))
+		closeMCPConnectionLocked(config.Name, connection)
+	}
+	mcpConnectionsMutex.Unlock()
+
+	transport, err := newMCPTransport(config)
+	if err != nil {
+		setMCPServerError(config.Name, err)
+		return nil, err
 	}
 
 	client := mcp.NewClient(&mcp.Implementation{
@@ -27,90 +86,422 @@ func getMCPSession(ctx context.Context, config common.AIChatMCPServerConfig) (*m
 		Version: "2.0.0",
 	}, nil)
 
-	var transport mcp.Transport
-	if config.Type == common.AIChatMCPServerTypeSTDIO {
+	// Bug fix: HTTP transports tie the connection to the context passed to
+	// Connect, so connecting with the caller's timeout context killed the
+	// session as soon as the first call returned. The session now gets its own
+	// lifetime, and the timer only bounds the handshake.
+	lifetimeCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	connectTimer := time.AfterFunc(mcpOperationTimeout, cancel)
+	session, err := client.Connect(lifetimeCtx, transport, nil)
+	if !connectTimer.Stop() && err == nil {
+		_ = session.Close()
+		err = fmt.Errorf("timeout after %d seconds connecting to server: %s", int(mcpOperationTimeout.Seconds()), config.Name)
+	}
+	if err != nil {
+		cancel()
+		setMCPServerError(config.Name, err)
+		return nil, err
+	}
+
+	connection := &mcpConnection{key: key, session: session, cancel: cancel}
+	mcpConnectionsMutex.Lock()
+	mcpConnections.Store(config.Name, connection)
+	mcpConnectionsMutex.Unlock()
+	updateMCPServerHealth(config.Name, func(health *common.MCPServerHealth) {
+		health.State = common.MCPServerStateConnected
+		health.Error = ""
+	})
+
+	serverName := config.Name
+	util.Go(ctx, "watch MCP session "+serverName, func() {
+		waitErr := session.Wait()
+		mcpConnectionsMutex.Lock()
+		defer mcpConnectionsMutex.Unlock()
+		// Sessions closed by Wox are removed from the map before closing.
+		if current, ok := mcpConnections.Load(serverName); !ok || current != connection {
+			return
+		}
+		closeMCPConnectionLocked(serverName, connection)
+		if waitErr == nil {
+			waitErr = fmt.Errorf("connection closed by server")
+		}
+		util.GetLogger().Warn(context.Background(), fmt.Sprintf("MCP server %s disconnected: %s", serverName, waitErr.Error()))
+		setMCPServerError(serverName, waitErr)
+	})
+
+	return session, nil
+}
+
+func newMCPTransport(config common.AIChatMCPServerConfig) (mcp.Transport, error) {
+	switch config.Type {
+	case common.AIChatMCPServerTypeSTDIO:
 		command, args := parseCommandArgs(config.Command)
 		cmd := shell.BuildCommand(command, nil, args...)
 		// Set environment variables (each entry is already in "key=value" format)
 		cmd.Env = append(cmd.Env, config.EnvironmentVariables...)
-		transport = &mcp.CommandTransport{Command: cmd}
-	}
-	if config.Type == common.AIChatMCPServerTypeStreamableHTTP {
-		transport = &mcp.StreamableClientTransport{Endpoint: config.Url}
-	}
-	if transport == nil {
-		return nil, fmt.Errorf("unsupported MCP server type: %s", config.Type)
+		return &mcp.CommandTransport{Command: cmd}, nil
+	case common.AIChatMCPServerTypeStreamableHTTP:
+		httpClient, err := newMCPHTTPClient(config)
+		if err != nil {
+			return nil, err
+		}
+		return &mcp.StreamableClientTransport{Endpoint: config.Url, HTTPClient: httpClient}, nil
+	case common.AIChatMCPServerTypeSSE:
+		httpClient, err := newMCPHTTPClient(config)
+		if err != nil {
+			return nil, err
+		}
+		return &mcp.SSEClientTransport{Endpoint: config.Url, HTTPClient: httpClient}, nil
 	}
+	return nil, fmt.Errorf("unsupported MCP server type: %s", config.Type)
+}
 
-	// Connect to the server (handles initialization automatically)
-	session, err := client.Connect(ctx, transport, nil)
+// newMCPHTTPClient adds the configured headers and bearer token to every request.
+// It returns nil when there is nothing to add, so the SDK uses its default client.
+func newMCPHTTPClient(config common.AIChatMCPServerConfig) (*http.Client, error) {
+	headers, err := parseMCPHeaders(config.Headers)
 	if err != nil {
 		return nil, err
 	}
+	if token := strings.TrimSpace(config.BearerToken); token != "" {
+		headers.Set("Authorization", "Bearer "+token)
+	}
+	if len(headers) == 0 {
+		return nil, nil
+	}
+	return &http.Client{Transport: &mcpHeaderTransport{headers: headers, base: http.DefaultTransport}}, nil
+}
 
-	mcpSessions.Store(config.Name, session)
-	return session, nil
+// parseMCPHeaders parses "Key: Value" entries. Values are never logged because
+// they usually carry credentials.
+func parseMCPHeaders(entries []string) (http.Header, error) {
+	headers := http.Header{}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, value, found := strings.Cut(entry, ":")
+		key = strings.TrimSpace(key)
+		if !found || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("invalid MCP header, expected \"Key: Value\": %s", key)
+		}
+		headers.Add(key, strings.TrimSpace(value))
+	}
+	return headers, nil
 }
 
-// MCPListTools lists the tools for a given MCP server config with timeout protection
-func MCPListTools(ctx context.Context, config common.AIChatMCPServerConfig) ([]common.MCPTool, error) {
-	if tools, ok := mcpTools.Load(config.Name); ok {
-		util.GetLogger().Debug(ctx, fmt.Sprintf("Listing tools for MCP server from cache: %s", config.Name))
-		return tools, nil
+type mcpHeaderTransport struct {
+	headers http.Header
+	base    http.RoundTripper
+}
+
+func (t *mcpHeaderTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	request = request.Clone(request.Context())
+	for key, values := range t.headers {
+		request.Header[key] = append([]string(nil), values...)
 	}
+	return t.base.RoundTrip(request)
+}
 
-	util.GetLogger().Debug(ctx, fmt.Sprintf("Listing tools for MCP server: %s", config.Name))
+func closeMCPConnectionLocked(name string, connection *mcpConnection) {
+	mcpConnections.Delete(name)
+	mcpTools.Delete(name)
+	_ = connection.session.Close()
+	connection.cancel()
+}
+
+// isMCPTransportError reports whether err means the connection itself is
+// broken. JSON-RPC errors returned by the server, such as an unknown resource
+// or invalid prompt arguments, leave the session usable and are not covered.
+func isMCPTransportError(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, mcp.ErrConnectionClosed) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, os.ErrClosed) ||
+		errors.As(err, &netErr)
+}
+
+// dropMCPSession closes the session of a failed server so the next call reconnects.
+func dropMCPSession(ctx context.Context, name string, cause error) {
+	mcpConnectionsMutex.Lock()
+	if connection, ok := mcpConnections.Load(name); ok {
+		closeMCPConnectionLocked(name, connection)
+	}
+	mcpConnectionsMutex.Unlock()
 
-	// Use channel and goroutine to implement timeout protection
-	type listToolsResult struct {
-		tools []common.MCPTool
-		err   error
+	util.GetLogger().Warn(ctx, fmt.Sprintf("MCP server %s failed: %s", name, cause.Error()))
+	setMCPServerError(name, cause)
+}
+
+// CloseMCPServers disconnects every server whose name is not in keep, used when
+// servers are removed or disabled in settings.
+func CloseMCPServers(ctx context.Context, keep []string) {
+	// Every connection was opened under its connect lock, so waiting for that
+	// lock also closes a session that was still connecting.
+	for _, name := range mcpConnectLocks.Keys() {
+		if slices.Contains(keep, name) {
+			continue
+		}
+		connectLock, _ := mcpConnectLocks.Load(name)
+		connectLock.Lock()
+		mcpConnectionsMutex.Lock()
+		if connection, ok := mcpConnections.Load(name); ok {
+			util.GetLogger().Info(ctx, fmt.Sprintf("Closing MCP server: %s", name))
+			closeMCPConnectionLocked(name, connection)
+		}
+		mcpConnectionsMutex.Unlock()
+		connectLock.Unlock()
+	}
+	for _, name := range mcpHealth.Keys() {
+		if !slices.Contains(keep, name) {
+			mcpHealth.Delete(name)
+		}
+	}
+}
+
+func updateMCPServerHealth(name string, update func(health *common.MCPServerHealth)) {
+	health, ok := mcpHealth.Load(name)
+	if !ok {
+		health = common.MCPServerHealth{Name: name, State: common.MCPServerStateIdle}
+	}
+	update(&health)
+	health.CheckedAt = util.GetSystemTimestamp()
+	mcpHealth.Store(name, health)
+}
+
+func setMCPServerError(name string, err error) {
+	updateMCPServerHealth(name, func(health *common.MCPServerHealth) {
+		health.State = common.MCPServerStateError
+		health.Error = err.Error()
+	})
+}
+
+// GetMCPServerHealth reports the connection state of a configured server.
+func GetMCPServerHealth(config common.AIChatMCPServerConfig) common.MCPServerHealth {
+	if config.Disabled {
+		return common.MCPServerHealth{Name: config.Name, State: common.MCPServerStateDisabled}
+	}
+	if health, ok := mcpHealth.Load(config.Name); ok {
+		return health
 	}
+	return common.MCPServerHealth{Name: config.Name, State: common.MCPServerStateIdle}
+}
 
-	resultChan := make(chan listToolsResult, 1)
+// callMCPServer runs one request against the server with timeout and panic
+// protection. Connection failures drop the session and are recorded in the
+// server health; errors the server answered with keep the session open.
+func callMCPServer[T any](ctx context.Context, config common.AIChatMCPServerConfig, operation string, call func(ctx context.Context, session *mcp.ClientSession) (T, error)) (T, error) {
+	type callResult struct {
+		value     T
+		err       error
+		transport bool
+	}
 
-	// Start the actual tool listing in a separate goroutine
+	resultChan := make(chan callResult, 1)
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {
-				util.GetLogger().Error(ctx, fmt.Sprintf("Panic in MCPListTools for server %s: %v", config.Name, r))
-				resultChan <- listToolsResult{
-					tools: nil,
-					err:   fmt.Errorf("panic occurred while listing tools: %v", r),
-				}
+				util.GetLogger().Error(ctx, fmt.Sprintf("Panic while %s for MCP server %s: %v", operation, config.Name, r))
+				resultChan <- callResult{err: fmt.Errorf("panic occurred while %s: %v", operation, r)}
 			}
 		}()
 
-		// Create timeout context for this operation (30 seconds)
-		timeoutCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		timeoutCtx, cancel := context.WithTimeout(ctx, mcpOperationTimeout)
 		defer cancel()
 
 		session, err := getMCPSession(timeoutCtx, config)
 		if err != nil {
-			resultChan <- listToolsResult{tools: nil, err: err}
+			resultChan <- callResult{err: err, transport: true}
 			return
 		}
-
-		// Process tools and send result
-		processedTools, processErr := processToolsFromSession(timeoutCtx, session, config)
-		resultChan <- listToolsResult{tools: processedTools, err: processErr}
+		value, err := call(timeoutCtx, session)
+		resultChan <- callResult{value: value, err: err, transport: isMCPTransportError(err)}
 	}()
 
-	// Wait for result or timeout
 	select {
 	case result := <-resultChan:
 		if result.err != nil {
-			return nil, result.err
+			if result.transport {
+				dropMCPSession(ctx, config.Name, result.err)
+			} else {
+				util.GetLogger().Warn(ctx, fmt.Sprintf("MCP server %s failed %s: %s", config.Name, operation, result.err.Error()))
+			}
+		}
+		return result.value, result.err
+	case <-time.After(mcpOperationTimeout + 5*time.Second): // Slightly longer than the context timeout
+		err := fmt.Errorf("timeout after %d seconds %s for server: %s", int(mcpOperationTimeout.Seconds())+5, operation, config.Name)
+		util.GetLogger().Error(ctx, err.Error())
+		dropMCPSession(ctx, config.Name, err)
+		var zero T
+		return zero, err
+	}
+}
+
+// MCPListTools lists the tools for a given MCP server config with timeout protection
+func MCPListTools(ctx context.Context, config common.AIChatMCPServerConfig) ([]common.MCPTool, error) {
+	if connection, ok := mcpConnections.Load(config.Name); ok && connection.key == mcpConnectionKey(config) {
+		if tools, ok := mcpTools.Load(config.Name); ok {
+			util.GetLogger().Debug(ctx, fmt.Sprintf("Listing tools for MCP server from cache: %s", config.Name))
+			// Rebind to the current config so an approval policy change applies
+			// without reconnecting.
+			return lo.Map(tools, func(tool common.MCPTool, _ int) common.MCPTool {
+				tool.ServerConfig = &config
+				return tool
+			}), nil
 		}
+	}
+
+	util.GetLogger().Debug(ctx, fmt.Sprintf("Listing tools for MCP server: %s", config.Name))
+
+	tools, err := callMCPServer(ctx, config, "listing tools", func(ctx context.Context, session *mcp.ClientSession) ([]common.MCPTool, error) {
+		return processToolsFromSession(ctx, session, config)
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		util.GetLogger().Debug(ctx, fmt.Sprintf("Found %d tools", len(result.tools)))
-		mcpTools.Store(config.Name, result.tools)
-		return result.tools, nil
+	util.GetLogger().Debug(ctx, fmt.Sprintf("Found %d tools", len(tools)))
+	mcpTools.Store(config.Name, tools)
+	updateMCPServerHealth(config.Name, func(health *common.MCPServerHealth) {
+		health.ToolCount = len(tools)
+	})
+	return tools, nil
+}
 
-	case <-time.After(35 * time.Second): // Slightly longer than the context timeout
-		util.GetLogger().Error(ctx, fmt.Sprintf("Timeout listing tools for MCP server: %s", config.Name))
-		return nil, fmt.Errorf("timeout after 35 seconds listing tools for server: %s", config.Name)
+// MCPListResources lists the resources of a server. Servers without the
+// resources capability return an empty list.
+func MCPListResources(ctx context.Context, config common.AIChatMCPServerConfig) ([]common.MCPResource, error) {
+	resources, err := callMCPServer(ctx, config, "listing resources", func(ctx context.Context, session *mcp.ClientSession) ([]common.MCPResource, error) {
+		if !mcpServerHasCapability(session, func(capabilities *mcp.ServerCapabilities) bool { return capabilities.Resources != nil }) {
+			return nil, nil
+		}
+		var resources []common.MCPResource
+		for resource, err := range session.Resources(ctx, nil) {
+			if err != nil {
+				return nil, fmt.Errorf("error iterating resources: %w", err)
+			}
+			name := resource.Title
+			if name == "" {
+				name = resource.Name
+			}
+			resources = append(resources, common.MCPResource{
+				ServerName:  config.Name,
+				URI:         resource.URI,
+				Name:        name,
+				Description: resource.Description,
+				MimeType:    resource.MIMEType,
+			})
+		}
+		return resources, nil
+	})
+	if err != nil {
+		return nil, err
 	}
+
+	updateMCPServerHealth(config.Name, func(health *common.MCPServerHealth) {
+		health.ResourceCount = len(resources)
+	})
+	return resources, nil
+}
+
+// MCPListPrompts lists the prompt templates of a server. Servers without the
+// prompts capability return an empty list.
+func MCPListPrompts(ctx context.Context, config common.AIChatMCPServerConfig) ([]common.MCPPrompt, error) {
+	prompts, err := callMCPServer(ctx, config, "listing prompts", func(ctx context.Context, session *mcp.ClientSession) ([]common.MCPPrompt, error) {
+		if !mcpServerHasCapability(session, func(capabilities *mcp.ServerCapabilities) bool { return capabilities.Prompts != nil }) {
+			return nil, nil
+		}
+		var prompts []common.MCPPrompt
+		for prompt, err := range session.Prompts(ctx, nil) {
+			if err != nil {
+				return nil, fmt.Errorf("error iterating prompts: %w", err)
+			}
+			converted := common.MCPPrompt{ServerName: config.Name, Name: prompt.Name, Description: prompt.Description}
+			for _, argument := range prompt.Arguments {
+				converted.Arguments = append(converted.Arguments, common.MCPPromptArgument{
+					Name:        argument.Name,
+					Description: argument.Description,
+					Required:    argument.Required,
+				})
+			}
+			prompts = append(prompts, converted)
+		}
+		return prompts, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	updateMCPServerHealth(config.Name, func(health *common.MCPServerHealth) {
+		health.PromptCount = len(prompts)
+	})
+	return prompts, nil
+}
+
+func mcpServerHasCapability(session *mcp.ClientSession, has func(capabilities *mcp.ServerCapabilities) bool) bool {
+	initializeResult := session.InitializeResult()
+	return initializeResult != nil && initializeResult.Capabilities != nil && has(initializeResult.Capabilities)
+}
+
+// MCPReadResource reads a resource as text for the model. Binary contents are
+// described instead of inlined.
+func MCPReadResource(ctx context.Context, config common.AIChatMCPServerConfig, uri string) (string, error) {
+	return callMCPServer(ctx, config, "reading resource", func(ctx context.Context, session *mcp.ClientSession) (string, error) {
+		result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+		if err != nil {
+			return "", err
+		}
+		var parts []string
+		for _, contents := range result.Contents {
+			parts = append(parts, formatMCPResourceContents(contents))
+		}
+		return truncateMCPText(strings.Join(parts, "\n\n")), nil
+	})
+}
+
+// MCPGetPrompt renders a prompt template with the given arguments into plain text.
+func MCPGetPrompt(ctx context.Context, config common.AIChatMCPServerConfig, name string, args map[string]string) (string, error) {
+	return callMCPServer(ctx, config, "getting prompt", func(ctx context.Context, session *mcp.ClientSession) (string, error) {
+		result, err := session.GetPrompt(ctx, &mcp.GetPromptParams{Name: name, Arguments: args})
+		if err != nil {
+			return "", err
+		}
+		var parts []string
+		for _, message := range result.Messages {
+			text := ""
+			switch content := message.Content.(type) {
+			case *mcp.TextContent:
+				text = content.Text
+			case *mcp.EmbeddedResource:
+				if content.Resource != nil {
+					text = formatMCPResourceContents(content.Resource)
+				}
+			default:
+				text = fmt.Sprintf("[%T omitted]", message.Content)
+			}
+			if len(result.Messages) > 1 {
+				text = string(message.Role) + ": " + text
+			}
+			parts = append(parts, text)
+		}
+		return truncateMCPText(strings.Join(parts, "\n\n")), nil
+	})
+}
+
+func formatMCPResourceContents(contents *mcp.ResourceContents) string {
+	if contents.Blob != nil {
+		return fmt.Sprintf("[binary resource %s omitted: %s, %d bytes]", contents.URI, contents.MIMEType, len(contents.Blob))
+	}
+	return contents.Text
+}
+
+func truncateMCPText(text string) string {
+	if len(text) <= mcpResourceMaxChars {
+		return text
+	}
+	return strings.ToValidUTF8(text[:mcpResourceMaxChars], "") + "\n[truncated]"
 }
 
 // processToolsFromSession processes tools from a session and converts to MCPTool format
@@ -217,12 +608,21 @@ func processToolsFromSession(ctx context.Context, session *mcp.ClientSession, co
 			Callback: func(ctx context.Context, args map[string]any) (common.Conversation, error) {
 				util.GetLogger().Debug(ctx, fmt.Sprintf("MCP: Tool call: %s, args: %v", toolName, args))
 
-				result, err := session.CallTool(ctx, &mcp.CallToolParams{
+				// Resolve the session on every call so a dropped connection is
+				// reopened instead of failing every later call.
+				currentSession, err := getMCPSession(ctx, config)
+				if err != nil {
+					return common.Conversation{}, err
+				}
+				result, err := currentSession.CallTool(ctx, &mcp.CallToolParams{
 					Name:      toolName,
 					Arguments: args,
 				})
 				if err != nil {
 					util.GetLogger().Error(ctx, fmt.Sprintf("MCP: Tool call: %s, error: %s", toolName, err))
+					if ctx.Err() == nil && isMCPTransportError(err) {
+						dropMCPSession(ctx, config.Name, err)
+					}
 					return common.Conversation{}, err
 				}
 
@@ -252,9 +652,6 @@ func processToolsFromSession(ctx context.Context, session *mcp.ClientSession, co
 		})
 	}
 
-	util.GetLogger().Debug(ctx, fmt.Sprintf("Found %d tools", len(toolsList)))
-	mcpTools.Store(config.Name, toolsList)
-
 	return toolsList, nil
 }
 
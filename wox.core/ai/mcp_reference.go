@@ -0,0 +1,63 @@
+package ai
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	MCPReferenceKindPrompt   = "prompt"
+	MCPReferenceKindResource = "resource"
+)
+
+// {prompt:server:name arg="value"} and {resource:server:uri} are inserted by the
+// chat command palette. Like {skill:name}, they stay in the saved message and
+// are expanded only in the text sent to the model.
+var mcpReferenceTagPattern = regexp.MustCompile(`\{(prompt|resource):([^:{}\s]+):([^{}]+)\}`)
+var mcpPromptArgumentPattern = regexp.MustCompile(`([\w.-]+)=(?:"([^"]*)"|(\S+))`)
+
+// MCPReference is one prompt or resource tag found in a chat message.
+type MCPReference struct {
+	Tag        string
+	Kind       string
+	ServerName string
+	// Name is the prompt name or the resource URI.
+	Name      string
+	Arguments map[string]string
+}
+
+// ParseMCPReferences returns the unique MCP tags of a message in order.
+func ParseMCPReferences(text string) []MCPReference {
+	var references []MCPReference
+	seen := map[string]bool{}
+	for _, match := range mcpReferenceTagPattern.FindAllStringSubmatch(text, -1) {
+		if seen[match[0]] {
+			continue
+		}
+		seen[match[0]] = true
+
+		reference := MCPReference{Tag: match[0], Kind: match[1], ServerName: match[2]}
+		body := strings.TrimSpace(match[3])
+		if reference.Kind == MCPReferenceKindResource {
+			reference.Name = body
+		} else {
+			name, rest, _ := strings.Cut(body, " ")
+			reference.Name = name
+			for _, argument := range mcpPromptArgumentPattern.FindAllStringSubmatch(rest, -1) {
+				if reference.Arguments == nil {
+					reference.Arguments = map[string]string{}
+				}
+				reference.Arguments[argument[1]] = argument[2] + argument[3]
+			}
+		}
+		if reference.Name != "" {
+			references = append(references, reference)
+		}
+	}
+	return references
+}
+
+// StripMCPReferenceTags removes all prompt and resource tags from the given text.
+func StripMCPReferenceTags(text string) string {
+	return strings.TrimSpace(mcpReferenceTagPattern.ReplaceAllString(text, ""))
+}
@@ -0,0 +1,116 @@
+package ai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"wox/common"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func newTestMCPServer() *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "test"}, nil)
+	server.AddResource(&mcp.Resource{URI: "file:///notes.md", Name: "notes", MIMEType: "text/markdown"}, func(ctx context.Context, request *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{{URI: request.Params.URI, Text: "# Notes"}}}, nil
+	})
+	server.AddPrompt(&mcp.Prompt{Name: "review", Arguments: []*mcp.PromptArgument{{Name: "pr", Required: true}}}, func(ctx context.Context, request *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return &mcp.GetPromptResult{Messages: []*mcp.PromptMessage{{Role: "user", Content: &mcp.TextContent{Text: "Review PR " + request.Params.Arguments["pr"]}}}}, nil
+	})
+	return server
+}
+
+func TestMCPResourcesAndPromptsWithAuthHeaders(t *testing.T) {
+	server := newTestMCPServer()
+	streamable := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil)
+	sse := mcp.NewSSEHandler(func(*http.Request) *mcp.Server { return server }, nil)
+	httpServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("Authorization") != "Bearer secret" || request.Header.Get("X-Team") != "wox" {
+			http.Error(writer, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if strings.HasPrefix(request.URL.Path, "/sse") {
+			sse.ServeHTTP(writer, request)
+			return
+		}
+		streamable.ServeHTTP(writer, request)
+	}))
+	defer httpServer.Close()
+
+	ctx := context.Background()
+	for _, config := range []common.AIChatMCPServerConfig{
+		{Name: "test-streamable", Type: common.AIChatMCPServerTypeStreamableHTTP, Url: httpServer.URL + "/mcp", Headers: []string{"X-Team: wox"}, BearerToken: "secret"},
+		{Name: "test-sse", Type: common.AIChatMCPServerTypeSSE, Url: httpServer.URL + "/sse", Headers: []string{"X-Team: wox"}, BearerToken: "secret"},
+	} {
+		resources, err := MCPListResources(ctx, config)
+		if err != nil || len(resources) != 1 || resources[0].URI != "file:///notes.md" || resources[0].ServerName != config.Name {
+			t.Fatalf("%s: list resources: %v %+v", config.Name, err, resources)
+		}
+		text, err := MCPReadResource(ctx, config, resources[0].URI)
+		if err != nil || text != "# Notes" {
+			t.Fatalf("%s: read resource: %v %q", config.Name, err, text)
+		}
+		// An error answered by the server is not a broken connection.
+		if _, err := MCPReadResource(ctx, config, "file:///missing.md"); err == nil {
+			t.Fatalf("%s: expected reading an unknown resource to fail", config.Name)
+		}
+
+		prompts, err := MCPListPrompts(ctx, config)
+		if err != nil || len(prompts) != 1 || prompts[0].Name != "review" || len(prompts[0].Arguments) != 1 || !prompts[0].Arguments[0].Required {
+			t.Fatalf("%s: list prompts: %v %+v", config.Name, err, prompts)
+		}
+		text, err = MCPGetPrompt(ctx, config, "review", map[string]string{"pr": "42"})
+		if err != nil || text != "Review PR 42" {
+			t.Fatalf("%s: get prompt: %v %q", config.Name, err, text)
+		}
+
+		health := GetMCPServerHealth(config)
+		if health.State != common.MCPServerStateConnected || health.ResourceCount != 1 || health.PromptCount != 1 {
+			t.Fatalf("%s: unexpected health: %+v", config.Name, health)
+		}
+	}
+	CloseMCPServers(ctx, nil)
+
+	unauthorized := common.AIChatMCPServerConfig{Name: "test-unauthorized", Type: common.AIChatMCPServerTypeStreamableHTTP, Url: httpServer.URL + "/mcp"}
+	if _, err := MCPListResources(ctx, unauthorized); err == nil {
+		t.Fatalf("expected a server without credentials to fail")
+	}
+	if health := GetMCPServerHealth(unauthorized); health.State != common.MCPServerStateError || health.Error == "" {
+		t.Fatalf("expected an error health state, got %+v", health)
+	}
+	CloseMCPServers(ctx, nil)
+}
+
+func TestParseMCPHeaders(t *testing.T) {
+	headers, err := parseMCPHeaders([]string{"X-Api-Key: a:b=c", " ", "Accept:text/plain"})
+	if err != nil || headers.Get("X-Api-Key") != "a:b=c" || headers.Get("Accept") != "text/plain" {
+		t.Fatalf("unexpected headers: %v %v", err, headers)
+	}
+	for _, invalid := range []string{"X-Api-Key=abc", ": value", "X Api: value"} {
+		if _, err := parseMCPHeaders([]string{invalid}); err == nil {
+			t.Fatalf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestParseMCPReferences(t *testing.T) {
+	text := `{prompt:github:review pr="42" repo=wox draft=""} check {resource:docs:file:///a b.md} and {resource:docs:file:///a b.md} {skill:x}`
+	references := ParseMCPReferences(text)
+	if len(references) != 2 {
+		t.Fatalf("expected duplicate tags to be merged, got %+v", references)
+	}
+	prompt := references[0]
+	if prompt.Kind != MCPReferenceKindPrompt || prompt.ServerName != "github" || prompt.Name != "review" ||
+		prompt.Arguments["pr"] != "42" || prompt.Arguments["repo"] != "wox" || prompt.Arguments["draft"] != "" || len(prompt.Arguments) != 3 {
+		t.Fatalf("unexpected prompt reference: %+v", prompt)
+	}
+	resource := references[1]
+	if resource.Kind != MCPReferenceKindResource || resource.ServerName != "docs" || resource.Name != "file:///a b.md" {
+		t.Fatalf("unexpected resource reference: %+v", resource)
+	}
+	if stripped := StripMCPReferenceTags(text); stripped != "check  and  {skill:x}" {
+		t.Fatalf("unexpected stripped text: %q", stripped)
+	}
+}
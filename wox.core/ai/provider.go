@@ -19,6 +19,13 @@ type Provider interface {
 	Ping(ctx context.Context) error
 }
 
+// EmbeddingProvider is implemented by providers with an embeddings API. It is
+// kept out of Provider because most chat-only providers cannot embed text.
+type EmbeddingProvider interface {
+	// Embed returns one vector per input, in input order.
+	Embed(ctx context.Context, model string, inputs []string) ([][]float32, error)
+}
+
 type ChatStream interface {
 	// when chat stream data type is tool call, the data is json string of common.ToolCallInfo
 	Receive(ctx context.Context) (common.ChatStreamData, error)
@@ -0,0 +1,631 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/png"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"wox/common"
+	"wox/setting"
+	"wox/util"
+)
+
+func init() {
+	providerFactories["anthropic"] = NewAnthropicProvider
+}
+
+const (
+	anthropicDefaultHost          = "https://api.anthropic.com/v1"
+	anthropicAPIVersion           = "2023-06-01"
+	anthropicMaxTokens            = 8192
+	anthropicThinkingMaxTokens    = 16000
+	anthropicThinkingBudgetTokens = 8000
+	// anthropicSystemCacheBreakpoints marks the last two system blocks. The chat
+	// runtime puts volatile text such as the clock last, so the breakpoint on the
+	// block before it still hits the cache when only the clock changed.
+	anthropicSystemCacheBreakpoints = 2
+)
+
+var anthropicImageMediaTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// AnthropicProvider talks to the Anthropic Messages API directly. Unlike the
+// other providers it is not OpenAI compatible, so thinking blocks, tool_use
+// blocks and prompt caching are mapped by hand.
+type AnthropicProvider struct {
+	connectContext setting.AIProvider
+}
+
+// AnthropicProviderStream reads the server-sent events of one Messages request
+type AnthropicProviderStream struct {
+	body              io.ReadCloser
+	reader            *bufio.Reader
+	accumulatedData   string
+	accumulatedReason string
+	reasonSignature   string
+	redactedReasoning []string
+	toolCalls         []anthropicStreamToolCall
+	toolCallIndexes   map[int]int // content block index -> toolCalls index
+	finished          bool
+}
+
+type anthropicStreamToolCall struct {
+	Id          string
+	Name        string
+	PartialJSON string
+}
+
+type anthropicMessagesRequest struct {
+	Model     string                  `json:"model"`
+	MaxTokens int                     `json:"max_tokens"`
+	System    []anthropicContentBlock `json:"system,omitempty"`
+	Messages  []anthropicMessage      `json:"messages"`
+	Tools     []anthropicTool         `json:"tools,omitempty"`
+	Thinking  *anthropicThinking      `json:"thinking,omitempty"`
+	Stream    bool                    `json:"stream"`
+}
+
+type anthropicMessage struct {
+	Role    string                  `json:"role"`
+	Content []anthropicContentBlock `json:"content"`
+}
+
+type anthropicContentBlock struct {
+	Type         string                 `json:"type"`
+	Text         string                 `json:"text,omitempty"`
+	Thinking     string                 `json:"thinking,omitempty"`
+	Signature    string                 `json:"signature,omitempty"`
+	Data         string                 `json:"data,omitempty"`
+	Source       *anthropicImageSource  `json:"source,omitempty"`
+	Id           string                 `json:"id,omitempty"`
+	Name         string                 `json:"name,omitempty"`
+	Input        any                    `json:"input,omitempty"`
+	ToolUseId    string                 `json:"tool_use_id,omitempty"`
+	Content      string                 `json:"content,omitempty"`
+	IsError      bool                   `json:"is_error,omitempty"`
+	CacheControl *anthropicCacheControl `json:"cache_control,omitempty"`
+}
+
+type anthropicImageSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+type anthropicCacheControl struct {
+	Type string `json:"type"`
+}
+
+type anthropicTool struct {
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description,omitempty"`
+	InputSchema  map[string]any         `json:"input_schema"`
+	CacheControl *anthropicCacheControl `json:"cache_control,omitempty"`
+}
+
+type anthropicThinking struct {
+	Type         string `json:"type"`
+	BudgetTokens int    `json:"budget_tokens"`
+}
+
+type anthropicStreamEvent struct {
+	Type         string                `json:"type"`
+	Index        int                   `json:"index"`
+	ContentBlock anthropicContentBlock `json:"content_block"`
+	Delta        anthropicStreamDelta  `json:"delta"`
+	Error        *anthropicError       `json:"error"`
+}
+
+type anthropicStreamDelta struct {
+	Type        string `json:"type"`
+	Text        string `json:"text"`
+	Thinking    string `json:"thinking"`
+	Signature   string `json:"signature"`
+	PartialJSON string `json:"partial_json"`
+	StopReason  string `json:"stop_reason"`
+}
+
+type anthropicError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+type anthropicModelsResponse struct {
+	Data []struct {
+		Id string `json:"id"`
+	} `json:"data"`
+	HasMore bool   `json:"has_more"`
+	LastId  string `json:"last_id"`
+}
+
+func NewAnthropicProvider(ctx context.Context, connectContext setting.AIProvider) Provider {
+	if connectContext.Host == "" {
+		connectContext.Host = anthropicDefaultHost
+	}
+
+	return &AnthropicProvider{connectContext: connectContext}
+}
+
+func (p *AnthropicProvider) GetIcon() common.WoxImage {
+	return common.NewWoxImageSvg(`<svg fill="currentColor" fill-rule="evenodd" height="1em" style="flex:none;line-height:1" viewBox="0 0 24 24" width="1em" xmlns="http://www.w3.org/2000/svg"><title>Anthropic</title><path d="M13.827 3.52h3.603L24 20h-3.603l-6.57-16.48zm-7.258 0h3.767L16.906 20h-3.674l-1.343-3.461H5.017l-1.344 3.46H0L6.57 3.522zm4.132 9.959L8.453 7.687 6.205 13.48H10.7z"></path></svg>`)
+}
+
+func (p *AnthropicProvider) GetDefaultHost() string {
+	return anthropicDefaultHost
+}
+
+// ChatStream starts a streaming Messages request
+func (p *AnthropicProvider) ChatStream(ctx context.Context, model common.Model, conversations []common.Conversation, options common.ChatOptions) (ChatStream, error) {
+	request := p.buildMessagesRequest(ctx, model, conversations, options)
+	util.GetLogger().Debug(ctx, fmt.Sprintf("AI: anthropic chat stream with model: %s, messages: %d, tools: %d, thinking: %t", model.Name, len(request.Messages), len(request.Tools), request.Thinking != nil))
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal anthropic request: %w", err)
+	}
+	resp, err := p.doRequest(ctx, http.MethodPost, "/messages", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	return &AnthropicProviderStream{
+		body:            resp.Body,
+		reader:          bufio.NewReader(resp.Body),
+		toolCallIndexes: map[int]int{},
+	}, nil
+}
+
+// Models lists every model id available to the api key, following pagination
+func (p *AnthropicProvider) Models(ctx context.Context) ([]common.Model, error) {
+	var models []common.Model
+	afterId := ""
+	for {
+		query := url.Values{"limit": []string{"1000"}}
+		if afterId != "" {
+			query.Set("after_id", afterId)
+		}
+		resp, err := p.doRequest(ctx, http.MethodGet, "/models?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var page anthropicModelsResponse
+		decodeErr := json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if decodeErr != nil {
+			return nil, fmt.Errorf("failed to decode anthropic models: %w", decodeErr)
+		}
+		for _, model := range page.Data {
+			models = append(models, common.Model{
+				Name:          model.Id,
+				Provider:      common.ProviderName(p.connectContext.Name),
+				ProviderAlias: p.connectContext.Alias,
+			})
+		}
+		if !page.HasMore || page.LastId == "" {
+			return models, nil
+		}
+		afterId = page.LastId
+	}
+}
+
+// Ping checks the api key with the cheapest authenticated request
+func (p *AnthropicProvider) Ping(ctx context.Context) error {
+	resp, err := p.doRequest(ctx, http.MethodGet, "/models?limit=1", nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// doRequest sends an authenticated request and turns non-2xx responses into
+// errors carrying the API error message.
+func (p *AnthropicProvider) doRequest(ctx context.Context, method string, path string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(p.connectContext.Host, "/")+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-api-key", p.connectContext.ApiKey)
+	req.Header.Set("anthropic-version", anthropicAPIVersion)
+	req.Header.Set("User-Agent", "Wox")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "text/event-stream")
+	}
+
+	resp, err := util.GetHTTPClient(ctx).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	var errorResponse struct {
+		Error anthropicError `json:"error"`
+	}
+	if json.Unmarshal(respBody, &errorResponse) == nil && errorResponse.Error.Message != "" {
+		return nil, fmt.Errorf("anthropic api error (%d %s): %s", resp.StatusCode, errorResponse.Error.Type, errorResponse.Error.Message)
+	}
+	return nil, fmt.Errorf("anthropic api error (%d): %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+}
+
+func (p *AnthropicProvider) buildMessagesRequest(ctx context.Context, model common.Model, conversations []common.Conversation, options common.ChatOptions) anthropicMessagesRequest {
+	system, messages := p.convertConversations(ctx, conversations)
+	request := anthropicMessagesRequest{
+		Model:     model.Name,
+		MaxTokens: anthropicMaxTokens,
+		System:    system,
+		Messages:  messages,
+		Tools:     p.convertTools(options.Tools),
+		Stream:    true,
+	}
+
+	// Thinking stays off unless asked for: it is not the API default and it
+	// needs a larger max_tokens than the budget.
+	if options.ThinkingMode == common.ChatThinkingModeThinking {
+		if lastToolUseTurnHasThinking(messages) {
+			request.Thinking = &anthropicThinking{Type: "enabled", BudgetTokens: anthropicThinkingBudgetTokens}
+			request.MaxTokens = anthropicThinkingMaxTokens
+		} else {
+			// A tool loop that started without thinking, or whose thinking
+			// signature was lost, cannot switch thinking on midway.
+			util.GetLogger().Warn(ctx, "AI: anthropic thinking disabled because the current tool call turn has no signed thinking block")
+		}
+	}
+
+	// Cache order is tools -> system -> messages. A breakpoint on the last tool
+	// caches all tools, the system breakpoints cache prompts such as the skill
+	// list, and the last message breakpoint lets the next tool loop iteration
+	// reuse the conversation so far. Four is the API maximum.
+	if len(request.Tools) > 0 {
+		request.Tools[len(request.Tools)-1].CacheControl = &anthropicCacheControl{Type: "ephemeral"}
+	}
+	for i := max(0, len(request.System)-anthropicSystemCacheBreakpoints); i < len(request.System); i++ {
+		request.System[i].CacheControl = &anthropicCacheControl{Type: "ephemeral"}
+	}
+	if len(request.Messages) > 0 {
+		lastMessage := request.Messages[len(request.Messages)-1]
+		if len(lastMessage.Content) > 0 {
+			lastMessage.Content[len(lastMessage.Content)-1].CacheControl = &anthropicCacheControl{Type: "ephemeral"}
+		}
+	}
+
+	return request
+}
+
+// convertConversations splits system prompts out and maps the rest to
+// alternating user/assistant messages. Tool conversations become tool_use
+// blocks on the assistant turn followed by a user turn of tool_result blocks.
+func (p *AnthropicProvider) convertConversations(ctx context.Context, conversations []common.Conversation) ([]anthropicContentBlock, []anthropicMessage) {
+	var system []anthropicContentBlock
+	var messages []anthropicMessage
+	for i := 0; i < len(conversations); i++ {
+		conversation := conversations[i]
+		switch conversation.Role {
+		case common.ConversationRoleSystem:
+			if strings.TrimSpace(conversation.Text) != "" {
+				system = append(system, anthropicContentBlock{Type: "text", Text: conversation.Text})
+			}
+		case common.ConversationRoleUser:
+			var blocks []anthropicContentBlock
+			for _, image := range conversation.Images {
+				source, err := convertAnthropicImage(ctx, image)
+				if err != nil {
+					util.GetLogger().Warn(ctx, fmt.Sprintf("AI: skip image for anthropic request: %s", err.Error()))
+					continue
+				}
+				blocks = append(blocks, anthropicContentBlock{Type: "image", Source: source})
+			}
+			if strings.TrimSpace(conversation.Text) != "" {
+				blocks = append(blocks, anthropicContentBlock{Type: "text", Text: conversation.Text})
+			}
+			messages = appendAnthropicMessage(messages, "user", blocks)
+		case common.ConversationRoleAssistant:
+			var blocks []anthropicContentBlock
+			// Unsigned reasoning, e.g. from another provider, cannot be replayed.
+			if conversation.Reasoning != "" && conversation.ReasoningSignature != "" {
+				blocks = append(blocks, anthropicContentBlock{Type: "thinking", Thinking: conversation.Reasoning, Signature: conversation.ReasoningSignature})
+			}
+			for _, data := range conversation.RedactedReasoning {
+				blocks = append(blocks, anthropicContentBlock{Type: "redacted_thinking", Data: data})
+			}
+			if strings.TrimSpace(conversation.Text) != "" {
+				blocks = append(blocks, anthropicContentBlock{Type: "text", Text: conversation.Text})
+			}
+			messages = appendAnthropicMessage(messages, "assistant", blocks)
+		case common.ConversationRoleTool:
+			toolConversations := []common.Conversation{conversation}
+			for i+1 < len(conversations) && conversations[i+1].Role == common.ConversationRoleTool {
+				i++
+				toolConversations = append(toolConversations, conversations[i])
+			}
+
+			toolUses := make([]anthropicContentBlock, 0, len(toolConversations))
+			toolResults := make([]anthropicContentBlock, 0, len(toolConversations))
+			for _, toolConversation := range toolConversations {
+				toolCall := toolConversation.ToolCallInfo
+				input := toolCall.Arguments
+				if input == nil {
+					input = map[string]any{}
+				}
+				toolUses = append(toolUses, anthropicContentBlock{Type: "tool_use", Id: toolCall.Id, Name: toolCall.Name, Input: input})
+				toolResults = append(toolResults, anthropicContentBlock{
+					Type:      "tool_result",
+					ToolUseId: toolCall.Id,
+					Content:   toolCall.Response,
+					IsError:   toolCall.Status == common.ToolCallStatusFailed,
+				})
+			}
+			messages = appendAnthropicMessage(messages, "assistant", toolUses)
+			messages = appendAnthropicMessage(messages, "user", toolResults)
+		}
+	}
+
+	return system, messages
+}
+
+// appendAnthropicMessage merges consecutive turns of the same role, because
+// the assistant text of a tool loop and its tool_use blocks must be one turn.
+func appendAnthropicMessage(messages []anthropicMessage, role string, blocks []anthropicContentBlock) []anthropicMessage {
+	if len(blocks) == 0 {
+		return messages
+	}
+	if len(messages) > 0 && messages[len(messages)-1].Role == role {
+		messages[len(messages)-1].Content = append(messages[len(messages)-1].Content, blocks...)
+		return messages
+	}
+	return append(messages, anthropicMessage{Role: role, Content: blocks})
+}
+
+// lastToolUseTurnHasThinking reports whether thinking can be enabled. When the
+// conversation ends in a tool loop, the API requires that loop's assistant turn
+// to start with a signed thinking block.
+func lastToolUseTurnHasThinking(messages []anthropicMessage) bool {
+	if len(messages) < 2 || messages[len(messages)-1].Role != "user" {
+		return true
+	}
+	lastUser := messages[len(messages)-1]
+	if len(lastUser.Content) == 0 || lastUser.Content[0].Type != "tool_result" {
+		return true
+	}
+	assistant := messages[len(messages)-2]
+	return assistant.Role == "assistant" && len(assistant.Content) > 0 && (assistant.Content[0].Type == "thinking" || assistant.Content[0].Type == "redacted_thinking")
+}
+
+func (p *AnthropicProvider) convertTools(tools []common.Tool) []anthropicTool {
+	convertedTools := make([]anthropicTool, 0, len(tools))
+	for _, tool := range tools {
+		inputSchema := map[string]any{"type": tool.Parameters.Type}
+		if inputSchema["type"] == "" {
+			inputSchema["type"] = "object"
+		}
+		if tool.Parameters.Properties != nil {
+			inputSchema["properties"] = tool.Parameters.Properties
+		} else {
+			inputSchema["properties"] = map[string]any{}
+		}
+		if len(tool.Parameters.Required) > 0 {
+			inputSchema["required"] = tool.Parameters.Required
+		}
+
+		convertedTools = append(convertedTools, anthropicTool{
+			Name:        tool.Name,
+			Description: tool.Description,
+			InputSchema: inputSchema,
+		})
+	}
+	return convertedTools
+}
+
+// convertAnthropicImage sends data urls and supported image files as they are,
+// remote images by url, and renders anything else (svg, file icons) to png.
+func convertAnthropicImage(ctx context.Context, image common.WoxImage) (*anthropicImageSource, error) {
+	switch image.ImageType {
+	case common.WoxImageTypeUrl:
+		return &anthropicImageSource{Type: "url", URL: image.ImageData}, nil
+	case common.WoxImageTypeBase64:
+		header, payload, found := strings.Cut(strings.TrimPrefix(image.ImageData, "data:"), ",")
+		mediaType := strings.TrimSuffix(header, ";base64")
+		if found && anthropicImageMediaTypes[mediaType] {
+			return &anthropicImageSource{Type: "base64", MediaType: mediaType, Data: payload}, nil
+		}
+	case common.WoxImageTypeAbsolutePath:
+		data, err := os.ReadFile(image.ImageData)
+		if err != nil {
+			return nil, err
+		}
+		if mediaType := http.DetectContentType(data); anthropicImageMediaTypes[mediaType] {
+			return &anthropicImageSource{Type: "base64", MediaType: mediaType, Data: base64.StdEncoding.EncodeToString(data)}, nil
+		}
+	}
+
+	img, err := image.ToImageWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, img); err != nil {
+		return nil, err
+	}
+	return &anthropicImageSource{Type: "base64", MediaType: "image/png", Data: base64.StdEncoding.EncodeToString(buffer.Bytes())}, nil
+}
+
+func (s *AnthropicProviderStream) Receive(ctx context.Context) (common.ChatStreamData, error) {
+	if s.finished {
+		return common.ChatStreamData{}, io.EOF
+	}
+
+	for {
+		eventData, err := s.nextEventData()
+		if errors.Is(err, io.EOF) {
+			// The connection closed before message_stop, so the answer and
+			// any tool input may be cut off.
+			s.body.Close()
+			s.finished = true
+			return common.ChatStreamData{}, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			s.body.Close()
+			s.finished = true
+			return common.ChatStreamData{}, err
+		}
+
+		var event anthropicStreamEvent
+		if err := json.Unmarshal([]byte(eventData), &event); err != nil {
+			util.GetLogger().Error(ctx, fmt.Sprintf("AI: Failed to unmarshal anthropic stream event: %s, error: %s", eventData, err.Error()))
+			continue
+		}
+
+		switch event.Type {
+		case "content_block_start":
+			// Redacted thinking arrives whole and encrypted; it is only kept
+			// so the tool loop can send it back.
+			if event.ContentBlock.Type == "redacted_thinking" && event.ContentBlock.Data != "" {
+				s.redactedReasoning = append(s.redactedReasoning, event.ContentBlock.Data)
+			}
+			if event.ContentBlock.Type == "tool_use" {
+				s.toolCallIndexes[event.Index] = len(s.toolCalls)
+				s.toolCalls = append(s.toolCalls, anthropicStreamToolCall{Id: event.ContentBlock.Id, Name: event.ContentBlock.Name})
+				return s.streamingData(), nil
+			}
+		case "content_block_delta":
+			switch event.Delta.Type {
+			case "text_delta":
+				if event.Delta.Text != "" {
+					s.accumulatedData += event.Delta.Text
+					return s.streamingData(), nil
+				}
+			case "thinking_delta":
+				if event.Delta.Thinking != "" {
+					s.accumulatedReason += event.Delta.Thinking
+					return s.streamingData(), nil
+				}
+			case "signature_delta":
+				s.reasonSignature += event.Delta.Signature
+			case "input_json_delta":
+				if toolCallIndex, ok := s.toolCallIndexes[event.Index]; ok && event.Delta.PartialJSON != "" {
+					s.toolCalls[toolCallIndex].PartialJSON += event.Delta.PartialJSON
+					return s.streamingData(), nil
+				}
+			}
+		case "message_delta":
+			if event.Delta.StopReason == "max_tokens" {
+				util.GetLogger().Warn(ctx, "AI: anthropic response stopped at max_tokens")
+			}
+		case "message_stop":
+			return s.finish(ctx), nil
+		case "error":
+			s.body.Close()
+			s.finished = true
+			if event.Error != nil {
+				return common.ChatStreamData{}, fmt.Errorf("anthropic stream error (%s): %s", event.Error.Type, event.Error.Message)
+			}
+			return common.ChatStreamData{}, fmt.Errorf("anthropic stream error: %s", eventData)
+		}
+	}
+}
+
+// nextEventData returns the data of the next server-sent event, skipping
+// comments and events without data.
+func (s *AnthropicProviderStream) nextEventData() (string, error) {
+	var dataLines []string
+	for {
+		line, err := s.reader.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		if data, ok := strings.CutPrefix(line, "data:"); ok {
+			dataLines = append(dataLines, strings.TrimPrefix(data, " "))
+		}
+		if (line == "" || err != nil) && len(dataLines) > 0 {
+			return strings.Join(dataLines, "\n"), nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+func (s *AnthropicProviderStream) streamingData() common.ChatStreamData {
+	streamData := common.ChatStreamData{
+		Status:    common.ChatStreamStatusStreaming,
+		Data:      s.accumulatedData,
+		Reasoning: s.accumulatedReason,
+	}
+	for index, toolCall := range s.toolCalls {
+		// Tool inputs stream one block at a time, so only the last one is
+		// still streaming.
+		status := common.ToolCallStatusStreaming
+		if index < len(s.toolCalls)-1 {
+			status = common.ToolCallStatusPending
+		}
+		streamData.ToolCalls = append(streamData.ToolCalls, common.ToolCallInfo{
+			Id:        toolCall.Id,
+			Name:      toolCall.Name,
+			Arguments: map[string]any{},
+			Delta:     toolCall.PartialJSON,
+			Status:    status,
+		})
+	}
+	return streamData
+}
+
+func (s *AnthropicProviderStream) finish(ctx context.Context) common.ChatStreamData {
+	s.body.Close()
+	s.finished = true
+
+	var toolCallInfos []common.ToolCallInfo
+	for _, toolCall := range s.toolCalls {
+		toolCallInfo := common.ToolCallInfo{
+			Id:    toolCall.Id,
+			Name:  toolCall.Name,
+			Delta: toolCall.PartialJSON,
+		}
+		// A tool without parameters streams no input_json_delta at all.
+		arguments := toolCall.PartialJSON
+		if strings.TrimSpace(arguments) == "" {
+			arguments = "{}"
+			toolCallInfo.Delta = arguments
+		}
+
+		var argsMap map[string]any
+		if err := json.Unmarshal([]byte(arguments), &argsMap); err == nil {
+			if argsMap == nil {
+				argsMap = map[string]any{}
+			}
+			toolCallInfo.Arguments = normalizeToolCallArguments(ctx, toolCall.Name, argsMap)
+			toolCallInfo.Status = common.ToolCallStatusPending
+		} else {
+			util.GetLogger().Error(ctx, fmt.Sprintf("AI: Failed to unmarshal anthropic tool input, json=%s, err: %s", arguments, err.Error()))
+			toolCallInfo.Arguments = map[string]any{}
+			toolCallInfo.Status = common.ToolCallStatusFailed
+			toolCallInfo.Response = err.Error()
+		}
+		toolCallInfos = append(toolCallInfos, toolCallInfo)
+	}
+
+	return common.ChatStreamData{
+		Status:             common.ChatStreamStatusStreamed,
+		Data:               s.accumulatedData,
+		Reasoning:          s.accumulatedReason,
+		ReasoningSignature: s.reasonSignature,
+		RedactedReasoning:  s.redactedReasoning,
+		ToolCalls:          toolCallInfos,
+	}
+}
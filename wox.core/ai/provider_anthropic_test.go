@@ -0,0 +1,221 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"wox/common"
+	"wox/setting"
+)
+
+func newAnthropicTestServer(t *testing.T, handler func(w http.ResponseWriter, request anthropicMessagesRequest)) *AnthropicProvider {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("x-api-key") != "test-key" || r.Header.Get("anthropic-version") != anthropicAPIVersion {
+			t.Errorf("unexpected auth headers: %v", r.Header)
+		}
+		var request anthropicMessagesRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		handler(w, request)
+	}))
+	t.Cleanup(server.Close)
+
+	return NewAnthropicProvider(context.Background(), setting.AIProvider{Name: "anthropic", ApiKey: "test-key", Host: server.URL + "/v1"}).(*AnthropicProvider)
+}
+
+func writeAnthropicEvents(w http.ResponseWriter, events ...string) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	for _, event := range events {
+		var parsed struct {
+			Type string `json:"type"`
+		}
+		_ = json.Unmarshal([]byte(event), &parsed)
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", parsed.Type, event)
+	}
+}
+
+func drainAnthropicStream(t *testing.T, stream ChatStream) (common.ChatStreamData, int) {
+	t.Helper()
+
+	streamingCount := 0
+	for {
+		data, err := stream.Receive(context.Background())
+		if err != nil {
+			t.Fatalf("receive: %v", err)
+		}
+		if data.Status == common.ChatStreamStatusStreamed {
+			return data, streamingCount
+		}
+		streamingCount++
+	}
+}
+
+func TestAnthropicProviderChatStream(t *testing.T) {
+	provider := newAnthropicTestServer(t, func(w http.ResponseWriter, request anthropicMessagesRequest) {
+		if request.Thinking == nil || request.Thinking.BudgetTokens != anthropicThinkingBudgetTokens || request.MaxTokens <= request.Thinking.BudgetTokens {
+			t.Errorf("expected thinking to be enabled, got %+v max_tokens=%d", request.Thinking, request.MaxTokens)
+		}
+		if len(request.System) != 2 || request.System[1].CacheControl == nil || request.Tools[0].CacheControl == nil {
+			t.Errorf("expected cached system prompt and tools, got %+v %+v", request.System, request.Tools)
+		}
+		if len(request.Messages) != 1 || request.Messages[0].Content[0].Type != "image" || request.Messages[0].Content[0].Source.MediaType != "image/png" {
+			t.Errorf("unexpected messages: %+v", request.Messages)
+		}
+
+		writeAnthropicEvents(w,
+			`{"type":"message_start","message":{"id":"msg_1","role":"assistant","content":[]}}`,
+			`{"type":"content_block_start","index":0,"content_block":{"type":"thinking","thinking":""}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"Need the "}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"weather."}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"signature_delta","signature":"sig-1"}}`,
+			`{"type":"content_block_stop","index":0}`,
+			`{"type":"content_block_start","index":1,"content_block":{"type":"redacted_thinking","data":"encrypted-1"}}`,
+			`{"type":"content_block_stop","index":1}`,
+			`{"type":"ping"}`,
+			`{"type":"content_block_start","index":2,"content_block":{"type":"text","text":""}}`,
+			`{"type":"content_block_delta","index":2,"delta":{"type":"text_delta","text":"Checking"}}`,
+			`{"type":"content_block_stop","index":2}`,
+			`{"type":"content_block_start","index":3,"content_block":{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{}}}`,
+			`{"type":"content_block_delta","index":3,"delta":{"type":"input_json_delta","partial_json":"{\"city\": "}}`,
+			`{"type":"content_block_delta","index":3,"delta":{"type":"input_json_delta","partial_json":"\"Paris\"}"}}`,
+			`{"type":"content_block_stop","index":3}`,
+			`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":20}}`,
+			`{"type":"message_stop"}`,
+		)
+	})
+
+	stream, err := provider.ChatStream(context.Background(), common.Model{Name: "claude-test"}, []common.Conversation{
+		{Role: common.ConversationRoleSystem, Text: "Available skills"},
+		{Role: common.ConversationRoleSystem, Text: "Current time"},
+		{Role: common.ConversationRoleUser, Text: "Weather?", Images: []common.WoxImage{{ImageType: common.WoxImageTypeBase64, ImageData: "data:image/png;base64,iVBORw0KGgo="}}},
+	}, common.ChatOptions{
+		Tools:        []common.Tool{{Name: "get_weather", Description: "Get weather"}},
+		ThinkingMode: common.ChatThinkingModeThinking,
+	})
+	if err != nil {
+		t.Fatalf("chat stream: %v", err)
+	}
+
+	result, streamingCount := drainAnthropicStream(t, stream)
+	if streamingCount != 6 {
+		t.Fatalf("expected one streaming update per content delta, got %d", streamingCount)
+	}
+	if result.Reasoning != "Need the weather." || result.ReasoningSignature != "sig-1" || result.Data != "Checking" || strings.Join(result.RedactedReasoning, ",") != "encrypted-1" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if len(result.ToolCalls) != 1 || result.ToolCalls[0].Id != "toolu_1" || result.ToolCalls[0].Arguments["city"] != "Paris" || result.ToolCalls[0].Status != common.ToolCallStatusPending {
+		t.Fatalf("unexpected tool calls: %+v", result.ToolCalls)
+	}
+}
+
+func TestAnthropicProviderConvertToolLoop(t *testing.T) {
+	provider := &AnthropicProvider{}
+	system, messages := provider.convertConversations(context.Background(), []common.Conversation{
+		{Role: common.ConversationRoleSystem, Text: "Be brief"},
+		{Role: common.ConversationRoleUser, Text: "Weather in Paris and Rome?"},
+		{Role: common.ConversationRoleAssistant, Text: "Checking", Reasoning: "Two cities.", ReasoningSignature: "sig-1", RedactedReasoning: []string{"encrypted-1"}},
+		{Role: common.ConversationRoleTool, ToolCallInfo: common.ToolCallInfo{Id: "toolu_1", Name: "get_weather", Arguments: map[string]any{"city": "Paris"}, Response: "sunny", Status: common.ToolCallStatusSucceeded}},
+		{Role: common.ConversationRoleTool, ToolCallInfo: common.ToolCallInfo{Id: "toolu_2", Name: "get_weather", Arguments: map[string]any{"city": "Rome"}, Response: "timeout", Status: common.ToolCallStatusFailed}},
+	})
+
+	if len(system) != 1 || system[0].Text != "Be brief" {
+		t.Fatalf("unexpected system blocks: %+v", system)
+	}
+	if len(messages) != 3 {
+		t.Fatalf("expected user, assistant, tool result turns, got %+v", messages)
+	}
+
+	assistant := messages[1]
+	blockTypes := []string{}
+	for _, block := range assistant.Content {
+		blockTypes = append(blockTypes, block.Type)
+	}
+	if assistant.Role != "assistant" || strings.Join(blockTypes, ",") != "thinking,redacted_thinking,text,tool_use,tool_use" || assistant.Content[0].Signature != "sig-1" || assistant.Content[1].Data != "encrypted-1" {
+		t.Fatalf("unexpected assistant turn: %+v", assistant)
+	}
+
+	toolResults := messages[2]
+	if toolResults.Role != "user" || len(toolResults.Content) != 2 || toolResults.Content[0].ToolUseId != "toolu_1" || toolResults.Content[0].IsError || !toolResults.Content[1].IsError {
+		t.Fatalf("unexpected tool result turn: %+v", toolResults)
+	}
+	if !lastToolUseTurnHasThinking(messages) {
+		t.Fatalf("expected signed thinking to allow thinking mode")
+	}
+
+	// Without a signature the reasoning cannot be replayed, so thinking has to
+	// stay off for the rest of this tool loop.
+	messages[1].Content = messages[1].Content[2:]
+	if lastToolUseTurnHasThinking(messages) {
+		t.Fatalf("expected unsigned tool loop to disable thinking mode")
+	}
+}
+
+func TestAnthropicProviderErrorResponse(t *testing.T) {
+	provider := newAnthropicTestServer(t, func(w http.ResponseWriter, request anthropicMessagesRequest) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens: too large"}}`)
+	})
+
+	_, err := provider.ChatStream(context.Background(), common.Model{Name: "claude-test"}, []common.Conversation{
+		{Role: common.ConversationRoleUser, Text: "hi"},
+	}, common.ChatOptions{})
+	if err == nil || !strings.Contains(err.Error(), "max_tokens: too large") {
+		t.Fatalf("expected api error message, got %v", err)
+	}
+}
+
+func TestAnthropicProviderStreamError(t *testing.T) {
+	provider := newAnthropicTestServer(t, func(w http.ResponseWriter, request anthropicMessagesRequest) {
+		writeAnthropicEvents(w,
+			`{"type":"message_start","message":{"id":"msg_1"}}`,
+			`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+		)
+	})
+
+	stream, err := provider.ChatStream(context.Background(), common.Model{Name: "claude-test"}, []common.Conversation{
+		{Role: common.ConversationRoleUser, Text: "hi"},
+	}, common.ChatOptions{})
+	if err != nil {
+		t.Fatalf("chat stream: %v", err)
+	}
+	if _, err := stream.Receive(context.Background()); err == nil || !strings.Contains(err.Error(), "Overloaded") {
+		t.Fatalf("expected overloaded stream error, got %v", err)
+	}
+}
+
+func TestAnthropicProviderStreamClosedBeforeMessageStop(t *testing.T) {
+	provider := newAnthropicTestServer(t, func(w http.ResponseWriter, request anthropicMessagesRequest) {
+		writeAnthropicEvents(w,
+			`{"type":"message_start","message":{"id":"msg_1"}}`,
+			`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Half an ans"}}`,
+		)
+	})
+
+	stream, err := provider.ChatStream(context.Background(), common.Model{Name: "claude-test"}, []common.Conversation{
+		{Role: common.ConversationRoleUser, Text: "hi"},
+	}, common.ChatOptions{})
+	if err != nil {
+		t.Fatalf("chat stream: %v", err)
+	}
+	if data, err := stream.Receive(context.Background()); err != nil || data.Data != "Half an ans" {
+		t.Fatalf("expected the streamed text first, got %+v err=%v", data, err)
+	}
+	if _, err := stream.Receive(context.Background()); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected unexpected EOF for a truncated stream, got %v", err)
+	}
+}
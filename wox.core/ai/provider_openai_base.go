@@ -152,6 +152,38 @@ func (o *OpenAIBaseProvider) Ping(ctx context.Context) error {
 	return err
 }
 
+// Embed calls the OpenAI compatible /embeddings endpoint, which Ollama serves as well.
+func (o *OpenAIBaseProvider) Embed(ctx context.Context, model string, inputs []string) ([][]float32, error) {
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+	client := o.getClient(ctx)
+	response, err := client.Embeddings.New(ctx, openai.EmbeddingNewParams{
+		Model:          model,
+		Input:          openai.EmbeddingNewParamsInputUnion{OfArrayOfStrings: inputs},
+		EncodingFormat: openai.EmbeddingNewParamsEncodingFormatFloat,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Data) != len(inputs) {
+		return nil, fmt.Errorf("embedding response has %d vectors for %d inputs", len(response.Data), len(inputs))
+	}
+
+	vectors := make([][]float32, len(inputs))
+	for _, embedding := range response.Data {
+		if embedding.Index < 0 || int(embedding.Index) >= len(inputs) {
+			return nil, fmt.Errorf("embedding response has invalid index %d", embedding.Index)
+		}
+		vector := make([]float32, len(embedding.Embedding))
+		for i, value := range embedding.Embedding {
+			vector[i] = float32(value)
+		}
+		vectors[embedding.Index] = vector
+	}
+	return vectors, nil
+}
+
 func (o *OpenAIBaseProvider) convertTools(tools []common.Tool) []openai.ChatCompletionToolUnionParam {
 	/*
 		{
@@ -226,7 +258,7 @@ func (s *OpenAIBaseProviderStream) Receive(ctx context.Context) (common.ChatStre
 				// try to unmarshal tool call arguments if possible
 				var argsMap map[string]any
 				if err := json.Unmarshal([]byte(toolCall.Function.Arguments), &argsMap); err == nil {
-					toolCallInfo.Arguments = s.normalizeArguments(ctx, toolCall.Function.Name, argsMap)
+					toolCallInfo.Arguments = normalizeToolCallArguments(ctx, toolCall.Function.Name, argsMap)
 					toolCallInfo.Status = common.ToolCallStatusPending
 				} else {
 					util.GetLogger().Error(ctx, fmt.Sprintf("AI: Failed to unmarshal tool call arguments, json=%s, err: %s", toolCall.Function.Arguments, err.Error()))
@@ -490,7 +522,7 @@ func (s *OpenAIBaseProviderStream) contentMarkerPrefixLength(content string, mar
 	return maxPrefixLength
 }
 
-// normalizeArguments normalizes the tool call arguments
+// normalizeToolCallArguments normalizes the tool call arguments
 // Case 1:
 //
 //		because we unmarshal the tool call arguments as map[string]any, some types are not correct, E.g. int64 will be unmarshaled as float64
@@ -505,7 +537,7 @@ func (s *OpenAIBaseProviderStream) contentMarkerPrefixLength(content string, mar
 // Case 3:
 //
 //	sometimes required arguments are not provided, so we need to add them to the arguments
-func (s *OpenAIBaseProviderStream) normalizeArguments(ctx context.Context, toolName string, argsMap map[string]any) map[string]any {
+func normalizeToolCallArguments(ctx context.Context, toolName string, argsMap map[string]any) map[string]any {
 	util.GetLogger().Debug(ctx, fmt.Sprintf("AI: Start normalizing tool call arguments for tool: %s, args: %v", toolName, argsMap))
 
 	var tool common.Tool
@@ -524,7 +556,7 @@ func (s *OpenAIBaseProviderStream) normalizeArguments(ctx context.Context, toolN
 			// name sometimes is not the same as the tool call argument name, so we need to map the name to the tool call argument name
 			// E.g. sequenceNumber -> sequence_number
 			for aiReturnName, value := range argsMap {
-				if s.isToolCallArgumentNameSame(toolRequiredName, aiReturnName) {
+				if isToolCallArgumentNameSame(toolRequiredName, aiReturnName) {
 					if f, ok := value.(float64); ok {
 						argsMap[toolRequiredName] = int64(f)
 						util.GetLogger().Debug(ctx, fmt.Sprintf("AI: argument type fixed %s, from float to int", toolRequiredName))
@@ -565,7 +597,7 @@ func (s *OpenAIBaseProviderStream) normalizeArguments(ctx context.Context, toolN
 	return argsMap
 }
 
-func (s *OpenAIBaseProviderStream) isToolCallArgumentNameSame(toolRequiredName string, aiReturnName string) bool {
+func isToolCallArgumentNameSame(toolRequiredName string, aiReturnName string) bool {
 	if strings.EqualFold(toolRequiredName, aiReturnName) {
 		return true
 	}
@@ -0,0 +1,350 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+	"wox/database"
+	"wox/setting"
+	"wox/util"
+)
+
+const (
+	semanticIndexInterval = 10 * time.Minute
+	// semanticIndexDebounce groups bursts of changes, e.g. a streaming chat
+	// reply or a clipboard paste storm, into one indexing pass.
+	semanticIndexDebounce  = 5 * time.Second
+	semanticEmbedBatchSize = 32
+	// semanticMaxTextRunes keeps documents within the input limit of common
+	// embedding models. The start of a document carries most of its meaning.
+	semanticMaxTextRunes = 4000
+	// semanticMinScore drops results that share little more than the language
+	// with the query.
+	semanticMinScore = float32(0.3)
+)
+
+// ErrSemanticSearchUnavailable is returned when no AI provider has an embedding model.
+var ErrSemanticSearchUnavailable = errors.New("semantic search needs an embedding model in AI provider settings")
+
+// ErrSemanticSourceNotReady is returned by a source whose document list is
+// temporarily incomplete. The source is skipped so its vectors are kept.
+var ErrSemanticSourceNotReady = errors.New("semantic source is not ready")
+
+// SemanticDocument is one piece of Wox data that can be found by meaning.
+type SemanticDocument struct {
+	Id string
+	// Hash identifies the current content. When empty it is computed from Text.
+	// Sources whose text is expensive to read set Hash and LoadText instead, so
+	// unchanged documents are never read again.
+	Hash     string
+	Text     string
+	LoadText func(ctx context.Context) (string, error)
+}
+
+// SemanticSource lists every document that should be searchable. Documents
+// missing from the list are removed from the index.
+type SemanticSource struct {
+	Name      string
+	Documents func(ctx context.Context) ([]SemanticDocument, error)
+}
+
+// SemanticHit is one document matched by Search, best first.
+type SemanticHit struct {
+	Id    string
+	Score float32
+}
+
+// Embedder turns text into vectors. Model names the vector space, so vectors
+// of different models are never compared.
+type Embedder interface {
+	Model() string
+	Embed(ctx context.Context, inputs []string) ([][]float32, error)
+}
+
+// SemanticIndex embeds registered sources in the background and answers
+// semantic queries from the local vector store.
+type SemanticIndex struct {
+	store   *database.AIEmbeddingStore
+	resolve func(ctx context.Context) (Embedder, error)
+
+	sourcesMutex sync.RWMutex
+	sources      []SemanticSource
+	indexMutex   sync.Mutex
+	refresh      chan struct{}
+}
+
+var semanticIndexInstance *SemanticIndex
+var semanticIndexOnce sync.Once
+
+// GetSemanticIndex returns the index backed by wox.db and the embedding model from AI provider settings.
+func GetSemanticIndex() *SemanticIndex {
+	semanticIndexOnce.Do(func() {
+		semanticIndexInstance = NewSemanticIndex(database.NewAIEmbeddingStore(database.GetDB()), resolveSettingEmbedder)
+	})
+	return semanticIndexInstance
+}
+
+func NewSemanticIndex(store *database.AIEmbeddingStore, resolve func(ctx context.Context) (Embedder, error)) *SemanticIndex {
+	return &SemanticIndex{
+		store:   store,
+		resolve: resolve,
+		refresh: make(chan struct{}, 1),
+	}
+}
+
+// RegisterSource adds a source, replacing a source with the same name.
+func (s *SemanticIndex) RegisterSource(source SemanticSource) {
+	s.sourcesMutex.Lock()
+	defer s.sourcesMutex.Unlock()
+	for i := range s.sources {
+		if s.sources[i].Name == source.Name {
+			s.sources[i] = source
+			return
+		}
+	}
+	s.sources = append(s.sources, source)
+}
+
+// Start indexes all sources now, after every Refresh and every semanticIndexInterval.
+func (s *SemanticIndex) Start(ctx context.Context) {
+	util.Go(ctx, "semantic index", func() {
+		ticker := time.NewTicker(semanticIndexInterval)
+		defer ticker.Stop()
+		for {
+			if err := s.IndexAll(ctx); err != nil && !errors.Is(err, ErrSemanticSearchUnavailable) {
+				util.GetLogger().Warn(ctx, fmt.Sprintf("semantic index: %s", err.Error()))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-s.refresh:
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(semanticIndexDebounce):
+				}
+			}
+		}
+	})
+}
+
+// Refresh asks the background loop to index again soon. It never blocks.
+func (s *SemanticIndex) Refresh() {
+	select {
+	case s.refresh <- struct{}{}:
+	default:
+	}
+}
+
+// IndexAll brings the vectors of every source up to date with the current model.
+func (s *SemanticIndex) IndexAll(ctx context.Context) error {
+	embedder, err := s.resolve(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.indexMutex.Lock()
+	defer s.indexMutex.Unlock()
+
+	if err := s.store.DeleteEmbeddingsExceptModel(ctx, embedder.Model()); err != nil {
+		return err
+	}
+	s.sourcesMutex.RLock()
+	sources := append([]SemanticSource(nil), s.sources...)
+	s.sourcesMutex.RUnlock()
+
+	var errs []error
+	for _, source := range sources {
+		if err := s.indexSource(ctx, embedder, source); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", source.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *SemanticIndex) indexSource(ctx context.Context, embedder Embedder, source SemanticSource) error {
+	documents, err := source.Documents(ctx)
+	if errors.Is(err, ErrSemanticSourceNotReady) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	stored, err := s.store.ListEmbeddingHashes(ctx, source.Name, embedder.Model())
+	if err != nil {
+		return err
+	}
+
+	var pending []SemanticDocument
+	current := make(map[string]bool, len(documents))
+	for _, document := range documents {
+		if document.Id == "" || current[document.Id] {
+			continue
+		}
+		current[document.Id] = true
+		if document.Hash == "" {
+			document.Hash = semanticTextHash(document.Text)
+		}
+		if stored[document.Id] != document.Hash {
+			pending = append(pending, document)
+		}
+	}
+	var removed []string
+	for id := range stored {
+		if !current[id] {
+			removed = append(removed, id)
+		}
+	}
+	if err := s.store.DeleteEmbeddings(ctx, source.Name, removed); err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	embedded := 0
+	for start := 0; start < len(pending); start += semanticEmbedBatchSize {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		batch := pending[start:min(start+semanticEmbedBatchSize, len(pending))]
+		documentsToEmbed := make([]SemanticDocument, 0, len(batch))
+		texts := make([]string, 0, len(batch))
+		for _, document := range batch {
+			text := document.Text
+			if document.LoadText != nil {
+				text, err = document.LoadText(ctx)
+				if err != nil {
+					util.GetLogger().Debug(ctx, fmt.Sprintf("semantic index: skip %s/%s: %s", source.Name, document.Id, err.Error()))
+					continue
+				}
+			}
+			text = truncateSemanticText(text)
+			if text == "" {
+				continue
+			}
+			documentsToEmbed = append(documentsToEmbed, document)
+			texts = append(texts, text)
+		}
+		if len(texts) == 0 {
+			continue
+		}
+
+		vectors, err := embedder.Embed(ctx, texts)
+		if err != nil {
+			return err
+		}
+		if len(vectors) != len(texts) {
+			return fmt.Errorf("embedder returned %d vectors for %d texts", len(vectors), len(texts))
+		}
+		now := util.GetSystemTimestamp()
+		for i, document := range documentsToEmbed {
+			if err := s.store.SaveEmbedding(ctx, source.Name, document.Id, embedder.Model(), document.Hash, vectors[i], now); err != nil {
+				return err
+			}
+			embedded++
+		}
+	}
+	util.GetLogger().Info(ctx, fmt.Sprintf("semantic index: embedded %d documents of %s, removed %d", embedded, source.Name, len(removed)))
+	return nil
+}
+
+// Search returns the documents of a source closest in meaning to the query.
+func (s *SemanticIndex) Search(ctx context.Context, sourceName string, query string, limit int) ([]SemanticHit, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, nil
+	}
+	embedder, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	vectors, err := embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("embedder returned %d vectors for the query", len(vectors))
+	}
+
+	storeHits, err := s.store.SearchEmbeddings(ctx, sourceName, embedder.Model(), vectors[0], semanticMinScore, limit)
+	if err != nil {
+		return nil, err
+	}
+	hits := make([]SemanticHit, 0, len(storeHits))
+	for _, hit := range storeHits {
+		hits = append(hits, SemanticHit{Id: hit.DocumentID, Score: hit.Score})
+	}
+	return hits, nil
+}
+
+// ParseSemanticQuery reports whether a query asks for semantic search, which
+// is written as "~ meaning" or "~meaning". "~/path" and "~\path" stay home
+// directory paths.
+func ParseSemanticQuery(search string) (string, bool) {
+	search = strings.TrimSpace(search)
+	rest, found := strings.CutPrefix(search, "~")
+	if !found || strings.HasPrefix(rest, "/") || strings.HasPrefix(rest, `\`) {
+		return "", false
+	}
+	rest = strings.TrimSpace(rest)
+	return rest, rest != ""
+}
+
+func semanticTextHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+func truncateSemanticText(text string) string {
+	text = strings.TrimSpace(text)
+	if utf8.RuneCountInString(text) <= semanticMaxTextRunes {
+		return text
+	}
+	return string([]rune(text)[:semanticMaxTextRunes])
+}
+
+type providerEmbedder struct {
+	provider EmbeddingProvider
+	model    string
+	key      string
+}
+
+func (e *providerEmbedder) Model() string {
+	return e.key
+}
+
+func (e *providerEmbedder) Embed(ctx context.Context, inputs []string) ([][]float32, error) {
+	return e.provider.Embed(ctx, e.model, inputs)
+}
+
+// resolveSettingEmbedder uses the first AI provider with an embedding model.
+func resolveSettingEmbedder(ctx context.Context) (Embedder, error) {
+	for _, providerSetting := range setting.GetSettingManager().GetWoxSetting(ctx).AIProviders.Get() {
+		model := strings.TrimSpace(providerSetting.EmbeddingModel)
+		if model == "" {
+			continue
+		}
+		provider, err := NewProvider(ctx, providerSetting)
+		if err != nil {
+			return nil, err
+		}
+		embeddingProvider, ok := provider.(EmbeddingProvider)
+		if !ok {
+			return nil, fmt.Errorf("AI provider %s does not support embeddings", providerSetting.Name)
+		}
+		return &providerEmbedder{
+			provider: embeddingProvider,
+			model:    model,
+			key:      fmt.Sprintf("%s/%s/%s", providerSetting.Name, providerSetting.Alias, model),
+		}, nil
+	}
+	return nil, ErrSemanticSearchUnavailable
+}
@@ -0,0 +1,191 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"wox/common"
+)
+
+// toolsAskingByDefault are builtin tools that change the machine, so they need
+// the user's approval unless a rule or allowlist says otherwise.
+var toolsAskingByDefault = map[string]bool{
+	"bash":  true,
+	"write": true,
+	"edit":  true,
+}
+
+// bashChainOperators are never auto-approved by a command prefix, otherwise an
+// allowed "git status" could be followed by anything.
+var bashChainOperators = []string{";", "&", "|", "`", "$", ">", "<", "\n", "\r"}
+
+// EvaluateToolApproval decides whether a tool call may run. An ask decision
+// means the caller has to get the user's approval before running it.
+//
+// Precedence: a rule for the tool name, then the MCP server policy, then the
+// default (ask for bash, write, edit and MCP tools, allow for the rest).
+// Allowlisted command prefixes and path roots only turn ask into allow.
+func EvaluateToolApproval(tool common.Tool, arguments map[string]any, rules []common.AIToolApprovalRule) common.AIToolApprovalDecision {
+	decision := common.AIToolApprovalDecision{Policy: common.AIToolApprovalPolicyAllow, Source: common.AIToolApprovalSourceDefault}
+	if tool.Source == common.ToolSourceMCP || toolsAskingByDefault[tool.Name] {
+		decision.Policy = common.AIToolApprovalPolicyAsk
+	}
+	if tool.Source == common.ToolSourceMCP && tool.ServerConfig != nil && tool.ServerConfig.ApprovalPolicy != "" {
+		decision.Policy = normalizeToolApprovalPolicy(tool.ServerConfig.ApprovalPolicy)
+		decision.Source = common.AIToolApprovalSourceMCPServer
+		decision.Reason = fmt.Sprintf("MCP server %q policy", tool.ServerConfig.Name)
+	}
+
+	rule, hasRule := findToolApprovalRule(rules, tool.Name)
+	if hasRule && rule.Policy != "" {
+		decision.Policy = normalizeToolApprovalPolicy(rule.Policy)
+		decision.Source = common.AIToolApprovalSourceRule
+		decision.Reason = fmt.Sprintf("rule for tool %q", tool.Name)
+	}
+	if !hasRule || decision.Policy != common.AIToolApprovalPolicyAsk || tool.Source == common.ToolSourceMCP {
+		return decision
+	}
+
+	switch tool.Name {
+	case "bash":
+		command, _ := arguments["command"].(string)
+		if prefix, ok := matchAllowedCommandPrefix(command, rule.CommandPrefixes); ok {
+			return common.AIToolApprovalDecision{
+				Policy: common.AIToolApprovalPolicyAllow,
+				Source: common.AIToolApprovalSourceAllowlist,
+				Reason: fmt.Sprintf("command matches allowed prefix %q", prefix),
+			}
+		}
+	case "write", "edit":
+		path, _ := arguments["path"].(string)
+		if root, ok := matchAllowedPathRoot(path, rule.PathRoots); ok {
+			return common.AIToolApprovalDecision{
+				Policy: common.AIToolApprovalPolicyAllow,
+				Source: common.AIToolApprovalSourceAllowlist,
+				Reason: fmt.Sprintf("path is inside allowed root %q", root),
+			}
+		}
+	}
+	return decision
+}
+
+func findToolApprovalRule(rules []common.AIToolApprovalRule, toolName string) (common.AIToolApprovalRule, bool) {
+	for _, rule := range rules {
+		if strings.EqualFold(strings.TrimSpace(rule.Tool), toolName) {
+			return rule, true
+		}
+	}
+	return common.AIToolApprovalRule{}, false
+}
+
+// normalizeToolApprovalPolicy treats unknown values as ask so a typo in the
+// settings never silently allows a tool.
+func normalizeToolApprovalPolicy(policy common.AIToolApprovalPolicy) common.AIToolApprovalPolicy {
+	switch policy {
+	case common.AIToolApprovalPolicyAllow, common.AIToolApprovalPolicyDeny:
+		return policy
+	default:
+		return common.AIToolApprovalPolicyAsk
+	}
+}
+
+// matchAllowedCommandPrefix matches whole words, so the prefix "git status"
+// allows "git status --short" but not "git statusx".
+func matchAllowedCommandPrefix(command string, prefixes []string) (string, bool) {
+	for _, operator := range bashChainOperators {
+		if strings.Contains(command, operator) {
+			return "", false
+		}
+	}
+
+	commandWords := strings.Fields(command)
+	for _, prefix := range prefixes {
+		prefixWords := strings.Fields(prefix)
+		if len(prefixWords) == 0 || len(prefixWords) > len(commandWords) {
+			continue
+		}
+		if strings.Join(commandWords[:len(prefixWords)], " ") == strings.Join(prefixWords, " ") {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+// matchAllowedPathRoot resolves symlinks on both sides, so a link inside an
+// allowed root cannot be used to write outside of it.
+func matchAllowedPathRoot(path string, roots []string) (string, bool) {
+	if strings.TrimSpace(path) == "" {
+		return "", false
+	}
+
+	target := evalToolPathSymlinks(ResolveToolPath(path))
+	for _, root := range roots {
+		if strings.TrimSpace(root) == "" {
+			continue
+		}
+		rel, err := filepath.Rel(evalToolPathSymlinks(ResolveToolPath(root)), target)
+		if err != nil {
+			continue
+		}
+		if rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))) {
+			return root, true
+		}
+	}
+	return "", false
+}
+
+// evalToolPathSymlinks resolves the longest existing part of path, because the
+// file a tool is about to write usually does not exist yet.
+func evalToolPathSymlinks(path string) string {
+	existing := path
+	var missing []string
+	for {
+		resolved, err := filepath.EvalSymlinks(existing)
+		if err == nil {
+			return filepath.Join(append([]string{resolved}, missing...)...)
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return path
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return path
+		}
+		missing = append([]string{filepath.Base(existing)}, missing...)
+		existing = parent
+	}
+}
+
+// ResolveToolPath resolves a path relative to the process working directory.
+// It expands ~ to the home directory and handles absolute paths directly.
+func ResolveToolPath(input string) string {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		input = "."
+	}
+
+	if strings.HasPrefix(input, "~") {
+		home, err := os.UserHomeDir()
+		if err == nil {
+			if input == "~" {
+				return home
+			}
+			if strings.HasPrefix(input, "~/") {
+				return filepath.Join(home, input[2:])
+			}
+		}
+	}
+
+	if filepath.IsAbs(input) {
+		return filepath.Clean(input)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return filepath.Clean(input)
+	}
+	return filepath.Clean(filepath.Join(cwd, input))
+}
@@ -0,0 +1,110 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"wox/common"
+)
+
+func TestEvaluateToolApprovalDefaults(t *testing.T) {
+	mcpServer := &common.AIChatMCPServerConfig{Name: "github"}
+	cases := []struct {
+		name   string
+		tool   common.Tool
+		policy common.AIToolApprovalPolicy
+	}{
+		{"bash asks", common.Tool{Name: "bash", Source: common.ToolSourceBuiltin}, common.AIToolApprovalPolicyAsk},
+		{"write asks", common.Tool{Name: "write", Source: common.ToolSourceBuiltin}, common.AIToolApprovalPolicyAsk},
+		{"read is allowed", common.Tool{Name: "read", Source: common.ToolSourceBuiltin}, common.AIToolApprovalPolicyAllow},
+		{"mcp asks", common.Tool{Name: "create_issue", Source: common.ToolSourceMCP, ServerConfig: mcpServer}, common.AIToolApprovalPolicyAsk},
+	}
+	for _, c := range cases {
+		decision := EvaluateToolApproval(c.tool, nil, nil)
+		if decision.Policy != c.policy || decision.Source != common.AIToolApprovalSourceDefault {
+			t.Fatalf("%s: got %+v, want %s from default", c.name, decision, c.policy)
+		}
+	}
+}
+
+func TestEvaluateToolApprovalPrecedence(t *testing.T) {
+	server := &common.AIChatMCPServerConfig{Name: "github", ApprovalPolicy: common.AIToolApprovalPolicyAllow}
+	tool := common.Tool{Name: "delete_repo", Source: common.ToolSourceMCP, ServerConfig: server}
+
+	decision := EvaluateToolApproval(tool, nil, nil)
+	if decision.Policy != common.AIToolApprovalPolicyAllow || decision.Source != common.AIToolApprovalSourceMCPServer {
+		t.Fatalf("expected server policy to allow, got %+v", decision)
+	}
+
+	rules := []common.AIToolApprovalRule{{Tool: "Delete_Repo", Policy: common.AIToolApprovalPolicyDeny}}
+	decision = EvaluateToolApproval(tool, nil, rules)
+	if decision.Policy != common.AIToolApprovalPolicyDeny || decision.Source != common.AIToolApprovalSourceRule {
+		t.Fatalf("expected tool rule to override server policy, got %+v", decision)
+	}
+
+	rules = []common.AIToolApprovalRule{{Tool: "bash", Policy: "sometimes"}}
+	decision = EvaluateToolApproval(common.Tool{Name: "bash", Source: common.ToolSourceBuiltin}, nil, rules)
+	if decision.Policy != common.AIToolApprovalPolicyAsk {
+		t.Fatalf("expected unknown policy to ask, got %+v", decision)
+	}
+}
+
+func TestEvaluateToolApprovalCommandPrefixes(t *testing.T) {
+	bash := common.Tool{Name: "bash", Source: common.ToolSourceBuiltin}
+	rules := []common.AIToolApprovalRule{{Tool: "bash", Policy: common.AIToolApprovalPolicyAsk, CommandPrefixes: []string{"git status", "ls"}}}
+
+	cases := map[string]common.AIToolApprovalPolicy{
+		"git status":              common.AIToolApprovalPolicyAllow,
+		"  git   status --short ": common.AIToolApprovalPolicyAllow,
+		"ls -la":                  common.AIToolApprovalPolicyAllow,
+		"git statusx":             common.AIToolApprovalPolicyAsk,
+		"git push":                common.AIToolApprovalPolicyAsk,
+		"ls; rm -rf ~":            common.AIToolApprovalPolicyAsk,
+		"ls && rm -rf ~":          common.AIToolApprovalPolicyAsk,
+		"ls $(rm -rf ~)":          common.AIToolApprovalPolicyAsk,
+		"ls > /etc/hosts":         common.AIToolApprovalPolicyAsk,
+		"ls\nrm -rf ~":            common.AIToolApprovalPolicyAsk,
+	}
+	for command, policy := range cases {
+		decision := EvaluateToolApproval(bash, map[string]any{"command": command}, rules)
+		if decision.Policy != policy {
+			t.Fatalf("command %q: got %+v, want %s", command, decision, policy)
+		}
+	}
+
+	// A deny rule is never turned into allow by its allowlist.
+	rules[0].Policy = common.AIToolApprovalPolicyDeny
+	if decision := EvaluateToolApproval(bash, map[string]any{"command": "ls"}, rules); decision.Policy != common.AIToolApprovalPolicyDeny {
+		t.Fatalf("expected deny rule to win over allowlist, got %+v", decision)
+	}
+}
+
+func TestEvaluateToolApprovalPathRoots(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	allowed := filepath.Join(root, "project")
+	if err := os.MkdirAll(allowed, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(allowed, "escape")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	write := common.Tool{Name: "write", Source: common.ToolSourceBuiltin}
+	rules := []common.AIToolApprovalRule{{Tool: "write", PathRoots: []string{allowed}}}
+
+	cases := map[string]common.AIToolApprovalPolicy{
+		filepath.Join(allowed, "main.go"):                common.AIToolApprovalPolicyAllow,
+		filepath.Join(allowed, "new", "dir", "file.txt"): common.AIToolApprovalPolicyAllow,
+		filepath.Join(allowed, "..", "secret.txt"):       common.AIToolApprovalPolicyAsk,
+		filepath.Join(root, "project-other", "file.txt"): common.AIToolApprovalPolicyAsk,
+		filepath.Join(allowed, "escape", "file.txt"):     common.AIToolApprovalPolicyAsk,
+		"": common.AIToolApprovalPolicyAsk,
+	}
+	for path, policy := range cases {
+		decision := EvaluateToolApproval(write, map[string]any{"path": path}, rules)
+		if decision.Policy != policy {
+			t.Fatalf("path %q: got %+v, want %s", path, decision, policy)
+		}
+	}
+}
@@ -21,20 +21,41 @@ func initCloudSync(ctx context.Context) {
 	accountService.StartTokenRefresh(ctx)
 	deviceProvider := cloudsync.NewDatabaseDeviceProvider()
 
-	client, err := cloudsync.NewCloudSyncHTTPClient(cloudsync.CloudSyncHTTPClientConfig{
-		BaseURL:        baseURL,
-		AuthProvider:   accountService,
-		DeviceProvider: deviceProvider,
-		AppVersion:     updater.CURRENT_VERSION,
-		Platform:       util.GetCurrentPlatform(),
-	})
-	if err != nil {
-		util.GetLogger().Error(ctx, "cloud sync init failed: "+err.Error())
-		return
+	backend := resolveCloudSyncBackend(ctx)
+	var client cloudsync.CloudSyncClient
+	var keyClient cloudsync.CloudSyncKeyClient
+	var deviceClient cloudsync.CloudSyncDeviceClient
+	var httpClient *cloudsync.CloudSyncHTTPClient
+	autoSyncAllowed := cloudSyncAutoSyncAllowedFromAccount
+	if backend.IsSelfHosted() {
+		transport, err := cloudsync.NewCloudSyncTransport(backend)
+		if err != nil {
+			util.GetLogger().Error(ctx, "cloud sync init failed: "+err.Error())
+			return
+		}
+		storageClient := cloudsync.NewCloudSyncStorageClient(transport)
+		client, keyClient, deviceClient = storageClient, storageClient, storageClient
+		// Self-hosted storage has no plan limits, so scheduling only depends on the local bootstrap.
+		autoSyncAllowed = func(context.Context) bool { return true }
+		util.GetLogger().Info(ctx, "cloud sync uses self-hosted backend: "+backend.Type)
+	} else {
+		var err error
+		httpClient, err = cloudsync.NewCloudSyncHTTPClient(cloudsync.CloudSyncHTTPClientConfig{
+			BaseURL:        baseURL,
+			AuthProvider:   accountService,
+			DeviceProvider: deviceProvider,
+			AppVersion:     updater.CURRENT_VERSION,
+			Platform:       util.GetCurrentPlatform(),
+		})
+		if err != nil {
+			util.GetLogger().Error(ctx, "cloud sync init failed: "+err.Error())
+			return
+		}
+		client, keyClient, deviceClient = httpClient, httpClient, httpClient
 	}
 
 	keyManager := cloudsync.NewKeyManager(cloudsync.KeyManagerConfig{
-		KeyClient:      client,
+		KeyClient:      keyClient,
 		DeviceProvider: deviceProvider,
 	})
 	historyStore := cloudsync.NewDefaultCloudSyncHistoryStore()
@@ -50,16 +71,17 @@ func initCloudSync(ctx context.Context) {
 		ExclusionProvider: settingadapter.NewCloudSyncPluginExclusionProvider(),
 		SettingReloader:   cloudSyncUISettingReloader{},
 		HistoryStore:      historyStore,
-		AutoSyncAllowed:   cloudSyncAutoSyncAllowedFromAccount,
+		AutoSyncAllowed:   autoSyncAllowed,
 	})
 
 	service := &cloudsync.Service{
 		Manager:        manager,
-		Client:         client,
-		DeviceClient:   client,
+		Client:         httpClient,
+		DeviceClient:   deviceClient,
 		KeyManager:     keyManager,
 		DeviceProvider: deviceProvider,
 		HistoryStore:   historyStore,
+		Backend:        backend,
 	}
 	cloudsync.SetService(service)
 }
@@ -114,3 +136,13 @@ func resolveCloudSyncBaseURL(ctx context.Context) string {
 
 	return strings.TrimRight(configuredURL, "/")
 }
+
+// resolveCloudSyncBackend returns the local backend choice. Changing it takes
+// effect on the next start because the sync manager keeps its client.
+func resolveCloudSyncBackend(ctx context.Context) cloudsync.CloudSyncBackendConfig {
+	settingManager := setting.GetSettingManager()
+	if settingManager == nil {
+		return cloudsync.CloudSyncBackendConfig{}
+	}
+	return settingManager.GetWoxSetting(ctx).CloudSyncBackend.Get()
+}
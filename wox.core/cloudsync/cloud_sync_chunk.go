@@ -0,0 +1,315 @@
+package cloudsync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+	"wox/database"
+	"wox/util"
+)
+
+// Large data entity values are pushed as numbered chunk records followed by a
+// manifest under the original key. The manifest names the chunk version, so
+// chunks left over from an older, longer value are never stitched in, and a
+// delete of the original key orphans every chunk at once.
+const (
+	cloudSyncChunkKeySeparator   = "#chunk/"
+	cloudSyncChunkManifestPrefix = "wox-sync-chunks:"
+)
+
+type cloudSyncChunk struct {
+	Version string `json:"version"`
+	Index   int    `json:"index"`
+	Data    string `json:"data"`
+}
+
+type cloudSyncChunkManifest struct {
+	Version string `json:"version"`
+	Count   int    `json:"count"`
+}
+
+func cloudSyncChunkKey(key string, index int) string {
+	return key + cloudSyncChunkKeySeparator + strconv.Itoa(index)
+}
+
+// splitCloudSyncChunkKey returns the original key of a chunk record.
+func splitCloudSyncChunkKey(key string) (string, bool) {
+	index := strings.LastIndex(key, cloudSyncChunkKeySeparator)
+	if index <= 0 {
+		return "", false
+	}
+	if _, err := strconv.Atoi(key[index+len(cloudSyncChunkKeySeparator):]); err != nil {
+		return "", false
+	}
+	return key[:index], true
+}
+
+func isCloudSyncChunkKey(key string) bool {
+	_, ok := splitCloudSyncChunkKey(key)
+	return ok
+}
+
+// buildCloudSyncChunks splits value into chunk plaintexts of at most size
+// bytes of data each and returns them with the manifest plaintext.
+func buildCloudSyncChunks(value string, size int) ([]string, string, error) {
+	sum := sha256.Sum256([]byte(value))
+	version := hex.EncodeToString(sum[:8])
+
+	parts := splitCloudSyncChunkData(value, size)
+	chunks := make([]string, 0, len(parts))
+	for index, part := range parts {
+		encoded, err := json.Marshal(cloudSyncChunk{Version: version, Index: index, Data: part})
+		if err != nil {
+			return nil, "", err
+		}
+		chunks = append(chunks, string(encoded))
+	}
+	manifest, err := json.Marshal(cloudSyncChunkManifest{Version: version, Count: len(parts)})
+	if err != nil {
+		return nil, "", err
+	}
+	return chunks, cloudSyncChunkManifestPrefix + string(manifest), nil
+}
+
+// splitCloudSyncChunkData cuts on rune boundaries so every chunk stays valid UTF-8 inside JSON.
+func splitCloudSyncChunkData(value string, size int) []string {
+	var parts []string
+	for len(value) > size {
+		end := size
+		for end > 0 && !utf8.RuneStart(value[end]) {
+			end--
+		}
+		if end == 0 {
+			end = size
+		}
+		parts = append(parts, value[:end])
+		value = value[end:]
+	}
+	return append(parts, value)
+}
+
+func parseCloudSyncChunkManifest(plaintext string) (cloudSyncChunkManifest, bool, error) {
+	encoded, ok := strings.CutPrefix(plaintext, cloudSyncChunkManifestPrefix)
+	if !ok {
+		return cloudSyncChunkManifest{}, false, nil
+	}
+	var manifest cloudSyncChunkManifest
+	if err := json.Unmarshal([]byte(encoded), &manifest); err != nil {
+		return cloudSyncChunkManifest{}, true, fmt.Errorf("invalid cloud sync chunk manifest: %w", err)
+	}
+	if manifest.Version == "" || manifest.Count <= 0 {
+		return cloudSyncChunkManifest{}, true, fmt.Errorf("invalid cloud sync chunk manifest")
+	}
+	return manifest, true, nil
+}
+
+// cloudSyncChunkAssembler buffers chunk records across pull pages until the
+// manifest and every chunk of its version have arrived. Buffered parts are
+// also written to the database and loaded back on first use, so a restart
+// between pull pages does not drop parts the pull cursor already moved past.
+type cloudSyncChunkAssembler struct {
+	mu      sync.Mutex
+	loaded  bool
+	pending map[string]*cloudSyncChunkedValue
+}
+
+type cloudSyncChunkedValue struct {
+	manifest *cloudSyncChunkManifest
+	record   CloudSyncRecord
+	chunks   map[string]map[int]string
+}
+
+// cloudSyncChunkManifestIndex marks the manifest row among persisted chunks.
+const cloudSyncChunkManifestIndex = -1
+
+// cloudSyncPendingManifest is the persisted value of a buffered manifest row.
+type cloudSyncPendingManifest struct {
+	Record CloudSyncRecord `json:"record"`
+	Count  int             `json:"count"`
+}
+
+func newCloudSyncChunkAssembler() *cloudSyncChunkAssembler {
+	return &cloudSyncChunkAssembler{pending: map[string]*cloudSyncChunkedValue{}}
+}
+
+// AddManifest stores the manifest record and returns the original record and
+// value once all chunks are present.
+func (a *cloudSyncChunkAssembler) AddManifest(record CloudSyncRecord, manifest cloudSyncChunkManifest) (CloudSyncRecord, string, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.load()
+	entry := a.entry(record.EntityType, record.PluginID, record.Key)
+	entry.manifest = &manifest
+	entry.record = record
+	a.persistManifest(record, manifest)
+	return a.complete(record.EntityType, record.PluginID, record.Key)
+}
+
+// AddChunk stores one decrypted chunk record and returns the original record
+// and value when it completes a value whose manifest already arrived.
+func (a *cloudSyncChunkAssembler) AddChunk(record CloudSyncRecord, baseKey string, plaintext string) (CloudSyncRecord, string, bool, error) {
+	var chunk cloudSyncChunk
+	if err := json.Unmarshal([]byte(plaintext), &chunk); err != nil {
+		return CloudSyncRecord{}, "", false, fmt.Errorf("invalid cloud sync chunk: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.load()
+	entry := a.entry(record.EntityType, record.PluginID, baseKey)
+	if entry.chunks[chunk.Version] == nil {
+		entry.chunks[chunk.Version] = map[int]string{}
+	}
+	entry.chunks[chunk.Version][chunk.Index] = chunk.Data
+	a.persist(database.CloudSyncPendingChunk{
+		EntityType: record.EntityType,
+		PluginID:   record.PluginID,
+		Key:        baseKey,
+		Version:    chunk.Version,
+		ChunkIndex: chunk.Index,
+		Value:      chunk.Data,
+	})
+	completed, value, ok := a.complete(record.EntityType, record.PluginID, baseKey)
+	return completed, value, ok, nil
+}
+
+// Forget drops buffered chunks after the original key was deleted.
+func (a *cloudSyncChunkAssembler) Forget(entityType string, pluginID string, key string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.load()
+	identity := cloudSyncChunkIdentity(entityType, pluginID, key)
+	if _, ok := a.pending[identity]; !ok {
+		return
+	}
+	delete(a.pending, identity)
+	a.deletePersisted(entityType, pluginID, key)
+}
+
+func (a *cloudSyncChunkAssembler) entry(entityType string, pluginID string, key string) *cloudSyncChunkedValue {
+	identity := cloudSyncChunkIdentity(entityType, pluginID, key)
+	entry, ok := a.pending[identity]
+	if !ok {
+		entry = &cloudSyncChunkedValue{chunks: map[string]map[int]string{}}
+		a.pending[identity] = entry
+	}
+	return entry
+}
+
+func (a *cloudSyncChunkAssembler) complete(entityType string, pluginID string, key string) (CloudSyncRecord, string, bool) {
+	identity := cloudSyncChunkIdentity(entityType, pluginID, key)
+	entry := a.pending[identity]
+	if entry == nil || entry.manifest == nil {
+		return CloudSyncRecord{}, "", false
+	}
+	chunks := entry.chunks[entry.manifest.Version]
+	if len(chunks) < entry.manifest.Count {
+		return CloudSyncRecord{}, "", false
+	}
+
+	var builder strings.Builder
+	for index := 0; index < entry.manifest.Count; index++ {
+		data, ok := chunks[index]
+		if !ok {
+			return CloudSyncRecord{}, "", false
+		}
+		builder.WriteString(data)
+	}
+	delete(a.pending, identity)
+	a.deletePersisted(entityType, pluginID, key)
+	return entry.record, builder.String(), true
+}
+
+// load restores the parts buffered before a restart. Persistence is best
+// effort: without a database the assembler still works within one run.
+func (a *cloudSyncChunkAssembler) load() {
+	if a.loaded {
+		return
+	}
+	a.loaded = true
+
+	db := database.GetDB()
+	if db == nil {
+		return
+	}
+	var rows []database.CloudSyncPendingChunk
+	if err := db.Find(&rows).Error; err != nil {
+		util.GetLogger().Warn(context.Background(), fmt.Sprintf("failed to load pending cloud sync chunks: %s", err.Error()))
+		return
+	}
+	for _, row := range rows {
+		entry := a.entry(row.EntityType, row.PluginID, row.Key)
+		if row.ChunkIndex == cloudSyncChunkManifestIndex {
+			var pendingManifest cloudSyncPendingManifest
+			if err := json.Unmarshal([]byte(row.Value), &pendingManifest); err != nil {
+				continue
+			}
+			entry.manifest = &cloudSyncChunkManifest{Version: row.Version, Count: pendingManifest.Count}
+			entry.record = pendingManifest.Record
+			continue
+		}
+		if entry.chunks[row.Version] == nil {
+			entry.chunks[row.Version] = map[int]string{}
+		}
+		entry.chunks[row.Version][row.ChunkIndex] = row.Value
+	}
+}
+
+// persistManifest replaces the buffered manifest row of the record's key.
+func (a *cloudSyncChunkAssembler) persistManifest(record CloudSyncRecord, manifest cloudSyncChunkManifest) {
+	db := database.GetDB()
+	if db == nil {
+		return
+	}
+	if err := db.Where("entity_type = ? AND plugin_id = ? AND key = ? AND chunk_index = ?", record.EntityType, record.PluginID, record.Key, cloudSyncChunkManifestIndex).
+		Delete(&database.CloudSyncPendingChunk{}).Error; err != nil {
+		util.GetLogger().Warn(context.Background(), fmt.Sprintf("failed to replace pending cloud sync manifest %s: %s", record.Key, err.Error()))
+		return
+	}
+	encoded, err := json.Marshal(cloudSyncPendingManifest{Record: record, Count: manifest.Count})
+	if err != nil {
+		return
+	}
+	a.persist(database.CloudSyncPendingChunk{
+		EntityType: record.EntityType,
+		PluginID:   record.PluginID,
+		Key:        record.Key,
+		Version:    manifest.Version,
+		ChunkIndex: cloudSyncChunkManifestIndex,
+		Value:      string(encoded),
+	})
+}
+
+func (a *cloudSyncChunkAssembler) persist(row database.CloudSyncPendingChunk) {
+	db := database.GetDB()
+	if db == nil {
+		return
+	}
+	if err := db.Save(&row).Error; err != nil {
+		util.GetLogger().Warn(context.Background(), fmt.Sprintf("failed to store pending cloud sync chunk %s: %s", row.Key, err.Error()))
+	}
+}
+
+func (a *cloudSyncChunkAssembler) deletePersisted(entityType string, pluginID string, key string) {
+	db := database.GetDB()
+	if db == nil {
+		return
+	}
+	if err := db.Where("entity_type = ? AND plugin_id = ? AND key = ?", entityType, pluginID, key).
+		Delete(&database.CloudSyncPendingChunk{}).Error; err != nil {
+		util.GetLogger().Warn(context.Background(), fmt.Sprintf("failed to drop pending cloud sync chunks %s: %s", key, err.Error()))
+	}
+}
+
+func cloudSyncChunkIdentity(entityType string, pluginID string, key string) string {
+	return entityType + "\x00" + pluginID + "\x00" + key
+}
@@ -0,0 +1,155 @@
+package cloudsync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"wox/database"
+)
+
+// Data entity types replicate user data rather than settings. Each one can be
+// turned off on its own from the Cloud Sync settings page.
+const (
+	EntityMRU               = "mru"
+	EntityQueryHistory      = "query_history"
+	EntityClipboardFavorite = "clipboard_favorite"
+	EntityAIChat            = "ai_chat"
+	EntityAttentionItem     = "attention_item"
+
+	// QueryHistorySyncKey is the single record key that carries the whole query history list.
+	QueryHistorySyncKey = "QueryHistories"
+)
+
+// CloudSyncConflictRule decides how a remote record is combined with local data.
+type CloudSyncConflictRule string
+
+const (
+	// CloudSyncConflictLastWriterWins replaces local data with the remote record
+	// unless a newer local write is still waiting to be pushed.
+	CloudSyncConflictLastWriterWins CloudSyncConflictRule = "last_writer_wins"
+	// CloudSyncConflictMergeByID keeps items from both sides and resolves
+	// items with the same id by their own timestamps.
+	CloudSyncConflictMergeByID CloudSyncConflictRule = "merge_by_id"
+)
+
+// CloudSyncEntitySpec describes how one data entity type is replicated.
+type CloudSyncEntitySpec struct {
+	EntityType string
+	Conflict   CloudSyncConflictRule
+	// PluginScoped entities store the owning plugin in Oplog.EntityID, so plugin exclusions apply to them too.
+	PluginScoped bool
+	// ChunkBytes splits larger upserts into several records so one chat or image never fills a push batch.
+	ChunkBytes int
+}
+
+// cloudSyncEntitySpecs keeps the display order used by the settings page.
+var cloudSyncEntitySpecs = []CloudSyncEntitySpec{
+	{EntityType: EntityMRU, Conflict: CloudSyncConflictLastWriterWins},
+	{EntityType: EntityQueryHistory, Conflict: CloudSyncConflictMergeByID, ChunkBytes: 128 * 1024},
+	{EntityType: EntityClipboardFavorite, Conflict: CloudSyncConflictLastWriterWins, PluginScoped: true, ChunkBytes: 128 * 1024},
+	{EntityType: EntityAIChat, Conflict: CloudSyncConflictMergeByID, ChunkBytes: 128 * 1024},
+	{EntityType: EntityAttentionItem, Conflict: CloudSyncConflictLastWriterWins, PluginScoped: true},
+}
+
+// CloudSyncDataEntityTypes lists the data entity types that have an opt-out toggle.
+func CloudSyncDataEntityTypes() []string {
+	types := make([]string, 0, len(cloudSyncEntitySpecs))
+	for _, spec := range cloudSyncEntitySpecs {
+		types = append(types, spec.EntityType)
+	}
+	return types
+}
+
+// ResolveCloudSyncEntitySpec returns the replication rules of a data entity type.
+func ResolveCloudSyncEntitySpec(entityType string) (CloudSyncEntitySpec, bool) {
+	index := slices.IndexFunc(cloudSyncEntitySpecs, func(spec CloudSyncEntitySpec) bool {
+		return spec.EntityType == entityType
+	})
+	if index < 0 {
+		return CloudSyncEntitySpec{}, false
+	}
+	return cloudSyncEntitySpecs[index], true
+}
+
+// OplogPluginID returns the plugin that owns an oplog, or "" for Wox-level rows.
+func OplogPluginID(oplog database.Oplog) string {
+	if oplog.EntityType == EntityPluginSetting {
+		return oplog.EntityID
+	}
+	if spec, ok := ResolveCloudSyncEntitySpec(oplog.EntityType); ok && spec.PluginScoped {
+		return oplog.EntityID
+	}
+	return ""
+}
+
+// LogEntityUpsert records a syncable data entity change. entityID is the
+// owning plugin for plugin-scoped entities and the key otherwise.
+func LogEntityUpsert(ctx context.Context, entityType string, entityID string, key string, value any) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to serialize %s sync value: %w", entityType, err)
+	}
+	return logEntityOplog(ctx, entityType, entityID, key, string(encoded), OpUpsert)
+}
+
+// LogEntityDelete records a syncable data entity removal.
+func LogEntityDelete(ctx context.Context, entityType string, entityID string, key string) error {
+	return logEntityOplog(ctx, entityType, entityID, key, "", OpDelete)
+}
+
+func logEntityOplog(ctx context.Context, entityType string, entityID string, key string, value string, op string) error {
+	_ = ctx
+	if _, ok := ResolveCloudSyncEntitySpec(entityType); !ok {
+		return fmt.Errorf("unknown cloud sync entity type: %s", entityType)
+	}
+	if key == "" {
+		return fmt.Errorf("%s sync key is empty", entityType)
+	}
+	db := database.GetDB()
+	if db == nil {
+		return fmt.Errorf("database is not initialized")
+	}
+
+	return WriteOplog(db, database.Oplog{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Operation:  op,
+		Key:        key,
+		Value:      value,
+	})
+}
+
+// MergeCloudSyncItemsByID combines two lists for merge-by-id entities. Items
+// keep the local order, remote-only items follow in remote order, and an item
+// present on both sides takes the copy with the newer timestamp. Ties keep the
+// remote copy so every device settles on the same value.
+func MergeCloudSyncItemsByID[T any](local []T, remote []T, id func(T) string, updatedAt func(T) int64) []T {
+	remoteByID := make(map[string]T, len(remote))
+	for _, item := range remote {
+		remoteByID[id(item)] = item
+	}
+
+	merged := make([]T, 0, len(local)+len(remote))
+	seen := make(map[string]struct{}, len(local)+len(remote))
+	for _, item := range local {
+		itemID := id(item)
+		if _, duplicate := seen[itemID]; duplicate {
+			continue
+		}
+		seen[itemID] = struct{}{}
+		if remoteItem, ok := remoteByID[itemID]; ok && updatedAt(remoteItem) >= updatedAt(item) {
+			item = remoteItem
+		}
+		merged = append(merged, item)
+	}
+	for _, item := range remote {
+		itemID := id(item)
+		if _, duplicate := seen[itemID]; duplicate {
+			continue
+		}
+		seen[itemID] = struct{}{}
+		merged = append(merged, item)
+	}
+	return merged
+}
@@ -0,0 +1,241 @@
+package cloudsync
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"wox/database"
+)
+
+func TestMergeCloudSyncItemsByIDKeepsBothSidesAndNewerCopy(t *testing.T) {
+	type item struct {
+		ID string
+		Ts int64
+		V  string
+	}
+	local := []item{{ID: "a", Ts: 10, V: "local-a"}, {ID: "b", Ts: 30, V: "local-b"}, {ID: "c", Ts: 5, V: "local-c"}}
+	remote := []item{{ID: "b", Ts: 20, V: "remote-b"}, {ID: "c", Ts: 5, V: "remote-c"}, {ID: "d", Ts: 1, V: "remote-d"}}
+
+	merged := MergeCloudSyncItemsByID(local, remote, func(i item) string { return i.ID }, func(i item) int64 { return i.Ts })
+
+	got := make([]string, 0, len(merged))
+	for _, i := range merged {
+		got = append(got, i.V)
+	}
+	want := []string{"local-a", "local-b", "remote-c", "remote-d"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("merged = %#v, want %#v", got, want)
+	}
+}
+
+func TestCloudSyncChunkedEntityRoundTripsThroughPushAndApply(t *testing.T) {
+	ctx := context.Background()
+	initCloudSyncTestDatabase(t)
+
+	// Multi-byte text makes sure chunk boundaries never split a rune.
+	value := `{"Id":"chat-1","Title":"` + strings.Repeat("聊天记录 chat history ", 20000) + `"}`
+	store := &testCloudSyncOplogStore{
+		pending: []database.Oplog{{ID: 7, EntityType: EntityAIChat, EntityID: "chat-1", Operation: OpUpsert, Key: "chat-1", Value: value, Timestamp: 100}},
+	}
+	client := &testCloudSyncClient{}
+	config := DefaultCloudSyncConfig()
+	config.MaxBatchBytes = len(value) * 2
+	manager := NewCloudSyncManager(config, CloudSyncDependencies{
+		Client:         client,
+		Crypto:         testCloudSyncCrypto{},
+		DeviceProvider: testCloudSyncDeviceProvider{deviceID: "device-a"},
+		OplogStore:     store,
+	})
+
+	manager.PushPending(ctx, "test")
+
+	if len(client.pushRequests) != 1 {
+		t.Fatalf("push requests = %d, want 1", len(client.pushRequests))
+	}
+	changes := client.pushRequests[0].Changes
+	if len(changes) < 3 {
+		t.Fatalf("changes = %d, want several chunks plus a manifest", len(changes))
+	}
+	if last := changes[len(changes)-1]; last.Key != "chat-1" || !strings.HasPrefix(last.Value.Ciphertext, cloudSyncChunkManifestPrefix) {
+		t.Fatalf("last change = %s, want manifest under original key", last.Key)
+	}
+	if !slices.Equal(store.synced, []uint{7}) {
+		t.Fatalf("synced oplogs = %#v, want [7]", store.synced)
+	}
+
+	// Deliver the manifest before the chunks, as a pull page boundary might.
+	records := make([]CloudSyncRecord, 0, len(changes))
+	for i := len(changes) - 1; i >= 0; i-- {
+		change := changes[i]
+		records = append(records, CloudSyncRecord{EntityType: change.EntityType, PluginID: change.PluginID, Key: change.Key, Op: change.Op, ClientTs: change.ClientTs, Value: change.Value})
+	}
+	applier := &testCloudSyncEntityApplier{}
+	receiver := NewCloudSyncManager(DefaultCloudSyncConfig(), CloudSyncDependencies{Crypto: testCloudSyncCrypto{}, Applier: applier})
+	result := receiver.applyRecordsWithDetails(ctx, records, nil)
+	if result.Err() != nil {
+		t.Fatalf("apply records: %v", result.Err())
+	}
+	if result.Succeeded != 1 {
+		t.Fatalf("succeeded = %d, want one assembled record", result.Succeeded)
+	}
+	if got := applier.entities[EntityAIChat+":chat-1"]; got != value {
+		t.Fatalf("assembled value length = %d, want %d", len(got), len(value))
+	}
+}
+
+func TestCloudSyncChunkedEntitySpansPushBatches(t *testing.T) {
+	ctx := context.Background()
+	initCloudSyncTestDatabase(t)
+
+	value := `{"Id":"chat-1","Title":"` + strings.Repeat("chat history ", 40000) + `"}`
+	store := &testCloudSyncOplogStore{
+		pending: []database.Oplog{{ID: 7, EntityType: EntityAIChat, EntityID: "chat-1", Operation: OpUpsert, Key: "chat-1", Value: value, Timestamp: 100}},
+	}
+	client := &testCloudSyncClient{}
+	config := DefaultCloudSyncConfig()
+	config.MaxBatchBytes = len(value) / 3
+	manager := NewCloudSyncManager(config, CloudSyncDependencies{
+		Client:         client,
+		Crypto:         testCloudSyncCrypto{},
+		DeviceProvider: testCloudSyncDeviceProvider{deviceID: "device-a"},
+		OplogStore:     store,
+	})
+
+	manager.PushPending(ctx, "test")
+
+	if len(client.pushRequests) < 3 {
+		t.Fatalf("push requests = %d, want the chunks split across several batches", len(client.pushRequests))
+	}
+	keys := []string{}
+	for _, request := range client.pushRequests {
+		for _, change := range request.Changes {
+			keys = append(keys, change.Key)
+		}
+	}
+	if len(keys) != len(slices.Compact(slices.Clone(keys))) {
+		t.Fatalf("pushed keys = %#v, want every chunk exactly once", keys)
+	}
+	if keys[len(keys)-1] != "chat-1" {
+		t.Fatalf("last pushed key = %s, want the manifest under the original key", keys[len(keys)-1])
+	}
+	if !slices.Equal(store.synced, []uint{7}) {
+		t.Fatalf("synced oplogs = %#v, want [7] once after the manifest", store.synced)
+	}
+}
+
+func TestCloudSyncChunkAssemblerKeepsPartsAcrossRestart(t *testing.T) {
+	initCloudSyncTestDatabase(t)
+
+	value := strings.Repeat("chat history ", 100)
+	chunks, manifestText, err := buildCloudSyncChunks(value, 256)
+	if err != nil {
+		t.Fatalf("build chunks: %v", err)
+	}
+	manifest, _, err := parseCloudSyncChunkManifest(manifestText)
+	if err != nil {
+		t.Fatalf("parse manifest: %v", err)
+	}
+	record := CloudSyncRecord{EntityType: EntityAIChat, PluginID: "", Key: "chat-1", Op: OpUpsert, ClientTs: 100}
+
+	// The first pull page delivers every chunk but the last one, then Wox restarts.
+	before := newCloudSyncChunkAssembler()
+	for _, chunk := range chunks[:len(chunks)-1] {
+		if _, _, ready, err := before.AddChunk(record, record.Key, chunk); err != nil || ready {
+			t.Fatalf("add chunk: ready = %v, err = %v", ready, err)
+		}
+	}
+
+	after := newCloudSyncChunkAssembler()
+	if _, _, ready := after.AddManifest(record, manifest); ready {
+		t.Fatalf("manifest completed the value before the last chunk arrived")
+	}
+	assembled, got, ready, err := after.AddChunk(record, record.Key, chunks[len(chunks)-1])
+	if err != nil || !ready {
+		t.Fatalf("add last chunk: ready = %v, err = %v", ready, err)
+	}
+	if got != value || assembled.Key != "chat-1" {
+		t.Fatalf("assembled %s with %d bytes, want chat-1 with %d bytes", assembled.Key, len(got), len(value))
+	}
+
+	var remaining int64
+	if err := database.GetDB().Model(&database.CloudSyncPendingChunk{}).Count(&remaining).Error; err != nil {
+		t.Fatalf("count pending chunks: %v", err)
+	}
+	if remaining != 0 {
+		t.Fatalf("pending chunk rows = %d, want 0 after assembly", remaining)
+	}
+}
+
+func TestApplyRecordsHonorsEntityExclusionsAndPendingLocalWrites(t *testing.T) {
+	ctx := context.Background()
+	initCloudSyncTestDatabase(t)
+
+	applier := &testCloudSyncEntityApplier{}
+	manager := NewCloudSyncManager(DefaultCloudSyncConfig(), CloudSyncDependencies{
+		Crypto:            testCloudSyncCrypto{},
+		Applier:           applier,
+		OplogStore:        &testCloudSyncPendingOplogStore{latest: map[string]int64{EntityMRU + ":hash-new": 500}},
+		ExclusionProvider: testCloudSyncEntityExclusions{entityTypes: []string{EntityQueryHistory}},
+	})
+
+	records := []CloudSyncRecord{
+		{EntityType: EntityMRU, Key: "hash-old", Op: OpUpsert, ClientTs: 400, Value: &CloudSyncEncryptedValue{KeyVersion: 1, Ciphertext: "old"}},
+		{EntityType: EntityMRU, Key: "hash-new", Op: OpUpsert, ClientTs: 400, Value: &CloudSyncEncryptedValue{KeyVersion: 1, Ciphertext: "stale"}},
+		{EntityType: EntityQueryHistory, Key: QueryHistorySyncKey, Op: OpUpsert, ClientTs: 400, Value: &CloudSyncEncryptedValue{KeyVersion: 1, Ciphertext: "[]"}},
+	}
+	if err := manager.applyRecords(ctx, records); err != nil {
+		t.Fatalf("apply records: %v", err)
+	}
+
+	if got := applier.entities[EntityMRU+":hash-old"]; got != "old" {
+		t.Fatalf("mru hash-old = %q, want old", got)
+	}
+	if _, applied := applier.entities[EntityMRU+":hash-new"]; applied {
+		t.Fatal("remote MRU record overwrote a newer pending local write")
+	}
+	if _, applied := applier.entities[EntityQueryHistory+":"+QueryHistorySyncKey]; applied {
+		t.Fatal("disabled query history entity was applied")
+	}
+}
+
+type testCloudSyncEntityApplier struct {
+	testCloudSyncApplier
+	entities map[string]string
+}
+
+func (a *testCloudSyncEntityApplier) ApplyEntity(ctx context.Context, entityType string, pluginID string, key string, op string, rawValue string) error {
+	_ = ctx
+	_ = pluginID
+	_ = op
+	if a.entities == nil {
+		a.entities = map[string]string{}
+	}
+	a.entities[entityType+":"+key] = rawValue
+	return nil
+}
+
+type testCloudSyncPendingOplogStore struct {
+	testCloudSyncOplogStore
+	latest map[string]int64
+}
+
+func (s *testCloudSyncPendingOplogStore) LatestPendingTimestamp(ctx context.Context, entityType string, entityID string, key string) (int64, error) {
+	_ = ctx
+	_ = entityID
+	return s.latest[entityType+":"+key], nil
+}
+
+type testCloudSyncEntityExclusions struct {
+	entityTypes []string
+}
+
+func (e testCloudSyncEntityExclusions) DisabledPluginIDs(ctx context.Context) []string {
+	_ = ctx
+	return nil
+}
+
+func (e testCloudSyncEntityExclusions) DisabledEntityTypes(ctx context.Context) []string {
+	_ = ctx
+	return e.entityTypes
+}
@@ -61,6 +61,11 @@ type CloudSyncManager struct {
 	settingReloader  CloudSyncSettingReloader
 	historyStore     CloudSyncHistoryStore
 	autoSyncAllowed  func(ctx context.Context) bool
+	chunks           *cloudSyncChunkAssembler
+	// chunkPushOffsets remembers how many changes of a chunked oplog earlier
+	// batches delivered, guarded by pushMu. Chunks are deterministic, so after a
+	// restart the oplog is simply pushed again from its first chunk.
+	chunkPushOffsets map[uint]int
 
 	mu         sync.Mutex
 	pushMu     sync.Mutex
@@ -89,6 +94,7 @@ func NewCloudSyncManager(config CloudSyncConfig, deps CloudSyncDependencies) *Cl
 		settingReloader:  deps.SettingReloader,
 		historyStore:     deps.HistoryStore,
 		autoSyncAllowed:  deps.AutoSyncAllowed,
+		chunks:           newCloudSyncChunkAssembler(),
 	}
 }
 
@@ -344,10 +350,10 @@ func (m *CloudSyncManager) pushPendingLocked(ctx context.Context, reason string)
 			m.setProgress(CloudSyncProgress{Operation: CloudSyncProgressOperationPush, Current: processed, Total: total})
 		}
 		m.setProgressFromOplog(CloudSyncProgressOperationPush, pending[0], processed, total)
-		eligible, dropped := m.filterOplogsByDisabledPlugins(ctx, pending)
+		eligible, dropped := m.filterOplogsByExclusions(ctx, pending)
 		if len(dropped) > 0 {
 			if err := m.oplogStore.MarkSynced(ctx, dropped); err != nil {
-				util.GetLogger().Warn(ctx, fmt.Sprintf("failed to drop disabled plugin oplogs: %v", err))
+				util.GetLogger().Warn(ctx, fmt.Sprintf("failed to drop excluded oplogs: %v", err))
 			}
 			processed += len(dropped)
 		}
@@ -360,7 +366,7 @@ func (m *CloudSyncManager) pushPendingLocked(ctx context.Context, reason string)
 			return
 		}
 
-		changes, oplogIds, err := m.buildPushBatch(ctx, eligible)
+		changes, oplogIds, partial, err := m.buildPushBatch(ctx, eligible)
 		if err != nil {
 			fail(fmt.Errorf("failed to build push batch: %w", err))
 			return
@@ -386,11 +392,12 @@ func (m *CloudSyncManager) pushPendingLocked(ctx context.Context, reason string)
 			return
 		}
 
-		syncedIds, rejectedFailures, err := m.resolvePushResults(resp, changes, oplogIds, eligible)
+		syncedIds, rejectedFailures, err := m.resolvePushResults(resp, changes, oplogIds, partial, eligible)
 		if err != nil {
 			fail(err)
 			return
 		}
+		m.advanceChunkPushOffsets(partial, syncedIds, rejectedFailures)
 		if len(syncedIds) > 0 {
 			if err := m.oplogStore.MarkSynced(ctx, syncedIds); err != nil {
 				fail(fmt.Errorf("failed to mark oplogs synced: %w", err))
@@ -426,12 +433,31 @@ func (m *CloudSyncManager) pushPendingLocked(ctx context.Context, reason string)
 		if len(rejectedFailures) > 0 {
 			return
 		}
-		if len(eligible) <= len(oplogIds) {
+		if len(partial) == 0 && len(eligible) <= len(uniqueCloudSyncOplogIDs(oplogIds)) {
 			return
 		}
 	}
 }
 
+// advanceChunkPushOffsets records the chunks a partial batch delivered and
+// forgets offsets of oplogs that finished or were rejected, so a rejected
+// oplog is retried from its first chunk.
+func (m *CloudSyncManager) advanceChunkPushOffsets(partial map[uint]int, syncedIds []uint, failures []CloudSyncOplogPushFailure) {
+	if m.chunkPushOffsets == nil {
+		m.chunkPushOffsets = map[uint]int{}
+	}
+	for _, oplogID := range syncedIds {
+		delete(m.chunkPushOffsets, oplogID)
+	}
+	for _, failure := range failures {
+		delete(m.chunkPushOffsets, failure.ID)
+		delete(partial, failure.ID)
+	}
+	for oplogID, offset := range partial {
+		m.chunkPushOffsets[oplogID] = offset
+	}
+}
+
 func (m *CloudSyncManager) Pull(ctx context.Context, reason string) {
 	m.pullMu.Lock()
 	defer m.pullMu.Unlock()
@@ -678,6 +704,7 @@ func (r *cloudSyncApplyRecordsResult) addFailed(record CloudSyncRecord, err erro
 // applyRecordsWithDetails keeps applying independent remote records after one item fails.
 func (m *CloudSyncManager) applyRecordsWithDetails(ctx context.Context, records []CloudSyncRecord, progress *cloudSyncApplyProgress) cloudSyncApplyRecordsResult {
 	disabled := m.disabledPluginSet(ctx)
+	disabledEntities := m.disabledEntitySet(ctx)
 	appliedWoxSetting := false
 	appliedPluginSetting := false
 	appliedInstalledPlugin := false
@@ -698,6 +725,16 @@ func (m *CloudSyncManager) applyRecordsWithDetails(ctx context.Context, records
 				continue
 			}
 		}
+		if spec, isDataEntity := ResolveCloudSyncEntitySpec(record.EntityType); isDataEntity {
+			if _, blocked := disabledEntities[record.EntityType]; blocked {
+				continue
+			}
+			if spec.PluginScoped {
+				if _, blocked := disabled[record.PluginID]; blocked {
+					continue
+				}
+			}
+		}
 		if !isSyncRecordForCurrentPlatform(record) {
 			continue
 		}
@@ -717,6 +754,19 @@ func (m *CloudSyncManager) applyRecordsWithDetails(ctx context.Context, records
 			rawValue = plaintext
 		}
 
+		if _, isDataEntity := ResolveCloudSyncEntitySpec(record.EntityType); isDataEntity {
+			assembled, value, ready, err := m.assembleDataEntityRecord(record, rawValue)
+			if err != nil {
+				result.addFailed(record, err)
+				continue
+			}
+			if !ready {
+				// Chunks and manifests only become an applied item once the whole value has arrived.
+				continue
+			}
+			record, rawValue = assembled, value
+		}
+
 		switch record.EntityType {
 		case EntityWoxSetting:
 			willChangeCurrentTheme := themeSettingWillChange(record, rawValue)
@@ -748,6 +798,12 @@ func (m *CloudSyncManager) applyRecordsWithDetails(ctx context.Context, records
 			}
 			appliedInstalledTheme = true
 			result.addSucceeded(record)
+		case EntityMRU, EntityQueryHistory, EntityClipboardFavorite, EntityAIChat, EntityAttentionItem:
+			if err := m.applyDataEntity(ctx, record, rawValue); err != nil {
+				result.addFailed(record, err)
+				continue
+			}
+			result.addSucceeded(record)
 		default:
 			util.GetLogger().Warn(ctx, fmt.Sprintf("unknown cloud sync entity type: %s", record.EntityType))
 			result.addSucceeded(record)
@@ -758,6 +814,64 @@ func (m *CloudSyncManager) applyRecordsWithDetails(ctx context.Context, records
 	return result
 }
 
+// assembleDataEntityRecord turns chunk and manifest records back into the
+// original record. ready is false while chunks of the value are still missing.
+func (m *CloudSyncManager) assembleDataEntityRecord(record CloudSyncRecord, rawValue string) (CloudSyncRecord, string, bool, error) {
+	if baseKey, isChunk := splitCloudSyncChunkKey(record.Key); isChunk {
+		if record.Op != OpUpsert {
+			return CloudSyncRecord{}, "", false, nil
+		}
+		return m.chunks.AddChunk(record, baseKey, rawValue)
+	}
+
+	if record.Op == OpDelete {
+		m.chunks.Forget(record.EntityType, record.PluginID, record.Key)
+		return record, rawValue, true, nil
+	}
+
+	manifest, isManifest, err := parseCloudSyncChunkManifest(rawValue)
+	if err != nil {
+		return CloudSyncRecord{}, "", false, err
+	}
+	if !isManifest {
+		m.chunks.Forget(record.EntityType, record.PluginID, record.Key)
+		return record, rawValue, true, nil
+	}
+	assembled, value, ready := m.chunks.AddManifest(record, manifest)
+	return assembled, value, ready, nil
+}
+
+// applyDataEntity hands a data entity record to the applier after checking
+// the entity's conflict rule. Last-writer-wins records lose to a newer local
+// write that is still queued, which will overwrite the remote copy on push.
+func (m *CloudSyncManager) applyDataEntity(ctx context.Context, record CloudSyncRecord, rawValue string) error {
+	applier, ok := m.applier.(CloudSyncEntityApplier)
+	if !ok {
+		util.GetLogger().Warn(ctx, fmt.Sprintf("cloud sync applier cannot apply %s records", record.EntityType))
+		return nil
+	}
+
+	spec, _ := ResolveCloudSyncEntitySpec(record.EntityType)
+	if spec.Conflict == CloudSyncConflictLastWriterWins {
+		if lookup, ok := m.oplogStore.(CloudSyncPendingTimestampLookup); ok {
+			entityID := record.Key
+			if spec.PluginScoped {
+				entityID = record.PluginID
+			}
+			pendingTs, err := lookup.LatestPendingTimestamp(ctx, record.EntityType, entityID, record.Key)
+			if err != nil {
+				return err
+			}
+			if pendingTs > record.ClientTs {
+				util.GetLogger().Info(ctx, fmt.Sprintf("skip remote %s record %s, newer local write is pending", record.EntityType, record.Key))
+				return nil
+			}
+		}
+	}
+
+	return applier.ApplyEntity(ctx, record.EntityType, record.PluginID, record.Key, record.Op, rawValue)
+}
+
 // cloudSyncHistoryDetailFromRecord converts an apply attempt into persisted history detail.
 func cloudSyncHistoryDetailFromRecord(record CloudSyncRecord, status string, errorMessage string) CloudSyncHistoryRecordDetail {
 	return CloudSyncHistoryRecordDetail{
@@ -837,40 +951,55 @@ func (m *CloudSyncManager) reloadAppliedSettings(ctx context.Context, reloadWoxS
 	}
 }
 
-func (m *CloudSyncManager) buildPushBatch(ctx context.Context, oplogs []database.Oplog) ([]CloudSyncChange, []uint, error) {
+// buildPushBatch fills one batch up to MaxBatchBytes and MaxBatchCount. The
+// chunks of a large oplog may span several batches with the manifest last;
+// partial maps such an oplog to the change offset this batch reaches, and the
+// oplog only counts as synced once the batch carrying its manifest is accepted.
+func (m *CloudSyncManager) buildPushBatch(ctx context.Context, oplogs []database.Oplog) ([]CloudSyncChange, []uint, map[uint]int, error) {
 	var changes []CloudSyncChange
 	var oplogIds []uint
 	var totalBytes int
+	partial := map[uint]int{}
 
 	for _, oplog := range oplogs {
-		change, err := m.oplogToChange(ctx, oplog)
+		oplogChanges, err := m.oplogToChanges(ctx, oplog)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 
-		encoded, err := json.Marshal(change)
-		if err != nil {
-			return nil, nil, fmt.Errorf("failed to encode change: %w", err)
+		// Never skip the manifest, even if the chunk offset is stale.
+		offset := min(m.chunkPushOffsets[oplog.ID], len(oplogChanges)-1)
+		next := offset
+		for _, change := range oplogChanges[offset:] {
+			encoded, err := json.Marshal(change)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("failed to encode change: %w", err)
+			}
+			if len(changes) > 0 && (totalBytes+len(encoded) > m.config.MaxBatchBytes || len(changes) >= m.config.MaxBatchCount) {
+				break
+			}
+			changes = append(changes, change)
+			oplogIds = append(oplogIds, oplog.ID)
+			totalBytes += len(encoded)
+			next++
 		}
 
-		if len(changes) > 0 && totalBytes+len(encoded) > m.config.MaxBatchBytes {
+		if next < len(oplogChanges) {
+			if next > offset {
+				partial[oplog.ID] = next
+			}
 			break
 		}
-
-		changes = append(changes, change)
-		oplogIds = append(oplogIds, oplog.ID)
-		totalBytes += len(encoded)
-
 		if len(changes) >= m.config.MaxBatchCount {
 			break
 		}
 	}
 
-	return changes, oplogIds, nil
+	return changes, oplogIds, partial, nil
 }
 
 // resolvePushResults maps server per-change results back to local oplog IDs and advances local failure counters.
-func (m *CloudSyncManager) resolvePushResults(resp *CloudSyncPushResponse, changes []CloudSyncChange, oplogIds []uint, oplogs []database.Oplog) ([]uint, []CloudSyncOplogPushFailure, error) {
+func (m *CloudSyncManager) resolvePushResults(resp *CloudSyncPushResponse, changes []CloudSyncChange, oplogIds []uint, partial map[uint]int, oplogs []database.Oplog) ([]uint, []CloudSyncOplogPushFailure, error) {
 	if resp == nil {
 		return nil, nil, errors.New("cloud sync push response is empty")
 	}
@@ -891,8 +1020,7 @@ func (m *CloudSyncManager) resolvePushResults(resp *CloudSyncPushResponse, chang
 	}
 
 	seen := map[string]struct{}{}
-	var synced []uint
-	var failures []CloudSyncOplogPushFailure
+	rejected := map[uint]CloudSyncAppliedChange{}
 	for _, result := range resp.Applied {
 		oplogID, ok := changeIDToOplogID[result.ChangeID]
 		if !ok {
@@ -901,19 +1029,10 @@ func (m *CloudSyncManager) resolvePushResults(resp *CloudSyncPushResponse, chang
 		seen[result.ChangeID] = struct{}{}
 		switch result.Status {
 		case "ok":
-			synced = append(synced, oplogID)
 		case "rejected":
-			oplog, ok := oplogByID[oplogID]
-			if !ok {
-				return nil, nil, fmt.Errorf("cloud sync push response referenced unknown oplog %d", oplogID)
+			if _, exists := rejected[oplogID]; !exists {
+				rejected[oplogID] = result
 			}
-			failedCount := oplog.CloudSyncPushFailedCount + 1
-			failures = append(failures, CloudSyncOplogPushFailure{
-				ID:          oplogID,
-				FailedCount: failedCount,
-				LastError:   cloudSyncAppliedChangeError(result),
-				Discarded:   failedCount >= cloudSyncMaxOplogPushFailures,
-			})
 		default:
 			return nil, nil, fmt.Errorf("cloud sync push response has unsupported change status %q", result.Status)
 		}
@@ -923,9 +1042,45 @@ func (m *CloudSyncManager) resolvePushResults(resp *CloudSyncPushResponse, chang
 			return nil, nil, fmt.Errorf("cloud sync push response missing result for change %s", change.ChangeID)
 		}
 	}
+
+	// A chunked oplog counts as synced only when every one of its changes was
+	// accepted, including the manifest that a partial batch doesn't carry yet.
+	var synced []uint
+	var failures []CloudSyncOplogPushFailure
+	for _, oplogID := range uniqueCloudSyncOplogIDs(oplogIds) {
+		result, isRejected := rejected[oplogID]
+		if !isRejected {
+			if _, isPartial := partial[oplogID]; !isPartial {
+				synced = append(synced, oplogID)
+			}
+			continue
+		}
+		oplog, ok := oplogByID[oplogID]
+		if !ok {
+			return nil, nil, fmt.Errorf("cloud sync push response referenced unknown oplog %d", oplogID)
+		}
+		failedCount := oplog.CloudSyncPushFailedCount + 1
+		failures = append(failures, CloudSyncOplogPushFailure{
+			ID:          oplogID,
+			FailedCount: failedCount,
+			LastError:   cloudSyncAppliedChangeError(result),
+			Discarded:   failedCount >= cloudSyncMaxOplogPushFailures,
+		})
+	}
 	return synced, failures, nil
 }
 
+// uniqueCloudSyncOplogIDs collapses the per-change oplog IDs of chunked oplogs in batch order.
+func uniqueCloudSyncOplogIDs(oplogIds []uint) []uint {
+	unique := make([]uint, 0, len(oplogIds))
+	for _, oplogID := range oplogIds {
+		if !slices.Contains(unique, oplogID) {
+			unique = append(unique, oplogID)
+		}
+	}
+	return unique
+}
+
 // cloudSyncAppliedChangeError uses the server-localized message for user-facing diagnostics.
 func cloudSyncAppliedChangeError(result CloudSyncAppliedChange) string {
 	if result.Message != "" {
@@ -960,7 +1115,7 @@ func cloudSyncPushHistoryDetails(changes []CloudSyncChange, oplogIds []uint, syn
 
 	details := make([]CloudSyncHistoryRecordDetail, 0, len(syncedIds)+len(failures))
 	for i, oplogID := range oplogIds {
-		if i >= len(changes) {
+		if i >= len(changes) || isCloudSyncChunkKey(changes[i].Key) {
 			continue
 		}
 		status := ""
@@ -1007,12 +1162,45 @@ func lastCloudSyncOplogPushFailureError(failures []CloudSyncOplogPushFailure) st
 	return "cloud sync change rejected"
 }
 
-func (m *CloudSyncManager) oplogToChange(ctx context.Context, oplog database.Oplog) (CloudSyncChange, error) {
-	pluginId := ""
-	if oplog.EntityType == EntityPluginSetting {
-		pluginId = oplog.EntityID
+// oplogToChanges converts one oplog into its push changes. Upserts of data
+// entities larger than their chunk size become chunk changes plus a manifest.
+func (m *CloudSyncManager) oplogToChanges(ctx context.Context, oplog database.Oplog) ([]CloudSyncChange, error) {
+	spec, isDataEntity := ResolveCloudSyncEntitySpec(oplog.EntityType)
+	if !isDataEntity || oplog.Operation != OpUpsert || spec.ChunkBytes <= 0 || len(oplog.Value) <= spec.ChunkBytes {
+		change, err := m.oplogToChange(ctx, oplog)
+		if err != nil {
+			return nil, err
+		}
+		return []CloudSyncChange{change}, nil
 	}
 
+	chunks, manifest, err := buildCloudSyncChunks(oplog.Value, spec.ChunkBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to split %s value into chunks: %w", oplog.EntityType, err)
+	}
+	changes := make([]CloudSyncChange, 0, len(chunks)+1)
+	for index, chunk := range chunks {
+		chunkOplog := oplog
+		chunkOplog.Key = cloudSyncChunkKey(oplog.Key, index)
+		chunkOplog.Value = chunk
+		change, err := m.oplogToChange(ctx, chunkOplog)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	manifestOplog := oplog
+	manifestOplog.Value = manifest
+	change, err := m.oplogToChange(ctx, manifestOplog)
+	if err != nil {
+		return nil, err
+	}
+	return append(changes, change), nil
+}
+
+func (m *CloudSyncManager) oplogToChange(ctx context.Context, oplog database.Oplog) (CloudSyncChange, error) {
+	pluginId := OplogPluginID(oplog)
+
 	var encrypted *CloudSyncEncryptedValue
 	if oplog.Operation == OpUpsert {
 		aad := buildCloudSyncAAD(oplog.EntityType, pluginId, oplog.Key, oplog.Operation)
@@ -1034,20 +1222,29 @@ func (m *CloudSyncManager) oplogToChange(ctx context.Context, oplog database.Opl
 	}, nil
 }
 
-func (m *CloudSyncManager) filterOplogsByDisabledPlugins(ctx context.Context, oplogs []database.Oplog) ([]database.Oplog, []uint) {
+// filterOplogsByExclusions drops oplogs of excluded plugins and of data entity types the user turned off.
+func (m *CloudSyncManager) filterOplogsByExclusions(ctx context.Context, oplogs []database.Oplog) ([]database.Oplog, []uint) {
 	disabled := m.disabledPluginSet(ctx)
-	if len(disabled) == 0 {
+	disabledEntities := m.disabledEntitySet(ctx)
+	if len(disabled) == 0 && len(disabledEntities) == 0 {
 		return oplogs, nil
 	}
 
 	eligible := make([]database.Oplog, 0, len(oplogs))
 	var dropped []uint
 	for _, oplog := range oplogs {
-		if oplog.EntityType == EntityPluginSetting || oplog.EntityType == EntityInstalledPlugin {
-			pluginID := oplog.EntityID
+		if _, blocked := disabledEntities[oplog.EntityType]; blocked {
+			dropped = append(dropped, oplog.ID)
+			continue
+		}
+		pluginID := OplogPluginID(oplog)
+		if oplog.EntityType == EntityInstalledPlugin {
+			pluginID = oplog.EntityID
 			if pluginID == "" {
 				pluginID = oplog.Key
 			}
+		}
+		if pluginID != "" {
 			if _, blocked := disabled[pluginID]; blocked {
 				dropped = append(dropped, oplog.ID)
 				continue
@@ -1059,6 +1256,22 @@ func (m *CloudSyncManager) filterOplogsByDisabledPlugins(ctx context.Context, op
 	return eligible, dropped
 }
 
+// disabledEntitySet returns the data entity types the user opted out of.
+func (m *CloudSyncManager) disabledEntitySet(ctx context.Context) map[string]struct{} {
+	provider, ok := m.exclusions.(CloudSyncEntityExclusionProvider)
+	if !ok {
+		return nil
+	}
+
+	disabled := map[string]struct{}{}
+	for _, entityType := range provider.DisabledEntityTypes(ctx) {
+		if _, isDataEntity := ResolveCloudSyncEntitySpec(entityType); isDataEntity {
+			disabled[entityType] = struct{}{}
+		}
+	}
+	return disabled
+}
+
 func (m *CloudSyncManager) disabledPluginSet(ctx context.Context) map[string]struct{} {
 	if m.exclusions == nil {
 		return nil
@@ -1304,7 +1517,7 @@ func buildCloudSyncAAD(entityType string, pluginId string, key string, op string
 
 func addCloudSyncChangeEntityCounts(counts map[string]int, changes []CloudSyncChange) {
 	for _, change := range changes {
-		if change.EntityType != "" {
+		if change.EntityType != "" && !isCloudSyncChunkKey(change.Key) {
 			counts[change.EntityType]++
 		}
 	}
@@ -1312,7 +1525,7 @@ func addCloudSyncChangeEntityCounts(counts map[string]int, changes []CloudSyncCh
 
 func addCloudSyncRecordEntityCounts(counts map[string]int, records []CloudSyncRecord) {
 	for _, record := range records {
-		if record.EntityType != "" {
+		if record.EntityType != "" && !isCloudSyncChunkKey(record.Key) {
 			counts[record.EntityType]++
 		}
 	}
@@ -1321,6 +1534,9 @@ func addCloudSyncRecordEntityCounts(counts map[string]int, records []CloudSyncRe
 func cloudSyncChangeRecordKeys(changes []CloudSyncChange) []CloudSyncRecordKey {
 	keys := make([]CloudSyncRecordKey, 0, len(changes))
 	for _, change := range changes {
+		if isCloudSyncChunkKey(change.Key) {
+			continue
+		}
 		keys = append(keys, CloudSyncRecordKey{
 			EntityType: change.EntityType,
 			PluginID:   change.PluginID,
@@ -1334,6 +1550,9 @@ func cloudSyncChangeRecordKeys(changes []CloudSyncChange) []CloudSyncRecordKey {
 func cloudSyncRecordKeys(records []CloudSyncRecord) []CloudSyncRecordKey {
 	keys := make([]CloudSyncRecordKey, 0, len(records))
 	for _, record := range records {
+		if isCloudSyncChunkKey(record.Key) {
+			continue
+		}
 		keys = append(keys, CloudSyncRecordKey{
 			EntityType: record.EntityType,
 			PluginID:   record.PluginID,
@@ -51,6 +51,26 @@ func (s *DefaultOplogStore) CountPending(ctx context.Context) (int, error) {
 	return int(count), nil
 }
 
+// LatestPendingTimestamp returns the newest unsynced local write for one sync identity, or 0 when none is queued.
+func (s *DefaultOplogStore) LatestPendingTimestamp(ctx context.Context, entityType string, entityID string, key string) (int64, error) {
+	_ = ctx
+	db := database.GetDB()
+	if db == nil {
+		return 0, fmt.Errorf("database not initialized")
+	}
+
+	var latest database.Oplog
+	result := db.Where("synced_to_cloud = ? AND cloud_sync_discarded = ? AND entity_type = ? AND entity_id = ? AND key = ?", false, false, entityType, entityID, key).
+		Order("timestamp desc").Limit(1).Find(&latest)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, nil
+	}
+	return latest.Timestamp, nil
+}
+
 func (s *DefaultOplogStore) MarkSynced(ctx context.Context, ids []uint) error {
 	_ = ctx
 	if len(ids) == 0 {
@@ -97,3 +117,86 @@ func (s *DefaultOplogStore) MarkPushFailed(ctx context.Context, failures []Cloud
 		return nil
 	})
 }
+
+// WriteOplog persists a local sync row according to the built-in CloudSync timing policy.
+func WriteOplog(db *gorm.DB, oplog database.Oplog) error {
+	now := util.GetSystemTimestamp()
+	oplog.Timestamp = now
+
+	if oplog.Operation == OpDelete {
+		if err := writeImmediateDeleteCloudSyncOplog(db, oplog); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	policy := ResolveOplogSyncPolicy(oplog.EntityType, oplog.EntityID, oplog.Key, oplog.Operation)
+	if policy.Delay <= 0 {
+		if err := db.Create(&oplog).Error; err != nil {
+			return err
+		}
+		return nil
+	}
+
+	return upsertDeferredCloudSyncOplog(db, oplog, now+policy.Delay.Milliseconds(), now)
+}
+
+// upsertDeferredCloudSyncOplog coalesces high-churn settings into one pending latest-wins row.
+func upsertDeferredCloudSyncOplog(db *gorm.DB, oplog database.Oplog, syncAfter int64, now int64) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var existing []database.Oplog
+		if err := tx.Where(
+			"synced_to_cloud = ? AND cloud_sync_discarded = ? AND entity_type = ? AND entity_id = ? AND operation = ? AND key = ?",
+			false,
+			false,
+			oplog.EntityType,
+			oplog.EntityID,
+			oplog.Operation,
+			oplog.Key,
+		).Order("id asc").Find(&existing).Error; err != nil {
+			return err
+		}
+		if len(existing) == 0 {
+			oplog.SyncAfter = syncAfter
+			return tx.Create(&oplog).Error
+		}
+
+		keepID := existing[0].ID
+		if len(existing) > 1 {
+			supersededIDs := make([]uint, 0, len(existing)-1)
+			for _, row := range existing[1:] {
+				supersededIDs = append(supersededIDs, row.ID)
+			}
+			if err := tx.Model(&database.Oplog{}).Where("id IN ?", supersededIDs).Update("synced_to_cloud", true).Error; err != nil {
+				return err
+			}
+		}
+
+		// Keep one pending row per delayed setting identity so rate limits or offline time do not replay stale intermediate values.
+		return tx.Model(&database.Oplog{}).Where("id = ?", keepID).Updates(map[string]interface{}{
+			"value":                        oplog.Value,
+			"timestamp":                    now,
+			"sync_after":                   syncAfter,
+			"cloud_sync_push_failed_count": 0,
+			"cloud_sync_last_push_error":   "",
+		}).Error
+	})
+}
+
+// writeImmediateDeleteCloudSyncOplog prevents an older delayed upsert from resurrecting a deleted setting remotely.
+func writeImmediateDeleteCloudSyncOplog(db *gorm.DB, oplog database.Oplog) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&database.Oplog{}).Where(
+			"synced_to_cloud = ? AND cloud_sync_discarded = ? AND entity_type = ? AND entity_id = ? AND operation = ? AND key = ?",
+			false,
+			false,
+			oplog.EntityType,
+			oplog.EntityID,
+			OpUpsert,
+			oplog.Key,
+		).Update("synced_to_cloud", true).Error; err != nil {
+			return err
+		}
+		return tx.Create(&oplog).Error
+	})
+}
@@ -18,13 +18,21 @@ func ResolveOplogSyncPolicy(entityType string, entityID string, key string, op s
 
 	if entityType == EntityWoxSetting {
 		switch key {
-		case "ActionedResults", "QueryHistories":
+		case "ActionedResults":
 			return OplogSyncPolicy{Delay: 10 * time.Minute}
 		default:
 			return OplogSyncPolicy{}
 		}
 	}
 
+	switch entityType {
+	case EntityMRU, EntityQueryHistory:
+		return OplogSyncPolicy{Delay: 10 * time.Minute}
+	case EntityAIChat:
+		// Streaming replies save a chat many times per answer, so only the settled copy is uploaded.
+		return OplogSyncPolicy{Delay: 2 * time.Minute}
+	}
+
 	// Rss reader plugin
 	if entityType == EntityPluginSetting && entityID == "9575a1fc-d81b-4947-bcce-bd075f118f3e" {
 		if strings.HasPrefix(key, "feedItems") {
@@ -0,0 +1,533 @@
+package cloudsync
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"wox/util"
+)
+
+const (
+	cloudSyncStorageKeyObject      = "key.json"
+	cloudSyncStorageDevicesPrefix  = "devices/"
+	cloudSyncStorageChangesPrefix  = "changes/"
+	cloudSyncStorageResetTokenTTL  = 10 * time.Minute
+	cloudSyncStorageChangeNameSize = 20
+)
+
+var cloudSyncStorageDeviceIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// CloudSyncStorageClient speaks the sync protocol against a CloudSyncTransport
+// instead of the Wox sync service, so settings can live on storage the user
+// controls. Values arrive already encrypted by CloudSyncCrypto and the data
+// key is wrapped with the recovery code, so the storage never sees plaintext.
+//
+// Layout below the transport root:
+//
+//	key.json                         wrapped data key and KDF parameters
+//	devices/<device id>.json         device metadata and revocation
+//	changes/<device id>/<seq>.json   one pushed batch
+//
+// Every device only writes batches into its own folder, which keeps file sync
+// tools free of write conflicts. The pull cursor records the last batch read
+// from each device folder.
+type CloudSyncStorageClient struct {
+	transport CloudSyncTransport
+
+	mu             sync.Mutex
+	lastChangeSeq  int64
+	resetToken     string
+	resetExpiresAt time.Time
+	// Batches are never rewritten, so each one is downloaded only once.
+	batchCacheMu sync.Mutex
+	batchCache   map[string]cloudSyncStorageBatch
+}
+
+// cloudSyncStorageBatch is the content of one changes/<device id>/<seq>.json object.
+type cloudSyncStorageBatch struct {
+	DeviceID string            `json:"device_id"`
+	Records  []CloudSyncRecord `json:"records"`
+}
+
+type cloudSyncStorageCursor map[string]string
+
+func NewCloudSyncStorageClient(transport CloudSyncTransport) *CloudSyncStorageClient {
+	return &CloudSyncStorageClient{transport: transport, batchCache: map[string]cloudSyncStorageBatch{}}
+}
+
+func (c *CloudSyncStorageClient) Push(ctx context.Context, req CloudSyncPushRequest) (*CloudSyncPushResponse, error) {
+	if err := c.ensureDeviceActive(ctx, req.DeviceID); err != nil {
+		return nil, err
+	}
+
+	serverTs := util.GetSystemTimestamp()
+	resp := &CloudSyncPushResponse{ServerTs: serverTs}
+	batch := cloudSyncStorageBatch{DeviceID: req.DeviceID}
+	for _, change := range req.Changes {
+		if change.Op != OpUpsert && change.Op != OpDelete {
+			resp.Applied = append(resp.Applied, CloudSyncAppliedChange{ChangeID: change.ChangeID, Status: "rejected", Code: "invalid_op", Message: "unsupported operation " + change.Op})
+			continue
+		}
+		if change.Op == OpUpsert && change.Value == nil {
+			resp.Applied = append(resp.Applied, CloudSyncAppliedChange{ChangeID: change.ChangeID, Status: "rejected", Code: "invalid_value", Message: "upsert without value"})
+			continue
+		}
+		batch.Records = append(batch.Records, CloudSyncRecord{
+			EntityType: change.EntityType,
+			PluginID:   change.PluginID,
+			Key:        change.Key,
+			Op:         change.Op,
+			ServerTs:   serverTs,
+			ClientTs:   change.ClientTs,
+			Value:      change.Value,
+		})
+		resp.Applied = append(resp.Applied, CloudSyncAppliedChange{ChangeID: change.ChangeID, Status: "ok", ServerTs: serverTs})
+	}
+	if len(batch.Records) == 0 {
+		return resp, nil
+	}
+
+	name, err := c.nextChangeName(ctx, req.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.putJSON(ctx, name, batch); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Pull returns batches written by other devices after the cursor, oldest first.
+func (c *CloudSyncStorageClient) Pull(ctx context.Context, req CloudSyncPullRequest) (*CloudSyncPullResponse, error) {
+	if err := c.ensureDeviceActive(ctx, req.DeviceID); err != nil {
+		return nil, err
+	}
+	cursor := decodeCloudSyncStorageCursor(req.Cursor)
+	batches, err := c.listChangeBatches(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &CloudSyncPullResponse{Records: []CloudSyncRecord{}}
+	for _, batch := range batches {
+		if batch.seq <= cursor[batch.deviceID] {
+			continue
+		}
+		if req.Limit > 0 && len(resp.Records) >= req.Limit {
+			resp.HasMore = true
+			break
+		}
+		// This device's own batches are already applied locally.
+		if batch.deviceID != req.DeviceID {
+			content, err := c.readBatch(ctx, batch.name)
+			if err != nil {
+				return nil, err
+			}
+			resp.Records = append(resp.Records, content.Records...)
+		}
+		cursor[batch.deviceID] = batch.seq
+	}
+	nextCursor, err := json.Marshal(cursor)
+	if err != nil {
+		return nil, err
+	}
+	resp.NextCursor = string(nextCursor)
+	return resp, nil
+}
+
+// Snapshot returns the latest upsert of every key. The cursor is an offset into the sorted key list.
+func (c *CloudSyncStorageClient) Snapshot(ctx context.Context, req CloudSyncPullRequest) (*CloudSyncPullResponse, error) {
+	if err := c.ensureDeviceActive(ctx, req.DeviceID); err != nil {
+		return nil, err
+	}
+	latest, err := c.latestRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var upserts []CloudSyncRecord
+	for _, record := range latest {
+		if record.Op == OpUpsert {
+			upserts = append(upserts, record)
+		}
+	}
+
+	offset, _ := strconv.Atoi(req.Cursor)
+	offset = max(0, min(offset, len(upserts)))
+	end := len(upserts)
+	if req.Limit > 0 {
+		end = min(offset+req.Limit, len(upserts))
+	}
+	return &CloudSyncPullResponse{
+		Records:    append([]CloudSyncRecord{}, upserts[offset:end]...),
+		NextCursor: strconv.Itoa(end),
+		HasMore:    end < len(upserts),
+	}, nil
+}
+
+func (c *CloudSyncStorageClient) ListRecordKeys(ctx context.Context, req CloudSyncRecordKeyListRequest) (*CloudSyncRecordKeyListResponse, error) {
+	if err := c.ensureDeviceActive(ctx, req.DeviceID); err != nil {
+		return nil, err
+	}
+	latest, err := c.latestRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := &CloudSyncRecordKeyListResponse{Keys: make([]CloudSyncRecordKey, 0, len(latest))}
+	for _, record := range latest {
+		resp.Keys = append(resp.Keys, CloudSyncRecordKey{EntityType: record.EntityType, PluginID: record.PluginID, Key: record.Key, Op: record.Op})
+	}
+	return resp, nil
+}
+
+func (c *CloudSyncStorageClient) ListDevices(ctx context.Context, req CloudSyncDeviceListRequest) (*CloudSyncDeviceListResponse, error) {
+	names, err := c.transport.List(ctx, cloudSyncStorageDevicesPrefix)
+	if err != nil {
+		return nil, err
+	}
+	resp := &CloudSyncDeviceListResponse{Devices: []CloudSyncDevice{}, CurrentDeviceID: req.DeviceID}
+	for _, name := range names {
+		var device CloudSyncDevice
+		if err := c.getJSON(ctx, name, &device); err != nil {
+			if errors.Is(err, ErrCloudSyncObjectNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		device.Current = device.DeviceID == req.DeviceID
+		if device.RevokedAt == 0 {
+			resp.DeviceCount++
+		}
+		resp.Devices = append(resp.Devices, device)
+	}
+	sort.SliceStable(resp.Devices, func(i, j int) bool {
+		return resp.Devices[i].CreatedAt < resp.Devices[j].CreatedAt
+	})
+	return resp, nil
+}
+
+func (c *CloudSyncStorageClient) UpdateDevice(ctx context.Context, req CloudSyncDeviceUpdateRequest) (*CloudSyncDeviceUpdateResponse, error) {
+	device, err := c.saveDevice(ctx, req.DeviceID, req.DeviceName, req.Platform, false)
+	if err != nil {
+		return nil, err
+	}
+	return &CloudSyncDeviceUpdateResponse{DeviceID: device.DeviceID, DeviceName: device.DeviceName, Platform: device.Platform, LastSeenAt: device.LastSeenAt}, nil
+}
+
+func (c *CloudSyncStorageClient) JoinDevice(ctx context.Context, req CloudSyncDeviceJoinRequest) (*CloudSyncDeviceJoinResponse, error) {
+	device, err := c.saveDevice(ctx, req.DeviceID, req.DeviceName, req.Platform, true)
+	if err != nil {
+		return nil, err
+	}
+	return &CloudSyncDeviceJoinResponse{DeviceID: device.DeviceID, DeviceName: device.DeviceName, Platform: device.Platform, LastSeenAt: device.LastSeenAt, Current: true}, nil
+}
+
+func (c *CloudSyncStorageClient) RevokeDevice(ctx context.Context, req CloudSyncDeviceRevokeRequest) (*CloudSyncDeviceRevokeResponse, error) {
+	name, err := cloudSyncStorageDeviceObject(req.TargetDeviceID)
+	if err != nil {
+		return nil, err
+	}
+	var device CloudSyncDevice
+	if err := c.getJSON(ctx, name, &device); err != nil {
+		if errors.Is(err, ErrCloudSyncObjectNotFound) {
+			return nil, &CloudSyncRequestError{Code: "device_not_found", Message: "device not found"}
+		}
+		return nil, err
+	}
+	if device.RevokedAt == 0 {
+		device.RevokedAt = util.GetSystemTimestamp()
+		device.Current = false
+		if err := c.putJSON(ctx, name, device); err != nil {
+			return nil, err
+		}
+	}
+	return &CloudSyncDeviceRevokeResponse{OK: true, RevokedAt: device.RevokedAt}, nil
+}
+
+func (c *CloudSyncStorageClient) Status(ctx context.Context) (CloudSyncKeyStatus, error) {
+	var key CloudSyncKeyFetchResponse
+	if err := c.getJSON(ctx, cloudSyncStorageKeyObject, &key); err != nil {
+		if errors.Is(err, ErrCloudSyncObjectNotFound) {
+			return CloudSyncKeyStatus{Available: false}, nil
+		}
+		return CloudSyncKeyStatus{}, err
+	}
+	return CloudSyncKeyStatus{Available: true, Version: key.KeyVersion}, nil
+}
+
+func (c *CloudSyncStorageClient) InitKey(ctx context.Context, req CloudSyncKeyInitRequest) (*CloudSyncKeyInitResponse, error) {
+	status, err := c.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if status.Available {
+		return nil, &CloudSyncRequestError{Code: "key_exists", Message: "a sync key already exists in this storage"}
+	}
+	version := max(req.KeyVersion, 1)
+	if err := c.putJSON(ctx, cloudSyncStorageKeyObject, CloudSyncKeyFetchResponse{KeyVersion: version, KDF: req.KDF, EncryptedDEK: req.EncryptedDEK}); err != nil {
+		return nil, err
+	}
+	return &CloudSyncKeyInitResponse{KeyVersion: version, CreatedAt: util.GetSystemTimestamp()}, nil
+}
+
+func (c *CloudSyncStorageClient) FetchKey(ctx context.Context, req CloudSyncKeyFetchRequest) (*CloudSyncKeyFetchResponse, error) {
+	var key CloudSyncKeyFetchResponse
+	if err := c.getJSON(ctx, cloudSyncStorageKeyObject, &key); err != nil {
+		if errors.Is(err, ErrCloudSyncObjectNotFound) {
+			return nil, &CloudSyncRequestError{Code: "key_not_found", Message: "no sync key exists in this storage"}
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// PrepareKeyReset hands out a short-lived token so a reset needs two explicit steps, like the hosted service.
+func (c *CloudSyncStorageClient) PrepareKeyReset(ctx context.Context) (*CloudSyncKeyResetPrepareResponse, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.resetToken = hex.EncodeToString(buf)
+	c.resetExpiresAt = time.Now().Add(cloudSyncStorageResetTokenTTL)
+	return &CloudSyncKeyResetPrepareResponse{ResetToken: c.resetToken, ExpiresAt: c.resetExpiresAt.UnixMilli()}, nil
+}
+
+// ResetKey removes the key and all pushed batches, which can no longer be decrypted without it.
+func (c *CloudSyncStorageClient) ResetKey(ctx context.Context, req CloudSyncKeyResetRequest) (*CloudSyncKeyResetResponse, error) {
+	c.mu.Lock()
+	validToken := req.Confirm && c.resetToken != "" && req.ResetToken == c.resetToken && time.Now().Before(c.resetExpiresAt)
+	if validToken {
+		c.resetToken = ""
+	}
+	c.mu.Unlock()
+	if !validToken {
+		return nil, &CloudSyncRequestError{Code: "invalid_reset_token", Message: "the reset token is invalid or expired"}
+	}
+
+	names, err := c.transport.List(ctx, cloudSyncStorageChangesPrefix)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if err := c.transport.Delete(ctx, name); err != nil {
+			return nil, err
+		}
+	}
+	c.batchCacheMu.Lock()
+	c.batchCache = map[string]cloudSyncStorageBatch{}
+	c.batchCacheMu.Unlock()
+	if err := c.transport.Delete(ctx, cloudSyncStorageKeyObject); err != nil {
+		return nil, err
+	}
+	return &CloudSyncKeyResetResponse{ResetAt: util.GetSystemTimestamp()}, nil
+}
+
+func (c *CloudSyncStorageClient) saveDevice(ctx context.Context, deviceID string, deviceName string, platform string, join bool) (CloudSyncDevice, error) {
+	name, err := cloudSyncStorageDeviceObject(deviceID)
+	if err != nil {
+		return CloudSyncDevice{}, err
+	}
+	now := util.GetSystemTimestamp()
+	device := CloudSyncDevice{DeviceID: deviceID, CreatedAt: now}
+	if err := c.getJSON(ctx, name, &device); err != nil && !errors.Is(err, ErrCloudSyncObjectNotFound) {
+		return CloudSyncDevice{}, err
+	}
+	if device.RevokedAt != 0 {
+		if !join {
+			return CloudSyncDevice{}, cloudSyncStorageDeviceRevokedError()
+		}
+		device.RevokedAt = 0
+	}
+	device.DeviceName = deviceName
+	device.Platform = platform
+	device.UpdatedAt = now
+	device.LastSeenAt = now
+	device.Current = false
+	if err := c.putJSON(ctx, name, device); err != nil {
+		return CloudSyncDevice{}, err
+	}
+	return device, nil
+}
+
+// ensureDeviceActive rejects revoked devices the same way the hosted service does, which stops their sync loop.
+func (c *CloudSyncStorageClient) ensureDeviceActive(ctx context.Context, deviceID string) error {
+	name, err := cloudSyncStorageDeviceObject(deviceID)
+	if err != nil {
+		return err
+	}
+	var device CloudSyncDevice
+	if err := c.getJSON(ctx, name, &device); err != nil {
+		if errors.Is(err, ErrCloudSyncObjectNotFound) {
+			return nil
+		}
+		return err
+	}
+	if device.RevokedAt != 0 {
+		return cloudSyncStorageDeviceRevokedError()
+	}
+	return nil
+}
+
+func cloudSyncStorageDeviceRevokedError() error {
+	return &CloudSyncRequestError{Code: "device_revoked", Message: "this device was removed from sync"}
+}
+
+type cloudSyncStorageBatchRef struct {
+	name     string
+	deviceID string
+	seq      string
+}
+
+// listChangeBatches returns all batches ordered by write time across devices.
+func (c *CloudSyncStorageClient) listChangeBatches(ctx context.Context) ([]cloudSyncStorageBatchRef, error) {
+	names, err := c.transport.List(ctx, cloudSyncStorageChangesPrefix)
+	if err != nil {
+		return nil, err
+	}
+	batches := make([]cloudSyncStorageBatchRef, 0, len(names))
+	for _, name := range names {
+		deviceID, file, ok := strings.Cut(strings.TrimPrefix(name, cloudSyncStorageChangesPrefix), "/")
+		seq, isJSON := strings.CutSuffix(file, ".json")
+		if !ok || !isJSON || strings.Contains(seq, "/") || len(seq) != cloudSyncStorageChangeNameSize {
+			continue
+		}
+		batches = append(batches, cloudSyncStorageBatchRef{name: name, deviceID: deviceID, seq: seq})
+	}
+	sort.SliceStable(batches, func(i, j int) bool {
+		if batches[i].seq != batches[j].seq {
+			return batches[i].seq < batches[j].seq
+		}
+		return batches[i].deviceID < batches[j].deviceID
+	})
+	return batches, nil
+}
+
+// latestRecords folds all batches into the newest record per key, sorted by key.
+func (c *CloudSyncStorageClient) latestRecords(ctx context.Context) ([]CloudSyncRecord, error) {
+	batches, err := c.listChangeBatches(ctx)
+	if err != nil {
+		return nil, err
+	}
+	latest := map[string]CloudSyncRecord{}
+	for _, batch := range batches {
+		content, err := c.readBatch(ctx, batch.name)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range content.Records {
+			key := cloudSyncStorageRecordKey(record)
+			if existing, ok := latest[key]; ok && existing.ServerTs > record.ServerTs {
+				continue
+			}
+			latest[key] = record
+		}
+	}
+	keys := make([]string, 0, len(latest))
+	for key := range latest {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	records := make([]CloudSyncRecord, 0, len(keys))
+	for _, key := range keys {
+		records = append(records, latest[key])
+	}
+	return records, nil
+}
+
+// nextChangeName returns a batch name that sorts after every earlier batch of
+// this device, even when the clock moved backwards since the last push.
+func (c *CloudSyncStorageClient) nextChangeName(ctx context.Context, deviceID string) (string, error) {
+	if !cloudSyncStorageDeviceIDPattern.MatchString(deviceID) {
+		return "", fmt.Errorf("invalid cloud sync device id %q", deviceID)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lastChangeSeq == 0 {
+		names, err := c.transport.List(ctx, cloudSyncStorageChangesPrefix+deviceID+"/")
+		if err != nil {
+			return "", err
+		}
+		for _, name := range names {
+			seq, err := strconv.ParseInt(strings.TrimSuffix(path.Base(name), ".json"), 10, 64)
+			if err == nil && seq > c.lastChangeSeq {
+				c.lastChangeSeq = seq
+			}
+		}
+	}
+	seq := max(time.Now().UnixMicro(), c.lastChangeSeq+1)
+	c.lastChangeSeq = seq
+	return fmt.Sprintf("%s%s/%0*d.json", cloudSyncStorageChangesPrefix, deviceID, cloudSyncStorageChangeNameSize, seq), nil
+}
+
+func (c *CloudSyncStorageClient) readBatch(ctx context.Context, name string) (cloudSyncStorageBatch, error) {
+	c.batchCacheMu.Lock()
+	batch, ok := c.batchCache[name]
+	c.batchCacheMu.Unlock()
+	if ok {
+		return batch, nil
+	}
+	if err := c.getJSON(ctx, name, &batch); err != nil {
+		// A batch deleted by a key reset between listing and reading is simply gone.
+		if errors.Is(err, ErrCloudSyncObjectNotFound) {
+			return cloudSyncStorageBatch{}, nil
+		}
+		return cloudSyncStorageBatch{}, err
+	}
+	c.batchCacheMu.Lock()
+	c.batchCache[name] = batch
+	c.batchCacheMu.Unlock()
+	return batch, nil
+}
+
+func (c *CloudSyncStorageClient) getJSON(ctx context.Context, name string, target any) error {
+	data, err := c.transport.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", name, err)
+	}
+	return nil
+}
+
+func (c *CloudSyncStorageClient) putJSON(ctx context.Context, name string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+	return c.transport.Put(ctx, name, data)
+}
+
+func cloudSyncStorageDeviceObject(deviceID string) (string, error) {
+	if !cloudSyncStorageDeviceIDPattern.MatchString(deviceID) {
+		return "", fmt.Errorf("invalid cloud sync device id %q", deviceID)
+	}
+	return cloudSyncStorageDevicesPrefix + deviceID + ".json", nil
+}
+
+func cloudSyncStorageRecordKey(record CloudSyncRecord) string {
+	return record.EntityType + "\x00" + record.PluginID + "\x00" + record.Key
+}
+
+// decodeCloudSyncStorageCursor treats cursors from another backend as empty,
+// which replays all batches once; applying records is idempotent.
+func decodeCloudSyncStorageCursor(cursor string) cloudSyncStorageCursor {
+	decoded := cloudSyncStorageCursor{}
+	if cursor != "" {
+		if err := json.Unmarshal([]byte(cursor), &decoded); err != nil || decoded == nil {
+			return cloudSyncStorageCursor{}
+		}
+	}
+	return decoded
+}
@@ -0,0 +1,99 @@
+package cloudsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	CloudSyncBackendWox    = ""
+	CloudSyncBackendWebDAV = "webdav"
+	CloudSyncBackendS3     = "s3"
+	CloudSyncBackendFolder = "folder"
+)
+
+var ErrCloudSyncObjectNotFound = errors.New("cloud sync object not found")
+
+// CloudSyncBackendConfig selects where settings are synced. An empty Type keeps
+// the Wox sync service; the other backends are self-hosted and do not need a
+// Wox account.
+type CloudSyncBackendConfig struct {
+	Type string
+	// Url is the WebDAV collection, the S3 endpoint or the shared folder path.
+	Url string
+	// Bucket and Region are only used by S3. Bucket may carry a key prefix.
+	Bucket string
+	Region string
+	// Username and Password are the WebDAV credentials or the S3 access key pair.
+	Username string
+	Password string
+}
+
+func (c CloudSyncBackendConfig) IsSelfHosted() bool {
+	return strings.TrimSpace(c.Type) != CloudSyncBackendWox
+}
+
+// NewCloudSyncTransport creates the transport for a self-hosted backend.
+func NewCloudSyncTransport(config CloudSyncBackendConfig) (CloudSyncTransport, error) {
+	switch strings.TrimSpace(config.Type) {
+	case CloudSyncBackendWebDAV:
+		return NewWebDAVTransport(WebDAVTransportConfig{
+			URL:      config.Url,
+			Username: config.Username,
+			Password: config.Password,
+		})
+	case CloudSyncBackendS3:
+		return NewS3Transport(S3TransportConfig{
+			Endpoint:        config.Url,
+			Bucket:          config.Bucket,
+			Region:          config.Region,
+			AccessKeyID:     config.Username,
+			SecretAccessKey: config.Password,
+		})
+	case CloudSyncBackendFolder:
+		return NewFolderTransport(config.Url)
+	default:
+		return nil, fmt.Errorf("unsupported cloud sync backend: %s", config.Type)
+	}
+}
+
+// CloudSyncTransport is the storage behind a self-hosted sync backend. It only
+// stores opaque named objects; CloudSyncStorageClient builds the sync protocol
+// on top of it, so every backend shares the same encryption and cursor model.
+// Object names use "/" separators and never start with one.
+type CloudSyncTransport interface {
+	// Get returns ErrCloudSyncObjectNotFound when the object does not exist.
+	Get(ctx context.Context, name string) ([]byte, error)
+	// Put creates or replaces an object, creating parent folders as needed.
+	Put(ctx context.Context, name string, data []byte) error
+	// Delete succeeds when the object does not exist.
+	Delete(ctx context.Context, name string) error
+	// List returns the names of all objects below prefix, including nested ones.
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// validateCloudSyncObjectName rejects names that could escape the backend root.
+func validateCloudSyncObjectName(name string) error {
+	if name == "" || strings.HasPrefix(name, "/") || strings.Contains(name, `\`) {
+		return fmt.Errorf("invalid cloud sync object name %q", name)
+	}
+	for _, segment := range strings.Split(name, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("invalid cloud sync object name %q", name)
+		}
+	}
+	return nil
+}
+
+// isHiddenCloudSyncObject skips temporary files and the metadata folders that
+// file sync tools such as Syncthing keep next to the synced data.
+func isHiddenCloudSyncObject(name string) bool {
+	for _, segment := range strings.Split(name, "/") {
+		if strings.HasPrefix(segment, ".") {
+			return true
+		}
+	}
+	return false
+}
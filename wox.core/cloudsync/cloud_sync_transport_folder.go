@@ -0,0 +1,119 @@
+package cloudsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// FolderTransport keeps sync objects in a local folder that another tool
+// shares between devices, such as a Syncthing folder or an NFS mount.
+type FolderTransport struct {
+	root string
+}
+
+func NewFolderTransport(root string) (*FolderTransport, error) {
+	root = strings.TrimSpace(root)
+	if root == "" {
+		return nil, fmt.Errorf("cloud sync folder is empty")
+	}
+	if !filepath.IsAbs(root) {
+		return nil, fmt.Errorf("cloud sync folder must be an absolute path: %s", root)
+	}
+	return &FolderTransport{root: filepath.Clean(root)}, nil
+}
+
+func (t *FolderTransport) Get(ctx context.Context, name string) ([]byte, error) {
+	path, err := t.path(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrCloudSyncObjectNotFound
+	}
+	return data, err
+}
+
+// Put writes to a hidden temporary file first, so other devices never read a
+// partially synced object.
+func (t *FolderTransport) Put(ctx context.Context, name string, data []byte) error {
+	path, err := t.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tempPath := filepath.Join(filepath.Dir(path), ".wox-tmp-"+uuid.NewString())
+	if err := os.WriteFile(tempPath, data, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		_ = os.Remove(tempPath)
+		return err
+	}
+	return nil
+}
+
+func (t *FolderTransport) Delete(ctx context.Context, name string) error {
+	path, err := t.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (t *FolderTransport) List(ctx context.Context, prefix string) ([]string, error) {
+	var names []string
+	err := filepath.WalkDir(t.root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		relative, err := filepath.Rel(t.root, path)
+		if err != nil || relative == "." {
+			return nil
+		}
+		name := filepath.ToSlash(relative)
+		if isHiddenCloudSyncObject(name) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() {
+			// Only walk folders that can contain names below the prefix.
+			if !strings.HasPrefix(name+"/", prefix) && !strings.HasPrefix(prefix, name+"/") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+		return nil
+	})
+	return names, err
+}
+
+func (t *FolderTransport) path(name string) (string, error) {
+	if err := validateCloudSyncObjectName(name); err != nil {
+		return "", err
+	}
+	return filepath.Join(t.root, filepath.FromSlash(name)), nil
+}
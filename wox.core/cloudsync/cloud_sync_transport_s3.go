@@ -0,0 +1,285 @@
+package cloudsync
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"wox/util"
+)
+
+const defaultS3Region = "us-east-1"
+
+type S3TransportConfig struct {
+	// Endpoint is the service root, e.g. https://s3.amazonaws.com or http://127.0.0.1:9000 for MinIO.
+	Endpoint string
+	// Bucket may carry a key prefix, e.g. "backups/wox".
+	Bucket          string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	HTTPClient      *http.Client
+}
+
+// S3Transport keeps sync objects in an S3-compatible bucket. Requests use
+// path-style addressing and Signature Version 4, which AWS, MinIO, Cloudflare
+// R2 and Backblaze B2 all accept.
+type S3Transport struct {
+	endpoint        *url.URL
+	bucket          string
+	prefix          string
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+	httpClient      *http.Client
+	now             func() time.Time
+}
+
+func NewS3Transport(config S3TransportConfig) (*S3Transport, error) {
+	endpoint, err := url.Parse(strings.TrimSpace(config.Endpoint))
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint: %s", config.Endpoint)
+	}
+	endpoint.Path = strings.TrimRight(endpoint.Path, "/")
+	bucket, prefix, _ := strings.Cut(strings.Trim(strings.TrimSpace(config.Bucket), "/"), "/")
+	if bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is empty")
+	}
+	if prefix != "" {
+		prefix = strings.Trim(prefix, "/") + "/"
+	}
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, fmt.Errorf("S3 access key is empty")
+	}
+	region := strings.TrimSpace(config.Region)
+	if region == "" {
+		region = defaultS3Region
+	}
+	return &S3Transport{
+		endpoint:        endpoint,
+		bucket:          bucket,
+		prefix:          prefix,
+		region:          region,
+		accessKeyID:     config.AccessKeyID,
+		secretAccessKey: config.SecretAccessKey,
+		httpClient:      config.HTTPClient,
+		now:             time.Now,
+	}, nil
+}
+
+func (t *S3Transport) Get(ctx context.Context, name string) ([]byte, error) {
+	if err := validateCloudSyncObjectName(name); err != nil {
+		return nil, err
+	}
+	resp, err := t.do(ctx, http.MethodGet, t.prefix+name, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrCloudSyncObjectNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, s3StatusError(http.MethodGet, name, resp)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (t *S3Transport) Put(ctx context.Context, name string, data []byte) error {
+	if err := validateCloudSyncObjectName(name); err != nil {
+		return err
+	}
+	resp, err := t.do(ctx, http.MethodPut, t.prefix+name, nil, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3StatusError(http.MethodPut, name, resp)
+	}
+	return nil
+}
+
+func (t *S3Transport) Delete(ctx context.Context, name string) error {
+	if err := validateCloudSyncObjectName(name); err != nil {
+		return err
+	}
+	resp, err := t.do(ctx, http.MethodDelete, t.prefix+name, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound || (resp.StatusCode >= 200 && resp.StatusCode < 300) {
+		return nil
+	}
+	return s3StatusError(http.MethodDelete, name, resp)
+}
+
+type s3ListBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func (t *S3Transport) List(ctx context.Context, prefix string) ([]string, error) {
+	var names []string
+	continuationToken := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", t.prefix+prefix)
+		if continuationToken != "" {
+			query.Set("continuation-token", continuationToken)
+		}
+		resp, err := t.do(ctx, http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := s3StatusError("LIST", prefix, resp)
+			resp.Body.Close()
+			return nil, err
+		}
+		var result s3ListBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode S3 listing: %w", err)
+		}
+		for _, content := range result.Contents {
+			name := strings.TrimPrefix(content.Key, t.prefix)
+			if name != "" && !isHiddenCloudSyncObject(name) {
+				names = append(names, name)
+			}
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return names, nil
+		}
+		continuationToken = result.NextContinuationToken
+	}
+}
+
+func (t *S3Transport) do(ctx context.Context, method string, key string, query url.Values, body []byte) (*http.Response, error) {
+	target := *t.endpoint
+	target.Path = t.endpoint.Path + "/" + t.bucket
+	if key != "" {
+		target.Path += "/" + key
+	}
+	target.RawPath = s3EscapePath(target.Path)
+	target.RawQuery = s3CanonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	signS3Request(req, body, t.region, t.accessKeyID, t.secretAccessKey, t.now().UTC())
+
+	client := t.httpClient
+	if client == nil {
+		client = util.GetHTTPClient(ctx)
+	}
+	return client.Do(req)
+}
+
+// signS3Request adds an AWS Signature Version 4 Authorization header.
+func signS3Request(req *http.Request, body []byte, region string, accessKeyID string, secretAccessKey string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3EscapePath(req.URL.Path),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host + "\n" + "x-amz-content-sha256:" + payloadHash + "\n" + "x-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", accessKeyID, scope, signedHeaders, signature))
+}
+
+// s3CanonicalQuery sorts and escapes query parameters the way Signature Version 4 expects.
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		for _, value := range query[key] {
+			parts = append(parts, s3Escape(key, true)+"="+s3Escape(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func s3EscapePath(path string) string {
+	return s3Escape(path, false)
+}
+
+// s3Escape percent-encodes everything except RFC 3986 unreserved characters,
+// keeping "/" in paths.
+func s3Escape(value string, encodeSlash bool) string {
+	var builder strings.Builder
+	for _, b := range []byte(value) {
+		switch {
+		case b >= 'A' && b <= 'Z', b >= 'a' && b <= 'z', b >= '0' && b <= '9', b == '-', b == '_', b == '.', b == '~':
+			builder.WriteByte(b)
+		case b == '/' && !encodeSlash:
+			builder.WriteByte(b)
+		default:
+			fmt.Fprintf(&builder, "%%%02X", b)
+		}
+	}
+	return builder.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+type s3ErrorResponse struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+func s3StatusError(method string, name string, resp *http.Response) error {
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var s3Err s3ErrorResponse
+	if xml.Unmarshal(detail, &s3Err) == nil && s3Err.Code != "" {
+		return fmt.Errorf("S3 %s %s failed (%d): %s: %s", method, name, resp.StatusCode, s3Err.Code, s3Err.Message)
+	}
+	return fmt.Errorf("S3 %s %s failed (%d): %s", method, name, resp.StatusCode, strings.TrimSpace(string(detail)))
+}
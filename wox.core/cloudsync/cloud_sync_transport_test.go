@@ -0,0 +1,368 @@
+package cloudsync
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+func newTestCloudSyncTransports(t *testing.T) map[string]CloudSyncTransport {
+	t.Helper()
+
+	folder, err := NewFolderTransport(t.TempDir())
+	if err != nil {
+		t.Fatalf("create folder transport: %v", err)
+	}
+
+	davRoot := t.TempDir()
+	// The WebDAV collection must exist, like a folder the user created on their server.
+	if err := os.MkdirAll(filepath.Join(davRoot, "remote.php", "dav", "wox sync"), 0o755); err != nil {
+		t.Fatalf("create WebDAV collection: %v", err)
+	}
+	davHandler := &webdav.Handler{FileSystem: webdav.Dir(davRoot), LockSystem: webdav.NewMemLS()}
+	davServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "wox" || password != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		davHandler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(davServer.Close)
+	dav, err := NewWebDAVTransport(WebDAVTransportConfig{URL: davServer.URL + "/remote.php/dav/wox sync", Username: "wox", Password: "secret"})
+	if err != nil {
+		t.Fatalf("create WebDAV transport: %v", err)
+	}
+
+	s3Server := httptest.NewServer(newTestS3Server(t, "minio", "minio-secret"))
+	t.Cleanup(s3Server.Close)
+	s3, err := NewS3Transport(S3TransportConfig{Endpoint: s3Server.URL, Bucket: "wox/devices sync", AccessKeyID: "minio", SecretAccessKey: "minio-secret"})
+	if err != nil {
+		t.Fatalf("create S3 transport: %v", err)
+	}
+
+	return map[string]CloudSyncTransport{CloudSyncBackendFolder: folder, CloudSyncBackendWebDAV: dav, CloudSyncBackendS3: s3}
+}
+
+func TestCloudSyncTransportsStoreObjects(t *testing.T) {
+	ctx := context.Background()
+	for backend, transport := range newTestCloudSyncTransports(t) {
+		t.Run(backend, func(t *testing.T) {
+			if _, err := transport.Get(ctx, "key.json"); !errors.Is(err, ErrCloudSyncObjectNotFound) {
+				t.Fatalf("get missing object error = %v, want not found", err)
+			}
+			objects := map[string]string{
+				"key.json":                "key",
+				"devices/device-a.json":   "device",
+				"changes/device-a/1.json": "first",
+				"changes/device-b/2.json": "second",
+			}
+			for name, content := range objects {
+				if err := transport.Put(ctx, name, []byte(content)); err != nil {
+					t.Fatalf("put %s: %v", name, err)
+				}
+			}
+			if err := transport.Put(ctx, "changes/device-a/1.json", []byte("first updated")); err != nil {
+				t.Fatalf("overwrite object: %v", err)
+			}
+			if data, err := transport.Get(ctx, "changes/device-a/1.json"); err != nil || string(data) != "first updated" {
+				t.Fatalf("get object = %q, %v, want first updated", data, err)
+			}
+
+			names, err := transport.List(ctx, "changes/")
+			if err != nil {
+				t.Fatalf("list changes: %v", err)
+			}
+			sort.Strings(names)
+			if !slices.Equal(names, []string{"changes/device-a/1.json", "changes/device-b/2.json"}) {
+				t.Fatalf("listed changes = %v", names)
+			}
+			if names, err := transport.List(ctx, ""); err != nil || len(names) != len(objects) {
+				t.Fatalf("listed all objects = %v, %v", names, err)
+			}
+
+			if err := transport.Delete(ctx, "changes/device-b/2.json"); err != nil {
+				t.Fatalf("delete object: %v", err)
+			}
+			if err := transport.Delete(ctx, "changes/device-b/2.json"); err != nil {
+				t.Fatalf("delete missing object: %v", err)
+			}
+			if names, err := transport.List(ctx, "changes/device-b/"); err != nil || len(names) != 0 {
+				t.Fatalf("listed deleted object = %v, %v", names, err)
+			}
+			for _, invalid := range []string{"../escape.json", "/key.json", "changes//a.json"} {
+				if err := transport.Put(ctx, invalid, []byte("x")); err == nil {
+					t.Fatalf("put %q succeeded, want invalid name error", invalid)
+				}
+			}
+		})
+	}
+}
+
+func TestCloudSyncStorageClientSyncsEncryptedRecordsBetweenDevices(t *testing.T) {
+	ctx := context.Background()
+	testKDF := CloudSyncKDF{Alg: "argon2id", Version: 19, Iter: 1, MemKiB: 1024, Parallelism: 1, HashLen: 32}
+	for backend, transport := range newTestCloudSyncTransports(t) {
+		t.Run(backend, func(t *testing.T) {
+			clientA := NewCloudSyncStorageClient(transport)
+			clientB := NewCloudSyncStorageClient(transport)
+			keysA := NewKeyManager(KeyManagerConfig{Keyring: &testCloudSyncKeyring{}, KeyClient: clientA, DeviceProvider: testCloudSyncDeviceProvider{deviceID: "device-a"}, KDFDefaults: testKDF})
+			keysB := NewKeyManager(KeyManagerConfig{Keyring: &testCloudSyncKeyring{}, KeyClient: clientB, DeviceProvider: testCloudSyncDeviceProvider{deviceID: "device-b"}, KDFDefaults: testKDF})
+
+			if _, err := keysA.InitWithRecoveryCode(ctx, "ABCD-EFGH-IJKL", "laptop"); err != nil {
+				t.Fatalf("init key: %v", err)
+			}
+			if _, err := keysA.InitWithRecoveryCode(ctx, "ABCD-EFGH-IJKL", "laptop"); err == nil {
+				t.Fatal("second key init succeeded, want key_exists")
+			}
+			if status, err := clientB.Status(ctx); err != nil || !status.Available {
+				t.Fatalf("remote key status = %#v, %v, want available", status, err)
+			}
+			if _, err := keysB.FetchWithRecoveryCode(ctx, "abcd efgh ijkl"); err != nil {
+				t.Fatalf("fetch key: %v", err)
+			}
+
+			cryptoA := NewAesGcmCrypto(keysA)
+			cryptoB := NewAesGcmCrypto(keysB)
+			push := func(key string, op string, plaintext string) {
+				t.Helper()
+				change := CloudSyncChange{ChangeID: key + "-" + op, EntityType: EntityWoxSetting, Key: key, Op: op, ClientTs: time.Now().UnixMilli()}
+				if op == OpUpsert {
+					value, err := cryptoA.Encrypt(ctx, plaintext, buildCloudSyncAAD(EntityWoxSetting, "", key, op))
+					if err != nil {
+						t.Fatalf("encrypt: %v", err)
+					}
+					change.Value = value
+				}
+				resp, err := clientA.Push(ctx, CloudSyncPushRequest{DeviceID: "device-a", Changes: []CloudSyncChange{change}})
+				if err != nil || len(resp.Applied) != 1 || resp.Applied[0].Status != "ok" {
+					t.Fatalf("push %s = %#v, %v", key, resp, err)
+				}
+			}
+			push("ThemeId", OpUpsert, "dark-theme")
+			push("LangCode", OpUpsert, "zh_CN")
+			push("LangCode", OpDelete, "")
+
+			names, _ := transport.List(ctx, "")
+			for _, name := range names {
+				data, _ := transport.Get(ctx, name)
+				if bytes.Contains(data, []byte("dark-theme")) {
+					t.Fatalf("object %s stores plaintext", name)
+				}
+			}
+
+			snapshot, err := clientB.Snapshot(ctx, CloudSyncPullRequest{DeviceID: "device-b", Limit: 10})
+			if err != nil || len(snapshot.Records) != 1 || snapshot.Records[0].Key != "ThemeId" {
+				t.Fatalf("snapshot = %#v, %v, want only the live ThemeId record", snapshot, err)
+			}
+			plaintext, err := cryptoB.Decrypt(ctx, *snapshot.Records[0].Value, buildCloudSyncAAD(EntityWoxSetting, "", "ThemeId", OpUpsert))
+			if err != nil || plaintext != "dark-theme" {
+				t.Fatalf("decrypted snapshot value = %q, %v", plaintext, err)
+			}
+			keys, err := clientB.ListRecordKeys(ctx, CloudSyncRecordKeyListRequest{DeviceID: "device-b"})
+			if err != nil || len(keys.Keys) != 2 || keys.Keys[0].Key != "LangCode" || keys.Keys[0].Op != OpDelete {
+				t.Fatalf("record keys = %#v, %v", keys, err)
+			}
+
+			// Pull pages through batches from other devices and skips the caller's own batches.
+			first, err := clientB.Pull(ctx, CloudSyncPullRequest{DeviceID: "device-b", Limit: 2})
+			if err != nil || len(first.Records) != 2 || !first.HasMore {
+				t.Fatalf("first pull = %#v, %v", first, err)
+			}
+			second, err := clientB.Pull(ctx, CloudSyncPullRequest{DeviceID: "device-b", Cursor: first.NextCursor, Limit: 2})
+			if err != nil || len(second.Records) != 1 || second.HasMore || second.Records[0].Op != OpDelete {
+				t.Fatalf("second pull = %#v, %v", second, err)
+			}
+			if own, err := clientA.Pull(ctx, CloudSyncPullRequest{DeviceID: "device-a"}); err != nil || len(own.Records) != 0 {
+				t.Fatalf("own pull = %#v, %v, want no records", own, err)
+			}
+			push("ThemeId", OpUpsert, "light-theme")
+			third, err := clientB.Pull(ctx, CloudSyncPullRequest{DeviceID: "device-b", Cursor: second.NextCursor, Limit: 2})
+			if err != nil || len(third.Records) != 1 || third.Records[0].Key != "ThemeId" {
+				t.Fatalf("incremental pull = %#v, %v", third, err)
+			}
+			// A fresh client on the same device continues after its last batch.
+			push("ThemeId", OpUpsert, "blue-theme")
+			if _, err := NewCloudSyncStorageClient(transport).Push(ctx, CloudSyncPushRequest{DeviceID: "device-a", Changes: []CloudSyncChange{{ChangeID: "x", EntityType: EntityWoxSetting, Key: "Other", Op: OpDelete}}}); err != nil {
+				t.Fatalf("push from restarted client: %v", err)
+			}
+			fourth, err := clientB.Pull(ctx, CloudSyncPullRequest{DeviceID: "device-b", Cursor: third.NextCursor})
+			if err != nil || len(fourth.Records) != 2 || fourth.Records[1].Key != "Other" {
+				t.Fatalf("pull after restart = %#v, %v", fourth, err)
+			}
+		})
+	}
+}
+
+func TestCloudSyncStorageClientDevicesAndKeyReset(t *testing.T) {
+	ctx := context.Background()
+	transport, err := NewFolderTransport(t.TempDir())
+	if err != nil {
+		t.Fatalf("create folder transport: %v", err)
+	}
+	client := NewCloudSyncStorageClient(transport)
+
+	for _, deviceID := range []string{"device-a", "device-b"} {
+		if _, err := client.UpdateDevice(ctx, CloudSyncDeviceUpdateRequest{DeviceID: deviceID, DeviceName: deviceID, Platform: "linux"}); err != nil {
+			t.Fatalf("update device: %v", err)
+		}
+	}
+	if _, err := client.UpdateDevice(ctx, CloudSyncDeviceUpdateRequest{DeviceID: "../escape"}); err == nil {
+		t.Fatal("update device with invalid id succeeded")
+	}
+	if _, err := client.RevokeDevice(ctx, CloudSyncDeviceRevokeRequest{DeviceID: "device-a", TargetDeviceID: "device-b"}); err != nil {
+		t.Fatalf("revoke device: %v", err)
+	}
+	devices, err := client.ListDevices(ctx, CloudSyncDeviceListRequest{DeviceID: "device-a"})
+	if err != nil || len(devices.Devices) != 2 || devices.DeviceCount != 1 {
+		t.Fatalf("devices = %#v, %v", devices, err)
+	}
+	if _, err := client.Pull(ctx, CloudSyncPullRequest{DeviceID: "device-b"}); !isCloudSyncDeviceRevokedError(err) {
+		t.Fatalf("pull from revoked device error = %v, want device_revoked", err)
+	}
+	if _, err := client.UpdateDevice(ctx, CloudSyncDeviceUpdateRequest{DeviceID: "device-b"}); !isCloudSyncDeviceRevokedError(err) {
+		t.Fatalf("update revoked device error = %v, want device_revoked", err)
+	}
+	if _, err := client.JoinDevice(ctx, CloudSyncDeviceJoinRequest{DeviceID: "device-b", DeviceName: "device-b"}); err != nil {
+		t.Fatalf("join device: %v", err)
+	}
+	if _, err := client.Pull(ctx, CloudSyncPullRequest{DeviceID: "device-b"}); err != nil {
+		t.Fatalf("pull after join: %v", err)
+	}
+
+	if _, err := client.InitKey(ctx, CloudSyncKeyInitRequest{DeviceID: "device-a", EncryptedDEK: "wrapped", KeyVersion: 1}); err != nil {
+		t.Fatalf("init key: %v", err)
+	}
+	if _, err := client.Push(ctx, CloudSyncPushRequest{DeviceID: "device-a", Changes: []CloudSyncChange{{ChangeID: "c1", EntityType: EntityWoxSetting, Key: "ThemeId", Op: OpDelete}}}); err != nil {
+		t.Fatalf("push: %v", err)
+	}
+	if _, err := client.ResetKey(ctx, CloudSyncKeyResetRequest{ResetToken: "guess", Confirm: true}); err == nil {
+		t.Fatal("reset with unknown token succeeded")
+	}
+	prepared, err := client.PrepareKeyReset(ctx)
+	if err != nil {
+		t.Fatalf("prepare reset: %v", err)
+	}
+	if _, err := client.ResetKey(ctx, CloudSyncKeyResetRequest{ResetToken: prepared.ResetToken, Confirm: true}); err != nil {
+		t.Fatalf("reset key: %v", err)
+	}
+	if status, err := client.Status(ctx); err != nil || status.Available {
+		t.Fatalf("key status after reset = %#v, %v", status, err)
+	}
+	if keys, err := client.ListRecordKeys(ctx, CloudSyncRecordKeyListRequest{DeviceID: "device-a"}); err != nil || len(keys.Keys) != 0 {
+		t.Fatalf("record keys after reset = %#v, %v", keys, err)
+	}
+}
+
+// newTestS3Server is a minimal MinIO stand-in: one bucket, ListObjectsV2 with
+// two keys per page and Signature Version 4 checks on every request.
+func TestNewCloudSyncTransportValidatesBackendConfig(t *testing.T) {
+	folder, err := NewCloudSyncTransport(CloudSyncBackendConfig{Type: " folder ", Url: t.TempDir()})
+	if err != nil {
+		t.Fatalf("folder backend: %v", err)
+	}
+	if _, ok := folder.(*FolderTransport); !ok {
+		t.Fatalf("folder backend = %T", folder)
+	}
+	invalid := []CloudSyncBackendConfig{
+		{Type: CloudSyncBackendFolder, Url: "relative/path"},
+		{Type: CloudSyncBackendWebDAV, Url: "ftp://example.com"},
+		{Type: CloudSyncBackendS3, Url: "http://127.0.0.1:9000", Bucket: "wox"},
+		{Type: "dropbox", Url: "https://example.com"},
+	}
+	for _, config := range invalid {
+		if _, err := NewCloudSyncTransport(config); err == nil {
+			t.Fatalf("expected %#v to be rejected", config)
+		}
+	}
+	if (CloudSyncBackendConfig{}).IsSelfHosted() {
+		t.Fatal("the zero backend config should use the Wox sync service")
+	}
+}
+
+func newTestS3Server(t *testing.T, accessKeyID string, secretAccessKey string) http.Handler {
+	var mu sync.Mutex
+	objects := map[string][]byte{}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signedAt, err := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+		if err != nil {
+			http.Error(w, "missing date", http.StatusForbidden)
+			return
+		}
+		expected, _ := http.NewRequest(r.Method, "http://"+r.Host+r.URL.RequestURI(), nil)
+		signS3Request(expected, body, defaultS3Region, accessKeyID, secretAccessKey, signedAt)
+		if r.Header.Get("Authorization") != expected.Header.Get("Authorization") {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte("<Error><Code>SignatureDoesNotMatch</Code><Message>bad signature</Message></Error>"))
+			return
+		}
+
+		bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		if bucket != "wox" {
+			http.Error(w, "no such bucket", http.StatusNotFound)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodGet && key == "" && r.URL.Query().Get("list-type") == "2":
+			prefix := r.URL.Query().Get("prefix")
+			var keys []string
+			for name := range objects {
+				if strings.HasPrefix(name, prefix) && name > r.URL.Query().Get("continuation-token") {
+					keys = append(keys, name)
+				}
+			}
+			sort.Strings(keys)
+			type content struct {
+				Key string `xml:"Key"`
+			}
+			result := struct {
+				XMLName               xml.Name  `xml:"ListBucketResult"`
+				Contents              []content `xml:"Contents"`
+				IsTruncated           bool      `xml:"IsTruncated"`
+				NextContinuationToken string    `xml:"NextContinuationToken,omitempty"`
+			}{}
+			for index, name := range keys {
+				if index == 2 {
+					result.IsTruncated = true
+					result.NextContinuationToken = keys[1]
+					break
+				}
+				result.Contents = append(result.Contents, content{Key: name})
+			}
+			_ = xml.NewEncoder(w).Encode(result)
+		case r.Method == http.MethodGet:
+			data, ok := objects[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte("<Error><Code>NoSuchKey</Code></Error>"))
+				return
+			}
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			_, _ = w.Write(data)
+		case r.Method == http.MethodPut:
+			objects[key] = body
+		case r.Method == http.MethodDelete:
+			delete(objects, key)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "unsupported", http.StatusMethodNotAllowed)
+		}
+	})
+}
@@ -0,0 +1,261 @@
+package cloudsync
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"wox/util"
+)
+
+type WebDAVTransportConfig struct {
+	// URL is the collection that holds the sync objects, e.g. https://dav.example.com/remote.php/dav/files/me/wox.
+	URL        string
+	Username   string
+	Password   string
+	HTTPClient *http.Client
+}
+
+// WebDAVTransport keeps sync objects on a WebDAV server such as Nextcloud or
+// a NAS. Listing uses Depth 1 PROPFIND requests because many servers refuse
+// Depth infinity.
+type WebDAVTransport struct {
+	baseURL    *url.URL
+	username   string
+	password   string
+	httpClient *http.Client
+
+	collectionsMu sync.Mutex
+	collections   map[string]bool
+}
+
+func NewWebDAVTransport(config WebDAVTransportConfig) (*WebDAVTransport, error) {
+	baseURL, err := url.Parse(strings.TrimSpace(config.URL))
+	if err != nil || (baseURL.Scheme != "http" && baseURL.Scheme != "https") || baseURL.Host == "" {
+		return nil, fmt.Errorf("invalid WebDAV url: %s", config.URL)
+	}
+	baseURL.Path = strings.TrimRight(baseURL.Path, "/") + "/"
+	baseURL.RawPath = ""
+	return &WebDAVTransport{
+		baseURL:     baseURL,
+		username:    config.Username,
+		password:    config.Password,
+		httpClient:  config.HTTPClient,
+		collections: map[string]bool{},
+	}, nil
+}
+
+func (t *WebDAVTransport) Get(ctx context.Context, name string) ([]byte, error) {
+	if err := validateCloudSyncObjectName(name); err != nil {
+		return nil, err
+	}
+	resp, err := t.do(ctx, http.MethodGet, t.objectURL(name), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrCloudSyncObjectNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, webDAVStatusError(http.MethodGet, name, resp)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (t *WebDAVTransport) Put(ctx context.Context, name string, data []byte) error {
+	if err := validateCloudSyncObjectName(name); err != nil {
+		return err
+	}
+	if err := t.ensureCollection(ctx, path.Dir(name)); err != nil {
+		return err
+	}
+	resp, err := t.do(ctx, http.MethodPut, t.objectURL(name), data, map[string]string{"Content-Type": "application/octet-stream"})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return webDAVStatusError(http.MethodPut, name, resp)
+	}
+	return nil
+}
+
+func (t *WebDAVTransport) Delete(ctx context.Context, name string) error {
+	if err := validateCloudSyncObjectName(name); err != nil {
+		return err
+	}
+	resp, err := t.do(ctx, http.MethodDelete, t.objectURL(name), nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound || (resp.StatusCode >= 200 && resp.StatusCode < 300) {
+		return nil
+	}
+	return webDAVStatusError(http.MethodDelete, name, resp)
+}
+
+func (t *WebDAVTransport) List(ctx context.Context, prefix string) ([]string, error) {
+	// Start at the deepest collection named by the prefix.
+	collection := ""
+	if index := strings.LastIndex(prefix, "/"); index >= 0 {
+		collection = prefix[:index]
+	}
+	var names []string
+	pending := []string{collection}
+	for len(pending) > 0 {
+		current := pending[0]
+		pending = pending[1:]
+		entries, err := t.propfind(ctx, current)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if isHiddenCloudSyncObject(entry.name) {
+				continue
+			}
+			if entry.collection {
+				if strings.HasPrefix(entry.name+"/", prefix) || strings.HasPrefix(prefix, entry.name+"/") {
+					pending = append(pending, entry.name)
+				}
+				continue
+			}
+			if strings.HasPrefix(entry.name, prefix) {
+				names = append(names, entry.name)
+			}
+		}
+	}
+	return names, nil
+}
+
+type webDAVEntry struct {
+	name       string
+	collection bool
+}
+
+type webDAVMultiStatus struct {
+	Responses []struct {
+		Href         string `xml:"href"`
+		ResourceType struct {
+			Collection *struct{} `xml:"collection"`
+		} `xml:"propstat>prop>resourcetype"`
+	} `xml:"response"`
+}
+
+const webDAVPropfindBody = `<?xml version="1.0" encoding="utf-8"?><d:propfind xmlns:d="DAV:"><d:prop><d:resourcetype/></d:prop></d:propfind>`
+
+// propfind lists the direct children of a collection as object names.
+func (t *WebDAVTransport) propfind(ctx context.Context, collection string) ([]webDAVEntry, error) {
+	target := t.baseURL.String()
+	if collection != "" {
+		target = t.objectURL(collection) + "/"
+	}
+	resp, err := t.do(ctx, "PROPFIND", target, []byte(webDAVPropfindBody), map[string]string{"Depth": "1", "Content-Type": "application/xml"})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, webDAVStatusError("PROPFIND", collection, resp)
+	}
+
+	var multiStatus webDAVMultiStatus
+	if err := xml.NewDecoder(resp.Body).Decode(&multiStatus); err != nil {
+		return nil, fmt.Errorf("failed to decode WebDAV listing: %w", err)
+	}
+	entries := make([]webDAVEntry, 0, len(multiStatus.Responses))
+	for _, response := range multiStatus.Responses {
+		name, ok := t.nameFromHref(response.Href)
+		if !ok || name == collection {
+			continue
+		}
+		entries = append(entries, webDAVEntry{name: name, collection: response.ResourceType.Collection != nil})
+	}
+	return entries, nil
+}
+
+// nameFromHref maps a listed href, which may be absolute or only a path, back to an object name.
+func (t *WebDAVTransport) nameFromHref(href string) (string, bool) {
+	parsed, err := url.Parse(href)
+	if err != nil {
+		return "", false
+	}
+	relative, ok := strings.CutPrefix(parsed.Path, t.baseURL.Path)
+	if !ok {
+		return "", false
+	}
+	return strings.Trim(relative, "/"), true
+}
+
+// ensureCollection creates the parent collections of an object once per session.
+func (t *WebDAVTransport) ensureCollection(ctx context.Context, collection string) error {
+	if collection == "." || collection == "" {
+		return nil
+	}
+	t.collectionsMu.Lock()
+	defer t.collectionsMu.Unlock()
+	current := ""
+	for _, segment := range strings.Split(collection, "/") {
+		current = path.Join(current, segment)
+		if t.collections[current] {
+			continue
+		}
+		resp, err := t.do(ctx, "MKCOL", t.objectURL(current)+"/", nil, nil)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		// 405 means the collection already exists.
+		if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusMethodNotAllowed && resp.StatusCode != http.StatusOK {
+			return webDAVStatusError("MKCOL", current, resp)
+		}
+		t.collections[current] = true
+	}
+	return nil
+}
+
+func (t *WebDAVTransport) objectURL(name string) string {
+	target := *t.baseURL
+	target.Path = t.baseURL.Path + name
+	return target.String()
+}
+
+func (t *WebDAVTransport) do(ctx context.Context, method string, target string, body []byte, headers map[string]string) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	if t.username != "" || t.password != "" {
+		req.SetBasicAuth(t.username, t.password)
+	}
+	client := t.httpClient
+	if client == nil {
+		client = util.GetHTTPClient(ctx)
+	}
+	return client.Do(req)
+}
+
+func webDAVStatusError(method string, name string, resp *http.Response) error {
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("WebDAV %s %s was denied (%d), check the username and password", method, name, resp.StatusCode)
+	}
+	return fmt.Errorf("WebDAV %s %s failed (%d): %s", method, name, resp.StatusCode, strings.TrimSpace(string(detail)))
+}
@@ -237,6 +237,12 @@ type CloudSyncApplier interface {
 	ApplyInstalledTheme(ctx context.Context, themeID string, op string, rawValue string) error
 }
 
+// CloudSyncEntityApplier applies the data entity types listed by
+// CloudSyncDataEntityTypes. Appliers implement it optionally.
+type CloudSyncEntityApplier interface {
+	ApplyEntity(ctx context.Context, entityType string, pluginID string, key string, op string, rawValue string) error
+}
+
 // CloudSyncSettingReloader lets the sync manager refresh UI-side cached settings
 // after remote records have been applied locally.
 type CloudSyncSettingReloader interface {
@@ -325,6 +331,12 @@ type CloudSyncPendingCounter interface {
 	CountPending(ctx context.Context) (int, error)
 }
 
+// CloudSyncPendingTimestampLookup lets last-writer-wins entities keep a newer
+// local write that has not been pushed yet.
+type CloudSyncPendingTimestampLookup interface {
+	LatestPendingTimestamp(ctx context.Context, entityType string, entityID string, key string) (int64, error)
+}
+
 // CloudSyncOplogPushFailure stores the next local retry state for one server-rejected oplog.
 type CloudSyncOplogPushFailure struct {
 	ID          uint
@@ -352,3 +364,9 @@ type CloudSyncHistoryStore interface {
 type CloudSyncPluginExclusionProvider interface {
 	DisabledPluginIDs(ctx context.Context) []string
 }
+
+// CloudSyncEntityExclusionProvider reports the data entity types the user
+// opted out of. Exclusion providers implement it optionally.
+type CloudSyncEntityExclusionProvider interface {
+	DisabledEntityTypes(ctx context.Context) []string
+}
@@ -3,27 +3,35 @@ package cloudsync
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"wox/database"
 	"wox/util"
 )
 
 type Service struct {
-	Manager        *CloudSyncManager
+	Manager *CloudSyncManager
+	// Client is nil when a self-hosted backend is configured.
 	Client         *CloudSyncHTTPClient
 	DeviceClient   CloudSyncDeviceClient
 	KeyManager     *KeyManager
 	DeviceProvider CloudSyncDeviceProvider
 	HistoryStore   CloudSyncHistoryStore
+	// Backend is the backend this service was built for; the zero value is the Wox sync service.
+	Backend CloudSyncBackendConfig
 }
 
+// ServiceStatus is the sync state shown in settings. RestartRequired is set
+// when the configured backend differs from the one the service runs on.
 type ServiceStatus struct {
-	Enabled      bool                `json:"enabled"`
-	DeviceID     string              `json:"device_id,omitempty"`
-	KeyStatus    CloudSyncKeyStatus  `json:"key_status"`
-	State        *CloudSyncStateView `json:"state,omitempty"`
-	Progress     *CloudSyncProgress  `json:"progress,omitempty"`
-	PendingCount int                 `json:"pending_count"`
+	Enabled         bool                `json:"enabled"`
+	Backend         string              `json:"backend,omitempty"`
+	RestartRequired bool                `json:"restart_required,omitempty"`
+	DeviceID        string              `json:"device_id,omitempty"`
+	KeyStatus       CloudSyncKeyStatus  `json:"key_status"`
+	State           *CloudSyncStateView `json:"state,omitempty"`
+	Progress        *CloudSyncProgress  `json:"progress,omitempty"`
+	PendingCount    int                 `json:"pending_count"`
 }
 
 type CloudSyncStateView struct {
@@ -53,6 +61,12 @@ func GetService() *Service {
 	return service
 }
 
+// SelfHosted reports whether sync runs against a WebDAV, S3 or folder backend,
+// which is not tied to a Wox account.
+func (s *Service) SelfHosted() bool {
+	return s != nil && s.Backend.IsSelfHosted()
+}
+
 func (s *Service) StartManager(ctx context.Context) {
 	if s == nil || s.Manager == nil {
 		return
@@ -146,6 +160,7 @@ func (s *Service) Status(ctx context.Context) ServiceStatus {
 	if s == nil {
 		return status
 	}
+	status.Backend = strings.TrimSpace(s.Backend.Type)
 
 	if s.DeviceProvider != nil {
 		if deviceID, err := s.DeviceProvider.DeviceID(ctx); err == nil {
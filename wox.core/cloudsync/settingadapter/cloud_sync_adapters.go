@@ -18,3 +18,12 @@ func (p *CloudSyncPluginExclusionProvider) DisabledPluginIDs(ctx context.Context
 	}
 	return woxSetting.CloudSyncDisabledPlugins.Get()
 }
+
+// DisabledEntityTypes returns the data entity types the user turned off in the Cloud Sync settings page.
+func (p *CloudSyncPluginExclusionProvider) DisabledEntityTypes(ctx context.Context) []string {
+	woxSetting := setting.GetSettingManager().GetWoxSetting(ctx)
+	if woxSetting == nil {
+		return nil
+	}
+	return woxSetting.CloudSyncDisabledEntities.Get()
+}
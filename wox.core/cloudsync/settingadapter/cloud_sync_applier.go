@@ -27,6 +27,15 @@ func (a *LocalSettingApplier) ApplyWoxSetting(ctx context.Context, key string, o
 		return fmt.Errorf("wox setting not initialized")
 	}
 
+	if key == cloudsync.QueryHistorySyncKey {
+		// Older clients still push query history as a plain Wox setting; merge it
+		// like the query_history entity instead of dropping local entries.
+		if op != cloudsync.OpUpsert {
+			return nil
+		}
+		return setting.GetSettingManager().MergeSyncedQueryHistories(ctx, rawValue)
+	}
+
 	store := setting.NewWoxSettingStore(database.GetDB())
 	previousValue, hadPrevious := loadStoredString(store, key)
 
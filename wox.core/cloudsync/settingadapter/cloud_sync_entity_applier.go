@@ -0,0 +1,174 @@
+package settingadapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"wox/cloudsync"
+	"wox/common"
+	"wox/database"
+	"wox/plugin"
+	"wox/setting"
+)
+
+const (
+	clipboardPluginID = "5f815d98-27f5-488d-a756-c317ea39935b"
+	// clipboardFavoritesSettingKey is the clipboard plugin setting that holds the favorite list.
+	clipboardFavoritesSettingKey = "favorites"
+)
+
+// aiChatReloader is implemented by the AI chat plugin so synced chats show up without a restart.
+type aiChatReloader interface {
+	ReloadChats(ctx context.Context) error
+}
+
+// ApplyEntity replays one remote data entity record without writing new local oplogs.
+func (a *LocalSettingApplier) ApplyEntity(ctx context.Context, entityType string, pluginID string, key string, op string, rawValue string) error {
+	if op != cloudsync.OpUpsert && op != cloudsync.OpDelete {
+		return fmt.Errorf("unknown oplog op: %s", op)
+	}
+
+	switch entityType {
+	case cloudsync.EntityMRU:
+		return setting.GetSettingManager().ApplySyncedMRURecord(ctx, key, op, rawValue)
+	case cloudsync.EntityQueryHistory:
+		if op == cloudsync.OpDelete {
+			return nil
+		}
+		return setting.GetSettingManager().MergeSyncedQueryHistories(ctx, rawValue)
+	case cloudsync.EntityClipboardFavorite:
+		return applyClipboardFavorite(ctx, pluginID, key, op, rawValue)
+	case cloudsync.EntityAIChat:
+		return applyAIChat(ctx, key, op, rawValue)
+	case cloudsync.EntityAttentionItem:
+		if err := plugin.GetAttentionManager().ApplySyncedItem(ctx, key, op, rawValue); err != nil {
+			return err
+		}
+		plugin.PublishAttentionUnreadCount(ctx)
+		return nil
+	default:
+		return fmt.Errorf("unknown cloud sync entity type: %s", entityType)
+	}
+}
+
+// applyClipboardFavorite replaces or removes one favorite in the local-only
+// favorite list. Favorites follow last-writer-wins, so the remote item is
+// taken as is.
+func applyClipboardFavorite(ctx context.Context, pluginID string, favoriteID string, op string, rawValue string) error {
+	store := setting.NewPluginSettingStore(database.GetDB(), pluginID)
+	previousValue, _ := loadStoredStringPlugin(store, clipboardFavoritesSettingKey)
+
+	var favorites []json.RawMessage
+	if previousValue != "" {
+		if err := json.Unmarshal([]byte(previousValue), &favorites); err != nil {
+			return fmt.Errorf("failed to parse local favorites: %w", err)
+		}
+	}
+
+	index := -1
+	for i, favorite := range favorites {
+		var identity struct {
+			ID string `json:"id"`
+		}
+		if json.Unmarshal(favorite, &identity) == nil && identity.ID == favoriteID {
+			index = i
+			break
+		}
+	}
+
+	switch op {
+	case cloudsync.OpDelete:
+		if index < 0 {
+			return nil
+		}
+		favorites = append(favorites[:index], favorites[index+1:]...)
+	case cloudsync.OpUpsert:
+		if !json.Valid([]byte(rawValue)) {
+			return fmt.Errorf("invalid synced favorite %s", favoriteID)
+		}
+		if index >= 0 {
+			favorites[index] = json.RawMessage(rawValue)
+		} else {
+			favorites = append(favorites, json.RawMessage(rawValue))
+		}
+	}
+
+	encoded, err := json.Marshal(favorites)
+	if err != nil {
+		return err
+	}
+	if string(encoded) == previousValue {
+		return nil
+	}
+	if err := store.SetWithSync(clipboardFavoritesSettingKey, string(encoded), false); err != nil {
+		return err
+	}
+	notifyPluginSettingChanged(ctx, pluginID, clipboardFavoritesSettingKey, string(encoded))
+	return nil
+}
+
+// applyAIChat merges a remote chat into the local copy by conversation id.
+// Title and model come from whichever side was updated last.
+func applyAIChat(ctx context.Context, chatID string, op string, rawValue string) error {
+	chatStore := database.NewAIChatStore(database.GetDB())
+	switch op {
+	case cloudsync.OpDelete:
+		if err := chatStore.DeleteChat(ctx, chatID); err != nil {
+			return err
+		}
+	case cloudsync.OpUpsert:
+		var remote common.AIChatData
+		if err := json.Unmarshal([]byte(rawValue), &remote); err != nil {
+			return fmt.Errorf("failed to parse synced chat: %w", err)
+		}
+		if remote.Id != chatID {
+			return fmt.Errorf("synced chat id mismatch: %s", chatID)
+		}
+
+		local, exists, err := chatStore.GetChat(ctx, chatID)
+		if err != nil {
+			return err
+		}
+		merged := remote
+		if exists {
+			merged = mergeSyncedAIChat(local, remote)
+		}
+		if err := chatStore.SaveChat(ctx, merged); err != nil {
+			return err
+		}
+	}
+
+	if reloader, ok := plugin.GetPluginManager().GetAIChatPluginChater(ctx).(aiChatReloader); ok {
+		return reloader.ReloadChats(ctx)
+	}
+	return nil
+}
+
+func mergeSyncedAIChat(local common.AIChatData, remote common.AIChatData) common.AIChatData {
+	merged := local
+	if remote.UpdatedAt >= local.UpdatedAt {
+		merged = remote
+	}
+	merged.DebugTrace = nil
+	merged.IsStreaming = false
+	merged.IsSummary = false
+
+	merged.Conversations = cloudsync.MergeCloudSyncItemsByID(local.Conversations, remote.Conversations, func(item common.Conversation) string {
+		return item.Id
+	}, func(item common.Conversation) int64 {
+		return item.Timestamp
+	})
+	sort.SliceStable(merged.Conversations, func(i, j int) bool {
+		return merged.Conversations[i].Timestamp < merged.Conversations[j].Timestamp
+	})
+	merged.CompactionEntries = cloudsync.MergeCloudSyncItemsByID(local.CompactionEntries, remote.CompactionEntries, func(item common.AIChatCompactionEntry) string {
+		return item.Id
+	}, func(item common.AIChatCompactionEntry) int64 {
+		return 0
+	})
+	if local.CreatedAt > 0 && (remote.CreatedAt == 0 || local.CreatedAt < remote.CreatedAt) {
+		merged.CreatedAt = local.CreatedAt
+	}
+	return merged
+}
@@ -11,6 +11,8 @@ import (
 	"wox/setting"
 	"wox/ui"
 	"wox/util"
+
+	"gorm.io/gorm"
 )
 
 type LocalSnapshotter struct{}
@@ -112,6 +114,9 @@ func (s *LocalSnapshotter) collectLocalSnapshotOplogs(ctx context.Context) ([]da
 	if err := appendInstalledThemeOplogs(ctx, timestamp, &oplogs); err != nil {
 		return nil, err
 	}
+	if err := appendDataEntityOplogs(ctx, db, disabledPlugins, timestamp, &oplogs); err != nil {
+		return nil, err
+	}
 
 	return oplogs, nil
 }
@@ -207,6 +212,104 @@ func appendInstalledThemeOplogs(ctx context.Context, timestamp int64, oplogs *[]
 	return nil
 }
 
+// appendDataEntityOplogs snapshots MRU items, query history, clipboard
+// favorites, AI chats and attention items, skipping the entity types the user
+// turned off.
+func appendDataEntityOplogs(ctx context.Context, db *gorm.DB, disabledPlugins map[string]struct{}, timestamp int64, oplogs *[]database.Oplog) error {
+	disabledEntities := currentCloudSyncDisabledEntities(ctx)
+	appendEntity := func(entityType string, entityID string, key string, value any) error {
+		rawValue, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s snapshot for %s: %w", entityType, key, err)
+		}
+		*oplogs = append(*oplogs, database.Oplog{
+			EntityType: entityType,
+			EntityID:   entityID,
+			Operation:  cloudsync.OpUpsert,
+			Key:        key,
+			Value:      string(rawValue),
+			Timestamp:  timestamp,
+		})
+		return nil
+	}
+
+	if _, blocked := disabledEntities[cloudsync.EntityMRU]; !blocked {
+		var records []database.MRURecord
+		if err := db.Find(&records).Error; err != nil {
+			return err
+		}
+		for _, record := range records {
+			if err := appendEntity(cloudsync.EntityMRU, record.Hash, record.Hash, record); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, blocked := disabledEntities[cloudsync.EntityQueryHistory]; !blocked {
+		if settingManager := setting.GetSettingManager(); settingManager != nil {
+			histories := settingManager.GetWoxSetting(ctx).QueryHistories.Get()
+			if len(histories) > 0 {
+				if err := appendEntity(cloudsync.EntityQueryHistory, cloudsync.QueryHistorySyncKey, cloudsync.QueryHistorySyncKey, histories); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	_, favoritesBlocked := disabledEntities[cloudsync.EntityClipboardFavorite]
+	_, clipboardBlocked := disabledPlugins[clipboardPluginID]
+	if !favoritesBlocked && !clipboardBlocked {
+		store := setting.NewPluginSettingStore(db, clipboardPluginID)
+		if favoritesJSON, ok := loadStoredStringPlugin(store, clipboardFavoritesSettingKey); ok && favoritesJSON != "" {
+			var favorites []json.RawMessage
+			if err := json.Unmarshal([]byte(favoritesJSON), &favorites); err != nil {
+				util.GetLogger().Warn(ctx, fmt.Sprintf("skip clipboard favorites snapshot: %s", err.Error()))
+			}
+			for _, favorite := range favorites {
+				var identity struct {
+					ID string `json:"id"`
+				}
+				if json.Unmarshal(favorite, &identity) != nil || identity.ID == "" {
+					continue
+				}
+				if err := appendEntity(cloudsync.EntityClipboardFavorite, clipboardPluginID, identity.ID, favorite); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if _, blocked := disabledEntities[cloudsync.EntityAIChat]; !blocked {
+		chats, err := database.NewAIChatStore(db).ListChats(ctx)
+		if err != nil {
+			return err
+		}
+		for _, chat := range chats {
+			chat.DebugTrace = nil
+			if err := appendEntity(cloudsync.EntityAIChat, chat.Id, chat.Id, chat); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, blocked := disabledEntities[cloudsync.EntityAttentionItem]; !blocked {
+		var items []database.AttentionItem
+		if err := db.Find(&items).Error; err != nil {
+			return err
+		}
+		for _, item := range items {
+			if _, pluginBlocked := disabledPlugins[item.PluginID]; pluginBlocked {
+				continue
+			}
+			if err := appendEntity(cloudsync.EntityAttentionItem, item.PluginID, item.IdentityKey, item); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 // resolveStorePluginManifest finds the downloadable manifest required to replay an installed plugin.
 func resolveStorePluginManifest(ctx context.Context, pluginID string) (plugin.StorePluginManifest, bool) {
 	store := plugin.GetStoreManager()
@@ -236,11 +339,7 @@ func cloudSyncRecordKeyIdentity(key cloudsync.CloudSyncRecordKey) string {
 }
 
 func cloudSyncOplogIdentity(oplog database.Oplog) string {
-	pluginID := ""
-	if oplog.EntityType == cloudsync.EntityPluginSetting {
-		pluginID = oplog.EntityID
-	}
-	return cloudSyncIdentity(oplog.EntityType, pluginID, oplog.Key)
+	return cloudSyncIdentity(oplog.EntityType, cloudsync.OplogPluginID(oplog), oplog.Key)
 }
 
 func cloudSyncIdentity(entityType string, pluginID string, key string) string {
@@ -308,3 +407,22 @@ func currentCloudSyncDisabledPlugins(ctx context.Context) map[string]struct{} {
 
 	return disabled
 }
+
+// currentCloudSyncDisabledEntities returns the data entity types intentionally excluded from cloud sync.
+func currentCloudSyncDisabledEntities(ctx context.Context) map[string]struct{} {
+	settingManager := setting.GetSettingManager()
+	if settingManager == nil {
+		return nil
+	}
+
+	woxSetting := settingManager.GetWoxSetting(ctx)
+	if woxSetting == nil {
+		return nil
+	}
+
+	disabled := map[string]struct{}{}
+	for _, entityType := range woxSetting.CloudSyncDisabledEntities.Get() {
+		disabled[entityType] = struct{}{}
+	}
+	return disabled
+}
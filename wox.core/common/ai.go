@@ -20,6 +20,9 @@ type AIChatMCPServerType string
 const (
 	AIChatMCPServerTypeSTDIO          AIChatMCPServerType = "stdio"
 	AIChatMCPServerTypeStreamableHTTP AIChatMCPServerType = "streamable-http"
+	// AIChatMCPServerTypeSSE is the HTTP+SSE transport of the 2024-11-05 spec,
+	// still used by older servers.
+	AIChatMCPServerTypeSSE AIChatMCPServerType = "sse"
 )
 
 const (
@@ -59,7 +62,13 @@ type ChatStreamData struct {
 	Data string
 	// Reasoning content from models that support reasoning (e.g., DeepSeek, OpenAI o1). Separate from Data for clean processing.
 	Reasoning string
-	ToolCalls []ToolCallInfo
+	// ReasoningSignature is the opaque signature Anthropic attaches to thinking blocks. It must be replayed with the
+	// reasoning while a tool call loop continues, otherwise the API rejects the next request.
+	ReasoningSignature string
+	// RedactedReasoning holds the encrypted data of Anthropic redacted_thinking blocks. Like the signature it has to be
+	// replayed unchanged while a tool call loop continues.
+	RedactedReasoning []string
+	ToolCalls         []ToolCallInfo
 }
 
 func (c *ChatStreamData) IsNotFinished() bool {
@@ -124,14 +133,16 @@ type AISkillRef struct {
 }
 
 type Conversation struct {
-	Id           string
-	Role         ConversationRole
-	Text         string
-	Reasoning    string // Reasoning content from models that support reasoning (e.g., DeepSeek, OpenAI o1, qwen3)
-	Images       []WoxImage
-	SkillRefs    []AISkillRef
-	ToolCallInfo ToolCallInfo
-	Timestamp    int64
+	Id                 string
+	Role               ConversationRole
+	Text               string
+	Reasoning          string   // Reasoning content from models that support reasoning (e.g., DeepSeek, OpenAI o1, qwen3)
+	ReasoningSignature string   `json:",omitempty"` // provider signature of Reasoning, only needed within one tool call loop
+	RedactedReasoning  []string `json:",omitempty"` // encrypted reasoning blocks, replayed like ReasoningSignature
+	Images             []WoxImage
+	SkillRefs          []AISkillRef
+	ToolCallInfo       ToolCallInfo
+	Timestamp          int64
 }
 
 type AIProviderInfo struct {
@@ -197,6 +208,7 @@ const (
 	AIChatDebugEventModelCallError    AIChatDebugEventType = "model_call_error"
 	AIChatDebugEventToolCallStarted   AIChatDebugEventType = "tool_call_started"
 	AIChatDebugEventToolCallFinished  AIChatDebugEventType = "tool_call_finished"
+	AIChatDebugEventToolCallApproval  AIChatDebugEventType = "tool_call_approval"
 )
 
 // AIChatDebugTrace is a development-only timeline of the runtime chat loop.
@@ -227,6 +239,7 @@ type AIChatDebugEvent struct {
 	Response     []Conversation
 	VisibleTools []AIChatDebugTool
 	ToolCallInfo *ToolCallInfo
+	Approval     *AIToolApprovalDecision
 }
 
 // AIChatDebugTool is the serializable subset of a runtime tool definition.
@@ -315,6 +328,10 @@ func cloneAIChatDebugEvent(event AIChatDebugEvent) AIChatDebugEvent {
 		toolCallInfo := cloneDebugToolCallInfo(*event.ToolCallInfo)
 		cloned.ToolCallInfo = &toolCallInfo
 	}
+	if event.Approval != nil {
+		approval := *event.Approval
+		cloned.Approval = &approval
+	}
 	return cloned
 }
 
@@ -357,6 +374,7 @@ type AIChater interface {
 	SummarizeChat(ctx context.Context, chatId string) bool
 	GetAllTools(ctx context.Context) []MCPTool
 	GetAllSkills(ctx context.Context) []Skill
+	GetMCPCatalog(ctx context.Context) MCPCatalog
 	ReloadMCPServers(ctx context.Context, notifyUI bool)
 	ReloadSkills(ctx context.Context) error
 	GetDefaultModel(ctx context.Context) Model
@@ -488,6 +506,98 @@ type AIChatMCPServerConfig struct {
 	Command              string
 	EnvironmentVariables []string //key=value
 
-	// for streamable http server
-	Url string
+	// for streamable http and sse server
+	Url         string
+	Headers     []string // "Key: Value"
+	BearerToken string
+
+	// ApprovalPolicy applies to every tool of this server unless a tool rule
+	// overrides it. Empty means ask.
+	ApprovalPolicy AIToolApprovalPolicy
+}
+
+// MCPResource is a resource advertised by an MCP server that can be attached to a chat.
+type MCPResource struct {
+	ServerName  string
+	URI         string
+	Name        string
+	Description string
+	MimeType    string
+}
+
+// MCPPrompt is a prompt template advertised by an MCP server, offered as a chat command.
+type MCPPrompt struct {
+	ServerName  string
+	Name        string
+	Description string
+	Arguments   []MCPPromptArgument
+}
+
+type MCPPromptArgument struct {
+	Name        string
+	Description string
+	Required    bool
+}
+
+type MCPServerState string
+
+const (
+	MCPServerStateIdle      MCPServerState = "idle"
+	MCPServerStateConnected MCPServerState = "connected"
+	MCPServerStateError     MCPServerState = "error"
+	MCPServerStateDisabled  MCPServerState = "disabled"
+)
+
+// MCPServerHealth is the last known connection state of one MCP server.
+type MCPServerHealth struct {
+	Name          string
+	State         MCPServerState
+	Error         string
+	ToolCount     int
+	ResourceCount int
+	PromptCount   int
+	CheckedAt     int64
+}
+
+// MCPCatalog is what the configured MCP servers offer besides tools.
+type MCPCatalog struct {
+	Prompts   []MCPPrompt
+	Resources []MCPResource
+	Servers   []MCPServerHealth
+}
+
+type AIToolApprovalPolicy string
+
+const (
+	AIToolApprovalPolicyAllow AIToolApprovalPolicy = "allow"
+	AIToolApprovalPolicyAsk   AIToolApprovalPolicy = "ask"
+	AIToolApprovalPolicyDeny  AIToolApprovalPolicy = "deny"
+)
+
+// AIToolApprovalRule overrides the approval policy of one tool by name.
+// CommandPrefixes (bash) and PathRoots (write_file, edit) let matching calls
+// run without asking even when the policy is ask.
+type AIToolApprovalRule struct {
+	Tool            string
+	Policy          AIToolApprovalPolicy
+	CommandPrefixes []string
+	PathRoots       []string
+}
+
+type AIToolApprovalSource string
+
+const (
+	AIToolApprovalSourceDefault   AIToolApprovalSource = "default"
+	AIToolApprovalSourceMCPServer AIToolApprovalSource = "mcp_server"
+	AIToolApprovalSourceRule      AIToolApprovalSource = "rule"
+	AIToolApprovalSourceAllowlist AIToolApprovalSource = "allowlist"
+	AIToolApprovalSourceUser      AIToolApprovalSource = "user"
+)
+
+// AIToolApprovalDecision records why a tool call was allowed, denied, or needs
+// the user to decide.
+type AIToolApprovalDecision struct {
+	Policy AIToolApprovalPolicy
+	Source AIToolApprovalSource
+	Reason string
 }
@@ -196,6 +196,10 @@ var uiIcons = map[string]WoxImage{
 	"screenshot.arrow":             newMonochromeUIIcon(`<path d="M5 19 19 5M11 5h8v8"/>`),
 	"screenshot.number":            newMonochromeUIIcon(`<circle cx="12" cy="12" r="8.5"/><path d="m10 9 2-2v10M9.5 17h5"/>`),
 	"screenshot.mosaic":            newMonochromeUIIcon(`<rect x="3.5" y="3.5" width="17" height="17" rx=".75"/><path d="M4 4h4v4H4zM12 4h4v4h-4zM8 8h4v4H8zM16 8h4v4h-4zM4 12h4v4H4zM12 12h4v4h-4zM8 16h4v4H8zM16 16h4v4h-4z" fill="#fff" stroke="none"/>`),
+	"screenshot.pen":               newMonochromeUIIcon(`<path d="M4 20c2-1 3-3 5-3s2 2 4 2 3-2 3-2M15 4l4 4-8 8-5 1 1-5z"/>`),
+	"screenshot.highlighter":       newMonochromeUIIcon(`<path d="m9 15 9-9-3-3-9 9v3zM6 15l-3 3h5l1-3"/><path d="M12 21h8" stroke-width="3"/>`),
+	"screenshot.blur":              newMonochromeUIIcon(`<circle cx="12" cy="12" r="8.5"/><path d="M9.5 12a2.5 2.5 0 1 0 5 0 2.5 2.5 0 1 0-5 0M6.8 9a1.2 1.2 0 1 0 2.4 0 1.2 1.2 0 1 0-2.4 0M14.8 9a1.2 1.2 0 1 0 2.4 0 1.2 1.2 0 1 0-2.4 0M6.8 15a1.2 1.2 0 1 0 2.4 0 1.2 1.2 0 1 0-2.4 0M14.8 15a1.2 1.2 0 1 0 2.4 0 1.2 1.2 0 1 0-2.4 0" fill="#fff" stroke="none"/>`),
+	"screenshot.crop":              newMonochromeUIIcon(`<path d="M6 2v14a2 2 0 0 0 2 2h14M2 6h14a2 2 0 0 1 2 2v14"/>`),
 	"screenshot.scrolling-capture": newMonochromeUIIcon(`<path d="m8 7 4-4 4 4M12 3v18M8 17l4 4 4-4"/>`),
 	"screenshot.cursor":            NewWoxImageSvg(`<svg xmlns="http://www.w3.org/2000/svg" width="24" height="24" viewBox="0 0 24 24"><path d="M4.5 2.5v16.2l4.1-4 3.4 7.1 3.8-1.8-3.3-6.8h6.1L4.5 2.5Z" fill="#fff" stroke="#171717" stroke-width="1.6" stroke-linejoin="round"/></svg>`),
 	"screenshot.pin":               newMonochromeUIIcon(`<path d="m9 3 6 0-1 6 4 4H6l4-4zM12 13v6"/>`),
@@ -254,12 +254,23 @@ type CaptureScreenshotRequest struct {
 	// AllowVideoRecording exposes the recording workflow only to trusted Wox-owned callers. The
 	// zero value deliberately preserves the image-only contract used by third-party plugins.
 	AllowVideoRecording bool `json:"allowVideoRecording"`
+	// SaveProject keeps the unannotated pixels and the annotation list in a sidecar next to the
+	// exported PNG so the screenshot can be reopened with every mark still editable. Only Wox-owned
+	// callers ask for it because the sidecar also stores what annotations were meant to hide.
+	SaveProject bool `json:"saveProject"`
+	// EditProjectPath reopens a sidecar written by SaveProject instead of starting a blank capture.
+	EditProjectPath string `json:"editProjectPath,omitempty"`
 	// CallerIcon is set only by plugin-originated screenshot API calls. The previous request did not
 	// carry caller identity, so UI could not visually distinguish a third-party capture from the
 	// built-in Wox screenshot flow; passing the already-resolved icon keeps that decision in Go.
 	CallerIcon *WoxImage `json:"callerIcon,omitempty"`
 }
 
+// ScreenshotProjectPath returns the editable project sidecar of an exported screenshot PNG.
+func ScreenshotProjectPath(screenshotPath string) string {
+	return screenshotPath + ".project.json"
+}
+
 // CaptureArtifactKind distinguishes the image compatibility path from saved video artifacts.
 type CaptureArtifactKind string
 
@@ -0,0 +1,672 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+	"wox/common"
+	"wox/util"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AIChat stores chat metadata. Messages and tool calls live in their own
+// tables so a streaming reply only rewrites the rows it touched instead of
+// every chat the user ever had.
+type AIChat struct {
+	ID                    string `gorm:"primaryKey"`
+	Title                 string
+	ModelJSON             string `gorm:"type:text"`
+	CompactionEntriesJSON string `gorm:"type:text"`
+	CreatedTimestamp      int64  `gorm:"not null"`
+	UpdatedTimestamp      int64  `gorm:"index;not null"`
+}
+
+// AIChatMessage is one conversation entry. Position keeps the chat order,
+// which is not always timestamp order because tool results update in place.
+type AIChatMessage struct {
+	ChatID                string `gorm:"primaryKey"`
+	ID                    string `gorm:"primaryKey"`
+	Position              int    `gorm:"not null"`
+	Role                  string `gorm:"not null"`
+	Text                  string `gorm:"type:text"`
+	Reasoning             string `gorm:"type:text"`
+	ReasoningSignature    string `gorm:"type:text"`
+	RedactedReasoningJSON string `gorm:"type:text"`
+	ImagesJSON            string `gorm:"type:text"`
+	SkillRefsJSON         string `gorm:"type:text"`
+	Timestamp             int64  `gorm:"not null"`
+}
+
+// AIChatToolCall holds the tool call carried by a tool-role message.
+type AIChatToolCall struct {
+	ChatID         string `gorm:"primaryKey"`
+	MessageID      string `gorm:"primaryKey"`
+	ToolCallID     string
+	Name           string `gorm:"index"`
+	ArgumentsJSON  string `gorm:"type:text"`
+	Status         string
+	Delta          string `gorm:"type:text"`
+	Response       string `gorm:"type:text"`
+	StartTimestamp int64
+	EndTimestamp   int64
+}
+
+// AIChatMessageSearchHit is one message matched by SearchMessages.
+type AIChatMessageSearchHit struct {
+	ChatID    string
+	ChatTitle string
+	MessageID string
+	Role      string
+	Snippet   string
+	Timestamp int64
+}
+
+// AIChatMessageText is the searchable text of one user or assistant message.
+type AIChatMessageText struct {
+	ChatID    string
+	MessageID string
+	Text      string
+}
+
+const (
+	// aiChatSearchRecencyMillis is the age at which a message's BM25 score is
+	// halved, so a fresh chat wins over an equally good match from last year.
+	aiChatSearchRecencyMillis = int64(90 * 24 * 60 * 60 * 1000)
+	aiChatSnippetRunes        = 80
+)
+
+// aiChatFTSTriggers keep ai_chat_messages_fts in sync with message text. Only
+// user and assistant text is searchable; tool output is noise for recall.
+var aiChatFTSTriggers = []struct {
+	name string
+	sql  string
+}{
+	{
+		name: "ai_chat_messages_fts_ai",
+		sql: `CREATE TRIGGER IF NOT EXISTS ai_chat_messages_fts_ai AFTER INSERT ON ai_chat_messages BEGIN
+			INSERT INTO ai_chat_messages_fts(rowid, text) VALUES (new.rowid, new.text);
+		END`,
+	},
+	{
+		name: "ai_chat_messages_fts_ad",
+		sql: `CREATE TRIGGER IF NOT EXISTS ai_chat_messages_fts_ad AFTER DELETE ON ai_chat_messages BEGIN
+			INSERT INTO ai_chat_messages_fts(ai_chat_messages_fts, rowid, text) VALUES ('delete', old.rowid, old.text);
+		END`,
+	},
+	{
+		name: "ai_chat_messages_fts_au",
+		sql: `CREATE TRIGGER IF NOT EXISTS ai_chat_messages_fts_au AFTER UPDATE OF text ON ai_chat_messages BEGIN
+			INSERT INTO ai_chat_messages_fts(ai_chat_messages_fts, rowid, text) VALUES ('delete', old.rowid, old.text);
+			INSERT INTO ai_chat_messages_fts(rowid, text) VALUES (new.rowid, new.text);
+		END`,
+	},
+}
+
+// InitAIChatFullTextSearch creates the FTS5 index over message text. Builds
+// without FTS5 drop the triggers so message writes keep working, and search
+// falls back to LIKE.
+func InitAIChatFullTextSearch(ctx context.Context, db *gorm.DB) error {
+	if err := db.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS temp.ai_chat_fts5_probe USING fts5(value)`).Error; err != nil {
+		for _, trigger := range aiChatFTSTriggers {
+			if dropErr := db.Exec(`DROP TRIGGER IF EXISTS ` + trigger.name).Error; dropErr != nil {
+				return fmt.Errorf("failed to drop AI chat FTS trigger %s: %w", trigger.name, dropErr)
+			}
+		}
+		util.GetLogger().Warn(ctx, fmt.Sprintf("AI chat full text search disabled, sqlite FTS5 is unavailable: %s", err.Error()))
+		return nil
+	}
+	if err := db.Exec(`DROP TABLE IF EXISTS temp.ai_chat_fts5_probe`).Error; err != nil {
+		return fmt.Errorf("failed to drop AI chat FTS5 probe table: %w", err)
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		// Missing triggers mean the index is new or a build without FTS5 wrote
+		// messages it never saw, so it is rebuilt from the table.
+		var existingTriggers int64
+		if err := tx.Raw(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name IN (?, ?, ?)`,
+			aiChatFTSTriggers[0].name, aiChatFTSTriggers[1].name, aiChatFTSTriggers[2].name).Scan(&existingTriggers).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS ai_chat_messages_fts USING fts5(
+			text,
+			content='ai_chat_messages',
+			content_rowid='rowid',
+			tokenize='unicode61 remove_diacritics 2',
+			prefix='2 3'
+		)`).Error; err != nil {
+			return fmt.Errorf("failed to create AI chat FTS table: %w", err)
+		}
+		for _, trigger := range aiChatFTSTriggers {
+			if err := tx.Exec(trigger.sql).Error; err != nil {
+				return fmt.Errorf("failed to create AI chat FTS trigger %s: %w", trigger.name, err)
+			}
+		}
+		if existingTriggers < int64(len(aiChatFTSTriggers)) {
+			if err := tx.Exec(`INSERT INTO ai_chat_messages_fts(ai_chat_messages_fts) VALUES ('rebuild')`).Error; err != nil {
+				return fmt.Errorf("failed to backfill AI chat FTS table: %w", err)
+			}
+			util.GetLogger().Info(ctx, "AI chat full text index rebuilt from messages")
+		}
+		return nil
+	})
+}
+
+// AIChatStore persists AI chats in the chat, message and tool-call tables.
+type AIChatStore struct {
+	db *gorm.DB
+}
+
+func NewAIChatStore(db *gorm.DB) *AIChatStore {
+	return &AIChatStore{db: db}
+}
+
+// ListChats loads every chat with its messages, newest first.
+func (s *AIChatStore) ListChats(ctx context.Context) ([]common.AIChatData, error) {
+	var chatRows []AIChat
+	if err := s.db.WithContext(ctx).Order("updated_timestamp DESC").Find(&chatRows).Error; err != nil {
+		return nil, err
+	}
+	var messageRows []AIChatMessage
+	if err := s.db.WithContext(ctx).Order("chat_id, position").Find(&messageRows).Error; err != nil {
+		return nil, err
+	}
+	var toolCallRows []AIChatToolCall
+	if err := s.db.WithContext(ctx).Find(&toolCallRows).Error; err != nil {
+		return nil, err
+	}
+
+	toolCalls := make(map[string]AIChatToolCall, len(toolCallRows))
+	for _, row := range toolCallRows {
+		toolCalls[row.ChatID+"\x00"+row.MessageID] = row
+	}
+	messagesByChat := make(map[string][]common.Conversation, len(chatRows))
+	for _, row := range messageRows {
+		toolCall, hasToolCall := toolCalls[row.ChatID+"\x00"+row.ID]
+		messagesByChat[row.ChatID] = append(messagesByChat[row.ChatID], aiChatMessageToConversation(row, toolCall, hasToolCall))
+	}
+
+	chats := make([]common.AIChatData, 0, len(chatRows))
+	for _, row := range chatRows {
+		chat := aiChatRowToData(row)
+		chat.Conversations = messagesByChat[row.ID]
+		if chat.Conversations == nil {
+			chat.Conversations = []common.Conversation{}
+		}
+		chats = append(chats, chat)
+	}
+	return chats, nil
+}
+
+// GetChat loads one chat with its messages. The bool is false when the chat
+// does not exist.
+func (s *AIChatStore) GetChat(ctx context.Context, chatID string) (common.AIChatData, bool, error) {
+	var chatRow AIChat
+	result := s.db.WithContext(ctx).Where("id = ?", chatID).Limit(1).Find(&chatRow)
+	if result.Error != nil {
+		return common.AIChatData{}, false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return common.AIChatData{}, false, nil
+	}
+
+	var messageRows []AIChatMessage
+	if err := s.db.WithContext(ctx).Where("chat_id = ?", chatID).Order("position").Find(&messageRows).Error; err != nil {
+		return common.AIChatData{}, false, err
+	}
+	var toolCallRows []AIChatToolCall
+	if err := s.db.WithContext(ctx).Where("chat_id = ?", chatID).Find(&toolCallRows).Error; err != nil {
+		return common.AIChatData{}, false, err
+	}
+	toolCalls := make(map[string]AIChatToolCall, len(toolCallRows))
+	for _, row := range toolCallRows {
+		toolCalls[row.MessageID] = row
+	}
+
+	chat := aiChatRowToData(chatRow)
+	chat.Conversations = make([]common.Conversation, 0, len(messageRows))
+	for _, row := range messageRows {
+		toolCall, hasToolCall := toolCalls[row.ID]
+		chat.Conversations = append(chat.Conversations, aiChatMessageToConversation(row, toolCall, hasToolCall))
+	}
+	return chat, true, nil
+}
+
+// SaveChat writes the chat row and all of its messages, and removes messages
+// that are no longer part of the chat.
+func (s *AIChatStore) SaveChat(ctx context.Context, chat common.AIChatData) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := saveAIChatRow(tx, chat); err != nil {
+			return err
+		}
+		messageIDs := make([]string, 0, len(chat.Conversations))
+		for position, conversation := range chat.Conversations {
+			if err := saveAIChatMessage(tx, chat.Id, position, conversation); err != nil {
+				return err
+			}
+			messageIDs = append(messageIDs, conversation.Id)
+		}
+
+		staleMessages := tx.Where("chat_id = ?", chat.Id)
+		staleToolCalls := tx.Where("chat_id = ?", chat.Id)
+		if len(messageIDs) > 0 {
+			staleMessages = staleMessages.Where("id NOT IN ?", messageIDs)
+			staleToolCalls = staleToolCalls.Where("message_id NOT IN ?", messageIDs)
+		}
+		if err := staleToolCalls.Delete(&AIChatToolCall{}).Error; err != nil {
+			return err
+		}
+		return staleMessages.Delete(&AIChatMessage{}).Error
+	})
+}
+
+// SaveConversations is the incremental path used while a reply streams: it
+// updates the chat row and only the listed conversations. Unknown ids are
+// ignored.
+func (s *AIChatStore) SaveConversations(ctx context.Context, chat common.AIChatData, conversationIDs ...string) error {
+	wanted := make(map[string]bool, len(conversationIDs))
+	for _, id := range conversationIDs {
+		wanted[id] = true
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := saveAIChatRow(tx, chat); err != nil {
+			return err
+		}
+		for position, conversation := range chat.Conversations {
+			if !wanted[conversation.Id] {
+				continue
+			}
+			if err := saveAIChatMessage(tx, chat.Id, position, conversation); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteChat removes a chat with its messages and tool calls.
+func (s *AIChatStore) DeleteChat(ctx context.Context, chatID string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("chat_id = ?", chatID).Delete(&AIChatToolCall{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("chat_id = ?", chatID).Delete(&AIChatMessage{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", chatID).Delete(&AIChat{}).Error
+	})
+}
+
+// SearchMessages finds user and assistant messages across all chats. FTS5 is
+// used when the index exists; otherwise, or for input with no indexable
+// token, messages are matched with LIKE.
+func (s *AIChatStore) SearchMessages(ctx context.Context, search string, limit int) ([]AIChatMessageSearchHit, error) {
+	search = strings.TrimSpace(search)
+	if search == "" {
+		return nil, nil
+	}
+
+	if s.hasFullTextIndex(ctx) {
+		if match, ok := util.BuildFTSQuery(search, []string{"text"}); ok {
+			hits, err := s.searchFullText(ctx, match, limit)
+			if err == nil {
+				return hits, nil
+			}
+			util.GetLogger().Warn(ctx, fmt.Sprintf("AI chat full text search failed for %q, falling back to LIKE: %s", search, err.Error()))
+		}
+	}
+	return s.searchLike(ctx, search, limit)
+}
+
+func (s *AIChatStore) hasFullTextIndex(ctx context.Context) bool {
+	var triggerCount int64
+	if err := s.db.WithContext(ctx).Raw(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name = ?`, aiChatFTSTriggers[0].name).Scan(&triggerCount).Error; err != nil {
+		return false
+	}
+	return triggerCount > 0
+}
+
+func (s *AIChatStore) searchFullText(ctx context.Context, match string, limit int) ([]AIChatMessageSearchHit, error) {
+	var hits []AIChatMessageSearchHit
+	err := s.db.WithContext(ctx).Raw(`
+	SELECT m.chat_id, c.title AS chat_title, m.id AS message_id, m.role, snippet(ai_chat_messages_fts, 0, '', '', '…', 16) AS snippet, m.timestamp
+	FROM ai_chat_messages_fts f
+	JOIN ai_chat_messages m ON m.rowid = f.rowid
+	JOIN ai_chats c ON c.id = m.chat_id
+	WHERE ai_chat_messages_fts MATCH ? AND m.role IN (?, ?)
+	ORDER BY bm25(ai_chat_messages_fts) / (1.0 + MAX(0, ? - m.timestamp) * 1.0 / ?) ASC, m.timestamp DESC
+	LIMIT ?
+	`, match, common.ConversationRoleUser, common.ConversationRoleAssistant, util.GetSystemTimestamp(), aiChatSearchRecencyMillis, limit).Scan(&hits).Error
+	return hits, err
+}
+
+func (s *AIChatStore) searchLike(ctx context.Context, search string, limit int) ([]AIChatMessageSearchHit, error) {
+	var rows []struct {
+		ChatID    string
+		ChatTitle string
+		MessageID string
+		Role      string
+		Text      string
+		Timestamp int64
+	}
+	err := s.db.WithContext(ctx).Raw(`
+	SELECT m.chat_id, c.title AS chat_title, m.id AS message_id, m.role, m.text, m.timestamp
+	FROM ai_chat_messages m
+	JOIN ai_chats c ON c.id = m.chat_id
+	WHERE m.text LIKE ? ESCAPE '\' AND m.role IN (?, ?)
+	ORDER BY m.timestamp DESC
+	LIMIT ?
+	`, "%"+aiChatEscapeLike(search)+"%", common.ConversationRoleUser, common.ConversationRoleAssistant, limit).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]AIChatMessageSearchHit, 0, len(rows))
+	for _, row := range rows {
+		hits = append(hits, AIChatMessageSearchHit{
+			ChatID:    row.ChatID,
+			ChatTitle: row.ChatTitle,
+			MessageID: row.MessageID,
+			Role:      row.Role,
+			Snippet:   aiChatLikeSnippet(row.Text, search),
+			Timestamp: row.Timestamp,
+		})
+	}
+	return hits, nil
+}
+
+// ListMessageTexts returns every user and assistant message with text. The
+// semantic index embeds these, so tool output and empty rows are left out.
+func (s *AIChatStore) ListMessageTexts(ctx context.Context) ([]AIChatMessageText, error) {
+	var rows []AIChatMessageText
+	err := s.db.WithContext(ctx).Raw(`
+	SELECT chat_id, id AS message_id, text
+	FROM ai_chat_messages
+	WHERE role IN (?, ?) AND TRIM(COALESCE(text, '')) <> ''
+	`, common.ConversationRoleUser, common.ConversationRoleAssistant).Scan(&rows).Error
+	return rows, err
+}
+
+// GetMessageSearchHits loads the messages named by the ChatID and MessageID
+// of keys as search hits, in key order. Messages that no longer exist are skipped.
+func (s *AIChatStore) GetMessageSearchHits(ctx context.Context, keys []AIChatMessageText) ([]AIChatMessageSearchHit, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	messageIDs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		messageIDs = append(messageIDs, key.MessageID)
+	}
+	var rows []struct {
+		ChatID    string
+		ChatTitle string
+		MessageID string
+		Role      string
+		Text      string
+		Timestamp int64
+	}
+	err := s.db.WithContext(ctx).Raw(`
+	SELECT m.chat_id, c.title AS chat_title, m.id AS message_id, m.role, m.text, m.timestamp
+	FROM ai_chat_messages m
+	JOIN ai_chats c ON c.id = m.chat_id
+	WHERE m.id IN ?
+	`, messageIDs).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	hitsByKey := make(map[string]AIChatMessageSearchHit, len(rows))
+	for _, row := range rows {
+		hitsByKey[row.ChatID+"\x00"+row.MessageID] = AIChatMessageSearchHit{
+			ChatID:    row.ChatID,
+			ChatTitle: row.ChatTitle,
+			MessageID: row.MessageID,
+			Role:      row.Role,
+			Snippet:   aiChatLikeSnippet(row.Text, ""),
+			Timestamp: row.Timestamp,
+		}
+	}
+	hits := make([]AIChatMessageSearchHit, 0, len(keys))
+	for _, key := range keys {
+		if hit, ok := hitsByKey[key.ChatID+"\x00"+key.MessageID]; ok {
+			hits = append(hits, hit)
+		}
+	}
+	return hits, nil
+}
+
+// aiChatEscapeLike escapes LIKE wildcards so "%" and "_" in a search match
+// literally.
+func aiChatEscapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// aiChatLikeSnippet cuts a window of text around the first case-insensitive
+// match, mirroring what FTS5 snippet() returns for the indexed path.
+func aiChatLikeSnippet(text string, search string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= aiChatSnippetRunes {
+		return text
+	}
+
+	matchRune := 0
+	if index := strings.Index(strings.ToLower(text), strings.ToLower(search)); index > 0 {
+		matchRune = utf8.RuneCountInString(text[:index])
+	}
+	start := max(0, matchRune-aiChatSnippetRunes/4)
+	end := min(len(runes), start+aiChatSnippetRunes)
+	start = max(0, end-aiChatSnippetRunes)
+
+	snippet := string(runes[start:end])
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+// ExportChatJSON returns the stored chat as indented JSON in the same shape the
+// chat UI uses.
+func (s *AIChatStore) ExportChatJSON(ctx context.Context, chatID string) ([]byte, error) {
+	chat, found, err := s.GetChat(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("chat not found: %s", chatID)
+	}
+	return json.MarshalIndent(chat, "", "  ")
+}
+
+// ExportChatMarkdown renders the stored chat as a readable transcript. Tool
+// calls are folded into <details> blocks so the conversation stays skimmable.
+func (s *AIChatStore) ExportChatMarkdown(ctx context.Context, chatID string) (string, error) {
+	chat, found, err := s.GetChat(ctx, chatID)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", fmt.Errorf("chat not found: %s", chatID)
+	}
+	return FormatAIChatMarkdown(chat), nil
+}
+
+// FormatAIChatMarkdown is the Markdown transcript format used by
+// ExportChatMarkdown.
+func FormatAIChatMarkdown(chat common.AIChatData) string {
+	var builder strings.Builder
+	title := strings.TrimSpace(chat.Title)
+	if title == "" {
+		title = chat.Id
+	}
+	builder.WriteString("# " + title + "\n\n")
+	if chat.Model.Name != "" {
+		builder.WriteString(fmt.Sprintf("- Model: %s (%s)\n", chat.Model.Name, chat.Model.ProviderName()))
+	}
+	builder.WriteString("- Created: " + util.FormatTimestamp(chat.CreatedAt) + "\n")
+	builder.WriteString("- Updated: " + util.FormatTimestamp(chat.UpdatedAt) + "\n")
+
+	for _, conversation := range chat.Conversations {
+		builder.WriteString("\n")
+		switch conversation.Role {
+		case common.ConversationRoleTool:
+			toolCall := conversation.ToolCallInfo
+			builder.WriteString(fmt.Sprintf("<details>\n<summary>Tool: %s (%s)</summary>\n\n", toolCall.Name, toolCall.Status))
+			if len(toolCall.Arguments) > 0 {
+				arguments, _ := json.MarshalIndent(toolCall.Arguments, "", "  ")
+				builder.WriteString("```json\n" + string(arguments) + "\n```\n\n")
+			}
+			if response := strings.TrimSpace(toolCall.Response); response != "" {
+				builder.WriteString("```\n" + response + "\n```\n\n")
+			}
+			builder.WriteString("</details>\n")
+		default:
+			roleTitle := string(conversation.Role)
+			if conversation.Role != "" {
+				roleTitle = strings.ToUpper(roleTitle[:1]) + roleTitle[1:]
+			}
+			builder.WriteString(fmt.Sprintf("## %s · %s\n\n", roleTitle, util.FormatTimestamp(conversation.Timestamp)))
+			if reasoning := strings.TrimSpace(conversation.Reasoning); reasoning != "" {
+				for _, line := range strings.Split(reasoning, "\n") {
+					builder.WriteString("> " + line + "\n")
+				}
+				builder.WriteString("\n")
+			}
+			builder.WriteString(strings.TrimSpace(conversation.Text) + "\n")
+		}
+	}
+	return builder.String()
+}
+
+func saveAIChatRow(tx *gorm.DB, chat common.AIChatData) error {
+	modelJSON, err := json.Marshal(chat.Model)
+	if err != nil {
+		return fmt.Errorf("failed to marshal chat model: %w", err)
+	}
+	compactionJSON, err := json.Marshal(chat.CompactionEntries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal chat compaction entries: %w", err)
+	}
+	return tx.Save(&AIChat{
+		ID:                    chat.Id,
+		Title:                 chat.Title,
+		ModelJSON:             string(modelJSON),
+		CompactionEntriesJSON: string(compactionJSON),
+		CreatedTimestamp:      chat.CreatedAt,
+		UpdatedTimestamp:      chat.UpdatedAt,
+	}).Error
+}
+
+func saveAIChatMessage(tx *gorm.DB, chatID string, position int, conversation common.Conversation) error {
+	imagesJSON, err := json.Marshal(conversation.Images)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message images: %w", err)
+	}
+	skillRefsJSON, err := json.Marshal(conversation.SkillRefs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message skill refs: %w", err)
+	}
+	redactedReasoningJSON, err := json.Marshal(conversation.RedactedReasoning)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message redacted reasoning: %w", err)
+	}
+	// Upsert by column list rather than Save: Save would replace the row and
+	// give it a new rowid, which churns the FTS index on every streamed chunk.
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chat_id"}, {Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"position", "role", "text", "reasoning", "reasoning_signature", "redacted_reasoning_json", "images_json", "skill_refs_json", "timestamp"}),
+	}).Create(&AIChatMessage{
+		ChatID:                chatID,
+		ID:                    conversation.Id,
+		Position:              position,
+		Role:                  string(conversation.Role),
+		Text:                  conversation.Text,
+		Reasoning:             conversation.Reasoning,
+		ReasoningSignature:    conversation.ReasoningSignature,
+		RedactedReasoningJSON: string(redactedReasoningJSON),
+		ImagesJSON:            string(imagesJSON),
+		SkillRefsJSON:         string(skillRefsJSON),
+		Timestamp:             conversation.Timestamp,
+	}).Error; err != nil {
+		return err
+	}
+
+	toolCall := conversation.ToolCallInfo
+	if toolCall.Id == "" && toolCall.Name == "" {
+		return nil
+	}
+	argumentsJSON, err := json.Marshal(toolCall.Arguments)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tool call arguments: %w", err)
+	}
+	return tx.Save(&AIChatToolCall{
+		ChatID:         chatID,
+		MessageID:      conversation.Id,
+		ToolCallID:     toolCall.Id,
+		Name:           toolCall.Name,
+		ArgumentsJSON:  string(argumentsJSON),
+		Status:         string(toolCall.Status),
+		Delta:          toolCall.Delta,
+		Response:       toolCall.Response,
+		StartTimestamp: toolCall.StartTimestamp,
+		EndTimestamp:   toolCall.EndTimestamp,
+	}).Error
+}
+
+func aiChatRowToData(row AIChat) common.AIChatData {
+	chat := common.AIChatData{
+		Id:        row.ID,
+		Title:     row.Title,
+		CreatedAt: row.CreatedTimestamp,
+		UpdatedAt: row.UpdatedTimestamp,
+	}
+	if row.ModelJSON != "" {
+		_ = json.Unmarshal([]byte(row.ModelJSON), &chat.Model)
+	}
+	if row.CompactionEntriesJSON != "" {
+		_ = json.Unmarshal([]byte(row.CompactionEntriesJSON), &chat.CompactionEntries)
+	}
+	return chat
+}
+
+func aiChatMessageToConversation(row AIChatMessage, toolCall AIChatToolCall, hasToolCall bool) common.Conversation {
+	conversation := common.Conversation{
+		Id:                 row.ID,
+		Role:               common.ConversationRole(row.Role),
+		Text:               row.Text,
+		Reasoning:          row.Reasoning,
+		ReasoningSignature: row.ReasoningSignature,
+		Timestamp:          row.Timestamp,
+	}
+	if row.ImagesJSON != "" {
+		_ = json.Unmarshal([]byte(row.ImagesJSON), &conversation.Images)
+	}
+	if row.SkillRefsJSON != "" {
+		_ = json.Unmarshal([]byte(row.SkillRefsJSON), &conversation.SkillRefs)
+	}
+	if row.RedactedReasoningJSON != "" {
+		_ = json.Unmarshal([]byte(row.RedactedReasoningJSON), &conversation.RedactedReasoning)
+	}
+	if hasToolCall {
+		conversation.ToolCallInfo = common.ToolCallInfo{
+			Id:             toolCall.ToolCallID,
+			Name:           toolCall.Name,
+			Status:         common.ToolCallStatus(toolCall.Status),
+			Delta:          toolCall.Delta,
+			Response:       toolCall.Response,
+			StartTimestamp: toolCall.StartTimestamp,
+			EndTimestamp:   toolCall.EndTimestamp,
+		}
+		if toolCall.ArgumentsJSON != "" {
+			_ = json.Unmarshal([]byte(toolCall.ArgumentsJSON), &conversation.ToolCallInfo.Arguments)
+		}
+	}
+	return conversation
+}
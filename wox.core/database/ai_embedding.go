@@ -0,0 +1,157 @@
+package database
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AIEmbedding is the vector of one document of a semantic search source.
+// Vectors are stored normalized, so cosine similarity is a dot product.
+type AIEmbedding struct {
+	Source           string `gorm:"primaryKey"`
+	DocumentID       string `gorm:"primaryKey"`
+	Model            string `gorm:"index;not null"`
+	ContentHash      string `gorm:"not null"`
+	Vector           []byte `gorm:"not null"`
+	UpdatedTimestamp int64  `gorm:"not null"`
+}
+
+// AIEmbeddingSearchHit is one document matched by SearchEmbeddings.
+type AIEmbeddingSearchHit struct {
+	DocumentID string
+	Score      float32
+}
+
+const aiEmbeddingBatchSize = 500
+
+// AIEmbeddingStore is the local vector store used by semantic search. Search
+// is a linear scan, which stays fast for the tens of thousands of documents a
+// desktop produces and needs no SQLite extension.
+type AIEmbeddingStore struct {
+	db *gorm.DB
+}
+
+func NewAIEmbeddingStore(db *gorm.DB) *AIEmbeddingStore {
+	return &AIEmbeddingStore{db: db}
+}
+
+// ListEmbeddingHashes returns the content hash of every document of a source
+// embedded with the given model, keyed by document id.
+func (s *AIEmbeddingStore) ListEmbeddingHashes(ctx context.Context, source string, model string) (map[string]string, error) {
+	var rows []AIEmbedding
+	if err := s.db.WithContext(ctx).Select("document_id", "content_hash").Where("source = ? AND model = ?", source, model).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	hashes := make(map[string]string, len(rows))
+	for _, row := range rows {
+		hashes[row.DocumentID] = row.ContentHash
+	}
+	return hashes, nil
+}
+
+// SaveEmbedding inserts or replaces the vector of one document.
+func (s *AIEmbeddingStore) SaveEmbedding(ctx context.Context, source string, documentID string, model string, contentHash string, vector []float32, timestamp int64) error {
+	if len(vector) == 0 {
+		return fmt.Errorf("empty embedding vector for %s/%s", source, documentID)
+	}
+	row := AIEmbedding{
+		Source:           source,
+		DocumentID:       documentID,
+		Model:            model,
+		ContentHash:      contentHash,
+		Vector:           encodeAIEmbeddingVector(normalizeAIEmbeddingVector(vector)),
+		UpdatedTimestamp: timestamp,
+	}
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&row).Error
+}
+
+// DeleteEmbeddings removes the vectors of documents that left a source.
+func (s *AIEmbeddingStore) DeleteEmbeddings(ctx context.Context, source string, documentIDs []string) error {
+	for start := 0; start < len(documentIDs); start += aiEmbeddingBatchSize {
+		end := min(start+aiEmbeddingBatchSize, len(documentIDs))
+		if err := s.db.WithContext(ctx).Where("source = ? AND document_id IN ?", source, documentIDs[start:end]).Delete(&AIEmbedding{}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteEmbeddingsExceptModel drops vectors of other models, which live in a
+// different vector space and can never be compared with the current one.
+func (s *AIEmbeddingStore) DeleteEmbeddingsExceptModel(ctx context.Context, model string) error {
+	return s.db.WithContext(ctx).Where("model <> ?", model).Delete(&AIEmbedding{}).Error
+}
+
+// SearchEmbeddings returns the documents of a source closest to the query
+// vector, best first. Documents scoring below minScore are skipped.
+func (s *AIEmbeddingStore) SearchEmbeddings(ctx context.Context, source string, model string, query []float32, minScore float32, limit int) ([]AIEmbeddingSearchHit, error) {
+	query = normalizeAIEmbeddingVector(query)
+	var hits []AIEmbeddingSearchHit
+	var rows []AIEmbedding
+	result := s.db.WithContext(ctx).Select("document_id", "vector").Where("source = ? AND model = ?", source, model).
+		FindInBatches(&rows, aiEmbeddingBatchSize, func(tx *gorm.DB, batch int) error {
+			for _, row := range rows {
+				vector := decodeAIEmbeddingVector(row.Vector)
+				if len(vector) != len(query) {
+					continue
+				}
+				var score float32
+				for i := range vector {
+					score += vector[i] * query[i]
+				}
+				if score >= minScore {
+					hits = append(hits, AIEmbeddingSearchHit{DocumentID: row.DocumentID, Score: score})
+				}
+			}
+			return nil
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Score > hits[j].Score
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+func normalizeAIEmbeddingVector(vector []float32) []float32 {
+	var sum float64
+	for _, value := range vector {
+		sum += float64(value) * float64(value)
+	}
+	if sum == 0 {
+		return vector
+	}
+	norm := float32(math.Sqrt(sum))
+	normalized := make([]float32, len(vector))
+	for i, value := range vector {
+		normalized[i] = value / norm
+	}
+	return normalized
+}
+
+func encodeAIEmbeddingVector(vector []float32) []byte {
+	data := make([]byte, len(vector)*4)
+	for i, value := range vector {
+		binary.LittleEndian.PutUint32(data[i*4:], math.Float32bits(value))
+	}
+	return data
+}
+
+func decodeAIEmbeddingVector(data []byte) []float32 {
+	vector := make([]float32, len(data)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+	}
+	return vector
+}
@@ -76,6 +76,19 @@ type CloudSyncState struct {
 	Bootstrapped bool
 }
 
+// CloudSyncPendingChunk keeps the pulled parts of a chunked data entity value
+// until all of them have arrived. The pull cursor moves past every page, so a
+// restart between pages would otherwise lose the parts already pulled.
+// ChunkIndex is -1 for the manifest row, whose Value is the manifest record.
+type CloudSyncPendingChunk struct {
+	EntityType string `gorm:"primaryKey"`
+	PluginID   string `gorm:"primaryKey"`
+	Key        string `gorm:"primaryKey"`
+	Version    string `gorm:"primaryKey"`
+	ChunkIndex int    `gorm:"primaryKey;autoIncrement:false"`
+	Value      string
+}
+
 // DeviceIdentity stores the local cloud sync device identifier outside synced settings.
 type DeviceIdentity struct {
 	ID       uint `gorm:"primaryKey"`
@@ -211,6 +224,7 @@ func Init(ctx context.Context) error {
 		&PluginSetting{},
 		&Oplog{},
 		&CloudSyncState{},
+		&CloudSyncPendingChunk{},
 		&DeviceIdentity{},
 		&TelemetryState{},
 		&CloudSyncHistory{},
@@ -218,11 +232,19 @@ func Init(ctx context.Context) error {
 		&MRURecord{},
 		&AttentionItem{},
 		&MigrationRecord{},
+		&AIChat{},
+		&AIChatMessage{},
+		&AIChatToolCall{},
+		&AIEmbedding{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to migrate database schema: %w", err)
 	}
 
+	if err := InitAIChatFullTextSearch(ctx, db); err != nil {
+		return fmt.Errorf("failed to initialize AI chat search: %w", err)
+	}
+
 	return nil
 }
 
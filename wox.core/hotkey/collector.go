@@ -1,5 +1,7 @@
 package hotkey
 
+import "strings"
+
 // EntrySource identifies which Wox subsystem owns a hotkey.
 type EntrySource string
 
@@ -8,6 +10,7 @@ const (
 	SourceSelection EntrySource = "selection"
 	SourceQuery     EntrySource = "query"
 	SourceDictation EntrySource = "dictation"
+	SourcePlugin    EntrySource = "plugin"
 )
 
 // Entry describes one Wox-owned hotkey before it is bound to the platform.
@@ -17,6 +20,10 @@ type Entry struct {
 	CombineKey string
 	OnPress    func()
 	OnRelease  func() // nil = press mode; non-nil = hold mode
+	// Apps limits the entry to these application identities. Empty = global.
+	Apps []string
+	// Label names the entry in the sequence overlay.
+	Label string
 }
 
 type collector struct {
@@ -58,6 +65,22 @@ func (c *collector) replaceSource(source EntrySource, entries []Entry) {
 	c.entries = filtered
 }
 
+// replaceOwned swaps the entries of one owner within a source. Owned entry IDs
+// start with ownerPrefix, see PluginEntryID.
+func (c *collector) replaceOwned(source EntrySource, ownerPrefix string, entries []Entry) {
+	filtered := c.entries[:0]
+	for _, e := range c.entries {
+		if e.Source != source || !strings.HasPrefix(e.ID, ownerPrefix) {
+			filtered = append(filtered, e)
+		}
+	}
+	for _, entry := range entries {
+		entry.Source = source
+		filtered = append(filtered, entry)
+	}
+	c.entries = filtered
+}
+
 func (c *collector) snapshot() []Entry {
 	result := make([]Entry, len(c.entries))
 	copy(result, c.entries)
@@ -18,6 +18,15 @@ type Callbacks struct {
 	OnDictationHoldPress   func(ctx context.Context, actionID string)
 	OnDictationHoldRelease func(ctx context.Context, actionID string)
 	OnDictationPressAction func(ctx context.Context, actionID string)
+	OnPlugin               func(combineKey string, pluginID string, hotkeyID string)
+	// ActiveAppIdentity resolves the frontmost application for app-scoped
+	// entries. An empty identity only matches global entries.
+	ActiveAppIdentity func() string
+	// OnSequenceStart can veto a leader before it waits for its keys, e.g.
+	// while the settings recorder owns the keyboard.
+	OnSequenceStart func(leader string) bool
+	// OnSequenceProgress drives the which-key overlay of an active sequence.
+	OnSequenceProgress func(progress utilhotkey.SequenceProgress)
 }
 
 // WoxConfig is the hotkey subset of Wox settings used by the service.
@@ -33,15 +42,25 @@ type DictationBinding struct {
 	Hotkey   string
 }
 
+// PluginBinding is the runtime hotkey binding for one plugin-registered hotkey.
+type PluginBinding struct {
+	HotkeyID string
+	Name     string
+	Hotkey   string
+}
+
 // Service is the Wox business-layer registry for global hotkeys.
 type Service struct {
 	callbacks Callbacks
 	collector *collector
 
-	mu              sync.Mutex
-	group           *utilhotkey.Group
-	registeredSpecs []utilhotkey.Spec
-	registered      []Entry
+	mu                 sync.Mutex
+	group              *utilhotkey.Group
+	registeredBindings []*hotkeyBinding
+	registered         []Entry
+	// started is set by the startup registration pass. Plugin hotkeys collected
+	// before it are bound by that pass instead of re-registering everything.
+	started bool
 }
 
 // NewService creates a Wox hotkey service with the given trigger callbacks.
@@ -71,6 +90,7 @@ func (s *Service) RegisterAll(ctx context.Context) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	s.started = true
 	return s.registerAllLocked(ctx)
 }
 
@@ -111,6 +131,82 @@ func (s *Service) UpdateDictationBindings(ctx context.Context, bindings []Dictat
 	return nil
 }
 
+// PluginEntryID namespaces a plugin hotkey ID so plugins can't replace each other's hotkeys.
+func PluginEntryID(pluginID string, hotkeyID string) string {
+	return pluginID + "/" + hotkeyID
+}
+
+// UpdatePluginBindings replaces the hotkeys of one plugin and re-registers the
+// whole Wox hotkey set. Bindings that are invalid, conflict with another
+// collected hotkey or are taken by another application are reported in
+// rejected by hotkey ID and stay unbound. err reports a registration failure,
+// after which the previous bindings are restored.
+func (s *Service) UpdatePluginBindings(ctx context.Context, pluginID string, bindings []PluginBinding) (rejected map[string]error, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previousEntries := s.collector.snapshot()
+	ownerPrefix := PluginEntryID(pluginID, "")
+	collected := make([]Entry, 0, len(previousEntries)+len(bindings))
+	for _, entry := range previousEntries {
+		if entry.Source != SourcePlugin || !strings.HasPrefix(entry.ID, ownerPrefix) {
+			collected = append(collected, entry)
+		}
+	}
+
+	rejected = map[string]error{}
+	entries := make([]Entry, 0, len(bindings))
+	for _, binding := range bindings {
+		hotkeyID := strings.TrimSpace(binding.HotkeyID)
+		hotkeyStr := strings.TrimSpace(binding.Hotkey)
+		if hotkeyID == "" || hotkeyStr == "" {
+			continue
+		}
+		parsed, parseErr := utilhotkey.ParseBinding(hotkeyStr)
+		if parseErr != nil {
+			rejected[hotkeyID] = fmt.Errorf("invalid hotkey %s: %w", hotkeyStr, parseErr)
+			continue
+		}
+		if parsed.Trigger == utilhotkey.TriggerHold {
+			rejected[hotkeyID] = fmt.Errorf("hold hotkey %s is not supported for plugins", hotkeyStr)
+			continue
+		}
+
+		entry := Entry{
+			Source:     SourcePlugin,
+			ID:         PluginEntryID(pluginID, hotkeyID),
+			CombineKey: hotkeyStr,
+			Label:      binding.Name,
+			OnPress: func() {
+				if s.callbacks.OnPlugin != nil {
+					s.callbacks.OnPlugin(hotkeyStr, pluginID, hotkeyID)
+				}
+			},
+		}
+		if conflict, found := findEntryConflict(collected, entry); found {
+			rejected[hotkeyID] = fmt.Errorf("hotkey %s conflicts with %s", hotkeyStr, describeEntry(conflict))
+			continue
+		}
+		collected = append(collected, entry)
+		entries = append(entries, entry)
+	}
+
+	s.collector.replaceOwned(SourcePlugin, ownerPrefix, entries)
+	if !s.started {
+		return rejected, nil
+	}
+	if registerErr := s.registerAllLocked(ctx); registerErr != nil {
+		s.collector.restore(previousEntries)
+		return rejected, registerErr
+	}
+	for _, entry := range entries {
+		if !s.isRegisteredLocked(entry.Source, entry.ID) {
+			rejected[strings.TrimPrefix(entry.ID, ownerPrefix)] = fmt.Errorf("hotkey %s is used by another application", entry.CombineKey)
+		}
+	}
+	return rejected, nil
+}
+
 // UnregisterAll unregisters all hotkeys managed by this service.
 func (s *Service) UnregisterAll(ctx context.Context) {
 	s.mu.Lock()
@@ -131,6 +227,10 @@ func (s *Service) IsRegistered(source EntrySource, id string) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	return s.isRegisteredLocked(source, id)
+}
+
+func (s *Service) isRegisteredLocked(source EntrySource, id string) bool {
 	for _, entry := range s.registered {
 		if entry.Source == source && entry.ID == id {
 			return true
@@ -149,121 +249,337 @@ func EffectiveSelectionHotkeyForRuntime(selectionHotkey string) string {
 
 func (s *Service) registerAllLocked(ctx context.Context) error {
 	entries := s.collector.snapshot()
-	specs, specsErr := buildHotkeySpecs(entries)
-	if specsErr != nil {
-		return specsErr
-	}
-
-	if len(specs) == 0 {
+	bindings := s.buildHotkeyBindings(ctx, entries)
+	if len(bindings) == 0 {
 		s.unregisterLocked(ctx)
 		return nil
 	}
 
-	return s.registerSpecsLocked(ctx, entries, specs)
+	return s.registerBindingsLocked(ctx, bindings)
+}
+
+// hotkeyBinding is one platform registration. Press entries that share a chord
+// are merged into one binding so app scopes and sequence keys behind the same
+// leader are resolved when the chord fires.
+type hotkeyBinding struct {
+	spec    utilhotkey.Spec
+	entries []boundEntry
+}
+
+type boundEntry struct {
+	entry Entry
+	steps []string
 }
 
-func buildHotkeySpecs(entries []Entry) ([]utilhotkey.Spec, error) {
-	specs := make([]utilhotkey.Spec, 0, len(entries))
+// buildHotkeyBindings merges the collected entries into platform bindings.
+// Entries that are invalid or conflict with an earlier entry are skipped and
+// logged, so one bad hotkey does not unbind all the others.
+func (s *Service) buildHotkeyBindings(ctx context.Context, entries []Entry) []*hotkeyBinding {
+	bindings := make([]*hotkeyBinding, 0, len(entries))
+	byChord := map[string]*hotkeyBinding{}
+	skip := func(e Entry, reason string) {
+		util.GetLogger().Warn(ctx, fmt.Sprintf("skip hotkey: source=%s id=%s key=%s: %s", e.Source, e.ID, e.CombineKey, reason))
+	}
 	for _, e := range entries {
 		combineKey := e.CombineKey
 		parsed, parseErr := utilhotkey.ParseBinding(combineKey)
 		if parseErr != nil {
-			return nil, fmt.Errorf("invalid hotkey binding: source=%s id=%s key=%s: %w", e.Source, e.ID, combineKey, parseErr)
+			skip(e, "invalid hotkey binding: "+parseErr.Error())
+			continue
 		}
 		if parsed.CombineKey == "" {
 			continue
 		}
 		if e.OnPress == nil {
-			return nil, fmt.Errorf("hotkey callback is required: source=%s id=%s key=%s", e.Source, e.ID, combineKey)
+			skip(e, "hotkey callback is required")
+			continue
 		}
 
-		spec := utilhotkey.Spec{
-			CombineKey: parsed.CombineKey,
-			Callback:   e.OnPress,
-		}
 		if parsed.Trigger == utilhotkey.TriggerHold {
 			if !utilhotkey.IsModifierChordHotkeyString(parsed.CombineKey) {
-				return nil, fmt.Errorf("hold hotkey requires a modifier-only chord: source=%s id=%s key=%s", e.Source, e.ID, combineKey)
+				skip(e, "hold hotkey requires a modifier-only chord")
+				continue
 			}
 			if e.OnRelease == nil {
-				return nil, fmt.Errorf("hold hotkey release callback is required: source=%s id=%s key=%s", e.Source, e.ID, combineKey)
+				skip(e, "hold hotkey release callback is required")
+				continue
+			}
+			if len(e.Apps) > 0 {
+				skip(e, "hold hotkey can't be scoped to apps")
+				continue
+			}
+			bindings = append(bindings, &hotkeyBinding{
+				spec:    utilhotkey.Spec{CombineKey: parsed.CombineKey, Callback: e.OnPress, OnRelease: e.OnRelease},
+				entries: []boundEntry{{entry: e}},
+			})
+			continue
+		}
+
+		chord := compactHotkey(parsed.CombineKey)
+		binding := byChord[chord]
+		if binding == nil {
+			binding = &hotkeyBinding{spec: utilhotkey.Spec{CombineKey: parsed.CombineKey}}
+			byChord[chord] = binding
+			bindings = append(bindings, binding)
+		}
+		bound := boundEntry{entry: e, steps: parsed.Steps}
+		if conflictErr := binding.checkConflict(bound); conflictErr != nil {
+			skip(e, conflictErr.Error())
+			continue
+		}
+		binding.entries = append(binding.entries, bound)
+	}
+
+	registrable := make([]*hotkeyBinding, 0, len(bindings))
+	for _, binding := range bindings {
+		if binding.spec.Callback != nil {
+			registrable = append(registrable, binding)
+			continue
+		}
+		// A grabbed chord never reaches other apps, and Wox can't send it on,
+		// so app-scoped entries are only bound next to a global entry that
+		// handles the chord everywhere else.
+		if !binding.hasGlobalEntry() {
+			for _, bound := range binding.entries {
+				skip(bound.entry, "app-scoped hotkey needs a global hotkey on the same chord")
 			}
-			spec.OnRelease = e.OnRelease
+			continue
+		}
+		registrable = append(registrable, binding)
+		if len(binding.entries) == 1 && len(binding.entries[0].entry.Apps) == 0 && len(binding.entries[0].steps) == 0 {
+			binding.spec.Callback = binding.entries[0].entry.OnPress
+			continue
+		}
+		current := binding
+		binding.spec.Callback = func() {
+			s.dispatch(current)
 		}
-		specs = append(specs, spec)
 	}
-	return specs, nil
+	return registrable
 }
 
-func (s *Service) registerSpecsLocked(ctx context.Context, entries []Entry, specs []utilhotkey.Spec) error {
-	previousSpecs := cloneHotkeySpecs(s.registeredSpecs)
-	previousRegistered := cloneEntries(s.registered)
+// checkConflict rejects entries whose app scopes overlap and that can't be told
+// apart once the leader fires: a plain chord and a sequence on the same leader,
+// or two sequences where one is a prefix of the other. Plain duplicates keep
+// the historical first-wins behavior.
+func (b *hotkeyBinding) checkConflict(candidate boundEntry) error {
+	for _, existing := range b.entries {
+		if !appScopesOverlap(existing.entry.Apps, candidate.entry.Apps) {
+			continue
+		}
+		if len(existing.steps) == 0 && len(candidate.steps) == 0 {
+			continue
+		}
+		if len(existing.steps) == 0 || len(candidate.steps) == 0 {
+			return fmt.Errorf("hotkey %s is both a hotkey and a sequence leader: source=%s id=%s, source=%s id=%s", b.spec.CombineKey, existing.entry.Source, existing.entry.ID, candidate.entry.Source, candidate.entry.ID)
+		}
+		if sequenceStepsPrefix(existing.steps, candidate.steps) {
+			return fmt.Errorf("hotkey sequence %s conflicts with %s", candidate.entry.CombineKey, existing.entry.CombineKey)
+		}
+	}
+	return nil
+}
+
+// dispatch resolves the entries of a merged binding for the active app. Entries
+// scoped to that app shadow global ones on the same chord.
+func (s *Service) dispatch(binding *hotkeyBinding) {
+	identity := ""
+	if binding.isScoped() && s.callbacks.ActiveAppIdentity != nil {
+		identity = strings.TrimSpace(s.callbacks.ActiveAppIdentity())
+	}
+	active := binding.entriesFor(identity)
+	if len(active) == 0 {
+		return
+	}
+	if len(active[0].steps) == 0 {
+		active[0].entry.OnPress()
+		return
+	}
+
+	if s.callbacks.OnSequenceStart != nil && !s.callbacks.OnSequenceStart(binding.spec.CombineKey) {
+		return
+	}
+	candidates := make([]utilhotkey.SequenceCandidate, 0, len(active))
+	for _, bound := range active {
+		candidates = append(candidates, utilhotkey.SequenceCandidate{Steps: bound.steps, Label: bound.entry.Label, Callback: bound.entry.OnPress})
+	}
+	ctx := util.NewTraceContext()
+	if err := utilhotkey.StartSequence(ctx, binding.spec.CombineKey, candidates, s.callbacks.OnSequenceProgress); err != nil {
+		util.GetLogger().Warn(ctx, fmt.Sprintf("failed to start hotkey sequence %s: %s", binding.spec.CombineKey, err.Error()))
+	}
+}
+
+func (b *hotkeyBinding) hasGlobalEntry() bool {
+	for _, bound := range b.entries {
+		if len(bound.entry.Apps) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *hotkeyBinding) isScoped() bool {
+	for _, bound := range b.entries {
+		if len(bound.entry.Apps) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *hotkeyBinding) entriesFor(identity string) []boundEntry {
+	scoped := []boundEntry{}
+	global := []boundEntry{}
+	for _, bound := range b.entries {
+		if len(bound.entry.Apps) == 0 {
+			global = append(global, bound)
+			continue
+		}
+		if identity != "" && appScopeContains(bound.entry.Apps, identity) {
+			scoped = append(scoped, bound)
+		}
+	}
+	if len(scoped) > 0 {
+		return scoped
+	}
+	return global
+}
+
+// appScopesOverlap reports whether two entries can be active in the same app.
+// A scoped entry never overlaps a global one because it shadows it.
+func appScopesOverlap(left, right []string) bool {
+	if len(left) == 0 || len(right) == 0 {
+		return len(left) == 0 && len(right) == 0
+	}
+	for _, identity := range left {
+		if appScopeContains(right, identity) {
+			return true
+		}
+	}
+	return false
+}
+
+func appScopeContains(apps []string, identity string) bool {
+	for _, app := range apps {
+		if strings.EqualFold(strings.TrimSpace(app), strings.TrimSpace(identity)) {
+			return true
+		}
+	}
+	return false
+}
+
+func sequenceStepsPrefix(left, right []string) bool {
+	for index := 0; index < len(left) && index < len(right); index++ {
+		if compactHotkey(left[index]) != compactHotkey(right[index]) {
+			return false
+		}
+	}
+	return true
+}
+
+// findEntryConflict returns the first entry that can't be told apart from
+// candidate in an overlapping app scope: the same chord, or one sequence being
+// a prefix of the other.
+func findEntryConflict(entries []Entry, candidate Entry) (Entry, bool) {
+	candidateSteps := entryCompareSteps(candidate.CombineKey)
+	if len(candidateSteps) == 0 {
+		return Entry{}, false
+	}
+	for _, entry := range entries {
+		if !appScopesOverlap(entry.Apps, candidate.Apps) {
+			continue
+		}
+		if steps := entryCompareSteps(entry.CombineKey); len(steps) > 0 && sequenceStepsPrefix(steps, candidateSteps) {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+func entryCompareSteps(combineKey string) []string {
+	parsed, err := utilhotkey.ParseBinding(combineKey)
+	if err != nil || parsed.CombineKey == "" {
+		return nil
+	}
+	return append([]string{parsed.CombineKey}, parsed.Steps...)
+}
+
+func describeEntry(entry Entry) string {
+	if strings.TrimSpace(entry.Label) != "" {
+		return fmt.Sprintf("%s hotkey %q", entry.Source, entry.Label)
+	}
+	return fmt.Sprintf("%s hotkey %s", entry.Source, entry.CombineKey)
+}
+
+func compactHotkey(hotkey string) string {
+	return strings.ToLower(strings.Join(strings.Fields(hotkey), ""))
+}
+
+func bindingSpecs(bindings []*hotkeyBinding) []utilhotkey.Spec {
+	specs := make([]utilhotkey.Spec, 0, len(bindings))
+	for _, binding := range bindings {
+		specs = append(specs, binding.spec)
+	}
+	return specs
+}
+
+func (s *Service) registerBindingsLocked(ctx context.Context, bindings []*hotkeyBinding) error {
+	previousBindings := s.registeredBindings
 	if s.group != nil {
 		s.group.Unregister(ctx)
 		s.group = nil
-		s.registeredSpecs = nil
+		s.registeredBindings = nil
 		s.registered = nil
 	}
 
-	group, err := utilhotkey.RegisterGroup(ctx, specs)
+	group, err := utilhotkey.RegisterGroup(ctx, bindingSpecs(bindings))
 	if err != nil {
-		if len(previousSpecs) > 0 {
-			restoreGroup, restoreErr := utilhotkey.RegisterGroup(ctx, previousSpecs)
+		if len(previousBindings) > 0 {
+			restoreGroup, restoreErr := utilhotkey.RegisterGroup(ctx, bindingSpecs(previousBindings))
 			if restoreErr != nil {
 				return fmt.Errorf("failed to register hotkeys: %w; failed to restore previous hotkeys: %v", err, restoreErr)
 			}
 			s.group = restoreGroup
-			s.registeredSpecs, s.registered = registeredHotkeyState(previousSpecs, previousRegistered, restoreGroup.RegisteredCombineKeys())
+			s.registeredBindings, s.registered = registeredHotkeyState(previousBindings, restoreGroup.RegisteredCombineKeys())
 		}
 		return err
 	}
 
 	s.group = group
-	s.registeredSpecs, s.registered = registeredHotkeyState(specs, entries, group.RegisteredCombineKeys())
+	s.registeredBindings, s.registered = registeredHotkeyState(bindings, group.RegisteredCombineKeys())
 	return nil
 }
 
 func (s *Service) unregisterLocked(ctx context.Context) {
+	utilhotkey.CancelSequence()
 	if s.group != nil {
 		s.group.Unregister(ctx)
 		s.group = nil
 	}
-	s.registeredSpecs = nil
+	s.registeredBindings = nil
 	s.registered = nil
 }
 
 // registeredHotkeyState reconciles partial non-Linux registrations with their owning entries.
-func registeredHotkeyState(specs []utilhotkey.Spec, entries []Entry, registeredCombineKeys []string) ([]utilhotkey.Spec, []Entry) {
+func registeredHotkeyState(bindings []*hotkeyBinding, registeredCombineKeys []string) ([]*hotkeyBinding, []Entry) {
 	remaining := make(map[string]int, len(registeredCombineKeys))
 	for _, combineKey := range registeredCombineKeys {
 		remaining[combineKey]++
 	}
 
-	registeredSpecs := make([]utilhotkey.Spec, 0, len(registeredCombineKeys))
+	registeredBindings := make([]*hotkeyBinding, 0, len(registeredCombineKeys))
 	registeredEntries := make([]Entry, 0, len(registeredCombineKeys))
-	for index, entry := range entries {
-		if remaining[entry.CombineKey] == 0 {
+	for _, binding := range bindings {
+		if remaining[binding.spec.CombineKey] == 0 {
 			continue
 		}
-		remaining[entry.CombineKey]--
-		registeredSpecs = append(registeredSpecs, specs[index])
-		registeredEntries = append(registeredEntries, entry)
-	}
-	return registeredSpecs, registeredEntries
-}
-
-func cloneHotkeySpecs(specs []utilhotkey.Spec) []utilhotkey.Spec {
-	if len(specs) == 0 {
-		return nil
-	}
-	return append([]utilhotkey.Spec(nil), specs...)
-}
-
-func cloneEntries(entries []Entry) []Entry {
-	if len(entries) == 0 {
-		return nil
+		remaining[binding.spec.CombineKey]--
+		registeredBindings = append(registeredBindings, binding)
+		for _, bound := range binding.entries {
+			registeredEntries = append(registeredEntries, bound.entry)
+		}
 	}
-	return append([]Entry(nil), entries...)
+	return registeredBindings, registeredEntries
 }
 
 func (s *Service) collectWoxConfig(ctx context.Context, config WoxConfig) {
@@ -300,13 +616,19 @@ func (s *Service) collectWoxConfig(ctx context.Context, config WoxConfig) {
 		}
 		queryHotkey := qh
 		combineKey := strings.TrimSpace(queryHotkey.Hotkey)
-		queryEntries = append(queryEntries, Entry{
+		entry := Entry{
 			ID:         combineKey,
 			CombineKey: combineKey,
+			Label:      queryHotkey.DisplayName(),
 			OnPress: func() {
 				s.callbacks.OnQuery(combineKey, queryHotkey)
 			},
-		})
+		}
+		if identity := strings.TrimSpace(queryHotkey.App.Identity); identity != "" {
+			entry.ID = combineKey + "@" + identity
+			entry.Apps = []string{identity}
+		}
+		queryEntries = append(queryEntries, entry)
 	}
 	s.collector.replaceSource(SourceQuery, queryEntries)
 }
@@ -1,25 +1,139 @@
 package hotkey
 
 import (
+	"context"
 	"testing"
 	utilhotkey "wox/util/hotkey"
 )
 
 func TestRegisteredHotkeyStateKeepsOnlySuccessfulEntries(t *testing.T) {
-	entries := []Entry{
-		{Source: SourceMain, ID: "main", CombineKey: "alt+space"},
-		{Source: SourceSelection, ID: "selection", CombineKey: "win+alt+space"},
-	}
-	specs := []utilhotkey.Spec{
-		{CombineKey: "alt+space", Callback: func() {}},
-		{CombineKey: "win+alt+space", Callback: func() {}},
+	bindings := []*hotkeyBinding{
+		{spec: utilhotkey.Spec{CombineKey: "alt+space", Callback: func() {}}, entries: []boundEntry{{entry: Entry{Source: SourceMain, ID: "main", CombineKey: "alt+space"}}}},
+		{spec: utilhotkey.Spec{CombineKey: "win+alt+space", Callback: func() {}}, entries: []boundEntry{{entry: Entry{Source: SourceSelection, ID: "selection", CombineKey: "win+alt+space"}}}},
 	}
 
-	registeredSpecs, registeredEntries := registeredHotkeyState(specs, entries, []string{"win+alt+space"})
-	if len(registeredSpecs) != 1 || registeredSpecs[0].CombineKey != "win+alt+space" {
-		t.Fatalf("registered specs = %+v, want selection hotkey only", registeredSpecs)
+	registeredBindings, registeredEntries := registeredHotkeyState(bindings, []string{"win+alt+space"})
+	if len(registeredBindings) != 1 || registeredBindings[0].spec.CombineKey != "win+alt+space" {
+		t.Fatalf("registered bindings = %+v, want selection hotkey only", registeredBindings)
 	}
 	if len(registeredEntries) != 1 || registeredEntries[0].Source != SourceSelection {
 		t.Fatalf("registered entries = %+v, want selection entry only", registeredEntries)
 	}
 }
+
+func TestBuildHotkeyBindingsMergesSequencesBehindLeader(t *testing.T) {
+	service := NewService(Callbacks{})
+	bindings := service.buildHotkeyBindings(context.Background(), []Entry{
+		{Source: SourceMain, ID: "main", CombineKey: "alt+space", OnPress: func() {}},
+		{Source: SourceQuery, ID: "github", CombineKey: "ctrl+space, g, h", OnPress: func() {}},
+		{Source: SourceQuery, ID: "gitlab", CombineKey: "ctrl+space, g, l", OnPress: func() {}},
+	})
+	if len(bindings) != 2 || bindings[1].spec.CombineKey != "ctrl+space" || len(bindings[1].entries) != 2 {
+		t.Fatalf("bindings = %+v, want main plus one ctrl+space leader", bindings)
+	}
+	if len(bindings[1].entries[0].steps) != 2 || bindings[1].entries[0].steps[1] != "h" {
+		t.Fatalf("leader entry steps = %v, want g, h", bindings[1].entries[0].steps)
+	}
+}
+
+func TestBuildHotkeyBindingsSkipsAmbiguousLeaders(t *testing.T) {
+	service := NewService(Callbacks{})
+	conflicts := [][]Entry{
+		{
+			{Source: SourceMain, ID: "main", CombineKey: "ctrl+space", OnPress: func() {}},
+			{Source: SourceQuery, ID: "github", CombineKey: "ctrl+space, g", OnPress: func() {}},
+		},
+		{
+			{Source: SourceQuery, ID: "git", CombineKey: "ctrl+space, g", OnPress: func() {}},
+			{Source: SourceQuery, ID: "github", CombineKey: "ctrl+space, g, h", OnPress: func() {}},
+		},
+	}
+	for _, entries := range conflicts {
+		// The conflicting entry is dropped, the rest stay bound.
+		entries = append(entries, Entry{Source: SourceSelection, ID: "selection", CombineKey: "alt+s", OnPress: func() {}})
+		bindings := service.buildHotkeyBindings(context.Background(), entries)
+		if len(bindings) != 2 || len(bindings[0].entries) != 1 || bindings[0].entries[0].entry.ID != entries[0].ID {
+			t.Fatalf("bindings = %+v, want %s and the selection hotkey", bindings, entries[0].ID)
+		}
+	}
+
+	// A sequence scoped to one app doesn't collide with the global chord it shadows.
+	bindings := service.buildHotkeyBindings(context.Background(), []Entry{
+		{Source: SourceMain, ID: "main", CombineKey: "ctrl+space", OnPress: func() {}},
+		{Source: SourceQuery, ID: "github", CombineKey: "ctrl+space, g", Apps: []string{"com.microsoft.VSCode"}, OnPress: func() {}},
+	})
+	if len(bindings) != 1 || len(bindings[0].entries) != 2 {
+		t.Fatalf("bindings = %+v, want the scoped sequence next to the global chord", bindings)
+	}
+}
+
+func TestBuildHotkeyBindingsSkipsChordsWithoutGlobalEntry(t *testing.T) {
+	service := NewService(Callbacks{})
+	bindings := service.buildHotkeyBindings(context.Background(), []Entry{
+		{Source: SourceQuery, ID: "code", CombineKey: "ctrl+k", Apps: []string{"com.microsoft.VSCode"}, OnPress: func() {}},
+		{Source: SourceMain, ID: "main", CombineKey: "alt+space", OnPress: func() {}},
+	})
+	if len(bindings) != 1 || bindings[0].spec.CombineKey != "alt+space" {
+		t.Fatalf("bindings = %+v, want only the main hotkey", bindings)
+	}
+}
+
+func TestScopedEntryShadowsGlobalEntryInItsApp(t *testing.T) {
+	identity := ""
+	service := NewService(Callbacks{ActiveAppIdentity: func() string { return identity }})
+	fired := ""
+	bindings := service.buildHotkeyBindings(context.Background(), []Entry{
+		{Source: SourceQuery, ID: "global", CombineKey: "ctrl+k", OnPress: func() { fired = "global" }},
+		{Source: SourceQuery, ID: "code", CombineKey: "ctrl+k", Apps: []string{"com.microsoft.VSCode"}, OnPress: func() { fired = "code" }},
+	})
+	if len(bindings) != 1 {
+		t.Fatalf("bindings = %+v, want one merged ctrl+k binding", bindings)
+	}
+
+	identity = "com.microsoft.vscode"
+	bindings[0].spec.Callback()
+	if fired != "code" {
+		t.Fatalf("fired %q in VS Code, want code", fired)
+	}
+	identity = "com.apple.Safari"
+	bindings[0].spec.Callback()
+	if fired != "global" {
+		t.Fatalf("fired %q in Safari, want global", fired)
+	}
+}
+
+func TestUpdatePluginBindingsRejectsConflicts(t *testing.T) {
+	service := NewService(Callbacks{})
+	service.collector.set(SourceMain, "main", Entry{CombineKey: "alt+space", OnPress: func() {}})
+	service.collector.set(SourceQuery, "github", Entry{CombineKey: "ctrl+space, g", OnPress: func() {}})
+
+	rejected, err := service.UpdatePluginBindings(t.Context(), "timer", []PluginBinding{
+		{HotkeyID: "start", Name: "Start", Hotkey: "ctrl+alt+t"},
+		{HotkeyID: "main", Hotkey: "alt+space"},
+		{HotkeyID: "prefix", Hotkey: "ctrl+space, g, h"},
+		{HotkeyID: "hold", Hotkey: "hold:left_alt"},
+		{HotkeyID: "twice", Hotkey: "ctrl+alt+t"},
+	})
+	if err != nil {
+		t.Fatalf("update plugin bindings: %v", err)
+	}
+	if len(rejected) != 4 || rejected["start"] != nil {
+		t.Fatalf("rejected = %v, want every binding but start", rejected)
+	}
+
+	// Another plugin can't take the chord, and replacing the first plugin's hotkeys frees it.
+	if rejected, _ := service.UpdatePluginBindings(t.Context(), "media", []PluginBinding{{HotkeyID: "play", Hotkey: "ctrl+alt+t"}}); rejected["play"] == nil {
+		t.Fatalf("expected media hotkey to conflict with timer hotkey")
+	}
+	if _, err := service.UpdatePluginBindings(t.Context(), "timer", nil); err != nil {
+		t.Fatalf("clear plugin bindings: %v", err)
+	}
+	if rejected, _ := service.UpdatePluginBindings(t.Context(), "media", []PluginBinding{{HotkeyID: "play", Hotkey: "ctrl+alt+t"}}); len(rejected) != 0 {
+		t.Fatalf("rejected = %v, want media hotkey accepted", rejected)
+	}
+	for _, entry := range service.Snapshot() {
+		if entry.ID == PluginEntryID("timer", "start") {
+			t.Fatalf("expected timer hotkeys to be removed, got %+v", entry)
+		}
+	}
+}
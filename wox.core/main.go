@@ -21,6 +21,7 @@ import (
 	"strings"
 	"wox/common"
 	"wox/i18n"
+	"wox/mcpserver"
 	"wox/plugin"
 	"wox/resource"
 	"wox/setting"
@@ -91,6 +92,15 @@ func main() {
 		}
 		return
 	}
+	// The stdio bridge is started by MCP clients and only forwards to the running
+	// instance, so it must not touch the UI, the logger or the instance lock.
+	if mcpserver.IsStdioBridgeArg(os.Args) {
+		if err := mcpserver.RunStdioBridge(context.Background(), updater.CURRENT_VERSION); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 	if diagnostic.GetManager().IsSupervisorArg(os.Args) {
 		ctx := util.NewTraceContext()
 		if locationErr := util.GetLocation().Init(); locationErr != nil {
@@ -334,6 +344,9 @@ func run() {
 	// Start plugins (dictation.Init collects dictation hotkeys via the registrar;
 	// registration stays deferred until the unified pass below).
 	plugin.GetPluginManager().Start(ctx, shareUI)
+	initMCPServer(ctx)
+	// Plugins register their semantic sources in Init, so indexing starts after them.
+	ai.GetSemanticIndex().Start(util.NewTraceContext())
 
 	selection.InitSelection()
 
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+	"wox/common"
+	"wox/mcpserver"
+	"wox/plugin"
+	clipboardplugin "wox/plugin/system/clipboard"
+	filesearchplugin "wox/plugin/system/file_search"
+	"wox/updater"
+	"wox/util"
+
+	"github.com/google/uuid"
+)
+
+const mcpQueryTimeout = 10 * time.Second
+
+// mcpSessionId keeps MCP queries in their own result cache, so execute_action
+// can find results without touching the launcher session.
+var mcpSessionId = "core-mcp-" + uuid.NewString()
+
+func initMCPServer(ctx context.Context) {
+	mcpserver.GetManager().SetHandlers(updater.CURRENT_VERSION, mcpserver.Handlers{
+		Query:         mcpQuery,
+		ExecuteAction: mcpExecuteAction,
+		SearchClipboard: func(ctx context.Context, query string, limit int) (json.RawMessage, error) {
+			return mcpInvokeSearchCommand(ctx, clipboardplugin.PluginID, clipboardplugin.PluginCommandSearch, common.ContextData{
+				clipboardplugin.PluginCommandDataQuery: query,
+				clipboardplugin.PluginCommandDataLimit: strconv.Itoa(limit),
+			}, clipboardplugin.PluginCommandResultDataResults, limit)
+		},
+		SearchFiles: func(ctx context.Context, query string, limit int) (json.RawMessage, error) {
+			return mcpInvokeSearchCommand(ctx, filesearchplugin.PluginID, filesearchplugin.PluginCommandSearch, common.ContextData{
+				filesearchplugin.PluginCommandDataQuery: query,
+			}, filesearchplugin.PluginCommandResultDataResults, limit)
+		},
+		InvokePluginCommand: func(ctx context.Context, pluginId string, command string, data map[string]string) (mcpserver.PluginCommandResult, error) {
+			result, err := plugin.GetPluginManager().InvokePluginCommand(ctx, nil, plugin.PluginCommandRequest{
+				PluginId: pluginId,
+				Command:  command,
+				Data:     data,
+			})
+			if err != nil {
+				return mcpserver.PluginCommandResult{}, err
+			}
+			return mcpserver.PluginCommandResult{Handled: result.Handled, Message: result.Message, Data: result.Data}, nil
+		},
+	})
+	mcpserver.GetManager().Reload(ctx)
+}
+
+func mcpQuery(ctx context.Context, text string, limit int) (string, []mcpserver.QueryResult, error) {
+	queryId := uuid.NewString()
+	ctx = util.WithQueryIdContext(util.WithSessionContext(ctx, mcpSessionId), queryId)
+	query, _, err := plugin.GetPluginManager().NewQuery(ctx, common.PlainQuery{
+		QueryId:   queryId,
+		QueryType: plugin.QueryTypeInput,
+		QueryText: text,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	var results []plugin.QueryResultUI
+	execution := plugin.GetPluginManager().Query(ctx, query)
+	timeout := time.After(mcpQueryTimeout)
+collect:
+	for {
+		select {
+		case response := <-execution.Results:
+			results = append(results, response.Results...)
+		case <-execution.Done:
+			break collect
+		case <-timeout:
+			util.GetLogger().Warn(ctx, fmt.Sprintf("MCP query timed out, returning %d results: %s", len(results), text))
+			break collect
+		}
+	}
+
+	slices.SortStableFunc(results, func(a, b plugin.QueryResultUI) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+	converted := make([]mcpserver.QueryResult, 0, min(len(results), limit))
+	for _, result := range results {
+		if result.IsGroup {
+			continue
+		}
+		item := mcpserver.QueryResult{Id: result.Id, Title: result.Title, SubTitle: result.SubTitle, Group: result.Group}
+		for _, action := range result.Actions {
+			// Form actions need user input in the launcher, so they cannot run from MCP.
+			if action.Type == plugin.QueryResultActionTypeForm {
+				continue
+			}
+			item.Actions = append(item.Actions, mcpserver.QueryAction{Id: action.Id, Name: action.Name, IsDefault: action.IsDefault})
+		}
+		converted = append(converted, item)
+		if len(converted) >= limit {
+			break
+		}
+	}
+	return queryId, converted, nil
+}
+
+func mcpExecuteAction(ctx context.Context, queryId string, resultId string, actionId string) error {
+	ctx = util.WithQueryIdContext(util.WithSessionContext(ctx, mcpSessionId), queryId)
+	return plugin.GetPluginManager().ExecuteAction(ctx, mcpSessionId, queryId, resultId, actionId)
+}
+
+// mcpInvokeSearchCommand calls a plugin search command and returns its JSON results trimmed to limit.
+func mcpInvokeSearchCommand(ctx context.Context, pluginId string, command string, data common.ContextData, resultKey string, limit int) (json.RawMessage, error) {
+	result, err := plugin.GetPluginManager().InvokePluginCommand(ctx, nil, plugin.PluginCommandRequest{
+		PluginId: pluginId,
+		Command:  command,
+		Data:     data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !result.Handled {
+		return nil, fmt.Errorf("plugin command not handled")
+	}
+	raw, ok := result.Data[resultKey]
+	if !ok {
+		return nil, fmt.Errorf("plugin returned no results: %s", result.Message)
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &items); err != nil {
+		return nil, err
+	}
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return json.Marshal(items)
+}
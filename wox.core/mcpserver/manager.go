@@ -0,0 +1,115 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"wox/setting"
+	"wox/util"
+)
+
+// Manager runs the loopback MCP server while at least one client is enabled.
+type Manager struct {
+	mu       sync.Mutex
+	version  string
+	handlers *Handlers
+	server   *http.Server
+
+	// tokens has its own lock so requests in flight never wait on a restart.
+	tokensMu sync.RWMutex
+	tokens   []string
+}
+
+var manager = &Manager{}
+
+func GetManager() *Manager {
+	return manager
+}
+
+// SetHandlers is called once the plugin manager is ready. Reload does nothing before that.
+func (m *Manager) SetHandlers(version string, handlers Handlers) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.version = version
+	m.handlers = &handlers
+}
+
+// Reload applies the MCPServerClients setting: it refreshes the accepted tokens
+// and starts or stops the listener.
+func (m *Manager) Reload(ctx context.Context) {
+	clients := setting.GetSettingManager().GetWoxSetting(ctx).MCPServerClients.Get()
+	tokens := make([]string, 0, len(clients))
+	for _, client := range clients {
+		if !client.Disabled && len(client.Token) >= MinTokenLength {
+			tokens = append(tokens, client.Token)
+		}
+	}
+
+	m.tokensMu.Lock()
+	m.tokens = tokens
+	m.tokensMu.Unlock()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.handlers == nil {
+		return
+	}
+
+	if len(tokens) == 0 {
+		m.stopLocked(ctx)
+		return
+	}
+	if m.server != nil {
+		return
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", ServerPort()))
+	if err != nil {
+		util.GetLogger().Error(ctx, fmt.Sprintf("failed to start MCP server: %s", err.Error()))
+		return
+	}
+	server := &http.Server{
+		Handler:           NewHandler(NewServer(m.version, *m.handlers), m.enabledTokens),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	m.server = server
+	util.GetLogger().Info(ctx, fmt.Sprintf("MCP server listening on %s, clients: %d", Endpoint(), len(tokens)))
+	util.Go(ctx, "serve MCP server", func() {
+		if serveErr := server.Serve(listener); serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			util.GetLogger().Error(ctx, fmt.Sprintf("MCP server stopped: %s", serveErr.Error()))
+		}
+	})
+}
+
+// Stop closes the listener, used when Wox exits.
+func (m *Manager) Stop(ctx context.Context) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stopLocked(ctx)
+}
+
+func (m *Manager) stopLocked(ctx context.Context) {
+	if m.server == nil {
+		return
+	}
+	shutdownCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	if err := m.server.Shutdown(shutdownCtx); err != nil {
+		// Clients keep a streaming GET open, so a graceful shutdown may not finish.
+		util.GetLogger().Warn(ctx, fmt.Sprintf("MCP server did not shut down gracefully: %s", err.Error()))
+		_ = m.server.Close()
+	}
+	m.server = nil
+	util.GetLogger().Info(ctx, "MCP server stopped")
+}
+
+func (m *Manager) enabledTokens() []string {
+	m.tokensMu.RLock()
+	defer m.tokensMu.RUnlock()
+	return m.tokens
+}
@@ -0,0 +1,252 @@
+package mcpserver
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"wox/util"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// Path is the streamable HTTP endpoint served on the loopback interface.
+	Path = "/mcp"
+	// Port is the fixed loopback port, so configured clients keep working across restarts.
+	Port = 34990
+	// DevPort keeps a dev build from clashing with an installed Wox.
+	DevPort = 34991
+
+	// MinTokenLength rejects tokens short enough to be guessed by a local process.
+	MinTokenLength = 16
+
+	defaultResultLimit = 20
+	maxResultLimit     = 100
+)
+
+// ServerPort is the loopback port of the MCP server for this build.
+func ServerPort() int {
+	if util.IsDev() {
+		return DevPort
+	}
+	return Port
+}
+
+// Endpoint is the URL MCP clients connect to.
+func Endpoint() string {
+	return fmt.Sprintf("http://127.0.0.1:%d%s", ServerPort(), Path)
+}
+
+// QueryAction is an action of a query result that can be run with execute_action.
+type QueryAction struct {
+	Id        string `json:"id"`
+	Name      string `json:"name"`
+	IsDefault bool   `json:"isDefault,omitempty"`
+}
+
+// QueryResult is a Wox result as seen by MCP clients.
+type QueryResult struct {
+	Id       string        `json:"id"`
+	Title    string        `json:"title"`
+	SubTitle string        `json:"subTitle,omitempty"`
+	Group    string        `json:"group,omitempty"`
+	Actions  []QueryAction `json:"actions,omitempty"`
+}
+
+// PluginCommandResult mirrors plugin.PluginCommandResult without importing the plugin package.
+type PluginCommandResult struct {
+	Handled bool              `json:"handled"`
+	Message string            `json:"message,omitempty"`
+	Data    map[string]string `json:"data,omitempty"`
+}
+
+// Handlers connects the MCP tools to Wox. They are injected so this package does
+// not depend on the plugin manager and can be tested with fakes.
+type Handlers struct {
+	// Query runs a Wox query and returns its query id with the collected results.
+	Query               func(ctx context.Context, text string, limit int) (string, []QueryResult, error)
+	ExecuteAction       func(ctx context.Context, queryId string, resultId string, actionId string) error
+	SearchClipboard     func(ctx context.Context, query string, limit int) (json.RawMessage, error)
+	SearchFiles         func(ctx context.Context, query string, limit int) (json.RawMessage, error)
+	InvokePluginCommand func(ctx context.Context, pluginId string, command string, data map[string]string) (PluginCommandResult, error)
+}
+
+type queryInput struct {
+	Query string `json:"query" jsonschema:"the text typed into the Wox launcher, including a trigger keyword if any"`
+	Limit int    `json:"limit,omitempty" jsonschema:"maximum number of results, defaults to 20"`
+}
+
+type queryOutput struct {
+	QueryId string        `json:"queryId"`
+	Results []QueryResult `json:"results"`
+}
+
+type executeActionInput struct {
+	QueryId  string `json:"queryId" jsonschema:"queryId returned by the query tool"`
+	ResultId string `json:"resultId" jsonschema:"id of the result"`
+	ActionId string `json:"actionId" jsonschema:"id of the action to run"`
+}
+
+type executeActionOutput struct {
+	Executed bool `json:"executed"`
+}
+
+type searchInput struct {
+	Query string `json:"query,omitempty" jsonschema:"text to search for"`
+	Limit int    `json:"limit,omitempty" jsonschema:"maximum number of results, defaults to 20"`
+}
+
+type invokePluginCommandInput struct {
+	PluginId string            `json:"pluginId" jsonschema:"id of the target plugin"`
+	Command  string            `json:"command" jsonschema:"command name handled by the plugin"`
+	Data     map[string]string `json:"data,omitempty" jsonschema:"string arguments of the command"`
+}
+
+// NewServer creates the MCP server with one tool per available handler.
+func NewServer(version string, handlers Handlers) *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "wox", Version: version}, nil)
+
+	if handlers.Query != nil {
+		mcp.AddTool(server, &mcp.Tool{
+			Name:        "query",
+			Description: "Run a Wox launcher query and return its results. Use execute_action to run an action of a result.",
+		}, func(ctx context.Context, request *mcp.CallToolRequest, input queryInput) (*mcp.CallToolResult, queryOutput, error) {
+			text := strings.TrimSpace(input.Query)
+			if text == "" {
+				return nil, queryOutput{}, fmt.Errorf("query is required")
+			}
+			queryId, results, err := handlers.Query(ctx, text, normalizeLimit(input.Limit))
+			if err != nil {
+				return nil, queryOutput{}, err
+			}
+			if results == nil {
+				results = []QueryResult{}
+			}
+			return nil, queryOutput{QueryId: queryId, Results: results}, nil
+		})
+	}
+
+	if handlers.ExecuteAction != nil {
+		mcp.AddTool(server, &mcp.Tool{
+			Name:        "execute_action",
+			Description: "Run an action of a result returned by the query tool.",
+		}, func(ctx context.Context, request *mcp.CallToolRequest, input executeActionInput) (*mcp.CallToolResult, executeActionOutput, error) {
+			if input.QueryId == "" || input.ResultId == "" || input.ActionId == "" {
+				return nil, executeActionOutput{}, fmt.Errorf("queryId, resultId and actionId are required")
+			}
+			if err := handlers.ExecuteAction(ctx, input.QueryId, input.ResultId, input.ActionId); err != nil {
+				return nil, executeActionOutput{}, err
+			}
+			return nil, executeActionOutput{Executed: true}, nil
+		})
+	}
+
+	if handlers.SearchClipboard != nil {
+		mcp.AddTool(server, &mcp.Tool{
+			Name:        "search_clipboard",
+			Description: "Search the Wox clipboard history. An empty query returns the most recent entries. Sensitive entries are never returned.",
+		}, searchTool(handlers.SearchClipboard, true))
+	}
+
+	if handlers.SearchFiles != nil {
+		mcp.AddTool(server, &mcp.Tool{
+			Name:        "search_files",
+			Description: "Search files and folders in the Wox file index.",
+		}, searchTool(handlers.SearchFiles, false))
+	}
+
+	if handlers.InvokePluginCommand != nil {
+		mcp.AddTool(server, &mcp.Tool{
+			Name:        "invoke_plugin_command",
+			Description: "Send a command to a Wox plugin through the plugin command bus and return its reply.",
+		}, func(ctx context.Context, request *mcp.CallToolRequest, input invokePluginCommandInput) (*mcp.CallToolResult, PluginCommandResult, error) {
+			if strings.TrimSpace(input.PluginId) == "" || strings.TrimSpace(input.Command) == "" {
+				return nil, PluginCommandResult{}, fmt.Errorf("pluginId and command are required")
+			}
+			result, err := handlers.InvokePluginCommand(ctx, input.PluginId, input.Command, input.Data)
+			if err != nil {
+				return nil, PluginCommandResult{}, err
+			}
+			return nil, result, nil
+		})
+	}
+
+	return server
+}
+
+func searchTool(search func(ctx context.Context, query string, limit int) (json.RawMessage, error), allowEmptyQuery bool) mcp.ToolHandlerFor[searchInput, any] {
+	return func(ctx context.Context, request *mcp.CallToolRequest, input searchInput) (*mcp.CallToolResult, any, error) {
+		query := strings.TrimSpace(input.Query)
+		if query == "" && !allowEmptyQuery {
+			return nil, nil, fmt.Errorf("query is required")
+		}
+		results, err := search(ctx, query, normalizeLimit(input.Limit))
+		if err != nil {
+			return nil, nil, err
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: string(results)}}}, nil, nil
+	}
+}
+
+func normalizeLimit(limit int) int {
+	if limit <= 0 {
+		return defaultResultLimit
+	}
+	return min(limit, maxResultLimit)
+}
+
+// NewHandler serves the MCP server over streamable HTTP. Every request must carry
+// "Authorization: Bearer <token>" with one of the tokens returned by tokens.
+func NewHandler(server *mcp.Server, tokens func() []string) http.Handler {
+	streamable := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server {
+		return server
+	}, nil)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(Path, func(writer http.ResponseWriter, request *http.Request) {
+		if !isAuthorized(request, tokens()) {
+			writer.Header().Set("WWW-Authenticate", `Bearer realm="wox"`)
+			http.Error(writer, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		streamable.ServeHTTP(writer, request)
+	})
+	return mux
+}
+
+func isAuthorized(request *http.Request, tokens []string) bool {
+	header := request.Header.Get("Authorization")
+	token, found := strings.CutPrefix(header, "Bearer ")
+	token = strings.TrimSpace(token)
+	if !found || token == "" {
+		return false
+	}
+
+	// Compare against every token so the response time does not reveal which
+	// client matched.
+	authorized := false
+	for _, candidate := range tokens {
+		if len(candidate) < MinTokenLength {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(candidate)) == 1 {
+			authorized = true
+		}
+	}
+	return authorized
+}
+
+// GenerateToken returns a random token for a new MCP client.
+func GenerateToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
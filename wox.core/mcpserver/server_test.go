@@ -0,0 +1,105 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const testToken = "0123456789abcdef0123"
+
+func newTestHandlers(executed *string) Handlers {
+	return Handlers{
+		Query: func(ctx context.Context, text string, limit int) (string, []QueryResult, error) {
+			return "query-1", []QueryResult{{Id: "result-1", Title: "Open " + text, Actions: []QueryAction{{Id: "open", Name: "Open", IsDefault: true}}}}, nil
+		},
+		ExecuteAction: func(ctx context.Context, queryId string, resultId string, actionId string) error {
+			*executed = queryId + "/" + resultId + "/" + actionId
+			return nil
+		},
+		SearchFiles: func(ctx context.Context, query string, limit int) (json.RawMessage, error) {
+			return json.RawMessage(`[{"Path":"/tmp/` + query + `"}]`), nil
+		},
+	}
+}
+
+func TestHandlerRejectsMissingAndUnknownTokens(t *testing.T) {
+	t.Parallel()
+
+	handler := NewHandler(NewServer("test", Handlers{}), func() []string { return []string{testToken, "short"} })
+	for _, authorization := range []string{"", "Bearer ", "Bearer wrong-token-0000000000", "Bearer short", testToken} {
+		request := httptest.NewRequest(http.MethodPost, Path, strings.NewReader(`{}`))
+		if authorization != "" {
+			request.Header.Set("Authorization", authorization)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusUnauthorized {
+			t.Fatalf("authorization %q: expected status 401, got %d", authorization, recorder.Code)
+		}
+	}
+}
+
+func TestToolsOverStreamableHTTP(t *testing.T) {
+	t.Parallel()
+
+	var executed string
+	httpServer := httptest.NewServer(NewHandler(NewServer("test", newTestHandlers(&executed)), func() []string { return []string{testToken} }))
+	defer httpServer.Close()
+
+	ctx := context.Background()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "test"}, nil)
+	session, err := client.Connect(ctx, &mcp.StreamableClientTransport{
+		Endpoint:   httpServer.URL + Path,
+		HTTPClient: &http.Client{Transport: bearerTransport{token: testToken, base: http.DefaultTransport}},
+		MaxRetries: -1,
+	}, nil)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer session.Close()
+
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("list tools: %v", err)
+	}
+	var names []string
+	for _, tool := range tools.Tools {
+		names = append(names, tool.Name)
+	}
+	if strings.Join(names, ",") != "execute_action,query,search_files" {
+		t.Fatalf("tools = %v, want only the tools with handlers", names)
+	}
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "query", Arguments: map[string]any{"query": "wox"}})
+	if err != nil || result.IsError {
+		t.Fatalf("query: %v %+v", err, result)
+	}
+	var output queryOutput
+	if err := json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &output); err != nil {
+		t.Fatalf("decode query output: %v", err)
+	}
+	if output.QueryId != "query-1" || len(output.Results) != 1 || output.Results[0].Title != "Open wox" {
+		t.Fatalf("unexpected query output: %+v", output)
+	}
+
+	result, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "execute_action", Arguments: map[string]any{"queryId": "query-1", "resultId": "result-1", "actionId": "open"}})
+	if err != nil || result.IsError || executed != "query-1/result-1/open" {
+		t.Fatalf("execute_action: %v %+v, executed %q", err, result, executed)
+	}
+
+	result, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "search_files", Arguments: map[string]any{"query": "notes"}})
+	if err != nil || result.IsError || result.Content[0].(*mcp.TextContent).Text != `[{"Path":"/tmp/notes"}]` {
+		t.Fatalf("search_files: %v %+v", err, result)
+	}
+
+	result, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "search_files", Arguments: map[string]any{}})
+	if err != nil || !result.IsError {
+		t.Fatalf("expected an empty file query to be a tool error, got %v %+v", err, result)
+	}
+}
@@ -0,0 +1,76 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// ArgStdioBridge starts Wox as a stdio MCP server for clients that cannot
+	// speak HTTP, e.g. `wox mcp-stdio` in an editor's MCP configuration.
+	ArgStdioBridge = "mcp-stdio"
+	// EnvToken holds the client token used by the stdio bridge.
+	EnvToken = "WOX_MCP_TOKEN"
+	// EnvEndpoint optionally overrides the HTTP endpoint used by the stdio bridge.
+	EnvEndpoint = "WOX_MCP_ENDPOINT"
+)
+
+func IsStdioBridgeArg(args []string) bool {
+	return len(args) > 1 && args[1] == ArgStdioBridge
+}
+
+// RunStdioBridge serves MCP over stdin/stdout and forwards every tool call to
+// the running Wox instance, so the tools and the token check live in one place.
+// Stdout carries the protocol, so nothing else may be written to it.
+func RunStdioBridge(ctx context.Context, version string) error {
+	token := strings.TrimSpace(os.Getenv(EnvToken))
+	if token == "" {
+		return fmt.Errorf("%s is not set", EnvToken)
+	}
+	endpoint := strings.TrimSpace(os.Getenv(EnvEndpoint))
+	if endpoint == "" {
+		endpoint = Endpoint()
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "wox-stdio-bridge", Version: version}, nil)
+	session, err := client.Connect(ctx, &mcp.StreamableClientTransport{
+		Endpoint:   endpoint,
+		HTTPClient: &http.Client{Transport: bearerTransport{token: token, base: http.DefaultTransport}},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Wox at %s: %w", endpoint, err)
+	}
+	defer session.Close()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "wox", Version: version}, nil)
+	for tool, toolErr := range session.Tools(ctx, nil) {
+		if toolErr != nil {
+			return fmt.Errorf("failed to list Wox tools: %w", toolErr)
+		}
+		server.AddTool(tool, func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			params := &mcp.CallToolParams{Name: request.Params.Name}
+			if len(request.Params.Arguments) > 0 {
+				params.Arguments = request.Params.Arguments
+			}
+			return session.CallTool(ctx, params)
+		})
+	}
+
+	return server.Run(ctx, &mcp.StdioTransport{})
+}
+
+type bearerTransport struct {
+	token string
+	base  http.RoundTripper
+}
+
+func (t bearerTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	request = request.Clone(request.Context())
+	request.Header.Set("Authorization", "Bearer "+t.token)
+	return t.base.RoundTrip(request)
+}
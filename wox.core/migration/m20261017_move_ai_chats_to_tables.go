@@ -0,0 +1,73 @@
+package migration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"wox/common"
+	"wox/database"
+	"wox/util"
+
+	"gorm.io/gorm"
+)
+
+const (
+	aiChatPluginID    = "a9cfd85a-6e53-415c-9d44-68777aa6323d"
+	aiChatsSettingKey = "ai_chats"
+)
+
+func init() {
+	Register(&moveAIChatsToTablesMigration{})
+}
+
+type moveAIChatsToTablesMigration struct{}
+
+func (m *moveAIChatsToTablesMigration) ID() string {
+	return "20261017_move_ai_chats_to_tables"
+}
+
+func (m *moveAIChatsToTablesMigration) Description() string {
+	return "Move AI chat history from the ai_chats plugin setting into the chat, message and tool call tables."
+}
+
+// Up copies every chat of the legacy JSON blob into the chat store, then
+// removes the setting locally. No delete oplog is written: a synced delete
+// would wipe the history on devices that have not migrated it yet.
+func (m *moveAIChatsToTablesMigration) Up(ctx context.Context, tx *gorm.DB) error {
+	var legacySetting database.PluginSetting
+	err := tx.Where("plugin_id = ? AND key = ?", aiChatPluginID, aiChatsSettingKey).First(&legacySetting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var chats []common.AIChatData
+	if legacySetting.Value != "" {
+		if err := json.Unmarshal([]byte(legacySetting.Value), &chats); err != nil {
+			return fmt.Errorf("parse legacy AI chats: %w", err)
+		}
+	}
+
+	store := database.NewAIChatStore(tx)
+	for _, chat := range chats {
+		if chat.Id == "" {
+			continue
+		}
+		chat.DebugTrace = nil
+		chat.IsStreaming = false
+		chat.IsSummary = false
+		if err := store.SaveChat(ctx, chat); err != nil {
+			return fmt.Errorf("save AI chat %s: %w", chat.Id, err)
+		}
+	}
+
+	if err := tx.Where("plugin_id = ? AND key = ?", aiChatPluginID, aiChatsSettingKey).Delete(&database.PluginSetting{}).Error; err != nil {
+		return err
+	}
+
+	util.GetLogger().Info(ctx, fmt.Sprintf("migrated %d AI chats from plugin setting to chat tables", len(chats)))
+	return nil
+}
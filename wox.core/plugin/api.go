@@ -2,15 +2,21 @@ package plugin
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"path"
+	"strings"
 	"sync"
 	"time"
 	"wox/ai"
+	aitool "wox/ai/builtintool/wox"
 	"wox/common"
+	"wox/i18n"
+	"wox/setting"
 	"wox/setting/definition"
 	"wox/util"
 	"wox/util/clipboard"
+	"wox/util/filesearch"
 
 	"github.com/samber/lo"
 )
@@ -206,6 +212,24 @@ type API interface {
 
 	// Screenshot captures a user-selected screen area and returns the saved PNG path.
 	Screenshot(ctx context.Context, option ScreenshotOption) ScreenshotResult
+
+	// RegisterHotkey binds a global hotkey that runs hotkey.Callback when pressed.
+	// Registering an existing id replaces it. Users can rebind or disable the
+	// hotkey in the plugin settings, and all hotkeys are released when the
+	// plugin is unloaded.
+	RegisterHotkey(ctx context.Context, hotkey PluginHotkey) RegisterHotkeyResult
+
+	// UnregisterHotkey releases a hotkey registered by RegisterHotkey.
+	UnregisterHotkey(ctx context.Context, id string)
+
+	// RegisterContentExtractor lets file search index the text of extra formats,
+	// or replaces the built-in parser for a format. Registering an existing name
+	// replaces it. Extractors run in the Wox process, so only Go plugins can
+	// register them, and they are removed when the plugin is unloaded.
+	RegisterContentExtractor(ctx context.Context, extractor filesearch.ContentExtractor) error
+
+	// UnregisterContentExtractor removes an extractor registered by RegisterContentExtractor.
+	UnregisterContentExtractor(ctx context.Context, name string)
 }
 
 type CopyParams struct {
@@ -456,6 +480,10 @@ func (a *APIImpl) OnHandlePluginCommand(ctx context.Context, handler PluginComma
 
 // InvokePluginCommand sends a command request to another loaded plugin by plugin id.
 func (a *APIImpl) InvokePluginCommand(ctx context.Context, request PluginCommandRequest) (PluginCommandResult, error) {
+	if !a.pluginInstance.IsSystemPlugin && !a.pluginInstance.Metadata.CanInvokePlugin(request.PluginId) {
+		a.Log(ctx, LogLevelError, fmt.Sprintf("denied invoking plugin %s: not listed in plugin.json Permissions.InvokePlugins", request.PluginId))
+		return PluginCommandResult{}, fmt.Errorf("%w: plugin %s is not an allowed invoke target", ErrPermissionDenied, request.PluginId)
+	}
 	return GetPluginManager().InvokePluginCommand(ctx, a.pluginInstance, request)
 }
 
@@ -484,6 +512,9 @@ func (a *APIImpl) AIChatStream(ctx context.Context, model common.Model, conversa
 	if !a.pluginInstance.Metadata.IsSupportFeature(MetadataFeatureAI) {
 		return fmt.Errorf("plugin has no access to ai feature")
 	}
+	if err := a.pluginInstance.CheckPermission(ctx, MetadataPermissionAI); err != nil {
+		return err
+	}
 
 	provider, providerErr := GetPluginManager().GetAIProvider(ctx, model.Provider, model.ProviderAlias)
 	if providerErr != nil {
@@ -643,11 +674,13 @@ func (a *APIImpl) runChatLoop(ctx context.Context, model common.Model, conversat
 		// in the next iteration. Without this the model may repeat itself
 		// because it has no memory of what it already said.
 		conversations = append(conversations, common.Conversation{
-			Id:        fmt.Sprintf("%s-assistant", modelCallId),
-			Role:      common.ConversationRoleAssistant,
-			Text:      streamedResult.Data,
-			Reasoning: streamedResult.Reasoning,
-			Timestamp: util.GetSystemTimestamp(),
+			Id:                 fmt.Sprintf("%s-assistant", modelCallId),
+			Role:               common.ConversationRoleAssistant,
+			Text:               streamedResult.Data,
+			Reasoning:          streamedResult.Reasoning,
+			ReasoningSignature: streamedResult.ReasoningSignature,
+			RedactedReasoning:  streamedResult.RedactedReasoning,
+			Timestamp:          util.GetSystemTimestamp(),
 		})
 
 		// Append tool results as tool-role conversations so the next iteration
@@ -805,6 +838,26 @@ func (a *APIImpl) runSingleToolCall(ctx context.Context, tool common.Tool, strea
 	})
 	callback(*streamedResult)
 
+	if approved, decision := a.approveToolCall(ctx, tool, toolCall, options, iteration, parentCallId); !approved {
+		// A denied call is reported back to the model as a failed result, so a
+		// chat with RetryOnFailure can explain or try another way.
+		streamedResult.ToolCalls[toolCallIndex].Status = common.ToolCallStatusFailed
+		streamedResult.ToolCalls[toolCallIndex].Response = fmt.Sprintf("tool call denied (%s): %s", decision.Source, decision.Reason)
+		streamedResult.ToolCalls[toolCallIndex].EndTimestamp = util.GetSystemTimestamp()
+		deniedToolCall := streamedResult.ToolCalls[toolCallIndex]
+		options.DebugTrace.AppendEvent(common.AIChatDebugEvent{
+			Type:         common.AIChatDebugEventToolCallFinished,
+			Name:         "tool_call",
+			Iteration:    iteration,
+			CallId:       deniedToolCall.Id,
+			ParentCallId: parentCallId,
+			Status:       string(deniedToolCall.Status),
+			ToolCallInfo: &deniedToolCall,
+		})
+		callback(*streamedResult)
+		return
+	}
+
 	toolResponse, toolErr := tool.Callback(ctx, toolCall.Arguments)
 	if toolErr != nil {
 		util.GetLogger().Info(ctx, fmt.Sprintf("AI: tool returned error (still counts as a result): %s", toolErr.Error()))
@@ -839,6 +892,85 @@ func (a *APIImpl) runSingleToolCall(ctx context.Context, tool common.Tool, strea
 	callback(*streamedResult)
 }
 
+// toolApprovalMutex serializes approval cards. The chat UI shows one question
+// at a time and cancels the previous one, so concurrent tool calls must wait.
+var toolApprovalMutex sync.Mutex
+
+// approveToolCall applies the tool approval rules and asks the user when the
+// policy is ask. Every decision is logged and recorded in the debug trace.
+func (a *APIImpl) approveToolCall(ctx context.Context, tool common.Tool, toolCall common.ToolCallInfo, options common.ChatOptions, iteration int, parentCallId string) (bool, common.AIToolApprovalDecision) {
+	rules := setting.GetSettingManager().GetWoxSetting(ctx).AIToolApprovalRules.Get()
+	decision := ai.EvaluateToolApproval(tool, toolCall.Arguments, rules)
+	if decision.Policy == common.AIToolApprovalPolicyAsk {
+		decision = a.askToolApproval(ctx, tool, toolCall)
+	}
+	approved := decision.Policy == common.AIToolApprovalPolicyAllow
+
+	status := "approved"
+	if !approved {
+		status = "denied"
+	}
+	util.GetLogger().Info(ctx, fmt.Sprintf("AI: tool call %s %s by %s: %s, toolcall id: %s", tool.Name, status, decision.Source, decision.Reason, toolCall.Id))
+	options.DebugTrace.AppendEvent(common.AIChatDebugEvent{
+		Type:         common.AIChatDebugEventToolCallApproval,
+		Name:         "tool_call_approval",
+		Iteration:    iteration,
+		CallId:       toolCall.Id,
+		ParentCallId: parentCallId,
+		Status:       status,
+		ToolCallInfo: &toolCall,
+		Approval:     &decision,
+	})
+	return approved, decision
+}
+
+// askToolApproval renders an approve/deny card through the ask_user plumbing.
+// Anything but the approve option denies the call: a cancelled card, or the
+// text typed into the deny option, which is passed on to the model as reason.
+func (a *APIImpl) askToolApproval(ctx context.Context, tool common.Tool, toolCall common.ToolCallInfo) common.AIToolApprovalDecision {
+	toolApprovalMutex.Lock()
+	defer toolApprovalMutex.Unlock()
+
+	const approveValue = "approve"
+	question := fmt.Sprintf(i18n.GetI18nManager().TranslateWox(ctx, "ai_tool_approval_question"), tool.Name)
+	if summary := summarizeToolCallArguments(tool, toolCall.Arguments); summary != "" {
+		question += "\n" + summary
+	}
+	answer, err := aitool.AskUser(ctx, question, []common.AIQuestionOption{
+		{Value: approveValue, Title: i18n.GetI18nManager().TranslateWox(ctx, "ai_tool_approval_allow")},
+		{Value: "deny", Title: i18n.GetI18nManager().TranslateWox(ctx, "ai_tool_approval_deny")},
+	})
+	if err != nil {
+		return common.AIToolApprovalDecision{Policy: common.AIToolApprovalPolicyDeny, Source: common.AIToolApprovalSourceUser, Reason: err.Error()}
+	}
+	if answer != approveValue {
+		return common.AIToolApprovalDecision{Policy: common.AIToolApprovalPolicyDeny, Source: common.AIToolApprovalSourceUser, Reason: fmt.Sprintf("user answered %q", answer)}
+	}
+	return common.AIToolApprovalDecision{Policy: common.AIToolApprovalPolicyAllow, Source: common.AIToolApprovalSourceUser, Reason: "user approved"}
+}
+
+// summarizeToolCallArguments shows what the user is approving: the command for
+// bash, the path for file tools, and the raw arguments for everything else. It
+// is never shortened, since a cut command can hide what actually runs; the
+// question card scrolls instead.
+func summarizeToolCallArguments(tool common.Tool, arguments map[string]any) string {
+	var summary string
+	switch tool.Name {
+	case "bash":
+		summary, _ = arguments["command"].(string)
+	case "write", "edit":
+		path, _ := arguments["path"].(string)
+		summary = ai.ResolveToolPath(path)
+	default:
+		if len(arguments) > 0 {
+			if encoded, err := json.Marshal(arguments); err == nil {
+				summary = string(encoded)
+			}
+		}
+	}
+	return strings.TrimSpace(summary)
+}
+
 // buildToolConversations turns a streamed result's tool calls into tool-role
 // conversation entries so the next loop iteration presents them to the model.
 func buildToolConversations(streamedResult *common.ChatStreamData) []common.Conversation {
@@ -986,6 +1118,11 @@ func (a *APIImpl) RefreshGlance(ctx context.Context, ids []string) {
 }
 
 func (a *APIImpl) Copy(ctx context.Context, params CopyParams) {
+	if err := a.pluginInstance.CheckPermission(ctx, MetadataPermissionClipboardWrite); err != nil {
+		a.Log(ctx, LogLevelError, fmt.Sprintf("failed to copy to clipboard: %v", err))
+		return
+	}
+
 	if params.Type == CopyTypePlainText {
 		err := clipboard.WriteText(params.Text)
 		if err != nil {
@@ -1010,7 +1147,37 @@ func (a *APIImpl) Copy(ctx context.Context, params CopyParams) {
 	}
 }
 
+func (a *APIImpl) RegisterHotkey(ctx context.Context, hotkey PluginHotkey) RegisterHotkeyResult {
+	if err := a.pluginInstance.CheckPermission(ctx, MetadataPermissionGlobalHotkey); err != nil {
+		return RegisterHotkeyResult{
+			Success: false,
+			ErrMsg:  err.Error(),
+		}
+	}
+
+	return GetPluginManager().registerPluginHotkey(ctx, a.pluginInstance, hotkey)
+}
+
+func (a *APIImpl) UnregisterHotkey(ctx context.Context, id string) {
+	GetPluginManager().unregisterPluginHotkey(ctx, a.pluginInstance, strings.TrimSpace(id))
+}
+
+func (a *APIImpl) RegisterContentExtractor(ctx context.Context, extractor filesearch.ContentExtractor) error {
+	return GetPluginManager().registerPluginContentExtractor(ctx, a.pluginInstance, extractor)
+}
+
+func (a *APIImpl) UnregisterContentExtractor(ctx context.Context, name string) {
+	GetPluginManager().unregisterPluginContentExtractor(ctx, a.pluginInstance, strings.TrimSpace(name))
+}
+
 func (a *APIImpl) Screenshot(ctx context.Context, option ScreenshotOption) ScreenshotResult {
+	if err := a.pluginInstance.CheckPermission(ctx, MetadataPermissionScreenshot); err != nil {
+		return ScreenshotResult{
+			Success: false,
+			ErrMsg:  err.Error(),
+		}
+	}
+
 	request := common.DefaultCaptureScreenshotRequest()
 	// Plugin screenshots return a saved file path and leave clipboard handling to the caller.
 	request.Output = "file"
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"wox/cloudsync"
 	"wox/common"
 	"wox/database"
 	"wox/util"
@@ -139,6 +140,7 @@ func (m *AttentionManager) Push(ctx context.Context, source AttentionPluginSourc
 	if err != nil {
 		return database.AttentionItem{}, err
 	}
+	m.logAttentionSync(ctx, saved)
 
 	if cleanupErr := m.Cleanup(ctx); cleanupErr != nil {
 		util.GetLogger().Warn(ctx, fmt.Sprintf("failed to cleanup attention items: %v", cleanupErr))
@@ -159,16 +161,74 @@ func (m *AttentionManager) MarkRead(ctx context.Context, identityKey string) err
 	}
 
 	now := util.GetSystemTimestamp()
-	return retryAttentionDatabaseWrite(ctx, func() error {
-		return m.db.WithContext(ctx).
+	var updated int64
+	err := retryAttentionDatabaseWrite(ctx, func() error {
+		result := m.db.WithContext(ctx).
 			Model(&database.AttentionItem{}).
 			Where("identity_key = ? AND is_read = ?", identityKey, false).
 			Updates(map[string]any{
 				"is_read":           true,
 				"read_timestamp":    now,
 				"updated_timestamp": now,
-			}).Error
+			})
+		updated = result.RowsAffected
+		return result.Error
 	})
+	if err != nil || updated == 0 {
+		return err
+	}
+
+	var item database.AttentionItem
+	if findErr := m.db.WithContext(ctx).First(&item, "identity_key = ?", identityKey).Error; findErr == nil {
+		m.logAttentionSync(ctx, item)
+	}
+	return nil
+}
+
+// ApplySyncedItem stores or removes an attention item received from cloud
+// sync without queueing it again. Items follow last-writer-wins, so the remote
+// row is taken as is.
+func (m *AttentionManager) ApplySyncedItem(ctx context.Context, identityKey string, op string, rawValue string) error {
+	if m == nil || m.db == nil {
+		return errors.New("attention manager database is not initialized")
+	}
+
+	if op == cloudsync.OpDelete {
+		return retryAttentionDatabaseWrite(ctx, func() error {
+			return m.db.WithContext(ctx).Delete(&database.AttentionItem{}, "identity_key = ?", identityKey).Error
+		})
+	}
+
+	var item database.AttentionItem
+	if err := json.Unmarshal([]byte(rawValue), &item); err != nil {
+		return fmt.Errorf("failed to parse synced attention item: %w", err)
+	}
+	if item.IdentityKey != identityKey {
+		return fmt.Errorf("synced attention item key mismatch: %s", identityKey)
+	}
+	return retryAttentionDatabaseWrite(ctx, func() error {
+		return m.db.WithContext(ctx).Save(&item).Error
+	})
+}
+
+// logAttentionSync queues the stored item for cloud sync. A failed oplog write
+// must not lose the local change, so it is only logged. Cleanup does not log
+// deletes because every device applies the same retention on its own.
+func (m *AttentionManager) logAttentionSync(ctx context.Context, item database.AttentionItem) {
+	value, err := json.Marshal(item)
+	if err != nil {
+		util.GetLogger().Warn(ctx, fmt.Sprintf("failed to serialize attention item %s for cloud sync: %s", item.IdentityKey, err.Error()))
+		return
+	}
+	if err := cloudsync.WriteOplog(m.db, database.Oplog{
+		EntityType: cloudsync.EntityAttentionItem,
+		EntityID:   item.PluginID,
+		Operation:  cloudsync.OpUpsert,
+		Key:        item.IdentityKey,
+		Value:      string(value),
+	}); err != nil {
+		util.GetLogger().Warn(ctx, fmt.Sprintf("failed to log attention item %s for cloud sync: %s", item.IdentityKey, err.Error()))
+	}
 }
 
 // UnreadCount returns the current number of unread attention items.
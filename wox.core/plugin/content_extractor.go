@@ -0,0 +1,76 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"wox/util/filesearch"
+)
+
+// pluginContentExtractor scopes a plugin extractor name by plugin id, so two
+// plugins can use the same name without replacing each other in the registry.
+type pluginContentExtractor struct {
+	filesearch.ContentExtractor
+	name string
+}
+
+func (e *pluginContentExtractor) Name() string { return e.name }
+
+func pluginContentExtractorName(pluginInstance *Instance, name string) string {
+	return pluginInstance.Metadata.Id + "/" + name
+}
+
+func (m *Manager) registerPluginContentExtractor(ctx context.Context, pluginInstance *Instance, extractor filesearch.ContentExtractor) error {
+	if extractor == nil {
+		return fmt.Errorf("content extractor is nil")
+	}
+	name := strings.TrimSpace(extractor.Name())
+	if name == "" {
+		return fmt.Errorf("content extractor name is empty")
+	}
+
+	m.pluginContentExtractorsMu.Lock()
+	defer m.pluginContentExtractorsMu.Unlock()
+
+	scoped := &pluginContentExtractor{ContentExtractor: extractor, name: pluginContentExtractorName(pluginInstance, name)}
+	if err := filesearch.RegisterContentExtractor(scoped); err != nil {
+		return err
+	}
+	for _, existing := range pluginInstance.RuntimeContentExtractors {
+		if existing == name {
+			return nil
+		}
+	}
+	pluginInstance.RuntimeContentExtractors = append(pluginInstance.RuntimeContentExtractors, name)
+	logger.Info(ctx, fmt.Sprintf("plugin %s registered content extractor %s for %s", pluginInstance.Metadata.GetName(ctx), name, strings.Join(extractor.Extensions(), ",")))
+	return nil
+}
+
+func (m *Manager) unregisterPluginContentExtractor(ctx context.Context, pluginInstance *Instance, name string) {
+	m.pluginContentExtractorsMu.Lock()
+	defer m.pluginContentExtractorsMu.Unlock()
+
+	runtimeExtractors := make([]string, 0, len(pluginInstance.RuntimeContentExtractors))
+	for _, existing := range pluginInstance.RuntimeContentExtractors {
+		if existing != name {
+			runtimeExtractors = append(runtimeExtractors, existing)
+		}
+	}
+	if len(runtimeExtractors) == len(pluginInstance.RuntimeContentExtractors) {
+		return
+	}
+	pluginInstance.RuntimeContentExtractors = runtimeExtractors
+	filesearch.UnregisterContentExtractor(pluginContentExtractorName(pluginInstance, name))
+}
+
+// releasePluginContentExtractors removes every content extractor of a plugin
+// when it is deactivated, so built-in extractors take over again.
+func (m *Manager) releasePluginContentExtractors(ctx context.Context, pluginInstance *Instance) {
+	m.pluginContentExtractorsMu.Lock()
+	defer m.pluginContentExtractorsMu.Unlock()
+
+	for _, name := range pluginInstance.RuntimeContentExtractors {
+		filesearch.UnregisterContentExtractor(pluginContentExtractorName(pluginInstance, name))
+	}
+	pluginInstance.RuntimeContentExtractors = nil
+}
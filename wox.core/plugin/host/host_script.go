@@ -9,10 +9,11 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
-	"time"
+	"sync"
 	"wox/common"
 	"wox/i18n"
 	"wox/plugin"
+	"wox/setting/definition"
 	"wox/util"
 	"wox/util/clipboard"
 	"wox/util/shell"
@@ -24,7 +25,9 @@ func init() {
 }
 
 type ScriptHost struct {
-	// Script host doesn't need persistent connections like websocket hosts
+	// Script host doesn't need persistent connections like websocket hosts, it
+	// only tracks plugins with the scriptPersistent feature to stop their processes
+	persistentPlugins sync.Map // plugin id -> *ScriptPlugin
 }
 
 func (s *ScriptHost) GetRuntime(ctx context.Context) plugin.Runtime {
@@ -38,7 +41,10 @@ func (s *ScriptHost) Start(ctx context.Context) error {
 }
 
 func (s *ScriptHost) Stop(ctx context.Context) {
-	// Script host doesn't need to stop any background processes
+	s.persistentPlugins.Range(func(key, value any) bool {
+		s.stopPersistentPlugin(ctx, key.(string))
+		return true
+	})
 	util.GetLogger().Info(ctx, "Script host stopped")
 }
 
@@ -72,12 +78,19 @@ func (s *ScriptHost) LoadPlugin(ctx context.Context, metadata plugin.Metadata, p
 		util.GetLogger().Warn(ctx, fmt.Sprintf("Failed to make script executable: %s", err.Error()))
 	}
 
+	scriptPlugin := NewScriptPlugin(metadata, scriptPath)
+	if metadata.IsSupportFeature(plugin.MetadataFeatureScriptPersistent) {
+		s.persistentPlugins.Store(metadata.Id, scriptPlugin)
+	}
+
 	util.GetLogger().Info(ctx, fmt.Sprintf("Loaded script plugin: %s", metadata.GetName(ctx)))
-	return NewScriptPlugin(metadata, scriptPath), nil
+	return scriptPlugin, nil
 }
 
 func (s *ScriptHost) UnloadPlugin(ctx context.Context, metadata plugin.Metadata) {
-	// Script plugins don't need explicit unloading since they're not persistent
+	// One-shot script plugins don't need explicit unloading, persistent ones
+	// get their process stopped so an edited script starts fresh
+	s.stopPersistentPlugin(ctx, metadata.Id)
 	util.GetLogger().Info(ctx, fmt.Sprintf("Unloaded script plugin: %s", metadata.GetName(ctx)))
 }
 
@@ -86,6 +99,9 @@ type ScriptPlugin struct {
 	metadata   plugin.Metadata
 	scriptPath string
 	api        plugin.API // API for accessing plugin settings
+
+	persistentMu sync.Mutex
+	persistent   *scriptProcess // only used with the scriptPersistent feature
 }
 
 func NewScriptPlugin(metadata plugin.Metadata, scriptPath string) *ScriptPlugin {
@@ -98,6 +114,13 @@ func NewScriptPlugin(metadata plugin.Metadata, scriptPath string) *ScriptPlugin
 func (s *ScriptPlugin) Init(ctx context.Context, initParams plugin.InitParams) {
 	// Save API reference for accessing settings
 	s.api = initParams.API
+	if s.metadata.IsSupportFeature(plugin.MetadataFeatureScriptPersistent) {
+		// Settings are also exported as WOX_SETTING_* env vars, which a running
+		// process can't see change, so restart it on the next request.
+		s.api.OnSettingChanged(ctx, func(callbackCtx context.Context, key string, value string) {
+			s.stopPersistentProcess(callbackCtx)
+		})
+	}
 	util.GetLogger().Debug(ctx, fmt.Sprintf("Script plugin %s initialized", s.metadata.GetName(ctx)))
 }
 
@@ -106,17 +129,12 @@ func (s *ScriptPlugin) Query(ctx context.Context, query plugin.Query) plugin.Que
 	request := map[string]interface{}{
 		"jsonrpc": "2.0",
 		"method":  "query",
-		"params": map[string]interface{}{
-			"search":          query.Search,
-			"trigger_keyword": query.TriggerKeyword,
-			"command":         query.Command,
-			"raw_query":       query.RawQuery,
-		},
-		"id": util.GetContextTraceId(ctx),
+		"params":  s.buildQueryParams(ctx, query),
+		"id":      util.GetContextTraceId(ctx),
 	}
 
 	// Execute script and get results
-	results, err := s.executeScript(ctx, request)
+	response, err := s.executeScript(ctx, request)
 	if err != nil {
 		requestJSON, _ := json.Marshal(request)
 		util.GetLogger().Error(ctx, fmt.Sprintf("script plugin query failed for %s: %s, raw request: %s", s.metadata.GetName(ctx), err.Error(), requestJSON))
@@ -155,40 +173,104 @@ func (s *ScriptPlugin) Query(ctx context.Context, query plugin.Query) plugin.Que
 		return plugin.QueryResponse{}
 	}
 
-	return plugin.NewQueryResponse(results)
+	return response
+}
+
+// buildQueryParams converts a query into the params of the query method. The
+// first four fields are the original protocol, the rest were added in v2 and
+// scripts that don't know them simply ignore them.
+func (s *ScriptPlugin) buildQueryParams(ctx context.Context, query plugin.Query) map[string]interface{} {
+	refinements := query.Refinements
+	if refinements == nil {
+		refinements = map[string]string{}
+	}
+	contextData := query.ContextData
+	if contextData == nil {
+		contextData = common.ContextData{}
+	}
+	filePaths := query.Selection.FilePaths
+	if filePaths == nil {
+		filePaths = []string{}
+	}
+
+	return map[string]interface{}{
+		"search":          query.Search,
+		"trigger_keyword": query.TriggerKeyword,
+		"command":         query.Command,
+		"raw_query":       query.RawQuery,
+		"query_id":        query.Id,
+		"type":            query.Type,
+		"selection": map[string]interface{}{
+			"type":       query.Selection.Type,
+			"text":       query.Selection.Text,
+			"file_paths": filePaths,
+		},
+		"env": map[string]interface{}{
+			"active_window_title": query.Env.ActiveWindowTitle,
+			"active_window_pid":   query.Env.ActiveWindowPid,
+			"active_browser_url":  query.Env.ActiveBrowserUrl,
+		},
+		"refinements":  refinements,
+		"context_data": contextData,
+		"settings":     s.getSettingValues(ctx),
+	}
+}
+
+// getSettingValues returns the current value of every setting the script
+// declared in its metadata header.
+func (s *ScriptPlugin) getSettingValues(ctx context.Context) map[string]string {
+	values := map[string]string{}
+	if s.api == nil {
+		return values
+	}
+	for _, settingDef := range s.metadata.SettingDefinitions {
+		if settingDef.Value == nil {
+			continue
+		}
+		key := settingDef.Value.GetKey()
+		if key == "" {
+			continue
+		}
+		values[key] = s.api.GetSetting(ctx, key)
+	}
+	return values
 }
 
-// executeScript executes the script with the given JSON-RPC request and returns the results
-func (s *ScriptPlugin) executeScript(ctx context.Context, request map[string]interface{}) ([]plugin.QueryResult, error) {
+// executeScript executes the script with the given JSON-RPC request and returns the query response
+func (s *ScriptPlugin) executeScript(ctx context.Context, request map[string]interface{}) (plugin.QueryResponse, error) {
 	// Execute script and get raw response
 	response, err := s.executeScriptRaw(ctx, request)
 	if err != nil {
-		return nil, err
+		return plugin.QueryResponse{}, err
 	}
 
 	// Extract results
 	result, exists := response["result"]
 	if !exists {
-		return []plugin.QueryResult{}, nil
+		return plugin.QueryResponse{}, nil
 	}
 
 	resultMap, ok := result.(map[string]interface{})
 	if !ok {
-		return nil, fmt.Errorf("invalid result format")
+		return plugin.QueryResponse{}, fmt.Errorf("invalid result format")
+	}
+
+	queryResponse := plugin.QueryResponse{
+		Refinements: parseScriptRefinements(ctx, s.metadata, resultMap),
+		Layout:      parseScriptLayout(ctx, s.metadata, resultMap),
 	}
 
 	items, exists := resultMap["items"]
 	if !exists {
-		return []plugin.QueryResult{}, nil
+		return queryResponse, nil
 	}
 
 	itemsArray, ok := items.([]interface{})
 	if !ok {
-		return nil, fmt.Errorf("invalid items format")
+		return plugin.QueryResponse{}, fmt.Errorf("invalid items format")
 	}
 
 	// Convert items to QueryResult
-	var queryResults []plugin.QueryResult
 	for _, item := range itemsArray {
 		itemMap, ok := item.(map[string]interface{})
 		if !ok {
@@ -251,22 +333,41 @@ func (s *ScriptPlugin) executeScript(ctx context.Context, request map[string]int
 
 						// Capture actionMap in closure
 						actionMapCopy := actionMap
-						queryResult.Actions = append(queryResult.Actions, plugin.QueryResultAction{
-							Name: actionName,
-							Icon: actionIcon,
-							Action: func(ctx context.Context, actionContext plugin.ActionContext) {
+						action := plugin.QueryResultAction{
+							Name:                   actionName,
+							Icon:                   actionIcon,
+							IsDefault:              getFirstBoolFromMap(actionMap, []string{"isDefault", "is_default", "IsDefault"}),
+							PreventHideAfterAction: getFirstBoolFromMap(actionMap, []string{"preventHideAfterAction", "prevent_hide_after_action", "PreventHideAfterAction"}),
+							Hotkey:                 getFirstStringFromMap(actionMap, []string{"hotkey", "Hotkey"}),
+						}
+
+						if strings.EqualFold(getFirstStringFromMap(actionMap, []string{"type", "Type"}), plugin.QueryResultActionTypeForm) {
+							form, formErr := parseScriptForm(actionMap)
+							if formErr != nil {
+								util.GetLogger().Warn(ctx, fmt.Sprintf("script plugin %s returned invalid form action %s: %s", s.metadata.GetName(ctx), actionName, formErr.Error()))
+								continue
+							}
+							action.Type = plugin.QueryResultActionTypeForm
+							action.Form = form
+							action.OnSubmit = func(ctx context.Context, actionContext plugin.FormActionContext) {
+								s.executeFormAction(ctx, actionMapCopy, actionContext.Values)
+							}
+						} else {
+							action.Action = func(ctx context.Context, actionContext plugin.ActionContext) {
 								s.executeAction(ctx, actionMapCopy)
-							},
-						})
+							}
+						}
+
+						queryResult.Actions = append(queryResult.Actions, action)
 					}
 				}
 			}
 		}
 
-		queryResults = append(queryResults, queryResult)
+		queryResponse.Results = append(queryResponse.Results, queryResult)
 	}
 
-	return queryResults, nil
+	return queryResponse, nil
 }
 
 // executeAction executes an action from a script plugin result
@@ -327,14 +428,86 @@ func (s *ScriptPlugin) executeAction(ctx context.Context, actionData map[string]
 	}
 }
 
+// executeFormAction sends the submitted values of a form action to the script.
+// Form actions use the action method too, with the values in params.values.
+func (s *ScriptPlugin) executeFormAction(ctx context.Context, actionData map[string]interface{}, values map[string]string) {
+	if values == nil {
+		values = map[string]string{}
+	}
+	actionParams := map[string]interface{}{
+		"id":     getStringFromMap(actionData, "id"),
+		"values": values,
+	}
+	if actionDataValue, hasActionData := actionData["data"]; hasActionData {
+		if actionDataMap := getStringMapFromValue(actionDataValue); actionDataMap != nil {
+			actionParams["data"] = actionDataMap
+		} else if actionDataString := getStringFromMap(actionData, "data"); actionDataString != "" {
+			actionParams["data"] = actionDataString
+		}
+	}
+	request := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "action",
+		"params":  actionParams,
+		"id":      util.GetContextTraceId(ctx),
+	}
+
+	if err := s.executeScriptAction(ctx, request); err != nil {
+		util.GetLogger().Error(ctx, fmt.Sprintf("Script plugin %s form action failed: %s", s.metadata.GetName(ctx), err.Error()))
+	}
+}
+
 // executeScriptRaw executes the script with the given JSON-RPC request and returns the raw response
 func (s *ScriptPlugin) executeScriptRaw(ctx context.Context, request map[string]interface{}) (map[string]interface{}, error) {
+	if s.metadata.IsSupportFeature(plugin.MetadataFeatureScriptPersistent) {
+		return s.getPersistentProcess().call(ctx, request)
+	}
+
 	// Convert request to JSON
 	requestJSON, err := json.Marshal(request)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
+	// Set timeout for script execution
+	timeoutCtx, cancel := context.WithTimeout(ctx, scriptExecutionTimeout)
+	defer cancel()
+
+	cmd, err := s.buildScriptCommand(ctx, timeoutCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set up stdin with the JSON-RPC request
+	cmd.Stdin = strings.NewReader(string(requestJSON))
+
+	// Execute script
+	output, err := cmd.Output()
+	if err != nil {
+		if exitError, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("script execution failed: %s, stderr: %s", exitError.Error(), string(exitError.Stderr))
+		}
+
+		return nil, fmt.Errorf("script execution failed: %w", err)
+	}
+
+	// Parse JSON-RPC response
+	var response map[string]interface{}
+	if err := json.Unmarshal(output, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse script response: %w", err)
+	}
+
+	// Check for JSON-RPC error
+	if errorData, exists := response["error"]; exists {
+		return nil, fmt.Errorf("script returned error: %v", errorData)
+	}
+
+	return response, nil
+}
+
+// buildScriptCommand prepares the script process with the interpreter and the
+// WOX_* environment. runCtx bounds the lifetime of the process.
+func (s *ScriptPlugin) buildScriptCommand(ctx context.Context, runCtx context.Context) (*exec.Cmd, error) {
 	// Determine the interpreter based on file extension
 	interpreter, err := s.getInterpreter(ctx)
 	if err != nil {
@@ -343,20 +516,21 @@ func (s *ScriptPlugin) executeScriptRaw(ctx context.Context, request map[string]
 
 	util.GetLogger().Debug(ctx, fmt.Sprintf("Using interpreter: '%s' for script: %s", interpreter, s.scriptPath))
 
-	// Set timeout for script execution
-	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	defer cancel()
-
 	// Prepare command
 	var cmd *exec.Cmd
 	if interpreter != "" {
-		cmd = shell.BuildCommandContext(timeoutCtx, interpreter, nil, s.scriptPath)
+		cmd = shell.BuildCommandContext(runCtx, interpreter, nil, s.scriptPath)
 		util.GetLogger().Debug(ctx, fmt.Sprintf("Executing command: %s %s", interpreter, s.scriptPath))
 	} else {
-		cmd = shell.BuildCommandContext(timeoutCtx, s.scriptPath, nil)
+		cmd = shell.BuildCommandContext(runCtx, s.scriptPath, nil)
 		util.GetLogger().Debug(ctx, fmt.Sprintf("Executing command: %s", s.scriptPath))
 	}
 
+	scriptMode := "oneshot"
+	if s.metadata.IsSupportFeature(plugin.MetadataFeatureScriptPersistent) {
+		scriptMode = "persistent"
+	}
+
 	// Set up environment variables for script plugins
 	envVars := []string{
 		"WOX_DIRECTORY_USER_SCRIPT_PLUGINS=" + util.GetLocation().GetUserScriptPluginsDirectory(),
@@ -366,53 +540,22 @@ func (s *ScriptPlugin) executeScriptRaw(ctx context.Context, request map[string]
 		"WOX_DIRECTORY_THEMES=" + util.GetLocation().GetThemeDirectory(),
 		"WOX_PLUGIN_ID=" + s.metadata.Id,
 		"WOX_PLUGIN_NAME=" + s.metadata.GetName(ctx),
+		"WOX_SCRIPT_PROTOCOL_VERSION=" + scriptProtocolVersion,
+		"WOX_SCRIPT_MODE=" + scriptMode,
 		"PYTHONIOENCODING=utf-8",
 	}
 
 	// Add plugin settings as environment variables
 	// Settings are prefixed with WOX_SETTING_ to avoid conflicts
-	if s.api != nil {
-		// Iterate through setting definitions to get all setting values
-		for _, settingDef := range s.metadata.SettingDefinitions {
-			if settingDef.Value != nil {
-				key := settingDef.Value.GetKey()
-				value := s.api.GetSetting(ctx, key)
-
-				// Convert setting key to uppercase and replace special characters for env var name
-				// e.g., "api_key" -> "WOX_SETTING_API_KEY"
-				envKey := "WOX_SETTING_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
-				envVars = append(envVars, envKey+"="+value)
-			}
-		}
+	for key, value := range s.getSettingValues(ctx) {
+		// Convert setting key to uppercase and replace special characters for env var name
+		// e.g., "api_key" -> "WOX_SETTING_API_KEY"
+		envKey := "WOX_SETTING_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+		envVars = append(envVars, envKey+"="+value)
 	}
 
 	cmd.Env = append(os.Environ(), envVars...)
-
-	// Set up stdin with the JSON-RPC request
-	cmd.Stdin = strings.NewReader(string(requestJSON))
-
-	// Execute script
-	output, err := cmd.Output()
-	if err != nil {
-		if exitError, ok := err.(*exec.ExitError); ok {
-			return nil, fmt.Errorf("script execution failed: %s, stderr: %s", exitError.Error(), string(exitError.Stderr))
-		}
-
-		return nil, fmt.Errorf("script execution failed: %w", err)
-	}
-
-	// Parse JSON-RPC response
-	var response map[string]interface{}
-	if err := json.Unmarshal(output, &response); err != nil {
-		return nil, fmt.Errorf("failed to parse script response: %w", err)
-	}
-
-	// Check for JSON-RPC error
-	if errorData, exists := response["error"]; exists {
-		return nil, fmt.Errorf("script returned error: %v", errorData)
-	}
-
-	return response, nil
+	return cmd, nil
 }
 
 // executeScriptAction executes the script for action requests
@@ -971,3 +1114,169 @@ func parseScriptTails(ctx context.Context, metadata plugin.Metadata, itemMap map
 
 	return tails
 }
+
+func getFirstBoolFromMap(m map[string]interface{}, keys []string) bool {
+	for _, key := range keys {
+		switch value := m[key].(type) {
+		case bool:
+			return value
+		case string:
+			return strings.EqualFold(value, "true")
+		}
+	}
+	return false
+}
+
+func getFirstStringSliceFromMap(m map[string]interface{}, keys []string) []string {
+	for _, key := range keys {
+		switch value := m[key].(type) {
+		case string:
+			return []string{value}
+		case []interface{}:
+			result := make([]string, 0, len(value))
+			for _, item := range value {
+				if str, ok := item.(string); ok {
+					result = append(result, str)
+				}
+			}
+			return result
+		}
+	}
+	return nil
+}
+
+func parseScriptImage(ctx context.Context, metadata plugin.Metadata, imageStr string) (common.WoxImage, bool) {
+	img, err := common.ParseWoxImage(imageStr)
+	if err != nil {
+		util.GetLogger().Warn(ctx, fmt.Sprintf("script plugin %s returned invalid image: %s, err: %s", metadata.GetName(ctx), imageStr, err.Error()))
+		return common.WoxImage{}, false
+	}
+	if img.ImageType == common.WoxImageTypeBase64 && !strings.Contains(img.ImageData, ",") {
+		img.ImageData = fmt.Sprintf("data:image/png;base64,%s", img.ImageData)
+	}
+	return img, true
+}
+
+// parseScriptForm reads the form of a form action. Form items use the same
+// Type/Value shape as SettingDefinitions in the script header.
+func parseScriptForm(actionMap map[string]interface{}) (definition.PluginSettingDefinitions, error) {
+	formData := getSliceFromMap(actionMap, "form")
+	if formData == nil {
+		formData = getSliceFromMap(actionMap, "Form")
+	}
+	if len(formData) == 0 {
+		return nil, fmt.Errorf("form action must have a non-empty form array")
+	}
+
+	formJSON, err := json.Marshal(formData)
+	if err != nil {
+		return nil, err
+	}
+	var form definition.PluginSettingDefinitions
+	if err := json.Unmarshal(formJSON, &form); err != nil {
+		return nil, err
+	}
+	return form, nil
+}
+
+func parseScriptRefinements(ctx context.Context, metadata plugin.Metadata, resultMap map[string]interface{}) []plugin.QueryRefinement {
+	refinementsArray := getSliceFromMap(resultMap, "refinements")
+	if refinementsArray == nil {
+		refinementsArray = getSliceFromMap(resultMap, "Refinements")
+	}
+
+	var refinements []plugin.QueryRefinement
+	for _, item := range refinementsArray {
+		refinementMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		refinementId := getFirstStringFromMap(refinementMap, []string{"id", "Id"})
+		if refinementId == "" {
+			util.GetLogger().Warn(ctx, fmt.Sprintf("script plugin %s returned refinement without id", metadata.GetName(ctx)))
+			continue
+		}
+
+		refinement := plugin.QueryRefinement{
+			Id:           refinementId,
+			Title:        getFirstStringFromMap(refinementMap, []string{"title", "Title"}),
+			Type:         getFirstStringFromMap(refinementMap, []string{"type", "Type"}),
+			DefaultValue: getFirstStringSliceFromMap(refinementMap, []string{"defaultValue", "default_value", "DefaultValue"}),
+			Hotkey:       getFirstStringFromMap(refinementMap, []string{"hotkey", "Hotkey"}),
+			Persist:      getFirstBoolFromMap(refinementMap, []string{"persist", "Persist"}),
+		}
+		if refinement.Type == "" {
+			refinement.Type = plugin.QueryRefinementTypeSingleSelect
+		}
+
+		optionsArray := getSliceFromMap(refinementMap, "options")
+		if optionsArray == nil {
+			optionsArray = getSliceFromMap(refinementMap, "Options")
+		}
+		for _, optionItem := range optionsArray {
+			optionMap, ok := optionItem.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			option := plugin.QueryRefinementOption{
+				Value:    getFirstStringFromMap(optionMap, []string{"value", "Value"}),
+				Title:    getFirstStringFromMap(optionMap, []string{"title", "Title"}),
+				Keywords: getFirstStringSliceFromMap(optionMap, []string{"keywords", "Keywords"}),
+			}
+			if iconStr := getFirstStringFromMap(optionMap, []string{"icon", "Icon"}); iconStr != "" {
+				if img, ok := parseScriptImage(ctx, metadata, iconStr); ok {
+					option.Icon = img
+				}
+			}
+			if count := getFirstFloatPtrFromMap(optionMap, []string{"count", "Count"}); count != nil {
+				countValue := int(*count)
+				option.Count = &countValue
+			}
+			refinement.Options = append(refinement.Options, option)
+		}
+
+		refinements = append(refinements, refinement)
+	}
+
+	return refinements
+}
+
+func parseScriptLayout(ctx context.Context, metadata plugin.Metadata, resultMap map[string]interface{}) plugin.QueryLayout {
+	layoutMap := getMapFromMap(resultMap, "layout")
+	if layoutMap == nil {
+		layoutMap = getMapFromMap(resultMap, "Layout")
+	}
+	if layoutMap == nil {
+		return plugin.QueryLayout{}
+	}
+
+	layout := plugin.QueryLayout{
+		ResultPreviewWidthRatio: getFirstFloatPtrFromMap(layoutMap, []string{"resultPreviewWidthRatio", "result_preview_width_ratio", "ResultPreviewWidthRatio"}),
+	}
+	if iconStr := getFirstStringFromMap(layoutMap, []string{"icon", "Icon"}); iconStr != "" {
+		if img, ok := parseScriptImage(ctx, metadata, iconStr); ok {
+			layout.Icon = &img
+		}
+	}
+
+	gridMap := getMapFromMap(layoutMap, "gridLayout")
+	if gridMap == nil {
+		gridMap = getMapFromMap(layoutMap, "grid_layout")
+	}
+	if gridMap == nil {
+		gridMap = getMapFromMap(layoutMap, "GridLayout")
+	}
+	if gridMap != nil {
+		// encoding/json matches field names case-insensitively, so both
+		// columns and Columns decode into the Go struct.
+		var gridLayout plugin.MetadataFeatureParamsGridLayout
+		gridJSON, _ := json.Marshal(gridMap)
+		if err := json.Unmarshal(gridJSON, &gridLayout); err != nil {
+			util.GetLogger().Warn(ctx, fmt.Sprintf("script plugin %s returned invalid grid layout: %s", metadata.GetName(ctx), err.Error()))
+		} else {
+			layout.GridLayout = &gridLayout
+		}
+	}
+
+	return layout
+}
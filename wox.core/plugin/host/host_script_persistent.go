@@ -0,0 +1,274 @@
+package host
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"wox/util"
+)
+
+// scriptProtocolVersion is exported to scripts as WOX_SCRIPT_PROTOCOL_VERSION,
+// so one script can support older Wox versions by checking it.
+const scriptProtocolVersion = "2"
+
+const scriptExecutionTimeout = 10 * time.Second
+
+// scriptStopGracePeriod is how long a persistent script gets to exit on its own
+// after stdin is closed before it is killed.
+const scriptStopGracePeriod = 2 * time.Second
+
+// persistent scripts may return base64 images, so allow much longer lines than
+// the bufio.Scanner default of 64KB
+const scriptMaxResponseLineSize = 32 * 1024 * 1024
+
+// scriptProcess is a long-lived script plugin process, enabled by the
+// scriptPersistent feature. Requests are written to stdin one JSON object per
+// line and responses are read from stdout one per line, matched by id, so
+// slow queries don't block newer ones. The process is started on the first
+// request and restarted on the next request after it exits.
+type scriptProcess struct {
+	plugin *ScriptPlugin
+
+	mu     sync.Mutex
+	run    *scriptProcessRun
+	nextId uint64
+
+	// writeMu is separate from mu so a script that is slow to read stdin can't
+	// block the reader goroutine from delivering responses.
+	writeMu sync.Mutex
+}
+
+type scriptProcessRun struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	cancel  context.CancelFunc
+	pending map[string]chan map[string]interface{} // guarded by scriptProcess.mu
+	done    chan struct{}
+	exitErr error // set before done is closed
+}
+
+func (s *ScriptPlugin) getPersistentProcess() *scriptProcess {
+	s.persistentMu.Lock()
+	defer s.persistentMu.Unlock()
+
+	if s.persistent == nil {
+		s.persistent = &scriptProcess{plugin: s}
+	}
+	return s.persistent
+}
+
+func (s *ScriptPlugin) stopPersistentProcess(ctx context.Context) {
+	s.persistentMu.Lock()
+	process := s.persistent
+	s.persistentMu.Unlock()
+
+	if process != nil {
+		process.stop(ctx)
+	}
+}
+
+func (p *scriptProcess) call(ctx context.Context, request map[string]interface{}) (map[string]interface{}, error) {
+	run, err := p.ensureRunning(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := make(chan map[string]interface{}, 1)
+	p.mu.Lock()
+	p.nextId++
+	requestId := strconv.FormatUint(p.nextId, 10)
+	run.pending[requestId] = reply
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		delete(run.pending, requestId)
+		p.mu.Unlock()
+	}()
+
+	// The trace id used by one-shot requests is not unique across concurrent
+	// requests, a per-process counter is.
+	processRequest := maps.Clone(request)
+	processRequest["id"] = requestId
+	requestJSON, err := json.Marshal(processRequest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Writes are serialized so two requests never interleave on one line.
+	p.writeMu.Lock()
+	_, writeErr := run.stdin.Write(append(requestJSON, '\n'))
+	p.writeMu.Unlock()
+	if writeErr != nil {
+		return nil, fmt.Errorf("failed to write request to script process: %w", writeErr)
+	}
+
+	timer := time.NewTimer(scriptExecutionTimeout)
+	defer timer.Stop()
+
+	var response map[string]interface{}
+	select {
+	case response = <-reply:
+	case <-run.done:
+		// The response may have arrived right before the process exited.
+		select {
+		case response = <-reply:
+		default:
+			return nil, fmt.Errorf("script execution failed: process exited: %v", run.exitErr)
+		}
+	case <-timer.C:
+		return nil, fmt.Errorf("script execution failed: no response within %s", scriptExecutionTimeout)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if errorData, exists := response["error"]; exists {
+		return nil, fmt.Errorf("script returned error: %v", errorData)
+	}
+	return response, nil
+}
+
+func (p *scriptProcess) ensureRunning(ctx context.Context) (*scriptProcessRun, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.run != nil {
+		select {
+		case <-p.run.done:
+			util.GetLogger().Warn(ctx, fmt.Sprintf("script plugin %s process exited (%v), restarting", p.plugin.metadata.GetName(ctx), p.run.exitErr))
+			p.run = nil
+		default:
+			return p.run, nil
+		}
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	cmd, err := p.plugin.buildScriptCommand(ctx, runCtx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to open script stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to open script stdout: %w", err)
+	}
+	cmd.Stderr = &scriptStderrWriter{plugin: p.plugin}
+
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, fmt.Errorf("script execution failed: %w", err)
+	}
+	util.GetLogger().Info(ctx, fmt.Sprintf("started persistent script plugin %s, pid: %d", p.plugin.metadata.GetName(ctx), cmd.Process.Pid))
+
+	run := &scriptProcessRun{
+		cmd:     cmd,
+		stdin:   stdin,
+		cancel:  cancel,
+		pending: map[string]chan map[string]interface{}{},
+		done:    make(chan struct{}),
+	}
+	util.Go(ctx, "read persistent script plugin responses", func() {
+		p.readResponses(run, stdout)
+	})
+
+	p.run = run
+	return run, nil
+}
+
+func (p *scriptProcess) readResponses(run *scriptProcessRun, stdout io.Reader) {
+	ctx := util.NewTraceContext()
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), scriptMaxResponseLineSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var message map[string]interface{}
+		if err := json.Unmarshal(line, &message); err != nil {
+			util.GetLogger().Warn(ctx, fmt.Sprintf("script plugin %s wrote non JSON-RPC output to stdout: %s", p.plugin.metadata.GetName(ctx), string(line)))
+			continue
+		}
+
+		requestId := getStringFromMap(message, "id")
+		p.mu.Lock()
+		reply, ok := run.pending[requestId]
+		p.mu.Unlock()
+		if !ok {
+			// late response of a request that already timed out
+			util.GetLogger().Debug(ctx, fmt.Sprintf("script plugin %s returned response for unknown request id: %s", p.plugin.metadata.GetName(ctx), requestId))
+			continue
+		}
+		select {
+		case reply <- message:
+		default:
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		util.GetLogger().Error(ctx, fmt.Sprintf("failed to read script plugin %s output: %s", p.plugin.metadata.GetName(ctx), err.Error()))
+	}
+
+	run.cancel()
+	run.exitErr = run.cmd.Wait()
+	close(run.done)
+}
+
+// stop closes stdin so the script can leave its read loop, and kills it if it
+// doesn't exit within scriptStopGracePeriod.
+func (p *scriptProcess) stop(ctx context.Context) {
+	p.mu.Lock()
+	run := p.run
+	p.run = nil
+	p.mu.Unlock()
+
+	if run == nil {
+		return
+	}
+
+	run.stdin.Close()
+	select {
+	case <-run.done:
+	case <-time.After(scriptStopGracePeriod):
+		run.cancel()
+		<-run.done
+	}
+	util.GetLogger().Info(ctx, fmt.Sprintf("stopped persistent script plugin %s", p.plugin.metadata.GetName(ctx)))
+}
+
+// scriptStderrWriter forwards stderr of persistent scripts to the Wox log, one
+// script process can live for hours so it can't be collected like one-shot runs.
+type scriptStderrWriter struct {
+	plugin *ScriptPlugin
+}
+
+func (w *scriptStderrWriter) Write(data []byte) (int, error) {
+	ctx := util.NewTraceContext()
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\r\n"), "\n") {
+		if strings.TrimSpace(line) != "" {
+			util.GetLogger().Warn(ctx, fmt.Sprintf("script plugin %s stderr: %s", w.plugin.metadata.GetName(ctx), line))
+		}
+	}
+	return len(data), nil
+}
+
+// stopPersistentPlugin is used by the script host when a plugin is unloaded.
+func (s *ScriptHost) stopPersistentPlugin(ctx context.Context, pluginId string) {
+	if value, ok := s.persistentPlugins.LoadAndDelete(pluginId); ok {
+		value.(*ScriptPlugin).stopPersistentProcess(ctx)
+	}
+}
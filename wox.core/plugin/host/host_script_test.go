@@ -0,0 +1,89 @@
+package host
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"wox/plugin"
+	"wox/setting/definition"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScriptQueryResponseV2(t *testing.T) {
+	ctx := context.Background()
+	raw := `{
+		"items": [],
+		"refinements": [
+			{"id": "sort", "title": "Sort", "type": "sort", "defaultValue": "name", "persist": true,
+			 "options": [{"value": "name", "title": "Name", "icon": "emoji:🔤", "count": 3}, {"value": "date", "title": "Date"}]},
+			{"id": "tags", "type": "multiSelect", "default_value": ["a", "b"]},
+			{"title": "missing id"}
+		],
+		"layout": {"resultPreviewWidthRatio": 0, "icon": "emoji:📁", "gridLayout": {"columns": 4, "showTitle": true}}
+	}`
+	var resultMap map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(raw), &resultMap))
+
+	refinements := parseScriptRefinements(ctx, plugin.Metadata{}, resultMap)
+	require.Len(t, refinements, 2)
+	assert.Equal(t, plugin.QueryRefinementTypeSort, refinements[0].Type)
+	assert.Equal(t, []string{"name"}, refinements[0].DefaultValue)
+	assert.True(t, refinements[0].Persist)
+	require.Len(t, refinements[0].Options, 2)
+	require.NotNil(t, refinements[0].Options[0].Count)
+	assert.Equal(t, 3, *refinements[0].Options[0].Count)
+	assert.Nil(t, refinements[0].Options[1].Count)
+	assert.Equal(t, []string{"a", "b"}, refinements[1].DefaultValue)
+
+	layout := parseScriptLayout(ctx, plugin.Metadata{}, resultMap)
+	require.NotNil(t, layout.ResultPreviewWidthRatio, "an explicit zero ratio must be kept")
+	assert.Equal(t, float64(0), *layout.ResultPreviewWidthRatio)
+	require.NotNil(t, layout.Icon)
+	require.NotNil(t, layout.GridLayout)
+	assert.Equal(t, 4, layout.GridLayout.Columns)
+	assert.True(t, layout.GridLayout.ShowTitle)
+
+	assert.Equal(t, plugin.QueryLayout{}, parseScriptLayout(ctx, plugin.Metadata{}, map[string]interface{}{}))
+}
+
+func TestParseScriptForm(t *testing.T) {
+	var actionMap map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"id": "rename",
+		"type": "form",
+		"form": [{"Type": "textbox", "Value": {"Key": "name", "Label": "Name"}}]
+	}`), &actionMap))
+
+	form, err := parseScriptForm(actionMap)
+	require.NoError(t, err)
+	require.Len(t, form, 1)
+	assert.Equal(t, definition.PluginSettingDefinitionTypeTextBox, form[0].Type)
+	assert.Equal(t, "name", form[0].Value.GetKey())
+
+	_, err = parseScriptForm(map[string]interface{}{"type": "form"})
+	assert.Error(t, err)
+}
+
+func TestBuildScriptQueryParamsKeepsV1Fields(t *testing.T) {
+	scriptPlugin := NewScriptPlugin(plugin.Metadata{}, "")
+	params := scriptPlugin.buildQueryParams(context.Background(), plugin.Query{
+		RawQuery:       "calc 1+1",
+		TriggerKeyword: "calc",
+		Search:         "1+1",
+		Refinements:    map[string]string{"mode": "exact"},
+	})
+
+	assert.Equal(t, "1+1", params["search"])
+	assert.Equal(t, "calc", params["trigger_keyword"])
+	assert.Equal(t, "calc 1+1", params["raw_query"])
+	assert.Equal(t, map[string]string{"mode": "exact"}, params["refinements"])
+
+	// v2 fields are always present so scripts don't need to guard against null
+	paramsJSON, err := json.Marshal(params)
+	require.NoError(t, err)
+	assert.Contains(t, string(paramsJSON), `"context_data":{}`)
+	assert.Contains(t, string(paramsJSON), `"file_paths":[]`)
+	assert.Contains(t, string(paramsJSON), `"settings":{}`)
+}
@@ -325,6 +325,14 @@ func (w *WebsocketHost) onMessage(data string) {
 	}
 }
 
+// websocketMethodPermissions maps plugin API methods to the permission they need.
+var websocketMethodPermissions = map[string]plugin.MetadataPermissionName{
+	"Copy":           plugin.MetadataPermissionClipboardWrite,
+	"Screenshot":     plugin.MetadataPermissionScreenshot,
+	"AIChatStream":   plugin.MetadataPermissionAI,
+	"RegisterHotkey": plugin.MetadataPermissionGlobalHotkey,
+}
+
 func (w *WebsocketHost) handleRequestFromPlugin(ctx context.Context, request JsonRpcRequest) {
 	if request.Method != "Log" {
 		util.GetLogger().Info(ctx, fmt.Sprintf("got request from plugin <%s>, method: %s", request.PluginName, request.Method))
@@ -342,6 +350,15 @@ func (w *WebsocketHost) handleRequestFromPlugin(ctx context.Context, request Jso
 		return
 	}
 
+	// Reject before decoding params, so a denied call never touches the API and the
+	// plugin gets an error instead of a silent no-op.
+	if permission, guarded := websocketMethodPermissions[request.Method]; guarded {
+		if err := pluginInstance.CheckPermission(ctx, permission); err != nil {
+			w.sendResponseErrToHost(ctx, request, err)
+			return
+		}
+	}
+
 	switch request.Method {
 	case "HideApp":
 		pluginInstance.API.HideApp(ctx)
@@ -712,6 +729,37 @@ func (w *WebsocketHost) handleRequestFromPlugin(ctx context.Context, request Jso
 			return &queryResult, nil
 		})
 		w.sendResponseToHost(ctx, request, "")
+	case "RegisterHotkey":
+		callbackId, exist := request.Params["callbackId"]
+		if !exist {
+			util.GetLogger().Error(ctx, fmt.Sprintf("[%s] RegisterHotkey method must have a callbackId parameter", request.PluginName))
+			return
+		}
+
+		var hotkey plugin.PluginHotkey
+		if err := json.Unmarshal([]byte(request.Params["hotkey"]), &hotkey); err != nil {
+			util.GetLogger().Error(ctx, fmt.Sprintf("[%s] failed to unmarshal hotkey: %s", request.PluginName, err))
+			w.sendResponseErrToHost(ctx, request, fmt.Errorf("failed to unmarshal hotkey: %w", err))
+			return
+		}
+
+		metadata := pluginInstance.Metadata
+		hotkey.Callback = func(callbackCtx context.Context) {
+			w.invokeMethod(callbackCtx, metadata, "onHotkey", map[string]string{
+				"CallbackId": callbackId,
+			})
+		}
+		result := pluginInstance.API.RegisterHotkey(ctx, hotkey)
+		w.sendResponseToHost(ctx, request, result)
+	case "UnregisterHotkey":
+		id, exist := request.Params["id"]
+		if !exist {
+			util.GetLogger().Error(ctx, fmt.Sprintf("[%s] UnregisterHotkey method must have an id parameter", request.PluginName))
+			return
+		}
+
+		pluginInstance.API.UnregisterHotkey(ctx, id)
+		w.sendResponseToHost(ctx, request, "")
 	case "RegisterQueryCommands":
 		var commands []plugin.MetadataCommand
 		unmarshalErr := json.Unmarshal([]byte(request.Params["commands"]), &commands)
@@ -0,0 +1,322 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	corehotkey "wox/hotkey"
+	"wox/setting/definition"
+	"wox/util"
+)
+
+// PluginHotkeySettingKey stores the user's rebinds of plugin-registered hotkeys.
+// Hotkeys differ between platforms, so the value is kept per platform.
+const PluginHotkeySettingKey = "WoxPluginHotkeys"
+
+// PluginHotkey is a global hotkey owned by a plugin. Hotkey is the default
+// binding, users can rebind or disable it in the plugin settings.
+type PluginHotkey struct {
+	Id       string
+	Name     string
+	Hotkey   string
+	Callback func(ctx context.Context) `json:"-"`
+}
+
+// RegisterHotkeyResult reports the binding that is active after user rebinds.
+// Hotkey is empty when the user disabled it. A conflicting hotkey stays listed
+// in plugin settings with Success false, so the user can pick another one.
+type RegisterHotkeyResult struct {
+	Success bool
+	Hotkey  string
+	ErrMsg  string
+}
+
+// pluginHotkeyOverride is one row of the plugin hotkey settings table. Only rows
+// that differ from the plugin's default are persisted.
+type pluginHotkeyOverride struct {
+	Id       string
+	Name     string
+	Hotkey   string
+	Disabled bool
+}
+
+// PluginHotkeyRegistrar lets plugin hotkeys reach the hotkey service without
+// importing ui, which owns the service. The UI Manager registers itself via
+// SetPluginHotkeyRegistrar.
+type PluginHotkeyRegistrar interface {
+	UpdatePluginHotkeys(ctx context.Context, pluginId string, bindings []corehotkey.PluginBinding) (map[string]error, error)
+}
+
+var pluginHotkeyRegistrar PluginHotkeyRegistrar
+
+func SetPluginHotkeyRegistrar(r PluginHotkeyRegistrar) {
+	pluginHotkeyRegistrar = r
+}
+
+func (m *Manager) registerPluginHotkey(ctx context.Context, pluginInstance *Instance, hotkey PluginHotkey) RegisterHotkeyResult {
+	hotkey.Id = strings.TrimSpace(hotkey.Id)
+	hotkey.Hotkey = strings.TrimSpace(hotkey.Hotkey)
+	if hotkey.Id == "" {
+		return RegisterHotkeyResult{ErrMsg: "hotkey id cannot be empty"}
+	}
+	if hotkey.Callback == nil {
+		return RegisterHotkeyResult{ErrMsg: "hotkey callback cannot be empty"}
+	}
+	if strings.TrimSpace(hotkey.Name) == "" {
+		hotkey.Name = hotkey.Id
+	}
+
+	m.pluginHotkeysMu.Lock()
+	defer m.pluginHotkeysMu.Unlock()
+
+	previous := pluginInstance.RuntimeHotkeys
+	runtimeHotkeys := make([]PluginHotkey, 0, len(previous)+1)
+	for _, existing := range previous {
+		if existing.Id != hotkey.Id {
+			runtimeHotkeys = append(runtimeHotkeys, existing)
+		}
+	}
+	pluginInstance.RuntimeHotkeys = append(runtimeHotkeys, hotkey)
+
+	rejected, err := m.syncPluginHotkeysLocked(ctx, pluginInstance)
+	if err != nil {
+		pluginInstance.RuntimeHotkeys = previous
+		return RegisterHotkeyResult{ErrMsg: err.Error()}
+	}
+
+	effective, enabled := m.effectivePluginHotkey(ctx, pluginInstance, hotkey)
+	if !enabled {
+		return RegisterHotkeyResult{Success: true}
+	}
+	if rejectErr := rejected[hotkey.Id]; rejectErr != nil {
+		logger.Warn(ctx, fmt.Sprintf("plugin %s hotkey %s is not bound: %s", pluginInstance.Metadata.GetName(ctx), hotkey.Id, rejectErr))
+		return RegisterHotkeyResult{Hotkey: effective, ErrMsg: rejectErr.Error()}
+	}
+	return RegisterHotkeyResult{Success: true, Hotkey: effective}
+}
+
+func (m *Manager) unregisterPluginHotkey(ctx context.Context, pluginInstance *Instance, id string) {
+	m.pluginHotkeysMu.Lock()
+	defer m.pluginHotkeysMu.Unlock()
+
+	runtimeHotkeys := make([]PluginHotkey, 0, len(pluginInstance.RuntimeHotkeys))
+	for _, existing := range pluginInstance.RuntimeHotkeys {
+		if existing.Id != id {
+			runtimeHotkeys = append(runtimeHotkeys, existing)
+		}
+	}
+	if len(runtimeHotkeys) == len(pluginInstance.RuntimeHotkeys) {
+		return
+	}
+	pluginInstance.RuntimeHotkeys = runtimeHotkeys
+
+	if _, err := m.syncPluginHotkeysLocked(ctx, pluginInstance); err != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to unregister plugin %s hotkey %s: %s", pluginInstance.Metadata.GetName(ctx), id, err))
+	}
+}
+
+// releasePluginHotkeys unbinds every hotkey of a plugin when it is deactivated.
+func (m *Manager) releasePluginHotkeys(ctx context.Context, pluginInstance *Instance) {
+	m.pluginHotkeysMu.Lock()
+	defer m.pluginHotkeysMu.Unlock()
+
+	if len(pluginInstance.RuntimeHotkeys) == 0 {
+		return
+	}
+	pluginInstance.RuntimeHotkeys = nil
+	if _, err := m.syncPluginHotkeysLocked(ctx, pluginInstance); err != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to release plugin %s hotkeys: %s", pluginInstance.Metadata.GetName(ctx), err))
+	}
+}
+
+// syncPluginHotkeysLocked publishes the enabled hotkeys of a plugin, with user
+// rebinds applied, to the hotkey service.
+func (m *Manager) syncPluginHotkeysLocked(ctx context.Context, pluginInstance *Instance) (map[string]error, error) {
+	if pluginHotkeyRegistrar == nil {
+		return nil, nil
+	}
+
+	bindings := make([]corehotkey.PluginBinding, 0, len(pluginInstance.RuntimeHotkeys))
+	for _, hotkey := range pluginInstance.RuntimeHotkeys {
+		effective, enabled := m.effectivePluginHotkey(ctx, pluginInstance, hotkey)
+		if !enabled || effective == "" {
+			continue
+		}
+		bindings = append(bindings, corehotkey.PluginBinding{HotkeyID: hotkey.Id, Name: hotkey.Name, Hotkey: effective})
+	}
+	return pluginHotkeyRegistrar.UpdatePluginHotkeys(ctx, pluginInstance.Metadata.Id, bindings)
+}
+
+func (m *Manager) effectivePluginHotkey(ctx context.Context, pluginInstance *Instance, hotkey PluginHotkey) (string, bool) {
+	for _, override := range m.loadPluginHotkeyOverrides(ctx, pluginInstance) {
+		if override.Id == hotkey.Id {
+			return strings.TrimSpace(override.Hotkey), !override.Disabled
+		}
+	}
+	return hotkey.Hotkey, true
+}
+
+func (m *Manager) loadPluginHotkeyOverrides(ctx context.Context, pluginInstance *Instance) []pluginHotkeyOverride {
+	if pluginInstance.Setting == nil {
+		return nil
+	}
+	value, exist := pluginInstance.Setting.Get(pluginHotkeySettingPlatformKey())
+	if !exist || strings.TrimSpace(value) == "" {
+		return nil
+	}
+
+	var overrides []pluginHotkeyOverride
+	if err := json.Unmarshal([]byte(value), &overrides); err != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to parse plugin %s hotkey settings: %s", pluginInstance.Metadata.GetName(ctx), err))
+		return nil
+	}
+	return overrides
+}
+
+// TriggerPluginHotkey runs the callback of a plugin hotkey that was pressed.
+func (m *Manager) TriggerPluginHotkey(ctx context.Context, pluginId string, hotkeyId string) {
+	pluginInstance := m.GetPluginInstanceById(pluginId)
+	if pluginInstance == nil {
+		logger.Warn(ctx, fmt.Sprintf("plugin hotkey triggered for missing plugin: %s", pluginId))
+		return
+	}
+
+	m.pluginHotkeysMu.Lock()
+	var callback func(ctx context.Context)
+	for _, hotkey := range pluginInstance.RuntimeHotkeys {
+		if hotkey.Id == hotkeyId {
+			callback = hotkey.Callback
+			break
+		}
+	}
+	m.pluginHotkeysMu.Unlock()
+
+	if callback == nil {
+		logger.Warn(ctx, fmt.Sprintf("plugin %s has no hotkey %s", pluginInstance.Metadata.GetName(ctx), hotkeyId))
+		return
+	}
+	util.Go(ctx, "plugin hotkey callback", func() {
+		callback(ctx)
+	})
+}
+
+// UpdatePluginHotkeySetting saves the plugin hotkey table edited in plugin
+// settings and rebinds the plugin's hotkeys. Rebinds of hotkeys the plugin
+// hasn't registered in this session are kept.
+func (m *Manager) UpdatePluginHotkeySetting(ctx context.Context, pluginInstance *Instance, value string) error {
+	var rows []pluginHotkeyOverride
+	if strings.TrimSpace(value) != "" {
+		if err := json.Unmarshal([]byte(value), &rows); err != nil {
+			return fmt.Errorf("invalid plugin hotkey settings: %w", err)
+		}
+	}
+
+	m.pluginHotkeysMu.Lock()
+	defer m.pluginHotkeysMu.Unlock()
+
+	defaults := map[string]PluginHotkey{}
+	for _, hotkey := range pluginInstance.RuntimeHotkeys {
+		defaults[hotkey.Id] = hotkey
+	}
+
+	overrides := []pluginHotkeyOverride{}
+	for _, override := range m.loadPluginHotkeyOverrides(ctx, pluginInstance) {
+		if _, registered := defaults[override.Id]; !registered {
+			overrides = append(overrides, override)
+		}
+	}
+	for _, row := range rows {
+		hotkey, registered := defaults[row.Id]
+		if !registered {
+			continue
+		}
+		row.Hotkey = strings.TrimSpace(row.Hotkey)
+		if !row.Disabled && row.Hotkey == hotkey.Hotkey {
+			continue
+		}
+		row.Name = hotkey.Name
+		overrides = append(overrides, row)
+	}
+
+	data, err := json.Marshal(overrides)
+	if err != nil {
+		return err
+	}
+	if err := pluginInstance.Setting.Set(pluginHotkeySettingPlatformKey(), string(data)); err != nil {
+		return fmt.Errorf("failed to persist plugin hotkey settings: %w", err)
+	}
+
+	rejected, err := m.syncPluginHotkeysLocked(ctx, pluginInstance)
+	if err != nil {
+		return err
+	}
+	for hotkeyId, rejectErr := range rejected {
+		logger.Warn(ctx, fmt.Sprintf("plugin %s hotkey %s is not bound: %s", pluginInstance.Metadata.GetName(ctx), hotkeyId, rejectErr))
+	}
+	return nil
+}
+
+// GetPluginHotkeySetting returns the settings table for the hotkeys a plugin has
+// registered, and its value with user rebinds applied. ok is false when the
+// plugin has no hotkeys.
+func (m *Manager) GetPluginHotkeySetting(ctx context.Context, pluginInstance *Instance) (item definition.PluginSettingDefinitionItem, value string, ok bool) {
+	m.pluginHotkeysMu.Lock()
+	defer m.pluginHotkeysMu.Unlock()
+
+	if len(pluginInstance.RuntimeHotkeys) == 0 {
+		return definition.PluginSettingDefinitionItem{}, "", false
+	}
+
+	rows := make([]pluginHotkeyOverride, 0, len(pluginInstance.RuntimeHotkeys))
+	for _, hotkey := range pluginInstance.RuntimeHotkeys {
+		effective, enabled := m.effectivePluginHotkey(ctx, pluginInstance, hotkey)
+		rows = append(rows, pluginHotkeyOverride{Id: hotkey.Id, Name: hotkey.Name, Hotkey: effective, Disabled: !enabled})
+	}
+	data, err := json.Marshal(rows)
+	if err != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to marshal plugin %s hotkey settings: %s", pluginInstance.Metadata.GetName(ctx), err))
+		return definition.PluginSettingDefinitionItem{}, "", false
+	}
+
+	return definition.PluginSettingDefinitionItem{
+		Type: definition.PluginSettingDefinitionTypeTable,
+		Value: &definition.PluginSettingValueTable{
+			Key:         PluginHotkeySettingKey,
+			Title:       "i18n:ui_plugin_hotkeys",
+			Tooltip:     "i18n:ui_plugin_hotkeys_tooltip",
+			InlineTable: true,
+			Columns: []definition.PluginSettingValueTableColumn{
+				{
+					Key:          "Id",
+					Label:        "Id",
+					Type:         definition.PluginSettingValueTableColumnTypeText,
+					HideInTable:  true,
+					HideInUpdate: true,
+				},
+				{
+					Key:          "Name",
+					Label:        "i18n:ui_plugin_hotkeys_name",
+					Type:         definition.PluginSettingValueTableColumnTypeText,
+					HideInUpdate: true,
+				},
+				{
+					Key:                "Hotkey",
+					Label:              "i18n:ui_plugin_hotkeys_hotkey",
+					Type:               definition.PluginSettingValueTableColumnTypeHotkey,
+					AllowedHotkeyKinds: []string{"normalCombo", "doubleModifier", "capsLockCombo", "sequence"},
+				},
+				{
+					Key:   "Disabled",
+					Label: "i18n:ui_plugin_hotkeys_disabled",
+					Type:  definition.PluginSettingValueTableColumnTypeCheckbox,
+					Width: 60,
+				},
+			},
+		},
+	}, string(data), true
+}
+
+func pluginHotkeySettingPlatformKey() string {
+	return PluginHotkeySettingKey + "@" + util.GetCurrentPlatform()
+}
@@ -8,18 +8,20 @@ import (
 )
 
 type Instance struct {
-	Plugin               Plugin                 // plugin implementation
-	API                  API                    // APIs exposed to plugin
-	Metadata             Metadata               // metadata parsed from plugin.json
-	IsSystemPlugin       bool                   // is system plugin, see `plugin.md` for more detail
-	RuntimeLoaded        bool                   // host runtime has loaded this plugin
-	Initialized          bool                   // plugin Init has run and runtime callbacks may be registered
-	IsDevPlugin          bool                   // plugins loaded from `local plugin directories` which defined in wpm settings
-	DevPluginDirectory   string                 // absolute path to dev plugin directory defined in wpm settings
-	PluginDirectory      string                 // absolute path to plugin directory
-	Host                 Host                   // plugin host to run this plugin
-	Setting              *setting.PluginSetting // setting for this plugin
-	RuntimeQueryCommands []MetadataCommand      // query commands registered at runtime
+	Plugin                   Plugin                 // plugin implementation
+	API                      API                    // APIs exposed to plugin
+	Metadata                 Metadata               // metadata parsed from plugin.json
+	IsSystemPlugin           bool                   // is system plugin, see `plugin.md` for more detail
+	RuntimeLoaded            bool                   // host runtime has loaded this plugin
+	Initialized              bool                   // plugin Init has run and runtime callbacks may be registered
+	IsDevPlugin              bool                   // plugins loaded from `local plugin directories` which defined in wpm settings
+	DevPluginDirectory       string                 // absolute path to dev plugin directory defined in wpm settings
+	PluginDirectory          string                 // absolute path to plugin directory
+	Host                     Host                   // plugin host to run this plugin
+	Setting                  *setting.PluginSetting // setting for this plugin
+	RuntimeQueryCommands     []MetadataCommand      // query commands registered at runtime
+	RuntimeHotkeys           []PluginHotkey         // global hotkeys registered at runtime, guarded by the manager
+	RuntimeContentExtractors []string               // file content extractors registered at runtime, guarded by the manager
 
 	DynamicSettingCallbacks   []func(ctx context.Context, key string) definition.PluginSettingDefinitionItem // dynamic setting callbacks
 	SettingChangeCallbacks    []func(ctx context.Context, key string, value string)
@@ -201,6 +201,13 @@ type Manager struct {
 	hostWatchdogDone      chan struct{}
 	hostWatchdogLifecycle sync.Mutex
 	hostWatchdogStarted   bool
+
+	// pluginHotkeysMu guards Instance.RuntimeHotkeys and serializes publishing
+	// them to the hotkey service.
+	pluginHotkeysMu sync.Mutex
+
+	// pluginContentExtractorsMu guards Instance.RuntimeContentExtractors.
+	pluginContentExtractorsMu sync.Mutex
 }
 
 const (
@@ -586,6 +593,8 @@ func (m *Manager) deactivatePlugin(ctx context.Context, pluginInstance *Instance
 			callback(ctx)
 		}
 	}
+	m.releasePluginHotkeys(ctx, pluginInstance)
+	m.releasePluginContentExtractors(ctx, pluginInstance)
 	m.clearRuntimeCallbacks(pluginInstance)
 	pluginInstance.Initialized = false
 
@@ -1157,8 +1166,10 @@ func (m *Manager) InvokePluginCommand(ctx context.Context, caller *Instance, req
 	}
 
 	callerName := ""
+	request.CallerPluginId = ""
 	if caller != nil {
 		callerName = caller.Metadata.GetName(ctx)
+		request.CallerPluginId = caller.Metadata.Id
 	}
 	logger.Info(ctx, fmt.Sprintf("invoke plugin command: caller=%s target=%s command=%s", callerName, target.Metadata.GetName(ctx), request.Command))
 
@@ -1182,7 +1193,7 @@ func (m *Manager) canOperateQuery(ctx context.Context, pluginInstance *Instance,
 			return false
 		}
 		if query.Type == QueryTypeSelection {
-			return pluginInstance.Metadata.IsSupportFeature(MetadataFeatureQuerySelection)
+			return m.canReceiveSelection(ctx, pluginInstance)
 		}
 		return query.Type == QueryTypeInput
 	}
@@ -1192,11 +1203,11 @@ func (m *Manager) canOperateQuery(ctx context.Context, pluginInstance *Instance,
 		// only route it to the plugin that owns that keyword, so users can configure
 		// a hotkey like "select " to target one specific plugin instead of all.
 		if query.TriggerKeyword != "" {
-			return lo.Contains(pluginInstance.GetTriggerKeywords(), query.TriggerKeyword)
+			return lo.Contains(pluginInstance.GetTriggerKeywords(), query.TriggerKeyword) && pluginInstance.CheckPermission(ctx, MetadataPermissionSelection) == nil
 		}
 		// No trigger keyword: fall back to old behavior - deliver to all plugins
 		// that have declared the querySelection feature.
-		return pluginInstance.Metadata.IsSupportFeature(MetadataFeatureQuerySelection)
+		return m.canReceiveSelection(ctx, pluginInstance)
 	}
 
 	var validGlobalQuery = lo.Contains(pluginInstance.GetTriggerKeywords(), "*") && query.TriggerKeyword == ""
@@ -1208,6 +1219,15 @@ func (m *Manager) canOperateQuery(ctx context.Context, pluginInstance *Instance,
 	return true
 }
 
+// canReceiveSelection keeps selected text and file paths away from plugins that
+// did not ask for selection access, even if they declare querySelection.
+func (m *Manager) canReceiveSelection(ctx context.Context, pluginInstance *Instance) bool {
+	if !pluginInstance.Metadata.IsSupportFeature(MetadataFeatureQuerySelection) {
+		return false
+	}
+	return pluginInstance.CheckPermission(ctx, MetadataPermissionSelection) == nil
+}
+
 // applyScopeForPlugin returns a per-plugin query copy with Command/TriggerKeyword from Scope.
 func (m *Manager) applyScopeForPlugin(query Query, pluginInstance *Instance) Query {
 	if !query.HasScope() || pluginInstance == nil {
@@ -59,6 +59,11 @@ const (
 	// existing plugins, but query-scoped layout is more flexible when only some result
 	// sets should use a grid.
 	MetadataFeatureGridLayout MetadataFeatureName = "gridLayout"
+
+	// enable this feature to keep a script plugin process alive between queries
+	// the script then reads line-delimited JSON-RPC requests from stdin until stdin is closed
+	// only used by script plugins, other runtimes already run in a long-lived host
+	MetadataFeatureScriptPersistent MetadataFeatureName = "scriptPersistent"
 )
 
 // Metadata parsed from plugin.json, see `Plugin.json.md` for more detail
@@ -82,6 +87,10 @@ type Metadata struct {
 	SettingDefinitions definition.PluginSettingDefinitions
 	QueryRequirements  MetadataQueryRequirements
 
+	// Permissions declares what the plugin may do through the plugin API, see
+	// MetadataPermissions. nil means the plugin predates permissions.
+	Permissions *MetadataPermissions
+
 	// I18n holds plugin-local translations.
 	// Wox central translations stay in the i18n manager so system plugins do not
 	// duplicate the same flattened language maps in every Metadata instance.
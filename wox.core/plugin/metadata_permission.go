@@ -0,0 +1,160 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"wox/i18n"
+)
+
+var ErrPermissionDenied = errors.New("permission denied")
+
+type MetadataPermissionName = string
+
+const (
+	// allow the plugin to write text or images to the system clipboard through Copy
+	MetadataPermissionClipboardWrite MetadataPermissionName = "clipboardWrite"
+
+	// allow the plugin to ask the user for a screen capture through Screenshot
+	MetadataPermissionScreenshot MetadataPermissionName = "screenshot"
+
+	// allow the plugin to chat with the user's AI models through AIChatStream,
+	// the plugin still needs the ai feature to be routed AI results
+	MetadataPermissionAI MetadataPermissionName = "ai"
+
+	// allow the plugin to receive selection queries, which contain selected text or file paths
+	MetadataPermissionSelection MetadataPermissionName = "selection"
+
+	// allow the plugin to register global hotkeys through RegisterHotkey
+	MetadataPermissionGlobalHotkey MetadataPermissionName = "globalHotkey"
+)
+
+// MetadataPermissions is the Permissions section of plugin.json, e.g.
+//
+//	"Permissions": {
+//	  "Capabilities": ["clipboardWrite", "ai"],
+//	  "NetworkDomains": ["api.example.com", "*.example.org"],
+//	  "InvokePlugins": ["<target plugin id>"]
+//	}
+//
+// Plugins without the section are legacy plugins and keep full access, so
+// plugins published before permissions existed continue to work.
+type MetadataPermissions struct {
+	Capabilities []MetadataPermissionName
+
+	// NetworkDomains lists the hosts the plugin talks to. Plugin runtimes own
+	// their sockets, so Wox can only show these to the user, not enforce them.
+	NetworkDomains []string
+
+	// InvokePlugins lists the plugin ids the plugin may send commands to.
+	InvokePlugins []string
+}
+
+func (p *MetadataPermissions) IsEmpty() bool {
+	return p == nil || (len(p.Capabilities) == 0 && len(p.NetworkDomains) == 0 && len(p.InvokePlugins) == 0)
+}
+
+// Covers reports whether every permission in other is also in p. A nil p or
+// other means legacy full access, so a nil p covers anything and a non-nil p
+// never covers a nil other.
+func (p *MetadataPermissions) Covers(other *MetadataPermissions) bool {
+	if p == nil {
+		return true
+	}
+	if other == nil {
+		return false
+	}
+	for _, capability := range other.Capabilities {
+		if !containsFold(p.Capabilities, capability) {
+			return false
+		}
+	}
+	for _, domain := range other.NetworkDomains {
+		if !containsFold(p.NetworkDomains, domain) {
+			return false
+		}
+	}
+	for _, pluginId := range other.InvokePlugins {
+		if !containsFold(p.InvokePlugins, pluginId) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *Metadata) HasPermission(name MetadataPermissionName) bool {
+	if m.Permissions == nil {
+		return true
+	}
+	return containsFold(m.Permissions.Capabilities, name)
+}
+
+func (m *Metadata) CanInvokePlugin(pluginId string) bool {
+	if m.Permissions == nil {
+		return true
+	}
+	return containsFold(m.Permissions.InvokePlugins, pluginId)
+}
+
+// CheckPermission guards plugin API calls and logs every denial. System plugins
+// are compiled into Wox and are not sandboxed.
+func (i *Instance) CheckPermission(ctx context.Context, name MetadataPermissionName) error {
+	if i == nil || i.IsSystemPlugin || i.Metadata.HasPermission(name) {
+		return nil
+	}
+
+	logger.Error(ctx, fmt.Sprintf("plugin %s(%s) denied %s: not declared in plugin.json Permissions", i.Metadata.GetName(ctx), i.Metadata.Id, name))
+	return fmt.Errorf("%w: plugin did not declare the %s permission", ErrPermissionDenied, name)
+}
+
+// DescribePermissions turns permissions into translated lines for consent
+// prompts and the plugin detail page.
+func DescribePermissions(ctx context.Context, permissions *MetadataPermissions) []string {
+	translate := func(key string) string {
+		return i18n.GetI18nManager().TranslateWox(ctx, key)
+	}
+	if permissions == nil {
+		return []string{translate("i18n:plugin_permission_legacy")}
+	}
+
+	lines := []string{}
+	for _, capability := range permissions.Capabilities {
+		switch strings.ToLower(capability) {
+		case strings.ToLower(MetadataPermissionClipboardWrite):
+			lines = append(lines, translate("i18n:plugin_permission_clipboard_write"))
+		case strings.ToLower(MetadataPermissionScreenshot):
+			lines = append(lines, translate("i18n:plugin_permission_screenshot"))
+		case strings.ToLower(MetadataPermissionAI):
+			lines = append(lines, translate("i18n:plugin_permission_ai"))
+		case strings.ToLower(MetadataPermissionSelection):
+			lines = append(lines, translate("i18n:plugin_permission_selection"))
+		case strings.ToLower(MetadataPermissionGlobalHotkey):
+			lines = append(lines, translate("i18n:plugin_permission_global_hotkey"))
+		default:
+			lines = append(lines, capability)
+		}
+	}
+	if len(permissions.NetworkDomains) > 0 {
+		lines = append(lines, fmt.Sprintf(translate("i18n:plugin_permission_network"), strings.Join(permissions.NetworkDomains, ", ")))
+	}
+	if len(permissions.InvokePlugins) > 0 {
+		targets := make([]string, 0, len(permissions.InvokePlugins))
+		for _, pluginId := range permissions.InvokePlugins {
+			if target := GetPluginManager().GetPluginInstanceById(pluginId); target != nil {
+				targets = append(targets, target.Metadata.GetName(ctx))
+				continue
+			}
+			targets = append(targets, pluginId)
+		}
+		lines = append(lines, fmt.Sprintf(translate("i18n:plugin_permission_invoke_plugins"), strings.Join(targets, ", ")))
+	}
+	return lines
+}
+
+func containsFold(values []string, value string) bool {
+	return slices.ContainsFunc(values, func(item string) bool {
+		return strings.EqualFold(strings.TrimSpace(item), strings.TrimSpace(value))
+	})
+}
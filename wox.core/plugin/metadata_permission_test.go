@@ -0,0 +1,63 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"wox/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadataPermissionsParseFromPluginJSON(t *testing.T) {
+	var metadata Metadata
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"Id": "example",
+		"Permissions": {
+			"Capabilities": ["clipboardWrite", "AI"],
+			"NetworkDomains": ["api.example.com"],
+			"InvokePlugins": ["target-plugin"]
+		}
+	}`), &metadata))
+
+	assert.True(t, metadata.HasPermission(MetadataPermissionClipboardWrite))
+	assert.True(t, metadata.HasPermission(MetadataPermissionAI), "capability names are case insensitive")
+	assert.False(t, metadata.HasPermission(MetadataPermissionScreenshot))
+	assert.True(t, metadata.CanInvokePlugin("target-plugin"))
+	assert.False(t, metadata.CanInvokePlugin("other-plugin"))
+
+	legacy := Metadata{Id: "legacy"}
+	assert.True(t, legacy.HasPermission(MetadataPermissionScreenshot), "plugins without Permissions keep full access")
+	assert.True(t, legacy.CanInvokePlugin("other-plugin"))
+}
+
+func TestMetadataPermissionsCovers(t *testing.T) {
+	listed := &MetadataPermissions{Capabilities: []string{"clipboardWrite", "ai"}, NetworkDomains: []string{"api.example.com"}}
+
+	assert.True(t, listed.Covers(&MetadataPermissions{Capabilities: []string{"ai"}}))
+	assert.True(t, listed.Covers(&MetadataPermissions{}))
+	assert.False(t, listed.Covers(&MetadataPermissions{Capabilities: []string{"screenshot"}}))
+	assert.False(t, listed.Covers(&MetadataPermissions{NetworkDomains: []string{"evil.example.com"}}))
+	assert.False(t, listed.Covers(nil), "dropping the Permissions section asks for full access")
+
+	var legacy *MetadataPermissions
+	assert.True(t, legacy.Covers(listed), "a listing without permissions already grants full access")
+	assert.True(t, legacy.Covers(nil))
+	assert.True(t, legacy.Covers(&MetadataPermissions{}))
+	assert.True(t, legacy.IsEmpty())
+}
+
+func TestInstanceCheckPermission(t *testing.T) {
+	logger = util.GetLogger()
+	ctx := context.Background()
+
+	sandboxed := &Instance{Metadata: Metadata{Id: "sandboxed", Permissions: &MetadataPermissions{Capabilities: []string{"screenshot"}}}}
+	assert.NoError(t, sandboxed.CheckPermission(ctx, MetadataPermissionScreenshot))
+	err := sandboxed.CheckPermission(ctx, MetadataPermissionClipboardWrite)
+	assert.True(t, errors.Is(err, ErrPermissionDenied))
+
+	system := &Instance{IsSystemPlugin: true, Metadata: Metadata{Id: "system", Permissions: &MetadataPermissions{}}}
+	assert.NoError(t, system.CheckPermission(ctx, MetadataPermissionClipboardWrite))
+}
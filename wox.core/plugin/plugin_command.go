@@ -13,6 +13,10 @@ type PluginCommandRequest struct {
 	PluginId string
 	Command  string
 	Data     common.ContextData
+
+	// CallerPluginId is set by the plugin manager to the invoking plugin, or left
+	// empty when Wox itself (e.g. the MCP server) sends the command.
+	CallerPluginId string `json:"-"`
 }
 
 // PluginCommandResult reports whether a plugin command was handled.
@@ -25,8 +25,9 @@ import (
 )
 
 type storeManifest struct {
-	Name string
-	Url  string
+	Name      string
+	Url       string
+	PublicKey string
 }
 
 type StorePluginManifest struct {
@@ -46,6 +47,20 @@ type StorePluginManifest struct {
 	DateCreated    string
 	DateUpdated    string
 
+	// Sha256 is the hex digest of the file at DownloadUrl.
+	Sha256 string
+	// Signature is a base64 ed25519 signature of storePluginSignaturePayload.
+	Signature string
+
+	// StoreName and StoreUrl record which store listed the plugin. They are set
+	// after fetching, so a catalog cannot claim to come from another store.
+	StoreName string
+	StoreUrl  string
+
+	// Permissions is what the store says the plugin will ask for. Users consent
+	// to this before installing, and installs fail if plugin.json asks for more.
+	Permissions *MetadataPermissions
+
 	// I18n holds inline translations for the store manifest.
 	// Map structure: langCode -> key -> translatedValue
 	// Example: {"en_US": {"plugin_name": "Hello"}, "zh_CN": {"plugin_name": "你好"}}
@@ -134,6 +149,9 @@ type Store struct {
 	// interleave their file-system and plugin-manager mutations, which previously
 	// caused loading-indicator flicker and potential data corruption.
 	installMu sync.Mutex
+
+	storeSources   []StoreSource
+	storeSourcesMu sync.RWMutex
 }
 
 func GetStoreManager() *Store {
@@ -143,15 +161,6 @@ func GetStoreManager() *Store {
 	return storeInstance
 }
 
-func (s *Store) getStoreManifests(ctx context.Context) []storeManifest {
-	return []storeManifest{
-		{
-			Name: "Wox Official Plugin Store",
-			Url:  "https://raw.githubusercontent.com/Wox-launcher/Wox/master/store-plugin.json",
-		},
-	}
-}
-
 // get plugin manifests from plugin stores, and update in the background every 10 minutes
 func (s *Store) Start(ctx context.Context) {
 	s.setPluginManifests(ctx, s.GetStorePluginManifests(ctx))
@@ -197,6 +206,7 @@ func (s *Store) buildManifestSignature(manifests []StorePluginManifest) string {
 
 func (s *Store) GetStorePluginManifests(ctx context.Context) []StorePluginManifest {
 	var storePluginManifests []StorePluginManifest
+	listedBy := map[string]string{}
 
 	for _, store := range s.getStoreManifests(ctx) {
 		pluginManifest, manifestErr := s.GetStorePluginManifest(ctx, store)
@@ -206,24 +216,19 @@ func (s *Store) GetStorePluginManifests(ctx context.Context) []StorePluginManife
 		}
 
 		for _, manifest := range pluginManifest {
-			existingManifest, found := lo.Find(s.pluginManifests, func(m StorePluginManifest) bool {
-				return manifest.Id == m.Id
-			})
-			if found {
-				existingVersion, existingErr := semver.NewVersion(existingManifest.Version)
-				currentVersion, currentErr := semver.NewVersion(manifest.Version)
-				if existingErr != nil && currentErr != nil {
-					if existingVersion.GreaterThan(currentVersion) {
-						logger.Info(ctx, fmt.Sprintf("skip %s(%s) from %s store, because it's already exist(%s)", manifest.GetName(ctx), manifest.Version, store.Name, existingManifest.Version))
-						continue
-					}
-				}
+			// Stores are ordered by the user, so the first store listing a plugin id
+			// owns it. Letting a later store replace it by publishing a higher version
+			// would allow any third-party catalog to hijack official plugins.
+			if ownerStore, found := listedBy[manifest.Id]; found {
+				logger.Info(ctx, fmt.Sprintf("skip %s(%s) from %s store, because it's already listed by %s store", manifest.GetName(ctx), manifest.Version, store.Name, ownerStore))
+				continue
 			}
 
 			if !IsAnySupportedInCurrentOS(manifest.SupportedOS) {
 				continue
 			}
 
+			listedBy[manifest.Id] = store.Name
 			storePluginManifests = append(storePluginManifests, manifest)
 		}
 	}
@@ -235,7 +240,7 @@ func (s *Store) GetStorePluginManifests(ctx context.Context) []StorePluginManife
 func (s *Store) GetStorePluginManifest(ctx context.Context, store storeManifest) ([]StorePluginManifest, error) {
 	logger.Info(ctx, fmt.Sprintf("start to get plugin manifest from %s(%s)", store.Name, store.Url))
 
-	response, getErr := util.HttpGet(ctx, store.Url)
+	response, getErr := readStoreCatalog(ctx, store.Url)
 	if getErr != nil {
 		return nil, getErr
 	}
@@ -250,6 +255,8 @@ func (s *Store) GetStorePluginManifest(ctx context.Context, store storeManifest)
 		if IsSupportedRuntime(string(storePluginManifests[i].Runtime)) {
 			storePluginManifests[i].Runtime = ConvertToRuntime(string(storePluginManifests[i].Runtime))
 		}
+		storePluginManifests[i].StoreName = store.Name
+		storePluginManifests[i].StoreUrl = store.Url
 	}
 
 	return storePluginManifests, nil
@@ -480,7 +487,7 @@ func (s *Store) installNormalPluginWithProgress(ctx context.Context, manifest St
 	pluginZipPath := path.Join(pluginDirectory, "plugin.zip")
 
 	// Download with progress tracking
-	downloadErr := util.HttpDownloadWithProgress(ctx, manifest.DownloadUrl, pluginZipPath, func(downloaded int64, total int64) {
+	downloadErr := downloadStorePluginFile(ctx, manifest, pluginZipPath, func(downloaded int64, total int64) {
 		if progressCallback != nil {
 			if total > 0 {
 				percentage := float64(downloaded) / float64(total) * 100
@@ -504,6 +511,20 @@ func (s *Store) installNormalPluginWithProgress(ctx context.Context, manifest St
 		progressCallback(i18n.GetI18nManager().TranslateWox(ctx, "i18n:plugin_install_progress_download_complete"))
 	}
 
+	// verify before extracting, a tampered archive must never touch the plugin directory
+	if progressCallback != nil {
+		progressCallback(i18n.GetI18nManager().TranslateWox(ctx, "i18n:plugin_install_progress_verifying"))
+	}
+	verifyErr := verifyStorePluginDownload(ctx, manifest, pluginZipPath, s.getStorePublicKey(manifest.StoreUrl))
+	if verifyErr != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to verify plugin %s(%s): %s", manifest.GetName(ctx), manifest.Version, verifyErr.Error()))
+		removeErr := os.RemoveAll(pluginDirectory)
+		if removeErr != nil {
+			logger.Error(ctx, fmt.Sprintf("failed to remove plugin directory %s: %s", pluginDirectory, removeErr.Error()))
+		}
+		return fmt.Errorf("failed to verify plugin %s(%s): %s", manifest.GetName(ctx), manifest.Version, verifyErr.Error())
+	}
+
 	//unzip plugin
 	logger.Info(ctx, fmt.Sprintf("start to unzip plugin %s(%s)", manifest.GetName(ctx), manifest.Version))
 	if progressCallback != nil {
@@ -524,6 +545,15 @@ func (s *Store) installNormalPluginWithProgress(ctx context.Context, manifest St
 		progressCallback(i18n.GetI18nManager().TranslateWox(ctx, "i18n:plugin_install_progress_extraction_complete"))
 	}
 
+	if permissionErr := ensurePermissionsConsented(ctx, manifest, pluginDirectory); permissionErr != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to install plugin %s(%s): %s", manifest.GetName(ctx), manifest.Version, permissionErr.Error()))
+		removeErr := os.RemoveAll(pluginDirectory)
+		if removeErr != nil {
+			logger.Error(ctx, fmt.Sprintf("failed to remove plugin directory %s: %s", pluginDirectory, removeErr.Error()))
+		}
+		return fmt.Errorf("failed to install plugin %s(%s): %s", manifest.GetName(ctx), manifest.Version, permissionErr.Error())
+	}
+
 	//load plugin
 	logger.Info(ctx, fmt.Sprintf("start to load plugin %s(%s)", manifest.GetName(ctx), manifest.Version))
 	if progressCallback != nil {
@@ -565,6 +595,19 @@ func (s *Store) installNormalPluginWithProgress(ctx context.Context, manifest St
 	return nil
 }
 
+// ensurePermissionsConsented compares the extracted plugin.json with the store
+// manifest the user agreed to, so a package cannot quietly grant itself more.
+func ensurePermissionsConsented(ctx context.Context, manifest StorePluginManifest, pluginDirectory string) error {
+	metadata, err := GetPluginManager().ParseMetadata(ctx, pluginDirectory)
+	if err != nil {
+		return err
+	}
+	if !manifest.Permissions.Covers(metadata.Permissions) {
+		return fmt.Errorf("plugin.json asks for more permissions than the store listed")
+	}
+	return nil
+}
+
 func (s *Store) installScriptPlugin(ctx context.Context, manifest StorePluginManifest) error {
 	return s.installScriptPluginWithProgress(ctx, manifest, nil)
 }
@@ -686,7 +729,7 @@ func (s *Store) installScriptPluginWithProgress(ctx context.Context, manifest St
 		progressCallback(i18n.GetI18nManager().TranslateWox(ctx, "i18n:plugin_install_progress_starting_download"))
 	}
 
-	downloadErr := util.HttpDownloadWithProgress(ctx, manifest.DownloadUrl, newScriptPath, func(downloaded int64, total int64) {
+	downloadErr := downloadStorePluginFile(ctx, manifest, newScriptPath, func(downloaded int64, total int64) {
 		if progressCallback != nil {
 			if total > 0 {
 				percentage := float64(downloaded) / float64(total) * 100
@@ -706,6 +749,18 @@ func (s *Store) installScriptPluginWithProgress(ctx context.Context, manifest St
 		}
 		return fmt.Errorf("failed to download script plugin %s(%s): %s", manifest.GetName(ctx), manifest.Version, downloadErr.Error())
 	}
+
+	verifyErr := verifyStorePluginDownload(ctx, manifest, newScriptPath, s.getStorePublicKey(manifest.StoreUrl))
+	if verifyErr != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to verify script plugin %s(%s): %s", manifest.GetName(ctx), manifest.Version, verifyErr.Error()))
+		// rollback, and never leave an unverified script where the loader can find it
+		_ = os.Remove(newScriptPath)
+		if hasBackup {
+			_ = os.Rename(backupPath, existingScriptPath)
+			_ = os.RemoveAll(backupDir)
+		}
+		return fmt.Errorf("failed to verify script plugin %s(%s): %s", manifest.GetName(ctx), manifest.Version, verifyErr.Error())
+	}
 	_ = os.Chmod(newScriptPath, 0755)
 
 	if progressCallback != nil {
@@ -728,6 +783,16 @@ func (s *Store) installScriptPluginWithProgress(ctx context.Context, manifest St
 		}
 		return fmt.Errorf("failed to parse script plugin metadata: %s", metaErr.Error())
 	}
+	if !manifest.Permissions.Covers(metadata.Permissions) {
+		logger.Error(ctx, fmt.Sprintf("script plugin %s(%s) asks for more permissions than the store listed", manifest.GetName(ctx), manifest.Version))
+		// rollback
+		_ = os.Remove(newScriptPath)
+		if hasBackup {
+			_ = os.Rename(backupPath, existingScriptPath)
+			_ = os.RemoveAll(backupDir)
+		}
+		return fmt.Errorf("script plugin %s(%s) asks for more permissions than the store listed", manifest.GetName(ctx), manifest.Version)
+	}
 	if manifest.Id != "" && metadata.Id != "" && !strings.EqualFold(manifest.Id, metadata.Id) {
 		logger.Warn(ctx, fmt.Sprintf("script metadata id(%s) not equal to store manifest id(%s), proceed with metadata id", metadata.Id, manifest.Id))
 	}
@@ -0,0 +1,225 @@
+package plugin
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"wox/util"
+)
+
+const officialStoreName = "Wox Official Plugin Store"
+const officialStoreUrl = "https://raw.githubusercontent.com/Wox-launcher/Wox/master/store-plugin.json"
+
+// StoreSource is a user configured plugin catalog. Catalogs can be served over
+// http(s) or read from a file:// url, e.g. a shared drive for company plugins.
+type StoreSource struct {
+	Name string
+	Url  string
+	// PublicKey is a base64 ed25519 key. When set, every plugin from this store
+	// must carry a Sha256 and a Signature that verify against it.
+	PublicKey string
+	// Priority orders sources, lower first. Sources with the same priority keep
+	// the order they were added in.
+	Priority int
+	Disabled bool
+}
+
+// SetStoreSources replaces the user configured stores and reloads the catalog
+// in the background when anything changed.
+func (s *Store) SetStoreSources(ctx context.Context, sources []StoreSource) {
+	s.storeSourcesMu.Lock()
+	changed := !slices.Equal(s.storeSources, sources)
+	s.storeSources = slices.Clone(sources)
+	s.storeSourcesMu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	util.Go(ctx, "reload store plugins after store sources changed", func() {
+		traceCtx := util.NewTraceContext()
+		s.setPluginManifests(traceCtx, s.GetStorePluginManifests(traceCtx))
+	})
+}
+
+// getStoreManifests returns the stores to load, official store first. A user
+// store can never shadow an official plugin because the first listing wins.
+func (s *Store) getStoreManifests(ctx context.Context) []storeManifest {
+	stores := []storeManifest{
+		{
+			Name: officialStoreName,
+			Url:  officialStoreUrl,
+		},
+	}
+
+	s.storeSourcesMu.RLock()
+	sources := slices.Clone(s.storeSources)
+	s.storeSourcesMu.RUnlock()
+
+	sort.SliceStable(sources, func(i, j int) bool {
+		return sources[i].Priority < sources[j].Priority
+	})
+	for _, source := range sources {
+		sourceUrl := strings.TrimSpace(source.Url)
+		if source.Disabled || sourceUrl == "" || sourceUrl == officialStoreUrl {
+			continue
+		}
+		name := strings.TrimSpace(source.Name)
+		if name == "" {
+			name = sourceUrl
+		}
+		stores = append(stores, storeManifest{
+			Name:      name,
+			Url:       sourceUrl,
+			PublicKey: strings.TrimSpace(source.PublicKey),
+		})
+	}
+
+	return stores
+}
+
+// getStorePublicKey looks up the key of the store a manifest came from. The key
+// always comes from local settings, never from the catalog it protects.
+func (s *Store) getStorePublicKey(storeUrl string) string {
+	for _, store := range s.getStoreManifests(context.Background()) {
+		if store.Url == storeUrl {
+			return store.PublicKey
+		}
+	}
+	return ""
+}
+
+// storeLocalPath returns the local path of a file:// url.
+func storeLocalPath(rawUrl string) (string, bool) {
+	parsed, err := url.Parse(rawUrl)
+	if err != nil || !strings.EqualFold(parsed.Scheme, "file") {
+		return "", false
+	}
+
+	localPath := parsed.Path
+	if parsed.Host != "" && parsed.Host != "localhost" {
+		// file://server/share/store.json is a UNC path on Windows.
+		localPath = "//" + parsed.Host + localPath
+	}
+	if runtime.GOOS == "windows" && len(localPath) > 2 && localPath[0] == '/' && localPath[2] == ':' {
+		localPath = localPath[1:]
+	}
+	return filepath.FromSlash(localPath), true
+}
+
+func readStoreCatalog(ctx context.Context, catalogUrl string) ([]byte, error) {
+	if localPath, ok := storeLocalPath(catalogUrl); ok {
+		return os.ReadFile(localPath)
+	}
+	return util.HttpGet(ctx, catalogUrl)
+}
+
+// downloadStorePluginFile fetches the plugin file of a manifest. Local files are
+// only read for file:// catalogs, so a remote catalog cannot point Wox at
+// arbitrary files on this machine.
+func downloadStorePluginFile(ctx context.Context, manifest StorePluginManifest, dest string, progressCallback func(downloaded int64, total int64)) error {
+	localPath, ok := storeLocalPath(manifest.DownloadUrl)
+	if !ok {
+		return util.HttpDownloadWithProgress(ctx, manifest.DownloadUrl, dest, progressCallback)
+	}
+	if _, isLocalStore := storeLocalPath(manifest.StoreUrl); !isLocalStore {
+		return fmt.Errorf("plugin %s(%s) from store %s has a file:// download url, which only file:// stores may use", manifest.Id, manifest.Version, manifest.StoreName)
+	}
+
+	src, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	written, copyErr := io.Copy(dst, src)
+	closeErr := dst.Close()
+	if copyErr != nil {
+		return copyErr
+	}
+	if closeErr != nil {
+		return closeErr
+	}
+	if progressCallback != nil {
+		progressCallback(written, written)
+	}
+	return nil
+}
+
+// storePluginSignaturePayload is the message store owners sign for each release.
+// Binding id and version stops a valid signature from being replayed on another
+// plugin or on an older, vulnerable release.
+func storePluginSignaturePayload(manifest StorePluginManifest) []byte {
+	return []byte(manifest.Id + "\n" + manifest.Version + "\n" + strings.ToLower(strings.TrimSpace(manifest.Sha256)))
+}
+
+// verifyStorePluginDownload checks a downloaded plugin file against the hash
+// and signature of its manifest.
+func verifyStorePluginDownload(ctx context.Context, manifest StorePluginManifest, filePath string, publicKey string) error {
+	if publicKey != "" && (manifest.Sha256 == "" || manifest.Signature == "") {
+		return fmt.Errorf("store %s requires signed plugins, but %s(%s) has no sha256 or signature", manifest.StoreName, manifest.Id, manifest.Version)
+	}
+	if manifest.Signature != "" && manifest.Sha256 == "" {
+		return fmt.Errorf("plugin %s(%s) is signed without a sha256", manifest.Id, manifest.Version)
+	}
+
+	if manifest.Sha256 != "" {
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		hash := sha256.New()
+		_, copyErr := io.Copy(hash, file)
+		file.Close()
+		if copyErr != nil {
+			return copyErr
+		}
+		actual := hex.EncodeToString(hash.Sum(nil))
+		if !strings.EqualFold(actual, strings.TrimSpace(manifest.Sha256)) {
+			return fmt.Errorf("sha256 mismatch for %s(%s): expected %s, got %s", manifest.Id, manifest.Version, manifest.Sha256, actual)
+		}
+	}
+
+	if manifest.Signature == "" {
+		return nil
+	}
+	if publicKey == "" {
+		// A signature that cannot be checked proves nothing, so refuse it rather
+		// than install a release the store expected to be verified.
+		return fmt.Errorf("plugin %s(%s) is signed, but no public key is configured for store %s", manifest.Id, manifest.Version, manifest.StoreName)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid ed25519 public key for store %s", manifest.StoreName)
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(manifest.Signature))
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding for %s(%s): %w", manifest.Id, manifest.Version, err)
+	}
+	if !ed25519.Verify(ed25519.PublicKey(key), storePluginSignaturePayload(manifest), signature) {
+		return fmt.Errorf("signature verification failed for %s(%s)", manifest.Id, manifest.Version)
+	}
+	return nil
+}
+
+// IsOfficialStore reports whether a manifest's StoreUrl is the official store.
+// Manifests saved before stores were recorded have no StoreUrl and came from it.
+func IsOfficialStore(storeUrl string) bool {
+	return storeUrl == "" || storeUrl == officialStoreUrl
+}
@@ -0,0 +1,106 @@
+package plugin
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"wox/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyStorePluginDownload(t *testing.T) {
+	logger = util.GetLogger()
+	ctx := context.Background()
+
+	filePath := filepath.Join(t.TempDir(), "plugin.zip")
+	content := []byte("plugin archive")
+	require.NoError(t, os.WriteFile(filePath, content, 0o644))
+	digest := sha256.Sum256(content)
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	encodedKey := base64.StdEncoding.EncodeToString(publicKey)
+
+	manifest := StorePluginManifest{Id: "plugin-id", Version: "1.2.0", Sha256: hex.EncodeToString(digest[:]), StoreName: "Company"}
+	manifest.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, storePluginSignaturePayload(manifest)))
+
+	assert.NoError(t, verifyStorePluginDownload(ctx, manifest, filePath, encodedKey))
+	assert.ErrorContains(t, verifyStorePluginDownload(ctx, manifest, filePath, ""), "no public key is configured")
+
+	tampered := manifest
+	tampered.Sha256 = hex.EncodeToString(make([]byte, sha256.Size))
+	assert.ErrorContains(t, verifyStorePluginDownload(ctx, tampered, filePath, encodedKey), "sha256 mismatch")
+
+	// A valid signature must not carry over to another version of the same plugin.
+	replayed := manifest
+	replayed.Version = "1.1.0"
+	assert.ErrorContains(t, verifyStorePluginDownload(ctx, replayed, filePath, encodedKey), "signature verification failed")
+
+	unsigned := manifest
+	unsigned.Signature = ""
+	assert.NoError(t, verifyStorePluginDownload(ctx, unsigned, filePath, ""))
+	assert.ErrorContains(t, verifyStorePluginDownload(ctx, unsigned, filePath, encodedKey), "requires signed plugins")
+}
+
+func TestDownloadStorePluginFileOnlyReadsLocalFilesForLocalStores(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	sourcePath := filepath.Join(dir, "plugin.zip")
+	require.NoError(t, os.WriteFile(sourcePath, []byte("plugin archive"), 0o644))
+	fileUrl := func(localPath string) string {
+		return (&url.URL{Scheme: "file", Path: filepath.ToSlash(localPath)}).String()
+	}
+
+	manifest := StorePluginManifest{Id: "plugin-id", Version: "1.0.0", StoreName: "Company", StoreUrl: fileUrl(filepath.Join(dir, "store-plugin.json")), DownloadUrl: fileUrl(sourcePath)}
+	dest := filepath.Join(dir, "downloaded.zip")
+	require.NoError(t, downloadStorePluginFile(ctx, manifest, dest, nil))
+	downloaded, err := os.ReadFile(dest)
+	require.NoError(t, err)
+	assert.Equal(t, "plugin archive", string(downloaded))
+
+	remote := manifest
+	remote.StoreUrl = "https://example.com/store-plugin.json"
+	assert.ErrorContains(t, downloadStorePluginFile(ctx, remote, filepath.Join(dir, "remote.zip"), nil), "only file:// stores")
+	assert.NoFileExists(t, filepath.Join(dir, "remote.zip"))
+}
+
+func TestStoreLoadsOrderedFileCatalogs(t *testing.T) {
+	logger = util.GetLogger()
+	ctx := context.Background()
+
+	catalogPath := filepath.Join(t.TempDir(), "store-plugin.json")
+	catalog := `[{"Id":"company-plugin","Name":"Company Plugin","Version":"1.0.0","Runtime":"python","StoreName":"Spoofed","StoreUrl":"https://example.com"}]`
+	require.NoError(t, os.WriteFile(catalogPath, []byte(catalog), 0o644))
+	catalogUrl := (&url.URL{Scheme: "file", Path: filepath.ToSlash(catalogPath)}).String()
+
+	store := &Store{}
+	store.storeSources = []StoreSource{
+		{Name: "Later", Url: "https://later.example.com/store.json", Priority: 10},
+		{Name: "Disabled", Url: "https://disabled.example.com/store.json", Disabled: true},
+		{Name: "Company", Url: catalogUrl, PublicKey: "key", Priority: 1},
+	}
+
+	stores := store.getStoreManifests(ctx)
+	require.Len(t, stores, 3)
+	assert.Equal(t, officialStoreName, stores[0].Name)
+	assert.Equal(t, "Company", stores[1].Name)
+	assert.Equal(t, "Later", stores[2].Name)
+	assert.Equal(t, "key", store.getStorePublicKey(catalogUrl))
+
+	manifests, err := store.GetStorePluginManifest(ctx, stores[1])
+	require.NoError(t, err)
+	require.Len(t, manifests, 1)
+	assert.Equal(t, PLUGIN_RUNTIME_PYTHON, manifests[0].Runtime)
+	assert.Equal(t, "Company", manifests[0].StoreName, "the catalog must not choose its own store name")
+	assert.Equal(t, catalogUrl, manifests[0].StoreUrl)
+	assert.False(t, IsOfficialStore(manifests[0].StoreUrl))
+}
@@ -0,0 +1,40 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"wox/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeStoreTestPluginJson(t *testing.T, permissions string) string {
+	t.Helper()
+
+	pluginDirectory := t.TempDir()
+	pluginJson := `{"Id":"store-test","Name":"Store Test","Version":"1.0.0","Runtime":"python","Entry":"main.py","TriggerKeywords":["st"],"SupportedOS":["Windows","Linux","Darwin"]` + permissions + `}`
+	require.NoError(t, os.WriteFile(filepath.Join(pluginDirectory, "plugin.json"), []byte(pluginJson), 0o644))
+	return pluginDirectory
+}
+
+func TestEnsurePermissionsConsented(t *testing.T) {
+	logger = util.GetLogger()
+	ctx := context.Background()
+
+	declared := writeStoreTestPluginJson(t, `,"Permissions":{"Capabilities":["clipboardWrite"]}`)
+	legacy := writeStoreTestPluginJson(t, "")
+
+	// Official listings carry no Permissions yet, which the user accepted as full access.
+	assert.NoError(t, ensurePermissionsConsented(ctx, StorePluginManifest{Id: "store-test"}, declared), "nil listing + declared plugin.json")
+	assert.NoError(t, ensurePermissionsConsented(ctx, StorePluginManifest{Id: "store-test"}, legacy))
+
+	listed := StorePluginManifest{Id: "store-test", Permissions: &MetadataPermissions{Capabilities: []string{"clipboardWrite", "ai"}}}
+	assert.NoError(t, ensurePermissionsConsented(ctx, listed, declared))
+	assert.ErrorContains(t, ensurePermissionsConsented(ctx, listed, legacy), "more permissions")
+
+	narrower := StorePluginManifest{Id: "store-test", Permissions: &MetadataPermissions{Capabilities: []string{"ai"}}}
+	assert.ErrorContains(t, ensurePermissionsConsented(ctx, narrower, declared), "more permissions")
+}
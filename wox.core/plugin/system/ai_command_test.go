@@ -9,6 +9,7 @@ import (
 	"wox/common"
 	"wox/plugin"
 	"wox/setting/definition"
+	"wox/util/filesearch"
 	"wox/util/overlay/textoverlay"
 	"wox/util/selection"
 
@@ -155,6 +156,20 @@ func (a *aiCommandTestAPI) Screenshot(ctx context.Context, option plugin.Screens
 	return plugin.ScreenshotResult{}
 }
 
+func (a *aiCommandTestAPI) RegisterHotkey(ctx context.Context, hotkey plugin.PluginHotkey) plugin.RegisterHotkeyResult {
+	return plugin.RegisterHotkeyResult{}
+}
+
+func (a *aiCommandTestAPI) UnregisterHotkey(ctx context.Context, id string) {
+}
+
+func (a *aiCommandTestAPI) RegisterContentExtractor(ctx context.Context, extractor filesearch.ContentExtractor) error {
+	return nil
+}
+
+func (a *aiCommandTestAPI) UnregisterContentExtractor(ctx context.Context, name string) {
+}
+
 func aiCommandTestCommand(defaultAction string) map[string]any {
 	command := map[string]any{
 		"name":    "Grammar",
@@ -70,6 +70,16 @@ type appInfo struct {
 	// back to a generic/default asset after a restart. Normal app search still
 	// keeps these entries visible.
 	IsDefaultIcon bool `json:"is_default_icon,omitempty"`
+
+	// DesktopActions are the extra launchers a Linux desktop entry declares, such as
+	// "New Private Window". They become result actions and standalone results.
+	DesktopActions []appDesktopAction `json:"desktop_actions,omitempty"`
+	// LaunchTargetKind and MimeTypes describe what the launcher accepts through its
+	// field codes, so selection queries can offer the app without reparsing it.
+	LaunchTargetKind string   `json:"launch_target_kind,omitempty"`
+	MimeTypes        []string `json:"mime_types,omitempty"`
+	// DesktopActionID marks a standalone result that launches one desktop action of Path.
+	DesktopActionID string `json:"-"`
 }
 
 type appCacheFile struct {
@@ -78,7 +88,7 @@ type appCacheFile struct {
 }
 
 // Bump this when cached appInfo fields or preprocessed icon semantics change.
-const appCacheVersion = 14
+const appCacheVersion = 15
 
 const (
 	appCommandReindex   = "reindex"
@@ -246,6 +256,9 @@ func (a *ApplicationPlugin) GetMetadata() plugin.Metadata {
 			{
 				Name: plugin.MetadataFeatureMRU,
 			},
+			{
+				Name: plugin.MetadataFeatureQuerySelection,
+			},
 		},
 		Commands: []plugin.MetadataCommand{
 			{
@@ -528,6 +541,10 @@ func (a *ApplicationPlugin) Query(ctx context.Context, query plugin.Query) plugi
 		})
 	}
 
+	if query.Type == plugin.QueryTypeSelection {
+		return plugin.NewQueryResponse(a.querySelection(ctx, query))
+	}
+
 	isLaunchpadQuery := query.Command == appCommandLaunchpad
 	queryStartedAt := util.GetSystemTimestamp()
 	usePinyin := setting.GetSettingManager().GetWoxSetting(ctx).UsePinYin.Get()
@@ -650,6 +667,9 @@ func (a *ApplicationPlugin) Query(ctx context.Context, query plugin.Query) plugi
 			"path": entry.info.Path,
 			"type": entry.info.Type,
 		}
+		if entry.info.DesktopActionID != "" {
+			contextData["desktop_action"] = entry.info.DesktopActionID
+		}
 		actionBuildStart := time.Now()
 		actions := a.buildAppActions(entry.info, match.displayName, contextData)
 		resultActionBuildUs := time.Since(actionBuildStart).Microseconds()
@@ -670,7 +690,7 @@ func (a *ApplicationPlugin) Query(ctx context.Context, query plugin.Query) plugi
 		// Launchpad mode is a static app grid that replaces macOS Launchpad's removed entry point.
 		// The normal app query tracks visible rows so CPU/memory tails and terminate actions stay fresh,
 		// but those running-state updates make a Launchpad-style grid noisy and can resize cells while browsing.
-		if !isLaunchpadQuery {
+		if !isLaunchpadQuery && entry.info.DesktopActionID == "" {
 			trackedStoreStart := time.Now()
 			a.trackedResults.Store(result.Id, entry.info)
 			resultTrackStoreUs = time.Since(trackedStoreStart).Microseconds()
@@ -790,6 +810,10 @@ func selectAppQueryMatches(matches []appQueryMatch, shouldLimit bool) ([]appQuer
 }
 
 func (a *ApplicationPlugin) buildAppActions(info appInfo, displayName string, contextData map[string]string) []plugin.QueryResultAction {
+	if info.DesktopActionID != "" {
+		return a.buildDesktopActionResultActions(info, displayName, contextData)
+	}
+
 	actions := []plugin.QueryResultAction{
 		{
 			Name:        "i18n:plugin_app_open",
@@ -800,8 +824,8 @@ func (a *ApplicationPlugin) buildAppActions(info appInfo, displayName string, co
 
 				// Check if app is already running and try to activate its window
 				// macos default behavior is to activate existing instance
-				// windows needs special handling to activate existing window
-				if util.IsWindows() {
+				// windows and linux need special handling to activate existing window
+				if util.IsWindows() || util.IsLinux() {
 					currentPid := a.retriever.GetPid(ctx, info)
 					if currentPid > 0 {
 						// App is running, try to activate its window
@@ -856,6 +880,8 @@ func (a *ApplicationPlugin) buildAppActions(info appInfo, displayName string, co
 		}
 	}
 
+	actions = append(actions, a.buildDesktopActionActions(info, displayName, contextData)...)
+
 	if info.Type != AppTypeWindowsSetting {
 		actions = append(actions, plugin.QueryResultAction{
 			Name:        "i18n:plugin_app_open_containing_folder",
@@ -1678,6 +1704,16 @@ func (a *ApplicationPlugin) rebuildQueryEntries(ctx context.Context) {
 			continue
 		}
 		entries = append(entries, entry)
+
+		// Desktop actions are searchable on their own, but only while their app is
+		// not ignored, so hiding an app also hides its jump-list entries.
+		for _, actionInfo := range expandDesktopActionApps(info) {
+			actionEntry := a.buildQueryEntry(ctx, actionInfo)
+			if _, actionIgnored := a.matchIgnoreRuleCandidates(actionEntry.ignoreCandidates, ignoreMatchers); actionIgnored {
+				continue
+			}
+			entries = append(entries, actionEntry)
+		}
 	}
 
 	a.queryEntriesMutex.Lock()
@@ -2010,7 +2046,20 @@ func (a *ApplicationPlugin) handleMRURestore(ctx context.Context, mruData plugin
 	}
 
 	var appInfo *appInfo
+	desktopActionID := mruData.ContextData["desktop_action"]
 	for _, info := range a.apps {
+		if desktopActionID != "" {
+			if info.Path != contextData.Path {
+				continue
+			}
+			for _, actionInfo := range expandDesktopActionApps(info) {
+				if actionInfo.DesktopActionID == desktopActionID {
+					appInfo = &actionInfo
+					break
+				}
+			}
+			break
+		}
 		if info.Name == contextData.Name && info.Path == contextData.Path {
 			appInfo = &info
 			break
@@ -2041,7 +2090,9 @@ func (a *ApplicationPlugin) handleMRURestore(ctx context.Context, mruData plugin
 	}
 
 	// Track this result for periodic refresh (refreshRunningApps will handle running state)
-	a.trackedResults.Store(result.Id, *appInfo)
+	if appInfo.DesktopActionID == "" {
+		a.trackedResults.Store(result.Id, *appInfo)
+	}
 
 	return result, nil
 }
@@ -2117,6 +2168,7 @@ func (a *ApplicationPlugin) refreshRunningApps(ctx context.Context) {
 				}
 				// Capture current Pid for the closure
 				currentAppPid := appInfo.Pid
+				runningApp := appInfo
 				actions = append(actions, plugin.QueryResultAction{
 					Name: "i18n:plugin_app_terminate",
 					Icon: common.TerminateAppIcon,
@@ -2127,6 +2179,13 @@ func (a *ApplicationPlugin) refreshRunningApps(ctx context.Context) {
 						"action": "terminate",
 					},
 					Action: func(ctx context.Context, actionContext plugin.ActionContext) {
+						if terminator, ok := a.retriever.(appTerminator); ok {
+							if terminateErr := terminator.TerminateApp(ctx, runningApp); terminateErr != nil {
+								a.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("error terminating %s: %s", runningApp.Name, terminateErr.Error()))
+							}
+							return
+						}
+
 						// peacefully kill the process
 						p, getErr := os.FindProcess(currentAppPid)
 						if getErr != nil {
@@ -12,6 +12,7 @@ import (
 	"wox/setting/definition"
 	"wox/util"
 	"wox/util/fileicon"
+	"wox/util/filesearch"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -126,6 +127,20 @@ func (e emptyAPIImpl) Screenshot(ctx context.Context, option plugin.ScreenshotOp
 	return plugin.ScreenshotResult{}
 }
 
+func (e emptyAPIImpl) RegisterHotkey(ctx context.Context, hotkey plugin.PluginHotkey) plugin.RegisterHotkeyResult {
+	return plugin.RegisterHotkeyResult{}
+}
+
+func (e emptyAPIImpl) UnregisterHotkey(ctx context.Context, id string) {
+}
+
+func (e emptyAPIImpl) RegisterContentExtractor(ctx context.Context, extractor filesearch.ContentExtractor) error {
+	return nil
+}
+
+func (e emptyAPIImpl) UnregisterContentExtractor(ctx context.Context, name string) {
+}
+
 func TestMacRetriever_ParseAppInfo(t *testing.T) {
 	if util.IsMacOS() {
 		util.GetLocation().Init()
@@ -0,0 +1,203 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"wox/analytics"
+	"wox/common"
+	"wox/plugin"
+	"wox/setting"
+	"wox/util"
+	"wox/util/fuzzymatch"
+	"wox/util/selection"
+)
+
+// appDesktopAction is an extra launcher declared by an app, e.g. a Linux desktop
+// entry's "[Desktop Action new-private-window]" group.
+type appDesktopAction struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// appLaunchTarget is a file or URL handed to an app at launch.
+type appLaunchTarget struct {
+	// Value is a local path for files and the raw URL otherwise.
+	Value string
+	IsURL bool
+}
+
+// expandDesktopActionApps turns each desktop action of info into its own searchable
+// app entry that points at the same launcher file.
+func expandDesktopActionApps(info appInfo) []appInfo {
+	if info.DesktopActionID != "" || len(info.DesktopActions) == 0 {
+		return nil
+	}
+
+	apps := make([]appInfo, 0, len(info.DesktopActions))
+	for _, desktopAction := range info.DesktopActions {
+		actionInfo := info
+		actionInfo.Name = fmt.Sprintf("%s - %s", info.Name, desktopAction.Name)
+		actionInfo.SearchableNames = []string{desktopAction.Name}
+		actionInfo.DesktopActions = nil
+		actionInfo.LaunchTargetKind = ""
+		actionInfo.MimeTypes = nil
+		actionInfo.DesktopActionID = desktopAction.ID
+		apps = append(apps, actionInfo)
+	}
+	return apps
+}
+
+// buildDesktopActionActions exposes desktop actions on the app's own result.
+func (a *ApplicationPlugin) buildDesktopActionActions(info appInfo, displayName string, contextData map[string]string) []plugin.QueryResultAction {
+	if len(info.DesktopActions) == 0 {
+		return nil
+	}
+	if _, ok := a.retriever.(appTargetLauncher); !ok {
+		return nil
+	}
+
+	actions := make([]plugin.QueryResultAction, 0, len(info.DesktopActions))
+	for _, desktopAction := range info.DesktopActions {
+		actions = append(actions, plugin.QueryResultAction{
+			Name:        desktopAction.Name,
+			Icon:        common.ExecuteRunIcon,
+			ContextData: contextData,
+			Action: func(ctx context.Context, actionContext plugin.ActionContext) {
+				a.launchAppWithTargets(ctx, info, displayName, desktopAction.ID, nil)
+			},
+		})
+	}
+	return actions
+}
+
+// buildDesktopActionResultActions builds the actions of a standalone desktop action result.
+func (a *ApplicationPlugin) buildDesktopActionResultActions(info appInfo, displayName string, contextData map[string]string) []plugin.QueryResultAction {
+	return []plugin.QueryResultAction{
+		{
+			Name:        "i18n:plugin_app_open",
+			Icon:        common.OpenIcon,
+			ContextData: contextData,
+			Action: func(ctx context.Context, actionContext plugin.ActionContext) {
+				a.launchAppWithTargets(ctx, info, displayName, info.DesktopActionID, nil)
+			},
+		},
+		{
+			Name:        "i18n:plugin_app_open_containing_folder",
+			Icon:        common.OpenContainingFolderIcon,
+			ContextData: contextData,
+			Action: func(ctx context.Context, actionContext plugin.ActionContext) {
+				if err := a.retriever.OpenAppFolder(ctx, info); err != nil {
+					a.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("error opening folder: %s", err.Error()))
+					a.api.Notify(ctx, fmt.Sprintf(a.api.GetTranslation(ctx, "plugin_app_open_failed_description"), err.Error()))
+				}
+			},
+		},
+	}
+}
+
+func (a *ApplicationPlugin) launchAppWithTargets(ctx context.Context, info appInfo, displayName string, actionID string, targets []appLaunchTarget) {
+	launcher, ok := a.retriever.(appTargetLauncher)
+	if !ok {
+		return
+	}
+
+	analytics.TrackAppLaunched(ctx, fmt.Sprintf("%s:%s", info.Type, info.Name), displayName)
+	if err := launcher.LaunchApp(ctx, info, actionID, targets); err != nil {
+		a.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("error launching app %s: %s", info.Path, err.Error()))
+		a.api.Notify(ctx, fmt.Sprintf(a.api.GetTranslation(ctx, "plugin_app_open_failed_description"), err.Error()))
+	}
+}
+
+// querySelection offers apps that declare support for the selected files or URL,
+// so "open with" works from a selection hotkey.
+func (a *ApplicationPlugin) querySelection(ctx context.Context, query plugin.Query) []plugin.QueryResult {
+	results := []plugin.QueryResult{}
+	launcher, ok := a.retriever.(appTargetLauncher)
+	if !ok {
+		return results
+	}
+	targets := selectionLaunchTargets(query.Selection)
+	if len(targets) == 0 {
+		return results
+	}
+
+	usePinyin := setting.GetSettingManager().GetWoxSetting(ctx).UsePinYin.Get()
+	preparedPattern := fuzzymatch.PreparePattern(query.Search)
+	entries, _ := a.getQueryEntriesSnapshot()
+	for _, entry := range entries {
+		if entry.info.DesktopActionID != "" || !launcher.CanOpenTargets(entry.info, targets) {
+			continue
+		}
+
+		displayName, displayPath, searchCandidates := a.resolveQueryEntryDisplay(ctx, entry)
+		var score int64
+		if query.Search != "" {
+			isMatch := false
+			for _, candidate := range searchCandidates {
+				matchResult := fuzzymatch.FuzzyMatchPrepared(candidate, preparedPattern, usePinyin)
+				if matchResult.IsMatch && (!isMatch || matchResult.Score > score) {
+					isMatch = true
+					score = matchResult.Score
+				}
+			}
+			if !isMatch {
+				continue
+			}
+		}
+
+		info := entry.info
+		results = append(results, plugin.QueryResult{
+			Title:    fmt.Sprintf(a.api.GetTranslation(ctx, "plugin_app_open_with"), displayName),
+			SubTitle: displayPath,
+			Icon:     info.Icon,
+			Score:    score,
+			Actions: []plugin.QueryResultAction{
+				{
+					Name: "i18n:plugin_app_open",
+					Icon: common.OpenIcon,
+					Action: func(ctx context.Context, actionContext plugin.ActionContext) {
+						a.launchAppWithTargets(ctx, info, displayName, "", targets)
+					},
+				},
+			},
+		})
+		if len(results) >= appQueryResultLimitInGloablQuery {
+			break
+		}
+	}
+
+	return results
+}
+
+// selectionLaunchTargets converts a selection into launch targets. Text is only
+// used when it is a single URL or an existing path; free text has no app to open it.
+func selectionLaunchTargets(selected selection.Selection) []appLaunchTarget {
+	targets := []appLaunchTarget{}
+	switch selected.Type {
+	case selection.SelectionTypeFile:
+		for _, filePath := range selected.FilePaths {
+			if strings.TrimSpace(filePath) != "" {
+				targets = append(targets, appLaunchTarget{Value: filePath})
+			}
+		}
+	case selection.SelectionTypeText:
+		text := strings.TrimSpace(selected.Text)
+		if text == "" || strings.ContainsAny(text, "\r\n") {
+			return targets
+		}
+		// Single-letter schemes are Windows drive letters, not URLs.
+		if parsed, err := url.Parse(text); err == nil && len(parsed.Scheme) > 1 {
+			if strings.EqualFold(parsed.Scheme, "file") {
+				text = parsed.Path
+			} else if parsed.Host != "" || parsed.Opaque != "" {
+				return append(targets, appLaunchTarget{Value: text, IsURL: true})
+			}
+		}
+		if util.IsFileExists(text) {
+			targets = append(targets, appLaunchTarget{Value: text})
+		}
+	}
+	return targets
+}
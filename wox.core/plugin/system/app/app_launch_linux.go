@@ -0,0 +1,243 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"wox/util"
+	"wox/util/shell"
+)
+
+const (
+	linuxLaunchTargetFile = "file"
+	linuxLaunchTargetURL  = "url"
+)
+
+// linuxDesktopLaunchTargetKind reports which targets an Exec line accepts: %u/%U
+// take URLs and local paths, %f/%F only local paths.
+func linuxDesktopLaunchTargetKind(execValue string) string {
+	kind := ""
+	for _, arg := range splitLinuxDesktopExec(execValue) {
+		if strings.Contains(arg, "%u") || strings.Contains(arg, "%U") {
+			return linuxLaunchTargetURL
+		}
+		if strings.Contains(arg, "%f") || strings.Contains(arg, "%F") {
+			kind = linuxLaunchTargetFile
+		}
+	}
+	return kind
+}
+
+func (a *LinuxRetriever) CanOpenTargets(app appInfo, targets []appLaunchTarget) bool {
+	if app.LaunchTargetKind == "" || len(targets) == 0 {
+		return false
+	}
+
+	// MimeType= is how desktop entries claim content; apps that list nothing would
+	// otherwise show up for every selection just because their Exec takes %F.
+	for _, target := range targets {
+		if target.IsURL {
+			if app.LaunchTargetKind != linuxLaunchTargetURL {
+				return false
+			}
+			parsed, err := url.Parse(target.Value)
+			if err != nil || !linuxMimeTypeAccepted(app.MimeTypes, "x-scheme-handler/"+strings.ToLower(parsed.Scheme)) {
+				return false
+			}
+			continue
+		}
+		if !linuxMimeTypeAccepted(app.MimeTypes, linuxTargetMimeType(target.Value)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *LinuxRetriever) LaunchApp(ctx context.Context, app appInfo, actionID string, targets []appLaunchTarget) error {
+	entry, err := parseLinuxDesktopEntry(app.Path)
+	if err != nil {
+		return err
+	}
+
+	execValue := entry.Exec
+	if actionID != "" {
+		execValue = ""
+		for _, desktopAction := range entry.Actions {
+			if desktopAction.ID == actionID {
+				execValue = desktopAction.Exec
+				break
+			}
+		}
+		if execValue == "" {
+			return fmt.Errorf("desktop action %s not found in %s", actionID, app.Path)
+		}
+	}
+
+	commands, err := buildLinuxDesktopLaunchCommands(execValue, entry, app.Path, targets)
+	if err != nil {
+		return err
+	}
+	for _, args := range commands {
+		if err := startLinuxDesktopCommand(ctx, args, entry.WorkingDir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildLinuxDesktopLaunchCommands expands field codes into argv lists. Exec lines
+// with only %f or %u take a single target, so the spec asks launchers to start one
+// instance per target in that case.
+func buildLinuxDesktopLaunchCommands(execValue string, entry linuxDesktopEntry, desktopPath string, targets []appLaunchTarget) ([][]string, error) {
+	tokens := splitLinuxDesktopExec(execValue)
+	acceptsList := false
+	for _, token := range tokens {
+		if token == "%F" || token == "%U" {
+			acceptsList = true
+			break
+		}
+	}
+
+	targetGroups := [][]appLaunchTarget{targets}
+	if !acceptsList && len(targets) > 1 {
+		targetGroups = make([][]appLaunchTarget, 0, len(targets))
+		for _, target := range targets {
+			targetGroups = append(targetGroups, []appLaunchTarget{target})
+		}
+	}
+
+	commands := make([][]string, 0, len(targetGroups))
+	for _, group := range targetGroups {
+		args := expandLinuxDesktopExec(tokens, entry, desktopPath, group)
+		if len(args) == 0 {
+			return nil, fmt.Errorf("empty Exec command in %s", desktopPath)
+		}
+		commands = append(commands, args)
+	}
+	return commands, nil
+}
+
+func expandLinuxDesktopExec(tokens []string, entry linuxDesktopEntry, desktopPath string, targets []appLaunchTarget) []string {
+	files := []string{}
+	urls := []string{}
+	for _, target := range targets {
+		if !target.IsURL {
+			files = append(files, target.Value)
+			urls = append(urls, target.Value)
+			continue
+		}
+		urls = append(urls, target.Value)
+		if parsed, err := url.Parse(target.Value); err == nil && strings.EqualFold(parsed.Scheme, "file") {
+			files = append(files, parsed.Path)
+		}
+	}
+	firstFile := ""
+	if len(files) > 0 {
+		firstFile = files[0]
+	}
+	firstURL := ""
+	if len(urls) > 0 {
+		firstURL = urls[0]
+	}
+
+	args := []string{}
+	for _, token := range tokens {
+		switch token {
+		case "%F":
+			args = append(args, files...)
+			continue
+		case "%U":
+			args = append(args, urls...)
+			continue
+		case "%i":
+			if entry.Icon != "" {
+				args = append(args, "--icon", entry.Icon)
+			}
+			continue
+		}
+
+		var expanded strings.Builder
+		for i := 0; i < len(token); i++ {
+			if token[i] != '%' || i+1 >= len(token) {
+				expanded.WriteByte(token[i])
+				continue
+			}
+			i++
+			switch token[i] {
+			case '%':
+				expanded.WriteByte('%')
+			case 'f', 'F':
+				expanded.WriteString(firstFile)
+			case 'u', 'U':
+				expanded.WriteString(firstURL)
+			case 'c':
+				expanded.WriteString(entry.Name)
+			case 'k':
+				expanded.WriteString(desktopPath)
+			case 'i':
+				expanded.WriteString(entry.Icon)
+			}
+			// Deprecated codes (%d, %D, %n, %N, %v, %m) and unknown codes expand to nothing.
+		}
+
+		// A token that was only a field code disappears when it has no value, instead
+		// of passing an empty argument the app would treat as a file name.
+		if expanded.Len() == 0 && len(token) == 2 && token[0] == '%' {
+			continue
+		}
+		args = append(args, expanded.String())
+	}
+	return args
+}
+
+func startLinuxDesktopCommand(ctx context.Context, args []string, workingDir string) error {
+	cmd := shell.BuildCommand(args[0], nil, args[1:]...)
+	cmd.Stdout = util.GetLogger().GetWriter()
+	cmd.Stderr = util.GetLogger().GetWriter()
+	if workingDir != "" && util.IsDirExists(workingDir) {
+		cmd.Dir = workingDir
+	} else if homeDir, err := os.UserHomeDir(); err == nil {
+		cmd.Dir = homeDir
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	util.Go(ctx, "wait desktop entry command", func() {
+		// Reap the child so long-running launches do not leave zombies behind.
+		_ = cmd.Wait()
+	})
+	return nil
+}
+
+func linuxTargetMimeType(targetPath string) string {
+	if util.IsDirExists(targetPath) {
+		return "inode/directory"
+	}
+	mimeType := mime.TypeByExtension(strings.ToLower(filepath.Ext(targetPath)))
+	if mimeType == "" {
+		return "application/octet-stream"
+	}
+	if separator := strings.Index(mimeType, ";"); separator >= 0 {
+		mimeType = mimeType[:separator]
+	}
+	return strings.TrimSpace(mimeType)
+}
+
+// linuxMimeTypeAccepted matches exact types plus "image/*" style wildcards, which
+// some desktop entries use for whole media families.
+func linuxMimeTypeAccepted(acceptedTypes []string, mimeType string) bool {
+	mimeType = strings.ToLower(mimeType)
+	major := strings.SplitN(mimeType, "/", 2)[0]
+	for _, accepted := range acceptedTypes {
+		accepted = strings.ToLower(accepted)
+		if accepted == mimeType || accepted == major+"/*" {
+			return true
+		}
+	}
+	return false
+}
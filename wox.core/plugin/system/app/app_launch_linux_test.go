@@ -0,0 +1,86 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLinuxDesktopEntryReadsDesktopActions(t *testing.T) {
+	desktopPath := filepath.Join(t.TempDir(), "firefox.desktop")
+	content := `[Desktop Entry]
+Type=Application
+Name=Firefox
+Exec=firefox %u
+MimeType=text/html;x-scheme-handler/http;x-scheme-handler/https;
+Actions=new-window;new-private-window;broken;
+
+[Desktop Action new-private-window]
+Name=New Private Window
+Exec=firefox --private-window %u
+
+[Desktop Action new-window]
+Name=New Window
+Exec=firefox --new-window %u
+
+[Desktop Action broken]
+Name=Missing Exec
+`
+	require.NoError(t, os.WriteFile(desktopPath, []byte(content), 0o644))
+
+	entry, err := parseLinuxDesktopEntry(desktopPath)
+	require.NoError(t, err)
+	assert.Equal(t, "Firefox", entry.Name)
+	assert.Equal(t, []linuxDesktopAction{
+		{ID: "new-window", Name: "New Window", Exec: "firefox --new-window %u"},
+		{ID: "new-private-window", Name: "New Private Window", Exec: "firefox --private-window %u"},
+	}, entry.Actions)
+	assert.Equal(t, []string{"text/html", "x-scheme-handler/http", "x-scheme-handler/https"}, entry.MimeTypes)
+
+	info, err := (&LinuxRetriever{}).ParseAppInfo(t.Context(), desktopPath)
+	require.NoError(t, err)
+	assert.Equal(t, linuxLaunchTargetURL, info.LaunchTargetKind)
+	actionApps := expandDesktopActionApps(info)
+	require.Len(t, actionApps, 2)
+	assert.Equal(t, "Firefox - New Private Window", actionApps[1].Name)
+	assert.Equal(t, "new-private-window", actionApps[1].DesktopActionID)
+	assert.Equal(t, desktopPath, actionApps[1].Path)
+}
+
+func TestBuildLinuxDesktopLaunchCommandsExpandsFieldCodes(t *testing.T) {
+	entry := linuxDesktopEntry{Name: "Viewer", Icon: "viewer"}
+	targets := []appLaunchTarget{{Value: "/tmp/a b.png"}, {Value: "file:///tmp/c.png", IsURL: true}}
+
+	commands, err := buildLinuxDesktopLaunchCommands(`viewer --title="%c" %i %F`, entry, "/apps/viewer.desktop", targets)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"viewer", "--title=Viewer", "--icon", "viewer", "/tmp/a b.png", "/tmp/c.png"}}, commands)
+
+	// %f takes a single file, so every target gets its own instance.
+	commands, err = buildLinuxDesktopLaunchCommands("viewer --open %f", entry, "/apps/viewer.desktop", targets)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"viewer", "--open", "/tmp/a b.png"}, {"viewer", "--open", "/tmp/c.png"}}, commands)
+
+	// Unused field codes vanish instead of becoming empty arguments.
+	commands, err = buildLinuxDesktopLaunchCommands("browser %U %k 100%%", entry, "/apps/browser.desktop", nil)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"browser", "/apps/browser.desktop", "100%"}}, commands)
+}
+
+func TestLinuxRetrieverCanOpenTargetsFollowsMimeTypes(t *testing.T) {
+	retriever := &LinuxRetriever{}
+	browser := appInfo{LaunchTargetKind: linuxLaunchTargetURL, MimeTypes: []string{"text/html", "x-scheme-handler/https"}}
+	viewer := appInfo{LaunchTargetKind: linuxLaunchTargetFile, MimeTypes: []string{"image/*"}}
+	noMime := appInfo{LaunchTargetKind: linuxLaunchTargetFile}
+
+	url := []appLaunchTarget{{Value: "https://example.com", IsURL: true}}
+	image := []appLaunchTarget{{Value: "/tmp/photo.png"}}
+
+	assert.True(t, retriever.CanOpenTargets(browser, url))
+	assert.False(t, retriever.CanOpenTargets(viewer, url))
+	assert.True(t, retriever.CanOpenTargets(viewer, image))
+	assert.False(t, retriever.CanOpenTargets(browser, image))
+	assert.False(t, retriever.CanOpenTargets(noMime, image))
+}
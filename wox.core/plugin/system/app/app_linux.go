@@ -3,13 +3,13 @@ package app
 import (
 	"bufio"
 	"context"
-	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"wox/common"
 	"wox/plugin"
 	"wox/util"
@@ -25,14 +25,35 @@ type linuxDesktopEntry struct {
 	Icon            string
 	Type            string
 	TryExec         string
+	Exec            string
+	StartupWMClass  string
+	FlatpakID       string
+	SnapName        string
+	WorkingDir      string
+	Terminal        bool
+	MimeTypes       []string
+	Actions         []linuxDesktopAction
 	Hidden          bool
 	NoDisplay       bool
 	DesktopID       string
 	SearchableNames []string
 }
 
+// linuxDesktopAction is one "[Desktop Action <id>]" group, e.g. "New Private Window".
+type linuxDesktopAction struct {
+	ID   string
+	Name string
+	Exec string
+}
+
 type LinuxRetriever struct {
 	api plugin.API
+
+	runningProcesses      []processInfo
+	runningProcessesMutex sync.RWMutex // protects runningProcesses and lastProcessUpdateTime
+	lastProcessUpdateTime int64
+	processMatchers       sync.Map // map[string]linuxAppProcessMatcher: desktop path -> matcher
+	cpuSamples            sync.Map // map[string]linuxCPUSample: desktop path -> last CPU sample
 }
 
 func (a *LinuxRetriever) UpdateAPI(api plugin.API) {
@@ -124,6 +145,17 @@ func (a *LinuxRetriever) ParseAppInfo(ctx context.Context, path string) (appInfo
 		icon = common.NewWoxImageAbsolutePath(iconPath)
 	}
 
+	// Terminal launchers need a terminal emulator around their Exec line, which only
+	// gio knows how to pick, so they keep the plain launch without actions or targets.
+	var desktopActions []appDesktopAction
+	launchTargetKind := ""
+	if !entry.Terminal {
+		for _, desktopAction := range entry.Actions {
+			desktopActions = append(desktopActions, appDesktopAction{ID: desktopAction.ID, Name: desktopAction.Name})
+		}
+		launchTargetKind = linuxDesktopLaunchTargetKind(entry.Exec)
+	}
+
 	// Bug fix: the previous Linux parser always returned "not implemented", so even
 	// valid .desktop files such as Chrome never became searchable. Parsing the desktop
 	// entry keeps Linux aligned with the launcher metadata desktop environments already use.
@@ -135,6 +167,10 @@ func (a *LinuxRetriever) ParseAppInfo(ctx context.Context, path string) (appInfo
 		IconSourcePath:  iconPath,
 		Type:            AppTypeDesktop,
 		IsDefaultIcon:   icon.ImageData == appIcon.ImageData,
+
+		DesktopActions:   desktopActions,
+		LaunchTargetKind: launchTargetKind,
+		MimeTypes:        entry.MimeTypes,
 	}, nil
 }
 
@@ -151,14 +187,6 @@ func (a *LinuxRetriever) GetExtraApps(ctx context.Context) ([]appInfo, error) {
 	return []appInfo{}, nil
 }
 
-func (a *LinuxRetriever) GetPid(ctx context.Context, app appInfo) int {
-	return 0
-}
-
-func (a *LinuxRetriever) GetProcessStat(ctx context.Context, app appInfo) (*ProcessStat, error) {
-	return nil, errors.New("not implemented")
-}
-
 func (a *LinuxRetriever) OpenAppFolder(ctx context.Context, app appInfo) error {
 	return shell.OpenFileInFolder(app.Path)
 }
@@ -170,8 +198,10 @@ func parseLinuxDesktopEntry(desktopPath string) (linuxDesktopEntry, error) {
 	}
 	defer file.Close()
 
-	values := map[string]string{}
-	inDesktopEntry := false
+	// Desktop files are INI-like: the main group describes the app and each
+	// "[Desktop Action <id>]" group describes one additional launcher.
+	groups := map[string]map[string]string{}
+	currentGroup := ""
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -179,10 +209,13 @@ func parseLinuxDesktopEntry(desktopPath string) (linuxDesktopEntry, error) {
 			continue
 		}
 		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
-			inDesktopEntry = strings.EqualFold(line, "[Desktop Entry]")
+			currentGroup = strings.TrimSpace(line[1 : len(line)-1])
+			if strings.EqualFold(currentGroup, "Desktop Entry") {
+				currentGroup = "Desktop Entry"
+			}
 			continue
 		}
-		if !inDesktopEntry {
+		if currentGroup == "" {
 			continue
 		}
 
@@ -192,12 +225,20 @@ func parseLinuxDesktopEntry(desktopPath string) (linuxDesktopEntry, error) {
 		}
 		key := strings.TrimSpace(line[:separator])
 		value := strings.TrimSpace(line[separator+1:])
-		values[key] = unescapeLinuxDesktopValue(value)
+		if groups[currentGroup] == nil {
+			groups[currentGroup] = map[string]string{}
+		}
+		groups[currentGroup][key] = unescapeLinuxDesktopValue(value)
 	}
 	if err := scanner.Err(); err != nil {
 		return linuxDesktopEntry{}, err
 	}
 
+	values := groups["Desktop Entry"]
+	if values == nil {
+		values = map[string]string{}
+	}
+
 	displayName := resolveLinuxDesktopLocalizedValue("Name", values)
 	if displayName == "" {
 		displayName = strings.TrimSuffix(filepath.Base(desktopPath), filepath.Ext(desktopPath))
@@ -226,6 +267,14 @@ func parseLinuxDesktopEntry(desktopPath string) (linuxDesktopEntry, error) {
 		Icon:            strings.TrimSpace(values["Icon"]),
 		Type:            strings.TrimSpace(values["Type"]),
 		TryExec:         strings.TrimSpace(values["TryExec"]),
+		Exec:            strings.TrimSpace(values["Exec"]),
+		StartupWMClass:  strings.TrimSpace(values["StartupWMClass"]),
+		FlatpakID:       strings.TrimSpace(values["X-Flatpak"]),
+		SnapName:        strings.TrimSpace(values["X-SnapInstanceName"]),
+		WorkingDir:      strings.TrimSpace(values["Path"]),
+		Terminal:        parseLinuxDesktopBool(values["Terminal"]),
+		MimeTypes:       splitLinuxDesktopList(values["MimeType"]),
+		Actions:         parseLinuxDesktopActions(values["Actions"], groups),
 		Hidden:          parseLinuxDesktopBool(values["Hidden"]),
 		NoDisplay:       parseLinuxDesktopBool(values["NoDisplay"]),
 		DesktopID:       strings.TrimSuffix(filepath.Base(desktopPath), filepath.Ext(desktopPath)),
@@ -233,6 +282,42 @@ func parseLinuxDesktopEntry(desktopPath string) (linuxDesktopEntry, error) {
 	}, nil
 }
 
+// parseLinuxDesktopActions keeps the order of the Actions= key, which is the order
+// desktop environments show in their own jump lists. Actions without a group,
+// name or command are dropped as the spec requires.
+func parseLinuxDesktopActions(actionsValue string, groups map[string]map[string]string) []linuxDesktopAction {
+	actions := []linuxDesktopAction{}
+	for _, actionID := range splitLinuxDesktopList(actionsValue) {
+		values, ok := groups["Desktop Action "+actionID]
+		if !ok {
+			continue
+		}
+		name := resolveLinuxDesktopLocalizedValue("Name", values)
+		execValue := strings.TrimSpace(values["Exec"])
+		if name == "" || execValue == "" {
+			continue
+		}
+		actions = append(actions, linuxDesktopAction{
+			ID:   actionID,
+			Name: name,
+			Exec: execValue,
+		})
+	}
+	return actions
+}
+
+// splitLinuxDesktopList splits ";"-separated desktop entry values such as MimeType.
+func splitLinuxDesktopList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ";") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return util.UniqueStrings(items)
+}
+
 func resolveLinuxDesktopLocalizedValue(baseKey string, values map[string]string) string {
 	for _, candidate := range linuxDesktopLocaleKeys(baseKey) {
 		if value := strings.TrimSpace(values[candidate]); value != "" {
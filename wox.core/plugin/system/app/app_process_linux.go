@@ -0,0 +1,582 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"wox/util"
+)
+
+// linuxClockTicksPerSecond is USER_HZ, the unit of utime/stime in /proc/<pid>/stat.
+// The kernel ABI fixes it at 100 on every mainstream architecture, which avoids a
+// cgo sysconf call just for process stats.
+const linuxClockTicksPerSecond = 100
+
+// linuxTerminateGracePeriod is how long terminated processes get to exit on
+// SIGTERM before the survivors of the tree are force killed.
+const linuxTerminateGracePeriod = 3 * time.Second
+
+// linuxProcRoot is a variable so tests can point the scanner at a fake procfs.
+var linuxProcRoot = "/proc"
+
+// linuxInterpreterCommands launch the real app from a script argument, so matching
+// on the interpreter itself would mark every python or shell process as the app.
+var linuxInterpreterCommands = map[string]bool{
+	"sh":      true,
+	"bash":    true,
+	"dash":    true,
+	"zsh":     true,
+	"python":  true,
+	"python2": true,
+	"python3": true,
+	"perl":    true,
+	"ruby":    true,
+	"node":    true,
+	"java":    true,
+	"mono":    true,
+	"wine":    true,
+}
+
+type processInfo struct {
+	Pid  int
+	PPid int
+	// Path is the resolved /proc/<pid>/exe target; it stays empty when the link
+	// cannot be read.
+	Path     string
+	Comm     string
+	Args     []string
+	Cgroup   string
+	CPUTicks uint64
+}
+
+type linuxCPUSample struct {
+	ticks     uint64
+	timestamp int64
+}
+
+// linuxAppProcessMatcher describes how processes of one desktop entry are recognized.
+// Sandboxed apps are matched by the cgroup scope flatpak and snapd create for them,
+// because their executables live inside the sandbox and differ from the Exec line.
+type linuxAppProcessMatcher struct {
+	ExecPaths  []string
+	ExecName   string
+	ScriptName string
+	WMClass    string
+	FlatpakID  string
+	SnapName   string
+}
+
+func (a *LinuxRetriever) GetPid(ctx context.Context, app appInfo) int {
+	processes := a.getRunningProcesses(ctx, false)
+	return linuxMainProcessPid(a.matchAppProcesses(ctx, app, processes))
+}
+
+func (a *LinuxRetriever) GetProcessStat(ctx context.Context, app appInfo) (*ProcessStat, error) {
+	processes := a.getRunningProcesses(ctx, false)
+	matched := a.matchAppProcesses(ctx, app, processes)
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("no running processes found for %s", app.Name)
+	}
+
+	// Multi-process apps such as browsers spread work across helpers, so stats cover
+	// the whole tree below every matched process instead of only the main pid.
+	var totalTicks uint64
+	var totalMemory float64
+	pageSize := float64(os.Getpagesize())
+	for _, proc := range collectLinuxProcessTree(processes, matched) {
+		totalTicks += proc.CPUTicks
+		if residentPages, err := readLinuxProcessResidentPages(linuxProcRoot, proc.Pid); err == nil {
+			totalMemory += float64(residentPages) * pageSize
+		}
+	}
+
+	currentTimestamp := util.GetSystemTimestamp()
+	var cpuPercent float64
+	if value, exists := a.cpuSamples.Load(app.Path); exists {
+		cpuPercent = calculateLinuxCPUPercent(value.(linuxCPUSample), totalTicks, currentTimestamp, runtime.NumCPU())
+	}
+	a.cpuSamples.Store(app.Path, linuxCPUSample{ticks: totalTicks, timestamp: currentTimestamp})
+
+	return &ProcessStat{
+		CPU:    cpuPercent,
+		Memory: totalMemory,
+	}, nil
+}
+
+// TerminateApp asks every process of the app to exit, children first, and force
+// kills whatever is still alive once the grace period ends.
+func (a *LinuxRetriever) TerminateApp(ctx context.Context, app appInfo) error {
+	processes := a.getRunningProcesses(ctx, true)
+	matched := a.matchAppProcesses(ctx, app, processes)
+	if len(matched) == 0 && app.Pid > 0 {
+		for _, proc := range processes {
+			if proc.Pid == app.Pid {
+				matched = append(matched, proc)
+			}
+		}
+	}
+	if len(matched) == 0 {
+		return fmt.Errorf("no running processes found for %s", app.Name)
+	}
+
+	tree := collectLinuxProcessTree(processes, matched)
+	pids := make([]int, 0, len(tree))
+	for i := len(tree) - 1; i >= 0; i-- {
+		pids = append(pids, tree[i].Pid)
+	}
+
+	var signalled []int
+	var lastErr error
+	for _, pid := range pids {
+		if err := syscall.Kill(pid, syscall.SIGTERM); err != nil {
+			if !errors.Is(err, syscall.ESRCH) {
+				lastErr = err
+			}
+			continue
+		}
+		signalled = append(signalled, pid)
+	}
+	if len(signalled) == 0 {
+		if lastErr != nil {
+			return fmt.Errorf("failed to terminate %s: %w", app.Name, lastErr)
+		}
+		return nil
+	}
+
+	util.Go(ctx, "force kill remaining app processes", func() {
+		deadline := time.Now().Add(linuxTerminateGracePeriod)
+		for time.Now().Before(deadline) {
+			if !anyLinuxProcessAlive(signalled) {
+				return
+			}
+			time.Sleep(200 * time.Millisecond)
+		}
+		for _, pid := range signalled {
+			if err := syscall.Kill(pid, syscall.SIGKILL); err == nil {
+				util.GetLogger().Info(ctx, fmt.Sprintf("force killed process %d of %s", pid, app.Name))
+			}
+		}
+	})
+
+	return nil
+}
+
+func (a *LinuxRetriever) getRunningProcesses(ctx context.Context, forceRefresh bool) []processInfo {
+	a.runningProcessesMutex.RLock()
+	needUpdate := forceRefresh || util.GetSystemTimestamp()-a.lastProcessUpdateTime > 1000
+	processes := a.runningProcesses
+	a.runningProcessesMutex.RUnlock()
+	if !needUpdate {
+		return processes
+	}
+
+	a.runningProcessesMutex.Lock()
+	defer a.runningProcessesMutex.Unlock()
+	// Double-check after acquiring write lock
+	if forceRefresh || util.GetSystemTimestamp()-a.lastProcessUpdateTime > 1000 {
+		a.lastProcessUpdateTime = util.GetSystemTimestamp()
+		a.runningProcesses = readLinuxProcesses(ctx, linuxProcRoot)
+	}
+	return a.runningProcesses
+}
+
+func (a *LinuxRetriever) matchAppProcesses(ctx context.Context, app appInfo, processes []processInfo) []processInfo {
+	matcher, ok := a.getProcessMatcher(ctx, app)
+	if !ok {
+		return nil
+	}
+
+	var matched []processInfo
+	for _, proc := range processes {
+		if matcher.matches(proc) {
+			matched = append(matched, proc)
+		}
+	}
+	return matched
+}
+
+// getProcessMatcher caches the parsed Exec line per desktop file revision, because
+// the refresh loop asks for running state of every visible result each second.
+func (a *LinuxRetriever) getProcessMatcher(ctx context.Context, app appInfo) (linuxAppProcessMatcher, bool) {
+	if !strings.HasSuffix(strings.ToLower(app.Path), ".desktop") {
+		return linuxAppProcessMatcher{}, false
+	}
+
+	cacheKey := fmt.Sprintf("%s|%d", app.Path, app.LastModifiedUnix)
+	if value, exists := a.processMatchers.Load(cacheKey); exists {
+		matcher := value.(linuxAppProcessMatcher)
+		return matcher, !matcher.isEmpty()
+	}
+
+	entry, err := parseLinuxDesktopEntry(app.Path)
+	if err != nil {
+		util.GetLogger().Debug(ctx, fmt.Sprintf("failed to parse desktop entry %s for process matching: %s", app.Path, err.Error()))
+		return linuxAppProcessMatcher{}, false
+	}
+
+	matcher := newLinuxAppProcessMatcher(entry)
+	a.processMatchers.Store(cacheKey, matcher)
+	return matcher, !matcher.isEmpty()
+}
+
+func newLinuxAppProcessMatcher(entry linuxDesktopEntry) linuxAppProcessMatcher {
+	matcher := linuxAppProcessMatcher{
+		WMClass:   strings.ToLower(strings.TrimSpace(entry.StartupWMClass)),
+		FlatpakID: strings.TrimSpace(entry.FlatpakID),
+		SnapName:  strings.TrimSpace(entry.SnapName),
+	}
+
+	args := []string{}
+	for _, arg := range splitLinuxDesktopExec(entry.Exec) {
+		// Field codes such as %U are placeholders for launch arguments and never show
+		// up in the command line of a running process.
+		if strings.HasPrefix(arg, "%") {
+			continue
+		}
+		args = append(args, arg)
+	}
+	args = stripLinuxEnvCommand(args)
+	if len(args) == 0 {
+		return matcher
+	}
+
+	command := args[0]
+	commandName := filepath.Base(command)
+	switch {
+	case commandName == "flatpak":
+		if matcher.FlatpakID == "" {
+			matcher.FlatpakID = linuxSubcommandTarget(args[1:], "run")
+		}
+		return matcher
+	case commandName == "snap":
+		if matcher.SnapName == "" {
+			matcher.SnapName = strings.SplitN(linuxSubcommandTarget(args[1:], "run"), ".", 2)[0]
+		}
+		return matcher
+	case strings.HasPrefix(command, "/snap/bin/"):
+		if matcher.SnapName == "" {
+			matcher.SnapName = strings.SplitN(commandName, ".", 2)[0]
+		}
+		return matcher
+	}
+	if matcher.FlatpakID != "" || matcher.SnapName != "" {
+		return matcher
+	}
+
+	if linuxInterpreterCommands[commandName] {
+		for _, arg := range args[1:] {
+			if strings.HasPrefix(arg, "-") {
+				continue
+			}
+			matcher.ScriptName = filepath.Base(arg)
+			break
+		}
+		return matcher
+	}
+
+	matcher.ExecName = commandName
+	commandPath := command
+	if !filepath.IsAbs(commandPath) {
+		if lookedUp, err := exec.LookPath(commandPath); err == nil {
+			commandPath = lookedUp
+		} else {
+			commandPath = ""
+		}
+	}
+	if commandPath != "" {
+		matcher.ExecPaths = append(matcher.ExecPaths, filepath.Clean(commandPath))
+		// Launchers in /usr/bin are often symlinks into /opt or /usr/lib, and /proc
+		// reports the symlink target as the process executable.
+		if resolved, err := filepath.EvalSymlinks(commandPath); err == nil && resolved != commandPath {
+			matcher.ExecPaths = append(matcher.ExecPaths, resolved)
+		}
+	}
+
+	return matcher
+}
+
+func (m linuxAppProcessMatcher) isEmpty() bool {
+	return len(m.ExecPaths) == 0 && m.ExecName == "" && m.ScriptName == "" && m.WMClass == "" && m.FlatpakID == "" && m.SnapName == ""
+}
+
+func (m linuxAppProcessMatcher) matches(proc processInfo) bool {
+	if m.FlatpakID != "" {
+		return strings.Contains(proc.Cgroup, "app-flatpak-"+m.FlatpakID+"-")
+	}
+	if m.SnapName != "" {
+		return strings.Contains(proc.Cgroup, "snap."+m.SnapName+".") || strings.HasPrefix(proc.Path, "/snap/"+m.SnapName+"/")
+	}
+
+	argName := ""
+	if len(proc.Args) > 0 {
+		argName = filepath.Base(proc.Args[0])
+	}
+	if proc.Path != "" {
+		for _, execPath := range m.ExecPaths {
+			if proc.Path == execPath {
+				return true
+			}
+		}
+	}
+	if m.ExecName != "" && (argName == m.ExecName || filepath.Base(proc.Path) == m.ExecName) {
+		return true
+	}
+	if m.ScriptName != "" && len(proc.Args) > 1 {
+		for _, arg := range proc.Args[1:] {
+			if filepath.Base(arg) == m.ScriptName {
+				return true
+			}
+		}
+	}
+	if m.WMClass != "" {
+		// StartupWMClass names the window class, which toolkits derive from the
+		// binary name; comparing it to the process names avoids an X11 round trip.
+		for _, name := range []string{proc.Comm, argName, filepath.Base(proc.Path)} {
+			if name != "" && strings.ToLower(name) == m.WMClass {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// splitLinuxDesktopExec tokenizes an Exec value following the desktop entry spec:
+// arguments are separated by spaces, double quotes group an argument and a
+// backslash escapes the next character inside quotes. Field codes are kept.
+func splitLinuxDesktopExec(value string) []string {
+	args := []string{}
+	var current strings.Builder
+	hasToken := false
+	inQuotes := false
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case inQuotes && c == '\\' && i+1 < len(value):
+			i++
+			current.WriteByte(value[i])
+		case c == '"':
+			inQuotes = !inQuotes
+			hasToken = true
+		case !inQuotes && (c == ' ' || c == '\t'):
+			if hasToken {
+				args = append(args, current.String())
+				current.Reset()
+				hasToken = false
+			}
+		default:
+			current.WriteByte(c)
+			hasToken = true
+		}
+	}
+	if hasToken {
+		args = append(args, current.String())
+	}
+	return args
+}
+
+// stripLinuxEnvCommand drops an "env VAR=value" prefix so matching sees the real command.
+func stripLinuxEnvCommand(args []string) []string {
+	if len(args) == 0 || filepath.Base(args[0]) != "env" {
+		return args
+	}
+	args = args[1:]
+	for len(args) > 0 && (strings.Contains(args[0], "=") || strings.HasPrefix(args[0], "-")) {
+		args = args[1:]
+	}
+	return args
+}
+
+// linuxSubcommandTarget returns the first positional argument after subcommand,
+// e.g. the app id in "flatpak run --branch=stable org.gimp.GIMP".
+func linuxSubcommandTarget(args []string, subcommand string) string {
+	seenSubcommand := false
+	for _, arg := range args {
+		if !seenSubcommand {
+			seenSubcommand = arg == subcommand
+			continue
+		}
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+		return arg
+	}
+	return ""
+}
+
+func readLinuxProcesses(ctx context.Context, procRoot string) []processInfo {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		util.GetLogger().Debug(ctx, fmt.Sprintf("failed to list %s: %s", procRoot, err.Error()))
+		return nil
+	}
+
+	currentUid := os.Getuid()
+	processes := make([]processInfo, 0, len(entries))
+	for _, entry := range entries {
+		pid, convErr := strconv.Atoi(entry.Name())
+		if convErr != nil || !entry.IsDir() {
+			continue
+		}
+		// Only processes of the current user are listed: their exe links are
+		// readable and they are the only ones the terminate action may signal.
+		if info, infoErr := entry.Info(); infoErr != nil {
+			continue
+		} else if stat, ok := info.Sys().(*syscall.Stat_t); ok && int(stat.Uid) != currentUid {
+			continue
+		}
+
+		proc, ok := readLinuxProcess(procRoot, pid)
+		if ok {
+			processes = append(processes, proc)
+		}
+	}
+
+	sort.Slice(processes, func(i, j int) bool {
+		return processes[i].Pid < processes[j].Pid
+	})
+	return processes
+}
+
+func readLinuxProcess(procRoot string, pid int) (processInfo, bool) {
+	procDir := filepath.Join(procRoot, strconv.Itoa(pid))
+	statContent, err := os.ReadFile(filepath.Join(procDir, "stat"))
+	if err != nil {
+		return processInfo{}, false
+	}
+	proc, ok := parseLinuxProcessStat(string(statContent))
+	if !ok {
+		return processInfo{}, false
+	}
+
+	cmdline, err := os.ReadFile(filepath.Join(procDir, "cmdline"))
+	if err != nil || len(cmdline) == 0 {
+		// Kernel threads and zombies have no command line and never belong to an app.
+		return processInfo{}, false
+	}
+	proc.Args = strings.Split(strings.TrimRight(string(cmdline), "\x00"), "\x00")
+
+	if exePath, linkErr := os.Readlink(filepath.Join(procDir, "exe")); linkErr == nil {
+		proc.Path = strings.TrimSuffix(exePath, " (deleted)")
+	}
+	if cgroup, cgroupErr := os.ReadFile(filepath.Join(procDir, "cgroup")); cgroupErr == nil {
+		proc.Cgroup = string(cgroup)
+	}
+
+	return proc, true
+}
+
+// parseLinuxProcessStat reads pid, comm, ppid and CPU time from /proc/<pid>/stat.
+// comm is wrapped in parentheses and may itself contain spaces or ")", so the
+// remaining fields are split after the last closing parenthesis.
+func parseLinuxProcessStat(content string) (processInfo, bool) {
+	openIndex := strings.Index(content, "(")
+	closeIndex := strings.LastIndex(content, ")")
+	if openIndex <= 0 || closeIndex < openIndex {
+		return processInfo{}, false
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(content[:openIndex]))
+	if err != nil {
+		return processInfo{}, false
+	}
+	// Fields after comm start at field 3 (state); ppid is field 4, utime 14 and stime 15.
+	fields := strings.Fields(content[closeIndex+1:])
+	if len(fields) < 13 {
+		return processInfo{}, false
+	}
+	ppid, _ := strconv.Atoi(fields[1])
+	utime, _ := strconv.ParseUint(fields[11], 10, 64)
+	stime, _ := strconv.ParseUint(fields[12], 10, 64)
+
+	return processInfo{
+		Pid:      pid,
+		PPid:     ppid,
+		Comm:     content[openIndex+1 : closeIndex],
+		CPUTicks: utime + stime,
+	}, true
+}
+
+// readLinuxProcessResidentPages returns the resident set size from /proc/<pid>/statm.
+func readLinuxProcessResidentPages(procRoot string, pid int) (uint64, error) {
+	content, err := os.ReadFile(filepath.Join(procRoot, strconv.Itoa(pid), "statm"))
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(content))
+	if len(fields) < 2 {
+		return 0, fmt.Errorf("unexpected statm content for process %d", pid)
+	}
+	return strconv.ParseUint(fields[1], 10, 64)
+}
+
+// linuxMainProcessPid picks the oldest matched process whose parent is not part of
+// the app, which is the instance that owns the main window for forking apps.
+func linuxMainProcessPid(matched []processInfo) int {
+	matchedPids := map[int]bool{}
+	for _, proc := range matched {
+		matchedPids[proc.Pid] = true
+	}
+	for _, proc := range matched {
+		if !matchedPids[proc.PPid] {
+			return proc.Pid
+		}
+	}
+	if len(matched) > 0 {
+		return matched[0].Pid
+	}
+	return 0
+}
+
+// collectLinuxProcessTree returns the roots and all of their descendants, parents
+// before children.
+func collectLinuxProcessTree(processes []processInfo, roots []processInfo) []processInfo {
+	children := map[int][]processInfo{}
+	for _, proc := range processes {
+		children[proc.PPid] = append(children[proc.PPid], proc)
+	}
+
+	visited := map[int]bool{}
+	tree := make([]processInfo, 0, len(roots))
+	queue := append([]processInfo{}, roots...)
+	for len(queue) > 0 {
+		proc := queue[0]
+		queue = queue[1:]
+		if visited[proc.Pid] {
+			continue
+		}
+		visited[proc.Pid] = true
+		tree = append(tree, proc)
+		queue = append(queue, children[proc.Pid]...)
+	}
+	return tree
+}
+
+func calculateLinuxCPUPercent(lastSample linuxCPUSample, ticks uint64, timestamp int64, numCPU int) float64 {
+	timeElapsed := timestamp - lastSample.timestamp
+	if timeElapsed <= 0 || ticks < lastSample.ticks || numCPU <= 0 {
+		return 0
+	}
+
+	// CPU% = (CPU time in ms / elapsed time in ms) * 100 / number of CPUs
+	cpuTimeMs := float64(ticks-lastSample.ticks) * 1000.0 / linuxClockTicksPerSecond
+	rawPercent := (cpuTimeMs / float64(timeElapsed)) * 100.0
+	return rawPercent / float64(numCPU)
+}
+
+func anyLinuxProcessAlive(pids []int) bool {
+	for _, pid := range pids {
+		if syscall.Kill(pid, 0) == nil {
+			return true
+		}
+	}
+	return false
+}
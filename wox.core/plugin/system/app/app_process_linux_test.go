@@ -0,0 +1,83 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLinuxAppProcessMatcherHandlesWrappers(t *testing.T) {
+	flatpak := newLinuxAppProcessMatcher(linuxDesktopEntry{Exec: `/usr/bin/flatpak run --branch=stable --arch=x86_64 --command=gimp org.gimp.GIMP %U`})
+	assert.Equal(t, "org.gimp.GIMP", flatpak.FlatpakID)
+	assert.Empty(t, flatpak.ExecName)
+
+	snap := newLinuxAppProcessMatcher(linuxDesktopEntry{Exec: `env BAMF_DESKTOP_FILE_HINT=/var/lib/snapd/desktop/applications/firefox_firefox.desktop /snap/bin/firefox %u`})
+	assert.Equal(t, "firefox", snap.SnapName)
+
+	script := newLinuxAppProcessMatcher(linuxDesktopEntry{Exec: `python3 -O "/opt/my tool/main.py" %F`, StartupWMClass: "MyTool"})
+	assert.Equal(t, "main.py", script.ScriptName)
+	assert.Empty(t, script.ExecName)
+	assert.Equal(t, "mytool", script.WMClass)
+
+	native := newLinuxAppProcessMatcher(linuxDesktopEntry{Exec: `/opt/editor/bin/editor --new-window %F`})
+	assert.Equal(t, "editor", native.ExecName)
+	assert.Contains(t, native.ExecPaths, "/opt/editor/bin/editor")
+}
+
+func TestLinuxAppProcessMatcherMatches(t *testing.T) {
+	flatpak := linuxAppProcessMatcher{FlatpakID: "org.gimp.GIMP"}
+	assert.True(t, flatpak.matches(processInfo{Args: []string{"/app/bin/gimp-2.10"}, Cgroup: "0::/user.slice/user-1000.slice/user@1000.service/app.slice/app-flatpak-org.gimp.GIMP-1234.scope\n"}))
+	assert.False(t, flatpak.matches(processInfo{Args: []string{"/usr/bin/gimp"}, Cgroup: "0::/user.slice/app-gnome-gimp-99.scope\n"}))
+
+	snap := linuxAppProcessMatcher{SnapName: "firefox"}
+	assert.True(t, snap.matches(processInfo{Path: "/snap/firefox/4136/usr/lib/firefox/firefox"}))
+
+	script := linuxAppProcessMatcher{ScriptName: "main.py"}
+	assert.True(t, script.matches(processInfo{Args: []string{"python3", "-O", "/opt/my tool/main.py"}}))
+	assert.False(t, script.matches(processInfo{Args: []string{"python3", "/opt/other/run.py"}}))
+
+	native := linuxAppProcessMatcher{ExecName: "editor", ExecPaths: []string{"/opt/editor/bin/editor"}, WMClass: "code"}
+	assert.True(t, native.matches(processInfo{Path: "/opt/editor/bin/editor", Args: []string{"/proc/self/exe", "--type=renderer"}}))
+	assert.True(t, native.matches(processInfo{Comm: "Code", Args: []string{"/usr/share/code/code"}}))
+	assert.False(t, native.matches(processInfo{Path: "/usr/bin/vim", Comm: "vim", Args: []string{"vim"}}))
+}
+
+func TestLinuxRetrieverReadsProcessTreeFromProc(t *testing.T) {
+	procRoot := t.TempDir()
+	writeFakeLinuxProcess(t, procRoot, 100, "100 (editor) S 1 100 100 0 -1 4194560 0 0 0 0 50 25 0 0 20 0 1 0 1 0 0", "/opt/editor/bin/editor\x00", "2048 300 0 0 0 0 0")
+	writeFakeLinuxProcess(t, procRoot, 120, "120 (editor (helper)) S 100 100 100 0 -1 4194560 0 0 0 0 10 5 0 0 20 0 1 0 1 0 0", "/opt/editor/bin/editor-helper\x00--type=gpu\x00", "1024 100 0 0 0 0 0")
+	writeFakeLinuxProcess(t, procRoot, 130, "130 (vim) S 1 130 130 0 -1 4194560 0 0 0 0 1 1 0 0 20 0 1 0 1 0 0", "vim\x00", "512 50 0 0 0 0 0")
+
+	originalProcRoot := linuxProcRoot
+	linuxProcRoot = procRoot
+	t.Cleanup(func() { linuxProcRoot = originalProcRoot })
+
+	desktopPath := filepath.Join(t.TempDir(), "editor.desktop")
+	require.NoError(t, os.WriteFile(desktopPath, []byte("[Desktop Entry]\nType=Application\nName=Editor\nExec=/opt/editor/bin/editor %F\n"), 0o644))
+
+	ctx := context.Background()
+	retriever := &LinuxRetriever{}
+	app := appInfo{Name: "Editor", Path: desktopPath, Type: AppTypeDesktop}
+	assert.Equal(t, 100, retriever.GetPid(ctx, app))
+
+	stat, err := retriever.GetProcessStat(ctx, app)
+	require.NoError(t, err)
+	assert.Equal(t, float64(400*os.Getpagesize()), stat.Memory, "memory should include the helper child")
+	assert.Zero(t, stat.CPU, "the first sample has no previous reading to diff against")
+
+	assert.InDelta(t, 25.0, calculateLinuxCPUPercent(linuxCPUSample{ticks: 90, timestamp: 1000}, 140, 2000, 2), 0.001)
+}
+
+func writeFakeLinuxProcess(t *testing.T, procRoot string, pid int, stat string, cmdline string, statm string) {
+	t.Helper()
+	procDir := filepath.Join(procRoot, strconv.Itoa(pid))
+	require.NoError(t, os.MkdirAll(procDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(procDir, "stat"), []byte(stat), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(procDir, "cmdline"), []byte(cmdline), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(procDir, "statm"), []byte(statm), 0o644))
+}
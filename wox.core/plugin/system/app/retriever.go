@@ -32,3 +32,15 @@ type extraAppPathRetriever interface {
 type appModifiedUnixRetriever interface {
 	GetAppModifiedUnix(appPath string, fileInfo os.FileInfo) int64
 }
+
+// appTerminator lets platforms stop every process of an app instead of only the main pid.
+type appTerminator interface {
+	TerminateApp(ctx context.Context, app appInfo) error
+}
+
+// appTargetLauncher lets platforms launch apps with arguments: desktop entry actions
+// and the files or URLs of a selection query.
+type appTargetLauncher interface {
+	CanOpenTargets(app appInfo, targets []appLaunchTarget) bool
+	LaunchApp(ctx context.Context, app appInfo, actionID string, targets []appLaunchTarget) error
+}
@@ -7,6 +7,7 @@ import (
 	"wox/database"
 	"wox/plugin"
 	"wox/setting/definition"
+	"wox/util/filesearch"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -85,6 +86,20 @@ func (a *attentionActionTestAPI) Screenshot(ctx context.Context, option plugin.S
 	return plugin.ScreenshotResult{}
 }
 
+func (a *attentionActionTestAPI) RegisterHotkey(ctx context.Context, hotkey plugin.PluginHotkey) plugin.RegisterHotkeyResult {
+	return plugin.RegisterHotkeyResult{}
+}
+
+func (a *attentionActionTestAPI) UnregisterHotkey(ctx context.Context, id string) {
+}
+
+func (a *attentionActionTestAPI) RegisterContentExtractor(ctx context.Context, extractor filesearch.ContentExtractor) error {
+	return nil
+}
+
+func (a *attentionActionTestAPI) UnregisterContentExtractor(ctx context.Context, name string) {
+}
+
 func newSystemAttentionTestManager(t *testing.T) *plugin.AttentionManager {
 	t.Helper()
 
@@ -9,6 +9,7 @@ import (
 	"wox/common"
 	"wox/plugin"
 	"wox/setting/definition"
+	"wox/util/filesearch"
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/assert"
@@ -144,3 +145,17 @@ func (m *mockAPI) Copy(ctx context.Context, params plugin.CopyParams) {
 func (m *mockAPI) Screenshot(ctx context.Context, option plugin.ScreenshotOption) plugin.ScreenshotResult {
 	return plugin.ScreenshotResult{}
 }
+
+func (m *mockAPI) RegisterHotkey(ctx context.Context, hotkey plugin.PluginHotkey) plugin.RegisterHotkeyResult {
+	return plugin.RegisterHotkeyResult{}
+}
+
+func (m *mockAPI) UnregisterHotkey(ctx context.Context, id string) {
+}
+
+func (m *mockAPI) RegisterContentExtractor(ctx context.Context, extractor filesearch.ContentExtractor) error {
+	return nil
+}
+
+func (m *mockAPI) UnregisterContentExtractor(ctx context.Context, name string) {
+}
@@ -3,7 +3,10 @@ package system
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
@@ -11,22 +14,29 @@ import (
 	"wox/ai"
 	_ "wox/ai/builtintool"
 	aitool "wox/ai/builtintool/wox"
+	"wox/cloudsync"
 	"wox/common"
+	"wox/database"
 	"wox/plugin"
 	"wox/setting"
 	"wox/setting/definition"
 	"wox/util"
 	"wox/util/selection"
+	"wox/util/shell"
 
 	"github.com/google/uuid"
 	"github.com/samber/lo"
 )
 
 var aiChatIcon = common.PluginAIChatIcon
-var aiChatsSettingKey = "ai_chats"
 
 const aiChatEnterChatModeActionId = "__wox_internal_enter_chat_mode__"
 
+const aiChatSearchResultLimit = 20
+
+// aiChatSemanticSource names chat messages in the semantic index. Document ids are "chatId/messageId".
+const aiChatSemanticSource = "chat"
+
 const (
 	aiChatCompactionTriggerEstimatedTokens = 24000
 	aiChatCompactionRecentTargetTokens     = 12000
@@ -38,11 +48,23 @@ func init() {
 }
 
 type AIChatPlugin struct {
+	// chatsMutex guards chats, which cloud sync replaces from its own goroutine.
+	chatsMutex  sync.RWMutex
 	chats       []common.AIChatData
+	chatStore   *database.AIChatStore
 	mcpServers  []common.AIChatMCPServerConfig
 	mcpToolsMap []common.MCPTool
 	api         plugin.API
 
+	// mcpPrompts and mcpResources are listed with the tools on every MCP reload.
+	mcpCatalogMutex sync.RWMutex
+	mcpPrompts      []common.MCPPrompt
+	mcpResources    []common.MCPResource
+	// mcpReferenceCache keeps expanded prompt and resource tags per chat, keyed
+	// by chatId, conversationId and tag, so earlier messages are not fetched again
+	// on every request and the prompt prefix stays stable.
+	mcpReferenceCache *util.HashMap[string, string]
+
 	// activeChatCancels maps chatId to the active streaming cancellation entry.
 	activeChatCancels sync.Map
 }
@@ -135,21 +157,26 @@ func (r *AIChatPlugin) configurePluginBuiltinToolHooks() {
 func (r *AIChatPlugin) Init(ctx context.Context, initParams plugin.InitParams) {
 	r.api = initParams.API
 	r.mcpServers = []common.AIChatMCPServerConfig{}
+	r.mcpReferenceCache = util.NewHashMap[string, string]()
 
 	// Configure hooks that let builtin tools call back into the plugin manager.
 	r.configurePluginBuiltinToolHooks()
 
-	chats, err := r.loadChats(ctx)
+	r.chatStore = database.NewAIChatStore(database.GetDB())
+	chats, err := r.chatStore.ListChats(ctx)
 	if err != nil {
-		r.chats = []common.AIChatData{}
+		chats = []common.AIChatData{}
 		r.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("AI: Failed to load chats: %s", err.Error()))
-	} else {
-		r.chats = chats
 	}
+	r.chatsMutex.Lock()
+	r.chats = chats
+	r.chatsMutex.Unlock()
 
 	// Providers may have been removed while Wox was closed; drop stale defaults now.
 	r.EnsureDefaultModelValid(ctx)
 
+	ai.GetSemanticIndex().RegisterSource(ai.SemanticSource{Name: aiChatSemanticSource, Documents: r.semanticDocuments})
+
 	util.Go(ctx, "reload AI skills", func() {
 		// Skill discovery can touch remote cache paths. Keep it off the startup path so UI readiness
 		// is not blocked by stale or relocated skill directories.
@@ -232,12 +259,15 @@ func (r *AIChatPlugin) GetDefaultModel(ctx context.Context) common.Model {
 	}
 
 	// Prefer the most recently updated chat whose provider still exists.
-	for _, chat := range r.chats {
-		if isAIModelProviderConfigured(ctx, chat.Model) {
+	r.chatsMutex.RLock()
+	chatModels := lo.Map(r.chats, func(chat common.AIChatData, _ int) common.Model { return chat.Model })
+	r.chatsMutex.RUnlock()
+	for _, chatModel := range chatModels {
+		if isAIModelProviderConfigured(ctx, chatModel) {
 			return common.Model{
-				Name:          chat.Model.Name,
-				Provider:      chat.Model.Provider,
-				ProviderAlias: chat.Model.ProviderAlias,
+				Name:          chatModel.Name,
+				Provider:      chatModel.Provider,
+				ProviderAlias: chatModel.ProviderAlias,
 			}
 		}
 	}
@@ -303,15 +333,22 @@ func (r *AIChatPlugin) ReloadMCPServers(ctx context.Context, notifyUI bool) {
 	}
 
 	var mcpTools []common.MCPTool
+	var mcpPrompts []common.MCPPrompt
+	var mcpResources []common.MCPResource
+	var enabledServers []string
 	for _, mcpServer := range r.mcpServers {
 		if mcpServer.Disabled {
 			r.api.Log(ctx, plugin.LogLevelInfo, fmt.Sprintf("AI: MCP server %s is disabled", mcpServer.Name))
 			continue
 		}
+		enabledServers = append(enabledServers, mcpServer.Name)
 
 		tools, err := ai.MCPListTools(ctx, mcpServer)
 		if err != nil {
+			// The server is unreachable, listing prompts and resources would only
+			// wait for the same timeout again.
 			r.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("AI: Failed to list tool: %s", err.Error()))
+			continue
 		}
 
 		r.api.Log(ctx, plugin.LogLevelInfo, fmt.Sprintf("AI: Found %d tools for MCP server %s", len(tools), mcpServer.Name))
@@ -319,7 +356,22 @@ func (r *AIChatPlugin) ReloadMCPServers(ctx context.Context, notifyUI bool) {
 			r.api.Log(ctx, plugin.LogLevelInfo, fmt.Sprintf("AI: %s tool %s", mcpServer.Name, tool.Name))
 			mcpTools = append(mcpTools, tool)
 		}
+
+		prompts, err := ai.MCPListPrompts(ctx, mcpServer)
+		if err != nil {
+			r.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("AI: Failed to list prompts of MCP server %s: %s", mcpServer.Name, err.Error()))
+		}
+		mcpPrompts = append(mcpPrompts, prompts...)
+
+		resources, err := ai.MCPListResources(ctx, mcpServer)
+		if err != nil {
+			r.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("AI: Failed to list resources of MCP server %s: %s", mcpServer.Name, err.Error()))
+		}
+		mcpResources = append(mcpResources, resources...)
+		r.api.Log(ctx, plugin.LogLevelInfo, fmt.Sprintf("AI: Found %d prompts and %d resources for MCP server %s", len(prompts), len(resources), mcpServer.Name))
 	}
+	// Close sessions of removed or disabled servers, so stdio processes do not linger.
+	ai.CloseMCPServers(ctx, enabledServers)
 
 	tools := lo.Map(mcpTools, func(mcpTool common.MCPTool, _ int) common.Tool {
 		return mcpTool.ToTool()
@@ -329,46 +381,96 @@ func (r *AIChatPlugin) ReloadMCPServers(ctx context.Context, notifyUI bool) {
 	ai.GetToolRegistry().ReplaceSource(common.ToolSourceMCP, tools)
 	r.mcpToolsMap = mcpTools
 
+	r.mcpCatalogMutex.Lock()
+	r.mcpPrompts = mcpPrompts
+	r.mcpResources = mcpResources
+	r.mcpCatalogMutex.Unlock()
+
 	if notifyUI {
 		plugin.GetPluginManager().GetUI().ReloadChatResources(ctx, "tools")
 	}
 }
 
+// GetMCPCatalog returns the prompts and resources found by the last MCP reload
+// with the current connection state of every configured server.
+func (r *AIChatPlugin) GetMCPCatalog(ctx context.Context) common.MCPCatalog {
+	r.mcpCatalogMutex.RLock()
+	catalog := common.MCPCatalog{
+		Prompts:   append([]common.MCPPrompt(nil), r.mcpPrompts...),
+		Resources: append([]common.MCPResource(nil), r.mcpResources...),
+	}
+	r.mcpCatalogMutex.RUnlock()
+
+	for _, mcpServer := range r.mcpServers {
+		catalog.Servers = append(catalog.Servers, ai.GetMCPServerHealth(mcpServer))
+	}
+	return catalog
+}
+
 func (r *AIChatPlugin) loadMCPServers(ctx context.Context) ([]common.AIChatMCPServerConfig, error) {
 	return setting.GetSettingManager().GetWoxSetting(ctx).AIMCPServers.Get(), nil
 }
 
-func (r *AIChatPlugin) loadChats(ctx context.Context) ([]common.AIChatData, error) {
-	chats := []common.AIChatData{}
-	chatsJson := r.api.GetSetting(ctx, aiChatsSettingKey)
-	if chatsJson == "" {
-		return []common.AIChatData{}, nil
+// saveChat writes one chat with all of its conversations to the chat store.
+func (r *AIChatPlugin) saveChat(ctx context.Context, aiChatData common.AIChatData) {
+	snapshot := cloneAIChatDataForState(aiChatData)
+	if err := r.chatStore.SaveChat(ctx, snapshot); err != nil {
+		r.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("AI: Failed to save chat %s: %s", aiChatData.Id, err.Error()))
+		return
 	}
+	r.logChatSync(ctx, snapshot)
+	ai.GetSemanticIndex().Refresh()
+}
 
-	err := json.Unmarshal([]byte(chatsJson), &chats)
-	if err != nil {
-		return []common.AIChatData{}, err
+// saveChatConversations writes the chat row and only the given conversations,
+// so a long chat is not rewritten for every tool call while it streams. It does
+// not log for cloud sync: the chat is logged once by saveChat when it settles.
+func (r *AIChatPlugin) saveChatConversations(ctx context.Context, aiChatData common.AIChatData, conversationIds ...string) bool {
+	snapshot := cloneAIChatDataForState(aiChatData)
+	if err := r.chatStore.SaveConversations(ctx, snapshot, conversationIds...); err != nil {
+		r.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("AI: Failed to save conversations of chat %s: %s", aiChatData.Id, err.Error()))
+		return false
 	}
-
-	sort.Slice(chats, func(i, j int) bool {
-		return chats[i].UpdatedAt > chats[j].UpdatedAt
-	})
-
-	return chats, nil
+	return true
 }
 
-func (r *AIChatPlugin) saveChats(ctx context.Context) {
-	persistedChats := make([]common.AIChatData, len(r.chats))
-	for i, chat := range r.chats {
-		persistedChats[i] = cloneAIChatDataForState(chat)
+// logChatSync queues the whole chat for cloud sync. Callers log settled chats
+// only, so a streaming answer produces one oplog instead of one per save.
+func (r *AIChatPlugin) logChatSync(ctx context.Context, aiChatData common.AIChatData) {
+	if err := cloudsync.LogEntityUpsert(ctx, cloudsync.EntityAIChat, aiChatData.Id, aiChatData.Id, aiChatData); err != nil {
+		util.GetLogger().Warn(ctx, fmt.Sprintf("AI: Failed to log chat %s for cloud sync: %s", aiChatData.Id, err.Error()))
 	}
-	chatsJson, err := json.Marshal(persistedChats)
+}
+
+// ReloadChats re-reads persisted chats after cloud sync changed them. Chats
+// that are streaming keep their in-memory copy so the running answer is not lost.
+func (r *AIChatPlugin) ReloadChats(ctx context.Context) error {
+	chats, err := r.chatStore.ListChats(ctx)
 	if err != nil {
-		r.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("AI: Failed to marshal chats: %s", err.Error()))
-		return
+		return err
 	}
 
-	r.api.SaveSetting(ctx, aiChatsSettingKey, string(chatsJson), false)
+	r.chatsMutex.Lock()
+	for _, chat := range r.chats {
+		if !r.isChatStreaming(chat.Id) {
+			continue
+		}
+		_, index, found := lo.FindIndexOf(chats, func(item common.AIChatData) bool {
+			return item.Id == chat.Id
+		})
+		if found {
+			chats[index] = chat
+		} else {
+			chats = append(chats, chat)
+		}
+	}
+	sort.Slice(chats, func(i, j int) bool {
+		return chats[i].UpdatedAt > chats[j].UpdatedAt
+	})
+	r.chats = chats
+	r.chatsMutex.Unlock()
+	ai.GetSemanticIndex().Refresh()
+	return nil
 }
 
 func (r *AIChatPlugin) GetAllTools(ctx context.Context) []common.MCPTool {
@@ -417,11 +519,11 @@ func (r *AIChatPlugin) Chat(ctx context.Context, aiChatData common.AIChatData, c
 	r.SetDefaultModel(ctx, aiChatData.Model)
 
 	r.appendOrUpdateChatData(aiChatData)
-	r.saveChats(ctx)
+	r.saveChat(ctx, aiChatData)
 
 	runtimeContext := r.buildRuntimeRequestContext(ctx, &aiChatData)
 	r.appendOrUpdateChatData(aiChatData)
-	r.saveChats(ctx)
+	r.saveChat(ctx, aiChatData)
 
 	// AIChatStream schedules the loop asynchronously, so keep the cancel entry
 	// registered until a terminal stream callback cleans up this exact entry.
@@ -438,6 +540,9 @@ func (r *AIChatPlugin) Chat(ctx context.Context, aiChatData common.AIChatData, c
 	var lastChatResponseAt int64
 	var responseId = uuid.NewString()
 	var prevStatus common.ChatStreamDataStatus
+	// unsavedConversationIds collects conversations changed since the last
+	// write, flushed whenever the stream reaches a non-streaming status.
+	unsavedConversationIds := map[string]bool{}
 
 	snapshotChatData := func(force bool) (common.AIChatData, bool) {
 		now := util.GetSystemTimestamp()
@@ -481,6 +586,7 @@ func (r *AIChatPlugin) Chat(ctx context.Context, aiChatData common.AIChatData, c
 
 		// Update conversations and sync to UI.
 		if streamResult.Data != "" || streamResult.Reasoning != "" {
+			unsavedConversationIds[responseId] = true
 			r.appendOrUpdateConversationAtEnd(&aiChatData, common.Conversation{
 				Id:        responseId,
 				Role:      common.ConversationRoleAssistant,
@@ -494,6 +600,7 @@ func (r *AIChatPlugin) Chat(ctx context.Context, aiChatData common.AIChatData, c
 				if isInternalChatToolCall(toolCall) {
 					continue
 				}
+				unsavedConversationIds[toolCall.Id] = true
 				r.appendOrUpdateConversation(&aiChatData, common.Conversation{
 					Id:           toolCall.Id,
 					Role:         common.ConversationRoleTool,
@@ -520,8 +627,21 @@ func (r *AIChatPlugin) Chat(ctx context.Context, aiChatData common.AIChatData, c
 		// still persist the chat data so the user can see the conversation
 		// when they reopen the chat later.
 		isTerminalError := streamResult.Status == common.ChatStreamStatusError
+
+		var pendingChat common.AIChatData
+		var pendingConversationIds []string
+		if forceSend && !isFinished && !isTerminalError && len(unsavedConversationIds) > 0 {
+			pendingChat = cloneAIChatDataForState(aiChatData)
+			pendingConversationIds = lo.Keys(unsavedConversationIds)
+			unsavedConversationIds = map[string]bool{}
+		}
 		chatDataMu.Unlock()
 
+		if len(pendingConversationIds) > 0 {
+			r.appendOrUpdateChatData(pendingChat)
+			r.saveChatConversations(ctx, pendingChat, pendingConversationIds...)
+		}
+
 		if shouldSendSnapshot {
 			plugin.GetPluginManager().GetUI().SendChatResponse(ctx, snapshot)
 		}
@@ -530,7 +650,7 @@ func (r *AIChatPlugin) Chat(ctx context.Context, aiChatData common.AIChatData, c
 			cleanupActiveCancel()
 			r.api.Log(ctx, plugin.LogLevelInfo, fmt.Sprintf("AI: chat stream finished: %s", streamResult.Data))
 			r.appendOrUpdateChatData(finishedSnapshot)
-			r.saveChats(ctx)
+			r.saveChat(ctx, finishedSnapshot)
 
 			// Only summarize the chat title if there is no tool call. If any
 			// tool calls are present, the loop has more context to add.
@@ -542,7 +662,7 @@ func (r *AIChatPlugin) Chat(ctx context.Context, aiChatData common.AIChatData, c
 			cleanupActiveCancel()
 			r.api.Log(ctx, plugin.LogLevelInfo, fmt.Sprintf("AI: chat stream ended with error, saving chat data: %s", streamResult.Data))
 			r.appendOrUpdateChatData(snapshot)
-			r.saveChats(ctx)
+			r.saveChat(ctx, snapshot)
 		}
 	})
 
@@ -558,7 +678,7 @@ func (r *AIChatPlugin) Chat(ctx context.Context, aiChatData common.AIChatData, c
 		aiChatData.IsStreaming = false
 		plugin.GetPluginManager().GetUI().SendChatResponse(ctx, aiChatData)
 		r.appendOrUpdateChatData(aiChatData)
-		r.saveChats(ctx)
+		r.saveChat(ctx, aiChatData)
 		r.api.Notify(ctx, r.api.GetTranslation(ctx, "ui_ai_chat_failed_to_chat"))
 	}
 }
@@ -589,12 +709,6 @@ func (r *AIChatPlugin) buildRuntimeRequestContext(ctx context.Context, aiChatDat
 func (r *AIChatPlugin) composeRuntimeConversations(ctx context.Context, aiChatData common.AIChatData, compactionEntry *common.AIChatCompactionEntry, expandCurrentSkillRefs bool) []common.Conversation {
 	recentConversations := r.recentConversationsForRuntime(ctx, aiChatData.Conversations, compactionEntry)
 	runtimeConversations := make([]common.Conversation, 0, len(recentConversations)+4)
-	runtimeConversations = append(runtimeConversations, common.Conversation{
-		Id:        uuid.NewString(),
-		Role:      common.ConversationRoleSystem,
-		Text:      formatRuntimeTimePrompt(util.GetSystemTime()),
-		Timestamp: util.GetSystemTimestamp(),
-	})
 	if availableSkillsPrompt := ai.FormatAvailableSkillsPrompt(ai.GetSkillRegistry().ListEnabled()); availableSkillsPrompt != "" {
 		runtimeConversations = append(runtimeConversations, common.Conversation{
 			Id:        uuid.NewString(),
@@ -619,12 +733,23 @@ func (r *AIChatPlugin) composeRuntimeConversations(ctx context.Context, aiChatDa
 			Timestamp: util.GetSystemTimestamp(),
 		})
 	}
+	// The clock changes on every request, so it goes after the skill and tool
+	// prompts to keep them a stable prefix for provider prompt caching.
+	runtimeConversations = append(runtimeConversations, common.Conversation{
+		Id:        uuid.NewString(),
+		Role:      common.ConversationRoleSystem,
+		Text:      formatRuntimeTimePrompt(util.GetSystemTime()),
+		Timestamp: util.GetSystemTimestamp(),
+	})
 
 	currentSkillMessageId := ""
 	if expandCurrentSkillRefs {
 		currentSkillMessageId = lastUserConversationId(recentConversations)
 	}
 	for _, conversation := range recentConversations {
+		// Prompt and resource tags are expanded in every message, unlike skills,
+		// because the attached content is part of what the user said.
+		conversation = r.withMessageMCPReferences(ctx, aiChatData.Id, conversation)
 		if conversation.Id == currentSkillMessageId {
 			runtimeConversations = append(runtimeConversations, r.withMessageSkillReferences(ctx, conversation))
 		} else {
@@ -768,6 +893,64 @@ func (r *AIChatPlugin) withMessageSkillReferences(ctx context.Context, conversat
 	return cloned
 }
 
+// withMessageMCPReferences replaces {prompt:...} and {resource:...} tags with
+// the rendered prompt and the resource content for the provider call.
+func (r *AIChatPlugin) withMessageMCPReferences(ctx context.Context, chatId string, conversation common.Conversation) common.Conversation {
+	if conversation.Role != common.ConversationRoleUser {
+		return conversation
+	}
+	references := ai.ParseMCPReferences(conversation.Text)
+	if len(references) == 0 {
+		return conversation
+	}
+
+	var prompts []string
+	var resources []string
+	for _, reference := range references {
+		cacheKey := chatId + "\x00" + conversation.Id + "\x00" + reference.Tag
+		content, cached := r.mcpReferenceCache.Load(cacheKey)
+		if !cached {
+			var err error
+			content, err = r.resolveMCPReference(ctx, reference)
+			if err != nil {
+				// Tell the model instead of dropping the tag silently, and retry on the next request.
+				r.api.Log(ctx, plugin.LogLevelWarning, fmt.Sprintf("AI: failed to resolve MCP %s %s of server %s: %s", reference.Kind, reference.Name, reference.ServerName, err.Error()))
+				content = fmt.Sprintf("[MCP %s %s of server %s is unavailable: %s]", reference.Kind, reference.Name, reference.ServerName, err.Error())
+			} else {
+				r.mcpReferenceCache.Store(cacheKey, content)
+			}
+		}
+		if reference.Kind == ai.MCPReferenceKindPrompt {
+			prompts = append(prompts, content)
+		} else {
+			resources = append(resources, fmt.Sprintf("<mcp_resource server=%q uri=%q>\n%s\n</mcp_resource>", reference.ServerName, reference.Name, content))
+		}
+	}
+
+	parts := append(prompts, resources...)
+	if cleanedText := ai.StripMCPReferenceTags(conversation.Text); cleanedText != "" {
+		parts = append(parts, cleanedText)
+	}
+	conversation.Text = strings.Join(parts, "\n\n")
+	return conversation
+}
+
+func (r *AIChatPlugin) resolveMCPReference(ctx context.Context, reference ai.MCPReference) (string, error) {
+	mcpServer, found := lo.Find(r.mcpServers, func(server common.AIChatMCPServerConfig) bool {
+		return server.Name == reference.ServerName
+	})
+	if !found {
+		return "", fmt.Errorf("server is not configured")
+	}
+	if mcpServer.Disabled {
+		return "", fmt.Errorf("server is disabled")
+	}
+	if reference.Kind == ai.MCPReferenceKindPrompt {
+		return ai.MCPGetPrompt(ctx, mcpServer, reference.Name, reference.Arguments)
+	}
+	return ai.MCPReadResource(ctx, mcpServer, reference.Name)
+}
+
 // updateMainChatDebugTrace keeps the dev inspector token summary synchronized while streaming.
 func updateMainChatDebugTrace(trace *common.AIChatDebugTrace, aiChatData common.AIChatData) {
 	if trace == nil {
@@ -1016,6 +1199,8 @@ func (r *AIChatPlugin) appendOrUpdateConversationAtEnd(aiChatData *common.AIChat
 
 func (r *AIChatPlugin) appendOrUpdateChatData(aiChatData common.AIChatData) {
 	aiChatData = cloneAIChatDataForState(aiChatData)
+	r.chatsMutex.Lock()
+	defer r.chatsMutex.Unlock()
 	for i := range r.chats {
 		if r.chats[i].Id == aiChatData.Id {
 			r.chats[i] = aiChatData
@@ -1047,19 +1232,37 @@ func (r *AIChatPlugin) StopChat(ctx context.Context, chatId string) bool {
 
 // DeleteChat removes a persisted chat by id and reports whether it existed.
 func (r *AIChatPlugin) DeleteChat(ctx context.Context, chatId string) bool {
-	for i := range r.chats {
-		if r.chats[i].Id == chatId {
-			r.chats = append(r.chats[:i], r.chats[i+1:]...)
-			r.saveChats(ctx)
-			return true
-		}
+	r.chatsMutex.Lock()
+	_, index, found := lo.FindIndexOf(r.chats, func(chat common.AIChatData) bool {
+		return chat.Id == chatId
+	})
+	if found {
+		r.chats = append(r.chats[:index], r.chats[index+1:]...)
+	}
+	r.chatsMutex.Unlock()
+	if !found {
+		return false
 	}
 
-	return false
+	for _, key := range r.mcpReferenceCache.Keys() {
+		if strings.HasPrefix(key, chatId+"\x00") {
+			r.mcpReferenceCache.Delete(key)
+		}
+	}
+	if err := r.chatStore.DeleteChat(ctx, chatId); err != nil {
+		r.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("AI: Failed to delete chat %s: %s", chatId, err.Error()))
+		return true
+	}
+	if err := cloudsync.LogEntityDelete(ctx, cloudsync.EntityAIChat, chatId, chatId); err != nil {
+		util.GetLogger().Warn(ctx, fmt.Sprintf("AI: Failed to log chat deletion %s for cloud sync: %s", chatId, err.Error()))
+	}
+	return true
 }
 
 // GetChat returns the full chat payload for a summary item selected in the UI.
 func (r *AIChatPlugin) GetChat(ctx context.Context, chatId string) (common.AIChatData, bool) {
+	r.chatsMutex.RLock()
+	defer r.chatsMutex.RUnlock()
 	for i := range r.chats {
 		if r.chats[i].Id == chatId {
 			snapshot := cloneAIChatDataForUI(r.chats[i])
@@ -1074,17 +1277,19 @@ func (r *AIChatPlugin) GetChat(ctx context.Context, chatId string) (common.AICha
 
 // SummarizeChat starts an asynchronous title refresh for a persisted chat.
 func (r *AIChatPlugin) SummarizeChat(ctx context.Context, chatId string) bool {
-	for i := range r.chats {
-		if r.chats[i].Id == chatId {
-			chat := r.chats[i]
-			util.Go(ctx, "summarize chat", func() {
-				r.summarizeChat(ctx, chat)
-			})
-			return true
-		}
+	r.chatsMutex.RLock()
+	chat, found := lo.Find(r.chats, func(chat common.AIChatData) bool {
+		return chat.Id == chatId
+	})
+	r.chatsMutex.RUnlock()
+	if !found {
+		return false
 	}
 
-	return false
+	util.Go(ctx, "summarize chat", func() {
+		r.summarizeChat(ctx, chat)
+	})
+	return true
 }
 
 func (r *AIChatPlugin) newChatData(ctx context.Context) common.AIChatData {
@@ -1104,10 +1309,12 @@ func (r *AIChatPlugin) getChatPreviewData(ctx context.Context, activeChatId stri
 		activeChat.Id = activeChatId
 		activeChat.IsStreaming = r.isChatStreaming(activeChatId)
 	}
+	r.chatsMutex.RLock()
 	chatSummaries := make([]common.AIChatData, 0, len(r.chats))
 	for _, chat := range r.chats {
 		chatSummaries = append(chatSummaries, r.cloneAIChatDataForPreviewList(chat))
 	}
+	r.chatsMutex.RUnlock()
 
 	previewData, err := json.Marshal(common.AIChatPreviewData{
 		ActiveChat:   activeChat,
@@ -1152,11 +1359,150 @@ func (r *AIChatPlugin) Query(ctx context.Context, query plugin.Query) plugin.Que
 	if query.ContextData != nil {
 		activeChatId = query.ContextData["ai_chat_active_id"]
 	}
-	response := plugin.NewQueryResponse([]plugin.QueryResult{r.getChatPreviewData(ctx, activeChatId)})
+	results := []plugin.QueryResult{r.getChatPreviewData(ctx, activeChatId)}
+	// Feature addition: typing after the chat keyword searches every past
+	// message, so an old answer can be found without scrolling the sidebar.
+	if activeChatId == "" && strings.TrimSpace(query.Search) != "" {
+		results = append(results, r.searchChatHistory(ctx, query.Search)...)
+	}
+	response := plugin.NewQueryResponse(results)
 	response.Layout = plugin.QueryLayout{ChatMode: true}
 	return response
 }
 
+func (r *AIChatPlugin) searchChatHistory(ctx context.Context, search string) []plugin.QueryResult {
+	hits, err := r.chatStore.SearchMessages(ctx, search, aiChatSearchResultLimit)
+	if err != nil {
+		r.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("AI: Failed to search chat history: %s", err.Error()))
+		return []plugin.QueryResult{}
+	}
+	// Feature addition: when no message contains the typed words, fall back to
+	// messages with a similar meaning, e.g. "car insurance" finds "vehicle policy".
+	if len(hits) == 0 {
+		hits = r.searchChatHistorySemantic(ctx, search)
+	}
+
+	results := make([]plugin.QueryResult, 0, len(hits))
+	for index, hit := range hits {
+		chatId := hit.ChatID
+		result := r.getChatPreviewData(ctx, chatId)
+		if result.Id == "" {
+			continue
+		}
+		chatTitle := hit.ChatTitle
+		if strings.TrimSpace(chatTitle) == "" {
+			chatTitle = r.api.GetTranslation(ctx, "ui_ai_chat_new_chat")
+		}
+		result.Title = hit.Snippet
+		result.SubTitle = fmt.Sprintf("%s · %s", chatTitle, util.FormatTimestamp(hit.Timestamp))
+		result.Group = "i18n:plugin_ai_chat_history_search_group"
+		result.GroupScore = 500
+		result.Score = int64(aiChatSearchResultLimit - index)
+		result.Actions[0].Name = "i18n:plugin_ai_chat_open_chat"
+		result.Actions = append(result.Actions,
+			plugin.QueryResultAction{
+				Name: "i18n:plugin_ai_chat_export_markdown",
+				Icon: common.OpenContainingFolderIcon,
+				Action: func(ctx context.Context, actionContext plugin.ActionContext) {
+					markdown, exportErr := r.chatStore.ExportChatMarkdown(ctx, chatId)
+					if exportErr != nil {
+						r.api.Notify(ctx, fmt.Sprintf(r.api.GetTranslation(ctx, "plugin_ai_chat_export_failed"), exportErr.Error()))
+						return
+					}
+					r.writeChatExport(ctx, chatTitle, ".md", []byte(markdown))
+				},
+			},
+			plugin.QueryResultAction{
+				Name: "i18n:plugin_ai_chat_export_json",
+				Icon: common.OpenContainingFolderIcon,
+				Action: func(ctx context.Context, actionContext plugin.ActionContext) {
+					data, exportErr := r.chatStore.ExportChatJSON(ctx, chatId)
+					if exportErr != nil {
+						r.api.Notify(ctx, fmt.Sprintf(r.api.GetTranslation(ctx, "plugin_ai_chat_export_failed"), exportErr.Error()))
+						return
+					}
+					r.writeChatExport(ctx, chatTitle, ".json", data)
+				},
+			},
+		)
+		results = append(results, result)
+	}
+	return results
+}
+
+// searchChatHistorySemantic finds messages by meaning. It returns nothing
+// when no embedding model is configured.
+func (r *AIChatPlugin) searchChatHistorySemantic(ctx context.Context, search string) []database.AIChatMessageSearchHit {
+	semanticHits, err := ai.GetSemanticIndex().Search(ctx, aiChatSemanticSource, search, aiChatSearchResultLimit)
+	if err != nil {
+		if !errors.Is(err, ai.ErrSemanticSearchUnavailable) {
+			r.api.Log(ctx, plugin.LogLevelWarning, fmt.Sprintf("AI: Failed to search chat history by meaning: %s", err.Error()))
+		}
+		return nil
+	}
+
+	keys := make([]database.AIChatMessageText, 0, len(semanticHits))
+	for _, hit := range semanticHits {
+		chatId, messageId, found := strings.Cut(hit.Id, "/")
+		if found {
+			keys = append(keys, database.AIChatMessageText{ChatID: chatId, MessageID: messageId})
+		}
+	}
+	hits, err := r.chatStore.GetMessageSearchHits(ctx, keys)
+	if err != nil {
+		r.api.Log(ctx, plugin.LogLevelWarning, fmt.Sprintf("AI: Failed to load semantic chat hits: %s", err.Error()))
+		return nil
+	}
+	return hits
+}
+
+// semanticDocuments lists user and assistant messages for the semantic index.
+func (r *AIChatPlugin) semanticDocuments(ctx context.Context) ([]ai.SemanticDocument, error) {
+	messages, err := r.chatStore.ListMessageTexts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	documents := make([]ai.SemanticDocument, 0, len(messages))
+	for _, message := range messages {
+		documents = append(documents, ai.SemanticDocument{Id: message.ChatID + "/" + message.MessageID, Text: message.Text})
+	}
+	return documents, nil
+}
+
+// writeChatExport saves an exported chat into the Downloads folder, falling
+// back to the home folder, and reveals the file.
+func (r *AIChatPlugin) writeChatExport(ctx context.Context, chatTitle string, extension string, data []byte) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		r.api.Notify(ctx, fmt.Sprintf(r.api.GetTranslation(ctx, "plugin_ai_chat_export_failed"), err.Error()))
+		return
+	}
+	exportDir := filepath.Join(homeDir, "Downloads")
+	if info, statErr := os.Stat(exportDir); statErr != nil || !info.IsDir() {
+		exportDir = homeDir
+	}
+
+	fileName := strings.Map(func(ch rune) rune {
+		if strings.ContainsRune(`<>:"/\|?*`, ch) || ch < 32 {
+			return '_'
+		}
+		return ch
+	}, strings.TrimSpace(chatTitle))
+	if fileName == "" {
+		fileName = "chat"
+	}
+	exportPath := filepath.Join(exportDir, fmt.Sprintf("%s-%s%s", fileName, time.Now().Format("20060102-150405"), extension))
+	if err := os.WriteFile(exportPath, data, 0644); err != nil {
+		r.api.Notify(ctx, fmt.Sprintf(r.api.GetTranslation(ctx, "plugin_ai_chat_export_failed"), err.Error()))
+		return
+	}
+
+	r.api.Log(ctx, plugin.LogLevelInfo, fmt.Sprintf("AI: Exported chat to %s", exportPath))
+	if err := shell.OpenFileInFolder(exportPath); err != nil {
+		r.api.Log(ctx, plugin.LogLevelWarning, fmt.Sprintf("AI: Failed to reveal exported chat: %s", err.Error()))
+	}
+}
+
 func (r *AIChatPlugin) summarizeChat(ctx context.Context, chat common.AIChatData) {
 	r.api.Log(ctx, plugin.LogLevelInfo, fmt.Sprintf("AI: Summarizing chat: %s", chat.Id))
 
@@ -1197,14 +1543,20 @@ func (r *AIChatPlugin) summarizeChat(ctx context.Context, chat common.AIChatData
 			// update the chat title
 			updatedChat := chat
 			updatedChat.Title = title
+			r.chatsMutex.Lock()
 			for i := range r.chats {
 				if r.chats[i].Id == chat.Id {
 					r.chats[i].Title = title
-					updatedChat = r.chats[i]
+					updatedChat = cloneAIChatDataForState(r.chats[i])
 					break
 				}
 			}
-			r.saveChats(ctx)
+			r.chatsMutex.Unlock()
+			// Only the title changed, so the conversations are left untouched.
+			// A title is a settled change, so it is logged for cloud sync here.
+			if r.saveChatConversations(ctx, updatedChat) {
+				r.logChatSync(ctx, updatedChat)
+			}
 			updatedSnapshot := cloneAIChatDataForUI(updatedChat)
 			// Title updates come from persisted chat state, so restore the transient debug trace for the UI snapshot.
 			updatedSnapshot.DebugTrace = cloneAIChatDebugTrace(debugTrace)
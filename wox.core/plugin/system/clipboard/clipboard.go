@@ -14,11 +14,14 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"reflect"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 	"unicode/utf8"
+	"wox/ai"
+	"wox/cloudsync"
 	"wox/common"
 	"wox/plugin"
 	"wox/plugin/system"
@@ -48,6 +51,14 @@ var primaryActionValueCopy = "copy"
 var primaryActionValuePaste = "paste"
 var favoritesSettingKey = "favorites"
 var ignoredApplicationsSettingKey = "ignored_applications"
+var sensitiveDetectionSettingKey = "sensitive_detection_enabled"
+var sensitiveActionSettingKey = "sensitive_action"
+var sensitiveActionValueSkip = "skip"
+var sensitiveActionValueMask = "mask"
+var sensitiveTTLSettingKey = "sensitive_ttl_minutes"
+var sensitiveEntropySettingKey = "sensitive_entropy_enabled"
+var sensitiveCustomRulesSettingKey = "sensitive_custom_rules"
+var transformPipelinesSettingKey = "transform_pipelines"
 
 const (
 	clipboardTypeRefinementKey   = "clipboard_type"
@@ -62,6 +73,7 @@ func init() {
 	plugin.AllSystemPlugin = append(plugin.AllSystemPlugin, &ClipboardPlugin{
 		maxHistoryCount: 5000,
 		imageCache:      util.NewHashMap[string, *ImageCacheEntry](),
+		sensitiveTexts:  util.NewHashMap[string, clipboardSensitiveText](),
 	})
 }
 
@@ -73,20 +85,22 @@ type ImageCacheEntry struct {
 
 // FavoriteClipboardItem represents a favorite clipboard item stored in settings
 type FavoriteClipboardItem struct {
-	ID        string   `json:"id"`
-	Type      string   `json:"type"`
-	Content   string   `json:"content"`
-	FilePath  string   `json:"filePath,omitempty"`
-	FilePaths []string `json:"filePaths,omitempty"`
-	ImageHash *string  `json:"imageHash,omitempty"`
-	IconData  *string  `json:"iconData,omitempty"`
-	Width     *int     `json:"width,omitempty"`
-	Height    *int     `json:"height,omitempty"`
-	FileSize  *int64   `json:"fileSize,omitempty"`
-	Alias     *string  `json:"alias,omitempty"`
-	OCRText   *string  `json:"ocrText,omitempty"`
-	Timestamp int64    `json:"timestamp"`
-	CreatedAt int64    `json:"createdAt"`
+	ID          string   `json:"id"`
+	Type        string   `json:"type"`
+	Content     string   `json:"content"`
+	FilePath    string   `json:"filePath,omitempty"`
+	FilePaths   []string `json:"filePaths,omitempty"`
+	ImageHash   *string  `json:"imageHash,omitempty"`
+	IconData    *string  `json:"iconData,omitempty"`
+	Width       *int     `json:"width,omitempty"`
+	Height      *int     `json:"height,omitempty"`
+	FileSize    *int64   `json:"fileSize,omitempty"`
+	Alias       *string  `json:"alias,omitempty"`
+	OCRText     *string  `json:"ocrText,omitempty"`
+	RichFormat  *string  `json:"richFormat,omitempty"`
+	RichContent *string  `json:"richContent,omitempty"`
+	Timestamp   int64    `json:"timestamp"`
+	CreatedAt   int64    `json:"createdAt"`
 }
 
 // ClipboardDBInterface defines the interface for clipboard database operations
@@ -104,6 +118,7 @@ type ClipboardDBInterface interface {
 	SearchByType(ctx context.Context, searchTerm string, recordType string, limit int) ([]ClipboardRecord, error)
 	GetByID(ctx context.Context, id string) (*ClipboardRecord, error)
 	DeleteExpired(ctx context.Context, textDays, imageDays int) (int64, error)
+	DeleteExpiredSensitive(ctx context.Context) (int64, error)
 	EnforceMaxCount(ctx context.Context, maxCount int) (int64, error)
 	GetStats(ctx context.Context) (map[string]int, error)
 	Close() error
@@ -116,6 +131,14 @@ type ClipboardPlugin struct {
 	backgroundTasks sync.WaitGroup
 	// Cache for generated preview and icon images to avoid regeneration
 	imageCache *util.HashMap[string, *ImageCacheEntry]
+	// sensitiveTexts keeps the clear text of sensitive records in memory only;
+	// the database, its full text index and cloud sync only see the mask
+	sensitiveTexts *util.HashMap[string, clipboardSensitiveText]
+	// transformTarget and transformSelection feed the transform view; both are
+	// reset when the user leaves the clipboard query
+	transformMutex     sync.Mutex
+	transformTarget    *ClipboardRecord
+	transformSelection []ClipboardRecord
 }
 
 type ignoredClipboardApplication struct {
@@ -131,7 +154,7 @@ type ignoredClipboardApplicationRow struct {
 
 func (c *ClipboardPlugin) GetMetadata() plugin.Metadata {
 	return plugin.Metadata{
-		Id:            "5f815d98-27f5-488d-a756-c317ea39935b",
+		Id:            PluginID,
 		Name:          "i18n:plugin_clipboard_plugin_name",
 		Author:        "Wox Launcher",
 		Website:       "https://github.com/Wox-launcher/Wox",
@@ -162,6 +185,10 @@ func (c *ClipboardPlugin) GetMetadata() plugin.Metadata {
 				Command:     "fav",
 				Description: "i18n:plugin_clipboard_command_fav_description",
 			},
+			{
+				Command:     clipboardTransformCommand,
+				Description: "i18n:plugin_clipboard_command_transform_description",
+			},
 		},
 		SupportedOS: []string{
 			"Windows",
@@ -231,6 +258,119 @@ func (c *ClipboardPlugin) GetMetadata() plugin.Metadata {
 					},
 				},
 			},
+			{
+				Type: definition.PluginSettingDefinitionTypeTable,
+				Value: &definition.PluginSettingValueTable{
+					Key:          transformPipelinesSettingKey,
+					Title:        "i18n:plugin_clipboard_transform_pipelines",
+					Tooltip:      "i18n:plugin_clipboard_transform_pipelines_tooltip",
+					DefaultValue: "[]",
+					MaxHeight:    220,
+					Columns: []definition.PluginSettingValueTableColumn{
+						{
+							Key:   "Name",
+							Label: "i18n:plugin_clipboard_transform_pipeline_name",
+							Width: 160,
+							Type:  definition.PluginSettingValueTableColumnTypeText,
+							Validators: []validator.PluginSettingValidator{
+								{Type: validator.PluginSettingValidatorTypeNotEmpty, Value: &validator.PluginSettingValidatorNotEmpty{}},
+								{Type: validator.PluginSettingValidatorTypeUnique, Value: &validator.PluginSettingValidatorUnique{}},
+							},
+						},
+						{
+							Key:     "Steps",
+							Label:   "i18n:plugin_clipboard_transform_pipeline_steps",
+							Tooltip: "i18n:plugin_clipboard_transform_pipeline_steps_tooltip",
+							Width:   260,
+							Type:    definition.PluginSettingValueTableColumnTypeText,
+							Validators: []validator.PluginSettingValidator{
+								{Type: validator.PluginSettingValidatorTypeNotEmpty, Value: &validator.PluginSettingValidatorNotEmpty{}},
+							},
+						},
+					},
+				},
+			},
+			{
+				Type: definition.PluginSettingDefinitionTypeHead,
+				Value: &definition.PluginSettingValueHead{
+					Content: "i18n:plugin_clipboard_sensitive_content",
+				},
+			},
+			{
+				Type: definition.PluginSettingDefinitionTypeCheckBox,
+				Value: &definition.PluginSettingValueCheckBox{
+					Key:          sensitiveDetectionSettingKey,
+					Label:        "i18n:plugin_clipboard_sensitive_detection",
+					Tooltip:      "i18n:plugin_clipboard_sensitive_detection_tooltip",
+					DefaultValue: "true",
+				},
+			},
+			{
+				Type: definition.PluginSettingDefinitionTypeSelect,
+				Value: &definition.PluginSettingValueSelect{
+					Key:          sensitiveActionSettingKey,
+					Label:        "i18n:plugin_clipboard_sensitive_action",
+					Tooltip:      "i18n:plugin_clipboard_sensitive_action_tooltip",
+					DefaultValue: sensitiveActionValueMask,
+					Options: []definition.PluginSettingValueSelectOption{
+						{Label: "i18n:plugin_clipboard_sensitive_action_mask", Value: sensitiveActionValueMask},
+						{Label: "i18n:plugin_clipboard_sensitive_action_skip", Value: sensitiveActionValueSkip},
+					},
+				},
+			},
+			{
+				Type: definition.PluginSettingDefinitionTypeTextBox,
+				Value: &definition.PluginSettingValueTextBox{
+					Key:          sensitiveTTLSettingKey,
+					Label:        "i18n:plugin_clipboard_sensitive_ttl",
+					Suffix:       "i18n:plugin_clipboard_minutes",
+					DefaultValue: strconv.Itoa(clipboardSensitiveDefaultTTLMinutes),
+					Validators: []validator.PluginSettingValidator{
+						{Type: validator.PluginSettingValidatorTypeIsNumber, Value: &validator.PluginSettingValidatorIsNumber{IsInteger: true}},
+					},
+				},
+			},
+			{
+				Type: definition.PluginSettingDefinitionTypeCheckBox,
+				Value: &definition.PluginSettingValueCheckBox{
+					Key:          sensitiveEntropySettingKey,
+					Label:        "i18n:plugin_clipboard_sensitive_entropy",
+					Tooltip:      "i18n:plugin_clipboard_sensitive_entropy_tooltip",
+					DefaultValue: "true",
+				},
+			},
+			{
+				Type: definition.PluginSettingDefinitionTypeTable,
+				Value: &definition.PluginSettingValueTable{
+					Key:          sensitiveCustomRulesSettingKey,
+					Title:        "i18n:plugin_clipboard_sensitive_custom_rules",
+					Tooltip:      "i18n:plugin_clipboard_sensitive_custom_rules_tooltip",
+					DefaultValue: "[]",
+					MaxHeight:    220,
+					Columns: []definition.PluginSettingValueTableColumn{
+						{
+							Key:   "Name",
+							Label: "i18n:plugin_clipboard_sensitive_custom_rule_name",
+							Width: 160,
+							Type:  definition.PluginSettingValueTableColumnTypeText,
+							Validators: []validator.PluginSettingValidator{
+								{Type: validator.PluginSettingValidatorTypeNotEmpty, Value: &validator.PluginSettingValidatorNotEmpty{}},
+								{Type: validator.PluginSettingValidatorTypeUnique, Value: &validator.PluginSettingValidatorUnique{}},
+							},
+						},
+						{
+							Key:     "Pattern",
+							Label:   "i18n:plugin_clipboard_sensitive_custom_rule_pattern",
+							Tooltip: "i18n:plugin_clipboard_sensitive_custom_rule_pattern_tooltip",
+							Width:   260,
+							Type:    definition.PluginSettingValueTableColumnTypeText,
+							Validators: []validator.PluginSettingValidator{
+								{Type: validator.PluginSettingValidatorTypeNotEmpty, Value: &validator.PluginSettingValidatorNotEmpty{}},
+							},
+						},
+					},
+				},
+			},
 			{
 				Type: definition.PluginSettingDefinitionTypeHead,
 				Value: &definition.PluginSettingValueHead{
@@ -274,6 +414,9 @@ func (c *ClipboardPlugin) Init(ctx context.Context, initParams plugin.InitParams
 	if c.imageCache == nil {
 		c.imageCache = util.NewHashMap[string, *ImageCacheEntry]()
 	}
+	if c.sensitiveTexts == nil {
+		c.sensitiveTexts = util.NewHashMap[string, clipboardSensitiveText]()
+	}
 	c.api.OnGetDynamicSetting(ctx, func(ctx context.Context, key string) definition.PluginSettingDefinitionItem {
 		if key != clipboardOCRModelSettingKey || !c.isImageTextRecognitionEnabled(ctx) {
 			return definition.PluginSettingDefinitionItem{}
@@ -281,6 +424,10 @@ func (c *ClipboardPlugin) Init(ctx context.Context, initParams plugin.InitParams
 		return system.BuildOCRModelSetting(ctx, clipboardOCRModelSettingKey, "i18n:plugin_clipboard_ocr_model", "i18n:plugin_clipboard_ocr_model_tooltip")
 	})
 
+	c.api.OnLeavePluginQuery(ctx, func(ctx context.Context) {
+		c.resetTransformState()
+	})
+
 	// Initialize database
 	db, err := NewClipboardDB(ctx, c.GetMetadata().Id)
 	if err != nil {
@@ -288,6 +435,8 @@ func (c *ClipboardPlugin) Init(ctx context.Context, initParams plugin.InitParams
 		return
 	}
 	c.db = db
+	c.api.OnHandlePluginCommand(ctx, c.handlePluginCommand)
+	ai.GetSemanticIndex().RegisterSource(ai.SemanticSource{Name: clipboardSemanticSource, Documents: c.semanticDocuments})
 	runtimeCtx, cancelRuntime := context.WithCancel(util.NewTraceContext())
 
 	// Migration is now handled by the central migrator during app startup
@@ -392,6 +541,11 @@ func isIgnoredClipboardApplication(rows []ignoredClipboardApplicationRow, identi
 }
 
 func (c *ClipboardPlugin) processClipboardData(ctx context.Context, data clipboard.Data) {
+	// Feature addition: formatted copies are stored as text records with the
+	// markup kept alongside, so search, dedup and refinements keep treating
+	// them as plain text.
+	data, richFormat, richContent := splitClipboardRichData(data)
+
 	var fileSignature string
 	var imageHash string
 	if data.GetType() == clipboard.ClipboardTypeImage {
@@ -428,11 +582,27 @@ func (c *ClipboardPlugin) processClipboardData(ctx context.Context, data clipboa
 	}
 
 	// Validate text data
+	var sensitiveRule string
 	if data.GetType() == clipboard.ClipboardTypeText {
 		textData := data.(*clipboard.TextData)
 		if len(textData.Text) == 0 || strings.TrimSpace(textData.Text) == "" {
 			return
 		}
+
+		// Feature addition: secrets copied from password managers and terminals
+		// used to land in history verbatim. Detection runs before any write so a
+		// skipped secret never touches the database.
+		if c.isSensitiveDetectionEnabled(ctx) {
+			if textData.Hints.Transient {
+				c.api.Log(ctx, plugin.LogLevelInfo, "clipboard content marked transient by source app, skipping")
+				return
+			}
+			sensitiveRule = c.detectSensitiveText(ctx, textData)
+			if sensitiveRule != "" && c.sensitiveAction(ctx) == sensitiveActionValueSkip {
+				c.api.Log(ctx, plugin.LogLevelInfo, fmt.Sprintf("sensitive clipboard content detected (rule=%s), skipping", sensitiveRule))
+				return
+			}
+		}
 	}
 
 	// Check for duplicate content by querying the most recent record
@@ -454,6 +624,19 @@ func (c *ClipboardPlugin) processClipboardData(ctx context.Context, data clipboa
 	if data.GetType() == clipboard.ClipboardTypeText {
 		textData := data.(*clipboard.TextData)
 		record.Content = textData.Text
+		if sensitiveRule != "" {
+			expiresAt := record.Timestamp + int64(c.getSensitiveTTLMinutes(ctx))*60*1000
+			c.maskSensitiveRecord(&record, textData.Text, sensitiveRule, expiresAt)
+			c.api.Log(ctx, plugin.LogLevelInfo, fmt.Sprintf("sensitive clipboard content detected (rule=%s), storing masked until %s", sensitiveRule, util.FormatTimestamp(expiresAt)))
+		}
+		if richFormat != "" && sensitiveRule == "" {
+			if len(richContent) <= clipboardRichContentMaxBytes {
+				record.RichFormat = &richFormat
+				record.RichContent = &richContent
+			} else {
+				c.api.Log(ctx, plugin.LogLevelInfo, fmt.Sprintf("clipboard %s content too large (%d bytes), keeping plain text only", richFormat, len(richContent)))
+			}
+		}
 
 		// Try to get active window icon for text clipboard
 		if iconImage, iconErr := system.GetActiveWindowIcon(ctx); iconErr == nil {
@@ -519,6 +702,7 @@ func (c *ClipboardPlugin) processClipboardData(ctx context.Context, data clipboa
 		c.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("failed to insert clipboard record: %s", err.Error()))
 		return
 	}
+	ai.GetSemanticIndex().Refresh()
 
 	if data.GetType() == clipboard.ClipboardTypeImage && c.isImageTextRecognitionEnabled(ctx) {
 		// Feature addition: clipboard image OCR is intentionally independent
@@ -711,6 +895,10 @@ func (c *ClipboardPlugin) Query(ctx context.Context, query plugin.Query) plugin.
 		return c.newClipboardQueryResponse(results)
 	}
 
+	if query.Command == clipboardTransformCommand {
+		return plugin.QueryResponse{Results: c.queryTransforms(ctx, query)}
+	}
+
 	if query.Search == "" {
 		if selectedType == clipboardTypeRefinementAll {
 			// The default clipboard view keeps favorites first. Explicit type
@@ -745,10 +933,15 @@ func (c *ClipboardPlugin) Query(ctx context.Context, query plugin.Query) plugin.
 		if recentErr != nil {
 			c.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("failed to get recent records: %s", recentErr.Error()))
 		} else {
+			now := util.GetSystemTimestamp()
 			for _, record := range recent {
 				if !clipboardRecordMatchesType(record.Type, record.Content, selectedType) {
 					continue
 				}
+				// Expired sensitive records wait for the next sweep; never show them.
+				if isClipboardRecordExpired(record, now) {
+					continue
+				}
 				// All records in database are non-favorite now
 				results = append(results, c.convertRecordToResult(ctx, record, query))
 			}
@@ -757,6 +950,12 @@ func (c *ClipboardPlugin) Query(ctx context.Context, query plugin.Query) plugin.
 		return c.newClipboardQueryResponse(results)
 	}
 
+	// Feature addition: "~ meaning" searches text and image OCR by meaning
+	// through the semantic index instead of by words.
+	if semanticSearch, ok := ai.ParseSemanticQuery(query.Search); ok {
+		return c.newClipboardQueryResponse(c.querySemantic(ctx, query, semanticSearch, selectedType))
+	}
+
 	// Search historical content. The default All path keeps the old text-only
 	// behavior, while explicit type refinements narrow the search to that type.
 	var allResults []ClipboardRecord
@@ -793,13 +992,41 @@ func (c *ClipboardPlugin) Query(ctx context.Context, query plugin.Query) plugin.
 }
 
 func (c *ClipboardPlugin) searchClipboardRecords(ctx context.Context, search string, selectedType string, limit int) ([]ClipboardRecord, error) {
+	// Feature addition: the FTS5 index ranks matches across the whole history
+	// by BM25 and recency, so old entries are found without loading every row.
+	var ranked []ClipboardRecord
+	var err error
+	if selectedType == clipboardTypeRefinementAll || selectedType == clipboardTypeRefinementLink {
+		ranked, err = c.db.SearchText(ctx, search, limit)
+	} else {
+		ranked, err = c.db.SearchByType(ctx, search, selectedType, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]ClipboardRecord, 0, limit)
+	seen := make(map[string]bool, len(ranked))
+	for _, record := range ranked {
+		if !clipboardRecordMatchesType(record.Type, record.Content, selectedType) {
+			continue
+		}
+		results = append(results, record)
+		seen[record.ID] = true
+	}
+	if limit > 0 && len(results) >= limit {
+		return results, nil
+	}
+
+	// The index tokenizes words, so pinyin, fuzzy and CJK substring matches
+	// still go through the plugin matcher over the whole kept history, as
+	// before the index existed; ranked index hits stay ahead of them.
 	scanLimit := c.maxHistoryCount
 	if scanLimit <= 0 {
 		scanLimit = 5000
 	}
 
 	var records []ClipboardRecord
-	var err error
 	if selectedType == clipboardTypeRefinementAll || selectedType == clipboardTypeRefinementLink {
 		records, err = c.db.GetRecentByType(ctx, string(clipboard.ClipboardTypeText), scanLimit, 0)
 	} else {
@@ -809,9 +1036,10 @@ func (c *ClipboardPlugin) searchClipboardRecords(ctx context.Context, search str
 		return nil, err
 	}
 
-	results := make([]ClipboardRecord, 0, limit)
 	for _, record := range records {
-		if !clipboardRecordMatchesSearch(ctx, record, search, selectedType) {
+		// Sensitive records are masked, so matching their hidden text would
+		// reveal it one query at a time.
+		if seen[record.ID] || isClipboardRecordSensitive(record) || !clipboardRecordMatchesSearch(ctx, record, search, selectedType) {
 			continue
 		}
 		results = append(results, record)
@@ -867,7 +1095,7 @@ func (c *ClipboardPlugin) isDuplicateContent(ctx context.Context, data clipboard
 
 	if data.GetType() == clipboard.ClipboardTypeText {
 		textData := data.(*clipboard.TextData)
-		if mostRecentRecord.Content == textData.Text {
+		if recordText, ok := c.getRecordText(*mostRecentRecord); ok && recordText == textData.Text {
 			// Update timestamp of existing record
 			c.updateRecordTimestamp(ctx, mostRecentRecord, util.GetSystemTimestamp())
 			return true
@@ -1325,10 +1553,28 @@ func (c *ClipboardPlugin) buildClipboardFilePreview(ctx context.Context, filePat
 // convertTextRecord converts a text record to a query result
 func (c *ClipboardPlugin) convertTextRecord(ctx context.Context, record ClipboardRecord, query plugin.Query) plugin.QueryResult {
 	primaryActionCode := c.api.GetSetting(ctx, primaryActionSettingKey)
-	openDirectoryPath := resolveClipboardDirectoryPath(record.Content)
+	isSensitive := isClipboardRecordSensitive(record)
+	openDirectoryPath := ""
 	normalizedLink := ""
-	if util.IsUrl(record.Content) {
-		normalizedLink = util.NormalizeUrl(record.Content)
+	if !isSensitive {
+		openDirectoryPath = resolveClipboardDirectoryPath(record.Content)
+		if util.IsUrl(record.Content) {
+			normalizedLink = util.NormalizeUrl(record.Content)
+		}
+	}
+
+	// Formatted copies write the markup back together with the plain text, so
+	// copy and the default paste keep the formatting of the source app.
+	richData := clipboardRecordRichData(record)
+	writeRecord := func() error {
+		if richData != nil {
+			return clipboard.Write(richData)
+		}
+		recordText, ok := c.getRecordText(record)
+		if !ok {
+			return fmt.Errorf("sensitive clipboard text is no longer available")
+		}
+		return clipboard.WriteText(recordText)
 	}
 
 	actions := []plugin.QueryResultAction{
@@ -1338,7 +1584,7 @@ func (c *ClipboardPlugin) convertTextRecord(ctx context.Context, record Clipboar
 			IsDefault: primaryActionValueCopy == primaryActionCode,
 			Action: func(ctx context.Context, actionContext plugin.ActionContext) {
 				c.moveRecordToTop(ctx, record.ID)
-				if err := clipboard.WriteText(record.Content); err != nil {
+				if err := writeRecord(); err != nil {
 					c.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("failed to copy text record to clipboard: id=%s err=%s", record.ID, err.Error()))
 				}
 			},
@@ -1348,16 +1594,36 @@ func (c *ClipboardPlugin) convertTextRecord(ctx context.Context, record Clipboar
 	// paste to active window
 	c.api.Log(ctx, plugin.LogLevelInfo, fmt.Sprintf("active window info: name=%s, pid=%d", query.Env.ActiveWindowTitle, query.Env.ActiveWindowPid))
 	pasteToActiveWindowAction, pasteToActiveWindowErr := system.GetPasteToActiveWindowAction(ctx, c.api, query.Env.ActiveWindowTitle, query.Env.ActiveWindowPid, query.Env.ActiveWindowIcon, func(actionCtx context.Context) error {
-		if err := clipboard.WriteText(record.Content); err != nil {
+		if err := writeRecord(); err != nil {
 			return fmt.Errorf("failed to copy text record before paste action: %w", err)
 		}
 		c.moveRecordToTop(actionCtx, record.ID)
 		return nil
 	})
 	if pasteToActiveWindowErr == nil {
+		if richData != nil {
+			pasteToActiveWindowAction.Name = fmt.Sprintf(c.api.GetTranslation(ctx, "plugin_clipboard_paste_rich_to_window"), query.Env.ActiveWindowTitle)
+		}
 		actions = append(actions, pasteToActiveWindowAction)
 	}
 
+	// Feature addition: some targets (terminals, code editors, chat inputs)
+	// mangle pasted formatting, so formatted records also offer a plain paste.
+	if richData != nil {
+		pastePlainAction, pastePlainErr := system.GetPasteToActiveWindowAction(ctx, c.api, query.Env.ActiveWindowTitle, query.Env.ActiveWindowPid, query.Env.ActiveWindowIcon, func(actionCtx context.Context) error {
+			if err := clipboard.WriteText(record.Content); err != nil {
+				return fmt.Errorf("failed to copy plain text record before paste action: %w", err)
+			}
+			c.moveRecordToTop(actionCtx, record.ID)
+			return nil
+		})
+		if pastePlainErr == nil {
+			pastePlainAction.Name = fmt.Sprintf(c.api.GetTranslation(ctx, "plugin_clipboard_paste_plain_to_window"), query.Env.ActiveWindowTitle)
+			pastePlainAction.IsDefault = false
+			actions = append(actions, pastePlainAction)
+		}
+	}
+
 	if normalizedLink != "" {
 		actions = append(actions, plugin.QueryResultAction{
 			Name: "i18n:plugin_clipboard_open_link",
@@ -1384,7 +1650,14 @@ func (c *ClipboardPlugin) convertTextRecord(ctx context.Context, record Clipboar
 		})
 	}
 
-	if !record.IsFavorite {
+	// Feature addition: transforms run on a copy of the text, so the record
+	// itself is never modified. Sensitive records stay out of the transform view.
+	if !isSensitive {
+		actions = append(actions, c.buildTransformRecordActions(ctx, record, query)...)
+	}
+
+	// Favorites are kept forever in settings, which would defeat the sensitive TTL.
+	if !record.IsFavorite && !isSensitive {
 		actions = append(actions, plugin.QueryResultAction{
 			Name:                   "i18n:plugin_clipboard_mark_favorite",
 			Icon:                   common.PinIcon,
@@ -1398,7 +1671,7 @@ func (c *ClipboardPlugin) convertTextRecord(ctx context.Context, record Clipboar
 				}
 			},
 		})
-	} else {
+	} else if record.IsFavorite {
 		actions = append(actions, plugin.QueryResultAction{
 			Name:                   "i18n:plugin_clipboard_cancel_favorite",
 			Icon:                   common.UnpinIcon,
@@ -1430,56 +1703,59 @@ func (c *ClipboardPlugin) convertTextRecord(ctx context.Context, record Clipboar
 		},
 	})
 
-	// add edit action to edit text content
-	actions = append(actions, plugin.QueryResultAction{
-		Name:                   "i18n:plugin_clipboard_edit_text",
-		Icon:                   common.EditIcon,
-		Type:                   plugin.QueryResultActionTypeForm,
-		PreventHideAfterAction: true,
-		Form: definition.PluginSettingDefinitions{
-			{
-				Type: definition.PluginSettingDefinitionTypeTextBox,
-				Value: &definition.PluginSettingValueTextBox{
-					Key:          "content",
-					Label:        "i18n:plugin_clipboard_edit_text_label",
-					DefaultValue: record.Content,
-					Tooltip:      "i18n:plugin_clipboard_edit_text_hint",
-					MaxLines:     10,
+	// add edit action to edit text content. Sensitive records skip it because
+	// the form would show the secret in clear text.
+	if !isSensitive {
+		actions = append(actions, plugin.QueryResultAction{
+			Name:                   "i18n:plugin_clipboard_edit_text",
+			Icon:                   common.EditIcon,
+			Type:                   plugin.QueryResultActionTypeForm,
+			PreventHideAfterAction: true,
+			Form: definition.PluginSettingDefinitions{
+				{
+					Type: definition.PluginSettingDefinitionTypeTextBox,
+					Value: &definition.PluginSettingValueTextBox{
+						Key:          "content",
+						Label:        "i18n:plugin_clipboard_edit_text_label",
+						DefaultValue: record.Content,
+						Tooltip:      "i18n:plugin_clipboard_edit_text_hint",
+						MaxLines:     10,
+					},
 				},
 			},
-		},
-		OnSubmit: func(ctx context.Context, actionContext plugin.FormActionContext) {
-			raw := actionContext.Values["content"]
-			if raw == "" {
-				return
-			}
-
-			isUpdateSuccess := false
-			// if record is favorite, update in settings, else update in database
-			if record.IsFavorite {
-				if err := c.updateFavoriteContent(ctx, record.ID, raw); err != nil {
-					c.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("failed to update favorite content: %s", err.Error()))
-					c.api.Notify(ctx, "Failed to update favorite content: "+err.Error())
-				} else {
-					c.api.Log(ctx, plugin.LogLevelInfo, fmt.Sprintf("updated favorite record content: %s", record.ID))
-					isUpdateSuccess = true
+			OnSubmit: func(ctx context.Context, actionContext plugin.FormActionContext) {
+				raw := actionContext.Values["content"]
+				if raw == "" {
+					return
 				}
-			} else {
-				if err := c.db.UpdateContent(ctx, record.ID, raw); err != nil {
-					c.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("failed to update content: %s", err.Error()))
-					c.api.Notify(ctx, "Failed to update clipboard content: "+err.Error())
+
+				isUpdateSuccess := false
+				// if record is favorite, update in settings, else update in database
+				if record.IsFavorite {
+					if err := c.updateFavoriteContent(ctx, record.ID, raw); err != nil {
+						c.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("failed to update favorite content: %s", err.Error()))
+						c.api.Notify(ctx, "Failed to update favorite content: "+err.Error())
+					} else {
+						c.api.Log(ctx, plugin.LogLevelInfo, fmt.Sprintf("updated favorite record content: %s", record.ID))
+						isUpdateSuccess = true
+					}
 				} else {
-					c.api.Log(ctx, plugin.LogLevelInfo, fmt.Sprintf("updated clipboard record content: %s", record.ID))
-					isUpdateSuccess = true
+					if err := c.db.UpdateContent(ctx, record.ID, raw); err != nil {
+						c.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("failed to update content: %s", err.Error()))
+						c.api.Notify(ctx, "Failed to update clipboard content: "+err.Error())
+					} else {
+						c.api.Log(ctx, plugin.LogLevelInfo, fmt.Sprintf("updated clipboard record content: %s", record.ID))
+						isUpdateSuccess = true
+					}
 				}
-			}
 
-			if isUpdateSuccess {
-				// Refresh query to update all result data including form default values and action closures
-				c.api.RefreshQuery(ctx, plugin.RefreshQueryParam{PreserveSelectedIndex: true})
-			}
-		},
-	})
+				if isUpdateSuccess {
+					// Refresh query to update all result data including form default values and action closures
+					c.api.RefreshQuery(ctx, plugin.RefreshQueryParam{PreserveSelectedIndex: true})
+				}
+			},
+		})
+	}
 
 	// add edit alias action
 	aliasDefaultValue := ""
@@ -1550,12 +1826,43 @@ func (c *ClipboardPlugin) convertTextRecord(ctx context.Context, record Clipboar
 	var title string
 	if record.Alias != nil && *record.Alias != "" {
 		title = *record.Alias
+	} else if isSensitive {
+		title = maskClipboardSensitiveText(record.Content)
 	} else {
 		title = strings.TrimSpace(ellipsis.Centering(record.Content, 80))
 	}
 
 	previewType := plugin.WoxPreviewTypeText
 	previewData := record.Content
+	characterCount := utf8.RuneCountInString(record.Content)
+	if recordText, ok := c.getRecordText(record); ok {
+		characterCount = utf8.RuneCountInString(recordText)
+	}
+	previewTags := []plugin.WoxPreviewTag{
+		{Label: util.FormatTimestamp(record.Timestamp), Tooltip: "i18n:plugin_clipboard_copy_date"},
+		// Preview pills show values only, so the character unit belongs in
+		// the value. Keep that unit localized instead of hard-coding a
+		// Chinese suffix into English and other languages.
+		{Label: fmt.Sprintf(c.api.GetTranslation(ctx, "plugin_clipboard_copy_characters_value"), characterCount), Tooltip: "i18n:plugin_clipboard_copy_characters"},
+	}
+	var tails []plugin.QueryResultTail
+	if isSensitive {
+		// Sensitive records stay pasteable until they expire, but the secret
+		// itself is never rendered. Rows stored before masking still hold the
+		// clear text, so mask again here.
+		previewData = maskClipboardSensitiveText(record.Content)
+		ruleLabel := clipboardSensitiveRuleLabel(*record.SensitiveRule)
+		previewTags = append(previewTags, plugin.WoxPreviewTag{Label: "i18n:plugin_clipboard_sensitive", Tooltip: ruleLabel})
+		if record.ExpiresAt != nil {
+			previewTags = append(previewTags, plugin.WoxPreviewTag{Label: util.FormatTimestamp(*record.ExpiresAt), Tooltip: "i18n:plugin_clipboard_sensitive_expires_at"})
+		}
+		tail := plugin.NewQueryResultTailTextWithCategory("i18n:plugin_clipboard_sensitive", plugin.QueryResultTailTextCategoryWarning)
+		tail.Tooltip = ruleLabel
+		tails = append(tails, tail)
+	}
+	if selectionIndex := c.transformSelectionIndex(record.ID); selectionIndex > 0 {
+		tails = append(tails, plugin.NewQueryResultTailText(fmt.Sprintf(c.api.GetTranslation(ctx, "plugin_clipboard_transform_selected"), selectionIndex)))
+	}
 	if normalizedLink != "" {
 		// Feature addition: link clipboard entries use Markdown preview so the
 		// existing UI markdown renderer can expose a clickable URL without
@@ -1563,6 +1870,17 @@ func (c *ClipboardPlugin) convertTextRecord(ctx context.Context, record Clipboar
 		previewType = plugin.WoxPreviewTypeMarkdown
 		previewData = formatClipboardLinkMarkdown(record.Content, normalizedLink)
 	}
+	if richData != nil {
+		previewTags = append(previewTags, plugin.WoxPreviewTag{Label: strings.ToUpper(*record.RichFormat), Tooltip: "i18n:plugin_clipboard_rich_format"})
+		if normalizedLink == "" && richData.GetType() == clipboard.ClipboardTypeHTML {
+			// HTML reuses the markdown preview for the same reason as links.
+			// RTF has no such renderer and keeps the plain text preview.
+			if markdown := convertClipboardHTMLToMarkdown(*record.RichContent); markdown != "" {
+				previewType = plugin.WoxPreviewTypeMarkdown
+				previewData = markdown
+			}
+		}
+	}
 
 	return plugin.QueryResult{
 		Title:      title,
@@ -1572,14 +1890,9 @@ func (c *ClipboardPlugin) convertTextRecord(ctx context.Context, record Clipboar
 		Preview: plugin.WoxPreview{
 			PreviewType: previewType,
 			PreviewData: previewData,
-			PreviewTags: []plugin.WoxPreviewTag{
-				{Label: util.FormatTimestamp(record.Timestamp), Tooltip: "i18n:plugin_clipboard_copy_date"},
-				// Preview pills show values only, so the character unit belongs in
-				// the value. Keep that unit localized instead of hard-coding a
-				// Chinese suffix into English and other languages.
-				{Label: fmt.Sprintf(c.api.GetTranslation(ctx, "plugin_clipboard_copy_characters_value"), utf8.RuneCountInString(record.Content)), Tooltip: "i18n:plugin_clipboard_copy_characters"},
-			},
+			PreviewTags: previewTags,
 		},
+		Tails: tails,
 
 		Score:   record.Timestamp,
 		Actions: actions,
@@ -2071,6 +2384,8 @@ func (c *ClipboardPlugin) startCleanupRoutine(ctx context.Context) {
 
 	ticker := time.NewTicker(30 * time.Minute) // Run cleanup every 30 minutes
 	defer ticker.Stop()
+	sensitiveTicker := time.NewTicker(time.Minute) // Sensitive TTLs are measured in minutes
+	defer sensitiveTicker.Stop()
 
 	for {
 		select {
@@ -2078,6 +2393,8 @@ func (c *ClipboardPlugin) startCleanupRoutine(ctx context.Context) {
 			return
 		case <-ticker.C:
 			c.performCleanup(ctx)
+		case <-sensitiveTicker.C:
+			c.deleteExpiredSensitiveRecords(ctx)
 		}
 	}
 }
@@ -2100,6 +2417,7 @@ func (c *ClipboardPlugin) performCleanup(ctx context.Context) {
 	} else if deletedCount > 0 {
 		c.api.Log(ctx, plugin.LogLevelInfo, fmt.Sprintf("deleted %d expired records", deletedCount))
 	}
+	c.deleteExpiredSensitiveRecords(ctx)
 
 	// Clean up orphaned cache files
 	c.cleanupOrphanedCacheFiles(ctx)
@@ -2249,17 +2567,49 @@ func (c *ClipboardPlugin) getFavoriteItems(ctx context.Context) ([]FavoriteClipb
 	return favorites, nil
 }
 
-// saveFavoriteItems saves favorite items to settings
+// saveFavoriteItems saves favorite items to settings. The list itself stays
+// local; cloud sync replicates each favorite as its own record so two devices
+// editing different favorites never overwrite each other.
 func (c *ClipboardPlugin) saveFavoriteItems(ctx context.Context, favorites []FavoriteClipboardItem) error {
 	favoritesJson, err := json.Marshal(favorites)
 	if err != nil {
 		return fmt.Errorf("failed to marshal favorites: %w", err)
 	}
 
-	c.api.SaveSetting(ctx, favoritesSettingKey, string(favoritesJson), false)
+	previous, _ := c.getFavoriteItems(ctx)
+	result := c.api.SetSetting(ctx, plugin.SetSettingOption{Key: favoritesSettingKey, Value: string(favoritesJson), IsLocal: true})
+	if !result.Success {
+		return fmt.Errorf("failed to save favorites: %s", result.ErrMsg)
+	}
+
+	c.logFavoriteSyncChanges(ctx, previous, favorites)
 	return nil
 }
 
+// logFavoriteSyncChanges queues one cloud sync record per added, changed or removed favorite.
+func (c *ClipboardPlugin) logFavoriteSyncChanges(ctx context.Context, previous []FavoriteClipboardItem, current []FavoriteClipboardItem) {
+	previousByID := make(map[string]FavoriteClipboardItem, len(previous))
+	for _, item := range previous {
+		previousByID[item.ID] = item
+	}
+
+	for _, item := range current {
+		old, existed := previousByID[item.ID]
+		delete(previousByID, item.ID)
+		if existed && reflect.DeepEqual(old, item) {
+			continue
+		}
+		if err := cloudsync.LogEntityUpsert(ctx, cloudsync.EntityClipboardFavorite, PluginID, item.ID, item); err != nil {
+			c.api.Log(ctx, plugin.LogLevelWarning, fmt.Sprintf("failed to log favorite %s for cloud sync: %s", item.ID, err.Error()))
+		}
+	}
+	for id := range previousByID {
+		if err := cloudsync.LogEntityDelete(ctx, cloudsync.EntityClipboardFavorite, PluginID, id); err != nil {
+			c.api.Log(ctx, plugin.LogLevelWarning, fmt.Sprintf("failed to log favorite removal %s for cloud sync: %s", id, err.Error()))
+		}
+	}
+}
+
 // addToFavorites adds an item to favorites settings
 func (c *ClipboardPlugin) addToFavorites(ctx context.Context, record ClipboardRecord) error {
 	favorites, err := c.getFavoriteItems(ctx)
@@ -2276,20 +2626,22 @@ func (c *ClipboardPlugin) addToFavorites(ctx context.Context, record ClipboardRe
 
 	// Convert ClipboardRecord to FavoriteClipboardItem
 	favoriteItem := FavoriteClipboardItem{
-		ID:        record.ID,
-		Type:      record.Type,
-		Content:   record.Content,
-		FilePath:  record.FilePath,
-		FilePaths: append([]string(nil), record.FilePaths...),
-		ImageHash: record.ImageHash,
-		IconData:  record.IconData,
-		Width:     record.Width,
-		Height:    record.Height,
-		FileSize:  record.FileSize,
-		Alias:     record.Alias,
-		OCRText:   record.OCRText,
-		Timestamp: record.Timestamp,
-		CreatedAt: record.CreatedAt.Unix(),
+		ID:          record.ID,
+		Type:        record.Type,
+		Content:     record.Content,
+		FilePath:    record.FilePath,
+		FilePaths:   append([]string(nil), record.FilePaths...),
+		ImageHash:   record.ImageHash,
+		IconData:    record.IconData,
+		Width:       record.Width,
+		Height:      record.Height,
+		FileSize:    record.FileSize,
+		Alias:       record.Alias,
+		OCRText:     record.OCRText,
+		RichFormat:  record.RichFormat,
+		RichContent: record.RichContent,
+		Timestamp:   record.Timestamp,
+		CreatedAt:   record.CreatedAt.Unix(),
 	}
 
 	favorites = append(favorites, favoriteItem)
@@ -2317,21 +2669,23 @@ func (c *ClipboardPlugin) removeFromFavorites(ctx context.Context, id string) er
 // convertFavoriteToRecord converts FavoriteClipboardItem to ClipboardRecord
 func (c *ClipboardPlugin) convertFavoriteToRecord(item FavoriteClipboardItem) ClipboardRecord {
 	return ClipboardRecord{
-		ID:         item.ID,
-		Type:       item.Type,
-		Content:    item.Content,
-		FilePath:   item.FilePath,
-		FilePaths:  append([]string(nil), item.FilePaths...),
-		ImageHash:  item.ImageHash,
-		IconData:   item.IconData,
-		Width:      item.Width,
-		Height:     item.Height,
-		FileSize:   item.FileSize,
-		Alias:      item.Alias,
-		OCRText:    item.OCRText,
-		Timestamp:  item.Timestamp,
-		IsFavorite: true,
-		CreatedAt:  time.Unix(item.CreatedAt, 0),
+		ID:          item.ID,
+		Type:        item.Type,
+		Content:     item.Content,
+		FilePath:    item.FilePath,
+		FilePaths:   append([]string(nil), item.FilePaths...),
+		ImageHash:   item.ImageHash,
+		IconData:    item.IconData,
+		Width:       item.Width,
+		Height:      item.Height,
+		FileSize:    item.FileSize,
+		Alias:       item.Alias,
+		OCRText:     item.OCRText,
+		RichFormat:  item.RichFormat,
+		RichContent: item.RichContent,
+		Timestamp:   item.Timestamp,
+		IsFavorite:  true,
+		CreatedAt:   time.Unix(item.CreatedAt, 0),
 	}
 }
 
@@ -2394,6 +2748,9 @@ func (c *ClipboardPlugin) updateFavoriteContent(ctx context.Context, id string,
 	for i := range favorites {
 		if favorites[i].ID == id {
 			favorites[i].Content = newContent
+			// Edited text no longer matches the copied markup
+			favorites[i].RichFormat = nil
+			favorites[i].RichContent = nil
 			return c.saveFavoriteItems(ctx, favorites)
 		}
 	}
@@ -0,0 +1,132 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"wox/common"
+	"wox/plugin"
+	"wox/util"
+)
+
+const (
+	PluginID                       = "5f815d98-27f5-488d-a756-c317ea39935b"
+	PluginCommandSearch            = "search"
+	PluginCommandDataQuery         = "query"
+	PluginCommandDataType          = "type"
+	PluginCommandDataLimit         = "limit"
+	PluginCommandResultDataResults = "results"
+
+	clipboardCommandDefaultLimit = 20
+	clipboardCommandMaxLimit     = 100
+)
+
+// ClipboardCommandRecord is the history entry returned by the search plugin
+// command. Image bytes and rich markup stay local; callers get the text parts.
+type ClipboardCommandRecord struct {
+	ID         string
+	Type       string
+	Content    string
+	FilePath   string   `json:",omitempty"`
+	FilePaths  []string `json:",omitempty"`
+	Alias      string   `json:",omitempty"`
+	OCRText    string   `json:",omitempty"`
+	RichFormat string   `json:",omitempty"`
+	Timestamp  int64
+}
+
+// handlePluginCommand lets the MCP server search clipboard history. Other
+// plugins are refused: the history holds whatever the user copied, and invoke
+// rights alone are not consent to read it. Sensitive and expired records are
+// never returned.
+func (c *ClipboardPlugin) handlePluginCommand(ctx context.Context, request plugin.PluginCommandRequest) plugin.PluginCommandResult {
+	if request.Command != PluginCommandSearch {
+		return plugin.PluginCommandResult{Handled: false}
+	}
+	if request.CallerPluginId != "" {
+		util.GetLogger().Warn(ctx, fmt.Sprintf("denied clipboard search from plugin %s", request.CallerPluginId))
+		return plugin.PluginCommandResult{Handled: true, Message: "clipboard history is only available to Wox"}
+	}
+	if c.db == nil {
+		return plugin.PluginCommandResult{Handled: true, Message: "clipboard history is unavailable"}
+	}
+
+	limit := clipboardCommandDefaultLimit
+	if raw := strings.TrimSpace(request.Data[PluginCommandDataLimit]); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			return plugin.PluginCommandResult{Handled: true, Message: "limit must be a positive number"}
+		}
+		limit = min(parsed, clipboardCommandMaxLimit)
+	}
+
+	selectedType := strings.ToLower(strings.TrimSpace(request.Data[PluginCommandDataType]))
+	switch selectedType {
+	case "":
+		selectedType = clipboardTypeRefinementAll
+	case clipboardTypeRefinementAll, clipboardTypeRefinementText, clipboardTypeRefinementFile, clipboardTypeRefinementImage, clipboardTypeRefinementLink:
+	default:
+		return plugin.PluginCommandResult{Handled: true, Message: "unknown clipboard type: " + selectedType}
+	}
+
+	search := strings.TrimSpace(request.Data[PluginCommandDataQuery])
+	var records []ClipboardRecord
+	var err error
+	if search == "" {
+		records, err = c.db.GetRecent(ctx, clipboardCommandMaxLimit, 0)
+	} else {
+		records, err = c.searchClipboardRecords(ctx, search, selectedType, limit)
+	}
+	if err != nil {
+		return plugin.PluginCommandResult{Handled: true, Message: err.Error()}
+	}
+
+	now := util.GetSystemTimestamp()
+	results := make([]ClipboardCommandRecord, 0, limit)
+	for _, record := range records {
+		if isClipboardRecordSensitive(record) || isClipboardRecordExpired(record, now) {
+			continue
+		}
+		if !clipboardRecordMatchesType(record.Type, record.Content, selectedType) {
+			continue
+		}
+		results = append(results, toClipboardCommandRecord(record))
+		if len(results) >= limit {
+			break
+		}
+	}
+
+	payload, err := json.Marshal(results)
+	if err != nil {
+		return plugin.PluginCommandResult{Handled: true, Message: err.Error()}
+	}
+	return plugin.PluginCommandResult{
+		Handled: true,
+		Data: common.ContextData{
+			PluginCommandResultDataResults: string(payload),
+		},
+	}
+}
+
+func toClipboardCommandRecord(record ClipboardRecord) ClipboardCommandRecord {
+	converted := ClipboardCommandRecord{
+		ID:        record.ID,
+		Type:      record.Type,
+		Content:   record.Content,
+		FilePath:  record.FilePath,
+		FilePaths: record.FilePaths,
+		Timestamp: record.Timestamp,
+	}
+	if record.Alias != nil {
+		converted.Alias = *record.Alias
+	}
+	if record.OCRText != nil {
+		converted.OCRText = *record.OCRText
+	}
+	if record.RichFormat != nil {
+		converted.RichFormat = *record.RichFormat
+	}
+	return converted
+}
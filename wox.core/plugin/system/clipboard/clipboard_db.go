@@ -17,25 +17,36 @@ import (
 // ClipboardDB handles all database operations for clipboard history
 type ClipboardDB struct {
 	db *sql.DB
+	// ftsEnabled is false when the sqlite build lacks FTS5; searches then use LIKE.
+	ftsEnabled bool
 }
 
 // ClipboardRecord represents a clipboard history record in the database
 type ClipboardRecord struct {
-	ID         string
-	Type       string
-	Content    string // For text content or metadata
-	FilePath   string // For image files
-	FilePaths  []string
-	ImageHash  *string // For image deduplication hash, nullable
-	IconData   *string // For storing icon data (base64 or file path), nullable
-	Width      *int    // For image width, nullable
-	Height     *int    // For image height, nullable
-	FileSize   *int64  // For file size in bytes, nullable
-	Alias      *string // For user-defined alias, nullable
-	OCRText    *string // For local OCR text extracted from image records, nullable
-	Timestamp  int64
-	IsFavorite bool
-	CreatedAt  time.Time
+	ID        string
+	Type      string
+	Content   string // For text content or metadata
+	FilePath  string // For image files
+	FilePaths []string
+	ImageHash *string // For image deduplication hash, nullable
+	IconData  *string // For storing icon data (base64 or file path), nullable
+	Width     *int    // For image width, nullable
+	Height    *int    // For image height, nullable
+	FileSize  *int64  // For file size in bytes, nullable
+	Alias     *string // For user-defined alias, nullable
+	OCRText   *string // For local OCR text extracted from image records, nullable
+	// RichFormat is "html" or "rtf" when a text record was copied with
+	// formatting, and RichContent holds that markup. Content stays the plain
+	// text alternative so search and plain pastes are unchanged.
+	RichFormat  *string
+	RichContent *string
+	// SensitiveRule names the detector rule that flagged the content, nullable.
+	// Sensitive records are masked in results and excluded from search.
+	SensitiveRule *string
+	ExpiresAt     *int64 // Millisecond timestamp after which a sensitive record is deleted, nullable
+	Timestamp     int64
+	IsFavorite    bool
+	CreatedAt     time.Time
 }
 
 // NewClipboardDB creates a new clipboard database instance
@@ -102,6 +113,10 @@ func (c *ClipboardDB) initTables(ctx context.Context) error {
 		file_size INTEGER,
 		alias TEXT,
 		ocr_text TEXT,
+		rich_format TEXT,
+		rich_content TEXT,
+		sensitive_rule TEXT,
+		expires_at INTEGER,
 		timestamp INTEGER NOT NULL,
 		is_favorite BOOLEAN DEFAULT FALSE,
 		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
@@ -131,6 +146,14 @@ func (c *ClipboardDB) initTables(ctx context.Context) error {
 		// the existing history table so Image refinement queries can match text
 		// seen inside screenshots without scanning image files on every query.
 		`ALTER TABLE clipboard_history ADD COLUMN ocr_text TEXT`,
+		// Feature addition: secrets detected in copied text are kept only for a
+		// short TTL, so records carry the matched rule and an absolute expiry.
+		`ALTER TABLE clipboard_history ADD COLUMN sensitive_rule TEXT`,
+		`ALTER TABLE clipboard_history ADD COLUMN expires_at INTEGER`,
+		// Feature addition: HTML and RTF copies keep their markup next to the
+		// plain text so history can paste them back with formatting.
+		`ALTER TABLE clipboard_history ADD COLUMN rich_format TEXT`,
+		`ALTER TABLE clipboard_history ADD COLUMN rich_content TEXT`,
 	}
 
 	for _, alterSQL := range alterTableSQLs {
@@ -149,6 +172,14 @@ func (c *ClipboardDB) initTables(ctx context.Context) error {
 		util.GetLogger().Info(ctx, fmt.Sprintf("Failed to add OCR text index: %s", err.Error()))
 	}
 
+	// Feature addition: LIKE '%term%' scanned the whole history and could not
+	// rank hits, so content, alias and OCR text are indexed with FTS5 as well.
+	// Created after the column migration because the index covers ocr_text.
+	// A broken index only costs ranking, so it must not block clipboard history.
+	if err := c.initFullTextSearch(ctx); err != nil {
+		util.GetLogger().Warn(ctx, fmt.Sprintf("Failed to initialize clipboard full text search: %s", err.Error()))
+	}
+
 	return nil
 }
 
@@ -160,13 +191,13 @@ func (c *ClipboardDB) Insert(ctx context.Context, record ClipboardRecord) error
 	}
 
 	insertSQL := `
-	INSERT INTO clipboard_history (id, type, content, file_path, file_paths, image_hash, icon_data, width, height, file_size, alias, ocr_text, timestamp, is_favorite, created_at)
-	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	INSERT INTO clipboard_history (id, type, content, file_path, file_paths, image_hash, icon_data, width, height, file_size, alias, ocr_text, rich_format, rich_content, sensitive_rule, expires_at, timestamp, is_favorite, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	_, err = c.db.ExecContext(ctx, insertSQL,
 		record.ID, record.Type, record.Content, record.FilePath, filePathsJSON, record.ImageHash, record.IconData,
-		record.Width, record.Height, record.FileSize, record.Alias, record.OCRText,
+		record.Width, record.Height, record.FileSize, record.Alias, record.OCRText, record.RichFormat, record.RichContent, record.SensitiveRule, record.ExpiresAt,
 		record.Timestamp, record.IsFavorite, record.CreatedAt)
 
 	return err
@@ -181,13 +212,13 @@ func (c *ClipboardDB) Update(ctx context.Context, record ClipboardRecord) error
 
 	updateSQL := `
 	UPDATE clipboard_history
-	SET type = ?, content = ?, file_path = ?, file_paths = ?, image_hash = ?, icon_data = ?, width = ?, height = ?, file_size = ?, alias = ?, ocr_text = ?, timestamp = ?, is_favorite = ?
+	SET type = ?, content = ?, file_path = ?, file_paths = ?, image_hash = ?, icon_data = ?, width = ?, height = ?, file_size = ?, alias = ?, ocr_text = ?, rich_format = ?, rich_content = ?, sensitive_rule = ?, expires_at = ?, timestamp = ?, is_favorite = ?
 	WHERE id = ?
 	`
 
 	_, err = c.db.ExecContext(ctx, updateSQL,
 		record.Type, record.Content, record.FilePath, filePathsJSON, record.ImageHash, record.IconData,
-		record.Width, record.Height, record.FileSize, record.Alias, record.OCRText,
+		record.Width, record.Height, record.FileSize, record.Alias, record.OCRText, record.RichFormat, record.RichContent, record.SensitiveRule, record.ExpiresAt,
 		record.Timestamp, record.IsFavorite, record.ID)
 
 	return err
@@ -200,9 +231,10 @@ func (c *ClipboardDB) UpdateTimestamp(ctx context.Context, id string, timestamp
 	return err
 }
 
-// UpdateContent updates the content of a record
+// UpdateContent updates the content of a record. Stored markup no longer
+// matches edited text, so the record becomes plain text.
 func (c *ClipboardDB) UpdateContent(ctx context.Context, id string, content string) error {
-	updateSQL := `UPDATE clipboard_history SET content = ? WHERE id = ?`
+	updateSQL := `UPDATE clipboard_history SET content = ?, rich_format = NULL, rich_content = NULL WHERE id = ?`
 	_, err := c.db.ExecContext(ctx, updateSQL, content, id)
 	return err
 }
@@ -231,7 +263,7 @@ func (c *ClipboardDB) Delete(ctx context.Context, id string) error {
 // GetRecent retrieves recent clipboard records with pagination
 func (c *ClipboardDB) GetRecent(ctx context.Context, limit, offset int) ([]ClipboardRecord, error) {
 	querySQL := `
-	SELECT id, type, content, file_path, file_paths, image_hash, icon_data, width, height, file_size, alias, ocr_text, timestamp, is_favorite, created_at
+	SELECT id, type, content, file_path, file_paths, image_hash, icon_data, width, height, file_size, alias, ocr_text, rich_format, rich_content, sensitive_rule, expires_at, timestamp, is_favorite, created_at
 	FROM clipboard_history
 	ORDER BY timestamp DESC
 	LIMIT ? OFFSET ?
@@ -249,7 +281,7 @@ func (c *ClipboardDB) GetRecent(ctx context.Context, limit, offset int) ([]Clipb
 // GetRecentByType retrieves recent clipboard records for one content type.
 func (c *ClipboardDB) GetRecentByType(ctx context.Context, recordType string, limit, offset int) ([]ClipboardRecord, error) {
 	querySQL := `
-	SELECT id, type, content, file_path, file_paths, image_hash, icon_data, width, height, file_size, alias, ocr_text, timestamp, is_favorite, created_at
+	SELECT id, type, content, file_path, file_paths, image_hash, icon_data, width, height, file_size, alias, ocr_text, rich_format, rich_content, sensitive_rule, expires_at, timestamp, is_favorite, created_at
 	FROM clipboard_history
 	WHERE type = ?
 	ORDER BY timestamp DESC
@@ -265,50 +297,21 @@ func (c *ClipboardDB) GetRecentByType(ctx context.Context, recordType string, li
 	return c.scanRecords(rows)
 }
 
-// SearchText searches for text content in clipboard history
+// SearchText searches text records by content and alias, ranked by relevance
+// and recency. Quoted words match as a phrase, other words as prefixes.
 func (c *ClipboardDB) SearchText(ctx context.Context, searchTerm string, limit int) ([]ClipboardRecord, error) {
-	querySQL := `
-	SELECT id, type, content, file_path, file_paths, image_hash, icon_data, width, height, file_size, alias, ocr_text, timestamp, is_favorite, created_at
-	FROM clipboard_history
-	WHERE type = ? AND (content LIKE ? OR alias LIKE ?)
-	ORDER BY timestamp DESC
-	LIMIT ?
-	`
-
-	searchPattern := "%" + searchTerm + "%"
-	rows, err := c.db.QueryContext(ctx, querySQL, string(clipboard.ClipboardTypeText), searchPattern, searchPattern, limit)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	return c.scanRecords(rows)
+	return c.searchRecords(ctx, searchTerm, string(clipboard.ClipboardTypeText), false, limit)
 }
 
-// SearchByType searches clipboard content and aliases inside one content type.
+// SearchByType searches clipboard content, aliases and OCR text inside one content type.
 func (c *ClipboardDB) SearchByType(ctx context.Context, searchTerm string, recordType string, limit int) ([]ClipboardRecord, error) {
-	querySQL := `
-	SELECT id, type, content, file_path, file_paths, image_hash, icon_data, width, height, file_size, alias, ocr_text, timestamp, is_favorite, created_at
-	FROM clipboard_history
-	WHERE type = ? AND (content LIKE ? OR alias LIKE ? OR ocr_text LIKE ?)
-	ORDER BY timestamp DESC
-	LIMIT ?
-	`
-
-	searchPattern := "%" + searchTerm + "%"
-	rows, err := c.db.QueryContext(ctx, querySQL, recordType, searchPattern, searchPattern, searchPattern, limit)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	return c.scanRecords(rows)
+	return c.searchRecords(ctx, searchTerm, recordType, true, limit)
 }
 
 // GetByID retrieves a specific record by ID
 func (c *ClipboardDB) GetByID(ctx context.Context, id string) (*ClipboardRecord, error) {
 	querySQL := `
-	SELECT id, type, content, file_path, file_paths, image_hash, icon_data, width, height, file_size, alias, ocr_text, timestamp, is_favorite, created_at
+	SELECT id, type, content, file_path, file_paths, image_hash, icon_data, width, height, file_size, alias, ocr_text, rich_format, rich_content, sensitive_rule, expires_at, timestamp, is_favorite, created_at
 	FROM clipboard_history
 	WHERE id = ?
 	`
@@ -318,7 +321,7 @@ func (c *ClipboardDB) GetByID(ctx context.Context, id string) (*ClipboardRecord,
 	var filePathsJSON sql.NullString
 
 	err := row.Scan(&record.ID, &record.Type, &record.Content,
-		&record.FilePath, &filePathsJSON, &record.ImageHash, &record.IconData, &record.Width, &record.Height, &record.FileSize, &record.Alias, &record.OCRText,
+		&record.FilePath, &filePathsJSON, &record.ImageHash, &record.IconData, &record.Width, &record.Height, &record.FileSize, &record.Alias, &record.OCRText, &record.RichFormat, &record.RichContent, &record.SensitiveRule, &record.ExpiresAt,
 		&record.Timestamp, &record.IsFavorite, &record.CreatedAt)
 
 	if err == sql.ErrNoRows {
@@ -362,6 +365,17 @@ func (c *ClipboardDB) DeleteExpired(ctx context.Context, textDays, imageDays int
 	return result.RowsAffected()
 }
 
+// DeleteExpiredSensitive removes sensitive records whose TTL has passed.
+func (c *ClipboardDB) DeleteExpiredSensitive(ctx context.Context) (int64, error) {
+	deleteSQL := `DELETE FROM clipboard_history WHERE expires_at IS NOT NULL AND expires_at <= ? AND is_favorite = FALSE`
+	result, err := c.db.ExecContext(ctx, deleteSQL, util.GetSystemTimestamp())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
 // EnforceMaxCount ensures the total number of records doesn't exceed maxCount
 func (c *ClipboardDB) EnforceMaxCount(ctx context.Context, maxCount int) (int64, error) {
 	// First, count total records
@@ -468,7 +482,7 @@ func (c *ClipboardDB) scanRecords(rows *sql.Rows) ([]ClipboardRecord, error) {
 		var record ClipboardRecord
 		var filePathsJSON sql.NullString
 		err := rows.Scan(&record.ID, &record.Type, &record.Content,
-			&record.FilePath, &filePathsJSON, &record.ImageHash, &record.IconData, &record.Width, &record.Height, &record.FileSize, &record.Alias, &record.OCRText,
+			&record.FilePath, &filePathsJSON, &record.ImageHash, &record.IconData, &record.Width, &record.Height, &record.FileSize, &record.Alias, &record.OCRText, &record.RichFormat, &record.RichContent, &record.SensitiveRule, &record.ExpiresAt,
 			&record.Timestamp, &record.IsFavorite, &record.CreatedAt)
 		if err != nil {
 			return nil, err
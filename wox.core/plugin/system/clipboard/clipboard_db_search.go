@@ -0,0 +1,182 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"wox/util"
+)
+
+var (
+	clipboardTextSearchColumns = []string{"content", "alias"}
+	clipboardAllSearchColumns  = []string{"content", "alias", "ocr_text"}
+)
+
+const (
+	// clipboardSearchRecencyMillis is the age at which a history record's BM25
+	// score is halved. Clipboard history is mostly re-used within days, so an
+	// old entry needs a clearly better text match to outrank a recent one.
+	clipboardSearchRecencyMillis = int64(30 * 24 * 60 * 60 * 1000)
+)
+
+// clipboardFTSTriggers keep clipboard_history_fts in sync with the history
+// table. The index uses external content, so deletes must replay the old
+// column values for FTS5 to remove the right tokens.
+var clipboardFTSTriggers = []struct {
+	name string
+	sql  string
+}{
+	{
+		name: "clipboard_history_fts_ai",
+		sql: `CREATE TRIGGER IF NOT EXISTS clipboard_history_fts_ai AFTER INSERT ON clipboard_history BEGIN
+			INSERT INTO clipboard_history_fts(rowid, content, alias, ocr_text) VALUES (new.rowid, new.content, new.alias, new.ocr_text);
+		END`,
+	},
+	{
+		name: "clipboard_history_fts_ad",
+		sql: `CREATE TRIGGER IF NOT EXISTS clipboard_history_fts_ad AFTER DELETE ON clipboard_history BEGIN
+			INSERT INTO clipboard_history_fts(clipboard_history_fts, rowid, content, alias, ocr_text) VALUES ('delete', old.rowid, old.content, old.alias, old.ocr_text);
+		END`,
+	},
+	{
+		// Timestamp bumps and favorite toggles are frequent and don't change
+		// searchable text, so only text column updates touch the index.
+		name: "clipboard_history_fts_au",
+		sql: `CREATE TRIGGER IF NOT EXISTS clipboard_history_fts_au AFTER UPDATE OF content, alias, ocr_text ON clipboard_history BEGIN
+			INSERT INTO clipboard_history_fts(clipboard_history_fts, rowid, content, alias, ocr_text) VALUES ('delete', old.rowid, old.content, old.alias, old.ocr_text);
+			INSERT INTO clipboard_history_fts(rowid, content, alias, ocr_text) VALUES (new.rowid, new.content, new.alias, new.ocr_text);
+		END`,
+	},
+}
+
+// initFullTextSearch creates the FTS5 index over content, alias and OCR text.
+// Builds without FTS5 keep working on LIKE queries; their triggers are dropped
+// because every history write would otherwise fail with "no such module".
+func (c *ClipboardDB) initFullTextSearch(ctx context.Context) error {
+	if _, err := c.db.ExecContext(ctx, `CREATE VIRTUAL TABLE IF NOT EXISTS temp.clipboard_fts5_probe USING fts5(value)`); err != nil {
+		for _, trigger := range clipboardFTSTriggers {
+			if _, dropErr := c.db.ExecContext(ctx, `DROP TRIGGER IF EXISTS `+trigger.name); dropErr != nil {
+				return fmt.Errorf("failed to drop clipboard FTS trigger %s: %w", trigger.name, dropErr)
+			}
+		}
+		util.GetLogger().Warn(ctx, fmt.Sprintf("clipboard full text search disabled, sqlite FTS5 is unavailable: %s", err.Error()))
+		return nil
+	}
+	if _, err := c.db.ExecContext(ctx, `DROP TABLE IF EXISTS temp.clipboard_fts5_probe`); err != nil {
+		return fmt.Errorf("failed to drop clipboard FTS5 probe table: %w", err)
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Missing triggers mean the index is new, or a build without FTS5 wrote
+	// history the index never saw. Either way it is rebuilt from the table.
+	var existingTriggers int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name IN (?, ?, ?)`,
+		clipboardFTSTriggers[0].name, clipboardFTSTriggers[1].name, clipboardFTSTriggers[2].name).Scan(&existingTriggers); err != nil {
+		return err
+	}
+
+	// clipboard_history has no INTEGER PRIMARY KEY, so the index is keyed by the
+	// implicit rowid. remove_diacritics lets "cafe" match "café", and the prefix
+	// index keeps short as-you-type prefixes from expanding over the whole vocabulary.
+	if _, err := tx.ExecContext(ctx, `CREATE VIRTUAL TABLE IF NOT EXISTS clipboard_history_fts USING fts5(
+		content,
+		alias,
+		ocr_text,
+		content='clipboard_history',
+		content_rowid='rowid',
+		tokenize='unicode61 remove_diacritics 2',
+		prefix='2 3'
+	)`); err != nil {
+		return fmt.Errorf("failed to create clipboard FTS table: %w", err)
+	}
+	for _, trigger := range clipboardFTSTriggers {
+		if _, err := tx.ExecContext(ctx, trigger.sql); err != nil {
+			return fmt.Errorf("failed to create clipboard FTS trigger %s: %w", trigger.name, err)
+		}
+	}
+	if existingTriggers < len(clipboardFTSTriggers) {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO clipboard_history_fts(clipboard_history_fts) VALUES ('rebuild')`); err != nil {
+			return fmt.Errorf("failed to backfill clipboard FTS table: %w", err)
+		}
+		util.GetLogger().Info(ctx, "clipboard full text index rebuilt from history")
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	c.ftsEnabled = true
+	return nil
+}
+
+// searchFullText runs an FTS5 match inside one record type. BM25 is divided by
+// an age factor so relevance decides between similar-age records while fresh
+// history still wins over an equally good match from months ago.
+func (c *ClipboardDB) searchFullText(ctx context.Context, match string, recordType string, limit int) ([]ClipboardRecord, error) {
+	querySQL := `
+	SELECT h.id, h.type, h.content, h.file_path, h.file_paths, h.image_hash, h.icon_data, h.width, h.height, h.file_size, h.alias, h.ocr_text, h.rich_format, h.rich_content, h.sensitive_rule, h.expires_at, h.timestamp, h.is_favorite, h.created_at
+	FROM clipboard_history_fts f
+	JOIN clipboard_history h ON h.rowid = f.rowid
+	WHERE clipboard_history_fts MATCH ? AND h.type = ? AND h.sensitive_rule IS NULL
+	ORDER BY bm25(clipboard_history_fts, 1.0, 2.0, 1.0) / (1.0 + MAX(0, ? - h.timestamp) * 1.0 / ?) ASC, h.timestamp DESC
+	LIMIT ?
+	`
+
+	rows, err := c.db.QueryContext(ctx, querySQL, match, recordType, util.GetSystemTimestamp(), clipboardSearchRecencyMillis, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return c.scanRecords(rows)
+}
+
+// searchLike is the pre-FTS search path, kept for builds without FTS5 and for
+// queries that have no indexable token, such as "://" or "#".
+func (c *ClipboardDB) searchLike(ctx context.Context, searchTerm string, recordType string, includeOCR bool, limit int) ([]ClipboardRecord, error) {
+	condition := `content LIKE ? OR alias LIKE ?`
+	searchPattern := "%" + searchTerm + "%"
+	args := []any{recordType, searchPattern, searchPattern}
+	if includeOCR {
+		condition += ` OR ocr_text LIKE ?`
+		args = append(args, searchPattern)
+	}
+	args = append(args, limit)
+
+	querySQL := `
+	SELECT id, type, content, file_path, file_paths, image_hash, icon_data, width, height, file_size, alias, ocr_text, rich_format, rich_content, sensitive_rule, expires_at, timestamp, is_favorite, created_at
+	FROM clipboard_history
+	WHERE type = ? AND sensitive_rule IS NULL AND (` + condition + `)
+	ORDER BY timestamp DESC
+	LIMIT ?
+	`
+
+	rows, err := c.db.QueryContext(ctx, querySQL, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return c.scanRecords(rows)
+}
+
+func (c *ClipboardDB) searchRecords(ctx context.Context, searchTerm string, recordType string, includeOCR bool, limit int) ([]ClipboardRecord, error) {
+	if c.ftsEnabled {
+		columns := clipboardTextSearchColumns
+		if includeOCR {
+			columns = clipboardAllSearchColumns
+		}
+		if match, ok := util.BuildFTSQuery(searchTerm, columns); ok {
+			records, err := c.searchFullText(ctx, match, recordType, limit)
+			if err == nil {
+				return records, nil
+			}
+			util.GetLogger().Warn(ctx, fmt.Sprintf("clipboard full text search failed for %q, falling back to LIKE: %s", searchTerm, err.Error()))
+		}
+	}
+
+	return c.searchLike(ctx, searchTerm, recordType, includeOCR, limit)
+}
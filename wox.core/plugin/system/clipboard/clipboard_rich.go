@@ -0,0 +1,290 @@
+package system
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"wox/util/clipboard"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// clipboardRichContentMaxBytes skips markup that would bloat the history
+// database, such as web pages copied with inline base64 images. The plain text
+// alternative is still stored.
+const clipboardRichContentMaxBytes = 2 * 1024 * 1024
+
+var (
+	clipboardMarkdownBlankLines      = regexp.MustCompile(`\n{3,}`)
+	clipboardMarkdownWhitespaceLines = regexp.MustCompile(`(?m)^[ \t]+$`)
+	clipboardMarkdownEscaper         = strings.NewReplacer(`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`)
+)
+
+// splitClipboardRichData turns formatted clipboard data into the text data it
+// is stored as, plus the format and markup kept alongside it.
+func splitClipboardRichData(data clipboard.Data) (clipboard.Data, string, string) {
+	switch richData := data.(type) {
+	case *clipboard.HTMLData:
+		return &clipboard.TextData{Text: richData.Text, Hints: richData.Hints}, string(clipboard.ClipboardTypeHTML), richData.HTML
+	case *clipboard.RTFData:
+		return &clipboard.TextData{Text: richData.Text, Hints: richData.Hints}, string(clipboard.ClipboardTypeRTF), richData.RTF
+	default:
+		return data, "", ""
+	}
+}
+
+// clipboardRecordRichData returns the formatted data to write for a rich paste,
+// or nil when the record has no usable markup. Sensitive records never carry
+// markup, but are checked anyway so a secret cannot leak through formatting.
+func clipboardRecordRichData(record ClipboardRecord) clipboard.Data {
+	if record.RichFormat == nil || record.RichContent == nil || *record.RichContent == "" || isClipboardRecordSensitive(record) {
+		return nil
+	}
+	switch clipboard.Type(*record.RichFormat) {
+	case clipboard.ClipboardTypeHTML:
+		return &clipboard.HTMLData{HTML: *record.RichContent, Text: record.Content}
+	case clipboard.ClipboardTypeRTF:
+		return &clipboard.RTFData{RTF: *record.RichContent, Text: record.Content}
+	default:
+		return nil
+	}
+}
+
+// convertClipboardHTMLToMarkdown renders copied HTML for the markdown preview.
+// Scripts, styles and embedded data images are dropped; links and images are
+// kept only for web and mail URLs.
+func convertClipboardHTMLToMarkdown(markup string) string {
+	doc, err := html.Parse(strings.NewReader(markup))
+	if err != nil {
+		return ""
+	}
+	return normalizeClipboardMarkdown(renderClipboardMarkdownChildren(doc))
+}
+
+func normalizeClipboardMarkdown(text string) string {
+	text = clipboardMarkdownWhitespaceLines.ReplaceAllString(text, "")
+	text = clipboardMarkdownBlankLines.ReplaceAllString(text, "\n\n")
+	return strings.TrimSpace(text)
+}
+
+func renderClipboardMarkdownChildren(node *html.Node) string {
+	var builder strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		builder.WriteString(renderClipboardMarkdownNode(child))
+	}
+	return builder.String()
+}
+
+func renderClipboardMarkdownNode(node *html.Node) string {
+	switch node.Type {
+	case html.TextNode:
+		return clipboardMarkdownEscaper.Replace(collapseClipboardHTMLWhitespace(node.Data))
+	case html.DocumentNode:
+		return renderClipboardMarkdownChildren(node)
+	case html.ElementNode:
+	default:
+		return ""
+	}
+
+	switch node.DataAtom {
+	case atom.Head, atom.Script, atom.Style, atom.Title, atom.Meta, atom.Link, atom.Noscript, atom.Template, atom.Svg:
+		return ""
+	case atom.Br:
+		return "  \n"
+	case atom.Hr:
+		return "\n\n---\n\n"
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level := int(node.Data[1] - '0')
+		return "\n\n" + strings.Repeat("#", level) + " " + strings.Join(strings.Fields(renderClipboardMarkdownChildren(node)), " ") + "\n\n"
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Header, atom.Footer, atom.Main, atom.Nav, atom.Aside, atom.Figure, atom.Figcaption:
+		return "\n\n" + renderClipboardMarkdownChildren(node) + "\n\n"
+	case atom.Strong, atom.B:
+		return wrapClipboardMarkdown(renderClipboardMarkdownChildren(node), "**")
+	case atom.Em, atom.I:
+		return wrapClipboardMarkdown(renderClipboardMarkdownChildren(node), "*")
+	case atom.Del, atom.S, atom.Strike:
+		return wrapClipboardMarkdown(renderClipboardMarkdownChildren(node), "~~")
+	case atom.Code:
+		code := clipboardHTMLNodeText(node)
+		if strings.TrimSpace(code) == "" {
+			return code
+		}
+		if strings.Contains(code, "`") {
+			return "`` " + code + " ``"
+		}
+		return "`" + code + "`"
+	case atom.Pre:
+		return "\n\n```\n" + strings.Trim(clipboardHTMLNodeText(node), "\n") + "\n```\n\n"
+	case atom.A:
+		text := renderClipboardMarkdownChildren(node)
+		href := strings.TrimSpace(clipboardHTMLAttr(node, "href"))
+		if strings.TrimSpace(text) == "" || !isClipboardMarkdownURL(href, true) {
+			return text
+		}
+		return "[" + strings.TrimSpace(text) + "](" + escapeClipboardMarkdownLinkDestination(href) + ")"
+	case atom.Img:
+		src := strings.TrimSpace(clipboardHTMLAttr(node, "src"))
+		if !isClipboardMarkdownURL(src, false) {
+			return ""
+		}
+		return "![" + escapeClipboardMarkdownLinkText(clipboardHTMLAttr(node, "alt")) + "](" + escapeClipboardMarkdownLinkDestination(src) + ")"
+	case atom.Ul, atom.Ol:
+		return "\n\n" + renderClipboardMarkdownList(node) + "\n\n"
+	case atom.Blockquote:
+		return "\n\n" + prefixClipboardMarkdownLines(normalizeClipboardMarkdown(renderClipboardMarkdownChildren(node)), "> ", "> ") + "\n\n"
+	case atom.Table:
+		return "\n\n" + renderClipboardMarkdownTable(node) + "\n\n"
+	default:
+		return renderClipboardMarkdownChildren(node)
+	}
+}
+
+// renderClipboardMarkdownList renders list items as a tight list. Nested lists
+// are indented under their item so the markdown renderer keeps the hierarchy.
+func renderClipboardMarkdownList(node *html.Node) string {
+	ordered := node.DataAtom == atom.Ol
+	index := 1
+	if start, err := strconv.Atoi(clipboardHTMLAttr(node, "start")); ordered && err == nil {
+		index = start
+	}
+
+	var items []string
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode || child.DataAtom != atom.Li {
+			continue
+		}
+		marker := "- "
+		if ordered {
+			marker = strconv.Itoa(index) + ". "
+			index++
+		}
+		content := strings.ReplaceAll(normalizeClipboardMarkdown(renderClipboardMarkdownChildren(child)), "\n\n", "\n")
+		items = append(items, prefixClipboardMarkdownLines(content, marker, strings.Repeat(" ", len(marker))))
+	}
+	return strings.Join(items, "\n")
+}
+
+// renderClipboardMarkdownTable flattens each cell to one line. The first row
+// becomes the header because copied tables often have no <th>.
+func renderClipboardMarkdownTable(node *html.Node) string {
+	var rows [][]string
+	var collectRows func(parent *html.Node)
+	collectRows = func(parent *html.Node) {
+		for child := parent.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			if child.DataAtom != atom.Tr {
+				collectRows(child)
+				continue
+			}
+			var cells []string
+			for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+				if cell.Type == html.ElementNode && (cell.DataAtom == atom.Td || cell.DataAtom == atom.Th) {
+					text := strings.Join(strings.Fields(renderClipboardMarkdownChildren(cell)), " ")
+					cells = append(cells, strings.ReplaceAll(text, "|", `\|`))
+				}
+			}
+			if len(cells) > 0 {
+				rows = append(rows, cells)
+			}
+		}
+	}
+	collectRows(node)
+	if len(rows) == 0 {
+		return ""
+	}
+
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+	var builder strings.Builder
+	for rowIndex, row := range rows {
+		for len(row) < columns {
+			row = append(row, "")
+		}
+		builder.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		if rowIndex == 0 {
+			builder.WriteString("|" + strings.Repeat(" --- |", columns) + "\n")
+		}
+	}
+	return strings.TrimRight(builder.String(), "\n")
+}
+
+// wrapClipboardMarkdown applies an emphasis marker inside the surrounding
+// whitespace, because "** bold**" is not emphasis in markdown.
+func wrapClipboardMarkdown(text string, marker string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	leading := text[:strings.Index(text, trimmed)]
+	trailing := text[len(leading)+len(trimmed):]
+	return leading + marker + trimmed + marker + trailing
+}
+
+func prefixClipboardMarkdownLines(text string, firstPrefix string, restPrefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		switch {
+		case i == 0:
+			lines[i] = firstPrefix + line
+		case line != "" || strings.TrimSpace(restPrefix) != "":
+			lines[i] = restPrefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func collapseClipboardHTMLWhitespace(text string) string {
+	var builder strings.Builder
+	lastSpace := false
+	for _, r := range text {
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f' {
+			if !lastSpace {
+				builder.WriteByte(' ')
+			}
+			lastSpace = true
+			continue
+		}
+		builder.WriteRune(r)
+		lastSpace = false
+	}
+	return builder.String()
+}
+
+func clipboardHTMLNodeText(node *html.Node) string {
+	var builder strings.Builder
+	var collect func(current *html.Node)
+	collect = func(current *html.Node) {
+		if current.Type == html.TextNode {
+			builder.WriteString(current.Data)
+			return
+		}
+		if current.Type == html.ElementNode && current.DataAtom == atom.Br {
+			builder.WriteByte('\n')
+			return
+		}
+		for child := current.FirstChild; child != nil; child = child.NextSibling {
+			collect(child)
+		}
+	}
+	collect(node)
+	return builder.String()
+}
+
+func clipboardHTMLAttr(node *html.Node, key string) string {
+	for _, attr := range node.Attr {
+		if strings.EqualFold(attr.Key, key) {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+func isClipboardMarkdownURL(value string, allowMail bool) bool {
+	lower := strings.ToLower(value)
+	return strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "http://") || (allowMail && strings.HasPrefix(lower, "mailto:"))
+}
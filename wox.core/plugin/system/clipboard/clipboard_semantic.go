@@ -0,0 +1,117 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"wox/ai"
+	"wox/plugin"
+	"wox/util"
+	"wox/util/clipboard"
+)
+
+const (
+	// clipboardSemanticSource names clipboard records in the semantic index. Document ids are record ids.
+	clipboardSemanticSource      = "clipboard"
+	clipboardSemanticResultLimit = 30
+)
+
+// semanticDocuments lists text records, OCR text of images and text favorites
+// for the semantic index. Sensitive records are never sent to an embedding model.
+func (c *ClipboardPlugin) semanticDocuments(ctx context.Context) ([]ai.SemanticDocument, error) {
+	db := c.db
+	if db == nil {
+		return nil, ai.ErrSemanticSourceNotReady
+	}
+
+	var documents []ai.SemanticDocument
+	favorites, err := c.getFavoriteItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, favorite := range favorites {
+		if text := clipboardSemanticText(c.convertFavoriteToRecord(favorite)); text != "" {
+			documents = append(documents, ai.SemanticDocument{Id: favorite.ID, Text: text})
+		}
+	}
+
+	for _, recordType := range []clipboard.Type{clipboard.ClipboardTypeText, clipboard.ClipboardTypeImage} {
+		records, err := db.GetRecentByType(ctx, string(recordType), c.maxHistoryCount, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			if isClipboardRecordSensitive(record) {
+				continue
+			}
+			if text := clipboardSemanticText(record); text != "" {
+				documents = append(documents, ai.SemanticDocument{Id: record.ID, Text: text})
+			}
+		}
+	}
+	return documents, nil
+}
+
+// clipboardSemanticText is the text that describes a record: its content for
+// text records and the recognized text for images.
+func clipboardSemanticText(record ClipboardRecord) string {
+	switch record.Type {
+	case string(clipboard.ClipboardTypeText):
+		return strings.TrimSpace(record.Content)
+	case string(clipboard.ClipboardTypeImage):
+		if record.OCRText != nil {
+			return strings.TrimSpace(*record.OCRText)
+		}
+	}
+	return ""
+}
+
+// querySemantic answers "cb ~ meaning" from the semantic index.
+func (c *ClipboardPlugin) querySemantic(ctx context.Context, query plugin.Query, search string, selectedType string) []plugin.QueryResult {
+	hits, err := ai.GetSemanticIndex().Search(ctx, clipboardSemanticSource, search, clipboardSemanticResultLimit)
+	if errors.Is(err, ai.ErrSemanticSearchUnavailable) {
+		return []plugin.QueryResult{
+			{
+				Title:    "i18n:ui_ai_semantic_search_unavailable",
+				SubTitle: "i18n:ui_ai_semantic_search_unavailable_subtitle",
+				Icon:     clipboardIcon,
+			},
+		}
+	}
+	if err != nil {
+		c.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("failed to search clipboard by meaning: %s", err.Error()))
+		return []plugin.QueryResult{}
+	}
+
+	favorites := map[string]FavoriteClipboardItem{}
+	if favoriteItems, err := c.getFavoriteItems(ctx); err == nil {
+		for _, item := range favoriteItems {
+			favorites[item.ID] = item
+		}
+	}
+
+	now := util.GetSystemTimestamp()
+	results := make([]plugin.QueryResult, 0, len(hits))
+	for index, hit := range hits {
+		var record ClipboardRecord
+		if favorite, ok := favorites[hit.Id]; ok {
+			record = c.convertFavoriteToRecord(favorite)
+		} else {
+			stored, err := c.db.GetByID(ctx, hit.Id)
+			if err != nil || stored == nil {
+				continue
+			}
+			record = *stored
+		}
+		// The index is refreshed in the background, so a record may have become
+		// sensitive or expired since it was embedded.
+		if isClipboardRecordSensitive(record) || isClipboardRecordExpired(record, now) || !clipboardRecordMatchesType(record.Type, record.Content, selectedType) {
+			continue
+		}
+		result := c.convertRecordToResult(ctx, record, query)
+		result.Score = int64(len(hits) - index)
+		results = append(results, result)
+	}
+	return results
+}
@@ -0,0 +1,298 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+	"wox/util"
+	"wox/util/clipboard"
+)
+
+const (
+	// clipboardSensitiveRuleConcealed is recorded when the source app marked the
+	// clipboard as concealed rather than a content rule matching.
+	clipboardSensitiveRuleConcealed = "concealed"
+	clipboardSensitiveRuleEntropy   = "high_entropy"
+	// clipboardSensitiveCustomRulePrefix separates user rule names from built-in
+	// rule ids, which are translated for display.
+	clipboardSensitiveCustomRulePrefix = "custom:"
+
+	clipboardSensitiveDefaultTTLMinutes = 10
+	clipboardSensitiveMask              = "••••••••"
+	// clipboardSensitiveMaskKeepRunes is how many leading and trailing runes of
+	// a long secret stay visible so users can tell entries apart.
+	clipboardSensitiveMaskKeepRunes = 4
+
+	clipboardEntropyMinLength = 20
+	clipboardEntropyMaxLength = 512
+	// clipboardEntropyMinBits is the Shannon entropy per character above which a
+	// single mixed-case token is treated as a generated secret. Words, camelCase
+	// identifiers and hex hashes stay below it.
+	clipboardEntropyMinBits = 4.0
+)
+
+// clipboardSensitiveRule is one content detector. Validate, when set, confirms
+// a regex hit that would otherwise match too broadly.
+type clipboardSensitiveRule struct {
+	ID       string
+	Pattern  *regexp.Regexp
+	Validate func(match string) bool
+}
+
+var builtinClipboardSensitiveRules = []clipboardSensitiveRule{
+	{ID: "private_key", Pattern: regexp.MustCompile(`-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----`)},
+	{ID: "aws_access_key", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`)},
+	{ID: "aws_secret_key", Pattern: regexp.MustCompile(`(?i)aws.{0,20}?secret.{0,20}?[=:"'\s][A-Za-z0-9/+]{40}\b`)},
+	{ID: "github_token", Pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{22,255})\b`)},
+	{ID: "gitlab_token", Pattern: regexp.MustCompile(`\bglpat-[A-Za-z0-9_\-]{20,}\b`)},
+	{ID: "slack_token", Pattern: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9\-]{10,}\b`)},
+	{ID: "api_key", Pattern: regexp.MustCompile(`\bsk-(?:ant-|proj-)?[A-Za-z0-9_\-]{32,}\b`)},
+	{ID: "jwt", Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]{8,}\.eyJ[A-Za-z0-9_\-]{8,}\.[A-Za-z0-9_\-]{8,}`)},
+	{ID: "credit_card", Pattern: regexp.MustCompile(`\b[3-6](?:[ \-]?[0-9]){12,18}\b`), Validate: isClipboardCreditCardNumber},
+}
+
+type clipboardSensitiveCustomRuleRow struct {
+	Name    string `json:"Name"`
+	Pattern string `json:"Pattern"`
+}
+
+// detectClipboardSensitiveText returns the rule that marks text as sensitive,
+// or "" when nothing matched. Platform hints win over content rules because a
+// password manager knows better than any pattern.
+func detectClipboardSensitiveText(text string, hints clipboard.Hints, rules []clipboardSensitiveRule, checkEntropy bool) string {
+	if hints.Concealed {
+		return clipboardSensitiveRuleConcealed
+	}
+	for _, rule := range rules {
+		for _, match := range rule.Pattern.FindAllString(text, -1) {
+			if rule.Validate == nil || rule.Validate(match) {
+				return rule.ID
+			}
+		}
+	}
+	if checkEntropy && isClipboardHighEntropyToken(text) {
+		return clipboardSensitiveRuleEntropy
+	}
+	return ""
+}
+
+// isClipboardHighEntropyToken flags a copy that is one generated-looking token,
+// such as a password or an unprefixed API key. Multi-word text, URLs and paths
+// are left alone so ordinary copies are not hidden.
+func isClipboardHighEntropyToken(text string) bool {
+	text = strings.TrimSpace(text)
+	length := utf8.RuneCountInString(text)
+	if length < clipboardEntropyMinLength || length > clipboardEntropyMaxLength {
+		return false
+	}
+	if strings.Contains(text, "://") || strings.HasPrefix(text, "/") || strings.HasPrefix(text, "~") || strings.Contains(text, `:\`) {
+		return false
+	}
+
+	var hasUpper, hasLower, hasDigit bool
+	counts := make(map[rune]int)
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			return false
+		}
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+		counts[r]++
+	}
+	if !hasUpper || !hasLower || !hasDigit {
+		return false
+	}
+
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(length)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy >= clipboardEntropyMinBits
+}
+
+// isClipboardCreditCardNumber accepts 13-19 digit numbers that pass the Luhn
+// checksum, which rules out most order numbers and phone numbers.
+func isClipboardCreditCardNumber(match string) bool {
+	digits := make([]int, 0, len(match))
+	for _, r := range match {
+		if r >= '0' && r <= '9' {
+			digits = append(digits, int(r-'0'))
+		}
+	}
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+
+	sum := 0
+	for i := range digits {
+		digit := digits[len(digits)-1-i]
+		if i%2 == 1 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+	}
+	return sum%10 == 0
+}
+
+// parseClipboardSensitiveCustomRules compiles the user's regex table. Invalid
+// patterns are reported and skipped so one typo does not disable every rule.
+func parseClipboardSensitiveCustomRules(value string) ([]clipboardSensitiveRule, []error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	var rows []clipboardSensitiveCustomRuleRow
+	if err := json.Unmarshal([]byte(value), &rows); err != nil {
+		return nil, []error{err}
+	}
+
+	var rules []clipboardSensitiveRule
+	var errs []error
+	for _, row := range rows {
+		name := strings.TrimSpace(row.Name)
+		if name == "" || strings.TrimSpace(row.Pattern) == "" {
+			continue
+		}
+		pattern, err := regexp.Compile(row.Pattern)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rule %q: %w", name, err))
+			continue
+		}
+		rules = append(rules, clipboardSensitiveRule{ID: clipboardSensitiveCustomRulePrefix + name, Pattern: pattern})
+	}
+	return rules, errs
+}
+
+// maskClipboardSensitiveText hides a secret for display. Long single-line
+// values keep a few characters at each end; anything else is fully masked.
+func maskClipboardSensitiveText(text string) string {
+	text = strings.TrimSpace(text)
+	runes := []rune(text)
+	if len(runes) < clipboardSensitiveMaskKeepRunes*4 || strings.ContainsAny(text, "\r\n") {
+		return clipboardSensitiveMask
+	}
+	return string(runes[:clipboardSensitiveMaskKeepRunes]) + clipboardSensitiveMask + string(runes[len(runes)-clipboardSensitiveMaskKeepRunes:])
+}
+
+// clipboardSensitiveRuleLabel returns the display label for a stored rule.
+func clipboardSensitiveRuleLabel(rule string) string {
+	if name, ok := strings.CutPrefix(rule, clipboardSensitiveCustomRulePrefix); ok {
+		return name
+	}
+	return "i18n:plugin_clipboard_sensitive_rule_" + rule
+}
+
+// clipboardSensitiveText is the clear text of a sensitive record, kept only
+// until the record expires so it stays pasteable without being stored.
+type clipboardSensitiveText struct {
+	Text      string
+	ExpiresAt int64
+}
+
+// maskSensitiveRecord stores the masked text in the record and keeps the
+// clear text in memory, so the secret never reaches the database, its full
+// text index or cloud sync.
+func (c *ClipboardPlugin) maskSensitiveRecord(record *ClipboardRecord, text string, rule string, expiresAt int64) {
+	record.Content = maskClipboardSensitiveText(text)
+	record.SensitiveRule = &rule
+	record.ExpiresAt = &expiresAt
+	if c.sensitiveTexts != nil {
+		c.sensitiveTexts.Store(record.ID, clipboardSensitiveText{Text: text, ExpiresAt: expiresAt})
+	}
+}
+
+// getRecordText returns the text to write back to the clipboard. Sensitive
+// records only have it while their clear text is still held in memory; after
+// a restart or once expired only the mask is left.
+func (c *ClipboardPlugin) getRecordText(record ClipboardRecord) (string, bool) {
+	if !isClipboardRecordSensitive(record) {
+		return record.Content, true
+	}
+	if c.sensitiveTexts == nil {
+		return "", false
+	}
+	sensitiveText, ok := c.sensitiveTexts.Load(record.ID)
+	if !ok || sensitiveText.ExpiresAt <= util.GetSystemTimestamp() {
+		return "", false
+	}
+	return sensitiveText.Text, true
+}
+
+func isClipboardRecordSensitive(record ClipboardRecord) bool {
+	return record.SensitiveRule != nil && *record.SensitiveRule != ""
+}
+
+func isClipboardRecordExpired(record ClipboardRecord, now int64) bool {
+	return record.ExpiresAt != nil && *record.ExpiresAt <= now
+}
+
+func (c *ClipboardPlugin) isSensitiveDetectionEnabled(ctx context.Context) bool {
+	return c.api.GetSetting(ctx, sensitiveDetectionSettingKey) != "false"
+}
+
+func (c *ClipboardPlugin) sensitiveAction(ctx context.Context) string {
+	if c.api.GetSetting(ctx, sensitiveActionSettingKey) == sensitiveActionValueSkip {
+		return sensitiveActionValueSkip
+	}
+	return sensitiveActionValueMask
+}
+
+func (c *ClipboardPlugin) getSensitiveTTLMinutes(ctx context.Context) int {
+	ttlStr := c.api.GetSetting(ctx, sensitiveTTLSettingKey)
+	if ttlMinutes, err := strconv.Atoi(ttlStr); err == nil && ttlMinutes > 0 {
+		return ttlMinutes
+	}
+	return clipboardSensitiveDefaultTTLMinutes
+}
+
+// detectSensitiveText runs the detector pipeline with the current settings.
+func (c *ClipboardPlugin) detectSensitiveText(ctx context.Context, textData *clipboard.TextData) string {
+	customRules, errs := parseClipboardSensitiveCustomRules(c.api.GetSetting(ctx, sensitiveCustomRulesSettingKey))
+	for _, err := range errs {
+		util.GetLogger().Warn(ctx, fmt.Sprintf("clipboard: ignoring invalid sensitive content rule: %v", err))
+	}
+
+	rules := make([]clipboardSensitiveRule, 0, len(builtinClipboardSensitiveRules)+len(customRules))
+	rules = append(rules, builtinClipboardSensitiveRules...)
+	rules = append(rules, customRules...)
+	return detectClipboardSensitiveText(textData.Text, textData.Hints, rules, c.api.GetSetting(ctx, sensitiveEntropySettingKey) != "false")
+}
+
+// deleteExpiredSensitiveRecords runs on its own short interval because
+// sensitive TTLs are minutes while regular history cleanup runs twice an hour.
+func (c *ClipboardPlugin) deleteExpiredSensitiveRecords(ctx context.Context) {
+	if c.sensitiveTexts != nil {
+		now := util.GetSystemTimestamp()
+		for _, id := range c.sensitiveTexts.Keys() {
+			if sensitiveText, ok := c.sensitiveTexts.Load(id); ok && sensitiveText.ExpiresAt <= now {
+				c.sensitiveTexts.Delete(id)
+			}
+		}
+	}
+	if c.db == nil {
+		return
+	}
+	deletedCount, err := c.db.DeleteExpiredSensitive(ctx)
+	if err != nil {
+		util.GetLogger().Warn(ctx, fmt.Sprintf("clipboard: failed to delete expired sensitive records: %v", err))
+		return
+	}
+	if deletedCount > 0 {
+		util.GetLogger().Info(ctx, fmt.Sprintf("clipboard: deleted %d expired sensitive records", deletedCount))
+	}
+}
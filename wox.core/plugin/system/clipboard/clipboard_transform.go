@@ -0,0 +1,547 @@
+package system
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+	"wox/common"
+	"wox/plugin"
+	"wox/plugin/system"
+	"wox/util"
+	"wox/util/clipboard"
+)
+
+const (
+	clipboardTransformCommand = "transform"
+	// clipboardTransformJoinID reads the records selected for joining instead
+	// of the transform target, so it is only valid as the first pipeline step.
+	clipboardTransformJoinID = "join"
+
+	clipboardTransformGroupScore = 20
+	clipboardPipelineGroupScore  = 30
+	// clipboardTransformTargetWindow bounds how many recent text records are
+	// scanned for a non-sensitive default target.
+	clipboardTransformTargetWindow = 20
+)
+
+// clipboardTransform is one text conversion offered under "cb transform".
+type clipboardTransform struct {
+	ID    string
+	Apply func(input string) (string, error)
+}
+
+// clipboardTransformPipeline is a named chain of transforms saved in settings.
+type clipboardTransformPipeline struct {
+	Name  string
+	Steps []clipboardTransform
+}
+
+type clipboardTransformPipelineRow struct {
+	Name  string `json:"Name"`
+	Steps string `json:"Steps"`
+}
+
+// clipboardTrackingParams are query keys removed by strip_tracking in addition
+// to every utm_* key.
+var clipboardTrackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "dclid": true, "gbraid": true, "wbraid": true, "msclkid": true,
+	"yclid": true, "igshid": true, "mc_cid": true, "mc_eid": true, "_hsenc": true, "_hsmi": true,
+	"mkt_tok": true, "vero_id": true, "oly_enc_id": true, "oly_anon_id": true, "ref_src": true,
+}
+
+var (
+	clipboardTransformURLPattern = regexp.MustCompile(`https?://[^\s<>"'` + "`" + `]+`)
+	errClipboardTransformNoText  = errors.New("result is empty")
+)
+
+var builtinClipboardTransforms = []clipboardTransform{
+	{ID: "trim", Apply: func(input string) (string, error) { return strings.TrimSpace(input), nil }},
+	{ID: "lowercase", Apply: func(input string) (string, error) { return strings.ToLower(input), nil }},
+	{ID: "uppercase", Apply: func(input string) (string, error) { return strings.ToUpper(input), nil }},
+	{ID: "title_case", Apply: func(input string) (string, error) { return toClipboardTitleCase(input), nil }},
+	{ID: "camel_case", Apply: func(input string) (string, error) { return mapClipboardLines(input, toClipboardCamelCase), nil }},
+	{ID: "snake_case", Apply: func(input string) (string, error) {
+		return mapClipboardLines(input, func(line string) string { return joinClipboardWords(line, "_") }), nil
+	}},
+	{ID: "kebab_case", Apply: func(input string) (string, error) {
+		return mapClipboardLines(input, func(line string) string { return joinClipboardWords(line, "-") }), nil
+	}},
+	{ID: "json_pretty", Apply: func(input string) (string, error) {
+		var buffer bytes.Buffer
+		if err := json.Indent(&buffer, []byte(strings.TrimSpace(input)), "", "  "); err != nil {
+			return "", err
+		}
+		return buffer.String(), nil
+	}},
+	{ID: "json_minify", Apply: func(input string) (string, error) {
+		var buffer bytes.Buffer
+		if err := json.Compact(&buffer, []byte(strings.TrimSpace(input))); err != nil {
+			return "", err
+		}
+		return buffer.String(), nil
+	}},
+	{ID: "url_encode", Apply: func(input string) (string, error) { return url.QueryEscape(input), nil }},
+	{ID: "url_decode", Apply: func(input string) (string, error) { return url.QueryUnescape(strings.TrimSpace(input)) }},
+	{ID: "base64_encode", Apply: func(input string) (string, error) { return base64.StdEncoding.EncodeToString([]byte(input)), nil }},
+	{ID: "base64_decode", Apply: decodeClipboardBase64},
+	{ID: "strip_tracking", Apply: func(input string) (string, error) {
+		return clipboardTransformURLPattern.ReplaceAllStringFunc(input, stripClipboardTrackingParams), nil
+	}},
+	{ID: "sort_lines", Apply: func(input string) (string, error) {
+		lines := strings.Split(strings.TrimRight(input, "\r\n"), "\n")
+		slices.SortStableFunc(lines, func(a, b string) int { return strings.Compare(strings.TrimRight(a, "\r"), strings.TrimRight(b, "\r")) })
+		return strings.Join(lines, "\n"), nil
+	}},
+	{ID: "dedupe_lines", Apply: func(input string) (string, error) {
+		seen := map[string]bool{}
+		var lines []string
+		for _, line := range strings.Split(strings.TrimRight(input, "\r\n"), "\n") {
+			key := strings.TrimRight(line, "\r")
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			lines = append(lines, line)
+		}
+		return strings.Join(lines, "\n"), nil
+	}},
+	{ID: clipboardTransformJoinID, Apply: func(input string) (string, error) { return input, nil }},
+}
+
+func findClipboardTransform(id string) (clipboardTransform, bool) {
+	for _, transform := range builtinClipboardTransforms {
+		if transform.ID == id {
+			return transform, true
+		}
+	}
+	return clipboardTransform{}, false
+}
+
+// parseClipboardTransformPipelines reads the pipeline table. Steps are
+// transform ids separated by commas or "|". A pipeline with an unknown step is
+// reported and skipped instead of silently running a shorter chain.
+func parseClipboardTransformPipelines(value string) ([]clipboardTransformPipeline, []error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	var rows []clipboardTransformPipelineRow
+	if err := json.Unmarshal([]byte(value), &rows); err != nil {
+		return nil, []error{err}
+	}
+
+	var pipelines []clipboardTransformPipeline
+	var errs []error
+	for _, row := range rows {
+		name := strings.TrimSpace(row.Name)
+		if name == "" {
+			continue
+		}
+		pipeline := clipboardTransformPipeline{Name: name}
+		var stepErr error
+		for index, stepID := range strings.FieldsFunc(row.Steps, func(r rune) bool { return r == ',' || r == '|' }) {
+			stepID = strings.ToLower(strings.TrimSpace(stepID))
+			if stepID == "" {
+				continue
+			}
+			transform, ok := findClipboardTransform(stepID)
+			if !ok {
+				stepErr = fmt.Errorf("pipeline %q: unknown transform %q", name, stepID)
+				break
+			}
+			if transform.ID == clipboardTransformJoinID && index > 0 {
+				stepErr = fmt.Errorf("pipeline %q: %s must be the first step", name, clipboardTransformJoinID)
+				break
+			}
+			pipeline.Steps = append(pipeline.Steps, transform)
+		}
+		if stepErr == nil && len(pipeline.Steps) == 0 {
+			stepErr = fmt.Errorf("pipeline %q: no steps", name)
+		}
+		if stepErr != nil {
+			errs = append(errs, stepErr)
+			continue
+		}
+		pipelines = append(pipelines, pipeline)
+	}
+	return pipelines, errs
+}
+
+// applyClipboardTransforms runs steps in order and stops at the first error so
+// the preview can show which step rejected the input.
+func applyClipboardTransforms(steps []clipboardTransform, input string) (string, error) {
+	output := input
+	for _, step := range steps {
+		var err error
+		output, err = step.Apply(output)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", step.ID, err)
+		}
+	}
+	if strings.TrimSpace(output) == "" {
+		return "", errClipboardTransformNoText
+	}
+	return output, nil
+}
+
+func decodeClipboardBase64(input string) (string, error) {
+	compact := strings.Join(strings.Fields(input), "")
+	var lastErr error
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		decoded, err := encoding.DecodeString(compact)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if !utf8.Valid(decoded) {
+			return "", errors.New("decoded data is not text")
+		}
+		return string(decoded), nil
+	}
+	return "", lastErr
+}
+
+// stripClipboardTrackingParams removes tracking keys from one URL while keeping
+// the order and encoding of the remaining parameters. Sentence punctuation
+// caught by the URL pattern is put back untouched.
+func stripClipboardTrackingParams(rawURL string) string {
+	trimmed := strings.TrimRight(rawURL, ".,;:!?)]}")
+	suffix := rawURL[len(trimmed):]
+	parsed, err := url.Parse(trimmed)
+	if err != nil || parsed.RawQuery == "" {
+		return rawURL
+	}
+
+	var kept []string
+	for _, pair := range strings.Split(parsed.RawQuery, "&") {
+		key, _, _ := strings.Cut(pair, "=")
+		if decodedKey, err := url.QueryUnescape(key); err == nil {
+			key = decodedKey
+		}
+		key = strings.ToLower(key)
+		if pair == "" || strings.HasPrefix(key, "utm_") || clipboardTrackingParams[key] {
+			continue
+		}
+		kept = append(kept, pair)
+	}
+	parsed.RawQuery = strings.Join(kept, "&")
+	parsed.ForceQuery = false
+	return parsed.String() + suffix
+}
+
+func mapClipboardLines(input string, mapper func(line string) string) string {
+	lines := strings.Split(input, "\n")
+	for i, line := range lines {
+		lines[i] = mapper(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// splitClipboardWords splits identifiers and prose into lower-case words,
+// breaking on separators and on camelCase boundaries.
+func splitClipboardWords(text string) []string {
+	var words []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+	runes := []rune(text)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && len(current) > 0 {
+			previous := current[len(current)-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextIsLower) {
+				flush()
+			}
+		}
+		current = append(current, r)
+	}
+	flush()
+	return words
+}
+
+func joinClipboardWords(line string, separator string) string {
+	return strings.Join(splitClipboardWords(line), separator)
+}
+
+func toClipboardCamelCase(line string) string {
+	words := splitClipboardWords(line)
+	for i := 1; i < len(words); i++ {
+		words[i] = upperClipboardFirstRune(words[i])
+	}
+	return strings.Join(words, "")
+}
+
+// toClipboardTitleCase capitalizes the first letter after whitespace and keeps
+// the original spacing, unlike the word splitters used for identifiers.
+func toClipboardTitleCase(input string) string {
+	var builder strings.Builder
+	atWordStart := true
+	for _, r := range input {
+		if unicode.IsSpace(r) {
+			atWordStart = true
+			builder.WriteRune(r)
+			continue
+		}
+		if atWordStart {
+			builder.WriteRune(unicode.ToTitle(r))
+		} else {
+			builder.WriteRune(unicode.ToLower(r))
+		}
+		atWordStart = false
+	}
+	return builder.String()
+}
+
+func upperClipboardFirstRune(word string) string {
+	r, size := utf8.DecodeRuneInString(word)
+	if r == utf8.RuneError {
+		return word
+	}
+	return string(unicode.ToTitle(r)) + word[size:]
+}
+
+// setTransformTarget remembers the record a "Transform" action was invoked on.
+// The target and join selection live until the user leaves the clipboard query.
+func (c *ClipboardPlugin) setTransformTarget(record ClipboardRecord) {
+	c.transformMutex.Lock()
+	defer c.transformMutex.Unlock()
+	c.transformTarget = &record
+}
+
+func (c *ClipboardPlugin) resetTransformState() {
+	c.transformMutex.Lock()
+	defer c.transformMutex.Unlock()
+	c.transformTarget = nil
+	c.transformSelection = nil
+}
+
+// toggleTransformSelection adds or removes a record from the join selection and
+// reports whether it is selected afterwards.
+func (c *ClipboardPlugin) toggleTransformSelection(record ClipboardRecord) bool {
+	c.transformMutex.Lock()
+	defer c.transformMutex.Unlock()
+	for i, selected := range c.transformSelection {
+		if selected.ID == record.ID {
+			c.transformSelection = slices.Delete(c.transformSelection, i, i+1)
+			return false
+		}
+	}
+	c.transformSelection = append(c.transformSelection, record)
+	return true
+}
+
+// transformSelectionIndex returns the 1-based join position of a record, or 0.
+func (c *ClipboardPlugin) transformSelectionIndex(id string) int {
+	c.transformMutex.Lock()
+	defer c.transformMutex.Unlock()
+	for i, selected := range c.transformSelection {
+		if selected.ID == id {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// resolveTransformInputs returns the text transforms run on and the joined
+// selection. Without an explicit target the latest non-sensitive text record is
+// used, which is what a pipeline bound to a query hotkey expects.
+func (c *ClipboardPlugin) resolveTransformInputs(ctx context.Context) (*ClipboardRecord, string) {
+	c.transformMutex.Lock()
+	target := c.transformTarget
+	var selectedTexts []string
+	for _, selected := range c.transformSelection {
+		selectedTexts = append(selectedTexts, selected.Content)
+	}
+	c.transformMutex.Unlock()
+
+	joined := ""
+	if len(selectedTexts) > 1 {
+		joined = strings.Join(selectedTexts, "\n")
+	}
+	if target != nil {
+		return target, joined
+	}
+
+	recent, err := c.db.GetRecentByType(ctx, string(clipboard.ClipboardTypeText), clipboardTransformTargetWindow, 0)
+	if err != nil {
+		c.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("failed to get transform target: %s", err.Error()))
+		return nil, joined
+	}
+	now := util.GetSystemTimestamp()
+	for _, record := range recent {
+		if isClipboardRecordSensitive(record) || isClipboardRecordExpired(record, now) {
+			continue
+		}
+		return &record, joined
+	}
+	return nil, joined
+}
+
+func (c *ClipboardPlugin) getTransformPipelines(ctx context.Context) []clipboardTransformPipeline {
+	pipelines, errs := parseClipboardTransformPipelines(c.api.GetSetting(ctx, transformPipelinesSettingKey))
+	for _, err := range errs {
+		util.GetLogger().Warn(ctx, fmt.Sprintf("clipboard: ignoring invalid transform pipeline: %v", err))
+	}
+	return pipelines
+}
+
+// queryTransforms lists every transform and pipeline with its output as the
+// preview. An exact pipeline name returns only that pipeline so a silent query
+// hotkey such as "cb transform tidy json" pastes without showing the list.
+func (c *ClipboardPlugin) queryTransforms(ctx context.Context, query plugin.Query) []plugin.QueryResult {
+	target, joined := c.resolveTransformInputs(ctx)
+	search := strings.TrimSpace(query.Search)
+
+	var results []plugin.QueryResult
+	for _, pipeline := range c.getTransformPipelines(ctx) {
+		if strings.EqualFold(pipeline.Name, search) {
+			return []plugin.QueryResult{c.buildTransformResult(ctx, query, pipeline.Name, pipeline.Steps, target, joined)}
+		}
+		if clipboardSearchCandidateMatches(ctx, pipeline.Name, search) {
+			results = append(results, c.buildTransformResult(ctx, query, pipeline.Name, pipeline.Steps, target, joined))
+		}
+	}
+	for _, transform := range builtinClipboardTransforms {
+		name := c.api.GetTranslation(ctx, "plugin_clipboard_transform_"+transform.ID)
+		if !clipboardSearchCandidateMatches(ctx, name, search) && !clipboardSearchCandidateMatches(ctx, transform.ID, search) {
+			continue
+		}
+		results = append(results, c.buildTransformResult(ctx, query, "", []clipboardTransform{transform}, target, joined))
+	}
+	return results
+}
+
+// buildTransformResult renders one transform or pipeline. pipelineName is empty
+// for built-in transforms.
+func (c *ClipboardPlugin) buildTransformResult(ctx context.Context, query plugin.Query, pipelineName string, steps []clipboardTransform, target *ClipboardRecord, joined string) plugin.QueryResult {
+	var stepNames []string
+	for _, step := range steps {
+		stepNames = append(stepNames, c.api.GetTranslation(ctx, "plugin_clipboard_transform_"+step.ID))
+	}
+
+	result := plugin.QueryResult{
+		Title:      pipelineName,
+		SubTitle:   strings.Join(stepNames, " → "),
+		Icon:       c.getDefaultTextIcon(),
+		Group:      "i18n:plugin_clipboard_transform_group_pipelines",
+		GroupScore: clipboardPipelineGroupScore,
+	}
+	if pipelineName == "" {
+		result.Title = stepNames[0]
+		result.SubTitle = ""
+		result.Group = "i18n:plugin_clipboard_transform_group_transforms"
+		result.GroupScore = clipboardTransformGroupScore
+	}
+
+	input := ""
+	if steps[0].ID == clipboardTransformJoinID {
+		input = joined
+	} else if target != nil {
+		input = target.Content
+	}
+	if input == "" {
+		emptyKey := "plugin_clipboard_transform_no_target"
+		if steps[0].ID == clipboardTransformJoinID {
+			emptyKey = "plugin_clipboard_transform_no_selection"
+		}
+		result.Preview = plugin.WoxPreview{PreviewType: plugin.WoxPreviewTypeText, PreviewData: c.api.GetTranslation(ctx, emptyKey)}
+		return result
+	}
+
+	output, err := applyClipboardTransforms(steps, input)
+	if err != nil {
+		result.Preview = plugin.WoxPreview{
+			PreviewType: plugin.WoxPreviewTypeText,
+			PreviewData: fmt.Sprintf(c.api.GetTranslation(ctx, "plugin_clipboard_transform_failed"), err.Error()),
+		}
+		result.Tails = []plugin.QueryResultTail{plugin.NewQueryResultTailTextWithCategory("i18n:plugin_clipboard_transform_error", plugin.QueryResultTailTextCategoryWarning)}
+		return result
+	}
+
+	result.Preview = plugin.WoxPreview{
+		PreviewType: plugin.WoxPreviewTypeText,
+		PreviewData: output,
+		PreviewTags: []plugin.WoxPreviewTag{
+			{Label: fmt.Sprintf(c.api.GetTranslation(ctx, "plugin_clipboard_copy_characters_value"), utf8.RuneCountInString(output)), Tooltip: "i18n:plugin_clipboard_copy_characters"},
+		},
+	}
+
+	primaryActionCode := c.api.GetSetting(ctx, primaryActionSettingKey)
+	result.Actions = []plugin.QueryResultAction{
+		{
+			Name:      "i18n:plugin_clipboard_copy",
+			Icon:      common.CopyIcon,
+			IsDefault: primaryActionValueCopy == primaryActionCode,
+			Action: func(ctx context.Context, actionContext plugin.ActionContext) {
+				if err := clipboard.WriteText(output); err != nil {
+					c.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("failed to copy transformed text to clipboard: %s", err.Error()))
+				}
+			},
+		},
+	}
+	pasteAction, pasteErr := system.GetPasteToActiveWindowAction(ctx, c.api, query.Env.ActiveWindowTitle, query.Env.ActiveWindowPid, query.Env.ActiveWindowIcon, func(actionCtx context.Context) error {
+		if err := clipboard.WriteText(output); err != nil {
+			return fmt.Errorf("failed to copy transformed text before paste action: %w", err)
+		}
+		return nil
+	})
+	if pasteErr == nil {
+		pasteAction.IsDefault = primaryActionValueCopy != primaryActionCode
+		result.Actions = append(result.Actions, pasteAction)
+	}
+	return result
+}
+
+// buildTransformRecordActions returns the per-record entry points into the
+// transform view: transform this record, and select it for joining.
+func (c *ClipboardPlugin) buildTransformRecordActions(ctx context.Context, record ClipboardRecord, query plugin.Query) []plugin.QueryResultAction {
+	triggerKeyword := query.TriggerKeyword
+	if triggerKeyword == "" {
+		triggerKeyword = "cb"
+	}
+	selectName := "i18n:plugin_clipboard_transform_select_for_join"
+	if c.transformSelectionIndex(record.ID) > 0 {
+		selectName = "i18n:plugin_clipboard_transform_deselect_for_join"
+	}
+
+	return []plugin.QueryResultAction{
+		{
+			Name:                   "i18n:plugin_clipboard_transform_action",
+			Icon:                   common.EditIcon,
+			PreventHideAfterAction: true,
+			Action: func(ctx context.Context, actionContext plugin.ActionContext) {
+				c.setTransformTarget(record)
+				c.api.ChangeQuery(ctx, common.PlainQuery{
+					QueryType: plugin.QueryTypeInput,
+					QueryText: fmt.Sprintf("%s %s ", triggerKeyword, clipboardTransformCommand),
+				})
+			},
+		},
+		{
+			Name:                   selectName,
+			Icon:                   common.CorrectIcon,
+			PreventHideAfterAction: true,
+			Action: func(ctx context.Context, actionContext plugin.ActionContext) {
+				selected := c.toggleTransformSelection(record)
+				c.api.Log(ctx, plugin.LogLevelInfo, fmt.Sprintf("clipboard record join selection changed: id=%s selected=%t", record.ID, selected))
+				c.api.RefreshQuery(ctx, plugin.RefreshQueryParam{PreserveSelectedIndex: true})
+			},
+		},
+	}
+}
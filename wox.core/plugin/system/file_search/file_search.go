@@ -12,6 +12,7 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"wox/ai"
 	"wox/common"
 	"wox/plugin"
 	"wox/plugin/system/file_search/indexpolicy"
@@ -77,6 +78,8 @@ const (
 	fileSearchSortRefinementName      = "name"
 	fileSearchSortRefinementModified  = "modified"
 	fileSearchSortRefinementSize      = "size"
+
+	fileSearchFilterRefinementKey = "file_filters"
 )
 
 type fileRootSetting struct {
@@ -260,6 +263,7 @@ func (c *FileSearchPlugin) Init(ctx context.Context, initParams plugin.InitParam
 	c.engine = engine
 	c.api.Log(ctx, plugin.LogLevelInfo, "File search engine initialized")
 	c.api.OnHandlePluginCommand(ctx, c.handlePluginCommand)
+	ai.GetSemanticIndex().RegisterSource(ai.SemanticSource{Name: fileSearchSemanticSource, Documents: c.semanticDocuments})
 	c.unsubscribeStatusChange = c.engine.OnStatusChanged(func(status filesearch.StatusSnapshot) {
 		c.handleStatusChanged(status)
 	})
@@ -327,6 +331,7 @@ func (c *FileSearchPlugin) Init(ctx context.Context, initParams plugin.InitParam
 					c.api.Log(callbackCtx, plugin.LogLevelWarning, fmt.Sprintf("failed to remove content search database: %s", err.Error()))
 				}
 				c.api.ClearToolbarMsg(callbackCtx, contentSearchToolbarMsgID)
+				ai.GetSemanticIndex().Refresh()
 			}
 			return
 		}
@@ -531,6 +536,7 @@ func (c *FileSearchPlugin) finishContentCrawl(ctx context.Context, generation in
 	c.contentSearchStateMu.Unlock()
 	if stopped != nil {
 		close(stopped)
+		ai.GetSemanticIndex().Refresh()
 	}
 }
 
@@ -714,6 +720,7 @@ func (c *FileSearchPlugin) Query(ctx context.Context, query plugin.Query) plugin
 	}
 
 	searchStartedAt := util.GetSystemTimestamp()
+	activeFilters := filesearch.ActiveSearchFilters(query.Search)
 	selectedType := selectedFileSearchType(query)
 	selectedSort := selectedFileSearchSort(query)
 	searchLimit := fileSearchResultLimit
@@ -723,7 +730,19 @@ func (c *FileSearchPlugin) Query(ctx context.Context, query plugin.Query) plugin
 		// for the default path preserves the fast historical relevance search.
 		searchLimit = fileSearchRefinedCandidateLimit
 	}
-	results, err := c.search(ctx, query.Search, searchLimit)
+	// Feature addition: "~ meaning" searches file contents by meaning through
+	// the semantic index. "~/path" stays a home-relative path query.
+	semanticSearch, isSemanticSearch := ai.ParseSemanticQuery(query.Search)
+	var results []filesearch.SearchResult
+	var err error
+	if isSemanticSearch {
+		results, err = c.searchSemantic(ctx, semanticSearch)
+		if errors.Is(err, ai.ErrSemanticSearchUnavailable) {
+			return c.semanticSearchUnavailableResponse()
+		}
+	} else {
+		results, err = c.search(ctx, query.Search, searchLimit)
+	}
 	diagnostics.searchElapsedMs = util.GetSystemTimestamp() - searchStartedAt
 	if err != nil {
 		c.logQueryDiagnostics(ctx, query.Search, diagnostics, 0, util.GetSystemTimestamp()-queryStartedAt)
@@ -732,8 +751,12 @@ func (c *FileSearchPlugin) Query(ctx context.Context, query plugin.Query) plugin
 		return plugin.QueryResponse{}
 	}
 	resultLimit := fileSearchResultLimit
-	if selectedType != fileSearchTypeRefinementFolder {
-		results = c.appendContentSearchResults(ctx, query.Search, results, fileSearchResultLimit+fileSearchContentResultLimit)
+	var contentSnippets map[string][]filesearch.ContentSnippet
+	// Content hits come from a separate FTS index that knows nothing about
+	// size, date or location, so mixing them into a filtered query would show
+	// rows the user explicitly excluded.
+	if !isSemanticSearch && selectedType != fileSearchTypeRefinementFolder && len(activeFilters) == 0 {
+		results, contentSnippets = c.appendContentSearchResults(ctx, query.Search, results, fileSearchResultLimit+fileSearchContentResultLimit)
 		if selectedSort == fileSearchSortRefinementRelevance {
 			resultLimit += fileSearchContentResultLimit
 		}
@@ -753,7 +776,8 @@ func (c *FileSearchPlugin) Query(ctx context.Context, query plugin.Query) plugin
 	queryResults := make([]plugin.QueryResult, 0, len(results))
 	for index, item := range results {
 		icon := resolveFileSearchResultIcon(ctx, item, fileTypeIcons, &diagnostics)
-		actions := c.buildFileSearchResultActions(ctx, item)
+		snippets := contentSnippets[item.Path]
+		actions := c.buildFileSearchResultActions(ctx, item, snippets)
 
 		queryResult := plugin.QueryResult{
 			Title:    item.Name,
@@ -771,6 +795,12 @@ func (c *FileSearchPlugin) Query(ctx context.Context, query plugin.Query) plugin
 				PreviewType: plugin.WoxPreviewTypeFile,
 				PreviewData: item.Path,
 			}
+			// Feature addition: content hits explain themselves with the matched
+			// lines instead of the file's first screen, which often does not
+			// contain the query at all.
+			if len(snippets) > 0 {
+				queryResult.Preview = c.buildFileSearchContentPreview(ctx, snippets)
+			}
 		}
 		if selectedSort != fileSearchSortRefinementRelevance {
 			queryResult.Score = fileSearchRefinementSortScore(index, len(results))
@@ -782,23 +812,32 @@ func (c *FileSearchPlugin) Query(ctx context.Context, query plugin.Query) plugin
 	c.logQueryDiagnostics(ctx, query.Search, diagnostics, len(queryResults), util.GetSystemTimestamp()-queryStartedAt)
 
 	response := plugin.NewQueryResponse(queryResults)
-	response.Refinements = c.buildFileSearchRefinements()
+	response.Refinements = c.buildFileSearchRefinements(activeFilters)
 	return response
 }
 
 // appendContentSearchResults adds existing content hits to the common result envelope before refinements run.
-func (c *FileSearchPlugin) appendContentSearchResults(ctx context.Context, queryText string, nameResults []filesearch.SearchResult, limit int) []filesearch.SearchResult {
+// It also returns the snippets of every content hit by path, including files
+// that were already found by name, so their preview can show the matched text.
+func (c *FileSearchPlugin) appendContentSearchResults(ctx context.Context, queryText string, nameResults []filesearch.SearchResult, limit int) ([]filesearch.SearchResult, map[string][]filesearch.ContentSnippet) {
 	if !c.isContentSearchEnabled(ctx) {
-		return nameResults
+		return nameResults, nil
 	}
 
 	contentHits, err := c.engine.SearchContent(ctx, queryText, limit)
 	if err != nil {
 		c.api.Log(ctx, plugin.LogLevelWarning, fmt.Sprintf("content search failed for query %q: %s", queryText, err.Error()))
-		return nameResults
+		return nameResults, nil
 	}
 	if len(contentHits) == 0 {
-		return nameResults
+		return nameResults, nil
+	}
+
+	snippets := make(map[string][]filesearch.ContentSnippet, len(contentHits))
+	for _, hit := range contentHits {
+		if len(hit.Snippets) > 0 {
+			snippets[hit.Path] = hit.Snippets
+		}
 	}
 
 	existingPaths := make(map[string]bool, len(nameResults))
@@ -827,11 +866,11 @@ func (c *FileSearchPlugin) appendContentSearchResults(ctx context.Context, query
 			break
 		}
 	}
-	return nameResults
+	return nameResults, snippets
 }
 
 // buildFileSearchResultActions keeps folder navigation integrated with the path-browse plugin.
-func (c *FileSearchPlugin) buildFileSearchResultActions(ctx context.Context, item filesearch.SearchResult) []plugin.QueryResultAction {
+func (c *FileSearchPlugin) buildFileSearchResultActions(ctx context.Context, item filesearch.SearchResult, snippets []filesearch.ContentSnippet) []plugin.QueryResultAction {
 	actions := []plugin.QueryResultAction{
 		{
 			Name: "i18n:plugin_file_open",
@@ -870,6 +909,9 @@ func (c *FileSearchPlugin) buildFileSearchResultActions(ctx context.Context, ite
 			},
 			Hotkey: util.PrimaryHotkey("enter"),
 		})
+		if openAtLineAction, ok := c.buildOpenAtLineAction(ctx, item.Path, snippets); ok {
+			actions = append(actions, openAtLineAction)
+		}
 	}
 	actions = append(actions, c.buildExecuteCommandAtLocationAction(item))
 
@@ -1051,11 +1093,40 @@ func (c *FileSearchPlugin) indexFilesFromScratch(ctx context.Context) {
 	})
 }
 
-func (c *FileSearchPlugin) buildFileSearchRefinements() []plugin.QueryRefinement {
-	return []plugin.QueryRefinement{
+func (c *FileSearchPlugin) buildFileSearchRefinements(activeFilters []string) []plugin.QueryRefinement {
+	refinements := []plugin.QueryRefinement{
 		c.buildFileSearchTypeRefinement(),
 		c.buildFileSearchSortRefinement(),
 	}
+	if len(activeFilters) > 0 {
+		refinements = append(refinements, c.buildFileSearchFilterRefinement(activeFilters))
+	}
+	return refinements
+}
+
+func (c *FileSearchPlugin) buildFileSearchFilterRefinement(activeFilters []string) plugin.QueryRefinement {
+	// Feature addition: structured operators such as size:>10mb are parsed out
+	// of the query text by filesearch, so the refinement bar mirrors them to show
+	// how the query was understood. The query text stays the single source of
+	// truth: the UI keeps selections across query edits, so reading this
+	// selection back would silently drop operators the user typed later.
+	options := make([]plugin.QueryRefinementOption, 0, len(activeFilters))
+	values := make([]string, 0, len(activeFilters))
+	for index, token := range activeFilters {
+		// Tokens like ext:pdf,docx contain commas, which the refinement value
+		// encoding uses as its separator, so options are keyed by position.
+		value := fmt.Sprintf("filter_%d", index)
+		options = append(options, plugin.QueryRefinementOption{Value: value, Title: token})
+		values = append(values, value)
+	}
+	return plugin.QueryRefinement{
+		Id:           fileSearchFilterRefinementKey,
+		Title:        "i18n:plugin_file_refinement_filters",
+		Type:         plugin.QueryRefinementTypeMultiSelect,
+		DefaultValue: values,
+		Persist:      false,
+		Options:      options,
+	}
 }
 
 func (c *FileSearchPlugin) buildFileSearchTypeRefinement() plugin.QueryRefinement {
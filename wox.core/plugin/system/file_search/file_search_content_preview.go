@@ -0,0 +1,170 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"unicode/utf8"
+	"wox/common"
+	"wox/plugin"
+	"wox/util/filesearch"
+	"wox/util/shell"
+)
+
+// fileSearchLineEditor is a code editor CLI that can open a file at a line.
+type fileSearchLineEditor struct {
+	name    string
+	command string
+	args    func(path string, line int) []string
+}
+
+// fileSearchLineEditors are probed in order. VS Code style editors need
+// --goto to parse the suffix; the others accept path:line directly.
+var fileSearchLineEditors = []fileSearchLineEditor{
+	{name: "VS Code", command: "code", args: gotoFileSearchLineArgs},
+	{name: "Cursor", command: "cursor", args: gotoFileSearchLineArgs},
+	{name: "Windsurf", command: "windsurf", args: gotoFileSearchLineArgs},
+	{name: "Zed", command: "zed", args: plainFileSearchLineArgs},
+	{name: "Sublime Text", command: "subl", args: plainFileSearchLineArgs},
+}
+
+func gotoFileSearchLineArgs(path string, line int) []string {
+	return []string{"--goto", fmt.Sprintf("%s:%d", path, line)}
+}
+
+func plainFileSearchLineArgs(path string, line int) []string {
+	return []string{fmt.Sprintf("%s:%d", path, line)}
+}
+
+// findFileSearchLineEditor returns the first supported editor CLI on PATH.
+func findFileSearchLineEditor() (fileSearchLineEditor, string, bool) {
+	for _, editor := range fileSearchLineEditors {
+		if executable, err := exec.LookPath(editor.command); err == nil {
+			return editor, executable, true
+		}
+	}
+	return fileSearchLineEditor{}, "", false
+}
+
+// firstFileSearchSnippetLine returns the first matched text line, if any.
+func firstFileSearchSnippetLine(snippets []filesearch.ContentSnippet) int {
+	for _, snippet := range snippets {
+		if snippet.Line > 0 {
+			return snippet.Line
+		}
+	}
+	return 0
+}
+
+// buildOpenAtLineAction is only offered for text hits and only when an editor
+// CLI is installed, because the platform opener has no portable line argument.
+func (c *FileSearchPlugin) buildOpenAtLineAction(ctx context.Context, path string, snippets []filesearch.ContentSnippet) (plugin.QueryResultAction, bool) {
+	line := firstFileSearchSnippetLine(snippets)
+	if line <= 0 {
+		return plugin.QueryResultAction{}, false
+	}
+	editor, executable, found := findFileSearchLineEditor()
+	if !found {
+		return plugin.QueryResultAction{}, false
+	}
+
+	return plugin.QueryResultAction{
+		Name: fmt.Sprintf(c.api.GetTranslation(ctx, "plugin_file_open_at_line"), line, editor.name),
+		Icon: common.PreviewIcon,
+		Action: func(ctx context.Context, actionContext plugin.ActionContext) {
+			if _, err := shell.Run(executable, editor.args(path, line)...); err != nil {
+				c.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("failed to open file search result at line: path=%s line=%d editor=%s err=%s", path, line, editor.command, err.Error()))
+				c.api.Notify(ctx, fmt.Sprintf(c.api.GetTranslation(ctx, "plugin_file_open_failed_description"), err.Error()))
+			}
+		},
+	}, true
+}
+
+// buildFileSearchContentPreview renders content snippets as markdown so the
+// matched terms can be highlighted, which the file preview cannot do.
+func (c *FileSearchPlugin) buildFileSearchContentPreview(ctx context.Context, snippets []filesearch.ContentSnippet) plugin.WoxPreview {
+	return plugin.WoxPreview{
+		PreviewType: plugin.WoxPreviewTypeMarkdown,
+		PreviewData: formatFileSearchContentSnippetsMarkdown(snippets, func(snippet filesearch.ContentSnippet) string {
+			switch {
+			case snippet.Line > 0:
+				return fmt.Sprintf(c.api.GetTranslation(ctx, "plugin_file_content_snippet_line"), snippet.Line)
+			case snippet.Sheet != "":
+				return fmt.Sprintf(c.api.GetTranslation(ctx, "plugin_file_content_snippet_sheet"), snippet.Sheet)
+			case snippet.Page > 0:
+				return fmt.Sprintf(c.api.GetTranslation(ctx, "plugin_file_content_snippet_page"), snippet.Page)
+			default:
+				return ""
+			}
+		}),
+	}
+}
+
+// formatFileSearchContentSnippetsMarkdown writes one paragraph per snippet with
+// an optional location heading, escaped text, bold matches and hard breaks.
+func formatFileSearchContentSnippetsMarkdown(snippets []filesearch.ContentSnippet, location func(filesearch.ContentSnippet) string) string {
+	var builder strings.Builder
+	for index, snippet := range snippets {
+		if index > 0 {
+			builder.WriteString("\n\n")
+		}
+		if label := location(snippet); label != "" {
+			builder.WriteString("**")
+			builder.WriteString(escapeFileSearchMarkdown(label))
+			builder.WriteString("**\n\n")
+		}
+		builder.WriteString(highlightFileSearchSnippet(snippet))
+	}
+	return builder.String()
+}
+
+// highlightFileSearchSnippet escapes markdown punctuation and wraps matches in
+// bold. Leading indentation becomes non-breaking spaces so indented code lines
+// neither collapse nor turn into markdown code blocks.
+func highlightFileSearchSnippet(snippet filesearch.ContentSnippet) string {
+	text := snippet.Text
+	lines := make([]string, 0, strings.Count(text, "\n")+1)
+	var line strings.Builder
+	lineStart := true
+	matchIndex := 0
+	for offset := 0; offset < len(text); {
+		r, size := utf8.DecodeRuneInString(text[offset:])
+		if matchIndex < len(snippet.Matches) && snippet.Matches[matchIndex].Start == offset {
+			line.WriteString("**")
+		}
+
+		switch {
+		case r == '\n':
+			lines = append(lines, line.String())
+			line.Reset()
+			lineStart = true
+		case lineStart && r == ' ':
+			line.WriteRune('\u00a0')
+		case lineStart && r == '\t':
+			line.WriteString(strings.Repeat("\u00a0", 4))
+		default:
+			lineStart = false
+			line.WriteString(escapeFileSearchMarkdown(string(r)))
+		}
+
+		offset += size
+		if matchIndex < len(snippet.Matches) && snippet.Matches[matchIndex].End == offset {
+			line.WriteString("**")
+			matchIndex++
+		}
+	}
+	lines = append(lines, line.String())
+	return strings.Join(lines, "  \n")
+}
+
+func escapeFileSearchMarkdown(text string) string {
+	var builder strings.Builder
+	for _, r := range text {
+		if r < 0x80 && strings.ContainsRune("\\`*_{}[]()<>#+-.!|~", r) {
+			builder.WriteByte('\\')
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
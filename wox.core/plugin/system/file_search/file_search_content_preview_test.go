@@ -0,0 +1,27 @@
+package system
+
+import (
+	"testing"
+	"wox/util/filesearch"
+)
+
+func TestFormatFileSearchContentSnippetsMarkdownHighlightsMatches(t *testing.T) {
+	text := "func main() {\n\tx := load_config(*path)\n}"
+	start := len("func main() {\n\tx := ")
+	snippets := []filesearch.ContentSnippet{{
+		Text:    text,
+		Line:    2,
+		Matches: []filesearch.ContentSnippetMatch{{Start: start, End: start + len("load_config")}},
+	}}
+
+	markdown := formatFileSearchContentSnippetsMarkdown(snippets, func(snippet filesearch.ContentSnippet) string {
+		return "Line 2"
+	})
+	expected := "**Line 2**\n\n" +
+		"func main\\(\\) \\{  \n" +
+		"\u00a0\u00a0\u00a0\u00a0x := **load\\_config**\\(\\*path\\)  \n" +
+		"\\}"
+	if markdown != expected {
+		t.Fatalf("unexpected snippet markdown:\n%q\nwant:\n%q", markdown, expected)
+	}
+}
@@ -0,0 +1,83 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"wox/ai"
+	"wox/plugin"
+	"wox/util/filesearch"
+)
+
+const (
+	// fileSearchSemanticSource names content-indexed files in the semantic index. Document ids are paths.
+	fileSearchSemanticSource      = "file"
+	fileSearchSemanticResultLimit = 30
+)
+
+// semanticDocuments lists the files of the content index. Text is extracted
+// only for files whose content hash changed since they were last embedded.
+func (c *FileSearchPlugin) semanticDocuments(ctx context.Context) ([]ai.SemanticDocument, error) {
+	if c.engine == nil {
+		return nil, ai.ErrSemanticSourceNotReady
+	}
+	if !c.isContentSearchEnabled(ctx) {
+		return nil, nil
+	}
+	hashes, err := c.engine.ListContentHashes(ctx)
+	if errors.Is(err, filesearch.ErrContentIndexNotReady) {
+		return nil, ai.ErrSemanticSourceNotReady
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	documents := make([]ai.SemanticDocument, 0, len(hashes))
+	for path, hash := range hashes {
+		documents = append(documents, ai.SemanticDocument{
+			Id:   path,
+			Hash: strconv.FormatUint(uint64(hash), 16),
+			LoadText: func(ctx context.Context) (string, error) {
+				return c.engine.ExtractContentText(ctx, path)
+			},
+		})
+	}
+	return documents, nil
+}
+
+// searchSemantic answers "f ~ meaning" with files whose content is closest in meaning.
+func (c *FileSearchPlugin) searchSemantic(ctx context.Context, search string) ([]filesearch.SearchResult, error) {
+	hits, err := ai.GetSemanticIndex().Search(ctx, fileSearchSemanticSource, search, fileSearchSemanticResultLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]filesearch.SearchResult, 0, len(hits))
+	for index, hit := range hits {
+		info, err := os.Lstat(hit.Id)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		results = append(results, filesearch.SearchResult{
+			Path:       hit.Id,
+			Name:       filepath.Base(hit.Id),
+			ParentPath: filepath.Dir(hit.Id),
+			Mtime:      info.ModTime().UnixMilli(),
+			Size:       info.Size(),
+			Score:      int64(len(hits) - index),
+		})
+	}
+	return results, nil
+}
+
+func (c *FileSearchPlugin) semanticSearchUnavailableResponse() plugin.QueryResponse {
+	return plugin.NewQueryResponse([]plugin.QueryResult{
+		{
+			Title:    "i18n:ui_ai_semantic_search_unavailable",
+			SubTitle: "i18n:ui_ai_semantic_search_unavailable_subtitle",
+			Icon:     fileIcon,
+		},
+	})
+}
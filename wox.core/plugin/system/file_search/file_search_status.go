@@ -279,7 +279,7 @@ func formatRootStatusCounts(counts map[filesearch.RootStatus]int) string {
 }
 
 func formatRootFeedTypeCounts(counts map[filesearch.RootFeedType]int) string {
-	return formatStatusCountMap([]string{string(filesearch.RootFeedTypeFSEvents), string(filesearch.RootFeedTypeUSN), string(filesearch.RootFeedTypeFallback)}, func(key string) int {
+	return formatStatusCountMap([]string{string(filesearch.RootFeedTypeFSEvents), string(filesearch.RootFeedTypeUSN), string(filesearch.RootFeedTypeInotify), string(filesearch.RootFeedTypeFanotify), string(filesearch.RootFeedTypeFallback)}, func(key string) int {
 		return counts[filesearch.RootFeedType(key)]
 	})
 }
@@ -81,6 +81,20 @@ func (a fileSearchToolbarTestAPI) Screenshot(ctx context.Context, option plugin.
 	return plugin.ScreenshotResult{}
 }
 
+func (a fileSearchToolbarTestAPI) RegisterHotkey(ctx context.Context, hotkey plugin.PluginHotkey) plugin.RegisterHotkeyResult {
+	return plugin.RegisterHotkeyResult{}
+}
+
+func (a fileSearchToolbarTestAPI) UnregisterHotkey(ctx context.Context, id string) {
+}
+
+func (a fileSearchToolbarTestAPI) RegisterContentExtractor(ctx context.Context, extractor filesearch.ContentExtractor) error {
+	return nil
+}
+
+func (a fileSearchToolbarTestAPI) UnregisterContentExtractor(ctx context.Context, name string) {
+}
+
 func TestIncrementalToolbarMessageWaitsForMinimumVisibleDuration(t *testing.T) {
 	plugin := &FileSearchPlugin{api: fileSearchToolbarTestAPI{}}
 
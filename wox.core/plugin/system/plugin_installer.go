@@ -136,10 +136,15 @@ func (i *PluginInstallerPlugin) queryForSelectionFile(ctx context.Context, fileP
 	}
 	pluginDetailJSON, _ := json.Marshal(pluginDetailData)
 
+	// The install action doubles as the consent prompt, so the permissions must be
+	// visible on the row itself rather than only in the plugin settings later.
+	subTitle := fmt.Sprintf("Version: %s, Author: %s\nDescription: %s", pluginMetadata.Version, pluginMetadata.Author, pluginMetadata.GetDescription(ctx))
+	subTitle += "\n" + fmt.Sprintf(i.api.GetTranslation(ctx, "plugin_installer_permissions"), strings.Join(plugin.DescribePermissions(ctx, pluginMetadata.Permissions), ", "))
+
 	// create result for plugin installation
 	results = append(results, plugin.QueryResult{
 		Title:    fmt.Sprintf("%s: %s", actionTitle, pluginMetadata.GetName(ctx)),
-		SubTitle: fmt.Sprintf("Version: %s, Author: %s\nDescription: %s", pluginMetadata.Version, pluginMetadata.Author, pluginMetadata.GetDescription(ctx)),
+		SubTitle: subTitle,
 		Icon:     pluginIcon,
 		Actions: []plugin.QueryResultAction{
 			{
@@ -48,6 +48,7 @@ func init() {
 type ScreenshotPlugin struct {
 	api                plugin.API
 	thumbnailM         sync.Mutex
+	recordingHistoryM  sync.Mutex
 	backgroundM        sync.Mutex
 	backgroundWG       sync.WaitGroup
 	backgroundCtx      context.Context
@@ -218,11 +219,13 @@ func (p *ScreenshotPlugin) Query(ctx context.Context, query plugin.Query) plugin
 }
 
 type screenshotHistoryItem struct {
-	path      string
-	fileName  string
-	size      int64
-	timestamp int64
-	ocrText   string
+	path        string
+	fileName    string
+	size        int64
+	timestamp   int64
+	ocrText     string
+	isRecording bool
+	hasProject  bool
 }
 
 type screenshotOCRSidecar struct {
@@ -321,14 +324,21 @@ func (p *ScreenshotPlugin) listScreenshotHistory() ([]screenshotHistoryItem, err
 			ocrText = sidecar.Text
 		}
 		items = append(items, screenshotHistoryItem{
-			path:      filepath.Join(screenshotDirectory, entry.Name()),
-			fileName:  entry.Name(),
-			size:      info.Size(),
-			timestamp: info.ModTime().UnixMilli(),
-			ocrText:   ocrText,
+			path:       filepath.Join(screenshotDirectory, entry.Name()),
+			fileName:   entry.Name(),
+			size:       info.Size(),
+			timestamp:  info.ModTime().UnixMilli(),
+			ocrText:    ocrText,
+			hasProject: util.IsFileExists(common.ScreenshotProjectPath(filepath.Join(screenshotDirectory, entry.Name()))),
 		})
 	}
 
+	recordings, err := p.listScreenshotRecordingHistory()
+	if err != nil {
+		return nil, err
+	}
+	items = append(items, recordings...)
+
 	sort.Slice(items, func(i, j int) bool {
 		return items[i].timestamp > items[j].timestamp
 	})
@@ -440,11 +450,13 @@ func (p *ScreenshotPlugin) cleanupExpiredScreenshots(ctx context.Context) {
 		removedCount++
 		p.removeScreenshotHistoryThumbnails(ctx, item)
 		p.removeScreenshotOCRSidecar(ctx, item.path)
+		p.removeScreenshotProject(ctx, item.path)
 	}
 
 	if removedCount > 0 {
 		p.api.Log(ctx, plugin.LogLevelInfo, fmt.Sprintf("removed %d expired screenshots older than %d days", removedCount, retentionDays))
 	}
+	p.cleanupExpiredScreenshotRecordings(ctx, cutoff)
 }
 
 func (p *ScreenshotPlugin) warmScreenshotHistoryThumbnails(ctx context.Context) {
@@ -461,6 +473,9 @@ func (p *ScreenshotPlugin) warmScreenshotHistoryThumbnails(ctx context.Context)
 		if ctx.Err() != nil {
 			return
 		}
+		if !screenshotHistoryItemHasThumbnail(item) {
+			continue
+		}
 		if err := p.ensureScreenshotHistoryThumbnails(ctx, item); err != nil {
 			p.api.Log(ctx, plugin.LogLevelWarning, fmt.Sprintf("failed to warm screenshot history thumbnail: path=%s err=%s", item.path, err.Error()))
 		}
@@ -627,6 +642,16 @@ func (p *ScreenshotPlugin) removeScreenshotOCRSidecar(ctx context.Context, scree
 	}
 }
 
+func (p *ScreenshotPlugin) removeScreenshotProject(ctx context.Context, screenshotPath string) {
+	projectPath := common.ScreenshotProjectPath(screenshotPath)
+	if !util.IsFileExists(projectPath) {
+		return
+	}
+	if err := os.Remove(projectPath); err != nil && !os.IsNotExist(err) {
+		p.api.Log(ctx, plugin.LogLevelWarning, fmt.Sprintf("failed to remove expired screenshot project: path=%s err=%s", projectPath, err.Error()))
+	}
+}
+
 func (p *ScreenshotPlugin) scheduleScreenshotOCR(screenshotPath string) {
 	// OCR runs after the screenshot file is already durable. The old history path had no text index,
 	// but blocking screenshot completion on platform OCR would make capture feel unreliable on
@@ -769,6 +794,34 @@ func (p *ScreenshotPlugin) screenshotHistoryResult(item screenshotHistoryItem) p
 		actions := []plugin.QueryResultAction{result.Actions[0], NewCopyOCRTextAction(p.api, ocrText)}
 		result.Actions = append(actions, result.Actions[1:]...)
 	}
+	if item.hasProject {
+		result.Actions = append(result.Actions, plugin.QueryResultAction{
+			Name: "i18n:plugin_screenshot_history_edit_annotations",
+			Icon: common.EditIcon,
+			Action: func(ctx context.Context, actionContext plugin.ActionContext) {
+				p.editScreenshotAnnotations(ctx, item)
+			},
+		})
+	}
+	if item.isRecording {
+		if !screenshotHistoryItemHasThumbnail(item) {
+			// MP4 and WebP recordings can't be decoded into an image preview in Go, the file
+			// preview lets UI pick a player for them instead of showing a broken image.
+			result.Preview = plugin.WoxPreview{PreviewType: plugin.WoxPreviewTypeFile, PreviewData: item.path, PreviewTags: previewTags}
+		}
+		result.Actions[0] = plugin.QueryResultAction{
+			Name:      "i18n:plugin_screenshot_history_copy_file",
+			Icon:      common.CopyIcon,
+			IsDefault: true,
+			Action: func(ctx context.Context, actionContext plugin.ActionContext) {
+				if err := p.copyScreenshotRecordingFile(item.path); err != nil {
+					p.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("failed to copy recording history item: path=%s err=%s", item.path, err.Error()))
+					p.api.Notify(ctx, "i18n:plugin_screenshot_capture_clipboard_warning")
+				}
+			},
+		}
+		result.Actions = append(result.Actions, p.screenshotRecordingExportAction(item))
+	}
 	return result
 }
 
@@ -882,13 +935,35 @@ func (p *ScreenshotPlugin) notifyCaptureFailure(ctx context.Context, errorCode s
 func (p *ScreenshotPlugin) captureScreenshot(ctx context.Context, actionContext plugin.ActionContext) {
 	request := common.DefaultCaptureScreenshotRequest()
 	request.AllowVideoRecording = true
+	// Every capture keeps its unannotated pixels and marks next to the PNG so history can reopen
+	// the editor later. The flattened PNG stays the artifact users copy and share.
+	request.SaveProject = true
+	p.runScreenshotCapture(ctx, request)
+}
+
+// editScreenshotAnnotations reopens a history item in the editor with its marks still editable and
+// writes the result back to the same PNG and project.
+func (p *ScreenshotPlugin) editScreenshotAnnotations(ctx context.Context, item screenshotHistoryItem) {
+	request := common.DefaultCaptureScreenshotRequest()
+	request.ExportFilePath = item.path
+	request.EditProjectPath = common.ScreenshotProjectPath(item.path)
+	request.SaveProject = true
+	if p.runScreenshotCapture(ctx, request) != "" {
+		// thumbnails are keyed by size and mtime, the rewritten PNG already got fresh ones
+		p.removeScreenshotHistoryThumbnails(ctx, item)
+	}
+}
+
+// runScreenshotCapture runs one editor session and returns the exported screenshot path, which is
+// empty for recordings, cancelled sessions and failures.
+func (p *ScreenshotPlugin) runScreenshotCapture(ctx context.Context, request common.CaptureScreenshotRequest) string {
 	result, err := plugin.GetPluginManager().GetUI().CaptureScreenshot(ctx, request)
 	if err != nil {
 		// The screenshot session spans Go, UI, and the native bridge, so transport failures need a local
 		// notification here instead of silently falling through to keep the action predictable for the user.
 		p.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("capture screenshot request failed: %s", err.Error()))
 		p.notifyCaptureFailure(ctx, "", err.Error())
-		return
+		return ""
 	}
 
 	switch result.Status {
@@ -897,17 +972,17 @@ func (p *ScreenshotPlugin) captureScreenshot(ctx context.Context, actionContext
 			if result.ArtifactPath == "" {
 				p.api.Log(ctx, plugin.LogLevelError, "video recording completed without an artifact path")
 				p.notifyCaptureFailure(ctx, "", "")
-				return
+				return ""
 			}
-			p.api.Notify(ctx, result.ArtifactPath)
-			return
+			p.completeScreenshotRecording(ctx, result.ArtifactPath)
+			return ""
 		}
 		// Screenshot export and clipboard write now complete inside UI plus the platform runner.
 		// Go treats a completed export as success and only surfaces clipboard warnings separately.
 		if result.ScreenshotPath == "" {
 			p.api.Log(ctx, plugin.LogLevelError, "screenshot completed without an export path")
 			p.notifyCaptureFailure(ctx, "", "")
-			return
+			return ""
 		}
 		if err := p.ensureScreenshotHistoryThumbnailsForPath(ctx, result.ScreenshotPath); err != nil {
 			// A thumbnail failure should not turn a successful capture into a failed screenshot. The
@@ -924,7 +999,7 @@ func (p *ScreenshotPlugin) captureScreenshot(ctx context.Context, actionContext
 				p.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("failed to pin screenshot: path=%s err=%s", result.ScreenshotPath, err.Error()))
 				p.api.Notify(ctx, "plugin_screenshot_pin_failed")
 			}
-			return
+			return result.ScreenshotPath
 		}
 
 		p.api.Notify(ctx, "plugin_screenshot_capture_success")
@@ -932,6 +1007,7 @@ func (p *ScreenshotPlugin) captureScreenshot(ctx context.Context, actionContext
 			p.api.Log(ctx, plugin.LogLevelWarning, fmt.Sprintf("screenshot clipboard warning: %s", result.ClipboardWarningMessage))
 			p.api.Notify(ctx, "plugin_screenshot_capture_clipboard_warning")
 		}
+		return result.ScreenshotPath
 	case common.CaptureScreenshotStatusFailed:
 		errText := result.ErrorMessage
 		if errText == "" {
@@ -943,9 +1019,9 @@ func (p *ScreenshotPlugin) captureScreenshot(ctx context.Context, actionContext
 		if result.CopiedColor != "" {
 			p.api.Notify(ctx, fmt.Sprintf(p.api.GetTranslation(ctx, "plugin_screenshot_color_copy_success"), result.CopiedColor))
 		}
-		return
 	default:
 		p.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("unexpected screenshot status: %s", result.Status))
 		p.notifyCaptureFailure(ctx, "", "")
 	}
+	return ""
 }
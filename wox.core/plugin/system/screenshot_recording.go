@@ -0,0 +1,337 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"wox/common"
+	"wox/plugin"
+	"wox/setting/definition"
+	"wox/util"
+	"wox/util/clipboard"
+	"wox/util/recordingexport"
+)
+
+var screenshotRecordingHistoryFileName = "recordings.json"
+
+const (
+	screenshotRecordingExportFormatKey = "format"
+	screenshotRecordingExportStartKey  = "start"
+	screenshotRecordingExportEndKey    = "end"
+	screenshotRecordingExportFPSKey    = "fps"
+	screenshotRecordingExportWidthKey  = "width"
+)
+
+// screenshotRecordingHistoryEntry tracks one saved or exported recording.
+// Screenshots are listed straight from the screenshot directory, but recordings
+// are usually saved wherever the user picked in the save dialog, so history
+// needs to remember their paths.
+type screenshotRecordingHistoryEntry struct {
+	Path      string `json:"path"`
+	CreatedAt int64  `json:"createdAt"`
+}
+
+func (p *ScreenshotPlugin) screenshotRecordingHistoryPath() string {
+	return filepath.Join(p.getScreenshotDirectory(), screenshotRecordingHistoryFileName)
+}
+
+func (p *ScreenshotPlugin) readScreenshotRecordingHistory() ([]screenshotRecordingHistoryEntry, error) {
+	data, err := os.ReadFile(p.screenshotRecordingHistoryPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []screenshotRecordingHistoryEntry{}, nil
+		}
+		return nil, fmt.Errorf("failed to read recording history: %w", err)
+	}
+
+	var entries []screenshotRecordingHistoryEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse recording history: %w", err)
+	}
+	return entries, nil
+}
+
+func (p *ScreenshotPlugin) writeScreenshotRecordingHistory(entries []screenshotRecordingHistoryEntry) error {
+	if err := util.GetLocation().EnsureDirectoryExist(p.getScreenshotDirectory()); err != nil {
+		return fmt.Errorf("failed to ensure screenshot directory: %w", err)
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal recording history: %w", err)
+	}
+	historyPath := p.screenshotRecordingHistoryPath()
+	tempPath := historyPath + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write recording history: %w", err)
+	}
+	if err := os.Rename(tempPath, historyPath); err != nil {
+		return fmt.Errorf("failed to replace recording history: %w", err)
+	}
+	return nil
+}
+
+func (p *ScreenshotPlugin) addScreenshotRecordingHistory(recordingPath string) error {
+	p.recordingHistoryM.Lock()
+	defer p.recordingHistoryM.Unlock()
+
+	entries, err := p.readScreenshotRecordingHistory()
+	if err != nil {
+		return err
+	}
+	entries = removeScreenshotRecordingHistoryEntry(entries, recordingPath)
+	entries = append(entries, screenshotRecordingHistoryEntry{Path: recordingPath, CreatedAt: util.GetSystemTimestamp()})
+	return p.writeScreenshotRecordingHistory(entries)
+}
+
+func removeScreenshotRecordingHistoryEntry(entries []screenshotRecordingHistoryEntry, recordingPath string) []screenshotRecordingHistoryEntry {
+	kept := make([]screenshotRecordingHistoryEntry, 0, len(entries))
+	for _, entry := range entries {
+		if filepath.Clean(entry.Path) != filepath.Clean(recordingPath) {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+// listScreenshotRecordingHistory skips recordings the user moved or deleted,
+// the entries themselves are only dropped by retention cleanup.
+func (p *ScreenshotPlugin) listScreenshotRecordingHistory() ([]screenshotHistoryItem, error) {
+	p.recordingHistoryM.Lock()
+	entries, err := p.readScreenshotRecordingHistory()
+	p.recordingHistoryM.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]screenshotHistoryItem, 0, len(entries))
+	for _, entry := range entries {
+		info, statErr := os.Stat(entry.Path)
+		if statErr != nil || info.IsDir() || info.Size() == 0 {
+			continue
+		}
+		items = append(items, screenshotHistoryItem{
+			path:        entry.Path,
+			fileName:    filepath.Base(entry.Path),
+			size:        info.Size(),
+			timestamp:   info.ModTime().UnixMilli(),
+			isRecording: true,
+		})
+	}
+	return items, nil
+}
+
+// cleanupExpiredScreenshotRecordings applies the screenshot retention to
+// recordings. Only files inside the screenshot directory are deleted, a
+// recording saved somewhere else belongs to the user and just leaves history.
+func (p *ScreenshotPlugin) cleanupExpiredScreenshotRecordings(ctx context.Context, cutoff time.Time) {
+	p.recordingHistoryM.Lock()
+	defer p.recordingHistoryM.Unlock()
+
+	entries, err := p.readScreenshotRecordingHistory()
+	if err != nil {
+		p.api.Log(ctx, plugin.LogLevelWarning, fmt.Sprintf("failed to read recording history for cleanup: %s", err.Error()))
+		return
+	}
+
+	kept := make([]screenshotRecordingHistoryEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !time.UnixMilli(entry.CreatedAt).Before(cutoff) {
+			kept = append(kept, entry)
+			continue
+		}
+
+		if info, statErr := os.Stat(entry.Path); statErr == nil {
+			p.removeScreenshotHistoryThumbnails(ctx, screenshotHistoryItem{path: entry.Path, size: info.Size(), timestamp: info.ModTime().UnixMilli()})
+		}
+		if filepath.Dir(filepath.Clean(entry.Path)) == filepath.Clean(p.getScreenshotDirectory()) {
+			if removeErr := os.Remove(entry.Path); removeErr != nil && !os.IsNotExist(removeErr) {
+				p.api.Log(ctx, plugin.LogLevelWarning, fmt.Sprintf("failed to remove expired recording: path=%s err=%s", entry.Path, removeErr.Error()))
+				kept = append(kept, entry)
+			}
+		}
+	}
+	if len(kept) == len(entries) {
+		return
+	}
+	if err := p.writeScreenshotRecordingHistory(kept); err != nil {
+		p.api.Log(ctx, plugin.LogLevelWarning, fmt.Sprintf("failed to update recording history: %s", err.Error()))
+		return
+	}
+	p.api.Log(ctx, plugin.LogLevelInfo, fmt.Sprintf("removed %d expired recordings from history", len(entries)-len(kept)))
+}
+
+// screenshotHistoryItemHasThumbnail reports whether imaging can decode a
+// thumbnail for item. GIF recordings decode to their first frame, MP4 and
+// animated WebP can't be decoded without ffmpeg.
+func screenshotHistoryItemHasThumbnail(item screenshotHistoryItem) bool {
+	return !item.isRecording || strings.EqualFold(filepath.Ext(item.path), ".gif")
+}
+
+// completeScreenshotRecording records a saved or exported recording in history
+// and puts the file on the clipboard, chat tools accept pasted files directly.
+func (p *ScreenshotPlugin) completeScreenshotRecording(ctx context.Context, recordingPath string) {
+	if err := p.addScreenshotRecordingHistory(recordingPath); err != nil {
+		p.api.Log(ctx, plugin.LogLevelWarning, fmt.Sprintf("failed to add recording to history: path=%s err=%s", recordingPath, err.Error()))
+	}
+	item := screenshotHistoryItem{path: recordingPath, isRecording: true}
+	if info, err := os.Stat(recordingPath); err == nil {
+		item.size = info.Size()
+		item.timestamp = info.ModTime().UnixMilli()
+	}
+	if screenshotHistoryItemHasThumbnail(item) {
+		if err := p.ensureScreenshotHistoryThumbnails(ctx, item); err != nil {
+			p.api.Log(ctx, plugin.LogLevelWarning, fmt.Sprintf("failed to generate recording history thumbnails: path=%s err=%s", recordingPath, err.Error()))
+		}
+	}
+
+	if err := p.copyScreenshotRecordingFile(recordingPath); err != nil {
+		p.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("failed to copy recording: path=%s err=%s", recordingPath, err.Error()))
+		p.api.Notify(ctx, "i18n:plugin_screenshot_capture_clipboard_warning")
+		return
+	}
+	p.api.Notify(ctx, "i18n:plugin_screenshot_recording_saved")
+}
+
+func (p *ScreenshotPlugin) copyScreenshotRecordingFile(recordingPath string) error {
+	return clipboard.Write(&clipboard.FilePathData{FilePaths: []string{recordingPath}})
+}
+
+func (p *ScreenshotPlugin) screenshotRecordingExportAction(item screenshotHistoryItem) plugin.QueryResultAction {
+	defaults := recordingexport.DefaultOptions(recordingexport.FormatGIF)
+	return plugin.QueryResultAction{
+		Name: "i18n:plugin_screenshot_recording_export",
+		Icon: common.EditIcon,
+		Type: plugin.QueryResultActionTypeForm,
+		Form: definition.PluginSettingDefinitions{
+			{
+				Type: definition.PluginSettingDefinitionTypeSelect,
+				Value: &definition.PluginSettingValueSelect{
+					Key:          screenshotRecordingExportFormatKey,
+					Label:        "i18n:plugin_screenshot_recording_export_format",
+					DefaultValue: string(recordingexport.FormatGIF),
+					Options: []definition.PluginSettingValueSelectOption{
+						{Label: "GIF", Value: string(recordingexport.FormatGIF)},
+						{Label: "WebP", Value: string(recordingexport.FormatWebP)},
+						{Label: "MP4", Value: string(recordingexport.FormatMP4)},
+					},
+				},
+			},
+			{
+				Type: definition.PluginSettingDefinitionTypeTextBox,
+				Value: &definition.PluginSettingValueTextBox{
+					Key:          screenshotRecordingExportStartKey,
+					Label:        "i18n:plugin_screenshot_recording_export_start",
+					Suffix:       "i18n:plugin_screenshot_recording_export_seconds",
+					DefaultValue: "0",
+				},
+			},
+			{
+				Type: definition.PluginSettingDefinitionTypeTextBox,
+				Value: &definition.PluginSettingValueTextBox{
+					Key:          screenshotRecordingExportEndKey,
+					Label:        "i18n:plugin_screenshot_recording_export_end",
+					Tooltip:      "i18n:plugin_screenshot_recording_export_end_tooltip",
+					Suffix:       "i18n:plugin_screenshot_recording_export_seconds",
+					DefaultValue: "0",
+				},
+			},
+			{
+				Type: definition.PluginSettingDefinitionTypeTextBox,
+				Value: &definition.PluginSettingValueTextBox{
+					Key:          screenshotRecordingExportFPSKey,
+					Label:        "i18n:plugin_screenshot_recording_export_fps",
+					Tooltip:      "i18n:plugin_screenshot_recording_export_keep_tooltip",
+					DefaultValue: strconv.Itoa(defaults.FPS),
+				},
+			},
+			{
+				Type: definition.PluginSettingDefinitionTypeTextBox,
+				Value: &definition.PluginSettingValueTextBox{
+					Key:          screenshotRecordingExportWidthKey,
+					Label:        "i18n:plugin_screenshot_recording_export_width",
+					Tooltip:      "i18n:plugin_screenshot_recording_export_keep_tooltip",
+					DefaultValue: strconv.Itoa(defaults.MaxWidth),
+				},
+			},
+		},
+		OnSubmit: func(ctx context.Context, actionContext plugin.FormActionContext) {
+			options, err := parseScreenshotRecordingExportOptions(actionContext.Values)
+			if err != nil {
+				p.api.Notify(ctx, fmt.Sprintf(p.api.GetTranslation(ctx, "plugin_screenshot_recording_export_failed"), err.Error()))
+				return
+			}
+			// ffmpeg exports of long recordings can take a while, don't block the action
+			util.Go(ctx, "export screenshot recording", func() {
+				p.exportScreenshotRecording(ctx, item.path, options)
+			})
+		},
+	}
+}
+
+func parseScreenshotRecordingExportOptions(values map[string]string) (recordingexport.Options, error) {
+	options := recordingexport.Options{Format: recordingexport.Format(strings.TrimSpace(values[screenshotRecordingExportFormatKey]))}
+	if options.Format == "" {
+		options.Format = recordingexport.FormatGIF
+	}
+
+	seconds := func(key string) (time.Duration, error) {
+		value := strings.TrimSpace(values[key])
+		if value == "" {
+			return 0, nil
+		}
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 {
+			return 0, fmt.Errorf("invalid %s: %s", key, value)
+		}
+		return time.Duration(parsed * float64(time.Second)), nil
+	}
+	integer := func(key string) (int, error) {
+		value := strings.TrimSpace(values[key])
+		if value == "" {
+			return 0, nil
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return 0, fmt.Errorf("invalid %s: %s", key, value)
+		}
+		return parsed, nil
+	}
+
+	var err error
+	if options.Start, err = seconds(screenshotRecordingExportStartKey); err != nil {
+		return options, err
+	}
+	if options.End, err = seconds(screenshotRecordingExportEndKey); err != nil {
+		return options, err
+	}
+	if options.FPS, err = integer(screenshotRecordingExportFPSKey); err != nil {
+		return options, err
+	}
+	if options.MaxWidth, err = integer(screenshotRecordingExportWidthKey); err != nil {
+		return options, err
+	}
+	return options, nil
+}
+
+// exportScreenshotRecording writes the export next to the screenshots so it
+// is covered by the same retention as captures.
+func (p *ScreenshotPlugin) exportScreenshotRecording(ctx context.Context, sourcePath string, options recordingexport.Options) {
+	p.api.Notify(ctx, "i18n:plugin_screenshot_recording_export_started")
+	target := filepath.Join(p.getScreenshotDirectory(), fmt.Sprintf("%s_wox_recording.%s", time.Now().Format("20060102_150405"), options.Format))
+	if err := recordingexport.Export(ctx, sourcePath, target, options); err != nil {
+		p.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("failed to export recording: source=%s target=%s err=%s", sourcePath, target, err.Error()))
+		if errors.Is(err, recordingexport.ErrFFmpegUnavailable) {
+			p.api.Notify(ctx, "i18n:plugin_screenshot_recording_export_ffmpeg_missing")
+			return
+		}
+		p.api.Notify(ctx, fmt.Sprintf(p.api.GetTranslation(ctx, "plugin_screenshot_recording_export_failed"), err.Error()))
+		return
+	}
+	p.completeScreenshotRecording(ctx, target)
+}
@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
+	"wox/util/recordingexport"
 )
 
 func TestScreenshotHistoryThumbnailHasWidth(t *testing.T) {
@@ -39,3 +41,34 @@ func TestNewScreenshotActionAllowsLauncherHide(t *testing.T) {
 		t.Fatal("new screenshot action must allow the launcher to hide")
 	}
 }
+
+func TestParseScreenshotRecordingExportOptions(t *testing.T) {
+	options, err := parseScreenshotRecordingExportOptions(map[string]string{
+		"format": "webp", "start": "1.5", "end": "4", "fps": "12", "width": "",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := recordingexport.Options{Format: recordingexport.FormatWebP, Start: 1500 * time.Millisecond, End: 4 * time.Second, FPS: 12}
+	if options != want {
+		t.Fatalf("options = %+v, want %+v", options, want)
+	}
+
+	if _, err := parseScreenshotRecordingExportOptions(map[string]string{"start": "-1"}); err == nil {
+		t.Fatal("negative trim start must be rejected")
+	}
+	if options, _ := parseScreenshotRecordingExportOptions(map[string]string{}); options.Format != recordingexport.FormatGIF {
+		t.Fatalf("default export format = %s, want gif", options.Format)
+	}
+}
+
+func TestRemoveScreenshotRecordingHistoryEntryDeduplicatesPaths(t *testing.T) {
+	entries := []screenshotRecordingHistoryEntry{
+		{Path: filepath.Join("a", "clip.gif")},
+		{Path: filepath.Join("a", "other.mp4")},
+	}
+	kept := removeScreenshotRecordingHistoryEntry(entries, filepath.Join("a", ".", "clip.gif"))
+	if len(kept) != 1 || kept[0].Path != entries[1].Path {
+		t.Fatalf("kept = %+v", kept)
+	}
+}
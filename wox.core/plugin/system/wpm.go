@@ -9,6 +9,7 @@ import (
 	"os/user"
 	"path"
 	"sort"
+	"strconv"
 	"strings"
 	texttmpl "text/template"
 	"time"
@@ -25,10 +26,12 @@ import (
 	"github.com/google/uuid"
 	cp "github.com/otiai10/copy"
 	"github.com/samber/lo"
+	"github.com/tidwall/gjson"
 )
 
 var wpmIcon = common.PluginWPMIcon
 var localPluginDirectoriesKey = "local_plugin_directories"
+var storeSourcesKey = "store_sources"
 
 const (
 	wpmInstallStatusRefinementKey          = "wpm_install_status"
@@ -182,6 +185,62 @@ func (w *WPMPlugin) GetMetadata() plugin.Metadata {
 					},
 				},
 			},
+			{
+				Type: definition.PluginSettingDefinitionTypeTable,
+				Value: &definition.PluginSettingValueTable{
+					Key:           storeSourcesKey,
+					Title:         "i18n:plugin_wpm_store_sources",
+					Tooltip:       "i18n:plugin_wpm_store_sources_tooltip",
+					SortColumnKey: "priority",
+					SortOrder:     definition.PluginSettingValueTableSortOrderAsc,
+					Columns: []definition.PluginSettingValueTableColumn{
+						{
+							Key:   "name",
+							Label: "i18n:plugin_wpm_store_name",
+							Width: 160,
+							Type:  definition.PluginSettingValueTableColumnTypeText,
+							Validators: []validator.PluginSettingValidator{
+								{
+									Type:  validator.PluginSettingValidatorTypeNotEmpty,
+									Value: &validator.PluginSettingValidatorNotEmpty{},
+								},
+							},
+						},
+						{
+							Key:     "url",
+							Label:   "i18n:plugin_wpm_store_url",
+							Tooltip: "i18n:plugin_wpm_store_url_tooltip",
+							Type:    definition.PluginSettingValueTableColumnTypeText,
+							Validators: []validator.PluginSettingValidator{
+								{
+									Type:  validator.PluginSettingValidatorTypeNotEmpty,
+									Value: &validator.PluginSettingValidatorNotEmpty{},
+								},
+							},
+						},
+						{
+							Key:         "public_key",
+							Label:       "i18n:plugin_wpm_store_public_key",
+							Tooltip:     "i18n:plugin_wpm_store_public_key_tooltip",
+							Type:        definition.PluginSettingValueTableColumnTypeText,
+							HideInTable: true,
+						},
+						{
+							Key:     "priority",
+							Label:   "i18n:plugin_wpm_store_priority",
+							Tooltip: "i18n:plugin_wpm_store_priority_tooltip",
+							Width:   80,
+							Type:    definition.PluginSettingValueTableColumnTypeText,
+						},
+						{
+							Key:   "disabled",
+							Label: "i18n:plugin_wpm_store_disabled",
+							Width: 80,
+							Type:  definition.PluginSettingValueTableColumnTypeCheckbox,
+						},
+					},
+				},
+			},
 		},
 	}
 }
@@ -191,6 +250,13 @@ func (w *WPMPlugin) Init(ctx context.Context, initParams plugin.InitParams) {
 
 	w.reloadAllDevPlugins(ctx)
 
+	plugin.GetStoreManager().SetStoreSources(ctx, parseStoreSources(w.api.GetSetting(ctx, storeSourcesKey)))
+	w.api.OnSettingChanged(ctx, func(callbackCtx context.Context, key string, value string) {
+		if key == storeSourcesKey {
+			plugin.GetStoreManager().SetStoreSources(callbackCtx, parseStoreSources(value))
+		}
+	})
+
 	util.Go(ctx, "reload dev plugins in dist", func() {
 		// must delay reload, because host env is not ready when system plugin init
 		time.Sleep(time.Second * 5)
@@ -202,6 +268,24 @@ func (w *WPMPlugin) Init(ctx context.Context, initParams plugin.InitParams) {
 	})
 }
 
+// parseStoreSources reads the store table setting. Table cells are stored as
+// strings, so priority is parsed leniently and a bad value just sorts first.
+func parseStoreSources(value string) []plugin.StoreSource {
+	var sources []plugin.StoreSource
+	gjson.Parse(value).ForEach(func(_, row gjson.Result) bool {
+		priority, _ := strconv.Atoi(strings.TrimSpace(row.Get("priority").String()))
+		sources = append(sources, plugin.StoreSource{
+			Name:      row.Get("name").String(),
+			Url:       row.Get("url").String(),
+			PublicKey: row.Get("public_key").String(),
+			Priority:  priority,
+			Disabled:  row.Get("disabled").Bool(),
+		})
+		return true
+	})
+	return sources
+}
+
 func (w *WPMPlugin) reloadAllDevPlugins(ctx context.Context) {
 	var localPluginDirs []LocalPlugin
 	unmarshalErr := json.Unmarshal([]byte(w.api.GetSetting(ctx, localPluginDirectoriesKey)), &localPluginDirs)
@@ -365,7 +449,7 @@ func (w *WPMPlugin) buildGlobalStorePluginResult(ctx context.Context, pluginMani
 		Title:    pluginName,
 		SubTitle: pluginManifest.GetDescription(ctx),
 		Icon:     w.buildPluginDetailIcon(pluginManifest),
-		Tails:    []plugin.QueryResultTail{plugin.NewQueryResultTailText(i18n.GetI18nManager().TranslateWox(ctx, "plugin_wpm_plugin_store"))},
+		Tails:    []plugin.QueryResultTail{w.buildStoreSourceTail(ctx, pluginManifest)},
 		Preview:  w.buildPluginDetailPreview(ctx, pluginManifest, false, false),
 		Actions: []plugin.QueryResultAction{
 			{
@@ -639,6 +723,16 @@ func (w *WPMPlugin) uninstallCommand(ctx context.Context, query plugin.Query) []
 	return results
 }
 
+// buildStoreSourceTail names the store a plugin comes from, so plugins from
+// third-party stores are never mistaken for official ones.
+func (w *WPMPlugin) buildStoreSourceTail(ctx context.Context, manifest plugin.StorePluginManifest) plugin.QueryResultTail {
+	storeName := i18n.GetI18nManager().TranslateWox(ctx, "plugin_wpm_plugin_store")
+	if manifest.StoreName != "" && !plugin.IsOfficialStore(manifest.StoreUrl) {
+		storeName = manifest.StoreName
+	}
+	return plugin.NewQueryResultTailText(storeName)
+}
+
 // buildPluginDetailIcon returns the display icon for a store plugin manifest.
 func (w *WPMPlugin) buildPluginDetailIcon(manifest plugin.StorePluginManifest) common.WoxImage {
 	if manifest.IconEmoji != "" {
@@ -711,12 +805,56 @@ func (w *WPMPlugin) buildPostInstallActions(ctx context.Context, pluginManifest
 	return newActions
 }
 
+// withPermissionConsent asks before installing a plugin that wants permissions
+// the user has not granted yet. The first Enter turns the row into a prompt that
+// lists them, and only the allow action runs the install. Plugins that declare
+// no permissions get full access, so they are prompted with the legacy line.
+func (w *WPMPlugin) withPermissionConsent(pluginManifest plugin.StorePluginManifest, installAction plugin.QueryResultAction) plugin.QueryResultAction {
+	consentAction := installAction
+	consentAction.Action = func(ctx context.Context, actionContext plugin.ActionContext) {
+		granted := &plugin.MetadataPermissions{}
+		if installed := plugin.GetPluginManager().GetPluginInstanceById(pluginManifest.Id); installed != nil {
+			granted = installed.Metadata.Permissions
+		}
+		updatable := w.api.GetUpdatableResult(ctx, actionContext.ResultId)
+		if granted.Covers(pluginManifest.Permissions) || updatable == nil {
+			installAction.Action(ctx, actionContext)
+			return
+		}
+
+		originalSubTitle := updatable.SubTitle
+		originalActions := updatable.Actions
+		consentSubTitle := fmt.Sprintf(w.api.GetTranslation(ctx, "i18n:plugin_wpm_permission_consent"), strings.Join(plugin.DescribePermissions(ctx, pluginManifest.Permissions), ", "))
+		allowAction := installAction
+		allowAction.Name = "i18n:plugin_wpm_permission_allow"
+		allowAction.IsDefault = true
+		consentActions := []plugin.QueryResultAction{
+			allowAction,
+			{
+				Name:                   "i18n:plugin_wpm_permission_deny",
+				PreventHideAfterAction: true,
+				Action: func(ctx context.Context, actionContext plugin.ActionContext) {
+					if restored := w.api.GetUpdatableResult(ctx, actionContext.ResultId); restored != nil {
+						restored.SubTitle = originalSubTitle
+						restored.Actions = originalActions
+						w.api.UpdateResult(ctx, *restored)
+					}
+				},
+			},
+		}
+		updatable.SubTitle = &consentSubTitle
+		updatable.Actions = &consentActions
+		w.api.UpdateResult(ctx, *updatable)
+	}
+	return consentAction
+}
+
 // createInstallAction creates an install action with immediate UI lock:
 // as soon as the user presses Enter the install action is removed and the
 // preview shows "Installing..." so they cannot trigger a second install while
 // one is already in flight. On failure the install action is restored.
 func (w *WPMPlugin) createInstallAction(pluginManifest plugin.StorePluginManifest) plugin.QueryResultAction {
-	return plugin.QueryResultAction{
+	return w.withPermissionConsent(pluginManifest, plugin.QueryResultAction{
 		Name:                   "i18n:plugin_wpm_install",
 		Icon:                   common.InstallIcon,
 		PreventHideAfterAction: true,
@@ -762,7 +900,7 @@ func (w *WPMPlugin) createInstallAction(pluginManifest plugin.StorePluginManifes
 
 				// Update tails, preview, and actions to the installed state.
 				if updatable := w.api.GetUpdatableResult(ctx, actionContext.ResultId); updatable != nil {
-					newTails := []plugin.QueryResultTail{{Type: plugin.QueryResultTailTypeImage, Image: common.PluginInstalledIcon}}
+					newTails := []plugin.QueryResultTail{w.buildStoreSourceTail(ctx, pluginManifest), {Type: plugin.QueryResultTailTypeImage, Image: common.PluginInstalledIcon}}
 					updatable.Tails = &newTails
 					successPreview := w.buildPluginDetailPreview(ctx, pluginManifest, true, false)
 					updatable.Preview = &successPreview
@@ -778,14 +916,14 @@ func (w *WPMPlugin) createInstallAction(pluginManifest plugin.StorePluginManifes
 				))
 			})
 		},
-	}
+	})
 }
 
 // createUpgradeAction creates an upgrade action with the same UI-lock pattern
 // as createInstallAction: the action is immediately removed when triggered to
 // prevent double-upgrades, and restored if the upgrade fails.
 func (w *WPMPlugin) createUpgradeAction(pluginManifest plugin.StorePluginManifest) plugin.QueryResultAction {
-	return plugin.QueryResultAction{
+	return w.withPermissionConsent(pluginManifest, plugin.QueryResultAction{
 		Name:                   "i18n:plugin_wpm_upgrade",
 		Icon:                   common.UpdateIcon,
 		PreventHideAfterAction: true,
@@ -831,7 +969,7 @@ func (w *WPMPlugin) createUpgradeAction(pluginManifest plugin.StorePluginManifes
 
 				// Update tails, preview, and actions to the installed state.
 				if updatable := w.api.GetUpdatableResult(ctx, actionContext.ResultId); updatable != nil {
-					newTails := []plugin.QueryResultTail{{Type: plugin.QueryResultTailTypeImage, Image: common.PluginInstalledIcon}}
+					newTails := []plugin.QueryResultTail{w.buildStoreSourceTail(ctx, pluginManifest), {Type: plugin.QueryResultTailTypeImage, Image: common.PluginInstalledIcon}}
 					updatable.Tails = &newTails
 					successPreview := w.buildPluginDetailPreview(ctx, pluginManifest, true, false)
 					updatable.Preview = &successPreview
@@ -847,7 +985,7 @@ func (w *WPMPlugin) createUpgradeAction(pluginManifest plugin.StorePluginManifes
 				))
 			})
 		},
-	}
+	})
 }
 
 // createUninstallAction creates an uninstall action that updates to install action after success
@@ -881,7 +1019,7 @@ func (w *WPMPlugin) createUninstallAction(pluginManifest plugin.StorePluginManif
 
 					// Update tails, preview, and actions to the uninstalled state.
 					if updatable := w.api.GetUpdatableResult(ctx, actionContext.ResultId); updatable != nil {
-						newTails := []plugin.QueryResultTail{}
+						newTails := []plugin.QueryResultTail{w.buildStoreSourceTail(ctx, pluginManifest)}
 						updatable.Tails = &newTails
 						uninstalledPreview := w.buildPluginDetailPreview(ctx, pluginManifest, false, false)
 						updatable.Preview = &uninstalledPreview
@@ -919,8 +1057,8 @@ func (w *WPMPlugin) installCommand(ctx context.Context, query plugin.Query) []pl
 			continue
 		}
 
-		// build tails to indicate installation/upgrade status
-		var tails []plugin.QueryResultTail
+		// build tails to indicate the store and installation/upgrade status
+		tails := []plugin.QueryResultTail{w.buildStoreSourceTail(ctx, pluginManifest)}
 		if installedFlag {
 			// plugin is installed, check if upgrade is available
 			if upgradeFlag {
@@ -2,10 +2,13 @@ package setting
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
+	"wox/cloudsync"
 	"wox/common"
 	"wox/database"
 	"wox/util"
@@ -175,6 +178,38 @@ func (m *Manager) AddQueryHistory(ctx context.Context, query common.PlainQuery)
 	}
 
 	m.woxSetting.QueryHistories.Set(histories)
+	if err := cloudsync.LogEntityUpsert(ctx, cloudsync.EntityQueryHistory, cloudsync.QueryHistorySyncKey, cloudsync.QueryHistorySyncKey, histories); err != nil {
+		util.GetLogger().Warn(ctx, fmt.Sprintf("failed to log query history for cloud sync: %s", err.Error()))
+	}
+}
+
+// MergeSyncedQueryHistories merges a query history list received from cloud
+// sync into the local list. Entries are matched by query identity and the
+// newer timestamp wins, so two devices never drop each other's queries.
+func (m *Manager) MergeSyncedQueryHistories(ctx context.Context, rawValue string) error {
+	var remote []QueryHistory
+	if err := json.Unmarshal([]byte(rawValue), &remote); err != nil {
+		return fmt.Errorf("failed to parse synced query histories: %w", err)
+	}
+
+	merged := cloudsync.MergeCloudSyncItemsByID(m.woxSetting.QueryHistories.Get(), remote, queryHistoryIdentity, func(item QueryHistory) int64 {
+		return item.Timestamp
+	})
+	merged = lo.Filter(merged, func(item QueryHistory, index int) bool {
+		return !item.Query.IsEmpty()
+	})
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Timestamp < merged[j].Timestamp
+	})
+	if len(merged) > 1000 {
+		merged = merged[len(merged)-1000:]
+	}
+
+	return m.woxSetting.QueryHistories.Set(merged)
+}
+
+func queryHistoryIdentity(item QueryHistory) string {
+	return strings.Join([]string{item.Query.QueryType, item.Query.QueryText, item.Query.QuerySelection.String(), item.Query.QueryScope.Identity()}, "\x00")
 }
 
 func plainQueryHistoryEqual(left, right common.PlainQuery) bool {
@@ -251,6 +286,11 @@ func (m *Manager) RemoveMRUItem(ctx context.Context, hash string) error {
 	return m.mruManager.RemoveMRUItem(ctx, hash)
 }
 
+// ApplySyncedMRURecord stores an MRU row received from cloud sync.
+func (m *Manager) ApplySyncedMRURecord(ctx context.Context, hash string, op string, rawValue string) error {
+	return m.mruManager.ApplySyncedMRURecord(ctx, hash, op, rawValue)
+}
+
 func (m *Manager) CleanupOldMRUItems(ctx context.Context, keepCount int) error {
 	return m.mruManager.CleanupOldMRUItems(ctx, keepCount)
 }
@@ -7,6 +7,7 @@ import (
 	"math"
 	"sort"
 	"time"
+	"wox/cloudsync"
 	"wox/common"
 	"wox/database"
 	"wox/util"
@@ -67,7 +68,11 @@ func (m *MRUManager) AddMRUItem(ctx context.Context, item MRUItem) error {
 			CreatedAt:   now,
 			UpdatedAt:   now,
 		}
-		return m.db.Create(&record).Error
+		if err := m.db.Create(&record).Error; err != nil {
+			return err
+		}
+		m.logMRUSync(ctx, record)
+		return nil
 	} else if err != nil {
 		return fmt.Errorf("failed to query MRU record: %w", err)
 	} else {
@@ -79,10 +84,54 @@ func (m *MRUManager) AddMRUItem(ctx context.Context, item MRUItem) error {
 			"icon":         string(iconData), // Update icon in case it changed
 			"updated_at":   now,
 		}
-		return m.db.Model(&existingRecord).Updates(updates).Error
+		if err := m.db.Model(&existingRecord).Updates(updates).Error; err != nil {
+			return err
+		}
+		existingRecord.LastUsed = timestamp
+		existingRecord.UseCount++
+		existingRecord.ContextData = contextDataStr
+		existingRecord.Icon = string(iconData)
+		existingRecord.UpdatedAt = now
+		m.logMRUSync(ctx, existingRecord)
+		return nil
+	}
+}
+
+// logMRUSync queues the stored MRU row for cloud sync. A failed oplog write
+// must not lose the local usage update, so it is only logged.
+func (m *MRUManager) logMRUSync(ctx context.Context, record database.MRURecord) {
+	value, err := json.Marshal(record)
+	if err != nil {
+		util.GetLogger().Warn(ctx, fmt.Sprintf("failed to serialize MRU item %s for cloud sync: %s", record.Hash, err.Error()))
+		return
+	}
+	if err := cloudsync.WriteOplog(m.db, database.Oplog{
+		EntityType: cloudsync.EntityMRU,
+		EntityID:   record.Hash,
+		Operation:  cloudsync.OpUpsert,
+		Key:        record.Hash,
+		Value:      string(value),
+	}); err != nil {
+		util.GetLogger().Warn(ctx, fmt.Sprintf("failed to log MRU item %s for cloud sync: %s", record.Hash, err.Error()))
 	}
 }
 
+// ApplySyncedMRURecord stores an MRU row received from cloud sync without queueing it again.
+func (m *MRUManager) ApplySyncedMRURecord(ctx context.Context, hash string, op string, rawValue string) error {
+	if op == cloudsync.OpDelete {
+		return m.db.Where("hash = ?", hash).Delete(&database.MRURecord{}).Error
+	}
+
+	var record database.MRURecord
+	if err := json.Unmarshal([]byte(rawValue), &record); err != nil {
+		return fmt.Errorf("failed to parse synced MRU item: %w", err)
+	}
+	if record.Hash != hash {
+		return fmt.Errorf("synced MRU item hash mismatch: %s", hash)
+	}
+	return m.db.Save(&record).Error
+}
+
 // GetMRUItems retrieves MRU items sorted by usage with smart scoring
 func (m *MRUManager) GetMRUItems(ctx context.Context, limit int) ([]MRUItem, error) {
 	var records []database.MRURecord
@@ -153,6 +202,15 @@ func (m *MRUManager) RemoveMRUItem(ctx context.Context, hash string) error {
 		return fmt.Errorf("failed to remove MRU item: %w", result.Error)
 	}
 
+	if err := cloudsync.WriteOplog(m.db, database.Oplog{
+		EntityType: cloudsync.EntityMRU,
+		EntityID:   hash,
+		Operation:  cloudsync.OpDelete,
+		Key:        hash,
+	}); err != nil {
+		util.GetLogger().Warn(ctx, fmt.Sprintf("failed to log MRU removal %s for cloud sync: %s", hash, err.Error()))
+	}
+
 	util.GetLogger().Debug(ctx, fmt.Sprintf("removed MRU item: %s", hash))
 	return nil
 }
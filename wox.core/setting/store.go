@@ -8,7 +8,6 @@ import (
 	"strconv"
 	"wox/cloudsync"
 	"wox/database"
-	"wox/util"
 
 	"gorm.io/gorm"
 )
@@ -96,7 +95,7 @@ func (s *WoxSettingStore) logOplog(key string, value interface{}, op string) err
 		Value:      strValue,
 	}
 
-	return writeCloudSyncOplog(s.db, oplog)
+	return cloudsync.WriteOplog(s.db, oplog)
 }
 
 // PluginSettingStore defines the interface for plugin settings
@@ -213,90 +212,7 @@ func (s *PluginSettingStore) logOplog(key string, value interface{}, op string)
 		Value:      strValue,
 	}
 
-	return writeCloudSyncOplog(s.db, oplog)
-}
-
-// writeCloudSyncOplog persists a local sync row according to the built-in CloudSync timing policy.
-func writeCloudSyncOplog(db *gorm.DB, oplog database.Oplog) error {
-	now := util.GetSystemTimestamp()
-	oplog.Timestamp = now
-
-	if oplog.Operation == cloudsync.OpDelete {
-		if err := writeImmediateDeleteCloudSyncOplog(db, oplog); err != nil {
-			return err
-		}
-		return nil
-	}
-
-	policy := cloudsync.ResolveOplogSyncPolicy(oplog.EntityType, oplog.EntityID, oplog.Key, oplog.Operation)
-	if policy.Delay <= 0 {
-		if err := db.Create(&oplog).Error; err != nil {
-			return err
-		}
-		return nil
-	}
-
-	return upsertDeferredCloudSyncOplog(db, oplog, now+policy.Delay.Milliseconds(), now)
-}
-
-// upsertDeferredCloudSyncOplog coalesces high-churn settings into one pending latest-wins row.
-func upsertDeferredCloudSyncOplog(db *gorm.DB, oplog database.Oplog, syncAfter int64, now int64) error {
-	return db.Transaction(func(tx *gorm.DB) error {
-		var existing []database.Oplog
-		if err := tx.Where(
-			"synced_to_cloud = ? AND cloud_sync_discarded = ? AND entity_type = ? AND entity_id = ? AND operation = ? AND key = ?",
-			false,
-			false,
-			oplog.EntityType,
-			oplog.EntityID,
-			oplog.Operation,
-			oplog.Key,
-		).Order("id asc").Find(&existing).Error; err != nil {
-			return err
-		}
-		if len(existing) == 0 {
-			oplog.SyncAfter = syncAfter
-			return tx.Create(&oplog).Error
-		}
-
-		keepID := existing[0].ID
-		if len(existing) > 1 {
-			supersededIDs := make([]uint, 0, len(existing)-1)
-			for _, row := range existing[1:] {
-				supersededIDs = append(supersededIDs, row.ID)
-			}
-			if err := tx.Model(&database.Oplog{}).Where("id IN ?", supersededIDs).Update("synced_to_cloud", true).Error; err != nil {
-				return err
-			}
-		}
-
-		// Keep one pending row per delayed setting identity so rate limits or offline time do not replay stale intermediate values.
-		return tx.Model(&database.Oplog{}).Where("id = ?", keepID).Updates(map[string]interface{}{
-			"value":                        oplog.Value,
-			"timestamp":                    now,
-			"sync_after":                   syncAfter,
-			"cloud_sync_push_failed_count": 0,
-			"cloud_sync_last_push_error":   "",
-		}).Error
-	})
-}
-
-// writeImmediateDeleteCloudSyncOplog prevents an older delayed upsert from resurrecting a deleted setting remotely.
-func writeImmediateDeleteCloudSyncOplog(db *gorm.DB, oplog database.Oplog) error {
-	return db.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Model(&database.Oplog{}).Where(
-			"synced_to_cloud = ? AND cloud_sync_discarded = ? AND entity_type = ? AND entity_id = ? AND operation = ? AND key = ?",
-			false,
-			false,
-			oplog.EntityType,
-			oplog.EntityID,
-			cloudsync.OpUpsert,
-			oplog.Key,
-		).Update("synced_to_cloud", true).Error; err != nil {
-			return err
-		}
-		return tx.Create(&oplog).Error
-	})
+	return cloudsync.WriteOplog(s.db, oplog)
 }
 
 func SerializeValue(value interface{}) (string, error) {
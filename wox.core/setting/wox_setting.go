@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"wox/cloudsync"
 	"wox/common"
 	"wox/i18n"
 	"wox/util"
@@ -22,29 +23,36 @@ type WoxSetting struct {
 	// OnboardingFinished records whether this user data directory has already
 	// seen the first-run guide. This is independent of account age because old
 	// users who never saw the guide should still get one skippable pass.
-	OnboardingFinished *WoxSettingValue[bool]
-	HideOnLostFocus    *WoxSettingValue[bool]
-	ShowTray           *WoxSettingValue[bool]
-	LangCode           *WoxSettingValue[i18n.LangCode]
-	QueryHotkeys       *PlatformValue[[]QueryHotkey]
-	QueryShortcuts     *WoxSettingValue[[]QueryShortcut]
-	TrayQueries        *WoxSettingValue[[]TrayQuery]
-	LaunchMode         *WoxSettingValue[LaunchMode]
-	StartPage          *WoxSettingValue[StartPage]
-	ShowPosition       *WoxSettingValue[PositionType]
-	AIProviders        *WoxSettingValue[[]AIProvider]
-	AIMCPServers       *WoxSettingValue[[]common.AIChatMCPServerConfig]
-	AISkills           *WoxSettingValue[[]common.Skill]
-	EnableAutoBackup   *WoxSettingValue[bool]
-	EnableAutoUpdate   *WoxSettingValue[bool]
-	ReleaseChannel     *WoxSettingValue[ReleaseChannel]
-	CustomPythonPath   *PlatformValue[string]
-	CustomNodejsPath   *PlatformValue[string]
+	OnboardingFinished  *WoxSettingValue[bool]
+	HideOnLostFocus     *WoxSettingValue[bool]
+	ShowTray            *WoxSettingValue[bool]
+	LangCode            *WoxSettingValue[i18n.LangCode]
+	QueryHotkeys        *PlatformValue[[]QueryHotkey]
+	QueryShortcuts      *WoxSettingValue[[]QueryShortcut]
+	TrayQueries         *WoxSettingValue[[]TrayQuery]
+	LaunchMode          *WoxSettingValue[LaunchMode]
+	StartPage           *WoxSettingValue[StartPage]
+	ShowPosition        *WoxSettingValue[PositionType]
+	AIProviders         *WoxSettingValue[[]AIProvider]
+	AIMCPServers        *WoxSettingValue[[]common.AIChatMCPServerConfig]
+	AIToolApprovalRules *WoxSettingValue[[]common.AIToolApprovalRule]
+	AISkills            *WoxSettingValue[[]common.Skill]
+	MCPServerClients    *WoxSettingValue[[]MCPServerClient]
+	EnableAutoBackup    *WoxSettingValue[bool]
+	EnableAutoUpdate    *WoxSettingValue[bool]
+	ReleaseChannel      *WoxSettingValue[ReleaseChannel]
+	CustomPythonPath    *PlatformValue[string]
+	CustomNodejsPath    *PlatformValue[string]
 
 	// CloudSyncServerUrl is a local-only development override. It must not be
 	// synced because each device may target a different test server.
 	CloudSyncServerUrl       *WoxSettingValue[string]
 	CloudSyncDisabledPlugins *WoxSettingValue[[]string]
+	// CloudSyncDisabledEntities lists data entity types (MRU, query history, ...) the user keeps off cloud sync.
+	CloudSyncDisabledEntities *WoxSettingValue[[]string]
+	// CloudSyncBackend is local-only as well: it holds storage credentials and
+	// decides where the synced settings live in the first place.
+	CloudSyncBackend *WoxSettingValue[cloudsync.CloudSyncBackendConfig]
 
 	// HTTP proxy settings
 	HttpProxyEnabled *PlatformValue[bool]
@@ -166,6 +174,17 @@ type AIProvider struct {
 	Alias  string              // optional, used to distinguish multiple configs for the same provider
 	ApiKey string
 	Host   string
+	// EmbeddingModel is optional. The first provider that sets it embeds Wox
+	// data for semantic search.
+	EmbeddingModel string
+}
+
+// MCPServerClient is an external agent or editor allowed to use the Wox MCP
+// server. Tokens are secrets of this machine, so they are not cloud-synced.
+type MCPServerClient struct {
+	Name     string
+	Token    string // sent as "Authorization: Bearer <token>"
+	Disabled bool
 }
 
 const (
@@ -200,6 +219,9 @@ type QueryHotkey struct {
 	MaxResultCount    int
 	Position          QueryHotkeyPosition
 	Disabled          bool
+	// App scopes the hotkey to one application, matched by identity like the
+	// ignored hotkey apps. An empty identity keeps the hotkey global.
+	App IgnoredHotkeyApp
 }
 
 func (q QueryHotkey) DisplayName() string {
@@ -360,6 +382,8 @@ func NewWoxSetting(store *WoxSettingStore) *WoxSetting {
 		CustomNodejsPath:                   NewPlatformValue(store, "CustomNodejsPath", "", "", ""),
 		CloudSyncServerUrl:                 NewLocalWoxSettingValue(store, "CloudSyncServerUrl", ""),
 		CloudSyncDisabledPlugins:           NewWoxSettingValue(store, "CloudSyncDisabledPlugins", []string{}),
+		CloudSyncDisabledEntities:          NewWoxSettingValue(store, "CloudSyncDisabledEntities", []string{}),
+		CloudSyncBackend:                   NewLocalWoxSettingValue(store, "CloudSyncBackend", cloudsync.CloudSyncBackendConfig{}),
 		EnableAutoBackup:                   NewWoxSettingValue(store, "EnableAutoBackup", true),
 		EnableAutoUpdate:                   NewWoxSettingValue(store, "EnableAutoUpdate", true),
 		ReleaseChannel:                     NewWoxSettingValueWithValidator(store, "ReleaseChannel", ReleaseChannelStable, IsValidReleaseChannel),
@@ -370,8 +394,10 @@ func NewWoxSetting(store *WoxSettingStore) *WoxSetting {
 		TrayQueries:                        NewWoxSettingValue(store, "TrayQueries", []TrayQuery{}),
 		AIProviders:                        NewWoxSettingValue(store, "AIProviders", []AIProvider{}),
 		AIMCPServers:                       NewWoxSettingValue(store, "AIMCPServers", []common.AIChatMCPServerConfig{}),
+		AIToolApprovalRules:                NewWoxSettingValue(store, "AIToolApprovalRules", []common.AIToolApprovalRule{}),
 		AISkills:                           NewWoxSettingValue(store, "AISkills", []common.Skill{}),
-		QueryHistories:                     NewWoxSettingValue(store, "QueryHistories", []QueryHistory{}),
+		MCPServerClients:                   NewLocalWoxSettingValue(store, "MCPServerClients", []MCPServerClient{}),
+		QueryHistories:                     NewLocalWoxSettingValue(store, "QueryHistories", []QueryHistory{}),
 		QueryCompletionFeedbacks:           NewWoxSettingValue(store, "QueryCompletionFeedback", []QueryCompletionFeedback{}),
 		PinedResults:                       NewWoxSettingValue(store, "PinedResults", util.NewHashMap[ResultHash, bool]()),
 		ActionedResults:                    NewWoxSettingValue(store, "ActionedResults", util.NewHashMap[ResultHash, []ActionedResult]()),
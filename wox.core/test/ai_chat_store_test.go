@@ -0,0 +1,245 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"wox/common"
+	"wox/database"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newAIChatTestStore(t *testing.T) (*database.AIChatStore, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "ai_chat_test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("get underlying sql db: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(&database.AIChat{}, &database.AIChatMessage{}, &database.AIChatToolCall{}); err != nil {
+		t.Fatalf("migrate AI chat tables: %v", err)
+	}
+	if err := database.InitAIChatFullTextSearch(context.Background(), db); err != nil {
+		t.Fatalf("init AI chat search: %v", err)
+	}
+	return database.NewAIChatStore(db), db
+}
+
+func testAIChat() common.AIChatData {
+	return common.AIChatData{
+		Id:    "chat-1",
+		Title: "Rust lifetimes",
+		Model: common.Model{Name: "gpt-4o", Provider: "openai"},
+		Conversations: []common.Conversation{
+			{Id: "m1", Role: common.ConversationRoleUser, Text: "Explain borrow checker lifetimes", Timestamp: 1000},
+			{Id: "m2", Role: common.ConversationRoleAssistant, Text: "Lifetimes describe how long references stay valid.", Reasoning: "User asks about Rust.", ReasoningSignature: "sig-1", RedactedReasoning: []string{"encrypted-1"}, Timestamp: 2000},
+			{
+				Id:   "t1",
+				Role: common.ConversationRoleTool,
+				Text: "",
+				ToolCallInfo: common.ToolCallInfo{
+					Id:        "call-1",
+					Name:      "web_search",
+					Arguments: map[string]any{"query": "rust lifetimes"},
+					Status:    common.ToolCallStatusSucceeded,
+					Response:  "doc.rust-lang.org",
+				},
+				Timestamp: 3000,
+			},
+		},
+		CreatedAt: 1000,
+		UpdatedAt: 3000,
+	}
+}
+
+func TestAIChatStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newAIChatTestStore(t)
+
+	chat := testAIChat()
+	if err := store.SaveChat(ctx, chat); err != nil {
+		t.Fatalf("save chat: %v", err)
+	}
+
+	loaded, found, err := store.GetChat(ctx, chat.Id)
+	if err != nil || !found {
+		t.Fatalf("get chat: found=%v err=%v", found, err)
+	}
+	if loaded.Title != chat.Title || loaded.Model.Name != "gpt-4o" || len(loaded.Conversations) != 3 {
+		t.Fatalf("unexpected chat: %+v", loaded)
+	}
+	if loaded.Conversations[1].ReasoningSignature != "sig-1" {
+		t.Fatalf("expected reasoning signature to be kept, got %q", loaded.Conversations[1].ReasoningSignature)
+	}
+	if len(loaded.Conversations[1].RedactedReasoning) != 1 || loaded.Conversations[1].RedactedReasoning[0] != "encrypted-1" {
+		t.Fatalf("expected redacted reasoning to be kept, got %v", loaded.Conversations[1].RedactedReasoning)
+	}
+	toolCall := loaded.Conversations[2].ToolCallInfo
+	if toolCall.Name != "web_search" || toolCall.Arguments["query"] != "rust lifetimes" || toolCall.Response != "doc.rust-lang.org" {
+		t.Fatalf("unexpected tool call: %+v", toolCall)
+	}
+
+	// Dropping a conversation from the chat removes its row on the next full save.
+	chat.Conversations = chat.Conversations[:2]
+	if err := store.SaveChat(ctx, chat); err != nil {
+		t.Fatalf("resave chat: %v", err)
+	}
+	loaded, _, _ = store.GetChat(ctx, chat.Id)
+	if len(loaded.Conversations) != 2 {
+		t.Fatalf("expected stale conversation to be removed, got %d", len(loaded.Conversations))
+	}
+
+	if err := store.DeleteChat(ctx, chat.Id); err != nil {
+		t.Fatalf("delete chat: %v", err)
+	}
+	chats, err := store.ListChats(ctx)
+	if err != nil || len(chats) != 0 {
+		t.Fatalf("expected no chats after delete, got %d (%v)", len(chats), err)
+	}
+}
+
+func TestAIChatStoreIncrementalSave(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newAIChatTestStore(t)
+
+	chat := testAIChat()
+	chat.Conversations = chat.Conversations[:1]
+	if err := store.SaveChat(ctx, chat); err != nil {
+		t.Fatalf("save chat: %v", err)
+	}
+
+	// Only the listed conversation is written; m1 is changed in memory but must
+	// keep its stored text.
+	chat.Conversations[0].Text = "not persisted"
+	chat.Conversations = append(chat.Conversations, common.Conversation{Id: "m2", Role: common.ConversationRoleAssistant, Text: "partial", Timestamp: 2000})
+	chat.UpdatedAt = 2000
+	if err := store.SaveConversations(ctx, chat, "m2"); err != nil {
+		t.Fatalf("save conversations: %v", err)
+	}
+	chat.Conversations[1].Text = "partial answer"
+	if err := store.SaveConversations(ctx, chat, "m2"); err != nil {
+		t.Fatalf("update conversation: %v", err)
+	}
+
+	loaded, _, _ := store.GetChat(ctx, chat.Id)
+	if len(loaded.Conversations) != 2 || loaded.Conversations[0].Text != "Explain borrow checker lifetimes" || loaded.Conversations[1].Text != "partial answer" {
+		t.Fatalf("unexpected conversations: %+v", loaded.Conversations)
+	}
+	if loaded.UpdatedAt != 2000 {
+		t.Fatalf("expected chat row to be updated, got %d", loaded.UpdatedAt)
+	}
+}
+
+func TestAIChatStoreSearchMessages(t *testing.T) {
+	ctx := context.Background()
+	store, db := newAIChatTestStore(t)
+
+	if err := store.SaveChat(ctx, testAIChat()); err != nil {
+		t.Fatalf("save chat: %v", err)
+	}
+	other := testAIChat()
+	other.Id = "chat-2"
+	other.Title = "Dinner"
+	other.Conversations = []common.Conversation{{Id: "m1", Role: common.ConversationRoleUser, Text: "Suggest a pasta recipe", Timestamp: 5000}}
+	if err := store.SaveChat(ctx, other); err != nil {
+		t.Fatalf("save other chat: %v", err)
+	}
+
+	var ftsTables int64
+	db.Raw(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'ai_chat_messages_fts'`).Scan(&ftsTables)
+
+	hits, err := store.SearchMessages(ctx, "lifetime", 10)
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(hits) == 0 {
+		t.Fatalf("expected hits for lifetime (fts=%d)", ftsTables)
+	}
+	for _, hit := range hits {
+		if hit.ChatID != "chat-1" || hit.ChatTitle != "Rust lifetimes" {
+			t.Fatalf("unexpected hit: %+v", hit)
+		}
+		if hit.Role == string(common.ConversationRoleTool) {
+			t.Fatalf("tool output should not be searchable: %+v", hit)
+		}
+	}
+
+	hits, err = store.SearchMessages(ctx, "pasta", 10)
+	if err != nil || len(hits) != 1 || hits[0].ChatID != "chat-2" {
+		t.Fatalf("expected one pasta hit, got %+v (%v)", hits, err)
+	}
+
+	// Input without an indexable token falls back to LIKE, where wildcards
+	// must match literally instead of matching every message.
+	hits, err = store.SearchMessages(ctx, "%_", 10)
+	if err != nil || len(hits) != 0 {
+		t.Fatalf("expected no hits for literal wildcards, got %+v (%v)", hits, err)
+	}
+
+	// Deleting a chat must also drop it from the index.
+	if err := store.DeleteChat(ctx, "chat-2"); err != nil {
+		t.Fatalf("delete chat: %v", err)
+	}
+	hits, _ = store.SearchMessages(ctx, "pasta", 10)
+	if len(hits) != 0 {
+		t.Fatalf("expected deleted chat to leave search results, got %+v", hits)
+	}
+}
+
+func TestAIChatStoreExport(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newAIChatTestStore(t)
+
+	chat := testAIChat()
+	if err := store.SaveChat(ctx, chat); err != nil {
+		t.Fatalf("save chat: %v", err)
+	}
+
+	markdown, err := store.ExportChatMarkdown(ctx, chat.Id)
+	if err != nil {
+		t.Fatalf("export markdown: %v", err)
+	}
+	for _, expected := range []string{"# Rust lifetimes", "## User", "Explain borrow checker lifetimes", "> User asks about Rust.", "<summary>Tool: web_search", `"query": "rust lifetimes"`} {
+		if !strings.Contains(markdown, expected) {
+			t.Fatalf("expected markdown to contain %q:\n%s", expected, markdown)
+		}
+	}
+
+	data, err := store.ExportChatJSON(ctx, chat.Id)
+	if err != nil {
+		t.Fatalf("export json: %v", err)
+	}
+	var exported common.AIChatData
+	if err := json.Unmarshal(data, &exported); err != nil {
+		t.Fatalf("parse exported json: %v", err)
+	}
+	if exported.Id != chat.Id || len(exported.Conversations) != 3 {
+		t.Fatalf("unexpected exported chat: %+v", exported)
+	}
+
+	// A message without a role must still export instead of panicking.
+	chat.Conversations = append(chat.Conversations, common.Conversation{Id: "m3", Text: "orphan", Timestamp: 4000})
+	if err := store.SaveChat(ctx, chat); err != nil {
+		t.Fatalf("save chat without role: %v", err)
+	}
+	if markdown, err = store.ExportChatMarkdown(ctx, chat.Id); err != nil || !strings.Contains(markdown, "orphan") {
+		t.Fatalf("expected message without role to be exported, got %v:\n%s", err, markdown)
+	}
+
+	if _, err := store.ExportChatMarkdown(ctx, "missing"); err == nil {
+		t.Fatalf("expected an error for a missing chat")
+	}
+}
@@ -0,0 +1,211 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"wox/ai"
+	"wox/database"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// keywordEmbedder maps each text to counts of a few known words, so texts
+// about the same topic point in the same direction.
+type keywordEmbedder struct {
+	model    string
+	embedded []string
+}
+
+var keywordEmbedderWords = []string{"cat", "dog", "rust", "invoice"}
+
+func (e *keywordEmbedder) Model() string {
+	return e.model
+}
+
+func (e *keywordEmbedder) Embed(ctx context.Context, inputs []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(inputs))
+	for _, input := range inputs {
+		e.embedded = append(e.embedded, input)
+		vector := make([]float32, len(keywordEmbedderWords)+1)
+		for i, word := range keywordEmbedderWords {
+			vector[i] = float32(strings.Count(strings.ToLower(input), word))
+		}
+		// Keep texts without known words apart from every query.
+		vector[len(keywordEmbedderWords)] = 0.01
+		vectors = append(vectors, vector)
+	}
+	return vectors, nil
+}
+
+func newSemanticIndexTestStore(t *testing.T) *database.AIEmbeddingStore {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "ai_embedding_test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("get underlying sql db: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(&database.AIEmbedding{}); err != nil {
+		t.Fatalf("migrate embedding table: %v", err)
+	}
+	return database.NewAIEmbeddingStore(db)
+}
+
+func semanticHitIds(hits []ai.SemanticHit) []string {
+	ids := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.Id)
+	}
+	return ids
+}
+
+func TestSemanticIndexIncrementalIndexAndSearch(t *testing.T) {
+	ctx := context.Background()
+	embedder := &keywordEmbedder{model: "fake/v1"}
+	index := ai.NewSemanticIndex(newSemanticIndexTestStore(t), func(ctx context.Context) (ai.Embedder, error) {
+		return embedder, nil
+	})
+
+	documents := []ai.SemanticDocument{
+		{Id: "1", Text: "my cat sleeps on the sofa"},
+		{Id: "2", Text: "rust borrow checker"},
+		{Id: "3", Text: "cat and dog playing"},
+		{Id: "4", Text: "   "},
+	}
+	ready := true
+	index.RegisterSource(ai.SemanticSource{Name: "notes", Documents: func(ctx context.Context) ([]ai.SemanticDocument, error) {
+		if !ready {
+			return nil, ai.ErrSemanticSourceNotReady
+		}
+		return documents, nil
+	}})
+
+	if err := index.IndexAll(ctx); err != nil {
+		t.Fatalf("index: %v", err)
+	}
+	if len(embedder.embedded) != 3 {
+		t.Fatalf("expected 3 non-empty documents to be embedded, got %q", embedder.embedded)
+	}
+
+	hits, err := index.Search(ctx, "notes", "cat", 10)
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if ids := semanticHitIds(hits); len(ids) != 2 || ids[0] != "1" || ids[1] != "3" {
+		t.Fatalf("expected cat documents best first, got %v", ids)
+	}
+	if hits, _ := index.Search(ctx, "other", "cat", 10); len(hits) != 0 {
+		t.Fatalf("expected sources to be searched separately, got %v", semanticHitIds(hits))
+	}
+
+	// Unchanged documents are not embedded again, changed ones are and missing ones are removed.
+	embedder.embedded = nil
+	documents = []ai.SemanticDocument{
+		{Id: "1", Text: "my cat sleeps on the sofa"},
+		{Id: "2", Text: "invoice for march"},
+	}
+	if err := index.IndexAll(ctx); err != nil {
+		t.Fatalf("reindex: %v", err)
+	}
+	if len(embedder.embedded) != 1 || embedder.embedded[0] != "invoice for march" {
+		t.Fatalf("expected only the changed document to be embedded, got %q", embedder.embedded)
+	}
+	if hits, _ := index.Search(ctx, "notes", "cat", 10); len(hits) != 1 || hits[0].Id != "1" {
+		t.Fatalf("expected removed document to be gone, got %v", semanticHitIds(hits))
+	}
+	if hits, _ := index.Search(ctx, "notes", "rust", 10); len(hits) != 0 {
+		t.Fatalf("expected changed document to match its new text only, got %v", semanticHitIds(hits))
+	}
+
+	// A source that is not ready keeps its vectors.
+	ready = false
+	if err := index.IndexAll(ctx); err != nil {
+		t.Fatalf("index not ready source: %v", err)
+	}
+	if hits, _ := index.Search(ctx, "notes", "invoice", 10); len(hits) != 1 || hits[0].Id != "2" {
+		t.Fatalf("expected vectors to survive a source that is not ready, got %v", semanticHitIds(hits))
+	}
+
+	// Switching models embeds everything again in the new vector space.
+	ready = true
+	embedder.model = "fake/v2"
+	embedder.embedded = nil
+	if err := index.IndexAll(ctx); err != nil {
+		t.Fatalf("index with new model: %v", err)
+	}
+	if len(embedder.embedded) != 2 {
+		t.Fatalf("expected all documents to be embedded with the new model, got %q", embedder.embedded)
+	}
+}
+
+func TestSemanticIndexLoadTextAndUnavailable(t *testing.T) {
+	ctx := context.Background()
+	embedder := &keywordEmbedder{model: "fake"}
+	index := ai.NewSemanticIndex(newSemanticIndexTestStore(t), func(ctx context.Context) (ai.Embedder, error) {
+		return embedder, nil
+	})
+
+	loads := 0
+	index.RegisterSource(ai.SemanticSource{Name: "files", Documents: func(ctx context.Context) ([]ai.SemanticDocument, error) {
+		return []ai.SemanticDocument{
+			{Id: "/a.txt", Hash: "h1", LoadText: func(ctx context.Context) (string, error) {
+				loads++
+				return "dog walking schedule", nil
+			}},
+			{Id: "/b.bin", Hash: "h2", LoadText: func(ctx context.Context) (string, error) {
+				return "", errors.New("unreadable")
+			}},
+		}, nil
+	}})
+	for range 2 {
+		if err := index.IndexAll(ctx); err != nil {
+			t.Fatalf("index: %v", err)
+		}
+	}
+	if loads != 1 {
+		t.Fatalf("expected unchanged content to be read once, got %d reads", loads)
+	}
+	if hits, _ := index.Search(ctx, "files", "dog", 10); len(hits) != 1 || hits[0].Id != "/a.txt" {
+		t.Fatalf("unexpected hits: %v", semanticHitIds(hits))
+	}
+
+	unavailable := ai.NewSemanticIndex(newSemanticIndexTestStore(t), func(ctx context.Context) (ai.Embedder, error) {
+		return nil, ai.ErrSemanticSearchUnavailable
+	})
+	if _, err := unavailable.Search(ctx, "files", "dog", 10); !errors.Is(err, ai.ErrSemanticSearchUnavailable) {
+		t.Fatalf("expected unavailable error, got %v", err)
+	}
+}
+
+func TestParseSemanticQuery(t *testing.T) {
+	cases := map[string]struct {
+		query string
+		ok    bool
+	}{
+		"~ tax documents": {"tax documents", true},
+		"~invoice":        {"invoice", true},
+		"  ~  cats ":      {"cats", true},
+		"~":               {"", false},
+		"~/Documents":     {"", false},
+		`~\Documents`:     {"", false},
+		"invoice ~":       {"", false},
+	}
+	for search, expected := range cases {
+		query, ok := ai.ParseSemanticQuery(search)
+		if query != expected.query || ok != expected.ok {
+			t.Fatalf("ParseSemanticQuery(%q) = %q, %v", search, query, ok)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"wox/account"
 	"wox/cloudsync"
+	"wox/setting"
 	"wox/ui/contract"
 	"wox/util"
 )
@@ -64,7 +65,7 @@ func startSyncBootstrap(ctx context.Context, recoveryCode string) error {
 	}
 	cloudsync.MarkCloudSyncBootstrapPending(ctx)
 
-	if accountService := account.GetService(); accountService != nil {
+	if accountService := account.GetService(); accountService != nil && !service.SelfHosted() {
 		if err := accountService.SetSyncEnabled(ctx, true); err != nil {
 			return err
 		}
@@ -115,6 +116,14 @@ func scheduleCloudSyncBootstrapInitialPush(ctx context.Context, service *cloudsy
 }
 
 func ensureSyncBootstrapAllowed(ctx context.Context) error {
+	service := cloudsync.GetService()
+	if cloudSyncBackendRestartRequired(ctx, service) {
+		return fmt.Errorf("cloud sync backend changed, restart Wox to apply it")
+	}
+	// Self-hosted backends are owned by the user and need no Wox account.
+	if service.SelfHosted() {
+		return nil
+	}
 	accountService := account.GetService()
 	accountStatus := account.Status{}
 	if accountService != nil {
@@ -135,17 +144,20 @@ func startCloudSyncManagerAfterUIReady(ctx context.Context) {
 }
 
 // startCloudSyncManagerIfSyncEnabled starts a configured scheduler after account and bootstrap checks pass.
+// Self-hosted backends skip the account checks.
 func startCloudSyncManagerIfSyncEnabled(ctx context.Context, service *cloudsync.Service) {
 	if service == nil || service.Manager == nil {
 		return
 	}
-	accountService := account.GetService()
-	if accountService == nil {
-		return
-	}
-	accountStatus := accountService.Status(ctx)
-	if !accountStatus.LoggedIn || !accountStatus.SyncEligible || !accountStatus.SyncEnabled {
-		return
+	if !service.SelfHosted() {
+		accountService := account.GetService()
+		if accountService == nil {
+			return
+		}
+		accountStatus := accountService.Status(ctx)
+		if !accountStatus.LoggedIn || !accountStatus.SyncEligible || !accountStatus.SyncEnabled {
+			return
+		}
 	}
 	if service.KeyManager == nil || !service.KeyManager.GetStatus(ctx).Available {
 		return
@@ -160,3 +172,12 @@ func startCloudSyncManagerIfSyncEnabled(ctx context.Context, service *cloudsync.
 	}
 	service.StartManager(ctx)
 }
+
+// cloudSyncBackendRestartRequired reports whether the backend setting changed
+// after the running sync service was built.
+func cloudSyncBackendRestartRequired(ctx context.Context, service *cloudsync.Service) bool {
+	if service == nil {
+		return false
+	}
+	return setting.GetSettingManager().GetWoxSetting(ctx).CloudSyncBackend.Get() != service.Backend
+}
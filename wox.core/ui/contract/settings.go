@@ -144,7 +144,9 @@ type GeneralSettings struct {
 	StartPage                          setting.StartPage
 	AIProviders                        []setting.AIProvider
 	AIMCPServers                       []common.AIChatMCPServerConfig
+	AIToolApprovalRules                []common.AIToolApprovalRule
 	AISkills                           []common.Skill
+	MCPServerClients                   []setting.MCPServerClient
 	HTTPProxyEnabled                   bool
 	HTTPProxyURL                       string
 	ShowPosition                       setting.PositionType
@@ -159,6 +161,8 @@ type GeneralSettings struct {
 	CustomNodejsPath                   string
 	CloudSyncServerURL                 string
 	CloudSyncDisabledPlugins           []string
+	CloudSyncDisabledEntities          []string
+	CloudSyncBackend                   cloudsync.CloudSyncBackendConfig
 	AppWidth                           int
 	MaxResultCount                     int
 	UIDensity                          setting.UiDensity
@@ -228,7 +232,9 @@ type HotkeyInteractionSettingsServices interface {
 	StartHotkeyRecording(ctx context.Context, sessionID string, purpose string, allowedKinds []string) (HotkeyRecordingCapability, error)
 	StopHotkeyRecording(ctx context.Context, sessionID string) error
 	SubmitHotkeyRecordingCandidate(ctx context.Context, sessionID string, hotkey string) error
-	CheckHotkeyAvailability(ctx context.Context, sessionID string, hotkey string) (HotkeyAvailability, error)
+	// CheckHotkeyAvailability checks hotkey against Wox-owned and system hotkeys.
+	// appIdentity scopes the check to one app and is empty for global hotkeys.
+	CheckHotkeyAvailability(ctx context.Context, sessionID string, hotkey string, appIdentity string) (HotkeyAvailability, error)
 }
 
 // WindowManagerSettingsServices exposes browser integration used by workspace layouts.
@@ -283,11 +289,53 @@ type AISkill struct {
 	Enabled      bool
 }
 
-// AICatalogSettingsServices exposes provider, model, and skill catalogs.
+// AIMCPPrompt is an MCP prompt template offered as a chat command.
+type AIMCPPrompt struct {
+	ServerName  string
+	Name        string
+	Description string
+	Arguments   []AIMCPPromptArgument
+}
+
+// AIMCPPromptArgument describes one argument of an MCP prompt.
+type AIMCPPromptArgument struct {
+	Name        string
+	Description string
+	Required    bool
+}
+
+// AIMCPResource is an MCP resource that can be attached to a chat message.
+type AIMCPResource struct {
+	ServerName  string
+	URI         string
+	Name        string
+	Description string
+	MimeType    string
+}
+
+// AIMCPServerHealth is the connection state shown next to each MCP server.
+type AIMCPServerHealth struct {
+	Name          string
+	State         string
+	Error         string
+	ToolCount     int
+	ResourceCount int
+	PromptCount   int
+}
+
+// AIMCPCatalog groups the prompts, resources, and server states of all MCP servers.
+type AIMCPCatalog struct {
+	Prompts   []AIMCPPrompt
+	Resources []AIMCPResource
+	Servers   []AIMCPServerHealth
+}
+
+// AICatalogSettingsServices exposes provider, model, skill, and MCP catalogs.
 type AICatalogSettingsServices interface {
 	AIProviders(ctx context.Context, sessionID string) ([]AIProvider, error)
 	AIModels(ctx context.Context, sessionID string) ([]AIModel, error)
 	AISkills(ctx context.Context, sessionID string) ([]AISkill, error)
+	AIMCPCatalog(ctx context.Context, sessionID string) (AIMCPCatalog, error)
 }
 
 // AIOperationSettingsServices exposes settings-owned AI skill mutations.
@@ -397,6 +445,7 @@ type PluginCatalogItem struct {
 	SupportedOS        []string
 	Features           []plugin.MetadataFeature
 	Glances            []plugin.MetadataGlance
+	Permissions        *plugin.MetadataPermissions
 	IsSystem           bool
 	IsDev              bool
 	IsInstalled        bool
@@ -28,6 +28,7 @@ type PluginDto struct {
 	Setting            PluginSettingDto                    // only available when plugin is installed
 	Features           []plugin.MetadataFeature            // only available when plugin is installed
 	Glances            []plugin.MetadataGlance
+	Permissions        *plugin.MetadataPermissions
 	IsSystem           bool
 	IsDev              bool
 	IsInstalled        bool
@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"wox/cloudsync"
 	"wox/common"
 	"wox/i18n"
 	"wox/setting"
@@ -29,7 +30,9 @@ type WoxSettingDto struct {
 	StartPage             setting.StartPage
 	AIProviders           []setting.AIProvider
 	AIMCPServers          []common.AIChatMCPServerConfig
+	AIToolApprovalRules   []common.AIToolApprovalRule
 	AISkills              []common.Skill
+	MCPServerClients      []setting.MCPServerClient
 	HttpProxyEnabled      bool
 	HttpProxyUrl          string
 	ShowPosition          setting.PositionType
@@ -47,6 +50,8 @@ type WoxSettingDto struct {
 	CustomNodejsPath          string
 	CloudSyncServerUrl        string
 	CloudSyncDisabledPlugins  []string
+	CloudSyncDisabledEntities []string
+	CloudSyncBackend          cloudsync.CloudSyncBackendConfig
 
 	// UI related
 	AppWidth       int
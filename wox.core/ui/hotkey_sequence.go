@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"wox/util"
+	utilhotkey "wox/util/hotkey"
+	"wox/util/overlay"
+	"wox/util/overlay/textoverlay"
+	"wox/util/window"
+)
+
+const hotkeySequenceOverlayID = "wox-hotkey-sequence"
+
+// activeHotkeyAppIdentity resolves the frontmost app with the same identity the
+// ignored hotkey apps use. Wayland exposes no active window identity, so
+// app-scoped hotkeys fall back to their global bindings there.
+func (m *Manager) activeHotkeyAppIdentity() string {
+	if util.IsLinuxWaylandSession() {
+		return ""
+	}
+	return strings.TrimSpace(window.GetProcessIdentity(window.GetActiveWindowPid()))
+}
+
+// handleHotkeySequenceStart applies the shared hotkey gates to a sequence leader
+// before it starts listening for the next keys.
+func (m *Manager) handleHotkeySequenceStart(leader string) bool {
+	ctx := util.NewTraceContext()
+	logger.Info(ctx, fmt.Sprintf("hotkey sequence leader received: hotkey=%s recordingActive=%t", leader, m.isHotkeyRecordingActive()))
+	if m.recordHotkeyIfRecording(ctx, leader) {
+		return false
+	}
+	return !m.shouldIgnoreHotkeyTrigger(ctx)
+}
+
+// showHotkeySequenceProgress keeps the which-key overlay in sync with the active sequence.
+func (m *Manager) showHotkeySequenceProgress(progress utilhotkey.SequenceProgress) {
+	if progress.Done || len(progress.Hints) == 0 {
+		overlay.Close(hotkeySequenceOverlayID)
+		return
+	}
+	textoverlay.Show(textoverlay.Options{
+		Window: overlay.WindowOptions{
+			ID:      hotkeySequenceOverlayID,
+			Anchor:  overlay.AnchorBottomCenter,
+			OffsetY: -80,
+			Topmost: true,
+		},
+		Title:   hotkeySequenceLabel(progress.Typed),
+		Message: hotkeySequenceOverlayMessage(progress.Hints),
+	})
+}
+
+// hotkeySequenceOverlayMessage renders one "key  label" line per next key. A key
+// that leads to more keys without a single binding behind it shows an ellipsis.
+func hotkeySequenceOverlayMessage(hints []utilhotkey.SequenceHint) string {
+	lines := make([]string, 0, len(hints))
+	for _, hint := range hints {
+		label := hint.Label
+		if hint.Partial {
+			label = strings.TrimSpace(label + " …")
+		}
+		lines = append(lines, hotkeySequenceKeyLabel(hint.Key)+"    "+label)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func hotkeySequenceLabel(steps []string) string {
+	labels := make([]string, 0, len(steps))
+	for _, step := range steps {
+		labels = append(labels, hotkeySequenceKeyLabel(step))
+	}
+	return utilhotkey.JoinSequence(labels)
+}
+
+func hotkeySequenceKeyLabel(key string) string {
+	parts := strings.Split(strings.TrimSpace(key), "+")
+	for index, part := range parts {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			part = strings.ToUpper(part[:1]) + part[1:]
+		}
+		parts[index] = part
+	}
+	return strings.Join(parts, "+")
+}
@@ -0,0 +1,21 @@
+package ui
+
+import "testing"
+
+func TestHotkeysConflictComparesSequencePrefixes(t *testing.T) {
+	cases := []struct {
+		left, right string
+		want        bool
+	}{
+		{"ctrl+space", "control+space", true},
+		{"ctrl+space", "ctrl+space, g", true},
+		{"ctrl+space, g", "ctrl+space, g, h", true},
+		{"ctrl+space, g, h", "ctrl+space, g, l", false},
+		{"ctrl+space, g", "alt+space, g", false},
+	}
+	for _, tc := range cases {
+		if got := hotkeysConflict(tc.left, tc.right); got != tc.want {
+			t.Fatalf("hotkeysConflict(%q, %q) = %t, want %t", tc.left, tc.right, got, tc.want)
+		}
+	}
+}
@@ -27,6 +27,9 @@ type aiSettingsSnapshot struct {
 	SkillsLoading    bool
 	SkillsLoaded     bool
 	SkillsError      string
+	MCPCatalog       chatMCPCatalog
+	MCPLoading       bool
+	MCPError         string
 	ModelManager     *modelManagerSnapshot
 }
 
@@ -64,6 +67,14 @@ type aiSettingsController struct {
 	skillsLoaded  bool
 	skillsError   string
 
+	// Shared MCP catalog: prompts and resources for the chat command palette and
+	// the server states shown in the MCP servers table. Cleared whenever core
+	// reloads MCP servers so the next access refetches.
+	mcpCatalog chatMCPCatalog
+	mcpLoading bool
+	mcpLoaded  bool
+	mcpError   string
+
 	// Modal model-manager overlay state. Owned here because it is opened from plugin
 	// settings and rendered in the settings window, but is a self-contained modal flow.
 	modelManager *modelManagerState
@@ -160,6 +171,13 @@ func (c *aiSettingsController) ResetSkills() {
 	c.skillsError = ""
 }
 
+// ResetMCPCatalog marks the shared MCP catalog as stale so the next access refetches it
+// from core. Called when core reports reloaded MCP servers.
+func (c *aiSettingsController) ResetMCPCatalog() {
+	c.mcpLoaded = false
+	c.mcpError = ""
+}
+
 // Models returns a copy of the shared model catalog. Callers that need indexed access
 // should use ModelAt instead so the slice is not mutated underneath them.
 func (c *aiSettingsController) Models() []aiModel {
@@ -281,6 +299,86 @@ func (c *aiSettingsController) SetSkillsError(msg string) {
 	c.skillsError = msg
 }
 
+// MCPCatalog returns a copy of the shared MCP catalog.
+func (c *aiSettingsController) MCPCatalog() chatMCPCatalog {
+	return c.mcpCatalog.clone()
+}
+
+// MCPPromptAt returns the prompt at the given index and false when the index is out of range.
+// Used by chat preview's insertChatMCPPrompt.
+func (c *aiSettingsController) MCPPromptAt(index int) (chatMCPPrompt, bool) {
+	if index < 0 || index >= len(c.mcpCatalog.Prompts) {
+		return chatMCPPrompt{}, false
+	}
+	return c.mcpCatalog.Prompts[index], true
+}
+
+// MCPResourceAt returns the resource at the given index and false when the index is out of range.
+// Used by chat preview's insertChatMCPResource.
+func (c *aiSettingsController) MCPResourceAt(index int) (chatMCPResource, bool) {
+	if index < 0 || index >= len(c.mcpCatalog.Resources) {
+		return chatMCPResource{}, false
+	}
+	return c.mcpCatalog.Resources[index], true
+}
+
+// MCPServerHealth returns the last reported state of one MCP server.
+func (c *aiSettingsController) MCPServerHealth(name string) (aiMCPServerHealth, bool) {
+	for _, server := range c.mcpCatalog.Servers {
+		if server.Name == name {
+			return server, true
+		}
+	}
+	return aiMCPServerHealth{}, false
+}
+
+// MCPLoading reports whether an MCP catalog load is in flight.
+func (c *aiSettingsController) MCPLoading() bool {
+	return c.mcpLoading
+}
+
+// MCPLoaded reports whether the MCP catalog has been loaded and is still considered fresh.
+func (c *aiSettingsController) MCPLoaded() bool {
+	return c.mcpLoaded
+}
+
+// SetMCPLoading records that an MCP catalog load is starting. Returns the previous
+// value so callers can detect a racing load.
+func (c *aiSettingsController) SetMCPLoading(loading bool) bool {
+	previous := c.mcpLoading
+	c.mcpLoading = loading
+	return previous
+}
+
+// LoadAIMCPCatalog fetches MCP prompts, resources, and server states from core, sorts
+// the prompts and resources by server/name, and stores them. onLoaded runs in the UI
+// apply transaction with the load error, if any.
+func (c *aiSettingsController) LoadAIMCPCatalog(ctx context.Context, service contract.AICatalogSettingsServices, sessionID string, onLoaded func(err error)) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	loaded, err := service.AIMCPCatalog(timeoutCtx, sessionID)
+	cancel()
+	catalog := chatMCPCatalogFromContract(loaded)
+	sort.SliceStable(catalog.Prompts, func(i, j int) bool {
+		return catalog.Prompts[i].ServerName+"\x00"+catalog.Prompts[i].Name < catalog.Prompts[j].ServerName+"\x00"+catalog.Prompts[j].Name
+	})
+	sort.SliceStable(catalog.Resources, func(i, j int) bool {
+		return catalog.Resources[i].ServerName+"\x00"+catalog.Resources[i].Name < catalog.Resources[j].ServerName+"\x00"+catalog.Resources[j].Name
+	})
+	c.deps.OnUI("apply AI MCP catalog", func() {
+		c.mcpLoading = false
+		c.mcpLoaded = true
+		if err == nil {
+			c.mcpCatalog = catalog
+			c.mcpError = ""
+		} else {
+			c.mcpError = err.Error()
+		}
+		if onLoaded != nil {
+			onLoaded(err)
+		}
+	})
+}
+
 // LoadAIModels fetches the core model catalog, sorts it, and stores it through SetModels.
 // onLoaded is invoked on success so the App can refresh the requirement/plugin/table
 // row forms that consume selectAIModel options and reset the chat-preview panel selection.
@@ -392,6 +490,9 @@ func (c *aiSettingsController) Snapshot() aiSettingsSnapshot {
 		SkillsLoading:    c.skillsLoading,
 		SkillsLoaded:     c.skillsLoaded,
 		SkillsError:      c.skillsError,
+		MCPCatalog:       c.mcpCatalog.clone(),
+		MCPLoading:       c.mcpLoading,
+		MCPError:         c.mcpError,
 		ModelManager:     snapshotModelManagerLocked(c.modelManager),
 	}
 }
@@ -12,6 +12,7 @@ type aiFakeService struct {
 	providers []contract.AIProvider
 	models    []contract.AIModel
 	skills    []contract.AISkill
+	mcp       contract.AIMCPCatalog
 	err       error
 }
 
@@ -27,6 +28,10 @@ func (f *aiFakeService) AISkills(_ context.Context, _ string) ([]contract.AISkil
 	return append([]contract.AISkill(nil), f.skills...), f.err
 }
 
+func (f *aiFakeService) AIMCPCatalog(_ context.Context, _ string) (contract.AIMCPCatalog, error) {
+	return f.mcp, f.err
+}
+
 func newAIDeps() (CommonDeps, *int) {
 	calls := 0
 	deps := CommonDeps{
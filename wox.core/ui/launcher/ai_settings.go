@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"wox/common"
 	launcherview "wox/ui/launcher/view"
 	woxui "wox/ui/runtime"
 	woxwidget "wox/ui/widget"
@@ -108,6 +109,7 @@ func newAISettingsForm(data settingsData) formFieldsState {
 					{Key: "Alias", Label: "i18n:ui_ai_providers_alias", Tooltip: "i18n:ui_ai_providers_alias_tooltip", Width: 120, Type: "text"},
 					{Key: "Host", Label: "i18n:ui_ai_providers_host", Tooltip: "i18n:ui_ai_providers_host_tooltip", Width: 160, Type: "text"},
 					{Key: "ApiKey", Label: "i18n:ui_ai_providers_api_key", Tooltip: "i18n:ui_ai_providers_api_key_tooltip", Type: "text"},
+					{Key: "EmbeddingModel", Label: "i18n:ui_ai_providers_embedding_model", Tooltip: "i18n:ui_ai_providers_embedding_model_tooltip", Width: 140, Type: "text"},
 				},
 			},
 		},
@@ -116,13 +118,40 @@ func newAISettingsForm(data settingsData) formFieldsState {
 			Value: formDefinitionValue{
 				Key: "AIMCPServers", Title: "i18n:ui_ai_mcp_servers", Tooltip: "i18n:ui_ai_mcp_servers_tooltip", SortColumnKey: "Name", InlineTable: true,
 				Columns: []formTableColumn{
+					{Key: "Status", Label: "i18n:plugin_ai_chat_mcp_server_status", Tooltip: "i18n:plugin_ai_chat_mcp_server_status_tooltip", Width: 40, Type: "aiMCPServerStatus", HideInUpdate: true},
 					{Key: "Name", Label: "i18n:plugin_ai_chat_mcp_server_name", Tooltip: "i18n:plugin_ai_chat_mcp_server_name_tooltip", Width: 100, Type: "text", Validators: []formValidator{{Type: "not_empty"}}},
 					{Key: "Tools", Label: "i18n:plugin_ai_chat_mcp_server_tools", Tooltip: "i18n:plugin_ai_chat_mcp_server_tools_tooltip", Width: 50, Type: "aiMCPServerTools", HideInUpdate: true},
 					{Key: "Disabled", Label: "i18n:plugin_ai_chat_mcp_server_disabled", Width: 80, Type: "checkbox"},
-					{Key: "Type", Label: "i18n:plugin_ai_chat_mcp_server_type", Tooltip: "i18n:plugin_ai_chat_mcp_server_type_tooltip", Width: 80, Type: "select", SelectOptions: []formOption{{Label: "STDIO", Value: "stdio"}, {Label: "Streamable HTTP", Value: "streamable-http"}}, Validators: []formValidator{{Type: "not_empty"}}},
+					{Key: "Type", Label: "i18n:plugin_ai_chat_mcp_server_type", Tooltip: "i18n:plugin_ai_chat_mcp_server_type_tooltip", Width: 80, Type: "select", SelectOptions: []formOption{{Label: "STDIO", Value: "stdio"}, {Label: "Streamable HTTP", Value: "streamable-http"}, {Label: "SSE", Value: "sse"}}, Validators: []formValidator{{Type: "not_empty"}}},
 					{Key: "Command", Label: "i18n:plugin_ai_chat_mcp_server_command", Tooltip: "i18n:plugin_ai_chat_mcp_server_command_tooltip", Width: 100, Type: "text"},
 					{Key: "EnvironmentVariables", Label: "i18n:plugin_ai_chat_mcp_server_environment_variables", Tooltip: "i18n:plugin_ai_chat_mcp_server_environment_variables_tooltip", Width: 160, Type: "textList", TextMaxLines: 6},
 					{Key: "Url", Label: "i18n:plugin_ai_chat_mcp_server_url", Tooltip: "i18n:plugin_ai_chat_mcp_server_url_tooltip", Width: 120, Type: "text", TextMaxLines: 10},
+					{Key: "Headers", Label: "i18n:plugin_ai_chat_mcp_server_headers", Tooltip: "i18n:plugin_ai_chat_mcp_server_headers_tooltip", Width: 160, Type: "textList", TextMaxLines: 6},
+					{Key: "BearerToken", Label: "i18n:plugin_ai_chat_mcp_server_bearer_token", Tooltip: "i18n:plugin_ai_chat_mcp_server_bearer_token_tooltip", Width: 120, Type: "text"},
+					{Key: "ApprovalPolicy", Label: "i18n:plugin_ai_chat_mcp_server_approval_policy", Tooltip: "i18n:plugin_ai_chat_mcp_server_approval_policy_tooltip", Width: 100, Type: "select", SelectOptions: aiToolApprovalPolicyOptions()},
+				},
+			},
+		},
+		{
+			Type: "table",
+			Value: formDefinitionValue{
+				Key: "AIToolApprovalRules", Title: "i18n:ui_ai_tool_approval_rules", Tooltip: "i18n:ui_ai_tool_approval_rules_tooltip", SortColumnKey: "Tool", InlineTable: true,
+				Columns: []formTableColumn{
+					{Key: "Tool", Label: "i18n:ui_ai_tool_approval_tool", Tooltip: "i18n:ui_ai_tool_approval_tool_tooltip", Width: 120, Type: "text", Validators: []formValidator{{Type: "not_empty"}}},
+					{Key: "Policy", Label: "i18n:ui_ai_tool_approval_policy", Width: 100, Type: "select", SelectOptions: aiToolApprovalPolicyOptions(), Validators: []formValidator{{Type: "not_empty"}}},
+					{Key: "CommandPrefixes", Label: "i18n:ui_ai_tool_approval_command_prefixes", Tooltip: "i18n:ui_ai_tool_approval_command_prefixes_tooltip", Width: 180, Type: "textList", TextMaxLines: 6},
+					{Key: "PathRoots", Label: "i18n:ui_ai_tool_approval_path_roots", Tooltip: "i18n:ui_ai_tool_approval_path_roots_tooltip", Width: 180, Type: "textList", TextMaxLines: 6},
+				},
+			},
+		},
+		{
+			Type: "table",
+			Value: formDefinitionValue{
+				Key: "MCPServerClients", Title: "i18n:ui_mcp_server_clients", Tooltip: "i18n:ui_mcp_server_clients_tooltip", SortColumnKey: "Name", InlineTable: true,
+				Columns: []formTableColumn{
+					{Key: "Name", Label: "i18n:ui_mcp_server_client_name", Tooltip: "i18n:ui_mcp_server_client_name_tooltip", Width: 120, Type: "text", Validators: []formValidator{{Type: "not_empty"}}},
+					{Key: "Token", Label: "i18n:ui_mcp_server_client_token", Tooltip: "i18n:ui_mcp_server_client_token_tooltip", Width: 320, Type: "text"},
+					{Key: "Disabled", Label: "i18n:ui_mcp_server_client_disabled", Width: 80, Type: "checkbox"},
 				},
 			},
 		},
@@ -145,13 +174,24 @@ func newAISettingsForm(data settingsData) formFieldsState {
 		},
 	}
 	values := map[string]string{
-		"AIProviders":  settingsJSONArray(data.AIProviders),
-		"AIMCPServers": settingsJSONArray(data.AIMCPServers),
-		"AISkills":     settingsJSONArray(data.AISkills),
+		"AIProviders":         settingsJSONArray(data.AIProviders),
+		"AIMCPServers":        settingsJSONArray(data.AIMCPServers),
+		"AIToolApprovalRules": settingsJSONArray(data.AIToolApprovalRules),
+		"MCPServerClients":    settingsJSONArray(data.MCPServerClients),
+		"AISkills":            settingsJSONArray(data.AISkills),
 	}
 	return newFormFieldsState(definitions, values, true)
 }
 
+// aiToolApprovalPolicyOptions lists the policies shared by MCP servers and tool rules.
+func aiToolApprovalPolicyOptions() []formOption {
+	return []formOption{
+		{Label: "i18n:ui_ai_tool_approval_policy_allow", Value: string(common.AIToolApprovalPolicyAllow)},
+		{Label: "i18n:ui_ai_tool_approval_policy_ask", Value: string(common.AIToolApprovalPolicyAsk)},
+		{Label: "i18n:ui_ai_tool_approval_policy_deny", Value: string(common.AIToolApprovalPolicyDeny)},
+	}
+}
+
 func settingsJSONArray(value json.RawMessage) string {
 	trimmed := strings.TrimSpace(string(value))
 	if trimmed == "" || trimmed == "null" {
@@ -307,13 +347,20 @@ func (a *App) openAISettingsTable(index int) {
 // skills table uses Flutter's tabbed add dialog instead of the generic row editor.
 func (a *App) addAISettingsTableRow(index int) {
 	a.openAISettingsTable(index)
-	if index < 2 {
+	if !a.isAISkillsSettingsTable(index) {
 		a.beginAddFormTableRowDirect()
 		return
 	}
 	a.openFormTableSkillAdd()
 }
 
+// isAISkillsSettingsTable reports whether index is the skills table, which has
+// its own add flow and read-only rows.
+func (a *App) isAISkillsSettingsTable(index int) bool {
+	form := a.aiSettings.Form()
+	return form != nil && index >= 0 && index < len(form.definitions) && form.definitions[index].Value.Key == "AISkills"
+}
+
 // openAISettingsTableRow carries the inline row selection into the shared table editor.
 func (a *App) openAISettingsTableRow(tableIndex, rowIndex int) {
 	form := a.aiSettings.Form()
@@ -325,7 +372,7 @@ func (a *App) openAISettingsTableRow(tableIndex, rowIndex int) {
 		}
 	}
 	a.finishOpeningFormTable()
-	if tableIndex < 2 {
+	if !a.isAISkillsSettingsTable(tableIndex) {
 		a.beginEditFormTableRowDirect()
 	}
 }
@@ -343,15 +390,38 @@ func validateAISettingsTableRow(definition formDefinition, fields *formFieldsSta
 			if strings.TrimSpace(fields.values["Command"]) == "" {
 				return map[string]string{"Command": "Command is required for a STDIO server."}
 			}
-		case "streamable-http":
+		case "streamable-http", "sse":
 			if strings.TrimSpace(fields.values["Url"]) == "" {
-				return map[string]string{"Url": "URL is required for a Streamable HTTP server."}
+				return map[string]string{"Url": "URL is required for an HTTP server."}
+			}
+			if invalid := invalidMCPHeader(fields.values["Headers"]); invalid != "" {
+				return map[string]string{"Headers": "Header must be \"Key: Value\": " + invalid}
 			}
 		}
+	case "MCPServerClients":
+		// An empty token is generated by core on save.
+		if token := strings.TrimSpace(fields.values["Token"]); token != "" && len(token) < 16 {
+			return map[string]string{"Token": "Token must be at least 16 characters, or empty to generate one."}
+		}
 	}
 	return nil
 }
 
+// invalidMCPHeader returns the first line of the Headers editor that is not "Key: Value".
+func invalidMCPHeader(value string) string {
+	for _, header := range strings.Split(strings.ReplaceAll(value, "\r\n", "\n"), "\n") {
+		header = strings.TrimSpace(header)
+		if header == "" {
+			continue
+		}
+		key, _, found := strings.Cut(header, ":")
+		if key = strings.TrimSpace(key); !found || key == "" || strings.ContainsAny(key, " \t") {
+			return header
+		}
+	}
+	return ""
+}
+
 // saveSettingsTable persists one settings-owned table and rolls the editor back if core rejects it.
 func (a *App) saveSettingsTable(state *formTableEditorState, key, value, previousValue string) {
 	coreValue := value
@@ -411,6 +481,18 @@ func (a *App) applyAISettingsRawLocked(key, value string) {
 		a.aiSettings.ResetModels()
 	case "AIMCPServers":
 		a.generalSettings.Update(func(d *settingsData) { d.AIMCPServers = raw })
+		// Core reconnects the servers and reports "tools" when done, which reloads their state.
+		a.aiSettings.ResetMCPCatalog()
+	case "AIToolApprovalRules":
+		a.generalSettings.Update(func(d *settingsData) { d.AIToolApprovalRules = raw })
+	case "MCPServerClients":
+		a.generalSettings.Update(func(d *settingsData) { d.MCPServerClients = raw })
+		// Core fills in tokens left empty, reload so the new tokens can be copied.
+		util.Go(a.lifecycleCtx, "reload settings after MCP client change", func() {
+			if err := a.reloadSettings(); err != nil {
+				log.Printf("reload settings after MCP client change: %v", err)
+			}
+		})
 	case "AISkills":
 		a.generalSettings.Update(func(d *settingsData) { d.AISkills = raw })
 		a.aiSettings.ResetSkills()
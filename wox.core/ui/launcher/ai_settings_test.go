@@ -9,8 +9,8 @@ import (
 
 func TestNewAISettingsFormMatchesFlutterTableDefinitions(t *testing.T) {
 	form := newAISettingsForm(settingsData{})
-	if len(form.definitions) != 3 {
-		t.Fatalf("AI table count = %d, want 3", len(form.definitions))
+	if len(form.definitions) != 5 {
+		t.Fatalf("AI table count = %d, want 5", len(form.definitions))
 	}
 
 	providers := form.definitions[0].Value
@@ -26,9 +26,21 @@ func TestNewAISettingsFormMatchesFlutterTableDefinitions(t *testing.T) {
 	if !mcp.InlineTable || mcp.SortColumnKey != "Name" {
 		t.Fatalf("MCP table options = inline %v, sort %q; want inline and Name", mcp.InlineTable, mcp.SortColumnKey)
 	}
-	assertFormTableColumnWidths(t, mcp.Columns, []int{100, 50, 80, 80, 100, 160, 120})
+	assertFormTableColumnWidths(t, mcp.Columns, []int{100, 50, 80, 80, 100, 160, 120, 100})
 
-	skills := form.definitions[2].Value
+	approval := form.definitions[2].Value
+	if approval.Key != "AIToolApprovalRules" || !approval.InlineTable || approval.SortColumnKey != "Tool" {
+		t.Fatalf("tool approval table options = key %q, inline %v, sort %q; want AIToolApprovalRules, inline and Tool", approval.Key, approval.InlineTable, approval.SortColumnKey)
+	}
+	assertFormTableColumnWidths(t, approval.Columns, []int{120, 100, 180, 180})
+
+	clients := form.definitions[3].Value
+	if clients.Key != "MCPServerClients" || !clients.InlineTable || clients.SortColumnKey != "Name" {
+		t.Fatalf("MCP client table options = key %q, inline %v, sort %q; want MCPServerClients, inline and Name", clients.Key, clients.InlineTable, clients.SortColumnKey)
+	}
+	assertFormTableColumnWidths(t, clients.Columns, []int{120, 320, 80})
+
+	skills := form.definitions[4].Value
 	if !skills.InlineTable || skills.SortColumnKey != "Name" || skills.MaxHeight != 360 {
 		t.Fatalf("skills table options = inline %v, sort %q, max height %d; want inline, Name, 360", skills.InlineTable, skills.SortColumnKey, skills.MaxHeight)
 	}
@@ -15,6 +15,7 @@ import (
 	"unicode"
 
 	"wox/common"
+	"wox/ui/contract"
 	woxui "wox/ui/runtime"
 	"wox/util"
 )
@@ -78,6 +79,68 @@ type chatSkill struct {
 	Enabled      bool   `json:"Enabled"`
 }
 
+type chatMCPPrompt struct {
+	ServerName  string
+	Name        string
+	Description string
+	Arguments   []contract.AIMCPPromptArgument
+}
+
+type chatMCPResource struct {
+	ServerName  string
+	URI         string
+	Name        string
+	Description string
+}
+
+type aiMCPServerHealth struct {
+	Name          string
+	State         string
+	Error         string
+	ToolCount     int
+	ResourceCount int
+	PromptCount   int
+}
+
+// chatMCPCatalog holds what MCP servers offer to the chat composer besides tools.
+type chatMCPCatalog struct {
+	Prompts   []chatMCPPrompt
+	Resources []chatMCPResource
+	Servers   []aiMCPServerHealth
+}
+
+func (c chatMCPCatalog) clone() chatMCPCatalog {
+	return chatMCPCatalog{
+		Prompts:   append([]chatMCPPrompt(nil), c.Prompts...),
+		Resources: append([]chatMCPResource(nil), c.Resources...),
+		Servers:   append([]aiMCPServerHealth(nil), c.Servers...),
+	}
+}
+
+func chatMCPCatalogFromContract(source contract.AIMCPCatalog) chatMCPCatalog {
+	catalog := chatMCPCatalog{
+		Prompts:   make([]chatMCPPrompt, len(source.Prompts)),
+		Resources: make([]chatMCPResource, len(source.Resources)),
+		Servers:   make([]aiMCPServerHealth, len(source.Servers)),
+	}
+	for index, prompt := range source.Prompts {
+		catalog.Prompts[index] = chatMCPPrompt{
+			ServerName: prompt.ServerName, Name: prompt.Name, Description: prompt.Description,
+			Arguments: append([]contract.AIMCPPromptArgument(nil), prompt.Arguments...),
+		}
+	}
+	for index, resource := range source.Resources {
+		catalog.Resources[index] = chatMCPResource{ServerName: resource.ServerName, URI: resource.URI, Name: resource.Name, Description: resource.Description}
+	}
+	for index, server := range source.Servers {
+		catalog.Servers[index] = aiMCPServerHealth{
+			Name: server.Name, State: server.State, Error: server.Error,
+			ToolCount: server.ToolCount, ResourceCount: server.ResourceCount, PromptCount: server.PromptCount,
+		}
+	}
+	return catalog
+}
+
 type chatToolCallInfo struct {
 	ID             string         `json:"Id"`
 	Name           string         `json:"Name"`
@@ -172,6 +235,8 @@ type chatPreviewSnapshot struct {
 	skills           []chatSkill
 	skillsLoading    bool
 	skillsError      string
+	mcp              chatMCPCatalog
+	mcpLoading       bool
 	panel            string
 	panelQuery       string
 	panelSelected    int
@@ -216,10 +281,11 @@ func findChatSlashToken(editing woxui.TextEditingState) (chatSlashToken, bool) {
 	return chatSlashToken{start: start, end: end, query: strings.TrimSpace(string(runes[start+1 : end]))}, true
 }
 
-// chatCommandPaletteItems mirrors Flutter's case-insensitive model and skill filtering.
-func chatCommandPaletteItems(models []aiModel, skills []chatSkill, current aiModel, query, filterGroup string) []chatCommandPaletteItem {
+// chatCommandPaletteItems mirrors Flutter's case-insensitive model and skill filtering,
+// followed by MCP prompts and resources.
+func chatCommandPaletteItems(models []aiModel, skills []chatSkill, mcp chatMCPCatalog, current aiModel, query, filterGroup string) []chatCommandPaletteItem {
 	query = strings.ToLower(strings.TrimSpace(query))
-	items := make([]chatCommandPaletteItem, 0, len(models)+len(skills))
+	items := make([]chatCommandPaletteItem, 0, len(models)+len(skills)+len(mcp.Prompts)+len(mcp.Resources))
 	if filterGroup == "" || filterGroup == chatCommandPanel || filterGroup == "models" {
 		for index, model := range models {
 			subtitle := model.Provider
@@ -252,6 +318,30 @@ func chatCommandPaletteItems(models []aiModel, skills []chatSkill, current aiMod
 			}
 		}
 	}
+	if filterGroup == "" || filterGroup == chatCommandPanel {
+		for index, prompt := range mcp.Prompts {
+			subtitle := prompt.ServerName
+			if prompt.Description != "" {
+				subtitle += " · " + prompt.Description
+			}
+			item := chatCommandPaletteItem{group: "prompts", sourceIndex: index, title: prompt.Name, subtitle: subtitle}
+			item.searchText = "prompt mcp 提示词 " + prompt.Name + " " + prompt.ServerName + " " + prompt.Description
+			if query == "" || strings.Contains(strings.ToLower(item.searchText), query) {
+				items = append(items, item)
+			}
+		}
+		for index, resource := range mcp.Resources {
+			title := resource.Name
+			if title == "" {
+				title = resource.URI
+			}
+			item := chatCommandPaletteItem{group: "resources", sourceIndex: index, title: title, subtitle: resource.ServerName + " · " + resource.URI}
+			item.searchText = "resource mcp 资源 " + resource.Name + " " + resource.ServerName + " " + resource.URI + " " + resource.Description
+			if query == "" || strings.Contains(strings.ToLower(item.searchText), query) {
+				items = append(items, item)
+			}
+		}
+	}
 	return items
 }
 
@@ -465,6 +555,8 @@ func (a *App) chatPreviewSnapshotFor(result queryResult, preview queryPreview) (
 	snapshot.skills = a.aiSettings.Skills()
 	snapshot.skillsLoading = a.aiSettings.SkillsLoading()
 	snapshot.skillsError = a.aiSettings.SkillsError()
+	snapshot.mcp = a.aiSettings.MCPCatalog()
+	snapshot.mcpLoading = a.aiSettings.MCPLoading()
 	return snapshot, nil
 }
 
@@ -541,6 +633,7 @@ func (a *App) toggleChatDisclosure(disclosureID string) {
 func (a *App) toggleChatPanel(panel string) {
 	requestModels := false
 	requestSkills := false
+	requestMCP := false
 	editorActive := false
 	state := a.chatPreview
 	if state == nil {
@@ -585,6 +678,9 @@ func (a *App) toggleChatPanel(panel string) {
 				a.aiSettings.SetSkillsLoading(true)
 			}
 		}
+		if panel == chatCommandPanel {
+			requestMCP = !a.aiSettings.MCPLoaded() && !a.aiSettings.SetMCPLoading(true)
+		}
 	}
 	state.active = true
 	editorActive = state.panel == ""
@@ -594,17 +690,21 @@ func (a *App) toggleChatPanel(panel string) {
 	if requestSkills {
 		util.Go(a.lifecycleCtx, "load AI skills for chat", a.loadAISkills)
 	}
+	if requestMCP {
+		util.Go(a.lifecycleCtx, "load AI MCP catalog for chat", a.loadAIMCPCatalog)
+	}
 	a.updateChatTextInput(editorActive)
 	_ = a.window.Invalidate()
 }
 
 // reloadChatResource invalidates only catalogs affected by a core resource notification.
 func (a *App) reloadChatResourceName(resource string) {
-	if resource != "models" && resource != "skills" && resource != "all" {
+	if resource != "models" && resource != "skills" && resource != "tools" && resource != "all" {
 		return
 	}
 	requestModels := false
 	requestSkills := false
+	requestMCP := false
 	if resource == "models" || resource == "all" {
 		a.aiSettings.ResetModels()
 		if state := a.chatPreview; state != nil && (state.panel == "models" || state.panel == chatCommandPanel) && !a.aiSettings.ModelsLoading() {
@@ -619,12 +719,26 @@ func (a *App) reloadChatResourceName(resource string) {
 			requestSkills = true
 		}
 	}
+	// Core sends "tools" after reloading MCP servers, which also refreshes their
+	// prompts, resources, and health.
+	if resource == "tools" || resource == "all" {
+		a.aiSettings.ResetMCPCatalog()
+		commandPanelOpen := a.chatPreview != nil && a.chatPreview.panel == chatCommandPanel
+		aiSettingsVisible := a.aiSettings.Form() != nil && a.aiSettings.Form().active
+		if (commandPanelOpen || aiSettingsVisible) && !a.aiSettings.MCPLoading() {
+			a.aiSettings.SetMCPLoading(true)
+			requestMCP = true
+		}
+	}
 	if requestModels {
 		util.Go(a.lifecycleCtx, "reload AI models for chat", a.loadAIModels)
 	}
 	if requestSkills {
 		util.Go(a.lifecycleCtx, "reload AI skills for chat", a.loadAISkills)
 	}
+	if requestMCP {
+		util.Go(a.lifecycleCtx, "reload AI MCP catalog", a.loadAIMCPCatalog)
+	}
 }
 
 // loadAISkills shares the enabled skill catalog with chat composition.
@@ -644,6 +758,20 @@ func (a *App) loadAISkills() {
 	})
 }
 
+// loadAIMCPCatalog shares MCP prompts and resources with chat composition and server
+// states with the MCP servers table.
+func (a *App) loadAIMCPCatalog() {
+	a.aiSettings.LoadAIMCPCatalog(context.Background(), a.services, a.sessionID, func(err error) {
+		if err != nil {
+			log.Printf("load AI MCP catalog: %v", err)
+		}
+		if a.aiSettings.Form() != nil && a.aiSettings.Form().active {
+			a.invalidateSettingsWindow()
+		}
+		_ = a.window.Invalidate()
+	})
+}
+
 // startNewChat resets the active draft while retaining the user's current model choice.
 func (a *App) startNewChat() {
 	questionID := ""
@@ -881,6 +1009,66 @@ func (a *App) insertChatSkill(index int) {
 	_ = a.window.Invalidate()
 }
 
+// insertChatMCPPrompt adds a {prompt:server:name} tag that core renders through the MCP server.
+// Declared arguments get empty values and the caret moves into the first one.
+func (a *App) insertChatMCPPrompt(index int) {
+	prompt, ok := a.aiSettings.MCPPromptAt(index)
+	if !ok {
+		return
+	}
+	tag, caretOffset := chatMCPPromptTag(prompt)
+	a.insertChatMCPTag(index, tag, caretOffset)
+}
+
+// insertChatMCPResource adds a {resource:server:uri} tag whose content core attaches on send.
+func (a *App) insertChatMCPResource(index int) {
+	resource, ok := a.aiSettings.MCPResourceAt(index)
+	if !ok {
+		return
+	}
+	tag := "{resource:" + resource.ServerName + ":" + resource.URI + "}"
+	a.insertChatMCPTag(index, tag, len([]rune(tag)))
+}
+
+// insertChatMCPTag replaces the slash token with tag and puts the caret caretOffset runes into it.
+func (a *App) insertChatMCPTag(index int, tag string, caretOffset int) {
+	state := a.chatPreview
+	if state == nil || state.editor == nil {
+		return
+	}
+	replaceChatSlashToken(state.editor, tag)
+	if tagLength := len([]rune(tag)); caretOffset < tagLength {
+		state.editor.SetCaret(state.editor.State().Selection.Focus - tagLength + caretOffset)
+	}
+	state.panelSelected = index
+	state.panel = ""
+	state.panelQuery = ""
+	state.error = ""
+	state.active = true
+	a.updateChatTextInput(true)
+	_ = a.window.Invalidate()
+}
+
+// chatMCPPromptTag builds the prompt tag and the rune offset of the first argument value.
+func chatMCPPromptTag(prompt chatMCPPrompt) (string, int) {
+	var builder strings.Builder
+	builder.WriteString("{prompt:" + prompt.ServerName + ":" + prompt.Name)
+	caretOffset := -1
+	for _, argument := range prompt.Arguments {
+		builder.WriteString(" " + argument.Name + `="`)
+		if caretOffset < 0 {
+			caretOffset = len([]rune(builder.String()))
+		}
+		builder.WriteString(`"`)
+	}
+	builder.WriteString("}")
+	tag := builder.String()
+	if caretOffset < 0 {
+		caretOffset = len([]rune(tag))
+	}
+	return tag, caretOffset
+}
+
 // replaceChatSlashToken replaces the active token while preserving surrounding message text.
 func replaceChatSlashToken(editor *woxui.TextEditor, replacement string) {
 	if editor == nil {
@@ -972,7 +1160,7 @@ func (a *App) moveChatPanelSelection(delta int) {
 	count := len(state.chats)
 	var commands []chatCommandPaletteItem
 	if state.panel != "history" {
-		commands = chatCommandPaletteItems(a.aiSettings.Models(), a.aiSettings.Skills(), state.chat.Model, state.panelQuery, state.panel)
+		commands = chatCommandPaletteItems(a.aiSettings.Models(), a.aiSettings.Skills(), a.aiSettings.MCPCatalog(), state.chat.Model, state.panelQuery, state.panel)
 		count = len(commands)
 	}
 	if count > 0 {
@@ -1001,15 +1189,25 @@ func (a *App) activateChatPanelSelection() {
 	if panel == "history" {
 		a.selectChatHistory(chatID)
 	} else {
-		items := chatCommandPaletteItems(a.aiSettings.Models(), a.aiSettings.Skills(), state.chat.Model, state.panelQuery, panel)
+		items := chatCommandPaletteItems(a.aiSettings.Models(), a.aiSettings.Skills(), a.aiSettings.MCPCatalog(), state.chat.Model, state.panelQuery, panel)
 		if selected < 0 || selected >= len(items) {
 			return
 		}
-		if items[selected].group == "models" {
-			a.selectChatModel(items[selected].sourceIndex)
-		} else {
-			a.insertChatSkill(items[selected].sourceIndex)
-		}
+		a.selectChatCommand(items[selected])
+	}
+}
+
+// selectChatCommand applies one command palette row according to its group.
+func (a *App) selectChatCommand(item chatCommandPaletteItem) {
+	switch item.group {
+	case "models":
+		a.selectChatModel(item.sourceIndex)
+	case "prompts":
+		a.insertChatMCPPrompt(item.sourceIndex)
+	case "resources":
+		a.insertChatMCPResource(item.sourceIndex)
+	default:
+		a.insertChatSkill(item.sourceIndex)
 	}
 }
 
@@ -1021,7 +1219,7 @@ func (a *App) setChatPanelViewport(height float32) {
 		count := len(state.chats)
 		commands := []chatCommandPaletteItem(nil)
 		if state.panel != "history" {
-			commands = chatCommandPaletteItems(a.aiSettings.Models(), a.aiSettings.Skills(), state.chat.Model, state.panelQuery, state.panel)
+			commands = chatCommandPaletteItems(a.aiSettings.Models(), a.aiSettings.Skills(), a.aiSettings.MCPCatalog(), state.chat.Model, state.panelQuery, state.panel)
 			count = len(commands)
 		}
 		if initialize {
@@ -1083,7 +1281,7 @@ func (a *App) scrollChatPanel(delta float32) {
 	}
 	contentHeight := chatHistoryContentHeight(state.chats, time.Now())
 	if state.panel != "history" {
-		items := chatCommandPaletteItems(a.aiSettings.Models(), a.aiSettings.Skills(), state.chat.Model, state.panelQuery, state.panel)
+		items := chatCommandPaletteItems(a.aiSettings.Models(), a.aiSettings.Skills(), a.aiSettings.MCPCatalog(), state.chat.Model, state.panelQuery, state.panel)
 		contentHeight = chatCommandContentHeight(items)
 	}
 	maxOffset := max(float32(0), contentHeight-state.panelViewport)
@@ -1614,6 +1812,7 @@ func (a *App) focusAIQuestionInput() {
 func (a *App) setChatText(value string) {
 	requestModels := false
 	requestSkills := false
+	requestMCP := false
 	if state := a.chatPreview; state != nil && state.editor != nil && state.question == nil {
 		state.editor.SetText(value, false)
 		state.error = ""
@@ -1636,6 +1835,7 @@ func (a *App) setChatText(value string) {
 			if requestSkills {
 				a.aiSettings.SetSkillsLoading(true)
 			}
+			requestMCP = !a.aiSettings.MCPLoaded() && !a.aiSettings.SetMCPLoading(true)
 		} else if state.panel == chatCommandPanel {
 			state.panel = ""
 			state.panelQuery = ""
@@ -1647,6 +1847,9 @@ func (a *App) setChatText(value string) {
 	if requestSkills {
 		util.Go(a.lifecycleCtx, "load AI skills for chat", a.loadAISkills)
 	}
+	if requestMCP {
+		util.Go(a.lifecycleCtx, "load AI MCP catalog for chat", a.loadAIMCPCatalog)
+	}
 	_ = a.window.Invalidate()
 }
 
@@ -38,7 +38,11 @@ func (a *App) buildChatPreview(result queryResult, preview queryPreview, palette
 	}
 	innerWidth := max(float32(0), contentWidth-20)
 	innerHeight := max(float32(0), height-14)
-	questionHeight := chatQuestionPanelHeight(snapshot, innerHeight)
+	var questionLayout woxwidget.TextBlockLayout
+	if snapshot.question != nil {
+		questionLayout = woxwidget.LayoutTextBlock(a.window, snapshot.question.Question, previewview.ChatQuestionTextStyle, max(float32(0), innerWidth-previewview.ChatQuestionTextHorizontalPadding), 0, previewview.ChatQuestionLineHeight)
+	}
+	questionHeight := chatQuestionPanelHeight(snapshot, questionLayout.Size.Height, innerHeight)
 	debugHeight := float32(0)
 	if snapshot.panel == "debug" {
 		debugHeight = chatCatalogPanelHeight(snapshot, innerHeight-questionHeight)
@@ -52,7 +56,7 @@ func (a *App) buildChatPreview(result queryResult, preview queryPreview, palette
 	}
 	var question *previewview.ChatQuestionProps
 	if questionHeight > 0 {
-		props := a.chatQuestionProps(snapshot, palette, innerWidth, questionHeight)
+		props := a.chatQuestionProps(snapshot, palette, innerWidth, questionHeight, questionLayout)
 		question = &props
 	}
 	var catalog *previewview.ChatCatalogProps
@@ -117,7 +121,7 @@ func chatCatalogPanelHeight(snapshot *chatPreviewSnapshot, available float32) fl
 		return 0
 	}
 	if snapshot.panel == "models" || snapshot.panel == "skills" || snapshot.panel == chatCommandPanel {
-		items := chatCommandPaletteItems(snapshot.models, snapshot.skills, snapshot.chat.Model, snapshot.panelQuery, snapshot.panel)
+		items := chatCommandPaletteItems(snapshot.models, snapshot.skills, snapshot.mcp, snapshot.chat.Model, snapshot.panelQuery, snapshot.panel)
 		contentHeight := float32(len(items)) * chatCatalogRowHeight
 		if snapshot.panel == chatCommandPanel {
 			contentHeight = chatCommandContentHeight(items)
@@ -144,7 +148,7 @@ func (a *App) chatCatalogProps(snapshot *chatPreviewSnapshot, palette uiPalette,
 	label := a.translate("i18n:ui_ai_chat_new_chat")
 	commands := []chatCommandPaletteItem(nil)
 	if snapshot.panel != "history" {
-		commands = chatCommandPaletteItems(snapshot.models, snapshot.skills, snapshot.chat.Model, snapshot.panelQuery, snapshot.panel)
+		commands = chatCommandPaletteItems(snapshot.models, snapshot.skills, snapshot.mcp, snapshot.chat.Model, snapshot.panelQuery, snapshot.panel)
 		count = len(commands)
 	}
 	if snapshot.panel == "models" {
@@ -180,22 +184,21 @@ func (a *App) chatCatalogProps(snapshot *chatPreviewSnapshot, palette uiPalette,
 	for index, command := range commands {
 		groupLabel := ""
 		if grouped {
-			if command.group == "models" {
+			switch command.group {
+			case "models":
 				groupLabel = a.translate("i18n:ui_ai_chat_select_model_title")
-			} else {
+			case "prompts":
+				groupLabel = a.translate("i18n:ui_ai_mcp_prompts")
+			case "resources":
+				groupLabel = a.translate("i18n:ui_ai_mcp_resources")
+			default:
 				groupLabel = a.translate("i18n:ui_ai_skills")
 			}
 		}
 		items = append(items, previewview.ChatCatalogItemProps{
 			SelectID: fmt.Sprintf("chat-%s-row-%s-%d", command.group, snapshot.key, index), GroupLabel: groupLabel,
 			Kind: command.group, Title: command.title, Subtitle: command.subtitle, Selected: index == snapshot.panelSelected, Current: command.current,
-			OnSelect: func() {
-				if command.group == "models" {
-					a.selectChatModel(command.sourceIndex)
-				} else {
-					a.insertChatSkill(command.sourceIndex)
-				}
-			},
+			OnSelect: func() { a.selectChatCommand(command) },
 		})
 	}
 	emptyMessage := "No saved conversations"
@@ -215,7 +218,7 @@ func (a *App) chatCatalogProps(snapshot *chatPreviewSnapshot, palette uiPalette,
 		}
 	} else if grouped {
 		emptyMessage = a.translate("i18n:ui_no_data")
-		if snapshot.modelsLoading || snapshot.skillsLoading {
+		if snapshot.modelsLoading || snapshot.skillsLoading || snapshot.mcpLoading {
 			emptyMessage = "Loading…"
 		} else if snapshot.modelsError != "" && snapshot.skillsError != "" {
 			emptyMessage = snapshot.modelsError + "; " + snapshot.skillsError
@@ -842,7 +845,8 @@ func (a *App) chatInputProps(snapshot *chatPreviewSnapshot, palette uiPalette, w
 }
 
 // chatQuestionPanelHeight bounds the tool question without starving the conversation viewport.
-func chatQuestionPanelHeight(snapshot *chatPreviewSnapshot, available float32) float32 {
+// Questions taller than two lines grow the panel up to the cap and scroll beyond it.
+func chatQuestionPanelHeight(snapshot *chatPreviewSnapshot, questionTextHeight float32, available float32) float32 {
 	if snapshot == nil || snapshot.question == nil {
 		return 0
 	}
@@ -853,14 +857,15 @@ func chatQuestionPanelHeight(snapshot *chatPreviewSnapshot, available float32) f
 			height += 56
 		}
 	}
+	height += max(0, questionTextHeight-previewview.ChatQuestionLineHeight*2)
 	return min(max(float32(140), height), max(float32(140), available*0.48))
 }
 
 // chatQuestionProps prepares ask-user options and keeps selection and submission in the controller.
-func (a *App) chatQuestionProps(snapshot *chatPreviewSnapshot, palette uiPalette, width, height float32) previewview.ChatQuestionProps {
+func (a *App) chatQuestionProps(snapshot *chatPreviewSnapshot, palette uiPalette, width, height float32, questionLayout woxwidget.TextBlockLayout) previewview.ChatQuestionProps {
 	question := snapshot.question
 	props := previewview.ChatQuestionProps{
-		Width: width, Height: height, Question: question.Question, Theme: palette.componentTheme(),
+		ID: question.QuestionID, Width: width, Height: height, Question: question.Question, QuestionLayout: questionLayout, Theme: palette.componentTheme(),
 		OnCancel: func() { a.submitAIQuestionAnswer("User cancelled") }, OnSubmit: a.submitSelectedAIQuestionAnswer,
 	}
 	props.Options = make([]previewview.ChatQuestionOptionProps, 0, len(question.Options))
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"wox/ui/contract"
 	woxui "wox/ui/runtime"
 )
 
@@ -34,16 +35,46 @@ func TestChatCommandPaletteFiltersModelsAndSkills(t *testing.T) {
 	models := []aiModel{{Name: "deepseek-v4-pro", Provider: "deepseek"}}
 	skills := []chatSkill{{Name: "writing-plans", Description: "Create an implementation plan", Source: "remote"}}
 
-	items := chatCommandPaletteItems(models, skills, aiModel{}, "deep", chatCommandPanel)
+	items := chatCommandPaletteItems(models, skills, chatMCPCatalog{}, aiModel{}, "deep", chatCommandPanel)
 	if len(items) != 1 || items[0].group != "models" || items[0].sourceIndex != 0 {
 		t.Fatalf("model filter = %+v", items)
 	}
-	items = chatCommandPaletteItems(models, skills, aiModel{}, "implementation", chatCommandPanel)
+	items = chatCommandPaletteItems(models, skills, chatMCPCatalog{}, aiModel{}, "implementation", chatCommandPanel)
 	if len(items) != 1 || items[0].group != "skills" || items[0].sourceIndex != 0 {
 		t.Fatalf("skill filter = %+v", items)
 	}
 }
 
+func TestChatCommandPaletteListsMCPPromptsAndResources(t *testing.T) {
+	mcp := chatMCPCatalog{
+		Prompts:   []chatMCPPrompt{{ServerName: "github", Name: "review", Description: "Review a pull request"}},
+		Resources: []chatMCPResource{{ServerName: "docs", URI: "file:///guide.md", Name: "guide"}},
+	}
+
+	items := chatCommandPaletteItems(nil, nil, mcp, aiModel{}, "", chatCommandPanel)
+	if len(items) != 2 || items[0].group != "prompts" || items[1].group != "resources" || items[1].subtitle != "docs · file:///guide.md" {
+		t.Fatalf("mcp items = %+v", items)
+	}
+	items = chatCommandPaletteItems(nil, nil, mcp, aiModel{}, "pull", chatCommandPanel)
+	if len(items) != 1 || items[0].group != "prompts" {
+		t.Fatalf("prompt filter = %+v", items)
+	}
+	if items = chatCommandPaletteItems(nil, nil, mcp, aiModel{}, "", "skills"); len(items) != 0 {
+		t.Fatalf("skill panel should not list MCP items: %+v", items)
+	}
+}
+
+func TestChatMCPPromptTagPlacesCaretInFirstArgument(t *testing.T) {
+	tag, caret := chatMCPPromptTag(chatMCPPrompt{ServerName: "github", Name: "review", Arguments: []contract.AIMCPPromptArgument{{Name: "pr"}, {Name: "repo"}}})
+	if tag != `{prompt:github:review pr="" repo=""}` || caret != len(`{prompt:github:review pr="`) {
+		t.Fatalf("prompt tag = %q caret %d", tag, caret)
+	}
+	tag, caret = chatMCPPromptTag(chatMCPPrompt{ServerName: "github", Name: "summary"})
+	if tag != "{prompt:github:summary}" || caret != len(tag) {
+		t.Fatalf("prompt tag without arguments = %q caret %d", tag, caret)
+	}
+}
+
 func TestChatModelPaletteHeightShrinksToContentAndCaps(t *testing.T) {
 	snapshot := &chatPreviewSnapshot{panel: "models", models: []aiModel{{Name: "flash"}, {Name: "pro"}}}
 	if height := chatCatalogPanelHeight(snapshot, 600); height != 118 {
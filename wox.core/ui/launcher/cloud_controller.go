@@ -273,7 +273,7 @@ func (c *cloudSettingsController) ReloadCloudSync(ctx context.Context, service c
 		if err == nil {
 			syncStatus = cloudSyncStatusFromContract(loadedStatus)
 		}
-		if accountStatus.LoggedIn {
+		if accountStatus.LoggedIn || syncStatus.Backend != "" {
 			loadedDevices, err := service.CloudDevices(timeoutCtx, sessionID)
 			devicesErr = err
 			if err == nil {
@@ -362,7 +362,7 @@ func cloudAccountStatusFromContract(status account.Status) cloudAccountStatus {
 // cloudSyncStatusFromContract deep-copies optional sync state and progress.
 func cloudSyncStatusFromContract(status cloudsync.ServiceStatus) cloudSyncStatus {
 	result := cloudSyncStatus{
-		Enabled: status.Enabled, DeviceID: status.DeviceID,
+		Enabled: status.Enabled, Backend: status.Backend, RestartRequired: status.RestartRequired, DeviceID: status.DeviceID,
 		KeyStatus: cloudSyncKeyStatus{Available: status.KeyStatus.Available, Version: status.KeyStatus.Version},
 	}
 	if status.State != nil {
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"wox/account"
+	"wox/cloudsync"
 	"wox/ui/contract"
 	woxui "wox/ui/runtime"
 	woxwidget "wox/ui/widget"
@@ -48,11 +49,13 @@ type cloudBillingPlanPrice struct {
 }
 
 type cloudSyncStatus struct {
-	Enabled   bool               `json:"enabled"`
-	DeviceID  string             `json:"device_id"`
-	KeyStatus cloudSyncKeyStatus `json:"key_status"`
-	State     *cloudSyncState    `json:"state"`
-	Progress  *cloudSyncProgress `json:"progress"`
+	Enabled         bool               `json:"enabled"`
+	Backend         string             `json:"backend"`
+	RestartRequired bool               `json:"restart_required"`
+	DeviceID        string             `json:"device_id"`
+	KeyStatus       cloudSyncKeyStatus `json:"key_status"`
+	State           *cloudSyncState    `json:"state"`
+	Progress        *cloudSyncProgress `json:"progress"`
 }
 
 type cloudSyncKeyStatus struct {
@@ -107,6 +110,7 @@ type cloudFormState struct {
 	saving        bool
 	email         string
 	hasRemoteData bool
+	backendType   string
 	controllers   []*woxwidget.TextEditingController
 	focusNodes    []*woxwidget.FocusNode
 }
@@ -120,6 +124,7 @@ type cloudFormSnapshot struct {
 	saving        bool
 	email         string
 	hasRemoteData bool
+	backendType   string
 	controllers   []*woxwidget.TextEditingController
 	focusNodes    []*woxwidget.FocusNode
 }
@@ -138,7 +143,7 @@ func snapshotCloudFormLocked(state *cloudFormState) *cloudFormSnapshot {
 	fields.active = !state.saving
 	return &cloudFormSnapshot{
 		formFieldsSnapshot: fields,
-		kind:               state.kind, title: state.title, error: state.error, notice: state.notice, saving: state.saving, email: state.email, hasRemoteData: state.hasRemoteData,
+		kind:               state.kind, title: state.title, error: state.error, notice: state.notice, saving: state.saving, email: state.email, hasRemoteData: state.hasRemoteData, backendType: state.backendType,
 		controllers: append([]*woxwidget.TextEditingController(nil), state.controllers...),
 		focusNodes:  append([]*woxwidget.FocusNode(nil), state.focusNodes...),
 	}
@@ -215,6 +220,8 @@ func cloudActionLabel(name string) string {
 		return "Revoke device"
 	case "join":
 		return "Join device"
+	case "backend":
+		return "Switch storage"
 	default:
 		return strings.Title(name)
 	}
@@ -368,7 +375,66 @@ func (a *App) runCloudMenuAction(action string) {
 		util.Go(a.lifecycleCtx, "reload cloud sync", a.reloadCloudSync)
 	case "billing":
 		a.openCloudBilling()
+	default:
+		if backendType, ok := strings.CutPrefix(action, "backend:"); ok {
+			a.chooseCloudBackend(backendType)
+		}
+	}
+}
+
+// chooseCloudBackend switches back to the Wox sync service directly and asks
+// for connection details before switching to a self-hosted backend.
+func (a *App) chooseCloudBackend(backendType string) {
+	if backendType != cloudsync.CloudSyncBackendWox {
+		a.cloudSettings.SetForm(newCloudBackendForm(backendType, a.generalSettings.Data().CloudSyncBackend))
+		a.updateSettingsTextInput(true)
+		a.invalidateSettingsWindow()
+		return
 	}
+	a.runCloudAction("backend", func(ctx context.Context) error {
+		if err := a.services.UpdateGeneralSetting(ctx, a.sessionID, "CloudSyncBackend", "{}"); err != nil {
+			return err
+		}
+		return a.reloadSettings()
+	})
+}
+
+// newCloudBackendForm only asks for the fields the chosen backend uses and
+// prefills them when that backend is already configured.
+func newCloudBackendForm(backendType string, current cloudsync.CloudSyncBackendConfig) *cloudFormState {
+	values := map[string]string{}
+	if current.Type == backendType {
+		values = map[string]string{"Url": current.Url, "Bucket": current.Bucket, "Region": current.Region, "Username": current.Username, "Password": current.Password}
+	}
+	textbox := func(key, label string) formDefinition {
+		return formDefinition{Type: "textbox", Value: formDefinitionValue{Key: key, Label: label, MaxLines: 1}}
+	}
+	password := func(key, label string) formDefinition {
+		return formDefinition{Type: "password", Value: formDefinitionValue{Key: key, Label: label, MaxLines: 1}}
+	}
+	var definitions []formDefinition
+	switch backendType {
+	case cloudsync.CloudSyncBackendWebDAV:
+		definitions = []formDefinition{
+			textbox("Url", "i18n:ui_cloud_sync_backend_webdav_url"),
+			textbox("Username", "i18n:ui_cloud_sync_backend_username"),
+			password("Password", "i18n:ui_cloud_sync_backend_password"),
+		}
+	case cloudsync.CloudSyncBackendS3:
+		definitions = []formDefinition{
+			textbox("Url", "i18n:ui_cloud_sync_backend_s3_endpoint"),
+			textbox("Bucket", "i18n:ui_cloud_sync_backend_s3_bucket"),
+			textbox("Region", "i18n:ui_cloud_sync_backend_s3_region"),
+			textbox("Username", "i18n:ui_cloud_sync_backend_s3_access_key"),
+			password("Password", "i18n:ui_cloud_sync_backend_s3_secret_key"),
+		}
+	case cloudsync.CloudSyncBackendFolder:
+		definitions = []formDefinition{textbox("Url", "i18n:ui_cloud_sync_backend_folder_path")}
+	}
+	fields := newFormFieldsState(definitions, values, true)
+	state := newCloudFormState(fields, "backend", "i18n:ui_cloud_sync_backend_"+backendType)
+	state.backendType = backendType
+	return state
 }
 
 // beginCloudBootstrap reuses a local key when possible or opens the recovery-password form required by core.
@@ -701,6 +767,9 @@ func (a *App) submitCloudForm() {
 	values["Token"] = strings.TrimSpace(values["Token"])
 	email := form.email
 	hasRemoteData := form.hasRemoteData
+	if kind == "backend" {
+		values["Type"] = form.backendType
+	}
 	validationError := validateCloudForm(kind, values, hasRemoteData)
 	if validationError != "" {
 		form.error = validationError
@@ -800,6 +869,13 @@ func validateCloudForm(kind string, values map[string]string, hasRemoteData bool
 				return "i18n:ui_cloud_sync_recovery_code_mismatch"
 			}
 		}
+	case "backend":
+		if strings.TrimSpace(values["Url"]) == "" {
+			return "i18n:ui_cloud_sync_backend_url_required"
+		}
+		if values["Type"] == cloudsync.CloudSyncBackendS3 && (strings.TrimSpace(values["Bucket"]) == "" || strings.TrimSpace(values["Username"]) == "" || values["Password"] == "") {
+			return "i18n:ui_cloud_sync_backend_s3_required"
+		}
 	}
 	return ""
 }
@@ -827,6 +903,17 @@ func (a *App) submitCloudFormRequest(kind string, values map[string]string, emai
 		err = a.services.ChangeAccountPassword(ctx, a.sessionID, values["CurrentPassword"], values["Password"], lang)
 	case "bootstrap":
 		err = a.services.StartCloudBootstrap(ctx, a.sessionID, values["RecoveryCode"])
+	case "backend":
+		var encoded []byte
+		encoded, err = json.Marshal(cloudsync.CloudSyncBackendConfig{
+			Type: values["Type"], Url: values["Url"], Bucket: values["Bucket"], Region: values["Region"], Username: values["Username"], Password: values["Password"],
+		})
+		if err == nil {
+			err = a.services.UpdateGeneralSetting(ctx, a.sessionID, "CloudSyncBackend", string(encoded))
+		}
+		if err == nil {
+			err = a.reloadSettings()
+		}
 	}
 	if err == nil && (kind == "login" || kind == "register") && result.Code == "need_verify_email" {
 		verificationEmail := result.Email
@@ -941,6 +1028,56 @@ func (a *App) toggleCloudPluginExclusion(pluginID string) {
 	})
 }
 
+// toggleCloudEntityExclusion flips one data entity type between synced and local-only.
+func (a *App) toggleCloudEntityExclusion(entityType string) {
+	entityType = strings.TrimSpace(entityType)
+	if entityType == "" {
+		return
+	}
+	if a.cloudSettings.Busy() != "" {
+		return
+	}
+	found := false
+	next := make([]string, 0, len(a.generalSettings.Data().CloudSyncDisabledEntities)+1)
+	for _, candidate := range a.generalSettings.Data().CloudSyncDisabledEntities {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "" {
+			continue
+		}
+		if candidate == entityType {
+			found = true
+			continue
+		}
+		next = append(next, candidate)
+	}
+	if !found {
+		next = append(next, entityType)
+	}
+	sort.Strings(next)
+	encoded, err := json.Marshal(next)
+	if err != nil {
+		a.cloudSettings.SetError("Could not encode sync data types: " + err.Error())
+		a.invalidateSettingsWindow()
+		return
+	}
+	a.cloudSettings.SetBusy("data-type-" + entityType)
+	a.cloudSettings.SetError("")
+	a.invalidateSettingsWindow()
+	util.Go(a.lifecycleCtx, "save cloud sync data types", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		err := a.services.UpdateGeneralSetting(ctx, a.sessionID, "CloudSyncDisabledEntities", string(encoded))
+		cancel()
+		if err == nil {
+			err = a.reloadSettings()
+		}
+		if err != nil {
+			a.cloudSettings.SetError("Could not save sync data types: " + err.Error())
+		}
+		a.cloudSettings.SetBusy("")
+		a.invalidateSettingsWindow()
+	})
+}
+
 func cloudPluginExclusionRows(plugins []pluginSettingsPlugin, excluded []string) []pluginSettingsPlugin {
 	rows := append([]pluginSettingsPlugin(nil), plugins...)
 	seen := make(map[string]bool, len(rows))
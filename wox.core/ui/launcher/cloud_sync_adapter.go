@@ -32,6 +32,8 @@ func (a *App) buildCloudSettingsPage(snapshot settingsSnapshot, width, height, i
 		Intro:        a.cloudIntroViewProps(snapshot, imageScale),
 		Account:      a.cloudAccountViewProps(snapshot, contentWidth, imageScale),
 		Sync:         a.cloudSyncViewProps(snapshot, contentWidth),
+		DataTypes:    a.cloudDataTypesViewProps(snapshot),
+		Backend:      a.cloudBackendViewProps(snapshot, contentWidth),
 		Devices:      a.cloudDevicesViewProps(snapshot, contentWidth, imageScale),
 		Plugins:      a.cloudPluginExclusionsViewProps(snapshot, imageScale),
 		ConfigNotes:  a.cloudConfigNotesViewProps(snapshot, imageScale),
@@ -165,7 +167,7 @@ func (a *App) cloudSyncViewProps(snapshot settingsSnapshot, contentWidth float32
 	ready := cloudSyncReady(snapshot)
 	buttonLabel := a.translate("i18n:ui_cloud_sync_sync")
 	buttonAction := func() {
-		if !ready || !snapshot.cloud.Account.SyncEnabled || !snapshot.cloud.Sync.Enabled {
+		if !ready || !cloudSyncEnabled(snapshot) {
 			a.beginCloudBootstrap()
 			return
 		}
@@ -187,18 +189,62 @@ func (a *App) cloudSyncViewProps(snapshot settingsSnapshot, contentWidth float32
 		Detail:        detail,
 		Color:         color,
 		ButtonLabel:   cloudBusyLabel(snapshot, "sync", buttonLabel),
-		ButtonEnabled: snapshot.cloud.Busy == "" && !snapshot.cloud.Loading && !snapshot.cloud.Account.SessionExpired && snapshot.cloud.Account.SyncEligible,
+		ButtonEnabled: snapshot.cloud.Busy == "" && !snapshot.cloud.Loading && (cloudSelfHosted(snapshot) || (!snapshot.cloud.Account.SessionExpired && snapshot.cloud.Account.SyncEligible)),
 		OnSync:        buttonAction,
 	}
 }
 
+// cloudBackendViewProps summarizes the configured storage backend and offers the switch menu.
+func (a *App) cloudBackendViewProps(snapshot settingsSnapshot, contentWidth float32) launcherview.CloudBackendProps {
+	backend := snapshot.general.Data.CloudSyncBackend
+	detail := backend.Url
+	if backend.Type == cloudsync.CloudSyncBackendS3 && backend.Bucket != "" {
+		detail += " / " + backend.Bucket
+	}
+	color := snapshot.palette.resultSubtitle
+	if snapshot.cloud.Sync.RestartRequired {
+		detail = a.translate("i18n:ui_cloud_sync_backend_restart_required")
+		color = snapshot.palette.componentTheme().ErrorText
+	}
+	return launcherview.CloudBackendProps{
+		SectionLabel:  a.translate("i18n:ui_cloud_sync_backend"),
+		StatusLabel:   a.translate("i18n:ui_cloud_sync_backend_storage"),
+		LabelWidth:    cloudSettingsLabelWidth(contentWidth, 154),
+		Label:         a.cloudBackendLabel(backend.Type),
+		Detail:        detail,
+		Color:         color,
+		ButtonLabel:   cloudBusyLabel(snapshot, "backend", a.translate("i18n:ui_cloud_sync_backend_change")),
+		ButtonEnabled: snapshot.cloud.Busy == "" && !snapshot.cloud.Loading,
+		SelfHosted:    cloudSelfHosted(snapshot),
+		OnChange:      func() { a.toggleCloudActionMenu("backend") },
+	}
+}
+
+func (a *App) cloudBackendLabel(backendType string) string {
+	if backendType == cloudsync.CloudSyncBackendWox {
+		return a.translate("i18n:ui_cloud_sync_backend_wox")
+	}
+	return a.translate("i18n:ui_cloud_sync_backend_" + backendType)
+}
+
+// cloudSelfHosted follows the running backend, which only changes after a restart.
+func cloudSelfHosted(snapshot settingsSnapshot) bool {
+	return snapshot.cloud.Sync.Backend != ""
+}
+
+// cloudSyncEnabled skips the account sync switch for self-hosted backends, which have no account.
+func cloudSyncEnabled(snapshot settingsSnapshot) bool {
+	return snapshot.cloud.Sync.Enabled && (cloudSelfHosted(snapshot) || snapshot.cloud.Account.SyncEnabled)
+}
+
 func (a *App) cloudSyncPresentation(snapshot settingsSnapshot) (string, string, woxui.Color) {
 	muted := snapshot.palette.resultSubtitle
 	errorColor := snapshot.palette.componentTheme().ErrorText
 	if snapshot.cloud.Loading {
 		return a.translate("i18n:ui_cloud_sync_loading"), "", muted
 	}
-	if snapshot.cloud.Account.SessionExpired {
+	selfHosted := cloudSelfHosted(snapshot)
+	if snapshot.cloud.Account.SessionExpired && !selfHosted {
 		return a.translate("i18n:ui_cloud_sync_sync_error"), a.translate("i18n:ui_cloud_sync_account_session_expired"), errorColor
 	}
 	if snapshot.cloud.Error != "" {
@@ -213,13 +259,13 @@ func (a *App) cloudSyncPresentation(snapshot settingsSnapshot) (string, string,
 	if state := snapshot.cloud.Sync.State; state != nil && state.LastError != "" {
 		return a.translate("i18n:ui_cloud_sync_sync_error"), state.LastError, errorColor
 	}
-	if !snapshot.cloud.Account.SyncEligible {
+	if !snapshot.cloud.Account.SyncEligible && !selfHosted {
 		return a.translate("i18n:ui_cloud_sync_unsynced"), a.translate("i18n:ui_cloud_sync_subscription_required"), muted
 	}
 	if !cloudSyncReady(snapshot) {
 		return a.translate("i18n:ui_cloud_sync_unsynced"), "", muted
 	}
-	if !snapshot.cloud.Account.SyncEnabled || !snapshot.cloud.Sync.Enabled {
+	if !cloudSyncEnabled(snapshot) {
 		return a.translate("i18n:ui_cloud_sync_disabled"), "", muted
 	}
 	lastSync := max(cloudStateTimestamp(snapshot.cloud.Sync.State, true), cloudStateTimestamp(snapshot.cloud.Sync.State, false))
@@ -406,6 +452,32 @@ func cloudRefreshLabel(a *App, snapshot settingsSnapshot) string {
 	return a.translate("i18n:ui_cloud_sync_refresh")
 }
 
+// cloudDataTypesViewProps prepares one sync switch per data entity type known to Wox core.
+func (a *App) cloudDataTypesViewProps(snapshot settingsSnapshot) launcherview.CloudDataTypesProps {
+	disabled := make(map[string]bool, len(snapshot.general.Data.CloudSyncDisabledEntities))
+	for _, entityType := range snapshot.general.Data.CloudSyncDisabledEntities {
+		disabled[strings.TrimSpace(entityType)] = true
+	}
+	busy := a.cloudSettings.Busy() != ""
+	entityTypes := cloudsync.CloudSyncDataEntityTypes()
+	items := make([]launcherview.CloudDataTypeProps, 0, len(entityTypes))
+	for _, entityType := range entityTypes {
+		items = append(items, launcherview.CloudDataTypeProps{
+			ID:          entityType,
+			Label:       a.translate("i18n:ui_cloud_sync_data_type_" + entityType),
+			Description: a.translate("i18n:ui_cloud_sync_data_type_" + entityType + "_description"),
+			Enabled:     !disabled[entityType],
+			Disabled:    busy,
+			OnToggle:    func() { a.toggleCloudEntityExclusion(entityType) },
+		})
+	}
+	return launcherview.CloudDataTypesProps{
+		SectionLabel: a.translate("i18n:ui_cloud_sync_data_types"),
+		Tips:         a.translate("i18n:ui_cloud_sync_data_types_tips"),
+		Items:        items,
+	}
+}
+
 // cloudPluginExclusionsViewProps prepares the visible plugin boundary and toggle actions.
 func (a *App) cloudPluginExclusionsViewProps(snapshot settingsSnapshot, imageScale float32) launcherview.CloudPluginExclusionsProps {
 	rows := cloudPluginExclusionRows(snapshot.cloud.Plugins, snapshot.general.Data.CloudSyncDisabledPlugins)
@@ -534,6 +606,12 @@ func (a *App) cloudActionMenuViewProps(snapshot settingsSnapshot) *launcherview.
 		}
 		top = 205
 	}
+	if snapshot.cloud.ActionMenu == "backend" {
+		actions = []menuAction{{id: "backend-wox", label: a.cloudBackendLabel(cloudsync.CloudSyncBackendWox), action: "backend:" + cloudsync.CloudSyncBackendWox}}
+		for _, backendType := range []string{cloudsync.CloudSyncBackendWebDAV, cloudsync.CloudSyncBackendS3, cloudsync.CloudSyncBackendFolder} {
+			actions = append(actions, menuAction{id: "backend-" + backendType, label: a.cloudBackendLabel(backendType), action: "backend:" + backendType})
+		}
+	}
 	if snapshot.cloud.ActionMenu == "plugins" {
 		excluded := make(map[string]bool, len(snapshot.general.Data.CloudSyncDisabledPlugins))
 		for _, pluginID := range snapshot.general.Data.CloudSyncDisabledPlugins {
@@ -565,7 +643,7 @@ func (a *App) cloudActionMenuViewProps(snapshot settingsSnapshot) *launcherview.
 		}
 		items = append(items, launcherview.CloudActionMenuItemProps{ID: "cloud-menu-" + entry.id, Label: entry.label, OnTap: onTap})
 	}
-	return &launcherview.CloudActionMenuProps{Top: top, Modal: snapshot.cloud.ActionMenu == "plugins", Items: items}
+	return &launcherview.CloudActionMenuProps{Top: top, Modal: snapshot.cloud.ActionMenu == "plugins" || snapshot.cloud.ActionMenu == "backend", Items: items}
 }
 
 func (a *App) formatCloudTime(timestamp int64) string {
@@ -688,6 +766,8 @@ func cloudFormDescription(snapshot *cloudFormSnapshot) string {
 			return "i18n:ui_cloud_sync_bootstrap_restore_description"
 		}
 		return "i18n:ui_cloud_sync_bootstrap_start_description"
+	case "backend":
+		return "i18n:ui_cloud_sync_backend_description"
 	default:
 		return "Use your Wox account credentials to continue."
 	}
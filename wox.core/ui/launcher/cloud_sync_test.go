@@ -107,3 +107,51 @@ func TestFormatCloudSyncProgressUsesPushTargetAndTotal(t *testing.T) {
 		t.Fatalf("push detail = %q", detail)
 	}
 }
+
+func TestNewCloudBackendFormOnlyAsksForBackendFields(t *testing.T) {
+	current := cloudsync.CloudSyncBackendConfig{Type: cloudsync.CloudSyncBackendWebDAV, Url: "https://dav.example.com/wox", Username: "me", Password: "secret"}
+
+	webdav := newCloudBackendForm(cloudsync.CloudSyncBackendWebDAV, current)
+	if webdav.kind != "backend" || webdav.backendType != cloudsync.CloudSyncBackendWebDAV || len(webdav.definitions) != 3 {
+		t.Fatalf("webdav form = %q/%q with %d fields", webdav.kind, webdav.backendType, len(webdav.definitions))
+	}
+	if webdav.values["Url"] != current.Url || webdav.values["Password"] != "secret" || webdav.definitions[2].Type != "password" {
+		t.Fatalf("webdav form should prefill the configured backend, got %#v", webdav.values)
+	}
+
+	s3 := newCloudBackendForm(cloudsync.CloudSyncBackendS3, current)
+	if len(s3.definitions) != 5 || s3.values["Url"] != "" {
+		t.Fatalf("s3 form should not reuse another backend's values, got %d fields and %#v", len(s3.definitions), s3.values)
+	}
+	if got := validateCloudForm("backend", map[string]string{"Type": cloudsync.CloudSyncBackendS3, "Url": "http://127.0.0.1:9000"}, false); got != "i18n:ui_cloud_sync_backend_s3_required" {
+		t.Fatalf("s3 validation = %q", got)
+	}
+
+	folder := newCloudBackendForm(cloudsync.CloudSyncBackendFolder, current)
+	if len(folder.definitions) != 1 || folder.title != "i18n:ui_cloud_sync_backend_folder" {
+		t.Fatalf("folder form = %q with %d fields", folder.title, len(folder.definitions))
+	}
+	if got := validateCloudForm("backend", map[string]string{"Type": cloudsync.CloudSyncBackendFolder}, false); got != "i18n:ui_cloud_sync_backend_url_required" {
+		t.Fatalf("folder validation = %q", got)
+	}
+}
+
+func TestCloudSyncPresentationIgnoresAccountForSelfHostedBackend(t *testing.T) {
+	app := &App{translations: map[string]string{
+		"ui_cloud_sync_synced":         "Synced",
+		"ui_cloud_sync_last_sync_time": "Last sync",
+		"ui_cloud_sync_never":          "Never",
+	}}
+	label, _, _ := app.cloudSyncPresentation(settingsSnapshot{cloud: cloudSettingsSnapshot{
+		Account: cloudAccountStatus{SessionExpired: true},
+		Sync: cloudSyncStatus{
+			Enabled:   true,
+			Backend:   cloudsync.CloudSyncBackendFolder,
+			KeyStatus: cloudSyncKeyStatus{Available: true},
+			State:     &cloudSyncState{Bootstrapped: true},
+		},
+	}})
+	if label != "Synced" {
+		t.Fatalf("self-hosted presentation = %q, want Synced without a Wox account", label)
+	}
+}
@@ -356,14 +356,19 @@ func (a *App) CaptureScreenshot(_ context.Context, request common.CaptureScreens
 	result, err := woxscreenshot.CaptureScreenshot(woxscreenshot.ScreenshotOptions{
 		ExportFilePath: request.ExportFilePath, CopyToClipboard: request.Output == "" || strings.EqualFold(request.Output, "clipboard"),
 		HideAnnotationToolbar: request.HideAnnotationToolbar, AutoConfirm: request.AutoConfirm, AllowVideoRecording: request.AllowVideoRecording,
+		SaveProject: request.SaveProject, ProjectPath: request.EditProjectPath,
 		RecordingDefaults: woxscreenshot.RecordingDefaults{FPS: 30, ShowPointer: true}, WindowManager: a.windows,
 		AnnotationTooltips: woxscreenshot.ScreenshotAnnotationTooltips{
-			Rectangle: a.translate("i18n:ui_screenshot_tool_rectangle"),
-			Ellipse:   a.translate("i18n:ui_screenshot_tool_ellipse"),
-			Text:      a.translate("i18n:ui_screenshot_tool_text"),
-			Arrow:     a.translate("i18n:ui_screenshot_tool_arrow"),
-			Number:    a.translate("i18n:ui_screenshot_tool_number"),
-			Mosaic:    a.translate("i18n:ui_screenshot_tool_mosaic"),
+			Rectangle:   a.translate("i18n:ui_screenshot_tool_rectangle"),
+			Ellipse:     a.translate("i18n:ui_screenshot_tool_ellipse"),
+			Text:        a.translate("i18n:ui_screenshot_tool_text"),
+			Arrow:       a.translate("i18n:ui_screenshot_tool_arrow"),
+			Number:      a.translate("i18n:ui_screenshot_tool_number"),
+			Mosaic:      a.translate("i18n:ui_screenshot_tool_mosaic"),
+			Pen:         a.translate("i18n:ui_screenshot_tool_pen"),
+			Highlighter: a.translate("i18n:ui_screenshot_tool_highlighter"),
+			Blur:        a.translate("i18n:ui_screenshot_tool_blur"),
+			Crop:        a.translate("i18n:ui_screenshot_tool_crop"),
 		},
 		ActionTooltips: woxscreenshot.ScreenshotActionTooltips{
 			Undo:             a.translate("i18n:ui_screenshot_tool_undo"),
@@ -107,3 +107,15 @@ func (a *App) chooseFormTableAppCandidate(candidate ignoredHotkeyApp) {
 	a.updateFormTableTextInput(false)
 	a.invalidateFormTableWindow()
 }
+
+// clearFormTableRowApp resets an optional app field back to "any app".
+func (a *App) clearFormTableRowApp(index int) {
+	state := a.activeFormTableEditor()
+	if state == nil || state.rowForm == nil || state.appPicker != nil || index < 0 || index >= len(state.rowForm.definitions) || state.rowForm.definitions[index].Type != "app" ||
+		formValidatorsRequireValue(state.rowForm.definitions[index].Value.Validators) {
+		return
+	}
+	state.rowForm.values[state.rowForm.definitions[index].Value.Key] = "{}"
+	clearFormTableRowValidationLocked(state)
+	a.invalidateFormTableWindow()
+}
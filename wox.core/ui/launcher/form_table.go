@@ -659,7 +659,7 @@ func inferQueryHotkeyPreset(values map[string]string) queryHotkeyPreset {
 // queryHotkeyFieldVisible keeps each preset limited to the fields shown by Flutter.
 func queryHotkeyFieldVisible(preset queryHotkeyPreset, key string, editing bool) bool {
 	switch key {
-	case "Name", "Hotkey", "Query":
+	case "Name", "Hotkey", "App", "Query":
 		return true
 	case "Position", "Width", "MaxResultCount":
 		return preset == queryHotkeyPresetWebPanel || preset == queryHotkeyPresetCustom
@@ -761,6 +761,10 @@ func formTableRowFromFields(definition formDefinition, fields *formFieldsState,
 			image, _ := parseFormTableWoxImage(value)
 			row[column.Key] = image
 		case "app":
+			if formTableAppUnset(column, value) {
+				row[column.Key] = nil
+				continue
+			}
 			app, _ := parseFormTableApp(value)
 			row[column.Key] = app
 		}
@@ -830,7 +834,7 @@ func validateFormTableRow(definition formDefinition, fields *formFieldsState, ro
 				continue
 			}
 		}
-		if column.Type == "app" {
+		if column.Type == "app" && !formTableAppUnset(column, fields.values[column.Key]) {
 			if _, err := parseFormTableApp(fields.values[column.Key]); err != nil {
 				errors[column.Key] = err.Error()
 				continue
@@ -906,6 +910,25 @@ func formTableAppValue(value any) string {
 	return "{}"
 }
 
+// formTableAppUnset reports whether an optional app column was left empty.
+// Columns without a not_empty validator treat a missing app as "any app".
+func formTableAppUnset(column formTableColumn, value string) bool {
+	if formValidatorsRequireValue(column.Validators) {
+		return false
+	}
+	var app ignoredHotkeyApp
+	return json.Unmarshal([]byte(value), &app) == nil && strings.TrimSpace(app.Identity) == ""
+}
+
+func formValidatorsRequireValue(validators []formValidator) bool {
+	for _, validator := range validators {
+		if validator.Type == "not_empty" {
+			return true
+		}
+	}
+	return false
+}
+
 func parseFormTableApp(value string) (map[string]any, error) {
 	var app ignoredHotkeyApp
 	if err := json.Unmarshal([]byte(value), &app); err != nil {
@@ -1620,6 +1643,12 @@ func (a *App) onFormTableKey(event woxui.KeyEvent) bool {
 		} else {
 			a.editFormTableRowKey(event)
 		}
+	case woxui.KeyBackspace, woxui.KeyDelete:
+		if fieldType == "app" {
+			a.clearFormTableRowApp(focused)
+		} else {
+			a.editFormTableRowKey(event)
+		}
 	case woxui.KeySpace, woxui.KeyEnter:
 		if event.Key == woxui.KeyEnter && multiline {
 			a.editFormTableRowKey(event)
@@ -6,6 +6,7 @@ import (
 	"sort"
 	"strings"
 
+	"wox/common"
 	woxcomponent "wox/ui/launcher/component"
 	launcherview "wox/ui/launcher/view"
 	woxui "wox/ui/runtime"
@@ -41,7 +42,16 @@ func (a *App) formTableDisplayValue(column formTableColumn, row map[string]any)
 		}
 	}
 	value := formTableColumnValue(column, row)
+	if column.Type == "aiMCPServerStatus" {
+		if health, ok := a.aiSettings.MCPServerHealth(fmt.Sprint(row["Name"])); ok && health.Error != "" {
+			return health.Error
+		}
+		return ""
+	}
 	if column.Type == "aiMCPServerTools" {
+		if health, ok := a.aiSettings.MCPServerHealth(fmt.Sprint(row["Name"])); ok && health.State == string(common.MCPServerStateConnected) {
+			return fmt.Sprintf("%d tools, %d prompts, %d resources", health.ToolCount, health.PromptCount, health.ResourceCount)
+		}
 		switch tools := row[column.Key].(type) {
 		case []any:
 			return fmt.Sprintf("%d tools", len(tools))
@@ -249,6 +259,22 @@ func (a *App) formTableViewCell(column formTableColumn, row map[string]any, them
 		cell.IndicatorColor = &statusColor
 		return cell
 	}
+	if column.Type == "aiMCPServerStatus" {
+		// Gray until core has connected, green when connected, red with the error as tooltip otherwise.
+		statusColor := woxui.Color{R: 158, G: 158, B: 158, A: 255}
+		if health, ok := a.aiSettings.MCPServerHealth(fmt.Sprint(row["Name"])); ok {
+			switch common.MCPServerState(health.State) {
+			case common.MCPServerStateConnected:
+				statusColor = woxui.Color{R: 69, G: 184, B: 88, A: 255}
+			case common.MCPServerStateError:
+				statusColor = woxui.Color{R: 229, G: 72, B: 77, A: 255}
+			}
+			cell.Tooltip = health.Error
+		}
+		cell.Text = ""
+		cell.IndicatorColor = &statusColor
+		return cell
+	}
 	if column.Type == "checkbox" {
 		cell.Text = ""
 		if formTableColumnValue(column, row) == "true" {
@@ -649,6 +675,9 @@ func (a *App) buildFormTableRowField(fields formFieldsSnapshot, callbacks formFi
 		props.Value = app.Name
 		if strings.TrimSpace(props.Value) == "" {
 			props.Value = a.translate("i18n:ui_hotkey_ignore_apps_app_placeholder")
+			if strings.TrimSpace(app.Identity) == "" && !formValidatorsRequireValue(definition.Value.Validators) {
+				props.Value = a.translate("i18n:ui_query_hotkeys_app_any")
+			}
 		}
 		props.Detail = app.Path
 		if strings.TrimSpace(props.Detail) == "" {
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	woxui "wox/ui/runtime"
+	utilhotkey "wox/util/hotkey"
 )
 
 // hotkeyMatches compares a configured normal hotkey with one physical key-down event.
@@ -40,9 +41,25 @@ func hotkeyMatches(hotkey string, event woxui.KeyEvent) bool {
 }
 
 // formatHotkeyLabels applies platform labels while keeping each physical key separate.
+// The chords of a hotkey sequence are separated by a "," label.
 func formatHotkeyLabels(hotkey string) []string {
 	hotkey = strings.TrimSpace(hotkey)
 	hotkey = strings.TrimPrefix(hotkey, "hold:")
+	steps := utilhotkey.SplitSequence(hotkey)
+	if len(steps) <= 1 {
+		return formatHotkeyChordLabels(hotkey)
+	}
+	labels := make([]string, 0, len(steps)*3)
+	for index, step := range steps {
+		if index > 0 {
+			labels = append(labels, ",")
+		}
+		labels = append(labels, formatHotkeyChordLabels(step)...)
+	}
+	return labels
+}
+
+func formatHotkeyChordLabels(hotkey string) []string {
 	parts := strings.Split(strings.TrimSpace(hotkey), "+")
 	labels := make([]string, 0, len(parts))
 	for _, part := range parts {
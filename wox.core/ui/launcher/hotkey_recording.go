@@ -2,6 +2,7 @@ package launcher
 
 import (
 	"context"
+	"encoding/json"
 	"log"
 	"runtime"
 	"strconv"
@@ -13,6 +14,7 @@ import (
 )
 
 var defaultHotkeyRecordingKinds = []string{"normalCombo", "doubleModifier", "capsLockCombo"}
+var queryHotkeyRecordingKinds = []string{"normalCombo", "doubleModifier", "capsLockCombo", "sequence"}
 var dictationHotkeyRecordingKinds = []string{"normalCombo", "doubleModifier", "capsLockCombo", "pressModifier", "holdModifier"}
 
 type hotkeyRecordingState struct {
@@ -29,6 +31,9 @@ type hotkeyRecordingState struct {
 	hint        string
 	display     string
 	statusError bool
+	// pending holds the latest recorded hotkey that arrived while an availability
+	// check was running, so the later steps of a sequence aren't dropped.
+	pending *recordedHotkeyPayload
 }
 
 type hotkeyRecordingPresentation struct {
@@ -109,6 +114,9 @@ func (a *App) hotkeyRecordingHint(definition formDefinition, allowedKinds []stri
 	if containsString(allowedKinds, "pressModifier") {
 		return a.translate("i18n:ui_hotkey_modifier_press_hint")
 	}
+	if containsString(allowedKinds, "sequence") {
+		return a.translate("i18n:ui_hotkey_sequence_press_hint")
+	}
 	return a.translate("i18n:ui_hotkey_press_hint")
 }
 
@@ -172,7 +180,11 @@ func (a *App) applyRecordedHotkey(payload recordedHotkeyPayload) error {
 		return nil
 	}
 	state := a.hotkeySettings.Recording()
-	if state == nil || state.checking || (payload.Kind != "" && !state.allowed[payload.Kind]) || !a.hotkeyRecordingTargetCurrentLocked(state.target) {
+	if state == nil || (payload.Kind != "" && !state.allowed[payload.Kind]) || !a.hotkeyRecordingTargetCurrentLocked(state.target) {
+		return nil
+	}
+	if state.checking {
+		state.pending = &payload
 		return nil
 	}
 	canonical := canonicalRecordedHotkey(payload)
@@ -204,13 +216,18 @@ func hotkeyKindSkipsAvailability(kind string) bool {
 
 func (a *App) checkRecordedHotkey(state *hotkeyRecordingState, hotkey string) {
 	ctx, cancel := context.WithTimeout(context.Background(), 8*time.Second)
-	availability, err := a.services.CheckHotkeyAvailability(ctx, a.sessionID, hotkey)
+	availability, err := a.services.CheckHotkeyAvailability(ctx, a.sessionID, hotkey, hotkeyRecordingAppIdentity(state))
 	cancel()
 	_ = a.runOnUI("apply recorded hotkey availability", func() {
 		if a.hotkeySettings.Recording() != state || !a.hotkeyRecordingTargetCurrentLocked(state.target) {
 			return
 		}
 		state.checking = false
+		if pending := state.pending; pending != nil {
+			state.pending = nil
+			_ = a.applyRecordedHotkey(*pending)
+			return
+		}
 		if err != nil {
 			state.status = state.hint
 			state.statusError = false
@@ -228,6 +245,19 @@ func (a *App) checkRecordedHotkey(state *hotkeyRecordingState, hotkey string) {
 	})
 }
 
+// hotkeyRecordingAppIdentity returns the app a recorded hotkey is scoped to,
+// taken from the App field of the same form when there is one.
+func hotkeyRecordingAppIdentity(state *hotkeyRecordingState) string {
+	if state == nil || state.target == nil {
+		return ""
+	}
+	var app ignoredHotkeyApp
+	if json.Unmarshal([]byte(state.target.values["App"]), &app) != nil {
+		return ""
+	}
+	return strings.TrimSpace(app.Identity)
+}
+
 func (a *App) hotkeyConflictMessage(kind, value string) string {
 	switch kind {
 	case "main":
@@ -236,8 +266,12 @@ func (a *App) hotkeyConflictMessage(kind, value string) string {
 		return a.translate("i18n:ui_hotkey_conflict_selection")
 	case "query":
 		return strings.ReplaceAll(a.translate("i18n:ui_hotkey_conflict_query"), "{query}", value)
+	case "plugin":
+		return strings.ReplaceAll(a.translate("i18n:ui_hotkey_conflict_plugin"), "{hotkey}", value)
 	case "system":
 		return a.translate("i18n:ui_hotkey_conflict_system")
+	case "app_scope":
+		return a.translate("i18n:ui_hotkey_conflict_app_scope")
 	default:
 		return a.translate("i18n:ui_hotkey_unavailable")
 	}
@@ -1,6 +1,7 @@
 package launcher
 
 import (
+	"strings"
 	"testing"
 
 	woxui "wox/ui/runtime"
@@ -70,3 +71,21 @@ func TestFallbackHotkeyStringAllowsStandaloneFunctionKeys(t *testing.T) {
 		t.Fatalf("standalone letter = %q, want empty", got)
 	}
 }
+
+func TestHotkeyRecordingAppIdentityReadsAppField(t *testing.T) {
+	state := &hotkeyRecordingState{target: &formFieldsState{values: map[string]string{"App": `{"Name":"Code","Identity":" com.microsoft.VSCode "}`}}}
+	if got := hotkeyRecordingAppIdentity(state); got != "com.microsoft.VSCode" {
+		t.Fatalf("app identity = %q, want com.microsoft.VSCode", got)
+	}
+	state.target.values["App"] = "{}"
+	if got := hotkeyRecordingAppIdentity(state); got != "" {
+		t.Fatalf("empty app identity = %q, want global", got)
+	}
+}
+
+func TestFormatHotkeyLabelsSeparatesSequenceSteps(t *testing.T) {
+	got := strings.Join(formatHotkeyLabels("ctrl+space, g, shift+h"), " ")
+	if got != "Ctrl Space , G Shift H" {
+		t.Fatalf("sequence labels = %q", got)
+	}
+}
@@ -383,12 +383,20 @@ func pluginSettingsPluginsFromContract(items []contract.PluginCatalogItem) ([]pl
 				ID: glance.Id, Name: string(glance.Name), Description: string(glance.Description), Icon: glance.Icon, RefreshIntervalMs: glance.RefreshIntervalMs,
 			}
 		}
+		var permissions *pluginPermissions
+		if item.Permissions != nil {
+			permissions = &pluginPermissions{
+				Capabilities:   append([]string(nil), item.Permissions.Capabilities...),
+				NetworkDomains: append([]string(nil), item.Permissions.NetworkDomains...),
+				InvokePlugins:  append([]string(nil), item.Permissions.InvokePlugins...),
+			}
+		}
 		plugins[index] = pluginSettingsPlugin{
 			ID: item.ID, Name: item.Name, Description: item.Description, Author: item.Author, Website: item.Website, Version: item.Version,
 			Runtime: item.Runtime, Entry: item.Entry, PluginDirectory: item.PluginDirectory,
 			Icon:           woxImage{ImageType: item.Icon.ImageType, ImageData: item.Icon.ImageData},
 			ScreenshotURLs: append([]string(nil), item.ScreenshotURLs...), TriggerKeywords: append([]string(nil), item.TriggerKeywords...),
-			Commands: commands, SupportedOS: append([]string(nil), item.SupportedOS...), Features: features, Glances: glances,
+			Commands: commands, SupportedOS: append([]string(nil), item.SupportedOS...), Features: features, Glances: glances, Permissions: permissions,
 			IsSystem: item.IsSystem, IsDev: item.IsDev, IsInstalled: item.IsInstalled, IsDisable: item.IsDisable, IsUpgradable: item.IsUpgradable,
 			SettingDefinitions: definitions,
 			Setting: pluginSettingsData{
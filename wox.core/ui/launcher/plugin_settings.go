@@ -31,6 +31,7 @@ type pluginSettingsPlugin struct {
 	SupportedOS        []string           `json:"SupportedOS"`
 	Features           []pluginFeature    `json:"Features"`
 	Glances            []pluginGlance     `json:"Glances"`
+	Permissions        *pluginPermissions `json:"Permissions"`
 	IsSystem           bool               `json:"IsSystem"`
 	IsDev              bool               `json:"IsDev"`
 	IsInstalled        bool               `json:"IsInstalled"`
@@ -50,6 +51,12 @@ type pluginFeature struct {
 	Params map[string]any `json:"Params"`
 }
 
+type pluginPermissions struct {
+	Capabilities   []string `json:"Capabilities"`
+	NetworkDomains []string `json:"NetworkDomains"`
+	InvokePlugins  []string `json:"InvokePlugins"`
+}
+
 type filteredPlugin struct {
 	index  int
 	plugin pluginSettingsPlugin
@@ -365,20 +365,61 @@ func (a *App) pluginMetadataProps(plugin pluginSettingsPlugin, tab string) launc
 		props.DescriptionOnly = true
 		props.Description = plugin.Description
 	case "privacy":
-		accesses := pluginPrivacyAccesses(plugin.Features)
-		if len(accesses) == 0 {
+		for _, access := range pluginPrivacyAccesses(plugin.Features) {
+			props.Items = append(props.Items, launcherview.PluginMetadataItem{Title: pluginPrivacyTitle(a, access), Description: pluginPrivacyDescription(a, access)})
+		}
+		if !plugin.IsSystem {
+			props.Items = append(props.Items, a.pluginPermissionItems(plugin.Permissions)...)
+		}
+		if len(props.Items) == 0 {
 			props.EmptyTitle = a.translate("i18n:ui_plugin_no_data_access")
 			props.EmptyDescription = a.translate("i18n:ui_plugin_no_data_access_subtitle")
 			break
 		}
 		props.Header = a.translate("i18n:ui_plugin_data_access_title")
-		for _, access := range accesses {
-			props.Items = append(props.Items, launcherview.PluginMetadataItem{Title: pluginPrivacyTitle(a, access), Description: pluginPrivacyDescription(a, access)})
-		}
 	}
 	return props
 }
 
+// pluginPermissionItems lists what a plugin was granted from plugin.json. System
+// plugins are not sandboxed, so callers skip them.
+func (a *App) pluginPermissionItems(permissions *pluginPermissions) []launcherview.PluginMetadataItem {
+	if permissions == nil {
+		return []launcherview.PluginMetadataItem{{Title: a.translate("i18n:plugin_permission_legacy"), Description: a.translate("i18n:ui_plugin_permission_legacy_desc")}}
+	}
+
+	items := []launcherview.PluginMetadataItem{}
+	for _, capability := range permissions.Capabilities {
+		switch strings.ToLower(capability) {
+		case "clipboardwrite":
+			items = append(items, launcherview.PluginMetadataItem{Title: a.translate("i18n:plugin_permission_clipboard_write"), Description: a.translate("i18n:ui_plugin_permission_clipboard_write_desc")})
+		case "screenshot":
+			items = append(items, launcherview.PluginMetadataItem{Title: a.translate("i18n:plugin_permission_screenshot"), Description: a.translate("i18n:ui_plugin_permission_screenshot_desc")})
+		case "ai":
+			items = append(items, launcherview.PluginMetadataItem{Title: a.translate("i18n:plugin_permission_ai"), Description: a.translate("i18n:ui_plugin_permission_ai_desc")})
+		case "selection":
+			items = append(items, launcherview.PluginMetadataItem{Title: a.translate("i18n:plugin_permission_selection"), Description: a.translate("i18n:ui_plugin_permission_selection_desc")})
+		case "globalhotkey":
+			items = append(items, launcherview.PluginMetadataItem{Title: a.translate("i18n:plugin_permission_global_hotkey"), Description: a.translate("i18n:ui_plugin_permission_global_hotkey_desc")})
+		default:
+			items = append(items, launcherview.PluginMetadataItem{Title: capability})
+		}
+	}
+	if len(permissions.NetworkDomains) > 0 {
+		items = append(items, launcherview.PluginMetadataItem{
+			Title:       fmt.Sprintf(a.translate("i18n:plugin_permission_network"), strings.Join(permissions.NetworkDomains, ", ")),
+			Description: a.translate("i18n:ui_plugin_permission_network_desc"),
+		})
+	}
+	if len(permissions.InvokePlugins) > 0 {
+		items = append(items, launcherview.PluginMetadataItem{
+			Title:       fmt.Sprintf(a.translate("i18n:plugin_permission_invoke_plugins"), strings.Join(permissions.InvokePlugins, ", ")),
+			Description: a.translate("i18n:ui_plugin_permission_invoke_plugins_desc"),
+		})
+	}
+	return items
+}
+
 func pluginPrivacyAccesses(features []pluginFeature) []string {
 	accessSet := map[string]bool{}
 	for _, feature := range features {
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"wox/cloudsync"
 	"wox/ui/contract"
 	woxui "wox/ui/runtime"
 	woxwidget "wox/ui/widget"
@@ -78,8 +79,12 @@ type settingsData struct {
 	HideGlanceIcon                     bool
 	AIProviders                        json.RawMessage
 	AIMCPServers                       json.RawMessage
+	AIToolApprovalRules                json.RawMessage
+	MCPServerClients                   json.RawMessage
 	AISkills                           json.RawMessage
 	CloudSyncDisabledPlugins           []string
+	CloudSyncDisabledEntities          []string
+	CloudSyncBackend                   cloudsync.CloudSyncBackendConfig
 	ShowScoreTail                      bool
 	ShowPerformanceTail                bool
 	ShowPerformanceTailBatch           bool
@@ -99,6 +104,7 @@ type queryHotkeySetting struct {
 	MaxResultCount    int
 	Position          string
 	Disabled          bool
+	App               ignoredHotkeyApp
 }
 
 type queryShortcutSetting struct {
@@ -393,6 +399,8 @@ func (a *App) openSettings(windowContext settingWindowContext) error {
 	}
 	if tab == "ai" {
 		util.Go(a.lifecycleCtx, "load AI provider catalog", a.loadAIProviderCatalog)
+		// Refresh MCP server health every time the tab opens so the status column is current.
+		util.Go(a.lifecycleCtx, "load AI MCP catalog", a.loadAIMCPCatalog)
 	}
 	if tab == "appearance" {
 		util.Go(a.lifecycleCtx, "load glance catalog", a.loadGlanceCatalog)
@@ -555,6 +563,14 @@ func settingsDataFromContract(loaded contract.GeneralSettings) (settingsData, er
 	if err != nil {
 		return settingsData{}, fmt.Errorf("encode AI MCP servers: %w", err)
 	}
+	toolApprovalRules, err := json.Marshal(loaded.AIToolApprovalRules)
+	if err != nil {
+		return settingsData{}, fmt.Errorf("encode AI tool approval rules: %w", err)
+	}
+	mcpServerClients, err := json.Marshal(loaded.MCPServerClients)
+	if err != nil {
+		return settingsData{}, fmt.Errorf("encode MCP server clients: %w", err)
+	}
 	aiSkills, err := json.Marshal(loaded.AISkills)
 	if err != nil {
 		return settingsData{}, fmt.Errorf("encode AI skills: %w", err)
@@ -566,6 +582,7 @@ func settingsDataFromContract(loaded contract.GeneralSettings) (settingsData, er
 			Name: item.Name, Hotkey: item.Hotkey, Query: item.Query, IsSilentExecution: item.IsSilentExecution,
 			HideQueryBox: item.HideQueryBox, HideToolbar: item.HideToolbar, Width: item.Width,
 			MaxResultCount: item.MaxResultCount, Position: string(item.Position), Disabled: item.Disabled,
+			App: ignoredHotkeyApp{Name: item.App.Name, Identity: item.App.Identity, Path: item.App.Path, Icon: fromCoreImage(item.App.Icon)},
 		}
 	}
 	queryShortcuts := make([]queryShortcutSetting, len(loaded.QueryShortcuts))
@@ -613,8 +630,12 @@ func settingsDataFromContract(loaded contract.GeneralSettings) (settingsData, er
 		HideGlanceIcon:                     loaded.HideGlanceIcon,
 		AIProviders:                        aiProviders,
 		AIMCPServers:                       mcpServers,
+		AIToolApprovalRules:                toolApprovalRules,
+		MCPServerClients:                   mcpServerClients,
 		AISkills:                           aiSkills,
 		CloudSyncDisabledPlugins:           append([]string(nil), loaded.CloudSyncDisabledPlugins...),
+		CloudSyncDisabledEntities:          append([]string(nil), loaded.CloudSyncDisabledEntities...),
+		CloudSyncBackend:                   loaded.CloudSyncBackend,
 		ShowScoreTail:                      loaded.ShowScoreTail,
 		ShowPerformanceTail:                loaded.ShowPerformanceTail,
 		ShowPerformanceTailBatch:           loaded.ShowPerformanceTailBatch,
@@ -896,6 +917,8 @@ func (a *App) selectSettingTab(tab string) {
 	}
 	if loadAIProviders {
 		util.Go(a.lifecycleCtx, "load AI provider catalog", a.loadAIProviderCatalog)
+		// Refresh MCP server health every time the tab opens so the status column is current.
+		util.Go(a.lifecycleCtx, "load AI MCP catalog", a.loadAIMCPCatalog)
 	}
 	if loadGlanceCatalog {
 		util.Go(a.lifecycleCtx, "load glance catalog", a.loadGlanceCatalog)
@@ -43,8 +43,10 @@ var builtInSettingSearchAliases = map[string][]string{
 	"AppWidth":                  {"width"},
 	"AppFontFamily":             {"font"},
 	"EnableGlance":              {"glance"},
-	"AIProviders":               {"ai provider", "api key", "model"},
-	"AIMCPServers":              {"mcp", "tool", "server"},
+	"AIProviders":               {"ai provider", "api key", "model", "embedding", "semantic"},
+	"AIMCPServers":              {"mcp", "tool", "server", "prompt", "resource", "sse", "header"},
+	"AIToolApprovalRules":       {"tool", "approval", "permission", "allow", "deny", "bash"},
+	"MCPServerClients":          {"mcp", "server", "client", "token", "agent", "editor"},
 	"AISkills":                  {"skill", "repo", "path"},
 	"HttpProxyEnabled":          {"proxy"},
 	"HttpProxyUrl":              {"proxy url"},
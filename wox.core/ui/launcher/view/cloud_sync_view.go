@@ -15,6 +15,8 @@ type CloudSettingsPageProps struct {
 	Intro        CloudIntroProps
 	Account      CloudAccountProps
 	Sync         CloudSyncProps
+	DataTypes    CloudDataTypesProps
+	Backend      CloudBackendProps
 	Devices      CloudDevicesProps
 	Plugins      CloudPluginExclusionsProps
 	ConfigNotes  CloudConfigNotesProps
@@ -93,6 +95,21 @@ type CloudSyncProps struct {
 	OnSync        func()
 }
 
+// CloudBackendProps contains the storage backend summary. Self-hosted backends
+// replace the account sections because they need no Wox account.
+type CloudBackendProps struct {
+	SectionLabel  string
+	StatusLabel   string
+	LabelWidth    float32
+	Label         string
+	Detail        string
+	Color         woxui.Color
+	ButtonLabel   string
+	ButtonEnabled bool
+	SelfHosted    bool
+	OnChange      func()
+}
+
 // CloudDevicesProps contains device rows and refresh state.
 type CloudDevicesProps struct {
 	SectionLabel   string
@@ -118,6 +135,23 @@ type CloudDeviceProps struct {
 	OnRevoke      func()
 }
 
+// CloudDataTypesProps contains the per-entity opt-out switches for synced user data.
+type CloudDataTypesProps struct {
+	SectionLabel string
+	Tips         string
+	Items        []CloudDataTypeProps
+}
+
+// CloudDataTypeProps contains one data type switch. Enabled means the type is synced.
+type CloudDataTypeProps struct {
+	ID          string
+	Label       string
+	Description string
+	Enabled     bool
+	Disabled    bool
+	OnToggle    func()
+}
+
 // CloudPluginExclusionsProps contains plugin exclusion rows and scrolling state.
 type CloudPluginExclusionsProps struct {
 	SectionLabel   string
@@ -212,20 +246,37 @@ func CloudSettingsPage(props CloudSettingsPageProps) woxwidget.Widget {
 	appendChild(woxcomponent.WoxPageHeader(woxcomponent.PageHeaderProps{
 		Title: props.Title, Description: props.Description, Width: contentWidth, Theme: props.Theme,
 	}))
-	if !props.Account.LoggedIn {
-		appendChild(woxcomponent.WoxSectionHeader(woxcomponent.SectionHeaderProps{Label: props.Intro.SectionLabel, Width: contentWidth, Theme: props.Theme}))
-		appendChild(cloudIntro(props.Intro, contentWidth, props.Theme))
-	}
-	appendChild(woxcomponent.WoxSectionHeader(woxcomponent.SectionHeaderProps{Label: props.Account.SectionLabel, Width: contentWidth, Theme: props.Theme}))
-	accountHeight := float32(62)
-	if props.Account.LoggedIn {
-		accountHeight = 162
+	appendBackend := func() {
+		appendChild(woxcomponent.WoxSectionHeader(woxcomponent.SectionHeaderProps{Label: props.Backend.SectionLabel, Width: contentWidth, Theme: props.Theme}))
+		appendChild(cloudBackendCard(props.Backend, contentWidth, props.Theme))
+	}
+	if props.Backend.SelfHosted {
+		appendBackend()
+	} else {
+		if !props.Account.LoggedIn {
+			appendChild(woxcomponent.WoxSectionHeader(woxcomponent.SectionHeaderProps{Label: props.Intro.SectionLabel, Width: contentWidth, Theme: props.Theme}))
+			appendChild(cloudIntro(props.Intro, contentWidth, props.Theme))
+		}
+		appendChild(woxcomponent.WoxSectionHeader(woxcomponent.SectionHeaderProps{Label: props.Account.SectionLabel, Width: contentWidth, Theme: props.Theme}))
+		accountHeight := float32(62)
+		if props.Account.LoggedIn {
+			accountHeight = 162
+		}
+		appendChild(cloudAccountCard(props.Account, contentWidth, accountHeight, props.Theme))
 	}
-	appendChild(cloudAccountCard(props.Account, contentWidth, accountHeight, props.Theme))
 
-	if props.Account.LoggedIn {
+	if props.Account.LoggedIn || props.Backend.SelfHosted {
 		appendChild(woxcomponent.WoxSectionHeader(woxcomponent.SectionHeaderProps{Label: props.Sync.SectionLabel, Width: contentWidth, Theme: props.Theme}))
 		appendChild(cloudSyncCard(props.Sync, contentWidth, props.Theme))
+		if len(props.DataTypes.Items) > 0 {
+			appendChild(woxcomponent.WoxSectionHeader(woxcomponent.SectionHeaderProps{Label: props.DataTypes.SectionLabel, Width: contentWidth, Theme: props.Theme}))
+			if props.DataTypes.Tips != "" {
+				appendChild(woxwidget.TextBlock{Value: props.DataTypes.Tips, Width: contentWidth, Height: 34, MaxLines: 2, Style: woxui.TextStyle{Size: 11}, LineHeight: 16, Color: props.Theme.ResultSubtitle})
+			}
+			for _, item := range props.DataTypes.Items {
+				appendChild(cloudDataTypeField(item, contentWidth, props.Theme))
+			}
+		}
 		appendChild(woxcomponent.WoxSectionHeader(woxcomponent.SectionHeaderProps{Label: props.Devices.SectionLabel, Width: contentWidth, Theme: props.Theme}))
 		appendChild(cloudDeviceHeader(props.Devices, contentWidth, props.Theme))
 		deviceHeight := float32(len(props.Devices.Items)) * 56
@@ -240,6 +291,10 @@ func CloudSettingsPage(props CloudSettingsPageProps) woxwidget.Widget {
 		configHeight := FormTableFieldHeight(true, props.ConfigNotes.Tips, len(props.ConfigNotes.Items), 720)
 		appendChild(cloudConfigNotesCard(props.ConfigNotes, contentWidth, configHeight, props.Theme))
 	}
+	// The Wox account layout keeps the backend switch last so the account menus stay anchored.
+	if !props.Backend.SelfHosted {
+		appendBackend()
+	}
 	if props.Message != "" {
 		appendChild(woxwidget.Container{Width: contentWidth, Height: 34, Padding: woxwidget.Insets{Top: 9}, Child: woxwidget.TextBlock{
 			Value: props.Message, Width: contentWidth, Height: 22, MaxLines: 1, Style: woxui.TextStyle{Size: 10}, Color: props.MessageColor,
@@ -547,25 +602,50 @@ func cloudValueAction(id, value string, width float32, onTap func(), theme woxco
 
 // cloudSyncCard renders current sync state and its primary action.
 func cloudSyncCard(props CloudSyncProps, width float32, theme woxcomponent.Theme) woxwidget.Widget {
+	button := woxcomponent.WoxButton(woxcomponent.ButtonProps{ID: "cloud-sync", Label: props.ButtonLabel, Disabled: !props.ButtonEnabled, Variant: woxcomponent.ButtonOutline, OnTap: props.OnSync, Theme: theme})
+	return cloudStatusRow(props.StatusLabel, props.Label, props.Detail, props.Color, props.LabelWidth, props.ButtonLabel, button, width, theme)
+}
+
+// cloudDataTypeField renders one data type row with its sync switch.
+func cloudDataTypeField(item CloudDataTypeProps, width float32, theme woxcomponent.Theme) woxwidget.Widget {
+	return woxcomponent.WoxSettingField(woxcomponent.SettingFieldProps{
+		Label: item.Label, Description: item.Description, Width: width, Height: woxcomponent.SettingsRowHeight,
+		Gap: 12, Padding: woxwidget.Insets{Top: 5}, Theme: theme,
+		Child: woxwidget.Align{Width: woxcomponent.SettingsSwitchWidth, Height: woxcomponent.SettingsControlHeight, Horizontal: 1, Vertical: 0.5, Child: woxcomponent.WoxSwitch(woxcomponent.SwitchProps{
+			ID: "cloud-data-type-" + item.ID, Label: item.Label, Value: item.Enabled, Disabled: item.Disabled, OnChange: func(bool) {
+				if item.OnToggle != nil {
+					item.OnToggle()
+				}
+			}, Theme: theme,
+		})},
+	})
+}
+
+func cloudBackendCard(props CloudBackendProps, width float32, theme woxcomponent.Theme) woxwidget.Widget {
+	button := woxcomponent.WoxButton(woxcomponent.ButtonProps{ID: "cloud-backend", Label: props.ButtonLabel, Disabled: !props.ButtonEnabled, Variant: woxcomponent.ButtonOutline, OnTap: props.OnChange, Theme: theme})
+	return cloudStatusRow(props.StatusLabel, props.Label, props.Detail, props.Color, props.LabelWidth, props.ButtonLabel, button, width, theme)
+}
+
+// cloudStatusRow lays out a titled status line with one trailing action button.
+func cloudStatusRow(title, label, detail string, color woxui.Color, preferredLabelWidth float32, buttonLabel string, button woxwidget.Widget, width float32, theme woxcomponent.Theme) woxwidget.Widget {
 	const labelGap = float32(32)
 	// Reserve label space from an estimate only; the button itself sizes to its label.
-	buttonWidth := cloudFormButtonWidth(props.ButtonLabel, false)
+	buttonWidth := cloudFormButtonWidth(buttonLabel, false)
 	availableWidth := max(float32(0), width)
-	labelWidth := min(props.LabelWidth, max(float32(220), availableWidth-labelGap-buttonWidth))
+	labelWidth := min(preferredLabelWidth, max(float32(220), availableWidth-labelGap-buttonWidth))
 	if labelWidth <= 0 {
 		labelWidth = max(float32(220), width-260)
 	}
 	valueWidth := max(buttonWidth, availableWidth-labelWidth-labelGap)
-	button := woxcomponent.WoxButton(woxcomponent.ButtonProps{ID: "cloud-sync", Label: props.ButtonLabel, Disabled: !props.ButtonEnabled, Variant: woxcomponent.ButtonOutline, OnTap: props.OnSync, Theme: theme})
-	statusLine := props.Label
-	if props.Detail != "" {
-		statusLine += ", " + props.Detail
+	statusLine := label
+	if detail != "" {
+		statusLine += ", " + detail
 	}
 	return woxwidget.Container{Width: width, Height: cloudSyncCardHeight, Child: woxwidget.Flex{
 		Axis: woxwidget.Horizontal, Gap: labelGap, Children: []woxwidget.Widget{
 			woxwidget.Container{Width: labelWidth, Height: 50, Child: woxwidget.Flex{Axis: woxwidget.Vertical, Gap: 4, Children: []woxwidget.Widget{
-				woxwidget.Text{Value: props.StatusLabel, Style: woxui.TextStyle{Size: 13, Weight: woxui.FontWeightSemibold}, Color: theme.ResultTitle},
-				woxwidget.TextBlock{Value: statusLine, Width: labelWidth, Height: 24, MaxLines: 1, Style: woxui.TextStyle{Size: 12}, LineHeight: 17, Color: props.Color},
+				woxwidget.Text{Value: title, Style: woxui.TextStyle{Size: 13, Weight: woxui.FontWeightSemibold}, Color: theme.ResultTitle},
+				woxwidget.TextBlock{Value: statusLine, Width: labelWidth, Height: 24, MaxLines: 1, Style: woxui.TextStyle{Size: 12}, LineHeight: 17, Color: color},
 			}}},
 			woxwidget.Align{Width: valueWidth, Height: 57, Horizontal: 1, Child: button},
 		},
@@ -348,8 +348,13 @@ func chatCatalogItemWithDeleteState(item ChatCatalogItemProps, width, height flo
 		}
 		titleWidth := min(float32(220), max(float32(100), width*0.42))
 		icon := woxcomponent.ModelTrainingGlyph(18, iconColor)
-		if item.Kind == "skills" {
+		switch item.Kind {
+		case "skills":
 			icon = woxcomponent.ExtensionGlyph(18, iconColor)
+		case "prompts":
+			icon = woxcomponent.TerminalGlyph(18, iconColor)
+		case "resources":
+			icon = woxcomponent.ArticleGlyph(18, iconColor)
 		}
 		return woxwidget.Gesture{ID: item.SelectID, OnTap: item.OnSelect, OnHover: onHover, Child: woxwidget.Container{
 			Width: width, Height: height, Color: background, Child: woxwidget.Stack{Width: width, Height: height, Children: []woxwidget.StackChild{
@@ -1143,20 +1148,38 @@ type ChatQuestionInputProps struct {
 
 // ChatQuestionProps contains the typed ask-user options and actions.
 type ChatQuestionProps struct {
+	ID       string
 	Width    float32
 	Height   float32
 	Question string
-	Options  []ChatQuestionOptionProps
-	Input    *ChatQuestionInputProps
-	Theme    woxcomponent.Theme
-	OnCancel func()
-	OnSubmit func()
+	// QuestionLayout wraps the whole question. Tool approvals put the command
+	// being approved here, so it scrolls instead of being cut off.
+	QuestionLayout woxwidget.TextBlockLayout
+	Options        []ChatQuestionOptionProps
+	Input          *ChatQuestionInputProps
+	Theme          woxcomponent.Theme
+	OnCancel       func()
+	OnSubmit       func()
 }
 
+// ChatQuestionTextStyle and ChatQuestionLineHeight let the adapter measure the question like the panel paints it.
+var ChatQuestionTextStyle = woxui.TextStyle{Size: 12, Weight: woxui.FontWeightSemibold}
+
+const ChatQuestionLineHeight = float32(17)
+
+// ChatQuestionTextHorizontalPadding is the panel padding the question text is wrapped inside.
+const ChatQuestionTextHorizontalPadding = float32(24)
+
 // ChatQuestion builds the inline ask-user panel.
 func ChatQuestion(props ChatQuestionProps) woxwidget.Widget {
-	innerWidth := max(float32(0), props.Width-24)
-	children := []woxwidget.Widget{woxwidget.Container{Width: innerWidth, Height: 34, Child: woxwidget.TextBlock{Value: props.Question, Width: innerWidth, Height: 34, MaxLines: 2, Style: woxui.TextStyle{Size: 12, Weight: woxui.FontWeightSemibold}, LineHeight: 17, Color: props.Theme.PreviewText}}}
+	innerWidth := max(float32(0), props.Width-ChatQuestionTextHorizontalPadding)
+	questionHeight := chatQuestionTextHeight(props)
+	question := woxcomponent.WoxScrollView(woxcomponent.ScrollViewProps{
+		Key: woxwidget.Key("chat-question-scroll-" + props.ID), Width: innerWidth, Height: questionHeight, ContentHeight: props.QuestionLayout.Size.Height,
+		Content:    woxwidget.TextBlock{Value: props.Question, Width: innerWidth, Height: props.QuestionLayout.Size.Height, Style: ChatQuestionTextStyle, LineHeight: ChatQuestionLineHeight, Color: props.Theme.PreviewText, Layout: &props.QuestionLayout},
+		ThumbColor: props.Theme.PreviewText,
+	})
+	children := []woxwidget.Widget{woxwidget.Container{Width: innerWidth, Height: questionHeight, Child: question}}
 	for _, option := range props.Options {
 		background := props.Theme.QueryBackground
 		if option.Selected {
@@ -1187,3 +1210,18 @@ func ChatQuestion(props ChatQuestionProps) woxwidget.Widget {
 		Width: innerWidth, Height: max(float32(0), props.Height-16), Child: woxwidget.Flex{Axis: woxwidget.Vertical, Gap: 6, Children: children},
 	}}
 }
+
+// chatQuestionTextHeight gives the question whatever the options, input and
+// buttons leave of the panel, and at least the two lines it always had.
+func chatQuestionTextHeight(props ChatQuestionProps) float32 {
+	const gap = float32(6)
+	used := float32(16) + 32
+	for range props.Options {
+		used += 40 + gap
+	}
+	if props.Input != nil {
+		used += props.Input.Height + gap
+	}
+	available := props.Height - used - gap
+	return max(ChatQuestionLineHeight*2, min(props.QuestionLayout.Size.Height, available))
+}
@@ -326,3 +326,15 @@ func TestChatCatalogItemOnlyShowsCheckForCurrentModel(t *testing.T) {
 		t.Fatalf("current model check slot = width %.0f, child %#v; want check glyph", currentCheck.Width, currentCheck.Child)
 	}
 }
+
+func TestChatQuestionScrollsLongQuestions(t *testing.T) {
+	options := []ChatQuestionOptionProps{{ID: "allow"}, {ID: "deny"}}
+	long := ChatQuestionProps{Height: 200, Options: options, QuestionLayout: woxwidget.TextBlockLayout{Size: woxui.Size{Height: 400}}}
+	if height := chatQuestionTextHeight(long); height != 54 {
+		t.Fatalf("long question height = %.0f, want the 54 left by options and buttons", height)
+	}
+	short := ChatQuestionProps{Height: 200, Options: options, QuestionLayout: woxwidget.TextBlockLayout{Size: woxui.Size{Height: 17}}}
+	if height := chatQuestionTextHeight(short); height != 34 {
+		t.Fatalf("short question height = %.0f, want two lines", height)
+	}
+}
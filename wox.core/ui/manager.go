@@ -19,11 +19,13 @@ import (
 	"sync/atomic"
 	"time"
 	"wox/account"
+	"wox/ai"
 	"wox/analytics"
 	"wox/common"
 	"wox/diagnostic"
 	corehotkey "wox/hotkey"
 	"wox/i18n"
+	"wox/mcpserver"
 	"wox/plugin"
 	dictationplugin "wox/plugin/system/dictation"
 	"wox/plugin/system/shell/terminal"
@@ -108,6 +110,18 @@ func GetUIManager() *Manager {
 			OnDictationPressAction: func(ctx context.Context, actionID string) {
 				managerInstance.handleDictationHotkeyPressAction(ctx, actionID)
 			},
+			OnPlugin: func(combineKey string, pluginID string, hotkeyID string) {
+				managerInstance.handlePluginHotkeyTrigger(combineKey, pluginID, hotkeyID)
+			},
+			ActiveAppIdentity: func() string {
+				return managerInstance.activeHotkeyAppIdentity()
+			},
+			OnSequenceStart: func(leader string) bool {
+				return managerInstance.handleHotkeySequenceStart(leader)
+			},
+			OnSequenceProgress: func(progress utilhotkey.SequenceProgress) {
+				managerInstance.showHotkeySequenceProgress(progress)
+			},
 		})
 		managerInstance.ui = &uiImpl{
 			isVisible:       false, // Initially hidden
@@ -126,6 +140,7 @@ func GetUIManager() *Manager {
 		// Inject the UI Manager as the dictation hotkey registrar to break the
 		// import cycle between ui and plugin/system/dictation.
 		dictationplugin.SetHotkeyRegistrar(managerInstance)
+		plugin.SetPluginHotkeyRegistrar(managerInstance)
 	})
 	return managerInstance
 }
@@ -212,6 +227,11 @@ func (m *Manager) CollectDictationHotkeys(ctx context.Context, bindings []coreho
 	}
 }
 
+// UpdatePluginHotkeys implements the plugin.PluginHotkeyRegistrar interface.
+func (m *Manager) UpdatePluginHotkeys(ctx context.Context, pluginID string, bindings []corehotkey.PluginBinding) (map[string]error, error) {
+	return m.hotkeyService.UpdatePluginBindings(ctx, pluginID, bindings)
+}
+
 // CollectWoxSettingHotkeys collects startup Wox-setting hotkeys before plugins load.
 func (m *Manager) CollectWoxSettingHotkeys(ctx context.Context, woxSetting *setting.WoxSetting) {
 	m.hotkeyService.CollectWoxSettings(ctx, woxSetting)
@@ -470,6 +490,18 @@ func (m *Manager) handleQueryHotkeyTrigger(combineKey string, queryHotkey settin
 	}
 }
 
+func (m *Manager) handlePluginHotkeyTrigger(combineKey string, pluginID string, hotkeyID string) {
+	ctx := util.NewTraceContext()
+	logger.Info(ctx, fmt.Sprintf("plugin hotkey callback received: hotkey=%s plugin=%s id=%s recordingActive=%t", combineKey, pluginID, hotkeyID, m.isHotkeyRecordingActive()))
+	if m.recordHotkeyIfRecording(ctx, combineKey) {
+		return
+	}
+	if m.shouldIgnoreHotkeyTrigger(ctx) {
+		return
+	}
+	plugin.GetPluginManager().TriggerPluginHotkey(ctx, pluginID, hotkeyID)
+}
+
 // QuerySelection captures the current text selection and opens a Wox query for it.
 func (m *Manager) QuerySelection(ctx context.Context) {
 	newCtx := util.NewTraceContext()
@@ -559,7 +591,7 @@ func (m *Manager) triggerQueryHotkey(ctx context.Context, queryHotkey setting.Qu
 	// Preview-only hotkeys (e.g. webview panels) reuse the same chrome as selection quick look.
 	if queryHotkey.HideQueryBox && queryHotkey.HideToolbar {
 		showContext.ShowPreviewTitleBar = true
-		m.openSecondaryInstance(queryCtx, "query-hotkey:"+normalizeHotkeyForCompare(queryHotkey.Hotkey), plainQuery, showContext)
+		m.openSecondaryInstance(queryCtx, "query-hotkey:"+strings.Join(hotkeyCompareSteps(queryHotkey.Hotkey), ","), plainQuery, showContext)
 		return nil
 	}
 
@@ -635,17 +667,39 @@ const (
 	hotkeyConflictTypeSelection = "selection"
 	hotkeyConflictTypeQuery     = "query"
 	hotkeyConflictTypeDictation = "dictation"
+	hotkeyConflictTypePlugin    = "plugin"
 	hotkeyConflictTypeSystem    = "system"
+	// hotkeyConflictTypeAppScope rejects an app-scoped hotkey whose chord no
+	// global hotkey handles, because the grabbed chord would be swallowed in
+	// every other app.
+	hotkeyConflictTypeAppScope = "app_scope"
 )
 
 // CheckHotkeyAvailability checks Wox-owned settings before probing the platform registry.
-func (m *Manager) CheckHotkeyAvailability(ctx context.Context, hotkeyStr string) HotkeyAvailability {
-	if conflict := m.findConfiguredHotkeyConflict(ctx, hotkeyStr); conflict.ConflictType != "" {
-		logger.Info(ctx, fmt.Sprintf("hotkey availability check: hotkey=%s available=false reason=wox_setting conflictType=%s conflictValue=%s", hotkeyStr, conflict.ConflictType, conflict.ConflictValue))
+// appIdentity is the app a query hotkey is scoped to, empty for global hotkeys.
+func (m *Manager) CheckHotkeyAvailability(ctx context.Context, hotkeyStr string, appIdentity string) HotkeyAvailability {
+	if conflict := m.findConfiguredHotkeyConflict(ctx, hotkeyStr, appIdentity); conflict.ConflictType != "" {
+		logger.Info(ctx, fmt.Sprintf("hotkey availability check: hotkey=%s app=%s available=false reason=wox_setting conflictType=%s conflictValue=%s", hotkeyStr, appIdentity, conflict.ConflictType, conflict.ConflictValue))
 		return conflict
 	}
 
-	isAvailable := utilhotkey.IsHotkeyAvailable(ctx, hotkeyStr)
+	// Only the first chord of a sequence is registered with the platform. When
+	// Wox already owns that chord for another scope or sequence, probing it would
+	// report Wox itself as the conflicting application.
+	probeHotkey := hotkeyStr
+	if steps := utilhotkey.SplitSequence(hotkeyStr); len(steps) > 0 {
+		probeHotkey = steps[0]
+	}
+	if strings.TrimSpace(appIdentity) != "" && !m.isWoxOwnedHotkeyChord(probeHotkey, true) {
+		logger.Info(ctx, fmt.Sprintf("hotkey availability check: hotkey=%s app=%s available=false reason=no_global_chord", hotkeyStr, appIdentity))
+		return HotkeyAvailability{Available: false, ConflictType: hotkeyConflictTypeAppScope}
+	}
+	if m.isWoxOwnedHotkeyChord(probeHotkey, false) {
+		logger.Info(ctx, fmt.Sprintf("hotkey availability check: hotkey=%s app=%s available=true reason=wox_owned_chord", hotkeyStr, appIdentity))
+		return HotkeyAvailability{Available: true}
+	}
+
+	isAvailable := utilhotkey.IsHotkeyAvailable(ctx, probeHotkey)
 	logger.Info(ctx, fmt.Sprintf("hotkey availability check: hotkey=%s available=%t reason=platform_probe", hotkeyStr, isAvailable))
 	if !isAvailable {
 		return HotkeyAvailability{Available: false, ConflictType: hotkeyConflictTypeSystem}
@@ -655,39 +709,49 @@ func (m *Manager) CheckHotkeyAvailability(ctx context.Context, hotkeyStr string)
 
 // IsHotkeyAvailable keeps the existing bool endpoint compatible with callers that only need availability.
 func (m *Manager) IsHotkeyAvailable(ctx context.Context, hotkeyStr string) bool {
-	return m.CheckHotkeyAvailability(ctx, hotkeyStr).Available
+	return m.CheckHotkeyAvailability(ctx, hotkeyStr, "").Available
 }
 
 // findConfiguredHotkeyConflict keeps availability checks aligned with Wox-owned hotkey settings.
-func (m *Manager) findConfiguredHotkeyConflict(ctx context.Context, hotkeyStr string) HotkeyAvailability {
-	candidateKeys := hotkeyCompareKeys(hotkeyStr)
-	if len(candidateKeys) == 0 {
+// Hotkeys only conflict when their app scopes overlap, because a hotkey scoped to
+// an app shadows the global binding of the same keys inside that app.
+func (m *Manager) findConfiguredHotkeyConflict(ctx context.Context, hotkeyStr string, appIdentity string) HotkeyAvailability {
+	if len(hotkeyCompareSteps(hotkeyStr)) == 0 {
 		return HotkeyAvailability{Available: true}
 	}
+	appIdentity = strings.TrimSpace(appIdentity)
 
 	woxSetting := setting.GetSettingManager().GetWoxSetting(ctx)
-	if hotkeyCompareKeysIntersect(candidateKeys, hotkeyCompareKeys(woxSetting.MainHotkey.Get())) {
-		return HotkeyAvailability{Available: false, ConflictType: hotkeyConflictTypeMain}
-	}
-	if hotkeyCompareKeysIntersect(candidateKeys, hotkeyCompareKeys(corehotkey.EffectiveSelectionHotkeyForRuntime(woxSetting.SelectionHotkey.Get()))) {
-		return HotkeyAvailability{Available: false, ConflictType: hotkeyConflictTypeSelection}
+	if appIdentity == "" {
+		if hotkeysConflict(hotkeyStr, woxSetting.MainHotkey.Get()) {
+			return HotkeyAvailability{Available: false, ConflictType: hotkeyConflictTypeMain}
+		}
+		if hotkeysConflict(hotkeyStr, corehotkey.EffectiveSelectionHotkeyForRuntime(woxSetting.SelectionHotkey.Get())) {
+			return HotkeyAvailability{Available: false, ConflictType: hotkeyConflictTypeSelection}
+		}
 	}
 
 	for _, queryHotkey := range woxSetting.QueryHotkeys.Get() {
-		if queryHotkey.Disabled {
+		if queryHotkey.Disabled || !strings.EqualFold(strings.TrimSpace(queryHotkey.App.Identity), appIdentity) {
 			continue
 		}
-		if hotkeyCompareKeysIntersect(candidateKeys, hotkeyCompareKeys(queryHotkey.Hotkey)) {
+		if hotkeysConflict(hotkeyStr, queryHotkey.Hotkey) {
 			return HotkeyAvailability{Available: false, ConflictType: hotkeyConflictTypeQuery, ConflictValue: queryHotkey.DisplayName()}
 		}
 	}
 
-	// Dictation hotkeys are collected into the same collector; check all
-	// entries for conflicts by source.
-	for _, entry := range m.hotkeyService.Snapshot() {
-		if entry.Source == corehotkey.SourceDictation {
-			if hotkeyCompareKeysIntersect(candidateKeys, hotkeyCompareKeys(entry.CombineKey)) {
+	// Dictation and plugin hotkeys are collected into the same collector; check
+	// all entries for conflicts by source.
+	if appIdentity == "" {
+		for _, entry := range m.hotkeyService.Snapshot() {
+			if !hotkeysConflict(hotkeyStr, entry.CombineKey) {
+				continue
+			}
+			switch entry.Source {
+			case corehotkey.SourceDictation:
 				return HotkeyAvailability{Available: false, ConflictType: hotkeyConflictTypeDictation, ConflictValue: entry.ID}
+			case corehotkey.SourcePlugin:
+				return HotkeyAvailability{Available: false, ConflictType: hotkeyConflictTypePlugin, ConflictValue: pluginHotkeyConflictValue(ctx, entry)}
 			}
 		}
 	}
@@ -695,25 +759,64 @@ func (m *Manager) findConfiguredHotkeyConflict(ctx context.Context, hotkeyStr st
 	return HotkeyAvailability{Available: true}
 }
 
-func hotkeyCompareKeys(hotkeyStr string) map[string]bool {
-	normalized := normalizeHotkeyForCompare(hotkeyStr)
-	if normalized == "" {
-		return map[string]bool{}
+// pluginHotkeyConflictValue names a plugin hotkey as "Plugin: Hotkey name".
+func pluginHotkeyConflictValue(ctx context.Context, entry corehotkey.Entry) string {
+	pluginID, _, _ := strings.Cut(entry.ID, "/")
+	if pluginInstance := plugin.GetPluginManager().GetPluginInstanceById(pluginID); pluginInstance != nil {
+		return pluginInstance.GetName(ctx) + ": " + entry.Label
 	}
-
-	keys := map[string]bool{normalized: true}
-	return keys
+	return entry.Label
 }
 
-func hotkeyCompareKeysIntersect(left map[string]bool, right map[string]bool) bool {
-	for key := range left {
-		if right[key] {
+// isWoxOwnedHotkeyChord reports whether a collected hotkey already registers
+// chord. globalOnly ignores hotkeys that are scoped to an app.
+func (m *Manager) isWoxOwnedHotkeyChord(chord string, globalOnly bool) bool {
+	compared := normalizeHotkeyForCompare(chord)
+	if compared == "" {
+		return false
+	}
+	for _, entry := range m.hotkeyService.Snapshot() {
+		if globalOnly && len(entry.Apps) > 0 {
+			continue
+		}
+		if steps := hotkeyCompareSteps(entry.CombineKey); len(steps) > 0 && steps[0] == compared {
 			return true
 		}
 	}
 	return false
 }
 
+// hotkeysConflict reports whether two hotkeys can't be told apart when typed:
+// the same chord, or one sequence being a prefix of the other. Sequences that
+// only share their leader are fine, the next key decides between them.
+func hotkeysConflict(left, right string) bool {
+	leftSteps := hotkeyCompareSteps(left)
+	rightSteps := hotkeyCompareSteps(right)
+	if len(leftSteps) == 0 || len(rightSteps) == 0 {
+		return false
+	}
+	for index := 0; index < len(leftSteps) && index < len(rightSteps); index++ {
+		if leftSteps[index] != rightSteps[index] {
+			return false
+		}
+	}
+	return true
+}
+
+// hotkeyCompareSteps normalizes every chord of a hotkey or hotkey sequence.
+func hotkeyCompareSteps(hotkeyStr string) []string {
+	chords := utilhotkey.SplitSequence(hotkeyStr)
+	steps := make([]string, 0, len(chords))
+	for _, chord := range chords {
+		normalized := normalizeHotkeyForCompare(chord)
+		if normalized == "" {
+			return nil
+		}
+		steps = append(steps, normalized)
+	}
+	return steps
+}
+
 // normalizeHotkeyForCompare canonicalizes common aliases so stored settings and recorder output compare consistently.
 func normalizeHotkeyForCompare(hotkeyStr string) string {
 	tokens := []string{}
@@ -1404,6 +1507,8 @@ func (m *Manager) PostSettingUpdate(ctx context.Context, key string, value strin
 		if chater := plugin.GetPluginManager().GetAIChatPluginChater(ctx); chater != nil {
 			chater.EnsureDefaultModelValid(ctx)
 		}
+		// The embedding model may have changed.
+		ai.GetSemanticIndex().Refresh()
 	case "AIMCPServers":
 		if chater := plugin.GetPluginManager().GetAIChatPluginChater(ctx); chater != nil {
 			chater.ReloadMCPServers(ctx, true)
@@ -1414,6 +1519,8 @@ func (m *Manager) PostSettingUpdate(ctx context.Context, key string, value strin
 				logger.Error(ctx, fmt.Sprintf("failed to reload AI skills: %s", err.Error()))
 			}
 		}
+	case "MCPServerClients":
+		mcpserver.GetManager().Reload(ctx)
 	}
 }
 
@@ -1880,6 +1987,8 @@ func clampInt(v int, min int, max int) int {
 func (m *Manager) ExitApp(ctx context.Context) {
 	m.exitOnce.Do(func() {
 		util.GetLogger().Info(ctx, "start quitting")
+		// Close the MCP listener first so no client request reaches a plugin that is stopping.
+		mcpserver.GetManager().Stop(ctx)
 		plugin.GetPluginManager().Stop(ctx)
 		diagnostic.GetManager().MarkCleanExit(ctx)
 		util.GetLogger().Info(ctx, "bye~")
@@ -108,12 +108,22 @@ func convertPluginDto(ctx context.Context, pluginDto dto.PluginDto, pluginInstan
 		}
 	}
 
+	// Hotkeys registered through the plugin API get a settings table so users can rebind or disable them.
+	hotkeyDefinition, hotkeyValue, hasHotkeys := plugin.GetPluginManager().GetPluginHotkeySetting(ctx, pluginInstance)
+	if hasHotkeys {
+		hotkeyDefinition.Value = hotkeyDefinition.Value.Translate(pluginInstance.API.GetTranslation)
+		pluginDto.SettingDefinitions = append(pluginDto.SettingDefinitions, hotkeyDefinition)
+	}
+
 	nonDynamicSettings := make(map[string]string)
 	for _, item := range pluginDto.SettingDefinitions {
 		if item.Value != nil {
 			nonDynamicSettings[item.Value.GetKey()] = pluginInstance.API.GetSetting(ctx, item.Value.GetKey())
 		}
 	}
+	if hasHotkeys {
+		nonDynamicSettings[plugin.PluginHotkeySettingKey] = hotkeyValue
+	}
 	pluginDto.Setting = dto.PluginSettingDto{Disabled: pluginInstance.Setting.Disabled.Get(), TriggerKeywords: pluginInstance.Setting.TriggerKeywords.Get(), Settings: nonDynamicSettings}
 	pluginDto.Features = pluginInstance.Metadata.Features
 	pluginDto.TriggerKeywords = pluginInstance.GetTriggerKeywords()
@@ -38,6 +38,10 @@ const (
 	screenshotEditorToolArrow
 	screenshotEditorToolNumber
 	screenshotEditorToolMosaic
+	screenshotEditorToolPen
+	screenshotEditorToolHighlighter
+	screenshotEditorToolBlur
+	screenshotEditorToolCrop
 	screenshotEditorToolCount
 )
 
@@ -49,6 +53,10 @@ var screenshotEditorToolIconNames = [...]string{
 	"screenshot.arrow",
 	"screenshot.number",
 	"screenshot.mosaic",
+	"screenshot.pen",
+	"screenshot.highlighter",
+	"screenshot.blur",
+	"screenshot.crop",
 }
 
 var screenshotEditorDefaultTooltips = [...]string{
@@ -59,9 +67,13 @@ var screenshotEditorDefaultTooltips = [...]string{
 	"Arrow",
 	"Number",
 	"Mosaic",
+	"Pen",
+	"Highlighter",
+	"Blur",
+	"Crop",
 }
 
-var screenshotEditorToolShortcuts = [...]string{"", "R", "E", "T", "A", "N", "M"}
+var screenshotEditorToolShortcuts = [...]string{"", "R", "E", "T", "A", "N", "M", "D", "H", "B", "X"}
 
 type screenshotEditorAction uint8
 
@@ -210,6 +222,8 @@ type screenshotEditorOverlayState struct {
 	uiScale                 float32
 	chromeScale             func(selection Rect) float32
 	desktopPixelOrigin      Point
+	pendingProject          *screenshotProject
+	projectOrigin           image.Point
 	recordingUI             *recordingToolbarState
 	result                  chan screenshotEditorOverlayOutcome
 }
@@ -285,6 +299,10 @@ func newScreenshotEditorOverlayState(options ScreenshotOptions, uiImage *Image,
 		options.AnnotationTooltips.Arrow,
 		options.AnnotationTooltips.Number,
 		options.AnnotationTooltips.Mosaic,
+		options.AnnotationTooltips.Pen,
+		options.AnnotationTooltips.Highlighter,
+		options.AnnotationTooltips.Blur,
+		options.AnnotationTooltips.Crop,
 	}
 	state.actionTooltips = options.ActionTooltips
 	if platform.initialSelection != nil {
@@ -305,12 +323,31 @@ func runScreenshotEditor(options ScreenshotOptions, source image.Image, platform
 	if source == nil || platform.setWindowBounds == nil || platform.logicalSelection == nil || platform.captureDesktop == nil {
 		return ScreenshotResult{}, errors.New("screenshot editor platform is incomplete")
 	}
+	// a reopened project replaces the fresh capture with its own unannotated pixels, the marks are
+	// restored on the first frame once the logical frame size is known
+	var project *screenshotProject
+	var projectOrigin image.Point
+	var projectRegion image.Rectangle
+	if options.ProjectPath != "" {
+		loaded, projectSource, err := readScreenshotProject(options.ProjectPath)
+		if err != nil {
+			return ScreenshotResult{}, err
+		}
+		placed, origin, err := placeScreenshotProject(source, projectSource, image.Pt(loaded.Origin[0], loaded.Origin[1]))
+		if err != nil {
+			return ScreenshotResult{}, err
+		}
+		source, project, projectOrigin = placed, &loaded, origin
+		projectRegion = image.Rectangle{Min: source.Bounds().Min.Add(origin), Max: source.Bounds().Min.Add(origin).Add(projectSource.Bounds().Size())}
+	}
 	uiImage, err := newScreenshotEditorImage(source)
 	if err != nil {
 		return ScreenshotResult{}, fmt.Errorf("prepare screenshot overlay image: %w", err)
 	}
 
 	state := newScreenshotEditorOverlayState(options, uiImage, platform)
+	state.pendingProject = project
+	state.projectOrigin = projectOrigin
 	state.startScrolling = func() {
 		state.beginScrollingCapture(source, platform)
 	}
@@ -415,6 +452,8 @@ func runScreenshotEditor(options ScreenshotOptions, source image.Image, platform
 		if err := writeScreenshotPNG(exportPath, stitched); err != nil {
 			return ScreenshotResult{}, err
 		}
+		// a stitched capture has no single source frame to edit again
+		_ = os.Remove(common.ScreenshotProjectPath(exportPath))
 	} else {
 		pixelSelection, err := screenshotEditorPixelSelection(source.Bounds(), selection, frameSize)
 		if err != nil {
@@ -432,6 +471,16 @@ func runScreenshotEditor(options ScreenshotOptions, source image.Image, platform
 		if err := writeScreenshotEditorPNG(exportPath, composited, pixelSelection); err != nil {
 			return ScreenshotResult{}, err
 		}
+		if options.SaveProject {
+			// keep the whole reopened region so a crop can still be widened next time
+			region := pixelSelection
+			if !projectRegion.Empty() {
+				region = region.Union(projectRegion)
+			}
+			if err := writeScreenshotProject(common.ScreenshotProjectPath(exportPath), source, region, pixelSelection, annotations, frameSize); err != nil {
+				util.GetLogger().Warn(context.Background(), fmt.Sprintf("failed to save screenshot project: %s", err.Error()))
+			}
+		}
 	}
 
 	result := ScreenshotResult{
@@ -518,6 +567,9 @@ func (state *screenshotEditorOverlayState) draw(displayList *DisplayList, frame
 		return
 	}
 	state.frameSize = frame.Size
+	if state.pendingProject != nil && frame.Size.Width > 0 && frame.Size.Height > 0 {
+		state.applyProjectLocked(frame.Size)
+	}
 	selection := normalizeScreenshotEditorRect(state.selection, frame.Size)
 	hasSelection := state.hasSelection || state.dragging
 	pointerPosition := state.pointerPosition
@@ -657,7 +709,7 @@ func (state *screenshotEditorOverlayState) draw(displayList *DisplayList, frame
 
 	toolbarWidth := scaled(128)
 	if !hideTools {
-		toolbarWidth = scaled(632)
+		toolbarWidth = scaled(824)
 		if state.allowVideoRecording {
 			toolbarWidth += scaled(54)
 		}
@@ -1064,17 +1116,17 @@ func (state *screenshotEditorOverlayState) drawEditBar(
 	creationTextSize float32,
 	uiScale float32,
 ) {
-	if selected == nil && activeTool == screenshotEditorToolSelect {
+	if selected == nil && (activeTool == screenshotEditorToolSelect || activeTool == screenshotEditorToolCrop) {
 		return
 	}
 
-	isMosaic := activeTool == screenshotEditorToolMosaic
+	isMosaic := activeTool == screenshotEditorToolMosaic || activeTool == screenshotEditorToolBlur
 	isText := activeTool == screenshotEditorToolText
 	color := creationColor
 	mosaicRadius := creationMosaicRadius
 	textSize := creationTextSize
 	if selected != nil {
-		isMosaic = selected.tool == screenshotEditorToolMosaic
+		isMosaic = selected.tool == screenshotEditorToolMosaic || selected.tool == screenshotEditorToolBlur
 		isText = selected.tool == screenshotEditorToolText
 		color = screenshotEditorAnnotationDrawColor(*selected)
 		mosaicRadius = screenshotEditorAnnotationMosaicRadius(*selected)
@@ -1526,8 +1578,8 @@ func (state *screenshotEditorOverlayState) pointer(event PointerEvent) {
 		} else if state.annotationDragging && state.draft != nil {
 			position := clampScreenshotEditorPoint(event.Position, state.selection)
 			switch state.draft.tool {
-			case screenshotEditorToolRect, screenshotEditorToolEllipse:
-				if event.Modifiers&KeyModifierShift != 0 {
+			case screenshotEditorToolRect, screenshotEditorToolEllipse, screenshotEditorToolBlur, screenshotEditorToolCrop:
+				if event.Modifiers&KeyModifierShift != 0 && (state.draft.tool == screenshotEditorToolRect || state.draft.tool == screenshotEditorToolEllipse) {
 					state.draft.rect = squareScreenshotEditorRectFromAnchor(state.start, position, state.selection)
 				} else {
 					state.draft.rect = normalizeScreenshotEditorRect(Rect{X: state.start.X, Y: state.start.Y, Width: position.X - state.start.X, Height: position.Y - state.start.Y}, state.frameSize)
@@ -1540,6 +1592,13 @@ func (state *screenshotEditorOverlayState) pointer(event PointerEvent) {
 				if math.Hypot(float64(position.X-last.X), float64(position.Y-last.Y)) >= 7 {
 					state.draft.points = append(points, position)
 				}
+			case screenshotEditorToolPen, screenshotEditorToolHighlighter:
+				// freehand strokes keep denser samples than the mosaic brush so curves stay smooth
+				points := state.draft.points
+				last := points[len(points)-1]
+				if math.Hypot(float64(position.X-last.X), float64(position.Y-last.Y)) >= 2 {
+					state.draft.points = append(points, position)
+				}
 			}
 		} else {
 			hoverChanged := state.updateHoverLocked(event.Position) || pointerChanged
@@ -1589,7 +1648,15 @@ func (state *screenshotEditorOverlayState) pointer(event PointerEvent) {
 		draft := *state.draft
 		state.annotationDragging = false
 		state.draft = nil
-		if screenshotEditorAnnotationIsVisible(draft) {
+		if draft.tool == screenshotEditorToolCrop {
+			// Cropping only narrows the selection. Marks outside of it stay in the project and
+			// come back when the selection is widened again.
+			if screenshotEditorAnnotationIsVisible(draft) {
+				state.selection = draft.rect
+				state.hasSelectedMark = false
+				state.activeTool = screenshotEditorToolSelect
+			}
+		} else if screenshotEditorAnnotationIsVisible(draft) {
 			state.annotations = append(state.annotations, draft)
 			state.selectedAnnotation = len(state.annotations) - 1
 			state.hasSelectedMark = true
@@ -1754,6 +1821,14 @@ func (state *screenshotEditorOverlayState) key(event KeyEvent) bool {
 		tool = screenshotEditorToolNumber
 	case Key("m"):
 		tool = screenshotEditorToolMosaic
+	case Key("d"):
+		tool = screenshotEditorToolPen
+	case Key("h"):
+		tool = screenshotEditorToolHighlighter
+	case Key("b"):
+		tool = screenshotEditorToolBlur
+	case Key("x"):
+		tool = screenshotEditorToolCrop
 	default:
 		toolShortcut = false
 	}
@@ -2180,7 +2255,7 @@ func (state *screenshotEditorOverlayState) beginSelectionEditLocked(point Point)
 // screenshotEditorAnnotationHandleAt resolves shape handles and arrow endpoints to their edit modes.
 func screenshotEditorAnnotationHandleAt(annotation screenshotEditorAnnotation, point Point, uiScale float32) (screenshotEditorHandle, screenshotEditorEditMode, bool) {
 	switch annotation.tool {
-	case screenshotEditorToolRect, screenshotEditorToolEllipse:
+	case screenshotEditorToolRect, screenshotEditorToolEllipse, screenshotEditorToolBlur:
 		if handle, found := screenshotEditorHandleAt(annotation.rect, point, uiScale); found {
 			return handle, screenshotEditorEditResizeAnnotation, true
 		}
@@ -2279,7 +2354,7 @@ func (state *screenshotEditorOverlayState) updateHoverLocked(point Point) bool {
 		}
 		return toolHoverChanged || previousAnnotation != index || !previousHasHoveredMark || previousCursor != state.pointerCursor
 	}
-	if (state.activeTool == screenshotEditorToolMosaic || state.activeTool == screenshotEditorToolNumber) && screenshotEditorRectContains(state.selection, point) {
+	if (state.activeTool == screenshotEditorToolMosaic || state.activeTool == screenshotEditorToolNumber || state.activeTool == screenshotEditorToolPen || state.activeTool == screenshotEditorToolHighlighter) && screenshotEditorRectContains(state.selection, point) {
 		state.pointerCursor = PointerCursorCrosshair
 	} else if state.activeTool == screenshotEditorToolText && screenshotEditorRectContains(state.selection, point) {
 		state.pointerCursor = PointerCursorText
@@ -2475,11 +2550,11 @@ func clampScreenshotEditorPoint(point Point, bounds Rect) Point {
 
 func screenshotEditorAnnotationIsVisible(annotation screenshotEditorAnnotation) bool {
 	switch annotation.tool {
-	case screenshotEditorToolRect, screenshotEditorToolEllipse:
+	case screenshotEditorToolRect, screenshotEditorToolEllipse, screenshotEditorToolBlur, screenshotEditorToolCrop:
 		return annotation.rect.Width >= 2 && annotation.rect.Height >= 2
 	case screenshotEditorToolArrow:
 		return math.Hypot(float64(annotation.end.X-annotation.start.X), float64(annotation.end.Y-annotation.start.Y)) >= 2
-	case screenshotEditorToolMosaic:
+	case screenshotEditorToolMosaic, screenshotEditorToolPen, screenshotEditorToolHighlighter:
 		return len(annotation.points) > 0
 	case screenshotEditorToolNumber:
 		return annotation.number > 0
@@ -26,6 +26,11 @@ const (
 	screenshotEditorNumberFontSize   = float32(14)
 )
 
+const (
+	screenshotEditorHighlighterStroke = float32(18)
+	screenshotEditorHighlighterAlpha  = uint8(110)
+)
+
 var (
 	screenshotEditorAnnotationColor = Color{R: 255, G: 91, B: 54, A: 255}
 	screenshotEditorPalette         = [...]Color{
@@ -80,6 +85,12 @@ func drawScreenshotEditorAnnotations(displayList *DisplayList, annotations []scr
 			drawScreenshotEditorNumber(displayList, annotation, uiScale)
 		case screenshotEditorToolMosaic:
 			drawScreenshotEditorMosaicPreview(displayList, annotation.points, screenshotEditorAnnotationMosaicRadius(annotation), source, frame)
+		case screenshotEditorToolPen, screenshotEditorToolHighlighter:
+			drawScreenshotEditorStroke(displayList, annotation.points, screenshotEditorAnnotationStrokeWidth(annotation, uiScale), screenshotEditorAnnotationStrokeColor(annotation))
+		case screenshotEditorToolBlur:
+			drawScreenshotEditorBlurPreview(displayList, annotation.rect, screenshotEditorAnnotationBlurRadius(annotation), source, frame)
+		case screenshotEditorToolCrop:
+			drawScreenshotEditorTextFrame(displayList, annotation.rect, uiScale)
 		}
 	}
 }
@@ -91,13 +102,17 @@ func screenshotEditorAnnotationPreviewStroke(uiScale float32) float32 {
 func drawScreenshotEditorAnnotationHandles(displayList *DisplayList, annotation screenshotEditorAnnotation, uiScale float32) {
 	points := []Point{}
 	switch annotation.tool {
-	case screenshotEditorToolRect, screenshotEditorToolEllipse:
+	case screenshotEditorToolRect, screenshotEditorToolEllipse, screenshotEditorToolBlur:
 		points = screenshotEditorRectHandlePoints(annotation.rect)
 	case screenshotEditorToolArrow:
 		points = []Point{annotation.start, annotation.end}
 	case screenshotEditorToolText:
 		drawScreenshotEditorTextFrame(displayList, screenshotEditorTextFrame(annotation, uiScale), uiScale)
 		return
+	case screenshotEditorToolPen, screenshotEditorToolHighlighter:
+		// Freehand strokes have no resize handles, the dashed frame only shows what will be moved.
+		drawScreenshotEditorTextFrame(displayList, screenshotEditorAnnotationBounds(annotation, uiScale), uiScale)
+		return
 	case screenshotEditorToolNumber:
 		bounds := screenshotEditorNumberBounds(annotation, uiScale)
 		padding := 3 * max(float32(1), uiScale)
@@ -183,7 +198,7 @@ func drawScreenshotEditorTextFrame(displayList *DisplayList, frame Rect, uiScale
 
 func screenshotEditorAnnotationContains(annotation screenshotEditorAnnotation, point Point, uiScale float32) bool {
 	switch annotation.tool {
-	case screenshotEditorToolRect:
+	case screenshotEditorToolRect, screenshotEditorToolBlur:
 		return screenshotEditorRectContains(annotation.rect, point)
 	case screenshotEditorToolEllipse:
 		radiusX, radiusY := annotation.rect.Width/2, annotation.rect.Height/2
@@ -210,6 +225,14 @@ func screenshotEditorAnnotationContains(annotation screenshotEditorAnnotation, p
 				return true
 			}
 		}
+	case screenshotEditorToolPen, screenshotEditorToolHighlighter:
+		tolerance := screenshotEditorAnnotationStrokeWidth(annotation, uiScale)/2 + screenshotEditorAnnotationStroke
+		for index := range annotation.points {
+			previous := annotation.points[max(0, index-1)]
+			if screenshotEditorDistanceToSegment(point, previous, annotation.points[index]) <= tolerance {
+				return true
+			}
+		}
 	}
 	return false
 }
@@ -257,7 +280,7 @@ func screenshotEditorAnnotationAtMatching(annotations []screenshotEditorAnnotati
 
 func screenshotEditorAnnotationBounds(annotation screenshotEditorAnnotation, uiScale float32) Rect {
 	switch annotation.tool {
-	case screenshotEditorToolRect, screenshotEditorToolEllipse:
+	case screenshotEditorToolRect, screenshotEditorToolEllipse, screenshotEditorToolBlur, screenshotEditorToolCrop:
 		return annotation.rect
 	case screenshotEditorToolArrow:
 		return normalizeScreenshotEditorRect(Rect{X: annotation.start.X, Y: annotation.start.Y, Width: annotation.end.X - annotation.start.X, Height: annotation.end.Y - annotation.start.Y}, Size{Width: math.MaxFloat32, Height: math.MaxFloat32})
@@ -268,21 +291,27 @@ func screenshotEditorAnnotationBounds(annotation screenshotEditorAnnotation, uiS
 	case screenshotEditorToolNumber:
 		return screenshotEditorNumberBounds(annotation, uiScale)
 	case screenshotEditorToolMosaic:
-		if len(annotation.points) == 0 {
-			return Rect{}
-		}
-		left, top, right, bottom := annotation.points[0].X, annotation.points[0].Y, annotation.points[0].X, annotation.points[0].Y
-		for _, point := range annotation.points[1:] {
-			left, top = min(left, point.X), min(top, point.Y)
-			right, bottom = max(right, point.X), max(bottom, point.Y)
-		}
-		radius := screenshotEditorAnnotationMosaicRadius(annotation)
-		return Rect{X: left - radius, Y: top - radius, Width: right - left + 2*radius, Height: bottom - top + 2*radius}
+		return screenshotEditorPointsBounds(annotation.points, screenshotEditorAnnotationMosaicRadius(annotation))
+	case screenshotEditorToolPen, screenshotEditorToolHighlighter:
+		return screenshotEditorPointsBounds(annotation.points, screenshotEditorAnnotationStrokeWidth(annotation, uiScale)/2)
 	default:
 		return Rect{}
 	}
 }
 
+// screenshotEditorPointsBounds returns the box around brush points grown by the brush radius.
+func screenshotEditorPointsBounds(points []Point, radius float32) Rect {
+	if len(points) == 0 {
+		return Rect{}
+	}
+	left, top, right, bottom := points[0].X, points[0].Y, points[0].X, points[0].Y
+	for _, point := range points[1:] {
+		left, top = min(left, point.X), min(top, point.Y)
+		right, bottom = max(right, point.X), max(bottom, point.Y)
+	}
+	return Rect{X: left - radius, Y: top - radius, Width: right - left + 2*radius, Height: bottom - top + 2*radius}
+}
+
 func screenshotEditorAnnotationTextSize(annotation screenshotEditorAnnotation, renderedFontSize float32) Size {
 	if annotation.textSize.Width > 0 && annotation.textSize.Height > 0 && math.Abs(float64(annotation.measuredSize-renderedFontSize)) < 0.01 {
 		return annotation.textSize
@@ -346,6 +375,49 @@ func drawScreenshotEditorLine(displayList *DisplayList, start, end Point, width
 	}, color)
 }
 
+// drawScreenshotEditorStroke joins freehand points with round joints. Translucent highlighter
+// strokes skip the joints because every overlap would show up as a darker dot.
+func drawScreenshotEditorStroke(displayList *DisplayList, points []Point, width float32, color Color) {
+	if len(points) == 1 {
+		drawScreenshotEditorLine(displayList, points[0], points[0], width, color)
+		return
+	}
+	for index := 1; index < len(points); index++ {
+		drawScreenshotEditorLine(displayList, points[index-1], points[index], width, color)
+		if color.A == 255 && index < len(points)-1 {
+			joint := points[index]
+			displayList.FillRoundedRect(Rect{X: joint.X - width/2, Y: joint.Y - width/2, Width: width, Height: width}, width/2, color)
+		}
+	}
+}
+
+// drawScreenshotEditorBlurPreview averages source pixels into small cells. It is coarser than the
+// exported blur but cheap enough to redraw on every pointer move while the region is dragged.
+func drawScreenshotEditorBlurPreview(displayList *DisplayList, rect Rect, radius float32, source *Image, frame Size) {
+	if source == nil || frame.Width <= 0 || frame.Height <= 0 || rect.Width <= 0 || rect.Height <= 0 {
+		return
+	}
+	scaleX := float32(source.Width) / frame.Width
+	scaleY := float32(source.Height) / frame.Height
+	cell := max(float32(3), float32(math.Sqrt(float64(rect.Width*rect.Height/4000))))
+	for y := rect.Y; y < rect.Y+rect.Height; y += cell {
+		for x := rect.X; x < rect.X+rect.Width; x += cell {
+			cellWidth, cellHeight := min(cell, rect.X+rect.Width-x), min(cell, rect.Y+rect.Height-y)
+			centerX, centerY := x+cellWidth/2, y+cellHeight/2
+			red, green, blue, count := 0, 0, 0, 0
+			for row := -2; row <= 2; row++ {
+				for column := -2; column <= 2; column++ {
+					logicalX := min(max(centerX+float32(column)*radius/2, rect.X), rect.X+rect.Width)
+					logicalY := min(max(centerY+float32(row)*radius/2, rect.Y), rect.Y+rect.Height)
+					pixel := source.RGBAAt(min(max(0, int(logicalX*scaleX)), source.Width-1), min(max(0, int(logicalY*scaleY)), source.Height-1))
+					red, green, blue, count = red+int(pixel.R), green+int(pixel.G), blue+int(pixel.B), count+1
+				}
+			}
+			displayList.FillRect(Rect{X: x, Y: y, Width: cellWidth, Height: cellHeight}, Color{R: uint8(red / count), G: uint8(green / count), B: uint8(blue / count), A: 255})
+		}
+	}
+}
+
 func drawScreenshotEditorMosaicPreview(displayList *DisplayList, points []Point, radius float32, source *Image, frame Size) {
 	if source == nil || frame.Width <= 0 || frame.Height <= 0 {
 		return
@@ -413,11 +485,85 @@ func renderScreenshotEditorAnnotations(source image.Image, annotations []screens
 			drawScreenshotEditorPixelNumber(output, clip, annotation, previewScale, scaleX, scaleY, pixelColor)
 		case screenshotEditorToolMosaic:
 			drawScreenshotEditorPixelMosaic(output, clip, annotation.points, screenshotEditorAnnotationMosaicRadius(annotation), scaleX, scaleY)
+		case screenshotEditorToolPen:
+			drawScreenshotEditorPixelStroke(output, clip, annotation.points, strokeWidth, scaleX, scaleY, pixelColor)
+		case screenshotEditorToolHighlighter:
+			drawScreenshotEditorPixelHighlighter(output, clip, annotation.points, screenshotEditorAnnotationStrokeWidth(annotation, previewScale)*scaleX, scaleX, scaleY, pixelColor)
+		case screenshotEditorToolBlur:
+			radius := max(1, int(math.Round(float64(screenshotEditorAnnotationBlurRadius(annotation)*scaleX))))
+			blurScreenshotEditorPixels(output, screenshotEditorScaleRect(annotation.rect, scaleX, scaleY).Intersect(clip), radius)
 		}
 	}
 	return output, nil
 }
 
+func drawScreenshotEditorPixelStroke(target *image.RGBA, clip image.Rectangle, points []Point, width, scaleX, scaleY float32, color color.RGBA) {
+	for index := range points {
+		previous := points[max(0, index-1)]
+		drawScreenshotEditorPixelLine(target, clip, screenshotEditorScalePoint(previous, scaleX, scaleY), screenshotEditorScalePoint(points[index], scaleX, scaleY), width, color)
+	}
+}
+
+// drawScreenshotEditorPixelHighlighter rasterizes the stroke into a coverage mask first and
+// composites it once, so the translucent ink keeps one even tone where the stroke crosses itself.
+func drawScreenshotEditorPixelHighlighter(target *image.RGBA, clip image.Rectangle, points []Point, width, scaleX, scaleY float32, ink color.RGBA) {
+	mask := image.NewRGBA(clip.Intersect(target.Bounds()))
+	if mask.Rect.Empty() {
+		return
+	}
+	drawScreenshotEditorPixelStroke(mask, clip, points, width, scaleX, scaleY, color.RGBA{A: 255})
+	source := image.NewUniform(color.NRGBA{R: ink.R, G: ink.G, B: ink.B, A: screenshotEditorHighlighterAlpha})
+	draw.DrawMask(target, mask.Rect, source, image.Point{}, mask, mask.Rect.Min, draw.Over)
+}
+
+// blurScreenshotEditorPixels approximates a gaussian blur with three box blur passes. Samples are
+// clamped to the region so the blur never pulls in pixels from outside the marked area.
+func blurScreenshotEditorPixels(target *image.RGBA, region image.Rectangle, radius int) {
+	region = region.Intersect(target.Bounds())
+	if region.Empty() || radius < 1 {
+		return
+	}
+	width, height := region.Dx(), region.Dy()
+	pixels := make([]uint8, width*height*4)
+	for y := 0; y < height; y++ {
+		offset := target.PixOffset(region.Min.X, region.Min.Y+y)
+		copy(pixels[y*width*4:(y+1)*width*4], target.Pix[offset:offset+width*4])
+	}
+	scratch := make([]uint8, len(pixels))
+	for pass := 0; pass < 3; pass++ {
+		boxBlurScreenshotEditorPixels(pixels, scratch, width, height, radius, true)
+		boxBlurScreenshotEditorPixels(scratch, pixels, width, height, radius, false)
+	}
+	for y := 0; y < height; y++ {
+		offset := target.PixOffset(region.Min.X, region.Min.Y+y)
+		copy(target.Pix[offset:offset+width*4], pixels[y*width*4:(y+1)*width*4])
+	}
+}
+
+// boxBlurScreenshotEditorPixels runs one sliding window pass over every row or column.
+func boxBlurScreenshotEditorPixels(source, target []uint8, width, height, radius int, horizontal bool) {
+	lines, length, step, lineStride := height, width, 4, width*4
+	if !horizontal {
+		lines, length, step, lineStride = width, height, width*4, 4
+	}
+	window := 2*radius + 1
+	for line := 0; line < lines; line++ {
+		base := line * lineStride
+		for channel := 0; channel < 4; channel++ {
+			sum := 0
+			for offset := -radius; offset <= radius; offset++ {
+				sum += int(source[base+min(max(offset, 0), length-1)*step+channel])
+			}
+			for index := 0; index < length; index++ {
+				target[base+index*step+channel] = uint8((sum + window/2) / window)
+				outgoing := max(index-radius, 0)
+				incoming := min(index+radius+1, length-1)
+				sum += int(source[base+incoming*step+channel]) - int(source[base+outgoing*step+channel])
+			}
+		}
+	}
+}
+
 // drawScreenshotEditorPixelNumber preserves the marker's logical size and centered label in the exported image.
 func drawScreenshotEditorPixelNumber(target *image.RGBA, clip image.Rectangle, annotation screenshotEditorAnnotation, previewScale, scaleX, scaleY float32, fill color.RGBA) {
 	center := screenshotEditorScalePoint(annotation.start, scaleX, scaleY)
@@ -653,3 +799,24 @@ func screenshotEditorAnnotationMosaicRadius(annotation screenshotEditorAnnotatio
 	}
 	return annotation.mosaicRadius
 }
+
+// screenshotEditorAnnotationBlurRadius reuses the mosaic brush sizes so both privacy tools share
+// the same three edit bar choices.
+func screenshotEditorAnnotationBlurRadius(annotation screenshotEditorAnnotation) float32 {
+	return screenshotEditorAnnotationMosaicRadius(annotation) / 2
+}
+
+func screenshotEditorAnnotationStrokeWidth(annotation screenshotEditorAnnotation, uiScale float32) float32 {
+	if annotation.tool == screenshotEditorToolHighlighter {
+		return screenshotEditorHighlighterStroke * max(float32(1), uiScale)
+	}
+	return screenshotEditorAnnotationPreviewStroke(uiScale)
+}
+
+func screenshotEditorAnnotationStrokeColor(annotation screenshotEditorAnnotation) Color {
+	strokeColor := screenshotEditorAnnotationDrawColor(annotation)
+	if annotation.tool == screenshotEditorToolHighlighter {
+		strokeColor.A = screenshotEditorHighlighterAlpha
+	}
+	return strokeColor
+}
@@ -298,8 +298,8 @@ func TestScreenshotEditorToolbarUsesCompactCreationTools(t *testing.T) {
 	}
 	state.draw(&DisplayList{}, FrameInfo{Size: Size{Width: 1200, Height: 700}})
 
-	if state.toolbarRect.Width != 632 || state.toolbarRect.Height != 60 {
-		t.Fatalf("toolbar bounds = %+v, want 632x60", state.toolbarRect)
+	if state.toolbarRect.Width != 824 || state.toolbarRect.Height != 60 {
+		t.Fatalf("toolbar bounds = %+v, want 824x60", state.toolbarRect)
 	}
 	if state.toolbarRect.X != state.selection.X+state.selection.Width-state.toolbarRect.Width {
 		t.Fatalf("toolbar left = %v, want right-aligned to selection", state.toolbarRect.X)
@@ -349,7 +349,7 @@ func TestScreenshotEditorToolbarShowsRecordingOnlyWhenAllowed(t *testing.T) {
 	state.selection = Rect{X: 100, Y: 100, Width: 900, Height: 400}
 	state.hasSelection = true
 	state.draw(&DisplayList{}, FrameInfo{Size: Size{Width: 1200, Height: 700}})
-	if state.toolbarRect.Width != 686 || state.recordRect.Width != 40 {
+	if state.toolbarRect.Width != 878 || state.recordRect.Width != 40 {
 		t.Fatalf("recording toolbar=%+v button=%+v", state.toolbarRect, state.recordRect)
 	}
 
@@ -357,7 +357,7 @@ func TestScreenshotEditorToolbarShowsRecordingOnlyWhenAllowed(t *testing.T) {
 	imageOnly.selection = state.selection
 	imageOnly.hasSelection = true
 	imageOnly.draw(&DisplayList{}, FrameInfo{Size: Size{Width: 1200, Height: 700}})
-	if imageOnly.toolbarRect.Width != 632 || imageOnly.recordRect != (Rect{}) {
+	if imageOnly.toolbarRect.Width != 824 || imageOnly.recordRect != (Rect{}) {
 		t.Fatalf("image-only toolbar=%+v button=%+v", imageOnly.toolbarRect, imageOnly.recordRect)
 	}
 }
@@ -410,7 +410,7 @@ func TestScreenshotEditorToolbarIconsRenderFromSharedSVGs(t *testing.T) {
 
 func TestScreenshotEditorAnnotationToolsHaveTooltips(t *testing.T) {
 	configured := [screenshotEditorToolCount]string{"", "Localized rectangle"}
-	for tool := int(screenshotEditorToolRect); tool <= int(screenshotEditorToolMosaic); tool++ {
+	for tool := int(screenshotEditorToolRect); tool < int(screenshotEditorToolCount); tool++ {
 		if tooltip := screenshotEditorToolTooltip(tool, configured); tooltip == "" {
 			t.Fatalf("tool %d tooltip is empty", tool)
 		}
@@ -443,8 +443,8 @@ func TestScreenshotEditorChromeUsesSelectionMonitorScale(t *testing.T) {
 	if state.uiScale != 1.5 {
 		t.Fatalf("chrome scale = %.2f, want 1.5", state.uiScale)
 	}
-	if state.toolbarRect.Width != 948 || state.toolbarRect.Height != 90 {
-		t.Fatalf("scaled toolbar = %+v, want 948x90", state.toolbarRect)
+	if state.toolbarRect.Width != 1236 || state.toolbarRect.Height != 90 {
+		t.Fatalf("scaled toolbar = %+v, want 1236x90", state.toolbarRect)
 	}
 	if state.confirmRect.Width != 60 || state.confirmRect.Height != 60 {
 		t.Fatalf("scaled confirm action = %+v, want 60x60", state.confirmRect)
@@ -549,6 +549,9 @@ func TestScreenshotEditorCreatesEveryAnnotationTool(t *testing.T) {
 		screenshotEditorToolEllipse,
 		screenshotEditorToolArrow,
 		screenshotEditorToolMosaic,
+		screenshotEditorToolPen,
+		screenshotEditorToolHighlighter,
+		screenshotEditorToolBlur,
 	} {
 		state := &screenshotEditorOverlayState{
 			frameSize:    Size{Width: 200, Height: 100},
@@ -817,6 +820,47 @@ func TestScreenshotEditorArrowShaftStopsInsideHead(t *testing.T) {
 	}
 }
 
+func TestScreenshotEditorBlurAndHighlighterRender(t *testing.T) {
+	source := image.NewRGBA(image.Rect(0, 0, 120, 80))
+	draw.Draw(source, source.Bounds(), image.NewUniform(color.RGBA{R: 255, G: 255, B: 255, A: 255}), image.Point{}, draw.Src)
+	draw.Draw(source, image.Rect(0, 0, 30, 80), image.NewUniform(color.RGBA{A: 255}), image.Point{}, draw.Src)
+	output, err := renderScreenshotEditorAnnotations(source, []screenshotEditorAnnotation{
+		{tool: screenshotEditorToolBlur, rect: Rect{X: 10, Y: 10, Width: 40, Height: 40}, mosaicRadius: 12},
+		{tool: screenshotEditorToolHighlighter, points: []Point{{X: 70, Y: 60}, {X: 110, Y: 60}}, color: screenshotEditorAnnotationColor},
+	}, Rect{Width: 120, Height: 80}, Size{Width: 120, Height: 80}, 1)
+	if err != nil {
+		t.Fatalf("render blur and highlighter: %v", err)
+	}
+	if got := output.RGBAAt(30, 30); got.R == 0 || got.R == 255 {
+		t.Fatalf("blur kept the hard edge: %+v", got)
+	}
+	if got := output.RGBAAt(5, 70); got.R != 0 {
+		t.Fatalf("blur leaked outside its rect: %+v", got)
+	}
+	got := output.RGBAAt(90, 60)
+	if got.R != 255 || got.G <= screenshotEditorAnnotationColor.G || got.G == 255 {
+		t.Fatalf("highlighter should tint without covering the pixels: %+v", got)
+	}
+}
+
+func TestScreenshotEditorCropShrinksSelection(t *testing.T) {
+	state := &screenshotEditorOverlayState{
+		frameSize:    Size{Width: 200, Height: 100},
+		selection:    Rect{Width: 200, Height: 100},
+		hasSelection: true,
+		activeTool:   screenshotEditorToolCrop,
+	}
+	state.pointer(PointerEvent{Kind: PointerDown, Button: PointerButtonPrimary, Position: Point{X: 20, Y: 20}})
+	state.pointer(PointerEvent{Kind: PointerMove, Position: Point{X: 80, Y: 60}})
+	state.pointer(PointerEvent{Kind: PointerUp, Button: PointerButtonPrimary, Position: Point{X: 80, Y: 60}})
+	if state.selection != (Rect{X: 20, Y: 20, Width: 60, Height: 40}) {
+		t.Fatalf("cropped selection = %+v", state.selection)
+	}
+	if len(state.annotations) != 0 || state.activeTool != screenshotEditorToolSelect {
+		t.Fatalf("crop left annotations = %+v active = %d", state.annotations, state.activeTool)
+	}
+}
+
 func TestScreenshotEditorShiftConstrainsNewShapes(t *testing.T) {
 	for _, tool := range []screenshotEditorTool{screenshotEditorToolRect, screenshotEditorToolEllipse} {
 		state := &screenshotEditorOverlayState{
@@ -870,6 +914,10 @@ func TestScreenshotEditorToolbarKeyboardShortcuts(t *testing.T) {
 		Key("a"): screenshotEditorToolArrow,
 		Key("n"): screenshotEditorToolNumber,
 		Key("m"): screenshotEditorToolMosaic,
+		Key("d"): screenshotEditorToolPen,
+		Key("h"): screenshotEditorToolHighlighter,
+		Key("b"): screenshotEditorToolBlur,
+		Key("x"): screenshotEditorToolCrop,
 	} {
 		if !state.key(KeyEvent{Key: key, Down: true}) || state.activeTool != tool {
 			t.Fatalf("shortcut %q selected tool %d, want %d", key, state.activeTool, tool)
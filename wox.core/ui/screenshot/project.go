@@ -0,0 +1,262 @@
+package screenshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+)
+
+const screenshotProjectVersion = 1
+
+// screenshotProjectToolNames are the persisted tool identifiers. They are kept apart from the
+// toolbar icon names so a new icon never breaks projects saved by an older build. Crop only
+// changes the selection and never becomes a stored mark.
+var screenshotProjectToolNames = [screenshotEditorToolCount]string{
+	"",
+	"rectangle",
+	"ellipse",
+	"text",
+	"arrow",
+	"number",
+	"mosaic",
+	"pen",
+	"highlighter",
+	"blur",
+	"",
+}
+
+// screenshotProject is the sidecar that keeps an exported screenshot editable. Source holds the
+// unannotated pixels cut from the desktop capture at Origin. Selection and annotation geometry
+// use source pixels because the next editing session can run with a different logical frame.
+type screenshotProject struct {
+	Version     int                           `json:"version"`
+	Source      []byte                        `json:"source"`
+	Origin      [2]int                        `json:"origin"`
+	Selection   [4]float32                    `json:"selection"`
+	Annotations []screenshotProjectAnnotation `json:"annotations"`
+}
+
+type screenshotProjectAnnotation struct {
+	Tool     string       `json:"tool"`
+	Rect     [4]float32   `json:"rect"`
+	Start    [2]float32   `json:"start"`
+	End      [2]float32   `json:"end"`
+	Points   [][2]float32 `json:"points,omitempty"`
+	Text     string       `json:"text,omitempty"`
+	Color    string       `json:"color"`
+	FontSize float32      `json:"fontSize,omitempty"`
+	Radius   float32      `json:"radius,omitempty"`
+	Number   int          `json:"number,omitempty"`
+}
+
+// screenshotProjectSpace maps editor frame units to project source pixels. origin is the top left
+// corner of the project source inside the desktop capture.
+type screenshotProjectSpace struct {
+	scaleX float32
+	scaleY float32
+	origin image.Point
+}
+
+func newScreenshotProjectSpace(capture image.Rectangle, frame Size, origin image.Point) (screenshotProjectSpace, error) {
+	if capture.Empty() || frame.Width <= 0 || frame.Height <= 0 {
+		return screenshotProjectSpace{}, errors.New("screenshot project frame is empty")
+	}
+	return screenshotProjectSpace{
+		scaleX: float32(capture.Dx()) / frame.Width,
+		scaleY: float32(capture.Dy()) / frame.Height,
+		origin: origin,
+	}, nil
+}
+
+func (space screenshotProjectSpace) sourcePoint(point Point) [2]float32 {
+	return [2]float32{point.X*space.scaleX - float32(space.origin.X), point.Y*space.scaleY - float32(space.origin.Y)}
+}
+
+func (space screenshotProjectSpace) framePoint(point [2]float32) Point {
+	return Point{X: (point[0] + float32(space.origin.X)) / space.scaleX, Y: (point[1] + float32(space.origin.Y)) / space.scaleY}
+}
+
+func (space screenshotProjectSpace) sourceRect(rect Rect) [4]float32 {
+	start := space.sourcePoint(Point{X: rect.X, Y: rect.Y})
+	return [4]float32{start[0], start[1], rect.Width * space.scaleX, rect.Height * space.scaleY}
+}
+
+func (space screenshotProjectSpace) frameRect(rect [4]float32) Rect {
+	start := space.framePoint([2]float32{rect[0], rect[1]})
+	return Rect{X: start.X, Y: start.Y, Width: rect[2] / space.scaleX, Height: rect[3] / space.scaleY}
+}
+
+// encodeAnnotation drops measured text metrics, they are recomputed for the next frame anyway.
+func (space screenshotProjectSpace) encodeAnnotation(annotation screenshotEditorAnnotation) (screenshotProjectAnnotation, bool) {
+	if int(annotation.tool) >= len(screenshotProjectToolNames) || screenshotProjectToolNames[annotation.tool] == "" {
+		return screenshotProjectAnnotation{}, false
+	}
+	drawColor := screenshotEditorAnnotationDrawColor(annotation)
+	stored := screenshotProjectAnnotation{
+		Tool:     screenshotProjectToolNames[annotation.tool],
+		Rect:     space.sourceRect(annotation.rect),
+		Start:    space.sourcePoint(annotation.start),
+		End:      space.sourcePoint(annotation.end),
+		Text:     annotation.text,
+		Color:    fmt.Sprintf("#%02x%02x%02x%02x", drawColor.R, drawColor.G, drawColor.B, drawColor.A),
+		FontSize: annotation.fontSize,
+		Radius:   annotation.mosaicRadius,
+		Number:   annotation.number,
+	}
+	for _, point := range annotation.points {
+		stored.Points = append(stored.Points, space.sourcePoint(point))
+	}
+	return stored, true
+}
+
+// decodeAnnotation skips marks of tools this build doesn't know so a newer project still opens.
+func (space screenshotProjectSpace) decodeAnnotation(stored screenshotProjectAnnotation) (screenshotEditorAnnotation, bool) {
+	tool := screenshotEditorToolSelect
+	for index, name := range screenshotProjectToolNames {
+		if name != "" && name == stored.Tool {
+			tool = screenshotEditorTool(index)
+			break
+		}
+	}
+	if tool == screenshotEditorToolSelect {
+		return screenshotEditorAnnotation{}, false
+	}
+	annotation := screenshotEditorAnnotation{
+		tool:         tool,
+		rect:         space.frameRect(stored.Rect),
+		start:        space.framePoint(stored.Start),
+		end:          space.framePoint(stored.End),
+		text:         stored.Text,
+		fontSize:     stored.FontSize,
+		mosaicRadius: stored.Radius,
+		number:       stored.Number,
+	}
+	if _, err := fmt.Sscanf(stored.Color, "#%02x%02x%02x%02x", &annotation.color.R, &annotation.color.G, &annotation.color.B, &annotation.color.A); err != nil {
+		annotation.color = screenshotEditorAnnotationColor
+	}
+	for _, point := range stored.Points {
+		annotation.points = append(annotation.points, space.framePoint(point))
+	}
+	return annotation, screenshotEditorAnnotationIsVisible(annotation)
+}
+
+// writeScreenshotProject stores the unannotated region of source and the annotation list. region and
+// selection are capture pixels, annotations use the logical frame of the finished editor session.
+func writeScreenshotProject(path string, source image.Image, region, selection image.Rectangle, annotations []screenshotEditorAnnotation, frame Size) error {
+	bounds := source.Bounds()
+	region = region.Intersect(bounds)
+	if region.Empty() {
+		return errors.New("screenshot project region is empty")
+	}
+	space, err := newScreenshotProjectSpace(bounds, frame, region.Min.Sub(bounds.Min))
+	if err != nil {
+		return err
+	}
+	cropped := image.NewRGBA(image.Rect(0, 0, region.Dx(), region.Dy()))
+	draw.Draw(cropped, cropped.Bounds(), source, region.Min, draw.Src)
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, cropped); err != nil {
+		return fmt.Errorf("encode screenshot project source: %w", err)
+	}
+
+	selection = selection.Sub(region.Min)
+	project := screenshotProject{
+		Version:     screenshotProjectVersion,
+		Source:      encoded.Bytes(),
+		Origin:      [2]int{space.origin.X, space.origin.Y},
+		Selection:   [4]float32{float32(selection.Min.X), float32(selection.Min.Y), float32(selection.Dx()), float32(selection.Dy())},
+		Annotations: []screenshotProjectAnnotation{},
+	}
+	for _, annotation := range annotations {
+		if stored, ok := space.encodeAnnotation(annotation); ok {
+			project.Annotations = append(project.Annotations, stored)
+		}
+	}
+	data, err := json.Marshal(project)
+	if err != nil {
+		return fmt.Errorf("encode screenshot project: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create screenshot project directory: %w", err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return fmt.Errorf("write screenshot project: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("replace screenshot project: %w", err)
+	}
+	return nil
+}
+
+// readScreenshotProject loads a project sidecar together with its decoded source pixels.
+func readScreenshotProject(path string) (screenshotProject, image.Image, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return screenshotProject{}, nil, fmt.Errorf("read screenshot project: %w", err)
+	}
+	var project screenshotProject
+	if err := json.Unmarshal(data, &project); err != nil {
+		return screenshotProject{}, nil, fmt.Errorf("parse screenshot project: %w", err)
+	}
+	if project.Version != screenshotProjectVersion {
+		return screenshotProject{}, nil, fmt.Errorf("unsupported screenshot project version: %d", project.Version)
+	}
+	source, err := png.Decode(bytes.NewReader(project.Source))
+	if err != nil {
+		return screenshotProject{}, nil, fmt.Errorf("decode screenshot project source: %w", err)
+	}
+	return project, source, nil
+}
+
+// placeScreenshotProject pastes the project source over a fresh desktop capture where it was cut
+// from. A source that no longer fits there, because the display layout changed, is shifted back
+// inside the desktop. The returned origin is the final position relative to the capture bounds.
+func placeScreenshotProject(desktop image.Image, source image.Image, origin image.Point) (*image.RGBA, image.Point, error) {
+	bounds := desktop.Bounds()
+	size := source.Bounds().Size()
+	if size.X > bounds.Dx() || size.Y > bounds.Dy() {
+		return nil, image.Point{}, fmt.Errorf("screenshot project %dx%d doesn't fit the %dx%d desktop", size.X, size.Y, bounds.Dx(), bounds.Dy())
+	}
+	origin = image.Pt(min(max(0, origin.X), bounds.Dx()-size.X), min(max(0, origin.Y), bounds.Dy()-size.Y))
+	composited := image.NewRGBA(bounds)
+	draw.Draw(composited, bounds, desktop, bounds.Min, draw.Src)
+	draw.Draw(composited, image.Rectangle{Min: bounds.Min.Add(origin), Max: bounds.Min.Add(origin).Add(size)}, source, source.Bounds().Min, draw.Src)
+	return composited, origin, nil
+}
+
+// applyProjectLocked restores the selection and marks of a reopened project in frame units. The
+// caller holds state.mu and the frame size must be known.
+func (state *screenshotEditorOverlayState) applyProjectLocked(frame Size) {
+	project := state.pendingProject
+	state.pendingProject = nil
+	if state.image == nil {
+		return
+	}
+	space, err := newScreenshotProjectSpace(image.Rect(0, 0, state.image.Width, state.image.Height), frame, state.projectOrigin)
+	if err != nil {
+		return
+	}
+	state.selection = normalizeScreenshotEditorRect(space.frameRect(project.Selection), frame)
+	state.hasSelection = state.selection.Width >= 2 && state.selection.Height >= 2
+	state.colorInspectorDismissed = true
+	state.annotations = nil
+	for _, stored := range project.Annotations {
+		annotation, ok := space.decodeAnnotation(stored)
+		if !ok {
+			continue
+		}
+		state.annotations = append(state.annotations, annotation)
+		if annotation.tool == screenshotEditorToolNumber {
+			state.nextNumber = max(state.nextNumber, annotation.number+1)
+		}
+	}
+}
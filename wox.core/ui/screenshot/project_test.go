@@ -0,0 +1,68 @@
+package screenshot
+
+import (
+	"image"
+	"image/color"
+	"path/filepath"
+	"testing"
+)
+
+func TestScreenshotProjectRoundTripsAcrossFrameScales(t *testing.T) {
+	source := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	source.SetRGBA(120, 60, color.RGBA{R: 10, G: 20, B: 30, A: 255})
+	annotations := []screenshotEditorAnnotation{
+		{tool: screenshotEditorToolRect, rect: Rect{X: 60, Y: 30, Width: 20, Height: 10}, color: Color{R: 1, G: 2, B: 3, A: 255}},
+		{tool: screenshotEditorToolPen, points: []Point{{X: 60, Y: 30}, {X: 70, Y: 40}}},
+		{tool: screenshotEditorToolNumber, start: Point{X: 90, Y: 50}, number: 4},
+	}
+	path := filepath.Join(t.TempDir(), "shot.png.project.json")
+	// a 200x100 logical frame over 400x200 pixels, the selection starts at pixel 100,50
+	if err := writeScreenshotProject(path, source, image.Rect(100, 50, 300, 150), image.Rect(100, 50, 300, 150), annotations, Size{Width: 200, Height: 100}); err != nil {
+		t.Fatalf("write project: %v", err)
+	}
+
+	project, projectSource, err := readScreenshotProject(path)
+	if err != nil {
+		t.Fatalf("read project: %v", err)
+	}
+	if project.Origin != [2]int{100, 50} || project.Selection != [4]float32{0, 0, 200, 100} || len(project.Annotations) != 3 {
+		t.Fatalf("project = origin %v selection %v annotations %d", project.Origin, project.Selection, len(project.Annotations))
+	}
+	if project.Annotations[0].Rect != [4]float32{20, 10, 40, 20} {
+		t.Fatalf("stored rect = %v, want source pixels", project.Annotations[0].Rect)
+	}
+
+	placed, origin, err := placeScreenshotProject(image.NewRGBA(image.Rect(0, 0, 400, 200)), projectSource, image.Pt(project.Origin[0], project.Origin[1]))
+	if err != nil {
+		t.Fatalf("place project: %v", err)
+	}
+	if got := placed.RGBAAt(120, 60); got != (color.RGBA{R: 10, G: 20, B: 30, A: 255}) {
+		t.Fatalf("project pixels were not restored: %+v", got)
+	}
+	uiImage, err := newScreenshotEditorImage(placed)
+	if err != nil {
+		t.Fatalf("prepare image: %v", err)
+	}
+	// the next session runs in physical pixels, like the Windows editor
+	state := &screenshotEditorOverlayState{image: uiImage, pendingProject: &project, projectOrigin: origin, nextNumber: 1}
+	state.applyProjectLocked(Size{Width: 400, Height: 200})
+	if state.selection != (Rect{X: 100, Y: 50, Width: 200, Height: 100}) || !state.hasSelection {
+		t.Fatalf("restored selection = %+v", state.selection)
+	}
+	if len(state.annotations) != 3 || state.annotations[0].rect != (Rect{X: 120, Y: 60, Width: 40, Height: 20}) || state.annotations[0].color != (Color{R: 1, G: 2, B: 3, A: 255}) {
+		t.Fatalf("restored annotations = %+v", state.annotations)
+	}
+	if state.annotations[1].points[1] != (Point{X: 140, Y: 80}) || state.nextNumber != 5 {
+		t.Fatalf("restored pen = %+v next number = %d", state.annotations[1].points, state.nextNumber)
+	}
+}
+
+func TestPlaceScreenshotProjectKeepsSourceInsideSmallerDesktop(t *testing.T) {
+	_, origin, err := placeScreenshotProject(image.NewRGBA(image.Rect(0, 0, 100, 80)), image.NewRGBA(image.Rect(0, 0, 40, 30)), image.Pt(90, -5))
+	if err != nil || origin != image.Pt(60, 0) {
+		t.Fatalf("origin = %v err = %v, want 60,0", origin, err)
+	}
+	if _, _, err := placeScreenshotProject(image.NewRGBA(image.Rect(0, 0, 30, 30)), image.NewRGBA(image.Rect(0, 0, 40, 30)), image.Point{}); err == nil {
+		t.Fatal("oversized project should not be placed")
+	}
+}
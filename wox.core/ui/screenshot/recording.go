@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"wox/util"
+	"wox/util/recordingexport"
 )
 
 const (
@@ -85,6 +86,7 @@ type recordingSessionConfig struct {
 	Capture      func() (image.Image, error)
 	Compose      func(image.Image) (*image.RGBA, error)
 	Encoder      recordingEncoder
+	Extension    string
 	Release      func()
 	TempRoot     string
 	Countdown    time.Duration
@@ -145,6 +147,9 @@ func newRecordingSession(config recordingSessionConfig) (*recordingSession, erro
 	if config.TempRoot == "" {
 		config.TempRoot = filepath.Join(os.TempDir(), "wox-recordings")
 	}
+	if config.Extension == "" {
+		config.Extension = ".mp4"
+	}
 	return &recordingSession{config: config, state: recordingStateReady}, nil
 }
 
@@ -310,7 +315,7 @@ func (session *recordingSession) Start(ctx context.Context) error {
 	if err := os.MkdirAll(session.config.TempRoot, 0o700); err != nil {
 		return session.fail(fmt.Errorf("create recording temp directory: %w", err))
 	}
-	file, err := os.CreateTemp(session.config.TempRoot, "wox-recording-*.mp4")
+	file, err := os.CreateTemp(session.config.TempRoot, "wox-recording-*"+session.config.Extension)
 	if err != nil {
 		return session.fail(fmt.Errorf("create recording temp file: %w", err))
 	}
@@ -324,7 +329,7 @@ func (session *recordingSession) Start(ctx context.Context) error {
 		return session.fail(err)
 	}
 	if session.config.Diagnostics {
-		util.GetLogger().Info(ctx, fmt.Sprintf("recording prepared: backend=ffmpeg pixels=%dx%d fps=%d", session.config.PixelBounds.Dx(), session.config.PixelBounds.Dy(), session.config.FPS))
+		util.GetLogger().Info(ctx, fmt.Sprintf("recording prepared: backend=%s pixels=%dx%d fps=%d", session.backendName(), session.config.PixelBounds.Dx(), session.config.PixelBounds.Dy(), session.config.FPS))
 	}
 	session.mu.Lock()
 	session.tempPath = tempPath
@@ -465,7 +470,7 @@ func (session *recordingSession) setRuntimeError(err error) {
 		cancel()
 	}
 	if session.config.Diagnostics {
-		util.GetLogger().Error(context.Background(), fmt.Sprintf("recording runtime failed: backend=ffmpeg pixels=%dx%d fps=%d written=%d dropped=%d reason=%v", session.config.PixelBounds.Dx(), session.config.PixelBounds.Dy(), session.config.FPS, atomic.LoadInt64(&session.framesWritten), atomic.LoadInt64(&session.framesDropped), err))
+		util.GetLogger().Error(context.Background(), fmt.Sprintf("recording runtime failed: backend=%s pixels=%dx%d fps=%d written=%d dropped=%d reason=%v", session.backendName(), session.config.PixelBounds.Dx(), session.config.PixelBounds.Dy(), session.config.FPS, atomic.LoadInt64(&session.framesWritten), atomic.LoadInt64(&session.framesDropped), err))
 	}
 	session.notifyChanged()
 }
@@ -606,11 +611,18 @@ func (session *recordingSession) Finish() (string, error) {
 		if samples > 0 {
 			captureAvgMs = float64(atomic.LoadInt64(&session.captureNanos)) / float64(samples) / 1e6
 		}
-		util.GetLogger().Info(context.Background(), fmt.Sprintf("recording finalized: backend=ffmpeg pixels=%dx%d fps=%d written=%d dropped=%d captureAvgMs=%.1f reason=completed", session.config.PixelBounds.Dx(), session.config.PixelBounds.Dy(), session.config.FPS, atomic.LoadInt64(&session.framesWritten), atomic.LoadInt64(&session.framesDropped), captureAvgMs))
+		util.GetLogger().Info(context.Background(), fmt.Sprintf("recording finalized: backend=%s pixels=%dx%d fps=%d written=%d dropped=%d captureAvgMs=%.1f reason=completed", session.backendName(), session.config.PixelBounds.Dx(), session.config.PixelBounds.Dy(), session.config.FPS, atomic.LoadInt64(&session.framesWritten), atomic.LoadInt64(&session.framesDropped), captureAvgMs))
 	}
 	return path, nil
 }
 
+func (session *recordingSession) backendName() string {
+	if session.config.Extension == ".gif" {
+		return "gif"
+	}
+	return "ffmpeg"
+}
+
 // TempPath returns the session MP4 while it remains available for preview or Save As.
 func (session *recordingSession) TempPath() string {
 	session.mu.Lock()
@@ -637,7 +649,7 @@ func (session *recordingSession) Cancel() error {
 	session.mu.Unlock()
 	session.notifyChanged()
 	if session.config.Diagnostics {
-		util.GetLogger().Info(context.Background(), fmt.Sprintf("recording ended: backend=ffmpeg pixels=%dx%d fps=%d written=%d dropped=%d reason=cancelled", session.config.PixelBounds.Dx(), session.config.PixelBounds.Dy(), session.config.FPS, atomic.LoadInt64(&session.framesWritten), atomic.LoadInt64(&session.framesDropped)))
+		util.GetLogger().Info(context.Background(), fmt.Sprintf("recording ended: backend=%s pixels=%dx%d fps=%d written=%d dropped=%d reason=cancelled", session.backendName(), session.config.PixelBounds.Dx(), session.config.PixelBounds.Dy(), session.config.FPS, atomic.LoadInt64(&session.framesWritten), atomic.LoadInt64(&session.framesDropped)))
 	}
 	if path != "" {
 		return os.Remove(path)
@@ -647,6 +659,20 @@ func (session *recordingSession) Cancel() error {
 
 // Save atomically publishes the finalized MP4 and removes the session temporary file.
 func (session *recordingSession) Save(target string) error {
+	return session.publish(func(source string) error {
+		return copyRecordingAtomically(source, target)
+	})
+}
+
+// Export publishes a trimmed, downsampled or converted copy of the finalized
+// recording and removes the session temporary file like Save.
+func (session *recordingSession) Export(ctx context.Context, target string, options recordingexport.Options) error {
+	return session.publish(func(source string) error {
+		return recordingexport.Export(ctx, source, target, options)
+	})
+}
+
+func (session *recordingSession) publish(write func(source string) error) error {
 	session.operationMu.Lock()
 	defer session.operationMu.Unlock()
 	session.mu.Lock()
@@ -657,7 +683,7 @@ func (session *recordingSession) Save(target string) error {
 	}
 	source := session.tempPath
 	session.mu.Unlock()
-	if err := copyRecordingAtomically(source, target); err != nil {
+	if err := write(source); err != nil {
 		return err
 	}
 	if err := os.Remove(source); err != nil && !os.IsNotExist(err) {
@@ -671,7 +697,7 @@ func (session *recordingSession) Save(target string) error {
 	return nil
 }
 
-// cleanupRecordingOrphans removes only stale MP4 files from the dedicated recording directory.
+// cleanupRecordingOrphans removes only stale MP4 and GIF files from the dedicated recording directory.
 func cleanupRecordingOrphans(root string, now time.Time, maxAge time.Duration) error {
 	entries, err := os.ReadDir(root)
 	if os.IsNotExist(err) {
@@ -681,7 +707,7 @@ func cleanupRecordingOrphans(root string, now time.Time, maxAge time.Duration) e
 		return err
 	}
 	for _, entry := range entries {
-		if entry.IsDir() || filepath.Ext(entry.Name()) != ".mp4" {
+		if entry.IsDir() || (filepath.Ext(entry.Name()) != ".mp4" && filepath.Ext(entry.Name()) != ".gif") {
 			continue
 		}
 		info, infoErr := entry.Info()
@@ -785,23 +811,7 @@ func (encoder *ffmpegRecordingEncoder) Start(path string, width, height, fps int
 
 // recordingFFmpegPath prefers the packaged, version-pinned runtime and retains a development fallback.
 func recordingFFmpegPath() (string, error) {
-	executable := "ffmpeg"
-	if runtime.GOOS == "windows" {
-		executable += ".exe"
-	}
-	dataRoot := util.GetLocation().GetWoxDataDirectory()
-	if dataRoot != "" {
-		packaged := filepath.Join(util.GetLocation().GetOthersDirectory(), "recording", runtime.GOOS+"-"+runtime.GOARCH, executable)
-		if util.IsFileExists(packaged) {
-			if runtime.GOOS != "windows" {
-				if err := os.Chmod(packaged, 0o755); err != nil {
-					return "", fmt.Errorf("make packaged recording runtime executable: %w", err)
-				}
-			}
-			return packaged, nil
-		}
-	}
-	return exec.LookPath("ffmpeg")
+	return recordingexport.FFmpegPath()
 }
 
 func (encoder *ffmpegRecordingEncoder) WriteFrame(frame recordingFrame) error {
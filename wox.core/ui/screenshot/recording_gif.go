@@ -0,0 +1,202 @@
+package screenshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"wox/util/recordingexport"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+const (
+	gifRecordingFPS      = 15
+	gifRecordingMaxWidth = 960
+)
+
+// newRecordingEncoder picks the H.264 encoder when an ffmpeg runtime exists and
+// falls back to the pure Go GIF encoder otherwise, so recording still works on
+// machines without the packaged runtime.
+func newRecordingEncoder() (recordingEncoder, string) {
+	if _, err := recordingFFmpegPath(); err != nil {
+		return &gifRecordingEncoder{}, ".gif"
+	}
+	return &ffmpegRecordingEncoder{}, ".mp4"
+}
+
+func isGIFRecording(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".gif")
+}
+
+// gifRecordingEncoder writes frames straight into an animated GIF. GIF frames
+// are far more expensive to encode than H.264 ones, so the stream is
+// downsampled to gifRecordingFPS and gifRecordingMaxWidth while recording.
+// Held frames only extend the delay of the previous frame instead of being
+// written again.
+type gifRecordingEncoder struct {
+	mu           sync.Mutex
+	file         *os.File
+	writer       *recordingexport.GIFWriter
+	fps          int
+	step         int64
+	abort        bool
+	pending      *image.Paletted
+	pendingIndex int64
+}
+
+func (encoder *gifRecordingEncoder) Start(path string, width, height, fps int) error {
+	if width <= 0 || height <= 0 || fps <= 0 {
+		return errors.New("GIF recording needs a positive size and fps")
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create GIF recording: %w", err)
+	}
+	outputWidth, outputHeight := width, height
+	if width > gifRecordingMaxWidth {
+		outputWidth, outputHeight = gifRecordingMaxWidth, max(1, height*gifRecordingMaxWidth/width)
+	}
+	writer, err := recordingexport.NewGIFWriter(file, outputWidth, outputHeight)
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("start GIF encoder: %w", err)
+	}
+	encoder.mu.Lock()
+	encoder.file = file
+	encoder.writer = writer
+	encoder.fps = fps
+	encoder.step = int64(max(1, fps/gifRecordingFPS))
+	encoder.abort = false
+	encoder.pending = nil
+	encoder.pendingIndex = 0
+	encoder.mu.Unlock()
+	return nil
+}
+
+func (encoder *gifRecordingEncoder) WriteFrame(frame recordingFrame) error {
+	encoder.mu.Lock()
+	defer encoder.mu.Unlock()
+	if encoder.writer == nil || encoder.abort {
+		return errors.New("GIF encoder is not running")
+	}
+	if frame.image == nil {
+		return errors.New("GIF frame is empty")
+	}
+	if encoder.pending != nil && frame.index/encoder.step <= encoder.pendingIndex/encoder.step {
+		return nil
+	}
+
+	// captured frames are BGRA like the H.264 pipe expects, GIF palettes are RGB
+	rgba := &image.RGBA{Pix: append([]byte(nil), frame.image.Pix...), Stride: frame.image.Stride, Rect: frame.image.Rect}
+	swapRecordingFrameRedBlue(rgba)
+	paletted := recordingexport.Quantize(recordingexport.ScaleFrame(rgba, gifRecordingMaxWidth))
+	if encoder.pending != nil {
+		if err := encoder.writer.WriteFrame(encoder.pending, encoder.frameDelay(frame.index-encoder.pendingIndex)); err != nil {
+			return err
+		}
+	}
+	encoder.pending = paletted
+	encoder.pendingIndex = frame.index
+	return nil
+}
+
+func (encoder *gifRecordingEncoder) frameDelay(frames int64) time.Duration {
+	return time.Duration(frames) * time.Second / time.Duration(encoder.fps)
+}
+
+func (encoder *gifRecordingEncoder) Finalize() error {
+	encoder.mu.Lock()
+	defer encoder.mu.Unlock()
+	writer, file := encoder.writer, encoder.file
+	encoder.writer, encoder.file = nil, nil
+	if writer == nil || file == nil {
+		return errors.New("GIF encoder is not running")
+	}
+	if encoder.pending == nil {
+		_ = file.Close()
+		return errors.New("GIF recording has no frames")
+	}
+	writeErr := writer.WriteFrame(encoder.pending, encoder.frameDelay(encoder.step))
+	encoder.pending = nil
+	if writeErr == nil {
+		writeErr = writer.Close()
+	}
+	closeErr := file.Close()
+	if writeErr != nil {
+		return fmt.Errorf("finalize GIF encoder: %w", writeErr)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("close GIF recording: %w", closeErr)
+	}
+	return nil
+}
+
+func (encoder *gifRecordingEncoder) Abort() error {
+	encoder.mu.Lock()
+	defer encoder.mu.Unlock()
+	encoder.abort = true
+	encoder.pending = nil
+	encoder.writer = nil
+	if encoder.file != nil {
+		_ = encoder.file.Close()
+		encoder.file = nil
+	}
+	return nil
+}
+
+// extractGIFPreviewFrame decodes the first GIF frame at the preview size.
+func extractGIFPreviewFrame(path string, width, height int) (*image.RGBA, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var poster *image.RGBA
+	errPosterReady := errors.New("poster ready")
+	err = recordingexport.ReadGIF(file, func(canvas *image.RGBA, _ time.Duration) error {
+		poster = scaleRecordingPreviewFrame(canvas, width, height)
+		return errPosterReady
+	})
+	if poster == nil {
+		if err == nil {
+			err = errors.New("GIF recording has no frames")
+		}
+		return nil, fmt.Errorf("decode recording poster: %w", err)
+	}
+	return poster, nil
+}
+
+// pumpGIFPreview plays the GIF at its own frame delays until it ends or ctx is done.
+func pumpGIFPreview(ctx context.Context, path string, width, height int, onFrame func(*image.RGBA) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return recordingexport.ReadGIF(file, func(canvas *image.RGBA, delay time.Duration) error {
+		if err := onFrame(scaleRecordingPreviewFrame(canvas, width, height)); err != nil {
+			return err
+		}
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return nil
+		}
+	})
+}
+
+func scaleRecordingPreviewFrame(canvas *image.RGBA, width, height int) *image.RGBA {
+	frame := image.NewRGBA(image.Rect(0, 0, width, height))
+	xdraw.ApproxBiLinear.Scale(frame, frame.Bounds(), canvas, canvas.Bounds(), xdraw.Src, nil)
+	return frame
+}
@@ -101,6 +101,9 @@ func extractRecordingPreviewFrame(path string, width, height int) (*image.RGBA,
 	if path == "" || width < 2 || height < 2 {
 		return nil, errors.New("recording preview frame is unavailable")
 	}
+	if isGIFRecording(path) {
+		return extractGIFPreviewFrame(path, width, height)
+	}
 	ffmpegPath, err := recordingFFmpegPath()
 	if err != nil {
 		return nil, err
@@ -153,6 +156,9 @@ func recordingRGBAFrame(pixels []byte, width, height int) (*image.RGBA, error) {
 
 // pumpRecordingPreview decodes the MP4 in real time and delivers packed RGBA frames until the context ends.
 func pumpRecordingPreview(ctx context.Context, path string, width, height int, onFrame func(*image.RGBA) error) error {
+	if isGIFRecording(path) {
+		return pumpGIFPreview(ctx, path, width, height, onFrame)
+	}
 	cmd, stdout, err := startRecordingPreviewDecode(ctx, path, width, height)
 	if err != nil {
 		return err
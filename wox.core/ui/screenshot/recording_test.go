@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"image"
 	"image/color"
+	"image/gif"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -757,9 +758,10 @@ func TestCleanupRecordingOrphansKeepsRecentAndUnrelatedFiles(t *testing.T) {
 	root := t.TempDir()
 	now := time.Now()
 	oldVideo := filepath.Join(root, "old.mp4")
+	oldGIF := filepath.Join(root, "old.gif")
 	recentVideo := filepath.Join(root, "recent.mp4")
 	unrelated := filepath.Join(root, "old.txt")
-	for _, path := range []string{oldVideo, recentVideo, unrelated} {
+	for _, path := range []string{oldVideo, oldGIF, recentVideo, unrelated} {
 		if err := os.WriteFile(path, []byte("test"), 0o600); err != nil {
 			t.Fatalf("create fixture: %v", err)
 		}
@@ -768,6 +770,9 @@ func TestCleanupRecordingOrphansKeepsRecentAndUnrelatedFiles(t *testing.T) {
 	if err := os.Chtimes(oldVideo, oldTime, oldTime); err != nil {
 		t.Fatalf("age old video: %v", err)
 	}
+	if err := os.Chtimes(oldGIF, oldTime, oldTime); err != nil {
+		t.Fatalf("age old gif: %v", err)
+	}
 	if err := os.Chtimes(unrelated, oldTime, oldTime); err != nil {
 		t.Fatalf("age unrelated file: %v", err)
 	}
@@ -777,6 +782,9 @@ func TestCleanupRecordingOrphansKeepsRecentAndUnrelatedFiles(t *testing.T) {
 	if _, err := os.Stat(oldVideo); !os.IsNotExist(err) {
 		t.Fatalf("old video was not removed: %v", err)
 	}
+	if _, err := os.Stat(oldGIF); !os.IsNotExist(err) {
+		t.Fatalf("old gif was not removed: %v", err)
+	}
 	for _, path := range []string{recentVideo, unrelated} {
 		if _, err := os.Stat(path); err != nil {
 			t.Fatalf("preserved file %q: %v", path, err)
@@ -1074,3 +1082,57 @@ func TestExtractRecordingPreviewFrameFromEncodedMP4(t *testing.T) {
 		t.Fatalf("preview bounds = %v, want 32x16", preview.Bounds())
 	}
 }
+
+func TestGIFRecordingEncoderDownsamplesAndKeepsTiming(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fallback.gif")
+	encoder := &gifRecordingEncoder{}
+	if err := encoder.Start(path, 64, 32, 30); err != nil {
+		t.Fatalf("start encoder: %v", err)
+	}
+	// BGRA input, blue channel first like the capture pipeline delivers it
+	frame := image.NewRGBA(image.Rect(0, 0, 64, 32))
+	for pixel := 0; pixel < len(frame.Pix); pixel += 4 {
+		frame.Pix[pixel], frame.Pix[pixel+1], frame.Pix[pixel+2], frame.Pix[pixel+3] = 200, 80, 40, 255
+	}
+	// 30 source frames with a capture gap after index 9 are one second at 30fps
+	for index := int64(0); index < 30; index++ {
+		if index > 9 && index < 20 {
+			continue
+		}
+		if err := encoder.WriteFrame(recordingFrame{image: frame, index: index}); err != nil {
+			t.Fatalf("encode frame: %v", err)
+		}
+	}
+	if err := encoder.Finalize(); err != nil {
+		t.Fatalf("finalize encoder: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open gif: %v", err)
+	}
+	defer file.Close()
+	decoded, err := gif.DecodeAll(file)
+	if err != nil {
+		t.Fatalf("decode gif: %v", err)
+	}
+	total := 0
+	for _, delay := range decoded.Delay {
+		total += delay
+	}
+	if len(decoded.Image) != 10 || total != 100 {
+		t.Fatalf("gif frames = %d total delay = %d, want 10 frames lasting 100", len(decoded.Image), total)
+	}
+	red, _, blue, _ := decoded.Image[0].At(4, 4).RGBA()
+	if red>>8 < 30 || red>>8 > 50 || blue>>8 < 190 {
+		t.Fatalf("gif color = %d/%d, want red and blue swapped back to RGB", red>>8, blue>>8)
+	}
+
+	preview, err := extractRecordingPreviewFrame(path, 32, 16)
+	if err != nil {
+		t.Fatalf("extract preview frame: %v", err)
+	}
+	if preview.Bounds().Dx() != 32 || preview.Bounds().Dy() != 16 {
+		t.Fatalf("preview bounds = %v, want 32x16", preview.Bounds())
+	}
+}
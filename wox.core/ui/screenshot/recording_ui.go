@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"wox/util/keyboard"
+	"wox/util/recordingexport"
 )
 
 const (
@@ -1224,6 +1225,7 @@ func (state *recordingToolbarState) newSession() (*recordingSession, error) {
 		captureFrame = func() (image.Image, error) { return captureFn() }
 		releaseCapture = releaseFn
 	}
+	encoder, fileExtension := newRecordingEncoder()
 	return newRecordingSession(recordingSessionConfig{
 		FPS: fps, ShowPointer: showPointer, ShowKeypress: showKeypress, PixelBounds: image.Rect(0, 0, pixelSelection.Dx(), pixelSelection.Dy()),
 		Capture: captureFrame,
@@ -1254,7 +1256,7 @@ func (state *recordingToolbarState) newSession() (*recordingSession, error) {
 			}
 			return frame, nil
 		},
-		Encoder: &ffmpegRecordingEncoder{}, Release: releaseCapture, Diagnostics: true, OnChanged: func() {
+		Encoder: encoder, Extension: fileExtension, Release: releaseCapture, Diagnostics: true, OnChanged: func() {
 			state.syncRecordingSurfaces()
 			if state.window != nil {
 				_ = state.window.Invalidate()
@@ -1450,10 +1452,13 @@ func (state *recordingToolbarState) saveRecording() {
 		state.stopPreview()
 		var target string
 		var saveErr error
+		// The temporary recording is MP4, or GIF when no ffmpeg runtime exists. Typing another
+		// supported extension in the dialog exports to that format instead of copying.
+		sourceExtension := filepath.Ext(session.TempPath())
 		callErr := Call(func() {
 			state.hidePreviewSurfacesForDialog()
-			defaultName := time.Now().Format("20060102_150405") + "_wox_recording.mp4"
-			target, saveErr = state.window.SaveFile(SaveFileOptions{Title: "Save recording", DefaultFileName: defaultName, Extension: "mp4"})
+			defaultName := time.Now().Format("20060102_150405") + "_wox_recording" + sourceExtension
+			target, saveErr = state.window.SaveFile(SaveFileOptions{Title: "Save recording", DefaultFileName: defaultName, Extension: strings.TrimPrefix(sourceExtension, ".")})
 			if target == "" || saveErr != nil {
 				state.restorePreviewSurfacesAfterDialog()
 			}
@@ -1477,10 +1482,16 @@ func (state *recordingToolbarState) saveRecording() {
 			return
 		}
 		if filepath.Ext(target) == "" {
-			target += ".mp4"
+			target += sourceExtension
 		}
-		if err := session.Save(target); err != nil {
-			state.setFinishError(err)
+		var publishErr error
+		if format, ok := recordingexport.FormatFromPath(target); ok && !strings.EqualFold(filepath.Ext(target), sourceExtension) {
+			publishErr = session.Export(context.Background(), target, recordingexport.DefaultOptions(format))
+		} else {
+			publishErr = session.Save(target)
+		}
+		if publishErr != nil {
+			state.setFinishError(publishErr)
 			return
 		}
 		state.complete(recordingUIResult{result: ScreenshotResult{
@@ -2,13 +2,16 @@ package screenshot
 
 import woxui "wox/ui/runtime"
 
-// ScreenshotOptions configures one interactive desktop-region capture.
+// ScreenshotOptions configures one interactive desktop-region capture. SaveProject keeps an
+// editable project sidecar next to the exported PNG, ProjectPath reopens such a project.
 type ScreenshotOptions struct {
 	ExportFilePath        string
 	CopyToClipboard       bool
 	HideAnnotationToolbar bool
 	AutoConfirm           bool
 	AllowVideoRecording   bool
+	SaveProject           bool
+	ProjectPath           string
 	RecordingDefaults     RecordingDefaults
 	WindowManager         *woxui.WindowManager
 	AnnotationTooltips    ScreenshotAnnotationTooltips
@@ -52,12 +55,16 @@ type ScreenshotActionTooltips struct {
 
 // ScreenshotAnnotationTooltips carries localized labels for the annotation creation tools.
 type ScreenshotAnnotationTooltips struct {
-	Rectangle string
-	Ellipse   string
-	Text      string
-	Arrow     string
-	Number    string
-	Mosaic    string
+	Rectangle   string
+	Ellipse     string
+	Text        string
+	Arrow       string
+	Number      string
+	Mosaic      string
+	Pen         string
+	Highlighter string
+	Blur        string
+	Crop        string
 }
 
 const ScreenshotWindowID WindowID = "wox.screenshot"
@@ -64,12 +64,12 @@ func (s *CoreServices) SubmitHotkeyRecordingCandidate(ctx context.Context, sessi
 }
 
 // CheckHotkeyAvailability checks Wox-owned and operating-system conflicts.
-func (s *CoreServices) CheckHotkeyAvailability(ctx context.Context, sessionID string, hotkey string) (contract.HotkeyAvailability, error) {
+func (s *CoreServices) CheckHotkeyAvailability(ctx context.Context, sessionID string, hotkey string, appIdentity string) (contract.HotkeyAvailability, error) {
 	hotkey = strings.TrimSpace(hotkey)
 	if hotkey == "" {
 		return contract.HotkeyAvailability{}, errors.New("hotkey is empty")
 	}
-	availability := GetUIManager().CheckHotkeyAvailability(uiServiceContext(ctx, sessionID), hotkey)
+	availability := GetUIManager().CheckHotkeyAvailability(uiServiceContext(ctx, sessionID), hotkey, appIdentity)
 	return contract.HotkeyAvailability{
 		Available: availability.Available, ConflictType: availability.ConflictType, ConflictValue: availability.ConflictValue,
 	}, nil
@@ -139,6 +139,40 @@ func (s *CoreServices) AISkills(ctx context.Context, sessionID string) ([]contra
 	return converted, nil
 }
 
+// AIMCPCatalog returns MCP prompts, resources, and server health from the active AI chat plugin.
+func (s *CoreServices) AIMCPCatalog(ctx context.Context, sessionID string) (contract.AIMCPCatalog, error) {
+	ctx = uiServiceContext(ctx, sessionID)
+	chater := plugin.GetPluginManager().GetAIChatPluginChater(ctx)
+	if chater == nil {
+		return contract.AIMCPCatalog{}, errors.New("ai chat plugin not found")
+	}
+	catalog := chater.GetMCPCatalog(ctx)
+	converted := contract.AIMCPCatalog{
+		Prompts:   make([]contract.AIMCPPrompt, len(catalog.Prompts)),
+		Resources: make([]contract.AIMCPResource, len(catalog.Resources)),
+		Servers:   make([]contract.AIMCPServerHealth, len(catalog.Servers)),
+	}
+	for index, prompt := range catalog.Prompts {
+		arguments := make([]contract.AIMCPPromptArgument, len(prompt.Arguments))
+		for argumentIndex, argument := range prompt.Arguments {
+			arguments[argumentIndex] = contract.AIMCPPromptArgument{Name: argument.Name, Description: argument.Description, Required: argument.Required}
+		}
+		converted.Prompts[index] = contract.AIMCPPrompt{ServerName: prompt.ServerName, Name: prompt.Name, Description: prompt.Description, Arguments: arguments}
+	}
+	for index, resource := range catalog.Resources {
+		converted.Resources[index] = contract.AIMCPResource{
+			ServerName: resource.ServerName, URI: resource.URI, Name: resource.Name, Description: resource.Description, MimeType: resource.MimeType,
+		}
+	}
+	for index, server := range catalog.Servers {
+		converted.Servers[index] = contract.AIMCPServerHealth{
+			Name: server.Name, State: string(server.State), Error: server.Error,
+			ToolCount: server.ToolCount, ResourceCount: server.ResourceCount, PromptCount: server.PromptCount,
+		}
+	}
+	return converted, nil
+}
+
 // CloneAISkills discovers skills from one remote repository.
 func (s *CoreServices) CloneAISkills(ctx context.Context, sessionID string, sourceURL string) ([]contract.AISkill, error) {
 	if strings.TrimSpace(sourceURL) == "" {
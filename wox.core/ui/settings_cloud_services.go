@@ -19,18 +19,24 @@ func (s *CoreServices) AccountStatus(ctx context.Context, sessionID string) (acc
 	return service.Status(uiServiceContext(ctx, sessionID)), nil
 }
 
-// CloudSyncStatus returns local sync state when the account is eligible and logged in.
+// CloudSyncStatus returns local sync state when the account is eligible and logged in,
+// or when a self-hosted backend is configured.
 func (s *CoreServices) CloudSyncStatus(ctx context.Context, sessionID string) (cloudsync.ServiceStatus, error) {
 	ctx = uiServiceContext(ctx, sessionID)
-	accountService := account.GetService()
-	if accountService == nil || !accountService.Status(ctx).LoggedIn {
-		return cloudsync.ServiceStatus{Enabled: false}, nil
-	}
 	service := cloudsync.GetService()
 	if service == nil {
 		return cloudsync.ServiceStatus{Enabled: false}, nil
 	}
-	return service.Status(ctx), nil
+	restartRequired := cloudSyncBackendRestartRequired(ctx, service)
+	if !service.SelfHosted() {
+		accountService := account.GetService()
+		if accountService == nil || !accountService.Status(ctx).LoggedIn {
+			return cloudsync.ServiceStatus{Enabled: false, RestartRequired: restartRequired}, nil
+		}
+	}
+	status := service.Status(ctx)
+	status.RestartRequired = restartRequired
+	return status, nil
 }
 
 // CloudDevices refreshes and returns devices associated with the current sync identity.
@@ -110,7 +116,8 @@ func (s *CoreServices) LogoutAccount(ctx context.Context, sessionID string) erro
 	if err := service.Logout(ctx); err != nil {
 		return err
 	}
-	if cloudService := cloudsync.GetService(); cloudService != nil {
+	// Self-hosted sync does not belong to the account, so it keeps running after logout.
+	if cloudService := cloudsync.GetService(); cloudService != nil && !cloudService.SelfHosted() {
 		if err := cloudService.ResetLocalState(ctx); err != nil {
 			logger.Warn(ctx, fmt.Sprintf("failed to reset cloud sync state during logout: %v", err))
 		}
@@ -55,6 +55,20 @@ func parseQueryHotkeysSettingValue(value string) ([]setting.QueryHotkey, error)
 		if rawPosition, ok := rawQueryHotkey["Position"]; ok {
 			queryHotkey.Position = normalizeQueryHotkeyPosition(parseString(rawPosition))
 		}
+		if rawApp, ok := rawQueryHotkey["App"]; ok && rawApp != nil {
+			encoded, err := json.Marshal(rawApp)
+			if err != nil {
+				return nil, err
+			}
+			if err := json.Unmarshal(encoded, &queryHotkey.App); err != nil {
+				return nil, fmt.Errorf("invalid query hotkey app: %w", err)
+			}
+			if apps := normalizeIgnoredHotkeyApps([]setting.IgnoredHotkeyApp{queryHotkey.App}); len(apps) == 1 {
+				queryHotkey.App = apps[0]
+			} else {
+				queryHotkey.App = setting.IgnoredHotkeyApp{}
+			}
+		}
 		queryHotkeys = append(queryHotkeys, queryHotkey)
 	}
 	return queryHotkeys, nil
@@ -101,7 +115,7 @@ func applyCloudSyncServerURL(ctx context.Context, url string) error {
 		return nil
 	}
 
-	if cloudService := cloudsync.GetService(); cloudService != nil {
+	if cloudService := cloudsync.GetService(); cloudService != nil && !cloudService.SelfHosted() {
 		if err := cloudService.ResetLocalState(ctx); err != nil {
 			util.GetLogger().Warn(ctx, fmt.Sprintf("failed to reset cloud sync state after server change: %v", err))
 		}
@@ -116,6 +130,34 @@ func applyCloudSyncServerURL(ctx context.Context, url string) error {
 	return accountService.ResetLocalSession(ctx)
 }
 
+// applyCloudSyncBackend validates and stores the sync backend. The running
+// manager keeps its client until the next start, so local sync state is reset
+// here to stop it from pushing to the old backend with the old key.
+func applyCloudSyncBackend(ctx context.Context, woxSetting *setting.WoxSetting, backend cloudsync.CloudSyncBackendConfig) error {
+	backend.Type = strings.TrimSpace(backend.Type)
+	backend.Url = strings.TrimSpace(backend.Url)
+	backend.Bucket = strings.TrimSpace(backend.Bucket)
+	backend.Region = strings.TrimSpace(backend.Region)
+	backend.Username = strings.TrimSpace(backend.Username)
+	if !backend.IsSelfHosted() {
+		backend = cloudsync.CloudSyncBackendConfig{}
+	} else if _, err := cloudsync.NewCloudSyncTransport(backend); err != nil {
+		return err
+	}
+	if backend == woxSetting.CloudSyncBackend.Get() {
+		return nil
+	}
+	if err := woxSetting.CloudSyncBackend.Set(backend); err != nil {
+		return err
+	}
+	if cloudService := cloudsync.GetService(); cloudService != nil {
+		if err := cloudService.ResetLocalState(ctx); err != nil {
+			util.GetLogger().Warn(ctx, fmt.Sprintf("failed to reset cloud sync state after backend change: %v", err))
+		}
+	}
+	return nil
+}
+
 func resolveCloudSyncServerURL(url string) string {
 	trimmed := strings.TrimRight(strings.TrimSpace(url), "/")
 	if trimmed == "" {
@@ -7,9 +7,11 @@ import (
 	"strconv"
 	"strings"
 
+	"wox/cloudsync"
 	"wox/common"
 	corehotkey "wox/hotkey"
 	"wox/i18n"
+	"wox/mcpserver"
 	"wox/plugin"
 	pluginhost "wox/plugin/host"
 	"wox/privacy"
@@ -50,7 +52,9 @@ func (s *CoreServices) GeneralSettings(ctx context.Context, sessionID string) (c
 		StartPage:                          woxSetting.StartPage.Get(),
 		AIProviders:                        append([]setting.AIProvider(nil), woxSetting.AIProviders.Get()...),
 		AIMCPServers:                       append([]common.AIChatMCPServerConfig(nil), woxSetting.AIMCPServers.Get()...),
+		AIToolApprovalRules:                append([]common.AIToolApprovalRule(nil), woxSetting.AIToolApprovalRules.Get()...),
 		AISkills:                           append([]common.Skill(nil), skills...),
+		MCPServerClients:                   append([]setting.MCPServerClient(nil), woxSetting.MCPServerClients.Get()...),
 		HTTPProxyEnabled:                   woxSetting.HttpProxyEnabled.Get(),
 		HTTPProxyURL:                       woxSetting.HttpProxyUrl.Get(),
 		ShowPosition:                       woxSetting.ShowPosition.Get(),
@@ -65,6 +69,8 @@ func (s *CoreServices) GeneralSettings(ctx context.Context, sessionID string) (c
 		CustomNodejsPath:                   woxSetting.CustomNodejsPath.Get(),
 		CloudSyncServerURL:                 woxSetting.CloudSyncServerUrl.Get(),
 		CloudSyncDisabledPlugins:           append([]string(nil), woxSetting.CloudSyncDisabledPlugins.Get()...),
+		CloudSyncDisabledEntities:          append([]string(nil), woxSetting.CloudSyncDisabledEntities.Get()...),
+		CloudSyncBackend:                   woxSetting.CloudSyncBackend.Get(),
 		AppWidth:                           woxSetting.AppWidth.Get(),
 		MaxResultCount:                     woxSetting.MaxResultCount.Get(),
 		UIDensity:                          woxSetting.UiDensity.Get(),
@@ -214,6 +220,20 @@ func (s *CoreServices) UpdateGeneralSetting(ctx context.Context, sessionID strin
 			return err
 		}
 		woxSetting.CloudSyncDisabledPlugins.Set(disabledPlugins)
+	case "CloudSyncDisabledEntities":
+		var disabledEntities []string
+		if err := json.Unmarshal([]byte(value), &disabledEntities); err != nil {
+			return err
+		}
+		woxSetting.CloudSyncDisabledEntities.Set(disabledEntities)
+	case "CloudSyncBackend":
+		var backend cloudsync.CloudSyncBackendConfig
+		if err := json.Unmarshal([]byte(value), &backend); err != nil {
+			return err
+		}
+		if err := applyCloudSyncBackend(ctx, woxSetting, backend); err != nil {
+			return err
+		}
 	case "TrayQueries":
 		trayQueries, err := decodeTrayQueries(value)
 		if err != nil {
@@ -238,12 +258,28 @@ func (s *CoreServices) UpdateGeneralSetting(ctx context.Context, sessionID strin
 			return err
 		}
 		woxSetting.AIMCPServers.Set(servers)
+	case "AIToolApprovalRules":
+		var rules []common.AIToolApprovalRule
+		if err := json.Unmarshal([]byte(value), &rules); err != nil {
+			return err
+		}
+		woxSetting.AIToolApprovalRules.Set(rules)
 	case "AISkills":
 		var skills []common.Skill
 		if err := json.Unmarshal([]byte(value), &skills); err != nil {
 			return err
 		}
 		woxSetting.AISkills.Set(skills)
+	case "MCPServerClients":
+		var clients []setting.MCPServerClient
+		if err := json.Unmarshal([]byte(value), &clients); err != nil {
+			return err
+		}
+		normalized, err := normalizeMCPServerClients(clients)
+		if err != nil {
+			return err
+		}
+		woxSetting.MCPServerClients.Set(normalized)
 	case "EnableAutoBackup":
 		woxSetting.EnableAutoBackup.Set(boolValue)
 	case "EnableAutoUpdate":
@@ -358,3 +394,33 @@ func decodeTrayQueries(value string) ([]setting.TrayQuery, error) {
 	}
 	return queries, nil
 }
+
+// normalizeMCPServerClients fills in a token for new clients and rejects weak or
+// shared tokens, so each external agent can be revoked on its own.
+func normalizeMCPServerClients(clients []setting.MCPServerClient) ([]setting.MCPServerClient, error) {
+	seen := make(map[string]string, len(clients))
+	normalized := make([]setting.MCPServerClient, 0, len(clients))
+	for _, client := range clients {
+		client.Name = strings.TrimSpace(client.Name)
+		client.Token = strings.TrimSpace(client.Token)
+		if client.Name == "" {
+			return nil, fmt.Errorf("MCP client name is required")
+		}
+		if client.Token == "" {
+			token, err := mcpserver.GenerateToken()
+			if err != nil {
+				return nil, err
+			}
+			client.Token = token
+		}
+		if len(client.Token) < mcpserver.MinTokenLength {
+			return nil, fmt.Errorf("MCP client %s: token must be at least %d characters", client.Name, mcpserver.MinTokenLength)
+		}
+		if other, ok := seen[client.Token]; ok {
+			return nil, fmt.Errorf("MCP clients %s and %s use the same token", other, client.Name)
+		}
+		seen[client.Token] = client.Name
+		normalized = append(normalized, client)
+	}
+	return normalized, nil
+}
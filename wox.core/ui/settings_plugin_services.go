@@ -41,7 +41,7 @@ func (s *CoreServices) Plugins(ctx context.Context, sessionID string, catalog co
 			Runtime: item.Runtime, Entry: item.Entry, PluginDirectory: item.PluginDirectory, Icon: item.Icon,
 			ScreenshotURLs: append([]string(nil), item.ScreenshotUrls...), TriggerKeywords: append([]string(nil), item.TriggerKeywords...),
 			Commands: append([]plugin.MetadataCommand(nil), item.Commands...), SupportedOS: append([]string(nil), item.SupportedOS...),
-			Features: append([]plugin.MetadataFeature(nil), item.Features...), Glances: append([]plugin.MetadataGlance(nil), item.Glances...),
+			Features: append([]plugin.MetadataFeature(nil), item.Features...), Glances: append([]plugin.MetadataGlance(nil), item.Glances...), Permissions: item.Permissions,
 			IsSystem: item.IsSystem, IsDev: item.IsDev, IsInstalled: item.IsInstalled, IsDisable: item.IsDisable, IsUpgradable: item.IsUpgradable,
 			SettingDefinitions: item.SettingDefinitions,
 			Setting: contract.PluginSetting{
@@ -113,6 +113,10 @@ func (s *CoreServices) UpdatePluginSettings(ctx context.Context, sessionID strin
 			}
 		case "TriggerKeywords":
 			instance.Setting.TriggerKeywords.Set(strings.Split(value, ","))
+		case plugin.PluginHotkeySettingKey:
+			if err := plugin.GetPluginManager().UpdatePluginHotkeySetting(ctx, instance, value); err != nil {
+				return fmt.Errorf("update plugin setting %q: %w", key, err)
+			}
 		default:
 			isPlatformSpecific := false
 			for _, settingDefinition := range instance.Metadata.SettingDefinitions {
@@ -58,8 +58,26 @@ const (
 	ClipboardTypeText  Type = "text"
 	ClipboardTypeImage Type = "image"
 	ClipboardTypeFile  Type = "file"
+	// ClipboardTypeHTML and ClipboardTypeRTF are formatted text. They are only
+	// reported when the source also offers plain text, which every browser and
+	// office suite does, so the plain alternative is always available.
+	ClipboardTypeHTML Type = "html"
+	ClipboardTypeRTF  Type = "rtf"
 )
 
+// Hints are markers the copying application attached to the clipboard content.
+// Password managers set them to ask clipboard history tools not to keep a value.
+type Hints struct {
+	// Concealed marks secrets such as passwords: org.nspasteboard.ConcealedType
+	// on macOS, ExcludeClipboardContentFromMonitorProcessing on Windows and
+	// x-kde-passwordManagerHint=secret on Linux.
+	Concealed bool
+	// Transient marks content the source app restores or clears by itself:
+	// org.nspasteboard.TransientType/AutoGeneratedType on macOS and
+	// CanIncludeInClipboardHistory=0 on Windows.
+	Transient bool
+}
+
 type Data interface {
 	GetType() Type
 	String() string
@@ -97,7 +115,7 @@ func Read() (Data, error) {
 		if err != nil {
 			return nil, err
 		}
-		return &TextData{Text: text}, nil
+		return &TextData{Text: text, Hints: readClipboardHints()}, nil
 	case ClipboardTypeImage:
 		img, err := readImage()
 		if err != nil {
@@ -110,6 +128,19 @@ func Read() (Data, error) {
 			return nil, err
 		}
 		return &FilePathData{FilePaths: paths}, nil
+	case ClipboardTypeHTML, ClipboardTypeRTF:
+		markup, err := readRichText(contentType)
+		if err != nil {
+			return nil, err
+		}
+		text, err := readText()
+		if err != nil {
+			return nil, err
+		}
+		if contentType == ClipboardTypeHTML {
+			return &HTMLData{HTML: markup, Text: text, Hints: readClipboardHints()}, nil
+		}
+		return &RTFData{RTF: markup, Text: text, Hints: readClipboardHints()}, nil
 	default:
 		return nil, noDataErr
 	}
@@ -156,6 +187,18 @@ func Write(data Data) error {
 		}
 		return err
 	}
+	if data.GetType() == ClipboardTypeHTML || data.GetType() == ClipboardTypeRTF {
+		markup, text := richTextMarkup(data)
+		err := writeRichTextData(data.GetType(), markup, text)
+		if errors.Is(err, notImplement) {
+			// Platforms without a rich writer still paste the plain alternative.
+			err = writeTextData(text)
+		}
+		if err != nil {
+			util.GetLogger().Error(context.Background(), fmt.Sprintf("clipboard: write %s failed: %v", data.GetType(), err))
+		}
+		return err
+	}
 
 	return errors.New("not implemented")
 }
@@ -297,7 +340,12 @@ func ReadText() (string, error) {
 		}
 		return "", err
 	}
-	if text, ok := data.(*TextData); ok {
+	switch text := data.(type) {
+	case *TextData:
+		return text.Text, nil
+	case *HTMLData:
+		return text.Text, nil
+	case *RTFData:
 		return text.Text, nil
 	}
 	return "", nil
@@ -305,6 +353,8 @@ func ReadText() (string, error) {
 
 type TextData struct {
 	Text string
+	// Hints are only filled for data read from the system clipboard and are not serialized.
+	Hints Hints
 }
 
 func (t *TextData) GetType() Type {
@@ -333,6 +383,92 @@ func (t *TextData) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// HTMLData is formatted text copied from browsers and editors. Text is the
+// plain alternative offered by the same source.
+type HTMLData struct {
+	HTML string
+	Text string
+	// Hints are only filled for data read from the system clipboard and are not serialized.
+	Hints Hints
+}
+
+func (h *HTMLData) GetType() Type {
+	return ClipboardTypeHTML
+}
+
+func (h *HTMLData) String() string {
+	return h.Text
+}
+
+func (h *HTMLData) MarshalJSON() ([]byte, error) {
+	var mapData = make(map[string]string)
+	mapData["html"] = h.HTML
+	mapData["text"] = h.Text
+	mapData["type"] = string(h.GetType())
+	return json.Marshal(mapData)
+}
+
+func (h *HTMLData) UnmarshalJSON(data []byte) error {
+	var mapData = make(map[string]string)
+	err := json.Unmarshal(data, &mapData)
+	if err != nil {
+		return err
+	}
+
+	h.HTML = mapData["html"]
+	h.Text = mapData["text"]
+	return nil
+}
+
+// RTFData is formatted text copied from word processors. Text is the plain
+// alternative offered by the same source.
+type RTFData struct {
+	RTF  string
+	Text string
+	// Hints are only filled for data read from the system clipboard and are not serialized.
+	Hints Hints
+}
+
+func (r *RTFData) GetType() Type {
+	return ClipboardTypeRTF
+}
+
+func (r *RTFData) String() string {
+	return r.Text
+}
+
+func (r *RTFData) MarshalJSON() ([]byte, error) {
+	var mapData = make(map[string]string)
+	mapData["rtf"] = r.RTF
+	mapData["text"] = r.Text
+	mapData["type"] = string(r.GetType())
+	return json.Marshal(mapData)
+}
+
+func (r *RTFData) UnmarshalJSON(data []byte) error {
+	var mapData = make(map[string]string)
+	err := json.Unmarshal(data, &mapData)
+	if err != nil {
+		return err
+	}
+
+	r.RTF = mapData["rtf"]
+	r.Text = mapData["text"]
+	return nil
+}
+
+// richTextMarkup returns the formatted payload and its plain alternative.
+func richTextMarkup(data Data) (string, string) {
+	switch rich := data.(type) {
+	case *HTMLData:
+		return rich.HTML, rich.Text
+	case *RTFData:
+		return rich.RTF, rich.Text
+	default:
+		return "", data.String()
+	}
+}
+
 type FilePathData struct {
 	FilePaths []string
 }
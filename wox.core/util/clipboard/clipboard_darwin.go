@@ -13,6 +13,7 @@ void WriteClipboardFiles(const char **filePaths, int count);
 void WriteClipboardImage(const char *imageData, int length);
 _Bool hasClipboardChanged();
 int GetClipboardContentType();
+int GetClipboardHints();
 */
 import "C"
 import (
@@ -51,6 +52,26 @@ func readText() (string, error) {
 	return "", noDataErr
 }
 
+// readClipboardHints checks the nspasteboard.org marker types that password
+// managers and clipboard-restoring apps add next to the text.
+func readClipboardHints() Hints {
+	hints := int(C.GetClipboardHints())
+	return Hints{
+		Concealed: hints&1 != 0,
+		Transient: hints&2 != 0,
+	}
+}
+
+// readRichText is not wired yet; readClipboardContentType never reports HTML
+// or RTF here, so formatted copies are captured as their plain text.
+func readRichText(contentType Type) (string, error) {
+	return "", notImplement
+}
+
+func writeRichTextData(contentType Type, markup string, text string) error {
+	return notImplement
+}
+
 func readFilePaths() ([]string, error) {
 	cstr := C.GetAllClipboardFilePaths()
 	if cstr != nil {
@@ -39,6 +39,27 @@ int GetClipboardContentType() {
     return 0;
 }
 
+// GetClipboardHints returns bit 1 for concealed and bit 2 for transient or
+// auto-generated content, following http://nspasteboard.org.
+int GetClipboardHints() {
+    @try {
+        NSArray *types = [[NSPasteboard generalPasteboard] types];
+        int hints = 0;
+        if ([types containsObject:@"org.nspasteboard.ConcealedType"]) {
+            hints |= 1;
+        }
+        if ([types containsObject:@"org.nspasteboard.TransientType"] ||
+            [types containsObject:@"org.nspasteboard.AutoGeneratedType"]) {
+            hints |= 2;
+        }
+        return hints;
+    }
+    @catch (NSException *exception) {
+        NSLog(@"Exception occurred: %@, %@", exception, [exception userInfo]);
+        return 0;
+    }
+}
+
 const char* GetClipboardText() {
     @try {
         NSPasteboard *pasteboard = [NSPasteboard generalPasteboard];
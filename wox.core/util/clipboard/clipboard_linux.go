@@ -15,6 +15,7 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf16"
 	"wox/util"
 )
 
@@ -23,19 +24,29 @@ const (
 	portalMimeTextPlain             = "text/plain"
 	portalMimeURIList               = "text/uri-list"
 	portalMimePNG                   = "image/png"
+	linuxMimeHTML                   = "text/html"
+	linuxMimeRTF                    = "text/rtf"
+	linuxMimePasswordManagerHint    = "x-kde-passwordManagerHint"
 	linuxClipboardNoDataLogInterval = 30 * time.Second
 	dataControlCacheDuration        = 100 * time.Millisecond
 )
 
+// linuxRTFMimeTypes are the RTF names in use: LibreOffice offers text/rtf and
+// text/richtext, while Qt and Java apps use application/rtf.
+var linuxRTFMimeTypes = []string{linuxMimeRTF, "application/rtf", "text/richtext"}
+
 // linuxClipboard is one desktop-session backend. Selection happens once so
 // X11 sessions never probe portal or Wayland data-control.
 type linuxClipboard interface {
 	name() string
 	readContentType() Type
 	readText() (string, error)
+	readHints() Hints
+	readRichText(contentType Type) (string, error)
 	readFilePaths() ([]string, error)
 	readImage() (image.Image, error)
 	writeText(text string) error
+	writeRichText(contentType Type, markup string, text string) error
 	writeFilePaths(paths []string) error
 	writeImageBytes(pngData []byte) error
 	isChanged() bool
@@ -98,6 +109,14 @@ func readText() (string, error) {
 	return linuxClipboardBackend().readText()
 }
 
+func readClipboardHints() Hints {
+	return linuxClipboardBackend().readHints()
+}
+
+func readRichText(contentType Type) (string, error) {
+	return linuxClipboardBackend().readRichText(contentType)
+}
+
 func readFilePaths() ([]string, error) {
 	return linuxClipboardBackend().readFilePaths()
 }
@@ -110,6 +129,10 @@ func writeTextData(text string) error {
 	return linuxClipboardBackend().writeText(text)
 }
 
+func writeRichTextData(contentType Type, markup string, text string) error {
+	return linuxClipboardBackend().writeRichText(contentType, markup, text)
+}
+
 func writeFilePaths(filePaths []string) error {
 	return linuxClipboardBackend().writeFilePaths(filePaths)
 }
@@ -153,7 +176,7 @@ func dataControlReadText() (string, error) {
 	if err != nil {
 		return "", err
 	}
-	if dataControlSelectionContentType(selection) != ClipboardTypeText {
+	if !isLinuxTextContentType(dataControlSelectionContentType(selection)) {
 		return "", noDataErr
 	}
 	text := strings.TrimRight(string(selection.data), "\x00")
@@ -163,6 +186,25 @@ func dataControlReadText() (string, error) {
 	return text, nil
 }
 
+// dataControlReadRichText reads the formatted alternative of the cached text
+// selection. The cached payload stays plain text so change detection and
+// text reads keep working on one transfer per poll.
+func dataControlReadRichText(contentType Type) (string, error) {
+	selection, err := readDataControlSelectionLocked(false)
+	if err != nil {
+		return "", err
+	}
+	mimeType := choosePortalMimeType(selection.mimeTypes, linuxRichTextMimeTypes(contentType)...)
+	if mimeType == "" {
+		return "", noDataErr
+	}
+	richSelection, err := readDataControlMIME(mimeType)
+	if err != nil {
+		return "", err
+	}
+	return decodeLinuxRichText(richSelection.data)
+}
+
 func dataControlReadFilePaths() ([]string, error) {
 	selection, err := readDataControlSelectionLocked(false)
 	if err != nil {
@@ -270,7 +312,7 @@ func dataControlSelectionContentType(selection dataControlSelection) Type {
 	case strings.EqualFold(selection.mimeType, portalMimeTextUTF8),
 		strings.EqualFold(selection.mimeType, portalMimeTextPlain),
 		strings.EqualFold(selection.mimeType, "UTF8_STRING"):
-		return ClipboardTypeText
+		return linuxTextContentType(selection.mimeTypes)
 	default:
 		return ""
 	}
@@ -284,11 +326,78 @@ func portalMimeTypesContentType(mimeTypes []string) Type {
 		return ClipboardTypeImage
 	}
 	if choosePortalMimeType(mimeTypes, portalMimeTextUTF8, portalMimeTextPlain, "UTF8_STRING", "STRING", "TEXT") != "" {
-		return ClipboardTypeText
+		return linuxTextContentType(mimeTypes)
 	}
 	return ""
 }
 
+// linuxTextContentType upgrades an offer that has plain text to HTML or RTF
+// when a formatted alternative is offered next to it. HTML wins because
+// browsers and office suites that offer both render it more faithfully.
+func linuxTextContentType(mimeTypes []string) Type {
+	if portalMimeTypesContain(mimeTypes, linuxMimeHTML) {
+		return ClipboardTypeHTML
+	}
+	if choosePortalMimeType(mimeTypes, linuxRTFMimeTypes...) != "" {
+		return ClipboardTypeRTF
+	}
+	return ClipboardTypeText
+}
+
+func isLinuxTextContentType(contentType Type) bool {
+	return contentType == ClipboardTypeText || contentType == ClipboardTypeHTML || contentType == ClipboardTypeRTF
+}
+
+// linuxRichTextMimeTypes lists the targets to try for a formatted type, most
+// common first. The first entry is also the type Wox offers when writing.
+func linuxRichTextMimeTypes(contentType Type) []string {
+	switch contentType {
+	case ClipboardTypeHTML:
+		return []string{linuxMimeHTML}
+	case ClipboardTypeRTF:
+		return linuxRTFMimeTypes
+	default:
+		return nil
+	}
+}
+
+// decodeLinuxRichText converts a formatted payload to a UTF-8 string. Firefox
+// and other GTK apps still serve text/html as UTF-16 with a byte order mark.
+func decodeLinuxRichText(data []byte) (string, error) {
+	if len(data) >= 2 && (data[0] == 0xFF && data[1] == 0xFE || data[0] == 0xFE && data[1] == 0xFF) {
+		littleEndian := data[0] == 0xFF
+		units := make([]uint16, 0, len(data)/2-1)
+		for i := 2; i+1 < len(data); i += 2 {
+			if littleEndian {
+				units = append(units, uint16(data[i])|uint16(data[i+1])<<8)
+			} else {
+				units = append(units, uint16(data[i])<<8|uint16(data[i+1]))
+			}
+		}
+		data = []byte(string(utf16.Decode(units)))
+	}
+	markup := strings.TrimRight(string(bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))), "\x00")
+	if strings.TrimSpace(markup) == "" {
+		return "", noDataErr
+	}
+	return markup, nil
+}
+
+// linuxClipboardHints reads the password manager hint KDE introduced and
+// KeePassXC and other managers also offer on X11 and wlroots compositors.
+func linuxClipboardHints(mimeTypes []string, read func(mimeType string) ([]byte, error)) Hints {
+	if !portalMimeTypesContain(mimeTypes, linuxMimePasswordManagerHint) {
+		return Hints{}
+	}
+	data, err := read(linuxMimePasswordManagerHint)
+	if err != nil {
+		// The offer alone is only made by password managers, so an unreadable
+		// value still marks the content as a secret.
+		return Hints{Concealed: true}
+	}
+	return Hints{Concealed: strings.EqualFold(strings.TrimSpace(strings.TrimRight(string(data), "\x00")), "secret")}
+}
+
 func choosePortalMimeType(mimeTypes []string, candidates ...string) string {
 	for _, candidate := range candidates {
 		if portalMimeTypesContain(mimeTypes, candidate) {
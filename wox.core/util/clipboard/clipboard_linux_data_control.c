@@ -179,6 +179,32 @@ static const char *wox_data_control_choose_mime(const WoxDataControlOffer *offer
     return NULL;
 }
 
+static const char *wox_data_control_find_mime(const WoxDataControlOffer *offer, const char *mime_type) {
+    for (size_t i = 0; i < offer->mime_count; i++) {
+        if (strcasecmp(offer->mime_types[i], mime_type) == 0) {
+            return offer->mime_types[i];
+        }
+    }
+    return NULL;
+}
+
+static int wox_data_control_copy_mime_types(const WoxDataControlOffer *offer, WoxDataControlReadResult *result) {
+    size_t size = 1;
+    for (size_t i = 0; i < offer->mime_count; i++) {
+        size += strlen(offer->mime_types[i]) + 1;
+    }
+    result->mime_types = calloc(size, 1);
+    if (result->mime_types == NULL) {
+        wox_data_control_set_error(result, "failed to allocate clipboard MIME type list");
+        return -1;
+    }
+    for (size_t i = 0; i < offer->mime_count; i++) {
+        strcat(result->mime_types, offer->mime_types[i]);
+        strcat(result->mime_types, "\n");
+    }
+    return 0;
+}
+
 static int wox_data_control_append(
     WoxDataControlReadResult *result,
     const uint8_t *data,
@@ -296,7 +322,9 @@ static void wox_data_control_state_destroy(WoxDataControlState *state) {
     }
 }
 
-int wox_data_control_read(WoxDataControlReadResult *result) {
+// wox_data_control_read_selection reads requested_mime when the selection offers
+// it, or the preferred type when requested_mime is NULL.
+static int wox_data_control_read_selection(const char *requested_mime, WoxDataControlReadResult *result) {
     memset(result, 0, sizeof(*result));
     WoxDataControlState state = {0};
 
@@ -335,7 +363,14 @@ int wox_data_control_read(WoxDataControlReadResult *result) {
         return 1;
     }
 
-    const char *mime_type = wox_data_control_choose_mime(state.selection);
+    if (wox_data_control_copy_mime_types(state.selection, result) != 0) {
+        wox_data_control_state_destroy(&state);
+        return -1;
+    }
+
+    const char *mime_type = requested_mime == NULL
+        ? wox_data_control_choose_mime(state.selection)
+        : wox_data_control_find_mime(state.selection, requested_mime);
     if (mime_type == NULL) {
         wox_data_control_state_destroy(&state);
         return 1;
@@ -355,11 +390,20 @@ int wox_data_control_read(WoxDataControlReadResult *result) {
     return 0;
 }
 
+int wox_data_control_read(WoxDataControlReadResult *result) {
+    return wox_data_control_read_selection(NULL, result);
+}
+
+int wox_data_control_read_mime(const char *mime_type, WoxDataControlReadResult *result) {
+    return wox_data_control_read_selection(mime_type, result);
+}
+
 void wox_data_control_read_result_free(WoxDataControlReadResult *result) {
     if (result == NULL) {
         return;
     }
     free(result->mime_type);
+    free(result->mime_types);
     free(result->data);
     free(result->error);
     memset(result, 0, sizeof(*result));
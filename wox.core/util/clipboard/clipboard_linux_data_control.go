@@ -5,6 +5,7 @@ package clipboard
 /*
 #cgo pkg-config: wayland-client
 #cgo CFLAGS: -D_GNU_SOURCE
+#include <stdlib.h>
 #include "clipboard_linux_data_control.h"
 */
 import "C"
@@ -15,9 +16,11 @@ import (
 )
 
 // dataControlSelection is a complete clipboard payload captured from one Wayland selection.
+// mimeTypes keeps every offered type so formatted alternatives can be read later.
 type dataControlSelection struct {
-	mimeType string
-	data     []byte
+	mimeType  string
+	mimeTypes []string
+	data      []byte
 }
 
 // readDataControlSelection reads the regular clipboard through ext-data-control-v1.
@@ -25,7 +28,21 @@ func readDataControlSelection() (dataControlSelection, error) {
 	var result C.WoxDataControlReadResult
 	status := C.wox_data_control_read(&result)
 	defer C.wox_data_control_read_result_free(&result)
+	return dataControlSelectionFromResult(status, &result)
+}
+
+// readDataControlMIME reads one specific offered type, such as text/html.
+func readDataControlMIME(mimeType string) (dataControlSelection, error) {
+	cMimeType := C.CString(mimeType)
+	defer C.free(unsafe.Pointer(cMimeType))
 
+	var result C.WoxDataControlReadResult
+	status := C.wox_data_control_read_mime(cMimeType, &result)
+	defer C.wox_data_control_read_result_free(&result)
+	return dataControlSelectionFromResult(status, &result)
+}
+
+func dataControlSelectionFromResult(status C.int, result *C.WoxDataControlReadResult) (dataControlSelection, error) {
 	if status == 1 {
 		return dataControlSelection{}, noDataErr
 	}
@@ -39,8 +56,13 @@ func readDataControlSelection() (dataControlSelection, error) {
 		return dataControlSelection{}, noDataErr
 	}
 
+	var mimeTypes []string
+	if result.mime_types != nil {
+		mimeTypes = splitClipboardLines(C.GoString(result.mime_types))
+	}
 	return dataControlSelection{
-		mimeType: C.GoString(result.mime_type),
-		data:     C.GoBytes(unsafe.Pointer(result.data), C.int(result.size)),
+		mimeType:  C.GoString(result.mime_type),
+		mimeTypes: mimeTypes,
+		data:      C.GoBytes(unsafe.Pointer(result.data), C.int(result.size)),
 	}, nil
 }
@@ -6,12 +6,15 @@
 
 typedef struct {
     char *mime_type;
+    // mime_types lists every type the selection offers, one per line.
+    char *mime_types;
     uint8_t *data;
     size_t size;
     char *error;
 } WoxDataControlReadResult;
 
 int wox_data_control_read(WoxDataControlReadResult *result);
+int wox_data_control_read_mime(const char *mime_type, WoxDataControlReadResult *result);
 void wox_data_control_read_result_free(WoxDataControlReadResult *result);
 
 #endif
@@ -5,10 +5,15 @@ package clipboard
 import "errors"
 
 type dataControlSelection struct {
-	mimeType string
-	data     []byte
+	mimeType  string
+	mimeTypes []string
+	data      []byte
 }
 
 func readDataControlSelection() (dataControlSelection, error) {
 	return dataControlSelection{}, errors.New("ext-data-control-v1 requires cgo")
 }
+
+func readDataControlMIME(mimeType string) (dataControlSelection, error) {
+	return dataControlSelection{}, errors.New("ext-data-control-v1 requires cgo")
+}
@@ -31,6 +31,20 @@ func (gnomeWaylandClipboard) readText() (string, error) {
 	return dataControlReadText()
 }
 
+func (gnomeWaylandClipboard) readHints() Hints {
+	if err := portalReady(); err == nil {
+		return portalReadHints()
+	}
+	return Hints{}
+}
+
+func (gnomeWaylandClipboard) readRichText(contentType Type) (string, error) {
+	if err := portalReady(); err == nil {
+		return portalReadRichText(contentType)
+	}
+	return dataControlReadRichText(contentType)
+}
+
 func (gnomeWaylandClipboard) readFilePaths() ([]string, error) {
 	if err := portalReady(); err == nil {
 		return portalReadFilePaths()
@@ -52,6 +66,13 @@ func (gnomeWaylandClipboard) writeText(text string) error {
 	return waylandCopy(portalMimeTextUTF8, []byte(text))
 }
 
+func (gnomeWaylandClipboard) writeRichText(contentType Type, markup string, text string) error {
+	if err := portalReady(); err == nil {
+		return portalWriteRichText(contentType, markup, text)
+	}
+	return waylandCopyRichText(contentType, markup, text)
+}
+
 func (gnomeWaylandClipboard) writeFilePaths(paths []string) error {
 	if err := portalReady(); err == nil {
 		return portalWriteFilePaths(paths)
@@ -36,6 +36,20 @@ func (kdeWaylandClipboard) readText() (string, error) {
 	return dataControlReadText()
 }
 
+func (kdeWaylandClipboard) readHints() Hints {
+	if err := portalReady(); err == nil {
+		return portalReadHints()
+	}
+	return Hints{}
+}
+
+func (kdeWaylandClipboard) readRichText(contentType Type) (string, error) {
+	if err := portalReady(); err == nil {
+		return portalReadRichText(contentType)
+	}
+	return dataControlReadRichText(contentType)
+}
+
 func (kdeWaylandClipboard) readFilePaths() ([]string, error) {
 	if err := portalReady(); err == nil {
 		return portalReadFilePaths()
@@ -57,6 +71,13 @@ func (kdeWaylandClipboard) writeText(text string) error {
 	return waylandCopy(portalMimeTextUTF8, []byte(text))
 }
 
+func (kdeWaylandClipboard) writeRichText(contentType Type, markup string, text string) error {
+	if err := portalReady(); err == nil {
+		return portalWriteRichText(contentType, markup, text)
+	}
+	return waylandCopyRichText(contentType, markup, text)
+}
+
 func (kdeWaylandClipboard) writeFilePaths(paths []string) error {
 	if err := portalReady(); err == nil {
 		return portalWriteFilePaths(paths)
@@ -95,6 +95,32 @@ func portalReadText() (string, error) {
 	return text, nil
 }
 
+func portalReadHints() Hints {
+	linuxPortalMu.Lock()
+	defer linuxPortalMu.Unlock()
+	if err := ensureLinuxPortalClipboardLocked(); err != nil {
+		return Hints{}
+	}
+	return linuxClipboardHints(linuxClipboardPortal.latest.mimeTypes, readLinuxPortalSelectionLocked)
+}
+
+func portalReadRichText(contentType Type) (string, error) {
+	linuxPortalMu.Lock()
+	defer linuxPortalMu.Unlock()
+	if err := ensureLinuxPortalClipboardLocked(); err != nil {
+		return "", err
+	}
+	mimeType := choosePortalMimeType(linuxClipboardPortal.latest.mimeTypes, linuxRichTextMimeTypes(contentType)...)
+	if mimeType == "" {
+		return "", noDataErr
+	}
+	data, err := readLinuxPortalSelectionLocked(mimeType)
+	if err != nil {
+		return "", err
+	}
+	return decodeLinuxRichText(data)
+}
+
 func portalReadFilePaths() ([]string, error) {
 	linuxPortalMu.Lock()
 	defer linuxPortalMu.Unlock()
@@ -152,6 +178,30 @@ func portalWriteText(text string) error {
 	)
 }
 
+// portalWriteRichText offers the markup together with the plain text, so
+// every target gets the richest type it accepts.
+func portalWriteRichText(contentType Type, markup string, text string) error {
+	mimeTypes := linuxRichTextMimeTypes(contentType)
+	if len(mimeTypes) == 0 {
+		return portalWriteText(text)
+	}
+	linuxPortalMu.Lock()
+	defer linuxPortalMu.Unlock()
+	if err := ensureLinuxPortalClipboardLocked(); err != nil {
+		return err
+	}
+	textPayload := []byte(text)
+	return setLinuxPortalSelectionLocked(
+		contentType,
+		[]string{mimeTypes[0], portalMimeTextUTF8, portalMimeTextPlain},
+		map[string][]byte{
+			mimeTypes[0]:        []byte(markup),
+			portalMimeTextUTF8:  textPayload,
+			portalMimeTextPlain: textPayload,
+		},
+	)
+}
+
 func portalWriteFilePaths(filePaths []string) error {
 	payload, err := buildPortalURIListPayload(filePaths)
 	if err != nil {
@@ -525,7 +575,7 @@ func handleLinuxPortalSelectionOwnerChangedLocked(signal *dbus.Signal) bool {
 	mimeTypes := portalVariantStringSlice(options["mime_types"])
 	contentType := portalMimeTypesContentType(mimeTypes)
 	fingerprint := strings.Join(mimeTypes, "\x00")
-	if contentType == ClipboardTypeText {
+	if isLinuxTextContentType(contentType) {
 		if mimeType := choosePortalMimeType(mimeTypes, portalMimeTextUTF8, portalMimeTextPlain); mimeType != "" {
 			if data, err := readLinuxPortalSelectionLocked(mimeType); err == nil {
 				fingerprint = "text:" + hashLinuxClipboardBytes(data)
@@ -3,6 +3,7 @@
 package clipboard
 
 import (
+	"errors"
 	"os"
 	"testing"
 
@@ -12,9 +13,10 @@ import (
 
 func TestDataControlSelectionContentType(t *testing.T) {
 	tests := []struct {
-		name     string
-		mimeType string
-		expected Type
+		name      string
+		mimeType  string
+		mimeTypes []string
+		expected  Type
 	}{
 		{name: "URI list", mimeType: "text/uri-list", expected: ClipboardTypeFile},
 		{name: "PNG", mimeType: "image/png", expected: ClipboardTypeImage},
@@ -22,11 +24,13 @@ func TestDataControlSelectionContentType(t *testing.T) {
 		{name: "plain text", mimeType: "text/plain", expected: ClipboardTypeText},
 		{name: "legacy UTF-8 text", mimeType: "UTF8_STRING", expected: ClipboardTypeText},
 		{name: "unsupported", mimeType: "text/html", expected: ""},
+		{name: "HTML alternative", mimeType: "text/plain;charset=utf-8", mimeTypes: []string{"text/html", "text/plain;charset=utf-8"}, expected: ClipboardTypeHTML},
+		{name: "RTF alternative", mimeType: "UTF8_STRING", mimeTypes: []string{"UTF8_STRING", "application/rtf"}, expected: ClipboardTypeRTF},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			assert.Equal(t, test.expected, dataControlSelectionContentType(dataControlSelection{mimeType: test.mimeType}))
+			assert.Equal(t, test.expected, dataControlSelectionContentType(dataControlSelection{mimeType: test.mimeType, mimeTypes: test.mimeTypes}))
 		})
 	}
 }
@@ -62,6 +66,39 @@ func TestX11TargetsContentType(t *testing.T) {
 	assert.Equal(t, ClipboardTypeText, x11TargetsContentType([]string{"UTF8_STRING", "STRING"}))
 	assert.Equal(t, ClipboardTypeText, x11TargetsContentType([]string{"text/plain;charset=utf-8"}))
 	assert.Equal(t, Type(""), x11TargetsContentType([]string{"TIMESTAMP", "TARGETS"}))
+	assert.Equal(t, ClipboardTypeHTML, x11TargetsContentType([]string{"text/html", "text/rtf", "UTF8_STRING"}))
+	assert.Equal(t, ClipboardTypeRTF, x11TargetsContentType([]string{"text/richtext", "STRING"}))
+	assert.Equal(t, ClipboardTypeImage, x11TargetsContentType([]string{"text/html", "image/png", "UTF8_STRING"}))
+	// Formatted text without a plain alternative is not captured.
+	assert.Equal(t, Type(""), x11TargetsContentType([]string{"text/html"}))
+}
+
+func TestDecodeLinuxRichText(t *testing.T) {
+	markup, err := decodeLinuxRichText([]byte("<b>Caf\xc3\xa9</b>\x00"))
+	require.NoError(t, err)
+	assert.Equal(t, "<b>Café</b>", markup)
+
+	// Firefox serves text/html as UTF-16LE with a byte order mark.
+	markup, err = decodeLinuxRichText([]byte{0xFF, 0xFE, '<', 0, 'i', 0, '>', 0, 0xE9, 0, '<', 0, '/', 0, 'i', 0, '>', 0})
+	require.NoError(t, err)
+	assert.Equal(t, "<i>é</i>", markup)
+
+	_, err = decodeLinuxRichText([]byte{0xEF, 0xBB, 0xBF, ' '})
+	assert.ErrorIs(t, err, noDataErr)
+}
+
+func TestLinuxClipboardHints(t *testing.T) {
+	readValue := func(value string, err error) func(string) ([]byte, error) {
+		return func(mimeType string) ([]byte, error) {
+			assert.Equal(t, "x-kde-passwordManagerHint", mimeType)
+			return []byte(value), err
+		}
+	}
+
+	assert.Equal(t, Hints{}, linuxClipboardHints([]string{"UTF8_STRING"}, readValue("", errors.New("not offered"))))
+	assert.Equal(t, Hints{Concealed: true}, linuxClipboardHints([]string{"UTF8_STRING", "x-kde-passwordManagerHint"}, readValue("secret\n", nil)))
+	assert.Equal(t, Hints{}, linuxClipboardHints([]string{"x-kde-passwordManagerHint"}, readValue("public", nil)))
+	assert.Equal(t, Hints{Concealed: true}, linuxClipboardHints([]string{"x-kde-passwordManagerHint"}, readValue("", noDataErr)))
 }
 
 func TestLinuxDataControlLiveRead(t *testing.T) {
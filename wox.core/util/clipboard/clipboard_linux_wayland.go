@@ -43,6 +43,34 @@ func (waylandClipboard) readText() (string, error) {
 	return waylandPasteText()
 }
 
+// readHints lists offered types through wl-paste because ext-data-control
+// reads only keep the selected payload.
+func (waylandClipboard) readHints() Hints {
+	bin, err := exec.LookPath("wl-paste")
+	if err != nil {
+		return Hints{}
+	}
+	output, err := runWaylandClipboardCommand(bin, []string{"--list-types"}, nil)
+	if err != nil {
+		return Hints{}
+	}
+	return linuxClipboardHints(splitClipboardLines(string(output)), func(mimeType string) ([]byte, error) {
+		return waylandPaste(mimeType)
+	})
+}
+
+func (waylandClipboard) readRichText(contentType Type) (string, error) {
+	markup, err := dataControlReadRichText(contentType)
+	if err == nil {
+		return markup, nil
+	}
+	data, err := waylandPaste(linuxRichTextMimeTypes(contentType)...)
+	if err != nil {
+		return "", err
+	}
+	return decodeLinuxRichText(data)
+}
+
 func (waylandClipboard) readFilePaths() ([]string, error) {
 	paths, err := dataControlReadFilePaths()
 	if err == nil {
@@ -63,6 +91,10 @@ func (waylandClipboard) writeText(text string) error {
 	return waylandCopy(portalMimeTextUTF8, []byte(text))
 }
 
+func (waylandClipboard) writeRichText(contentType Type, markup string, text string) error {
+	return waylandCopyRichText(contentType, markup, text)
+}
+
 func (waylandClipboard) writeFilePaths(paths []string) error {
 	payload, err := buildPortalURIListPayload(paths)
 	if err != nil {
@@ -94,6 +126,17 @@ func waylandCopy(mimeType string, payload []byte) error {
 	return runWaylandClipboardCommandErr(bin, []string{"--type", mimeType}, payload)
 }
 
+// waylandCopyRichText offers only the formatted type because wl-copy serves
+// one type per invocation; plain-text-only targets see no data until the next
+// copy.
+func waylandCopyRichText(contentType Type, markup string, text string) error {
+	mimeTypes := linuxRichTextMimeTypes(contentType)
+	if len(mimeTypes) == 0 {
+		return waylandCopy(portalMimeTextUTF8, []byte(text))
+	}
+	return waylandCopy(mimeTypes[0], []byte(markup))
+}
+
 func waylandPasteText() (string, error) {
 	data, err := waylandPaste(portalMimeTextUTF8, portalMimeTextPlain)
 	if err != nil {
@@ -67,6 +67,24 @@ func (c *x11Clipboard) readText() (string, error) {
 	return text, nil
 }
 
+func (c *x11Clipboard) readHints() Hints {
+	targets, err := c.readTargets()
+	if err != nil {
+		return Hints{}
+	}
+	return linuxClipboardHints(targets, func(mimeType string) ([]byte, error) {
+		return c.readMIME(mimeType)
+	})
+}
+
+func (c *x11Clipboard) readRichText(contentType Type) (string, error) {
+	data, err := c.readMIME(linuxRichTextMimeTypes(contentType)...)
+	if err != nil {
+		return "", err
+	}
+	return decodeLinuxRichText(data)
+}
+
 func (c *x11Clipboard) readFilePaths() ([]string, error) {
 	data, err := c.readMIME(portalMimeURIList)
 	if err != nil {
@@ -99,6 +117,21 @@ func (c *x11Clipboard) writeText(text string) error {
 	return c.writeMIME(x11MimeUTF8String, []byte(text))
 }
 
+// writeRichText offers only the formatted target: xclip serves a single target
+// per selection owner, so apps that only accept plain text see no data until
+// the next copy. xsel cannot set targets at all and writes the plain text.
+func (c *x11Clipboard) writeRichText(contentType Type, markup string, text string) error {
+	tool, err := c.clipboardTool()
+	if err != nil {
+		return err
+	}
+	mimeTypes := linuxRichTextMimeTypes(contentType)
+	if tool.kind == "xsel" || len(mimeTypes) == 0 {
+		return c.writeText(text)
+	}
+	return c.writeMIME(mimeTypes[0], []byte(markup))
+}
+
 func (c *x11Clipboard) writeFilePaths(paths []string) error {
 	payload, err := buildPortalURIListPayload(paths)
 	if err != nil {
@@ -126,6 +159,9 @@ func (c *x11Clipboard) isChanged() bool {
 		payload = []byte(strings.Join(paths, "\n"))
 	case ClipboardTypeImage:
 		payload, err = c.readMIME(portalMimePNG)
+	case ClipboardTypeHTML, ClipboardTypeRTF:
+		// Hash the markup so reformatting the same text still counts as a copy.
+		payload, err = c.readMIME(linuxRichTextMimeTypes(contentType)...)
 	default:
 		c.logNoData(noDataErr)
 		return false
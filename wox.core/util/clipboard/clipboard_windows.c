@@ -50,6 +50,38 @@ int clipboardGetContentType() {
     return CLIPBOARD_TYPE_EMPTY;
 }
 
+// clipboardGetHints reports the privacy formats password managers put next to
+// the text. ExcludeClipboardContentFromMonitorProcessing and the legacy
+// "Clipboard Viewer Ignore" ask monitors to skip the content entirely, while
+// CanIncludeInClipboardHistory=0 only keeps it out of clipboard history.
+int clipboardGetHints() {
+    int hints = 0;
+
+    UINT excludeFmt = RegisterClipboardFormatW(L"ExcludeClipboardContentFromMonitorProcessing");
+    UINT ignoreFmt = RegisterClipboardFormatW(L"Clipboard Viewer Ignore");
+    if ((excludeFmt != 0 && IsClipboardFormatAvailable(excludeFmt)) ||
+        (ignoreFmt != 0 && IsClipboardFormatAvailable(ignoreFmt))) {
+        hints |= CLIPBOARD_HINT_CONCEALED;
+    }
+
+    UINT historyFmt = RegisterClipboardFormatW(L"CanIncludeInClipboardHistory");
+    if (historyFmt != 0 && IsClipboardFormatAvailable(historyFmt) && openClipboardRetry()) {
+        HANDLE hMem = GetClipboardData(historyFmt);
+        if (hMem != NULL && GlobalSize(hMem) >= sizeof(DWORD)) {
+            DWORD *value = (DWORD *)GlobalLock(hMem);
+            if (value != NULL) {
+                if (*value == 0) {
+                    hints |= CLIPBOARD_HINT_TRANSIENT;
+                }
+                GlobalUnlock(hMem);
+            }
+        }
+        CloseClipboard();
+    }
+
+    return hints;
+}
+
 // clipboardReadText reads CF_UNICODETEXT from the clipboard.
 // On success, *outText is a malloc'd UTF-16 buffer, *outLen is the number of wchar_t characters (not including null).
 // Caller must free *outText. Returns 0 on success, negative on error.
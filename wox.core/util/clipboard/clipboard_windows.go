@@ -70,6 +70,24 @@ func readText() (string, error) {
 	return string(utf16Decode(u16)), nil
 }
 
+func readClipboardHints() Hints {
+	hints := int(C.clipboardGetHints())
+	return Hints{
+		Concealed: hints&C.CLIPBOARD_HINT_CONCEALED != 0,
+		Transient: hints&C.CLIPBOARD_HINT_TRANSIENT != 0,
+	}
+}
+
+// readRichText is not wired yet; readClipboardContentType never reports HTML
+// or RTF here, so formatted copies are captured as their plain text.
+func readRichText(contentType Type) (string, error) {
+	return "", notImplement
+}
+
+func writeRichTextData(contentType Type, markup string, text string) error {
+	return notImplement
+}
+
 func readFilePaths() ([]string, error) {
 	var cPaths *C.wchar_t
 	var cLen C.int
@@ -9,6 +9,10 @@
 #define CLIPBOARD_TYPE_IMAGE 2
 #define CLIPBOARD_TYPE_FILE  3
 
+// Hint bits returned by clipboardGetHints
+#define CLIPBOARD_HINT_CONCEALED 1
+#define CLIPBOARD_HINT_TRANSIENT 2
+
 // BitmapInfo is returned alongside DIB data so Go can decode the image.
 typedef struct {
     int headerSize;
@@ -29,6 +33,7 @@ int clipboardWriteFilePaths(const wchar_t *paths, int totalLen);
 int clipboardWriteImage(const unsigned char *pngData, int pngLen,
                         const unsigned char *dibData, int dibLen);
 DWORD clipboardGetSequenceNumber();
+int clipboardGetHints();
 int clipboardGetDiagnosticInfo(char *buf, int bufLen);
 
 #endif
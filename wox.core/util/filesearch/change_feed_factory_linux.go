@@ -0,0 +1,7 @@
+//go:build linux
+
+package filesearch
+
+func newPlatformChangeFeed() ChangeFeed {
+	return NewLinuxChangeFeed()
+}
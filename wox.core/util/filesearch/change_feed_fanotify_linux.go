@@ -0,0 +1,387 @@
+//go:build linux
+
+package filesearch
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+	"wox/util"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	fanotifyWatchMask = unix.FAN_CREATE | unix.FAN_DELETE | unix.FAN_MOVED_FROM | unix.FAN_MOVED_TO |
+		unix.FAN_MODIFY | unix.FAN_ATTRIB | unix.FAN_CLOSE_WRITE | unix.FAN_DELETE_SELF | unix.FAN_MOVE_SELF |
+		unix.FAN_ONDIR
+	fanotifyReadBufferSize = 256 * 1024
+	// fanotify reports every change on the marked filesystem, not only inside
+	// roots, so resolved directory handles are cached to keep unrelated system
+	// writes from paying an open_by_handle_at + readlink round trip each.
+	fanotifyDirCacheLimit = 4096
+	// fanotify_event_info_header (4 bytes) + __kernel_fsid_t (8 bytes) +
+	// file_handle header (8 bytes) precede the handle bytes.
+	fanotifyInfoFIDHeaderSize = 20
+)
+
+type fanotifyMount struct {
+	fsid    [2]int32
+	mountFD int
+	rootIDs map[string]struct{}
+}
+
+type fanotifyWatcher struct {
+	feed *LinuxChangeFeed
+	file *os.File
+	fd   int
+
+	mu         sync.Mutex
+	mounts     map[[2]int32]*fanotifyMount
+	dirCache   map[string]string
+	lastReadAt time.Time
+	closed     bool
+
+	catchUpMu sync.Mutex
+}
+
+func newFanotifyWatcher(feed *LinuxChangeFeed) (*fanotifyWatcher, error) {
+	fd, err := unix.FanotifyInit(
+		unix.FAN_CLASS_NOTIF|unix.FAN_CLOEXEC|unix.FAN_NONBLOCK|unix.FAN_REPORT_DFID_NAME|unix.FAN_UNLIMITED_QUEUE,
+		unix.O_RDONLY|unix.O_LARGEFILE|unix.O_CLOEXEC,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fanotify init: %w", err)
+	}
+
+	return &fanotifyWatcher{
+		feed:     feed,
+		file:     os.NewFile(uintptr(fd), "fanotify"),
+		fd:       fd,
+		mounts:   map[[2]int32]*fanotifyMount{},
+		dirCache: map[string]string{},
+	}, nil
+}
+
+func (w *fanotifyWatcher) start() {
+	w.mu.Lock()
+	w.lastReadAt = time.Now()
+	w.mu.Unlock()
+
+	util.Go(context.Background(), "filesearch fanotify read loop", w.readLoop)
+}
+
+func (w *fanotifyWatcher) close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	for _, mount := range w.mounts {
+		_ = unix.Close(mount.mountFD)
+	}
+	w.mounts = map[[2]int32]*fanotifyMount{}
+	w.dirCache = map[string]string{}
+	w.mu.Unlock()
+
+	return w.file.Close()
+}
+
+func (w *fanotifyWatcher) isClosed() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.closed
+}
+
+// addRoot marks the filesystem that holds root. Marks are per filesystem, so
+// roots sharing one only add bookkeeping; routing back to the root happens
+// through the feed's root matcher.
+func (w *fanotifyWatcher) addRoot(root RootRecord) error {
+	var statfs unix.Statfs_t
+	if err := unix.Statfs(root.Path, &statfs); err != nil {
+		return fmt.Errorf("statfs: %w", err)
+	}
+	fsid := statfs.Fsid.Val
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return os.ErrClosed
+	}
+	if mount, ok := w.mounts[fsid]; ok {
+		mount.rootIDs[root.ID] = struct{}{}
+		return nil
+	}
+
+	if err := unix.FanotifyMark(w.fd, unix.FAN_MARK_ADD|unix.FAN_MARK_FILESYSTEM, fanotifyWatchMask, unix.AT_FDCWD, root.Path); err != nil {
+		return fmt.Errorf("fanotify mark filesystem: %w", err)
+	}
+	mountFD, err := unix.Open(root.Path, unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		_ = unix.FanotifyMark(w.fd, unix.FAN_MARK_REMOVE|unix.FAN_MARK_FILESYSTEM, fanotifyWatchMask, unix.AT_FDCWD, root.Path)
+		return fmt.Errorf("open mount reference: %w", err)
+	}
+	// Events only carry directory file handles. Resolving them needs
+	// CAP_DAC_READ_SEARCH, so prove it works now rather than silently dropping
+	// every event later.
+	if _, err := resolveFanotifyHandlePath(mountFD, root.Path); err != nil {
+		_ = unix.Close(mountFD)
+		_ = unix.FanotifyMark(w.fd, unix.FAN_MARK_REMOVE|unix.FAN_MARK_FILESYSTEM, fanotifyWatchMask, unix.AT_FDCWD, root.Path)
+		return fmt.Errorf("resolve fanotify file handle: %w", err)
+	}
+
+	w.mounts[fsid] = &fanotifyMount{
+		fsid:    fsid,
+		mountFD: mountFD,
+		rootIDs: map[string]struct{}{root.ID: {}},
+	}
+	return nil
+}
+
+func (w *fanotifyWatcher) removeRoot(root RootRecord) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for fsid, mount := range w.mounts {
+		if _, ok := mount.rootIDs[root.ID]; !ok {
+			continue
+		}
+		delete(mount.rootIDs, root.ID)
+		if len(mount.rootIDs) > 0 {
+			return
+		}
+		_ = unix.FanotifyMark(w.fd, unix.FAN_MARK_REMOVE|unix.FAN_MARK_FILESYSTEM, fanotifyWatchMask, unix.AT_FDCWD, root.Path)
+		_ = unix.Close(mount.mountFD)
+		delete(w.mounts, fsid)
+		return
+	}
+}
+
+func (w *fanotifyWatcher) catchUp(root RootRecord, since time.Time, reason string) {
+	w.catchUpMu.Lock()
+	defer w.catchUpMu.Unlock()
+
+	util.GetLogger().Info(context.Background(), fmt.Sprintf(
+		"filesearch fanotify catch-up started: root=%s path=%s reason=%s since=%s",
+		root.ID,
+		summarizeLogPath(root.Path),
+		reason,
+		since.Format(time.RFC3339),
+	))
+	walk := linuxFeedWalk{
+		root:     root,
+		feedType: RootFeedTypeFanotify,
+		since:    since,
+		owns: func(dirPath string) bool {
+			return w.feed.ownsDirectory(root.ID, dirPath)
+		},
+		stopped: w.isClosed,
+		emit:    w.feed.emit,
+	}
+	walk.run(root.Path)
+}
+
+func (w *fanotifyWatcher) readLoop() {
+	buf := make([]byte, fanotifyReadBufferSize)
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			if w.isClosed() || errors.Is(err, os.ErrClosed) {
+				return
+			}
+			util.GetLogger().Warn(context.Background(), "filesearch fanotify read failed: "+err.Error())
+			w.requestCatchUpForAllRoots(time.Now().Add(-linuxFeedCatchUpSlack), "fanotify read failure")
+			return
+		}
+
+		w.mu.Lock()
+		previousReadAt := w.lastReadAt
+		w.lastReadAt = time.Now()
+		w.mu.Unlock()
+		w.handleBuffer(buf[:n], previousReadAt)
+	}
+}
+
+func (w *fanotifyWatcher) handleBuffer(buf []byte, previousReadAt time.Time) {
+	now := time.Now()
+	metadataSize := int(unsafe.Sizeof(unix.FanotifyEventMetadata{}))
+	offset := 0
+	for offset+metadataSize <= len(buf) {
+		metadata := (*unix.FanotifyEventMetadata)(unsafe.Pointer(&buf[offset]))
+		eventLen := int(metadata.Event_len)
+		if eventLen < metadataSize || offset+eventLen > len(buf) {
+			return
+		}
+		if metadata.Vers != unix.FANOTIFY_METADATA_VERSION {
+			util.GetLogger().Warn(context.Background(), fmt.Sprintf("filesearch fanotify metadata version mismatch: got=%d", metadata.Vers))
+			return
+		}
+		if metadata.Fd >= 0 {
+			_ = unix.Close(int(metadata.Fd))
+		}
+
+		mask := metadata.Mask
+		if mask&unix.FAN_Q_OVERFLOW != 0 {
+			w.requestCatchUpForAllRoots(previousReadAt.Add(-linuxFeedCatchUpSlack), "fanotify queue overflow")
+		} else {
+			infoStart := offset + int(metadata.Metadata_len)
+			w.handleEvent(buf[infoStart:offset+eventLen], uint32(mask), now)
+		}
+		offset += eventLen
+	}
+}
+
+func (w *fanotifyWatcher) handleEvent(info []byte, mask uint32, at time.Time) {
+	for len(info) >= fanotifyInfoFIDHeaderSize {
+		infoType := info[0]
+		infoLen := int(binary.NativeEndian.Uint16(info[2:4]))
+		if infoLen < fanotifyInfoFIDHeaderSize || infoLen > len(info) {
+			return
+		}
+		record := info[:infoLen]
+		info = info[infoLen:]
+		if infoType != unix.FAN_EVENT_INFO_TYPE_DFID_NAME && infoType != unix.FAN_EVENT_INFO_TYPE_DFID {
+			continue
+		}
+
+		var fsid [2]int32
+		fsid[0] = int32(binary.NativeEndian.Uint32(record[4:8]))
+		fsid[1] = int32(binary.NativeEndian.Uint32(record[8:12]))
+		handleBytes := int(binary.NativeEndian.Uint32(record[12:16]))
+		handleType := int32(binary.NativeEndian.Uint32(record[16:20]))
+		if fanotifyInfoFIDHeaderSize+handleBytes > len(record) {
+			return
+		}
+		handle := record[fanotifyInfoFIDHeaderSize : fanotifyInfoFIDHeaderSize+handleBytes]
+		name := ""
+		if infoType == unix.FAN_EVENT_INFO_TYPE_DFID_NAME {
+			name = strings.TrimRight(string(record[fanotifyInfoFIDHeaderSize+handleBytes:]), "\x00")
+		}
+
+		dirPath, ok := w.resolveDirectory(fsid, handleType, handle)
+		if !ok {
+			continue
+		}
+		eventPath := dirPath
+		if name != "" && name != "." {
+			eventPath = filepath.Join(dirPath, name)
+		}
+		if mask&unix.FAN_ONDIR != 0 && mask&(unix.FAN_MOVED_FROM|unix.FAN_DELETE) != 0 {
+			// Cached handles still resolve to the old path after a directory
+			// moves; drop the cache rather than tracking every descendant.
+			w.clearDirCache()
+		}
+		w.routeEvent(eventPath, name, mask, at)
+	}
+}
+
+func (w *fanotifyWatcher) routeEvent(eventPath string, name string, mask uint32, at time.Time) {
+	owner, ok := w.feed.copyRootSnapshot().findClean(filepath.Clean(eventPath))
+	if !ok {
+		return
+	}
+	if name == "" || name == "." {
+		// Directory self events are also reported against the parent entry.
+		// Only the root has no parent inside the tree.
+		if filepath.Clean(eventPath) != filepath.Clean(owner.Path) || mask&(unix.FAN_DELETE_SELF|unix.FAN_MOVE_SELF) == 0 {
+			return
+		}
+	}
+	if shouldSkipSystemPathForRoot(owner, eventPath, mask&unix.FAN_ONDIR != 0) {
+		return
+	}
+	signal, ok := translateLinuxFeedEvent(owner, RootFeedTypeFanotify, eventPath, mask, at)
+	if !ok {
+		return
+	}
+	w.feed.emit(signal)
+}
+
+func (w *fanotifyWatcher) resolveDirectory(fsid [2]int32, handleType int32, handle []byte) (string, bool) {
+	cacheKey := strconv.Itoa(int(fsid[0])) + ":" + strconv.Itoa(int(fsid[1])) + ":" + strconv.Itoa(int(handleType)) + ":" + string(handle)
+
+	w.mu.Lock()
+	if path, ok := w.dirCache[cacheKey]; ok {
+		w.mu.Unlock()
+		return path, true
+	}
+	mount, ok := w.mounts[fsid]
+	mountFD := -1
+	if ok {
+		mountFD = mount.mountFD
+	}
+	w.mu.Unlock()
+	if mountFD < 0 {
+		return "", false
+	}
+
+	fd, err := unix.OpenByHandleAt(mountFD, unix.NewFileHandle(handleType, handle), unix.O_PATH|unix.O_CLOEXEC)
+	if err != nil {
+		// ESTALE means the directory is already gone; its parent reports the
+		// removal with a resolvable handle.
+		return "", false
+	}
+	path, err := os.Readlink("/proc/self/fd/" + strconv.Itoa(fd))
+	_ = unix.Close(fd)
+	if err != nil || strings.HasSuffix(path, " (deleted)") {
+		return "", false
+	}
+
+	w.mu.Lock()
+	if len(w.dirCache) >= fanotifyDirCacheLimit {
+		w.dirCache = map[string]string{}
+	}
+	w.dirCache[cacheKey] = path
+	w.mu.Unlock()
+	return path, true
+}
+
+func (w *fanotifyWatcher) clearDirCache() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.dirCache = map[string]string{}
+}
+
+func (w *fanotifyWatcher) requestCatchUpForAllRoots(since time.Time, reason string) {
+	w.clearDirCache()
+
+	w.feed.mu.RLock()
+	roots := make([]RootRecord, 0, len(w.feed.fanotifyRootIDs))
+	for _, root := range w.feed.roots {
+		if _, ok := w.feed.fanotifyRootIDs[root.ID]; ok {
+			roots = append(roots, root)
+		}
+	}
+	w.feed.mu.RUnlock()
+
+	for _, root := range roots {
+		current := root
+		util.Go(context.Background(), "filesearch fanotify overflow catch-up", func() {
+			w.catchUp(current, since, reason)
+		})
+	}
+}
+
+// resolveFanotifyHandlePath round-trips path through name_to_handle_at and
+// open_by_handle_at, which is exactly what event resolution needs later.
+func resolveFanotifyHandlePath(mountFD int, path string) (string, error) {
+	handle, _, err := unix.NameToHandleAt(unix.AT_FDCWD, path, 0)
+	if err != nil {
+		return "", err
+	}
+	fd, err := unix.OpenByHandleAt(mountFD, handle, unix.O_PATH|unix.O_CLOEXEC)
+	if err != nil {
+		return "", err
+	}
+	defer unix.Close(fd)
+	return os.Readlink("/proc/self/fd/" + strconv.Itoa(fd))
+}
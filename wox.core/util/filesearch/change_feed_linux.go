@@ -0,0 +1,977 @@
+//go:build linux
+
+package filesearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+	"wox/util"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	inotifyWatchMask = unix.IN_CREATE | unix.IN_DELETE | unix.IN_MODIFY | unix.IN_ATTRIB | unix.IN_CLOSE_WRITE |
+		unix.IN_MOVED_FROM | unix.IN_MOVED_TO | unix.IN_DELETE_SELF | unix.IN_MOVE_SELF |
+		unix.IN_ONLYDIR | unix.IN_DONT_FOLLOW | unix.IN_EXCL_UNLINK
+	inotifyReadBufferSize = 64 * 1024
+	// Linux feeds report every file write inside large trees. Match the FSEvents
+	// buffer so ordinary bursts are not dropped before the scanner drains them.
+	linuxFeedSignalBufferSize = 8192
+	// Watch-limit failures and dropped signals are repaired by a slow background
+	// pass. The interval is long enough that a machine sitting at the inotify
+	// limit does not spin, but short enough that freed watches are reused.
+	linuxFeedMaintenanceInterval      = time.Minute
+	linuxFeedWatchLimitRetryInterval  = 5 * time.Minute
+	linuxFeedDroppedSignalLogInterval = 5 * time.Second
+)
+
+// LinuxChangeFeed watches whole root trees. fanotify filesystem marks are used
+// when the process has the capabilities for them; otherwise every root gets
+// its own recursive inotify instance, so a queue overflow only loses history
+// for that root. Roots that cannot get either fall back to the fsnotify feed.
+type LinuxChangeFeed struct {
+	mu              sync.RWMutex
+	roots           []RootRecord
+	rootMatcher     rootPathMatcher
+	inotify         map[string]*inotifyRootWatcher
+	fanotify        *fanotifyWatcher
+	fanotifyRootIDs map[string]struct{}
+	fanotifyTried   bool
+	fallback        *FallbackChangeFeed
+	fallbackRootIDs map[string]struct{}
+	signals         chan ChangeSignal
+	done            chan struct{}
+	closed          bool
+	maintenanceOnce sync.Once
+
+	droppedMu     sync.Mutex
+	droppedSince  map[string]time.Time
+	lastDropLog   time.Time
+	droppedLogged int
+}
+
+func NewLinuxChangeFeed() *LinuxChangeFeed {
+	feed := &LinuxChangeFeed{
+		inotify:         map[string]*inotifyRootWatcher{},
+		fanotifyRootIDs: map[string]struct{}{},
+		fallback:        NewFallbackChangeFeed(),
+		fallbackRootIDs: map[string]struct{}{},
+		signals:         make(chan ChangeSignal, linuxFeedSignalBufferSize),
+		done:            make(chan struct{}),
+		droppedSince:    map[string]time.Time{},
+	}
+
+	go feed.forwardFallbackSignals()
+
+	return feed
+}
+
+func (f *LinuxChangeFeed) Mode() string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	parts := make([]string, 0, 3)
+	if len(f.fanotifyRootIDs) > 0 {
+		parts = append(parts, string(RootFeedTypeFanotify))
+	}
+	if len(f.inotify) > 0 || len(parts) == 0 {
+		parts = append(parts, string(RootFeedTypeInotify))
+	}
+	if len(f.fallbackRootIDs) > 0 {
+		parts = append(parts, string(RootFeedTypeFallback))
+	}
+	return strings.Join(parts, "+")
+}
+
+func (f *LinuxChangeFeed) Signals() <-chan ChangeSignal {
+	return f.signals
+}
+
+func (f *LinuxChangeFeed) Refresh(ctx context.Context, roots []RootRecord) error {
+	now := time.Now()
+
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return nil
+	}
+	f.ensureFanotifyLocked()
+
+	previousRoots := f.roots
+	previousByID := make(map[string]RootRecord, len(previousRoots))
+	for _, root := range previousRoots {
+		previousByID[root.ID] = root
+	}
+	nextByID := make(map[string]RootRecord, len(roots))
+	for _, root := range roots {
+		nextByID[root.ID] = root
+	}
+
+	// Publish the new ownership snapshot before touching watches so events from
+	// a freshly promoted dynamic root are routed to it immediately.
+	f.roots = append([]RootRecord(nil), roots...)
+	f.rootMatcher = newRootPathMatcher(roots)
+
+	var stoppedWatchers []*inotifyRootWatcher
+	var releasedSubtrees []RootRecord
+	for rootID, previous := range previousByID {
+		next, kept := nextByID[rootID]
+		if kept && filepath.Clean(next.Path) == filepath.Clean(previous.Path) {
+			continue
+		}
+		if watcher, ok := f.inotify[rootID]; ok {
+			stoppedWatchers = append(stoppedWatchers, watcher)
+			delete(f.inotify, rootID)
+		}
+		if _, ok := f.fanotifyRootIDs[rootID]; ok {
+			f.fanotify.removeRoot(previous)
+			delete(f.fanotifyRootIDs, rootID)
+		}
+		delete(f.fallbackRootIDs, rootID)
+		releasedSubtrees = append(releasedSubtrees, previous)
+	}
+
+	var added []RootRecord
+	for _, root := range roots {
+		previous, existed := previousByID[root.ID]
+		if existed && filepath.Clean(previous.Path) == filepath.Clean(root.Path) {
+			if watcher, ok := f.inotify[root.ID]; ok {
+				watcher.setRoot(root)
+			}
+			continue
+		}
+		added = append(added, root)
+	}
+	sort.Slice(added, func(i, j int) bool {
+		return len(added[i].Path) < len(added[j].Path)
+	})
+
+	var fanotifyAdded, fallbackRoots []RootRecord
+	var inotifyAdded []*inotifyRootWatcher
+	for _, root := range added {
+		if f.fanotify != nil {
+			if err := f.fanotify.addRoot(root); err == nil {
+				f.fanotifyRootIDs[root.ID] = struct{}{}
+				fanotifyAdded = append(fanotifyAdded, root)
+				continue
+			} else {
+				util.GetLogger().Info(ctx, fmt.Sprintf(
+					"filesearch fanotify mark unavailable, using inotify: root=%s path=%s err=%s",
+					root.ID,
+					summarizeLogPath(root.Path),
+					err.Error(),
+				))
+			}
+		}
+
+		watcher, err := newInotifyRootWatcher(f, root)
+		if err != nil {
+			// inotify instances are limited by fs.inotify.max_user_instances.
+			// Keep the root observable through the root-only feed instead of
+			// failing the whole refresh.
+			util.GetLogger().Warn(ctx, fmt.Sprintf(
+				"filesearch inotify instance unavailable, using fallback: root=%s path=%s err=%s",
+				root.ID,
+				summarizeLogPath(root.Path),
+				err.Error(),
+			))
+			f.fallbackRootIDs[root.ID] = struct{}{}
+			continue
+		}
+		f.inotify[root.ID] = watcher
+		inotifyAdded = append(inotifyAdded, watcher)
+	}
+	for rootID := range f.fallbackRootIDs {
+		if root, ok := nextByID[rootID]; ok {
+			fallbackRoots = append(fallbackRoots, root)
+		}
+	}
+
+	// A new nested dynamic root owns its subtree from now on, and a removed one
+	// hands its subtree back to the closest remaining inotify root.
+	keptWatchers := make([]*inotifyRootWatcher, 0, len(f.inotify))
+	for _, watcher := range f.inotify {
+		keptWatchers = append(keptWatchers, watcher)
+	}
+	matcher := f.rootMatcher
+	fanotify := f.fanotify
+	f.mu.Unlock()
+
+	for _, watcher := range stoppedWatchers {
+		watcher.close()
+	}
+	if len(added) > 0 {
+		for _, watcher := range keptWatchers {
+			watcher.pruneForeignWatches(matcher)
+		}
+	}
+
+	fanotifyPrepared := prepareLinuxFeedRefresh(fanotifyAdded, RootFeedTypeFanotify, now, defaultFeedCursorSafeWindow)
+	for _, signal := range fanotifyPrepared.signals {
+		f.emit(signal)
+	}
+	for _, plan := range fanotifyPrepared.roots {
+		if plan.catchUpSince.IsZero() {
+			continue
+		}
+		rootPlan := plan
+		util.Go(context.Background(), "filesearch fanotify catch-up", func() {
+			fanotify.catchUp(rootPlan.root, rootPlan.catchUpSince, "startup")
+		})
+	}
+
+	inotifyRoots := make([]RootRecord, 0, len(inotifyAdded))
+	for _, watcher := range inotifyAdded {
+		inotifyRoots = append(inotifyRoots, watcher.currentRoot())
+	}
+	prepared := prepareLinuxFeedRefresh(inotifyRoots, RootFeedTypeInotify, now, defaultFeedCursorSafeWindow)
+	for _, signal := range prepared.signals {
+		f.emit(signal)
+	}
+	for index, watcher := range inotifyAdded {
+		watcher.start(prepared.roots[index].catchUpSince)
+	}
+
+	for _, released := range releasedSubtrees {
+		owner, ok := matcher.findClean(filepath.Clean(released.Path))
+		if !ok || owner.ID == released.ID {
+			continue
+		}
+		f.mu.RLock()
+		watcher := f.inotify[owner.ID]
+		f.mu.RUnlock()
+		if watcher == nil {
+			continue
+		}
+		releasedPath := released.Path
+		util.Go(context.Background(), "filesearch inotify adopt released subtree", func() {
+			watcher.registerTree(releasedPath, now.Add(-linuxFeedCatchUpSlack))
+		})
+	}
+
+	f.maintenanceOnce.Do(func() {
+		util.Go(context.Background(), "filesearch linux change feed maintenance", f.maintenanceLoop)
+	})
+
+	// Bug fix: the recursive watchers must outlive the Refresh caller context,
+	// because refreshes run from short indexing tasks. Only the root-only
+	// fallback keeps its historical ctx-scoped lifetime.
+	return f.fallback.Refresh(ctx, fallbackRoots)
+}
+
+func (f *LinuxChangeFeed) Close() error {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return nil
+	}
+	f.closed = true
+	close(f.done)
+	watchers := make([]*inotifyRootWatcher, 0, len(f.inotify))
+	for _, watcher := range f.inotify {
+		watchers = append(watchers, watcher)
+	}
+	f.inotify = map[string]*inotifyRootWatcher{}
+	fanotify := f.fanotify
+	f.fanotify = nil
+	f.fanotifyRootIDs = map[string]struct{}{}
+	f.roots = nil
+	f.rootMatcher = rootPathMatcher{}
+	f.mu.Unlock()
+
+	for _, watcher := range watchers {
+		watcher.close()
+	}
+	var closeErr error
+	if fanotify != nil {
+		closeErr = fanotify.close()
+	}
+	if err := f.fallback.Close(); err != nil && closeErr == nil {
+		closeErr = err
+	}
+	return closeErr
+}
+
+func (f *LinuxChangeFeed) SnapshotRootFeed(ctx context.Context, root RootRecord) (RootFeedSnapshot, error) {
+	_ = ctx
+	feedType := f.feedTypeForRoot(root)
+	if feedType == RootFeedTypeFallback {
+		return f.fallback.SnapshotRootFeed(ctx, root)
+	}
+
+	// inotify and fanotify have no journal to replay, so the cursor is the
+	// acknowledgement time. The next start compares ctime against it instead of
+	// rescanning the whole root.
+	return RootFeedSnapshot{
+		FeedType:   feedType,
+		FeedCursor: encodeLinuxFeedCursor(feedType, time.Now()),
+		FeedState:  RootFeedStateReady,
+	}, nil
+}
+
+func (f *LinuxChangeFeed) feedTypeForRoot(root RootRecord) RootFeedType {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.fanotifyRootIDs[root.ID]; ok {
+		return RootFeedTypeFanotify
+	}
+	if _, ok := f.inotify[root.ID]; ok {
+		return RootFeedTypeInotify
+	}
+	if _, ok := f.fallbackRootIDs[root.ID]; ok {
+		return RootFeedTypeFallback
+	}
+	// Full scans capture the snapshot before the root reaches Refresh. Report
+	// the backend the root will get so the stored cursor is already usable.
+	f.ensureFanotifyLocked()
+	if f.fanotify != nil {
+		return RootFeedTypeFanotify
+	}
+	return RootFeedTypeInotify
+}
+
+func (f *LinuxChangeFeed) ensureFanotifyLocked() {
+	if f.fanotifyTried || f.closed {
+		return
+	}
+	f.fanotifyTried = true
+
+	watcher, err := newFanotifyWatcher(f)
+	if err != nil {
+		// fanotify filesystem marks need CAP_SYS_ADMIN, which desktop sessions
+		// normally do not have. inotify is the expected path, not a failure.
+		util.GetLogger().Info(context.Background(), "filesearch fanotify unavailable, using inotify: "+err.Error())
+		return
+	}
+	f.fanotify = watcher
+	watcher.start()
+}
+
+func (f *LinuxChangeFeed) copyRootSnapshot() rootPathMatcher {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.rootMatcher
+}
+
+func (f *LinuxChangeFeed) ownsDirectory(rootID string, dirPath string) bool {
+	matcher := f.copyRootSnapshot()
+	owner, ok := matcher.findClean(dirPath)
+	return ok && owner.ID == rootID
+}
+
+func (f *LinuxChangeFeed) isClosed() bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.closed
+}
+
+func (f *LinuxChangeFeed) forwardFallbackSignals() {
+	for {
+		select {
+		case <-f.done:
+			return
+		case signal := <-f.fallback.Signals():
+			f.emit(signal)
+		}
+	}
+}
+
+func (f *LinuxChangeFeed) emit(signal ChangeSignal) {
+	if signal.RootID == "" {
+		return
+	}
+	if signal.At.IsZero() {
+		signal.At = time.Now()
+	}
+	if f.isClosed() {
+		return
+	}
+
+	select {
+	case f.signals <- signal:
+	default:
+		f.recordDroppedSignal(signal)
+	}
+}
+
+// recordDroppedSignal turns a full signal channel into a bounded catch-up
+// instead of a silent loss. The maintenance loop later walks the owning root
+// with the earliest dropped timestamp as the ctime bound.
+func (f *LinuxChangeFeed) recordDroppedSignal(signal ChangeSignal) {
+	f.droppedMu.Lock()
+	defer f.droppedMu.Unlock()
+
+	if since, ok := f.droppedSince[signal.RootID]; !ok || signal.At.Before(since) {
+		f.droppedSince[signal.RootID] = signal.At
+	}
+	f.droppedLogged++
+	now := time.Now()
+	if !f.lastDropLog.IsZero() && now.Sub(f.lastDropLog) < linuxFeedDroppedSignalLogInterval {
+		return
+	}
+	dropped := f.droppedLogged
+	f.droppedLogged = 0
+	f.lastDropLog = now
+	util.GetLogger().Warn(context.Background(), fmt.Sprintf(
+		"filesearch linux change feed signal dropped: channel_full=true dropped_since_last=%d root=%s path=%s",
+		dropped,
+		signal.RootID,
+		summarizeLogPath(signal.Path),
+	))
+}
+
+func (f *LinuxChangeFeed) maintenanceLoop() {
+	ticker := time.NewTicker(linuxFeedMaintenanceInterval)
+	defer ticker.Stop()
+
+	lastWatchRetry := time.Now()
+	for {
+		select {
+		case <-f.done:
+			return
+		case now := <-ticker.C:
+			f.recoverDroppedSignals()
+			if now.Sub(lastWatchRetry) >= linuxFeedWatchLimitRetryInterval {
+				lastWatchRetry = now
+				f.mu.RLock()
+				watchers := make([]*inotifyRootWatcher, 0, len(f.inotify))
+				for _, watcher := range f.inotify {
+					watchers = append(watchers, watcher)
+				}
+				f.mu.RUnlock()
+				for _, watcher := range watchers {
+					watcher.retryUnwatched()
+				}
+			}
+		}
+	}
+}
+
+func (f *LinuxChangeFeed) recoverDroppedSignals() {
+	f.droppedMu.Lock()
+	dropped := f.droppedSince
+	f.droppedSince = map[string]time.Time{}
+	f.droppedMu.Unlock()
+
+	for rootID, since := range dropped {
+		f.mu.RLock()
+		watcher := f.inotify[rootID]
+		_, onFanotify := f.fanotifyRootIDs[rootID]
+		fanotify := f.fanotify
+		var root RootRecord
+		for _, candidate := range f.roots {
+			if candidate.ID == rootID {
+				root = candidate
+				break
+			}
+		}
+		f.mu.RUnlock()
+
+		since = since.Add(-linuxFeedCatchUpSlack)
+		switch {
+		case watcher != nil:
+			watcher.catchUp(since, "dropped signals")
+		case onFanotify && fanotify != nil && root.ID != "":
+			fanotify.catchUp(root, since, "dropped signals")
+		}
+	}
+}
+
+// linuxFeedWalk visits every directory of one root scope. inotify uses it to
+// register watches and both backends use it to catch up on changes whose
+// events were never delivered (offline gap, queue overflow, dropped signals).
+type linuxFeedWalk struct {
+	root     RootRecord
+	feedType RootFeedType
+	since    time.Time
+	// visitDir returns false when the directory must not be descended into,
+	// for example because inotify ran out of watches for it.
+	visitDir func(dirPath string) bool
+	owns     func(dirPath string) bool
+	stopped  func() bool
+	emit     func(ChangeSignal)
+}
+
+func (w linuxFeedWalk) run(scopePath string) {
+	type walkItem struct {
+		path string
+		// covered is true when an ancestor already requested a subtree
+		// reconcile, so changed descendants do not need their own signal.
+		covered bool
+	}
+
+	scopePath = filepath.Clean(scopePath)
+	now := time.Now()
+	stack := []walkItem{{path: scopePath}}
+	for len(stack) > 0 {
+		if w.stopped != nil && w.stopped() {
+			return
+		}
+
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if current.path != scopePath && w.owns != nil && !w.owns(current.path) {
+			// Nested dynamic roots own their subtree and run their own watcher.
+			continue
+		}
+		if shouldSkipSystemPathForRoot(w.root, current.path, true) {
+			continue
+		}
+		if w.visitDir != nil && !w.visitDir(current.path) {
+			continue
+		}
+
+		covered := current.covered
+		if !covered && !w.since.IsZero() {
+			if info, err := os.Lstat(current.path); err == nil && linuxFeedCatchUpNeeded(statChangeTime(info), w.since) {
+				w.emit(newLinuxFeedCatchUpSignal(w.root, w.feedType, current.path, true, now))
+				covered = true
+			}
+		}
+
+		entries, err := os.ReadDir(current.path)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			entryPath := filepath.Join(current.path, entry.Name())
+			if entry.IsDir() {
+				stack = append(stack, walkItem{path: entryPath, covered: covered})
+				continue
+			}
+			if covered || w.since.IsZero() {
+				continue
+			}
+			info, infoErr := entry.Info()
+			if infoErr != nil || !linuxFeedCatchUpNeeded(statChangeTime(info), w.since) {
+				continue
+			}
+			if shouldSkipSystemPathForRoot(w.root, entryPath, false) {
+				continue
+			}
+			w.emit(newLinuxFeedCatchUpSignal(w.root, w.feedType, entryPath, false, now))
+		}
+	}
+}
+
+func statChangeTime(info os.FileInfo) time.Time {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return time.Unix(int64(stat.Ctim.Sec), int64(stat.Ctim.Nsec))
+	}
+	return info.ModTime()
+}
+
+type inotifyRootWatcher struct {
+	feed *LinuxChangeFeed
+	file *os.File
+	fd   int
+
+	mu         sync.Mutex
+	root       RootRecord
+	watches    map[int32]string
+	paths      map[string]int32
+	unwatched  map[string]struct{}
+	lastReadAt time.Time
+	closed     bool
+	limitNoted bool
+
+	// catchUpMu serializes overflow and dropped-signal walks so an overflow
+	// storm cannot start one tree walk per overflow event.
+	catchUpMu sync.Mutex
+}
+
+func newInotifyRootWatcher(feed *LinuxChangeFeed, root RootRecord) (*inotifyRootWatcher, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("inotify init: %w", err)
+	}
+
+	return &inotifyRootWatcher{
+		feed: feed,
+		// A non-blocking descriptor wrapped in os.File is served by the runtime
+		// poller, so Close reliably wakes the read loop.
+		file:      os.NewFile(uintptr(fd), "inotify:"+root.Path),
+		fd:        fd,
+		root:      root,
+		watches:   map[int32]string{},
+		paths:     map[string]int32{},
+		unwatched: map[string]struct{}{},
+	}, nil
+}
+
+func (w *inotifyRootWatcher) start(catchUpSince time.Time) {
+	w.mu.Lock()
+	w.lastReadAt = time.Now()
+	w.mu.Unlock()
+
+	util.Go(context.Background(), "filesearch inotify read loop", w.readLoop)
+	util.Go(context.Background(), "filesearch inotify register", func() {
+		root := w.currentRoot()
+		startedAt := time.Now()
+		w.registerTree(root.Path, catchUpSince)
+		if fileSearchDiagnosticLoggingEnabled {
+			w.mu.Lock()
+			watchCount := len(w.watches)
+			unwatchedCount := len(w.unwatched)
+			w.mu.Unlock()
+			util.GetLogger().Info(context.Background(), fmt.Sprintf(
+				"filesearch inotify watcher registered: root=%s path=%s watches=%d unwatched=%d catch_up=%t elapsed=%s",
+				root.ID,
+				summarizeLogPath(root.Path),
+				watchCount,
+				unwatchedCount,
+				!catchUpSince.IsZero(),
+				time.Since(startedAt),
+			))
+		}
+	})
+}
+
+func (w *inotifyRootWatcher) currentRoot() RootRecord {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.root
+}
+
+func (w *inotifyRootWatcher) setRoot(root RootRecord) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.root = root
+}
+
+func (w *inotifyRootWatcher) isClosed() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.closed
+}
+
+func (w *inotifyRootWatcher) close() {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return
+	}
+	w.closed = true
+	w.watches = map[int32]string{}
+	w.paths = map[string]int32{}
+	w.unwatched = map[string]struct{}{}
+	w.mu.Unlock()
+
+	_ = w.file.Close()
+}
+
+// registerTree adds a watch for every directory under scopePath that this root
+// owns. When since is set, the same walk reports entries whose ctime moved
+// past it so history lost before the watches existed is reconciled.
+func (w *inotifyRootWatcher) registerTree(scopePath string, since time.Time) {
+	root := w.currentRoot()
+	cleanRootPath := filepath.Clean(root.Path)
+	walk := linuxFeedWalk{
+		root:     root,
+		feedType: RootFeedTypeInotify,
+		since:    since,
+		owns: func(dirPath string) bool {
+			return w.feed.ownsDirectory(root.ID, dirPath)
+		},
+		stopped: w.isClosed,
+		emit:    w.feed.emit,
+		visitDir: func(dirPath string) bool {
+			err := w.addWatch(dirPath)
+			if err == nil {
+				return true
+			}
+			switch {
+			case errors.Is(err, unix.ENOSPC):
+				w.noteWatchLimit(root, dirPath)
+			case dirPath == cleanRootPath && !errors.Is(err, unix.EACCES):
+				w.feed.emit(ChangeSignal{
+					Kind:          ChangeSignalKindFeedUnavailable,
+					SemanticKind:  ChangeSemanticKindFeedUnavailable,
+					RootID:        root.ID,
+					FeedType:      RootFeedTypeInotify,
+					Path:          root.Path,
+					PathIsDir:     true,
+					PathTypeKnown: true,
+					Reason:        err.Error(),
+				})
+			}
+			// Vanished and unreadable directories are skipped the same way the
+			// snapshot builder skips them; the parent watch still reports them.
+			return false
+		},
+	}
+	walk.run(scopePath)
+}
+
+func (w *inotifyRootWatcher) addWatch(dirPath string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return os.ErrClosed
+	}
+
+	wd, err := unix.InotifyAddWatch(w.fd, dirPath, inotifyWatchMask)
+	if err != nil {
+		return err
+	}
+	watchID := int32(wd)
+	if previousPath, ok := w.watches[watchID]; ok && previousPath != dirPath {
+		delete(w.paths, previousPath)
+	}
+	w.watches[watchID] = dirPath
+	w.paths[dirPath] = watchID
+	if _, ok := w.unwatched[dirPath]; ok {
+		delete(w.unwatched, dirPath)
+		// Changes made while the subtree had no watch were never reported.
+		// Reconcile exactly that subtree now that it is observable again.
+		w.feed.emit(newLinuxFeedReconcileSignal(w.root, RootFeedTypeInotify, dirPath, "inotify watch recovered after limit", time.Now()))
+	}
+	return nil
+}
+
+func (w *inotifyRootWatcher) noteWatchLimit(root RootRecord, dirPath string) {
+	w.mu.Lock()
+	_, known := w.unwatched[dirPath]
+	w.unwatched[dirPath] = struct{}{}
+	firstNotice := !w.limitNoted
+	w.limitNoted = true
+	w.mu.Unlock()
+
+	if firstNotice {
+		util.GetLogger().Warn(context.Background(), fmt.Sprintf(
+			"filesearch inotify watch limit reached: root=%s path=%s; raise fs.inotify.max_user_watches to watch the full tree",
+			root.ID,
+			summarizeLogPath(dirPath),
+		))
+	}
+	if known {
+		return
+	}
+	// The subtree will not report live changes, so reconcile just that subtree
+	// to at least publish its current contents. The maintenance loop retries
+	// the watch and reconciles again once it succeeds.
+	w.feed.emit(newLinuxFeedReconcileSignal(root, RootFeedTypeInotify, dirPath, "inotify watch limit reached", time.Now()))
+}
+
+func (w *inotifyRootWatcher) retryUnwatched() {
+	w.mu.Lock()
+	paths := make([]string, 0, len(w.unwatched))
+	for path := range w.unwatched {
+		paths = append(paths, path)
+	}
+	w.mu.Unlock()
+
+	sort.Strings(paths)
+	for _, path := range paths {
+		if w.isClosed() {
+			return
+		}
+		if _, err := os.Lstat(path); err != nil {
+			w.mu.Lock()
+			delete(w.unwatched, path)
+			w.mu.Unlock()
+			continue
+		}
+		w.registerTree(path, time.Time{})
+	}
+}
+
+func (w *inotifyRootWatcher) catchUp(since time.Time, reason string) {
+	w.catchUpMu.Lock()
+	defer w.catchUpMu.Unlock()
+
+	root := w.currentRoot()
+	util.GetLogger().Info(context.Background(), fmt.Sprintf(
+		"filesearch inotify catch-up started: root=%s path=%s reason=%s since=%s",
+		root.ID,
+		summarizeLogPath(root.Path),
+		reason,
+		since.Format(time.RFC3339),
+	))
+	w.registerTree(root.Path, since)
+}
+
+// pruneForeignWatches drops watches for directories that a newly added nested
+// root now owns, so the same change is not reported by two instances.
+func (w *inotifyRootWatcher) pruneForeignWatches(matcher rootPathMatcher) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+
+	for dirPath, wd := range w.paths {
+		owner, ok := matcher.findClean(dirPath)
+		if ok && owner.ID == w.root.ID {
+			continue
+		}
+		_, _ = unix.InotifyRmWatch(w.fd, uint32(wd))
+		delete(w.paths, dirPath)
+		delete(w.watches, wd)
+	}
+	for dirPath := range w.unwatched {
+		if owner, ok := matcher.findClean(dirPath); !ok || owner.ID != w.root.ID {
+			delete(w.unwatched, dirPath)
+		}
+	}
+}
+
+// forgetSubtree removes watches below a directory that moved away. The kernel
+// keeps those watch descriptors alive with stale paths, so they must be
+// dropped before the new location is registered.
+func (w *inotifyRootWatcher) forgetSubtree(dirPath string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	prefix := dirPath + string(filepath.Separator)
+	for path, wd := range w.paths {
+		if path != dirPath && !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		_, _ = unix.InotifyRmWatch(w.fd, uint32(wd))
+		delete(w.paths, path)
+		delete(w.watches, wd)
+	}
+	for path := range w.unwatched {
+		if path == dirPath || strings.HasPrefix(path, prefix) {
+			delete(w.unwatched, path)
+		}
+	}
+}
+
+func (w *inotifyRootWatcher) watchPath(wd int32) (string, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	path, ok := w.watches[wd]
+	return path, ok
+}
+
+func (w *inotifyRootWatcher) forgetWatch(wd int32) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if path, ok := w.watches[wd]; ok {
+		delete(w.watches, wd)
+		if w.paths[path] == wd {
+			delete(w.paths, path)
+		}
+	}
+}
+
+func (w *inotifyRootWatcher) readLoop() {
+	buf := make([]byte, inotifyReadBufferSize)
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			if w.isClosed() || errors.Is(err, os.ErrClosed) {
+				return
+			}
+			root := w.currentRoot()
+			w.feed.emit(ChangeSignal{
+				Kind:          ChangeSignalKindFeedUnavailable,
+				SemanticKind:  ChangeSemanticKindFeedUnavailable,
+				RootID:        root.ID,
+				FeedType:      RootFeedTypeInotify,
+				Path:          root.Path,
+				PathIsDir:     true,
+				PathTypeKnown: true,
+				Reason:        "read inotify events: " + err.Error(),
+			})
+			return
+		}
+
+		w.mu.Lock()
+		previousReadAt := w.lastReadAt
+		w.lastReadAt = time.Now()
+		w.mu.Unlock()
+		w.handleBuffer(buf[:n], previousReadAt)
+	}
+}
+
+func (w *inotifyRootWatcher) handleBuffer(buf []byte, previousReadAt time.Time) {
+	now := time.Now()
+	offset := 0
+	for offset+unix.SizeofInotifyEvent <= len(buf) {
+		raw := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+		nameStart := offset + unix.SizeofInotifyEvent
+		nameEnd := nameStart + int(raw.Len)
+		if nameEnd > len(buf) {
+			return
+		}
+		name := strings.TrimRight(string(buf[nameStart:nameEnd]), "\x00")
+		w.handleEvent(raw.Wd, raw.Mask, name, previousReadAt, now)
+		offset = nameEnd
+	}
+}
+
+func (w *inotifyRootWatcher) handleEvent(wd int32, mask uint32, name string, previousReadAt time.Time, at time.Time) {
+	if mask&unix.IN_Q_OVERFLOW != 0 {
+		// The kernel queue overflowed, so events after the previous read were
+		// lost. A ctime walk bounded by that read recovers exactly the changed
+		// subtrees of this root instead of reconciling it from scratch.
+		since := previousReadAt.Add(-linuxFeedCatchUpSlack)
+		util.Go(context.Background(), "filesearch inotify overflow catch-up", func() {
+			w.catchUp(since, "inotify queue overflow")
+		})
+		return
+	}
+
+	dirPath, ok := w.watchPath(wd)
+	if !ok {
+		return
+	}
+	if mask&unix.IN_IGNORED != 0 {
+		w.forgetWatch(wd)
+		return
+	}
+
+	root := w.currentRoot()
+	if name == "" {
+		// Events on a watched directory itself are also reported by its parent
+		// watch. Only the root has no parent watch to report removal or unmount.
+		if filepath.Clean(dirPath) != filepath.Clean(root.Path) || mask&(unix.IN_DELETE_SELF|unix.IN_MOVE_SELF|unix.IN_UNMOUNT) == 0 {
+			return
+		}
+		w.feed.emit(newLinuxFeedReconcileSignal(root, RootFeedTypeInotify, root.Path, "watched root was removed, moved or unmounted", at))
+		return
+	}
+
+	eventPath := filepath.Join(dirPath, name)
+	if mask&unix.IN_ISDIR != 0 {
+		switch {
+		case mask&unix.IN_MOVED_FROM != 0:
+			w.forgetSubtree(eventPath)
+		case mask&(unix.IN_CREATE|unix.IN_MOVED_TO) != 0:
+			// Entries created before the new watches land are covered by the
+			// directory signal below, which the scanner turns into a subtree
+			// reconcile of the new directory.
+			newDirPath := eventPath
+			util.Go(context.Background(), "filesearch inotify register new directory", func() {
+				w.registerTree(newDirPath, time.Time{})
+			})
+		}
+	}
+
+	owner, ok := w.feed.copyRootSnapshot().findClean(eventPath)
+	if !ok {
+		return
+	}
+	if shouldSkipSystemPathForRoot(owner, eventPath, mask&unix.IN_ISDIR != 0) {
+		return
+	}
+	signal, ok := translateLinuxFeedEvent(owner, RootFeedTypeInotify, eventPath, mask, at)
+	if !ok {
+		return
+	}
+	w.feed.emit(signal)
+}
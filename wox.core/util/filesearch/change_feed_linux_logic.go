@@ -0,0 +1,210 @@
+package filesearch
+
+import (
+	"path/filepath"
+	"time"
+)
+
+// inotify and fanotify share the same low event bits, so both Linux backends
+// feed the translation below. Mirroring the values here keeps the logic
+// testable on every platform, the same way the USN reason bits are.
+const (
+	linuxFeedEventModify     uint32 = 0x00000002
+	linuxFeedEventAttrib     uint32 = 0x00000004
+	linuxFeedEventCloseWrite uint32 = 0x00000008
+	linuxFeedEventMovedFrom  uint32 = 0x00000040
+	linuxFeedEventMovedTo    uint32 = 0x00000080
+	linuxFeedEventCreate     uint32 = 0x00000100
+	linuxFeedEventDelete     uint32 = 0x00000200
+	linuxFeedEventDeleteSelf uint32 = 0x00000400
+	linuxFeedEventMoveSelf   uint32 = 0x00000800
+	linuxFeedEventUnmount    uint32 = 0x00002000
+	linuxFeedEventOverflow   uint32 = 0x00004000
+	linuxFeedEventIgnored    uint32 = 0x00008000
+	linuxFeedEventIsDir      uint32 = 0x40000000
+)
+
+// linuxFeedCatchUpSlack widens ctime comparisons so coarse filesystem
+// timestamps and the gap between the last drained event and the cursor write
+// cannot hide a change that landed right at the boundary.
+const linuxFeedCatchUpSlack = 2 * time.Second
+
+type linuxFeedRootPlan struct {
+	root RootRecord
+	// catchUpSince is the lower bound for the registration walk's ctime check.
+	// A zero value means the root has no trustworthy cursor and the scanner's
+	// own startup/full reconcile is responsible for the offline gap.
+	catchUpSince time.Time
+}
+
+type preparedLinuxFeedRefresh struct {
+	roots   []linuxFeedRootPlan
+	signals []ChangeSignal
+}
+
+func prepareLinuxFeedRefresh(roots []RootRecord, feedType RootFeedType, now time.Time, safeWindow time.Duration) preparedLinuxFeedRefresh {
+	prepared := preparedLinuxFeedRefresh{
+		roots: make([]linuxFeedRootPlan, 0, len(roots)),
+	}
+
+	for _, root := range roots {
+		plan := linuxFeedRootPlan{root: root}
+		if root.FeedState == RootFeedStateUnavailable {
+			prepared.signals = append(prepared.signals, newLinuxFeedReconcileSignal(root, feedType, root.Path, "linux change feed recovered", now))
+			prepared.roots = append(prepared.roots, plan)
+			continue
+		}
+
+		cursor, ok := decodeLinuxFeedCursor(root.FeedCursor)
+		if !ok {
+			if root.FeedCursor != "" && isLinuxFeedType(root.FeedType) {
+				prepared.signals = append(prepared.signals, newLinuxFeedReconcileSignal(root, feedType, root.Path, "invalid linux feed cursor", now))
+			}
+			prepared.roots = append(prepared.roots, plan)
+			continue
+		}
+		if !feedCursorFresh(cursor, now, safeWindow) {
+			prepared.signals = append(prepared.signals, newLinuxFeedReconcileSignal(root, feedType, root.Path, "expired linux feed cursor", now))
+			prepared.roots = append(prepared.roots, plan)
+			continue
+		}
+
+		// inotify and fanotify cannot replay history, but a fresh cursor bounds
+		// the offline gap. The registration walk compares ctime against it so a
+		// restart only reconciles what actually changed while Wox was not running.
+		plan.catchUpSince = time.UnixMilli(cursor.UpdatedAt).Add(-linuxFeedCatchUpSlack)
+		prepared.roots = append(prepared.roots, plan)
+	}
+
+	return prepared
+}
+
+func decodeLinuxFeedCursor(value string) (FeedCursor, bool) {
+	// A root can move between fanotify and inotify when capabilities change.
+	// Both cursors are wall-clock acknowledgements, so either one still bounds
+	// the catch-up walk for the other backend.
+	if cursor, ok := decodeFeedCursor(value, RootFeedTypeFanotify); ok {
+		return cursor, true
+	}
+	return decodeFeedCursor(value, RootFeedTypeInotify)
+}
+
+func isLinuxFeedType(feedType RootFeedType) bool {
+	return feedType == RootFeedTypeInotify || feedType == RootFeedTypeFanotify
+}
+
+func encodeLinuxFeedCursor(feedType RootFeedType, at time.Time) string {
+	cursor, err := encodeFeedCursor(FeedCursor{
+		FeedType:  feedType,
+		UpdatedAt: at.UnixMilli(),
+	})
+	if err != nil {
+		return ""
+	}
+	return cursor
+}
+
+func translateLinuxFeedEvent(root RootRecord, feedType RootFeedType, eventPath string, mask uint32, at time.Time) (ChangeSignal, bool) {
+	eventPath = filepath.Clean(eventPath)
+	cleanRootPath := filepath.Clean(root.Path)
+	if eventPath == "" || eventPath == "." {
+		eventPath = cleanRootPath
+	}
+
+	if eventPath == cleanRootPath && mask&(linuxFeedEventDeleteSelf|linuxFeedEventMoveSelf|linuxFeedEventUnmount) != 0 {
+		return newLinuxFeedReconcileSignal(root, feedType, cleanRootPath, "watched root was removed, moved or unmounted", at), true
+	}
+
+	semanticKind, ok := translateLinuxFeedSemanticKind(mask)
+	if !ok {
+		return ChangeSignal{}, false
+	}
+
+	kind := ChangeSignalKindDirtyPath
+	pathIsDir := mask&linuxFeedEventIsDir != 0
+	if eventPath == cleanRootPath {
+		kind = ChangeSignalKindDirtyRoot
+		pathIsDir = true
+	}
+
+	return ChangeSignal{
+		Kind:          kind,
+		SemanticKind:  semanticKind,
+		RootID:        root.ID,
+		FeedType:      feedType,
+		Path:          eventPath,
+		PathIsDir:     pathIsDir,
+		PathTypeKnown: true,
+		Cursor:        encodeLinuxFeedCursor(feedType, at),
+		At:            at,
+	}, true
+}
+
+func translateLinuxFeedSemanticKind(mask uint32) (ChangeSemanticKind, bool) {
+	switch {
+	case mask&(linuxFeedEventMovedFrom|linuxFeedEventMovedTo) != 0:
+		return ChangeSemanticKindRename, true
+	case mask&linuxFeedEventDelete != 0:
+		return ChangeSemanticKindRemove, true
+	case mask&linuxFeedEventCreate != 0:
+		return ChangeSemanticKindCreate, true
+	case mask&(linuxFeedEventModify|linuxFeedEventCloseWrite) != 0:
+		return ChangeSemanticKindModify, true
+	case mask&linuxFeedEventAttrib != 0:
+		return ChangeSemanticKindMetadata, true
+	default:
+		// Self events on non-root directories are also reported by the parent
+		// watch, and IN_IGNORED only retires a watch descriptor.
+		return "", false
+	}
+}
+
+// newLinuxFeedCatchUpSignal describes one entry whose ctime moved past the
+// catch-up bound. Directories lose history for their whole subtree because a
+// create, delete or rename inside them is only visible through the parent, so
+// they request a reconcile scoped to that directory; files stay exact deltas.
+func newLinuxFeedCatchUpSignal(root RootRecord, feedType RootFeedType, path string, isDir bool, at time.Time) ChangeSignal {
+	if isDir {
+		return newLinuxFeedReconcileSignal(root, feedType, path, "linux change feed catch-up found a changed directory", at)
+	}
+	return ChangeSignal{
+		Kind:          ChangeSignalKindDirtyPath,
+		SemanticKind:  ChangeSemanticKindModify,
+		RootID:        root.ID,
+		FeedType:      feedType,
+		Path:          filepath.Clean(path),
+		PathIsDir:     false,
+		PathTypeKnown: true,
+		At:            at,
+	}
+}
+
+// newLinuxFeedReconcileSignal requests a reconcile for path, which may be the
+// root itself or one of its subtrees. Recursive Linux feeds know which subtree
+// lost history, so they never widen a watch-limit or overflow gap to the root.
+func newLinuxFeedReconcileSignal(root RootRecord, feedType RootFeedType, path string, reason string, at time.Time) ChangeSignal {
+	if path == "" {
+		path = root.Path
+	}
+	return ChangeSignal{
+		Kind:          ChangeSignalKindRequiresRootReconcile,
+		SemanticKind:  ChangeSemanticKindRequiresRootReconcile,
+		RootID:        root.ID,
+		FeedType:      feedType,
+		Path:          filepath.Clean(path),
+		PathIsDir:     true,
+		PathTypeKnown: true,
+		Reason:        reason,
+		At:            at,
+	}
+}
+
+// linuxFeedCatchUpNeeded reports whether an entry changed after since. Linux
+// updates ctime for content writes, metadata changes and renames of the entry,
+// and a directory's ctime for creates, deletes and renames of its children.
+func linuxFeedCatchUpNeeded(ctime time.Time, since time.Time) bool {
+	if since.IsZero() {
+		return false
+	}
+	return ctime.After(since)
+}
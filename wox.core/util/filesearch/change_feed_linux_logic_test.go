@@ -0,0 +1,124 @@
+package filesearch
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPrepareLinuxFeedRefreshBoundsCatchUpByFreshCursor(t *testing.T) {
+	now := time.Now()
+	freshAt := now.Add(-time.Hour)
+	freshCursor := mustEncodeFeedCursorForTest(t, FeedCursor{
+		FeedType:  RootFeedTypeInotify,
+		UpdatedAt: freshAt.UnixMilli(),
+	})
+	fanotifyCursor := mustEncodeFeedCursorForTest(t, FeedCursor{
+		FeedType:  RootFeedTypeFanotify,
+		UpdatedAt: freshAt.UnixMilli(),
+	})
+	expiredCursor := mustEncodeFeedCursorForTest(t, FeedCursor{
+		FeedType:  RootFeedTypeInotify,
+		UpdatedAt: now.Add(-26 * time.Hour).UnixMilli(),
+	})
+
+	prepared := prepareLinuxFeedRefresh([]RootRecord{
+		{ID: "root-fresh", Path: "/data/fresh", FeedType: RootFeedTypeInotify, FeedCursor: freshCursor},
+		{ID: "root-switched", Path: "/data/switched", FeedType: RootFeedTypeFanotify, FeedCursor: fanotifyCursor},
+		{ID: "root-expired", Path: "/data/expired", FeedType: RootFeedTypeInotify, FeedCursor: expiredCursor},
+		{ID: "root-invalid", Path: "/data/invalid", FeedType: RootFeedTypeInotify, FeedCursor: "{"},
+		{ID: "root-upgraded", Path: "/data/upgraded", FeedType: RootFeedTypeFallback},
+		{ID: "root-recovered", Path: "/data/recovered", FeedType: RootFeedTypeInotify, FeedState: RootFeedStateUnavailable},
+	}, RootFeedTypeInotify, now, defaultFeedCursorSafeWindow)
+
+	if len(prepared.roots) != 6 {
+		t.Fatalf("expected every root to stay watched, got %d", len(prepared.roots))
+	}
+	catchUpByRoot := map[string]time.Time{}
+	for _, plan := range prepared.roots {
+		catchUpByRoot[plan.root.ID] = plan.catchUpSince
+	}
+	wantSince := time.UnixMilli(freshAt.UnixMilli()).Add(-linuxFeedCatchUpSlack)
+	if !catchUpByRoot["root-fresh"].Equal(wantSince) {
+		t.Fatalf("expected fresh cursor to bound catch-up at %s, got %s", wantSince, catchUpByRoot["root-fresh"])
+	}
+	if !catchUpByRoot["root-switched"].Equal(wantSince) {
+		t.Fatalf("expected fanotify cursor to bound inotify catch-up, got %s", catchUpByRoot["root-switched"])
+	}
+	for _, rootID := range []string{"root-expired", "root-invalid", "root-upgraded", "root-recovered"} {
+		if !catchUpByRoot[rootID].IsZero() {
+			t.Fatalf("expected %s to skip ctime catch-up, got %s", rootID, catchUpByRoot[rootID])
+		}
+	}
+
+	signalRoots := map[string]ChangeSignal{}
+	for _, signal := range prepared.signals {
+		signalRoots[signal.RootID] = signal
+	}
+	if len(signalRoots) != 3 {
+		t.Fatalf("expected expired, invalid and recovered roots to reconcile, got %#v", prepared.signals)
+	}
+	for _, rootID := range []string{"root-expired", "root-invalid", "root-recovered"} {
+		signal, ok := signalRoots[rootID]
+		if !ok || signal.Kind != ChangeSignalKindRequiresRootReconcile || signal.FeedType != RootFeedTypeInotify {
+			t.Fatalf("expected %s to require root reconcile, got %#v ok=%t", rootID, signal, ok)
+		}
+	}
+}
+
+func TestTranslateLinuxFeedEventKeepsFileDeltaAndCursor(t *testing.T) {
+	root := RootRecord{ID: "root-linux", Path: "/data/root"}
+	at := time.Now()
+
+	signal, ok := translateLinuxFeedEvent(root, RootFeedTypeInotify, "/data/root/nested/deep/report.txt", linuxFeedEventCloseWrite, at)
+	if !ok {
+		t.Fatal("expected close-write event to translate")
+	}
+	if signal.Kind != ChangeSignalKindDirtyPath || signal.SemanticKind != ChangeSemanticKindModify {
+		t.Fatalf("expected dirty modify path, got %#v", signal)
+	}
+	if signal.PathIsDir || !signal.PathTypeKnown {
+		t.Fatalf("expected known file path, got %#v", signal)
+	}
+	cursor, ok := decodeFeedCursor(signal.Cursor, RootFeedTypeInotify)
+	if !ok || cursor.UpdatedAt != at.UnixMilli() {
+		t.Fatalf("expected inotify cursor at event time, got %q", signal.Cursor)
+	}
+
+	signal, ok = translateLinuxFeedEvent(root, RootFeedTypeInotify, "/data/root/nested/moved", linuxFeedEventMovedFrom|linuxFeedEventIsDir, at)
+	if !ok || signal.SemanticKind != ChangeSemanticKindRename || !signal.PathIsDir {
+		t.Fatalf("expected moved directory rename signal, got %#v ok=%t", signal, ok)
+	}
+
+	if _, ok := translateLinuxFeedEvent(root, RootFeedTypeInotify, "/data/root/nested", linuxFeedEventIgnored, at); ok {
+		t.Fatal("expected ignored watch event to be dropped")
+	}
+}
+
+func TestTranslateLinuxFeedEventReconcilesRemovedRoot(t *testing.T) {
+	root := RootRecord{ID: "root-linux", Path: "/data/root"}
+
+	signal, ok := translateLinuxFeedEvent(root, RootFeedTypeFanotify, "/data/root", linuxFeedEventDeleteSelf|linuxFeedEventIsDir, time.Now())
+	if !ok {
+		t.Fatal("expected root delete-self event to translate")
+	}
+	if signal.Kind != ChangeSignalKindRequiresRootReconcile || signal.Path != root.Path {
+		t.Fatalf("expected root reconcile for removed root, got %#v", signal)
+	}
+}
+
+func TestNewLinuxFeedCatchUpSignalScopesDirectoriesToSubtree(t *testing.T) {
+	root := RootRecord{ID: "root-linux", Path: "/data/root"}
+	dirPath := filepath.Join(root.Path, "projects", "wox")
+
+	signal := newLinuxFeedCatchUpSignal(root, RootFeedTypeInotify, dirPath, true, time.Now())
+	if signal.Kind != ChangeSignalKindRequiresRootReconcile || signal.Path != dirPath {
+		t.Fatalf("expected changed directory to request a subtree reconcile, got %#v", signal)
+	}
+
+	filePath := filepath.Join(dirPath, "main.go")
+	signal = newLinuxFeedCatchUpSignal(root, RootFeedTypeInotify, filePath, false, time.Now())
+	if signal.Kind != ChangeSignalKindDirtyPath || signal.PathIsDir || signal.Path != filePath {
+		t.Fatalf("expected changed file to stay an exact dirty path, got %#v", signal)
+	}
+}
@@ -0,0 +1,167 @@
+//go:build linux
+
+package filesearch
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewPlatformChangeFeedUsesLinuxFeedOnLinux(t *testing.T) {
+	feed := newPlatformChangeFeed()
+	defer feed.Close()
+
+	if _, ok := feed.(*LinuxChangeFeed); !ok {
+		t.Fatalf("expected linux change feed, got %T", feed)
+	}
+}
+
+func TestLinuxChangeFeedReportsChangesDeepInsideRoot(t *testing.T) {
+	rootPath := filepath.Join(t.TempDir(), "root-recursive")
+	deepPath := filepath.Join(rootPath, "a", "b", "c")
+	mustMkdirAll(t, deepPath)
+
+	feed := NewLinuxChangeFeed()
+	defer feed.Close()
+
+	root := RootRecord{ID: "root-recursive", Path: rootPath, FeedState: RootFeedStateReady}
+	if err := feed.Refresh(context.Background(), []RootRecord{root}); err != nil {
+		t.Fatalf("refresh linux change feed: %v", err)
+	}
+	waitForLinuxFeedWatch(t, feed, root, deepPath)
+
+	filePath := filepath.Join(deepPath, "deep.txt")
+	mustWriteTestFile(t, filePath, "deep")
+
+	signal := mustReadLinuxFeedSignalForPath(t, feed.Signals(), filePath)
+	if signal.Kind != ChangeSignalKindDirtyPath || signal.RootID != root.ID {
+		t.Fatalf("expected deep dirty path for root, got %#v", signal)
+	}
+	if signal.PathIsDir || !signal.PathTypeKnown {
+		t.Fatalf("expected known file signal, got %#v", signal)
+	}
+}
+
+func TestLinuxChangeFeedRegistersNewDirectories(t *testing.T) {
+	rootPath := filepath.Join(t.TempDir(), "root-new-dir")
+	mustMkdirAll(t, rootPath)
+
+	feed := NewLinuxChangeFeed()
+	defer feed.Close()
+
+	root := RootRecord{ID: "root-new-dir", Path: rootPath, FeedState: RootFeedStateReady}
+	if err := feed.Refresh(context.Background(), []RootRecord{root}); err != nil {
+		t.Fatalf("refresh linux change feed: %v", err)
+	}
+	waitForLinuxFeedWatch(t, feed, root, rootPath)
+
+	newDirPath := filepath.Join(rootPath, "created")
+	mustMkdirAll(t, newDirPath)
+	signal := mustReadLinuxFeedSignalForPath(t, feed.Signals(), newDirPath)
+	if !signal.PathIsDir || signal.SemanticKind != ChangeSemanticKindCreate {
+		t.Fatalf("expected created directory signal, got %#v", signal)
+	}
+	waitForLinuxFeedWatch(t, feed, root, newDirPath)
+
+	filePath := filepath.Join(newDirPath, "later.txt")
+	mustWriteTestFile(t, filePath, "later")
+	mustReadLinuxFeedSignalForPath(t, feed.Signals(), filePath)
+}
+
+func TestLinuxChangeFeedCatchUpReconcilesOnlyChangedSubtree(t *testing.T) {
+	rootPath := filepath.Join(t.TempDir(), "root-catch-up")
+	stablePath := filepath.Join(rootPath, "stable")
+	changedPath := filepath.Join(rootPath, "changed")
+	mustWriteTestFile(t, filepath.Join(stablePath, "old.txt"), "old")
+	mustMkdirAll(t, changedPath)
+
+	// ctime cannot be set directly, so place the cursor after the setup writes
+	// (including the catch-up slack) and only change one directory after it.
+	time.Sleep(20 * time.Millisecond)
+	cursorAt := time.Now().Add(linuxFeedCatchUpSlack)
+	time.Sleep(linuxFeedCatchUpSlack + 100*time.Millisecond)
+	mustWriteTestFile(t, filepath.Join(changedPath, "offline.txt"), "offline")
+
+	cursor := mustEncodeFeedCursorForTest(t, FeedCursor{FeedType: RootFeedTypeInotify, UpdatedAt: cursorAt.UnixMilli()})
+	feed := NewLinuxChangeFeed()
+	defer feed.Close()
+
+	root := RootRecord{ID: "root-catch-up", Path: rootPath, FeedType: RootFeedTypeInotify, FeedCursor: cursor, FeedState: RootFeedStateReady}
+	if err := feed.Refresh(context.Background(), []RootRecord{root}); err != nil {
+		t.Fatalf("refresh linux change feed: %v", err)
+	}
+
+	signal := mustReadLinuxFeedSignalForPath(t, feed.Signals(), changedPath)
+	if signal.Kind != ChangeSignalKindRequiresRootReconcile || signal.Path != changedPath {
+		t.Fatalf("expected catch-up to reconcile only the changed subtree, got %#v", signal)
+	}
+	select {
+	case extra := <-feed.Signals():
+		t.Fatalf("expected no catch-up signal outside the changed subtree, got %#v", extra)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestLinuxChangeFeedSnapshotUsesLinuxCursor(t *testing.T) {
+	rootPath := filepath.Join(t.TempDir(), "root-snapshot")
+	mustMkdirAll(t, rootPath)
+
+	feed := NewLinuxChangeFeed()
+	defer feed.Close()
+
+	snapshot, err := feed.SnapshotRootFeed(context.Background(), RootRecord{ID: "root-snapshot", Path: rootPath})
+	if err != nil {
+		t.Fatalf("snapshot linux feed: %v", err)
+	}
+	if !isLinuxFeedType(snapshot.FeedType) {
+		t.Fatalf("expected inotify or fanotify snapshot, got %q", snapshot.FeedType)
+	}
+	cursor, ok := decodeLinuxFeedCursor(snapshot.FeedCursor)
+	if !ok || !feedCursorFresh(cursor, time.Now(), defaultFeedCursorSafeWindow) {
+		t.Fatalf("expected fresh linux cursor, got %q", snapshot.FeedCursor)
+	}
+}
+
+func waitForLinuxFeedWatch(t *testing.T, feed *LinuxChangeFeed, root RootRecord, dirPath string) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		feed.mu.RLock()
+		watcher := feed.inotify[root.ID]
+		_, onFanotify := feed.fanotifyRootIDs[root.ID]
+		feed.mu.RUnlock()
+		if onFanotify {
+			return
+		}
+		if watcher != nil {
+			watcher.mu.Lock()
+			_, watched := watcher.paths[dirPath]
+			watcher.mu.Unlock()
+			if watched {
+				return
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for watch on %s", dirPath)
+}
+
+func mustReadLinuxFeedSignalForPath(t *testing.T, signals <-chan ChangeSignal, path string) ChangeSignal {
+	t.Helper()
+
+	deadline := time.After(3 * time.Second)
+	for {
+		select {
+		case signal := <-signals:
+			if signal.Path == path {
+				return signal
+			}
+		case <-deadline:
+			t.Fatalf("timed out waiting for change signal on %s", path)
+			return ChangeSignal{}
+		}
+	}
+}
@@ -278,7 +278,7 @@ func (c *ContentCrawler) extractContentCandidateBatch(ctx context.Context, candi
 				}
 				candidate := candidates[index]
 				readBytes := contentExtractionMaxBytes(candidate.Path, candidate.Size, c.maxReadBytes)
-				results[index].text, results[index].err = extractContentText(candidate.Path, readBytes)
+				results[index].text, results[index].err = extractContentText(ctx, candidate.Path, readBytes)
 			}
 		}()
 	}
@@ -398,7 +398,7 @@ func (c *ContentCrawler) crawlRoot(ctx context.Context, root RootRecord, rootIdx
 		}
 
 		readBytes := contentExtractionMaxBytes(path, info.Size(), c.maxReadBytes)
-		text, err := extractContentText(path, readBytes)
+		text, err := extractContentText(ctx, path, readBytes)
 		if err != nil {
 			counters.failed++
 			return nil
@@ -2,61 +2,112 @@ package filesearch
 
 import (
 	"archive/zip"
+	"context"
 	"encoding/xml"
 	"fmt"
 	"io"
 	"os"
 	"sort"
 	"strings"
+	"time"
 	"unicode"
-
-	pdf "github.com/ledongthuc/pdf"
 )
 
 const openXMLMaxEntryReadBytes int64 = 16 * 1024 * 1024
 
-// extractContentText returns plain text suitable for content indexing. Text
-// files are read directly, while supported document containers are parsed
-// through format-specific extractors so binary bytes are never indexed as text.
-func extractContentText(path string, maxBytes int64) (string, error) {
-	switch contentNormalizeExtension(path) {
-	case "docx":
-		return extractOpenXMLText(path, maxBytes, openXMLWordTextFile)
-	case "pptx":
-		return extractOpenXMLText(path, maxBytes, openXMLPowerPointTextFile)
-	case "xlsx":
-		return extractOpenXMLText(path, maxBytes, openXMLSpreadsheetTextFile)
-	case "pdf":
-		return extractPDFText(path, maxBytes)
-	default:
+// plainTextContentExtractor handles every whitelisted extension without a
+// dedicated parser, which keeps source files and logs on the direct read path.
+var plainTextContentExtractor = &funcContentExtractor{
+	name:    "plain-text",
+	rawText: true,
+	extract: func(ctx context.Context, path string, maxBytes int64) (string, error) {
 		return readPlainContentFile(path, maxBytes)
-	}
-}
-
-// contentExtractionMaxBytes keeps text files bounded by their file size, while
-// compressed document containers use the configured extracted-text cap because
-// their XML text can legitimately be larger than the archive file itself.
-func contentExtractionMaxBytes(path string, fileSize int64, maxBytes int64) int64 {
-	if maxBytes <= 0 {
-		return 0
-	}
-	if isStructuredContentExtension(contentNormalizeExtension(path)) {
-		return maxBytes
-	}
-	if fileSize < maxBytes {
-		return fileSize
-	}
-	return maxBytes
+	},
 }
 
-// isStructuredContentExtension reports formats that need a parser instead of a
-// direct byte-to-string read.
-func isStructuredContentExtension(extension string) bool {
-	switch extension {
-	case "docx", "pptx", "xlsx", "pdf":
-		return true
-	default:
-		return false
+// builtinContentExtractors lists the parsers shipped with Wox. Supported
+// document containers are parsed through format-specific extractors so binary
+// bytes are never indexed as text.
+func builtinContentExtractors() []ContentExtractor {
+	return []ContentExtractor{
+		&funcContentExtractor{
+			name:       "docx",
+			extensions: []string{"docx"},
+			extract: func(ctx context.Context, path string, maxBytes int64) (string, error) {
+				return extractOpenXMLText(ctx, path, maxBytes, openXMLWordTextFile)
+			},
+		},
+		&funcContentExtractor{
+			name:       "pptx",
+			extensions: []string{"pptx"},
+			extract: func(ctx context.Context, path string, maxBytes int64) (string, error) {
+				return extractOpenXMLText(ctx, path, maxBytes, openXMLPowerPointTextFile)
+			},
+			segments: extractPresentationSegments,
+		},
+		&funcContentExtractor{
+			name:       "xlsx",
+			extensions: []string{"xlsx"},
+			extract: func(ctx context.Context, path string, maxBytes int64) (string, error) {
+				return extractOpenXMLText(ctx, path, maxBytes, openXMLSpreadsheetTextFile)
+			},
+			segments: extractSpreadsheetSegments,
+		},
+		&funcContentExtractor{
+			name:       "pdf",
+			extensions: []string{"pdf"},
+			// Text-layer extraction walks every content stream, so large
+			// scanned PDFs get more time than the default.
+			limits:   ContentExtractorLimits{Timeout: 30 * time.Second},
+			extract:  extractPDFText,
+			segments: extractPDFSegments,
+		},
+		&funcContentExtractor{
+			name:       "opendocument",
+			extensions: []string{"odt", "ods", "odp"},
+			extract:    extractOpenDocumentText,
+		},
+		&funcContentExtractor{
+			name:       "epub",
+			extensions: []string{"epub"},
+			extract:    extractEPUBText,
+		},
+		&funcContentExtractor{
+			name:       "rtf",
+			extensions: []string{"rtf"},
+			// RTF embeds images as hex, so the raw file can be far larger than
+			// its text; the parser streams and stops at the text cap.
+			extract: extractRTFText,
+		},
+		&funcContentExtractor{
+			name:       "html",
+			extensions: []string{"html", "htm", "xhtml"},
+			extract:    extractHTMLText,
+		},
+		&funcContentExtractor{
+			name:       "email",
+			extensions: []string{"eml"},
+			extract:    extractEmailText,
+		},
+		&funcContentExtractor{
+			name:       "mbox",
+			extensions: []string{"mbox"},
+			extract:    extractMboxText,
+		},
+		&funcContentExtractor{
+			name:       "jupyter",
+			extensions: []string{"ipynb"},
+			// Notebooks are decoded as one JSON document, and embedded
+			// output images make huge notebooks common.
+			limits:  ContentExtractorLimits{MaxFileBytes: 64 * 1024 * 1024},
+			extract: extractJupyterNotebookText,
+		},
+		&funcContentExtractor{
+			name:       "source-archive",
+			extensions: contentArchiveExtensions,
+			limits:     ContentExtractorLimits{MaxFileBytes: contentArchiveMaxFileBytes, Timeout: 30 * time.Second},
+			extract:    extractSourceArchiveText,
+		},
 	}
 }
 
@@ -83,28 +134,16 @@ func readPlainContentFile(path string, maxBytes int64) (string, error) {
 
 // extractPDFText indexes the embedded text layer only. Scanned image-only PDFs
 // need OCR and intentionally stay outside the lightweight content index path.
-func extractPDFText(path string, maxBytes int64) (string, error) {
-	f, reader, err := pdf.Open(path)
+func extractPDFText(ctx context.Context, path string, maxBytes int64) (string, error) {
+	segments, err := extractPDFSegments(ctx, path, maxBytes)
 	if err != nil {
 		return "", err
 	}
-	defer f.Close()
-
-	builder := newContentTextBuilder(maxBytes)
-	fonts := map[string]*pdf.Font{}
-	for pageNum := 1; pageNum <= reader.NumPage() && !builder.Done(); pageNum++ {
-		page := reader.Page(pageNum)
-		if page.V.IsNull() {
-			continue
-		}
-		text, err := page.GetPlainText(fonts)
-		if err != nil {
-			return "", fmt.Errorf("extract pdf page %d: %w", pageNum, err)
-		}
-		builder.AppendText(text)
-		builder.AppendSeparator()
+	pages := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		pages = append(pages, segment.text)
 	}
-	return builder.String(), nil
+	return strings.Join(pages, " "), nil
 }
 
 type openXMLTextFileMatcher func(name string) bool
@@ -133,7 +172,7 @@ func openXMLSpreadsheetTextFile(name string) bool {
 
 // extractOpenXMLText streams selected XML entries from an Office OpenXML zip
 // package and collects text nodes without loading the whole document in memory.
-func extractOpenXMLText(path string, maxBytes int64, match openXMLTextFileMatcher) (string, error) {
+func extractOpenXMLText(ctx context.Context, path string, maxBytes int64, match openXMLTextFileMatcher) (string, error) {
 	reader, err := zip.OpenReader(path)
 	if err != nil {
 		return "", err
@@ -154,6 +193,9 @@ func extractOpenXMLText(path string, maxBytes int64, match openXMLTextFileMatche
 		if builder.Done() {
 			break
 		}
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
 		if err := appendOpenXMLFileText(file, builder); err != nil {
 			return "", err
 		}
@@ -235,6 +277,16 @@ func (b *contentTextBuilder) Done() bool {
 	return b.builder.Len() >= b.maxBytes
 }
 
+// Len reports the bytes written so far.
+func (b *contentTextBuilder) Len() int {
+	return b.builder.Len()
+}
+
+// Remaining reports how many more bytes fit under the cap.
+func (b *contentTextBuilder) Remaining() int {
+	return max(b.maxBytes-b.builder.Len(), 0)
+}
+
 func (b *contentTextBuilder) String() string {
 	return strings.TrimSpace(b.builder.String())
 }
@@ -0,0 +1,225 @@
+package filesearch
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	// contentArchiveMaxMembers bounds how many entries one archive walk visits,
+	// so an archive of many tiny binaries cannot keep a crawl worker busy.
+	contentArchiveMaxMembers = 20000
+	// contentArchiveMaxMemberBytes skips members too large to be hand-written
+	// source, such as generated bundles or data dumps.
+	contentArchiveMaxMemberBytes = 1024 * 1024
+	// contentArchiveSniffBytes is how much of a member is checked for binary data.
+	contentArchiveSniffBytes = 8 * 1024
+	// contentArchiveMaxFileBytes skips archives too large to unpack on a crawl.
+	contentArchiveMaxFileBytes = 64 * 1024 * 1024
+	// contentArchiveMaxUnpackedBytes bounds how much a tar or gzip stream is
+	// decompressed, including skipped members, so a compression bomb ends early.
+	contentArchiveMaxUnpackedBytes = 256 * 1024 * 1024
+)
+
+var (
+	contentArchiveTextExtensionsOnce sync.Once
+	contentArchiveTextExtensions     map[string]bool
+)
+
+// extractSourceArchiveText indexes text members of zip, tar and gzip
+// archives. Each member contributes its path and content so a hit can be
+// traced back to the file inside the archive. A plain .gz holds one file and
+// is indexed as that file's text. Archives are opt-in, see
+// ContentOptInExtensions, and every read checks ctx so a timed-out extraction
+// stops instead of decompressing in the background.
+func extractSourceArchiveText(ctx context.Context, path string, maxBytes int64) (string, error) {
+	lowerPath := strings.ToLower(path)
+	builder := newContentTextBuilder(maxBytes)
+	switch {
+	case strings.HasSuffix(lowerPath, ".zip"):
+		if err := appendZipArchiveText(ctx, path, builder); err != nil {
+			return "", err
+		}
+	case strings.HasSuffix(lowerPath, ".tar"), strings.HasSuffix(lowerPath, ".tgz"), strings.HasSuffix(lowerPath, ".tar.gz"):
+		if err := appendTarArchiveText(ctx, path, !strings.HasSuffix(lowerPath, ".tar"), builder); err != nil {
+			return "", err
+		}
+	case strings.HasSuffix(lowerPath, ".gz"):
+		if err := appendGzipFileText(ctx, path, builder); err != nil {
+			return "", err
+		}
+	default:
+		return "", errContentExtractionUnsupported
+	}
+	return builder.String(), nil
+}
+
+var errContentExtractionUnsupported = errors.New("unsupported content format")
+
+func appendZipArchiveText(ctx context.Context, path string, builder *contentTextBuilder) error {
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	for index, file := range reader.File {
+		if builder.Done() || index >= contentArchiveMaxMembers {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if file.FileInfo().IsDir() || file.UncompressedSize64 > contentArchiveMaxMemberBytes || !isContentArchiveTextMember(file.Name) {
+			continue
+		}
+		readCloser, err := file.Open()
+		if err != nil {
+			continue
+		}
+		appendContentArchiveMember(file.Name, &contentContextReader{ctx: ctx, reader: readCloser}, builder)
+		readCloser.Close()
+	}
+	return nil
+}
+
+func appendTarArchiveText(ctx context.Context, path string, gzipped bool, builder *contentTextBuilder) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var input io.Reader = f
+	if gzipped {
+		gzipReader, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close()
+		input = gzipReader
+	}
+
+	// tar skips members by reading through them, so the limit and ctx checks
+	// wrap the whole stream rather than each member.
+	reader := tar.NewReader(&contentContextReader{ctx: ctx, reader: io.LimitReader(input, contentArchiveMaxUnpackedBytes)})
+	for index := 0; !builder.Done() && index < contentArchiveMaxMembers; index++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			// A truncated archive keeps the members read so far.
+			return nil
+		}
+		if header.Typeflag != tar.TypeReg || header.Size > contentArchiveMaxMemberBytes || !isContentArchiveTextMember(header.Name) {
+			continue
+		}
+		appendContentArchiveMember(header.Name, reader, builder)
+	}
+	return nil
+}
+
+func appendGzipFileText(ctx context.Context, path string, builder *contentTextBuilder) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gzipReader, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gzipReader.Close()
+
+	data, err := io.ReadAll(io.LimitReader(&contentContextReader{ctx: ctx, reader: gzipReader}, int64(builder.Remaining())))
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if err != nil && len(data) == 0 {
+		return err
+	}
+	if isBinaryContentPrefix(data[:min(len(data), contentArchiveSniffBytes)]) {
+		return nil
+	}
+	builder.AppendText(string(data))
+	return nil
+}
+
+// appendContentArchiveMember appends one member's path and text, skipping
+// members whose leading bytes look binary despite a text extension.
+func appendContentArchiveMember(name string, reader io.Reader, builder *contentTextBuilder) {
+	data, err := io.ReadAll(io.LimitReader(reader, int64(builder.Remaining())+utf8MaxRuneBytes))
+	if err != nil || isBinaryContentPrefix(data[:min(len(data), contentArchiveSniffBytes)]) {
+		return
+	}
+	builder.AppendText(name)
+	builder.AppendSeparator()
+	builder.AppendText(string(data))
+	builder.AppendSeparator()
+}
+
+// contentContextReader fails reads once ctx is done, so a long decompression
+// notices a timeout between chunks instead of only between members.
+type contentContextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (r *contentContextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(p)
+}
+
+// isContentArchiveTextMember accepts members with a default content extension
+// that is read as plain text. Nested archives and documents are skipped because
+// the whole archive shares one text budget.
+func isContentArchiveTextMember(name string) bool {
+	contentArchiveTextExtensionsOnce.Do(func() {
+		builtin := make(map[string]bool)
+		for _, extractor := range builtinContentExtractors() {
+			for _, extension := range extractor.Extensions() {
+				builtin[extension] = true
+			}
+		}
+		contentArchiveTextExtensions = make(map[string]bool)
+		for _, extension := range ContentDefaultExtensions() {
+			if !builtin[extension] {
+				contentArchiveTextExtensions[extension] = true
+			}
+		}
+	})
+	return contentArchiveTextExtensions[contentNormalizeExtension(name)]
+}
+
+// isBinaryContentPrefix reports whether data looks like a binary file: a NUL
+// byte or a high share of control characters.
+func isBinaryContentPrefix(data []byte) bool {
+	control := 0
+	for _, b := range data {
+		if b == 0 {
+			return true
+		}
+		if b < 0x20 && !unicode.IsSpace(rune(b)) {
+			control++
+		}
+	}
+	return control*10 > len(data)
+}
@@ -0,0 +1,412 @@
+package filesearch
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// extractOpenDocumentText reads content.xml from an ODF package. Text,
+// spreadsheet and presentation documents share the same body part, so one
+// extractor covers odt, ods and odp.
+func extractOpenDocumentText(ctx context.Context, path string, maxBytes int64) (string, error) {
+	return extractOpenXMLText(ctx, path, maxBytes, func(name string) bool {
+		return name == "content.xml"
+	})
+}
+
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+type epubPackage struct {
+	Manifest []struct {
+		ID        string `xml:"id,attr"`
+		Href      string `xml:"href,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"manifest>item"`
+	Spine []struct {
+		IDRef string `xml:"idref,attr"`
+	} `xml:"spine>itemref"`
+}
+
+// extractEPUBText reads the book's XHTML documents in reading order so the
+// text cap keeps the beginning of the book rather than arbitrary chapters.
+func extractEPUBText(ctx context.Context, path string, maxBytes int64) (string, error) {
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	files := make(map[string]*zip.File, len(reader.File))
+	for _, file := range reader.File {
+		files[strings.TrimPrefix(file.Name, "/")] = file
+	}
+
+	builder := newContentTextBuilder(maxBytes)
+	for _, file := range listEPUBDocuments(files) {
+		if builder.Done() {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		readCloser, err := file.Open()
+		if err != nil {
+			return "", err
+		}
+		err = appendHTMLText(ctx, io.LimitReader(readCloser, openXMLMaxEntryReadBytes), builder)
+		readCloser.Close()
+		if err != nil {
+			return "", fmt.Errorf("parse %s: %w", file.Name, err)
+		}
+		builder.AppendSeparator()
+	}
+	return builder.String(), nil
+}
+
+// listEPUBDocuments follows container.xml to the package spine. Books with a
+// broken package fall back to every XHTML part in name order.
+func listEPUBDocuments(files map[string]*zip.File) []*zip.File {
+	var container epubContainer
+	if err := decodeOpenXMLPart(files["META-INF/container.xml"], &container); err == nil && len(container.Rootfiles) > 0 {
+		packagePath := container.Rootfiles[0].FullPath
+		var pkg epubPackage
+		if err := decodeOpenXMLPart(files[packagePath], &pkg); err == nil {
+			hrefs := make(map[string]string, len(pkg.Manifest))
+			for _, item := range pkg.Manifest {
+				href, err := url.PathUnescape(item.Href)
+				if err != nil {
+					href = item.Href
+				}
+				hrefs[item.ID] = path.Join(path.Dir(packagePath), href)
+			}
+			documents := make([]*zip.File, 0, len(pkg.Spine))
+			for _, itemRef := range pkg.Spine {
+				if file := files[hrefs[itemRef.IDRef]]; file != nil {
+					documents = append(documents, file)
+				}
+			}
+			if len(documents) > 0 {
+				return documents
+			}
+		}
+	}
+
+	documents := make([]*zip.File, 0)
+	for name, file := range files {
+		switch strings.ToLower(path.Ext(name)) {
+		case ".xhtml", ".html", ".htm":
+			documents = append(documents, file)
+		}
+	}
+	sort.Slice(documents, func(i, j int) bool { return documents[i].Name < documents[j].Name })
+	return documents
+}
+
+// rtfSkippedDestinations are groups that hold formatting tables, metadata or
+// binary payloads instead of document text.
+var rtfSkippedDestinations = map[string]bool{
+	"fonttbl": true, "colortbl": true, "stylesheet": true, "info": true, "pict": true,
+	"object": true, "themedata": true, "colorschememapping": true, "datastore": true,
+	"latentstyles": true, "listtable": true, "listoverridetable": true, "rsidtbl": true,
+	"generator": true, "xmlnstbl": true, "mmathPr": true, "fldinst": true, "filetbl": true,
+	"revtbl": true, "pgdsctbl": true, "bkmkstart": true, "bkmkend": true, "nonshppict": true,
+}
+
+type rtfGroupState struct {
+	skip bool
+	// unicodeSkip is the \ucN fallback length that follows every \uN.
+	unicodeSkip int
+}
+
+// extractRTFText converts RTF to plain text with a streaming parser: control
+// words become separators or characters, hex escapes are decoded as
+// Windows-1252 and \uN escapes as UTF-16 code units.
+func extractRTFText(ctx context.Context, path string, maxBytes int64) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	builder := newContentTextBuilder(maxBytes)
+	if err := appendRTFText(ctx, bufio.NewReader(f), builder); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
+
+func appendRTFText(ctx context.Context, reader *bufio.Reader, builder *contentTextBuilder) error {
+	state := rtfGroupState{unicodeSkip: 1}
+	var stack []rtfGroupState
+	groupStart := false
+	pendingFallback := 0
+	var highSurrogate rune
+
+	emit := func(r rune) {
+		if state.skip {
+			return
+		}
+		if pendingFallback > 0 {
+			pendingFallback--
+			return
+		}
+		builder.AppendText(string(r))
+	}
+
+	for byteCount := 0; !builder.Done(); byteCount++ {
+		if byteCount%4096 == 0 && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		b, err := reader.ReadByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch b {
+		case '{':
+			stack = append(stack, state)
+			groupStart = true
+			continue
+		case '}':
+			if len(stack) > 0 {
+				state = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+			}
+			groupStart = false
+			pendingFallback = 0
+			continue
+		case '\r', '\n':
+			continue
+		case '\\':
+		default:
+			groupStart = false
+			emit(charmap.Windows1252.DecodeByte(b))
+			continue
+		}
+
+		next, err := reader.ReadByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !isASCIILetter(next) {
+			wasGroupStart := groupStart
+			groupStart = false
+			switch next {
+			case '*':
+				if wasGroupStart {
+					state.skip = true
+				}
+			case '\'':
+				hex := make([]byte, 2)
+				if _, err := io.ReadFull(reader, hex); err != nil {
+					return nil
+				}
+				if value, err := strconv.ParseUint(string(hex), 16, 8); err == nil {
+					emit(charmap.Windows1252.DecodeByte(byte(value)))
+				}
+			case '~':
+				emit(' ')
+			case '_':
+				emit('-')
+			case '\r', '\n':
+				emit('\n')
+			case '-':
+				// Optional hyphen.
+			default:
+				emit(rune(next))
+			}
+			continue
+		}
+
+		word, param, hasParam, err := readRTFControlWord(reader, next)
+		if err != nil {
+			return err
+		}
+		if groupStart && rtfSkippedDestinations[word] {
+			state.skip = true
+		}
+		groupStart = false
+
+		switch word {
+		case "par", "line", "sect", "page", "row", "tab", "cell":
+			emit(' ')
+		case "uc":
+			if hasParam && param >= 0 {
+				state.unicodeSkip = param
+			}
+		case "u":
+			if !hasParam {
+				continue
+			}
+			r := rune(param)
+			if r < 0 {
+				r += 0x10000
+			}
+			pendingFallback = 0
+			switch {
+			case r >= 0xD800 && r <= 0xDBFF:
+				highSurrogate = r
+			case r >= 0xDC00 && r <= 0xDFFF && highSurrogate != 0:
+				emit((highSurrogate-0xD800)<<10 + (r - 0xDC00) + 0x10000)
+				highSurrogate = 0
+			default:
+				emit(r)
+			}
+			pendingFallback = state.unicodeSkip
+		case "emdash":
+			emit('—')
+		case "endash":
+			emit('–')
+		case "bullet":
+			emit('•')
+		case "lquote", "rquote":
+			emit('\'')
+		case "ldblquote", "rdblquote":
+			emit('"')
+		case "bin":
+			// Binary payloads are raw bytes that must not be parsed as RTF.
+			if hasParam && param > 0 {
+				if _, err := reader.Discard(param); err != nil {
+					return nil
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// readRTFControlWord reads the rest of a control word whose first letter was
+// already consumed, plus its optional numeric parameter and delimiter space.
+func readRTFControlWord(reader *bufio.Reader, first byte) (string, int, bool, error) {
+	var word strings.Builder
+	word.WriteByte(first)
+	for {
+		b, err := reader.ReadByte()
+		if err == io.EOF {
+			return word.String(), 0, false, nil
+		}
+		if err != nil {
+			return "", 0, false, err
+		}
+		if isASCIILetter(b) {
+			word.WriteByte(b)
+			continue
+		}
+
+		param, hasParam, negative, digits := 0, false, false, 0
+		if b == '-' {
+			negative = true
+			if b, err = reader.ReadByte(); err != nil {
+				return word.String(), 0, false, nil
+			}
+		}
+		for b >= '0' && b <= '9' {
+			hasParam = true
+			// RTF parameters are 16 or 32 bit; longer digit runs are malformed.
+			if digits < 10 {
+				param = param*10 + int(b-'0')
+			}
+			digits++
+			if b, err = reader.ReadByte(); err != nil {
+				break
+			}
+		}
+		if negative {
+			param = -param
+		}
+		if err == nil && b != ' ' {
+			_ = reader.UnreadByte()
+		}
+		return word.String(), param, hasParam, nil
+	}
+}
+
+func isASCIILetter(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+// jupyterText is notebook text, stored either as one string or as a list of
+// lines depending on the writer.
+type jupyterText string
+
+func (t *jupyterText) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*t = jupyterText(text)
+		return nil
+	}
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err != nil {
+		return err
+	}
+	*t = jupyterText(strings.Join(lines, ""))
+	return nil
+}
+
+type jupyterNotebook struct {
+	Cells []struct {
+		CellType string      `json:"cell_type"`
+		Source   jupyterText `json:"source"`
+		Outputs  []struct {
+			Text jupyterText                `json:"text"`
+			Data map[string]json.RawMessage `json:"data"`
+		} `json:"outputs"`
+	} `json:"cells"`
+}
+
+// extractJupyterNotebookText indexes cell sources plus textual outputs. The
+// JSON envelope, base64 images and execution metadata are left out.
+func extractJupyterNotebookText(ctx context.Context, path string, maxBytes int64) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	var notebook jupyterNotebook
+	if err := json.Unmarshal(data, &notebook); err != nil {
+		return "", fmt.Errorf("parse notebook: %w", err)
+	}
+
+	builder := newContentTextBuilder(maxBytes)
+	for _, cell := range notebook.Cells {
+		if builder.Done() {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		builder.AppendText(string(cell.Source))
+		builder.AppendSeparator()
+		for _, output := range cell.Outputs {
+			builder.AppendText(string(output.Text))
+			builder.AppendSeparator()
+			var plain jupyterText
+			if data, ok := output.Data["text/plain"]; ok && json.Unmarshal(data, &plain) == nil {
+				builder.AppendText(string(plain))
+				builder.AppendSeparator()
+			}
+		}
+	}
+	return builder.String(), nil
+}
@@ -0,0 +1,91 @@
+package filesearch
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"golang.org/x/net/html"
+)
+
+// extractHTMLText indexes the visible text of an HTML page. Indexing raw
+// markup made every page match tag and attribute names such as "div" or
+// "class", which buried real hits.
+func extractHTMLText(ctx context.Context, path string, maxBytes int64) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	builder := newContentTextBuilder(maxBytes)
+	if err := appendHTMLText(ctx, f, builder); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
+
+// appendHTMLText streams markup into builder, dropping tags, comments and the
+// content of elements that are never rendered as text. Entities are decoded by
+// the tokenizer. Block boundaries become separators so adjacent paragraphs do
+// not merge into one token, while inline tags keep words intact.
+func appendHTMLText(ctx context.Context, reader io.Reader, builder *contentTextBuilder) error {
+	tokenizer := html.NewTokenizer(reader)
+	hiddenDepth := 0
+	for tokenCount := 0; !builder.Done(); tokenCount++ {
+		// Checking ctx on every token is measurable on large pages.
+		if tokenCount%1024 == 0 && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if err := tokenizer.Err(); err != io.EOF {
+				return err
+			}
+			return nil
+		case html.StartTagToken:
+			name, _ := tokenizer.TagName()
+			if isHiddenHTMLElement(string(name)) {
+				hiddenDepth++
+			} else if isBlockHTMLElement(string(name)) {
+				builder.AppendSeparator()
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			if isHiddenHTMLElement(string(name)) {
+				if hiddenDepth > 0 {
+					hiddenDepth--
+				}
+			} else if isBlockHTMLElement(string(name)) {
+				builder.AppendSeparator()
+			}
+		case html.SelfClosingTagToken:
+			builder.AppendSeparator()
+		case html.TextToken:
+			if hiddenDepth == 0 {
+				builder.AppendText(string(tokenizer.Text()))
+			}
+		}
+	}
+	return nil
+}
+
+func isHiddenHTMLElement(name string) bool {
+	switch name {
+	case "script", "style", "noscript", "template", "svg", "canvas", "iframe", "object":
+		return true
+	default:
+		return false
+	}
+}
+
+func isBlockHTMLElement(name string) bool {
+	switch name {
+	case "address", "article", "aside", "blockquote", "br", "caption", "dd", "div", "dl", "dt",
+		"figcaption", "figure", "footer", "form", "h1", "h2", "h3", "h4", "h5", "h6", "header",
+		"hr", "li", "main", "nav", "ol", "p", "pre", "section", "table", "td", "th", "title", "tr", "ul":
+		return true
+	default:
+		return false
+	}
+}
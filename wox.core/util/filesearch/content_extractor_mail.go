@@ -0,0 +1,234 @@
+package filesearch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+const (
+	// contentMailMaxDepth stops pathological nesting of multiparts and
+	// forwarded messages.
+	contentMailMaxDepth = 8
+	// contentMboxMaxMessageBytes bounds how much of one mbox message is
+	// buffered. Large attachments are cut off, but headers and the leading
+	// text parts are kept.
+	contentMboxMaxMessageBytes = 8 * 1024 * 1024
+)
+
+// contentMailHeaders are indexed alongside the body so a search for a sender
+// or subject finds the message.
+var contentMailHeaders = []string{"Subject", "From", "To", "Cc"}
+
+var contentMailWordDecoder = &mime.WordDecoder{CharsetReader: contentCharsetReader}
+
+// extractEmailText indexes headers and text parts of one RFC 5322 message.
+// Attachments are skipped; HTML-only bodies are stripped to visible text.
+func extractEmailText(ctx context.Context, path string, maxBytes int64) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	builder := newContentTextBuilder(maxBytes)
+	if err := appendEmailMessageText(ctx, f, builder, 0); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
+
+// extractMboxText indexes every message of an mbox mailbox, in file order,
+// until the text cap is reached.
+func extractMboxText(ctx context.Context, path string, maxBytes int64) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	builder := newContentTextBuilder(maxBytes)
+	reader := bufio.NewReader(f)
+	var message bytes.Buffer
+	flush := func() error {
+		if message.Len() == 0 {
+			return nil
+		}
+		// One malformed message must not hide the rest of the mailbox.
+		_ = appendEmailMessageText(ctx, bytes.NewReader(message.Bytes()), builder, 0)
+		builder.AppendSeparator()
+		message.Reset()
+		return ctx.Err()
+	}
+
+	previousBlank := true
+	for !builder.Done() {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			// mboxrd and mboxo both start a message with "From " after a blank
+			// line; the envelope line itself is not part of the message.
+			if previousBlank && bytes.HasPrefix(line, []byte("From ")) {
+				if flushErr := flush(); flushErr != nil {
+					return "", flushErr
+				}
+			} else if message.Len() < contentMboxMaxMessageBytes {
+				message.Write(line)
+			}
+			previousBlank = len(bytes.TrimRight(line, "\r\n")) == 0
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	if err := flush(); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
+
+func appendEmailMessageText(ctx context.Context, reader io.Reader, builder *contentTextBuilder, depth int) error {
+	message, err := mail.ReadMessage(reader)
+	if err != nil {
+		return fmt.Errorf("parse email: %w", err)
+	}
+	for _, key := range contentMailHeaders {
+		value := message.Header.Get(key)
+		if value == "" {
+			continue
+		}
+		if decoded, err := contentMailWordDecoder.DecodeHeader(value); err == nil {
+			value = decoded
+		}
+		builder.AppendText(value)
+		builder.AppendSeparator()
+	}
+	return appendMIMEPartText(ctx, message.Header.Get("Content-Type"), message.Header.Get("Content-Transfer-Encoding"), message.Body, builder, depth)
+}
+
+// appendMIMEPartText walks one MIME part. multipart/alternative keeps only the
+// first part that produced text, which is the plain-text version in practice,
+// so the same body is not indexed twice.
+func appendMIMEPartText(ctx context.Context, contentType string, transferEncoding string, body io.Reader, builder *contentTextBuilder, depth int) error {
+	if depth > contentMailMaxDepth || builder.Done() {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType == "" {
+		mediaType, params = "text/plain", nil
+	}
+
+	switch {
+	case strings.HasPrefix(mediaType, "multipart/"):
+		if params["boundary"] == "" {
+			return nil
+		}
+		alternative := mediaType == "multipart/alternative"
+		multipartReader := multipart.NewReader(body, params["boundary"])
+		for !builder.Done() {
+			part, err := multipartReader.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				// Truncated messages keep whatever parts were complete.
+				return nil
+			}
+			if isMIMEAttachment(part.Header.Get("Content-Disposition")) {
+				continue
+			}
+			before := builder.Len()
+			if err := appendMIMEPartText(ctx, part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part, builder, depth+1); err != nil {
+				return err
+			}
+			if alternative && builder.Len() > before {
+				return nil
+			}
+		}
+		return nil
+	case mediaType == "message/rfc822":
+		return appendEmailMessageText(ctx, decodeMIMETransfer(body, transferEncoding), builder, depth+1)
+	case mediaType == "text/plain":
+		text, err := readMIMEText(decodeMIMETransfer(body, transferEncoding), params["charset"], builder)
+		if err != nil {
+			return err
+		}
+		builder.AppendText(text)
+		builder.AppendSeparator()
+		return nil
+	case mediaType == "text/html":
+		reader, err := contentCharsetReader(params["charset"], decodeMIMETransfer(body, transferEncoding))
+		if err != nil {
+			return err
+		}
+		if err := appendHTMLText(ctx, reader, builder); err != nil {
+			return err
+		}
+		builder.AppendSeparator()
+		return nil
+	default:
+		return nil
+	}
+}
+
+func isMIMEAttachment(disposition string) bool {
+	mediaType, _, err := mime.ParseMediaType(disposition)
+	return err == nil && mediaType == "attachment"
+}
+
+func decodeMIMETransfer(body io.Reader, transferEncoding string) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(transferEncoding)) {
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, body)
+	default:
+		return body
+	}
+}
+
+// readMIMEText reads just enough decoded text to fill the builder.
+func readMIMEText(body io.Reader, charset string, builder *contentTextBuilder) (string, error) {
+	reader, err := contentCharsetReader(charset, body)
+	if err != nil {
+		return "", err
+	}
+	data, err := io.ReadAll(io.LimitReader(reader, int64(builder.Remaining())+utf8MaxRuneBytes))
+	if err != nil && len(data) == 0 {
+		return "", err
+	}
+	return string(data), nil
+}
+
+const utf8MaxRuneBytes = 4
+
+// contentCharsetReader converts legacy mail charsets to UTF-8. Unknown
+// charsets pass through unchanged, which is right for the ASCII subset.
+func contentCharsetReader(charset string, input io.Reader) (io.Reader, error) {
+	charset = strings.ToLower(strings.TrimSpace(charset))
+	if charset == "" || charset == "utf-8" || charset == "us-ascii" {
+		return input, nil
+	}
+	encoding, err := htmlindex.Get(charset)
+	if err != nil {
+		return input, nil
+	}
+	return encoding.NewDecoder().Reader(input), nil
+}
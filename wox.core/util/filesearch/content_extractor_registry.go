@@ -0,0 +1,268 @@
+package filesearch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"wox/util"
+)
+
+// contentExtractorDefaultTimeout bounds one file extraction when an extractor
+// does not declare its own timeout. A crawl must never stall on one malformed
+// document, so the worker gives up and records the file as failed.
+const contentExtractorDefaultTimeout = 15 * time.Second
+
+// ContentExtractor turns one document format into plain text for the content
+// index. Built-in extractors cover the default formats; plugins can register
+// their own through the plugin API RegisterContentExtractor.
+type ContentExtractor interface {
+	// Name identifies the extractor in logs and in UnregisterContentExtractor.
+	Name() string
+	// Extensions lists handled file extensions, without the leading dot.
+	Extensions() []string
+	// Limits returns the caps the crawler applies to this extractor.
+	Limits() ContentExtractorLimits
+	// Extract returns at most maxBytes of text. Implementations should stop
+	// early when ctx is done; the caller abandons them after the timeout.
+	Extract(ctx context.Context, path string, maxBytes int64) (string, error)
+}
+
+// ContentExtractorLimits are per-extractor caps honoured by the content crawler
+// and hook. Zero values fall back to the crawler defaults.
+type ContentExtractorLimits struct {
+	// MaxBytes caps extracted text. It can only tighten the configured
+	// max-read-bytes, never raise it.
+	MaxBytes int64
+	// MaxFileBytes skips files larger than this. Skipped files are indexed
+	// without text so unchanged files are not reopened on every crawl.
+	MaxFileBytes int64
+	// Timeout bounds one extraction.
+	Timeout time.Duration
+}
+
+// NewContentExtractor adapts a function to ContentExtractor so plugins do not
+// need to declare a type for a single-format extractor.
+func NewContentExtractor(name string, extensions []string, limits ContentExtractorLimits, extract func(ctx context.Context, path string, maxBytes int64) (string, error)) ContentExtractor {
+	return &funcContentExtractor{name: name, extensions: extensions, limits: limits, extract: extract}
+}
+
+type funcContentExtractor struct {
+	name       string
+	extensions []string
+	limits     ContentExtractorLimits
+	extract    func(ctx context.Context, path string, maxBytes int64) (string, error)
+	// rawText marks extractors that copy file bytes verbatim, so their cap is
+	// also bounded by the file size.
+	rawText bool
+	// segments optionally keeps location boundaries for content snippets.
+	segments func(ctx context.Context, path string, maxBytes int64) ([]contentSegment, error)
+}
+
+func (e *funcContentExtractor) Name() string                   { return e.name }
+func (e *funcContentExtractor) Extensions() []string           { return e.extensions }
+func (e *funcContentExtractor) Limits() ContentExtractorLimits { return e.limits }
+
+func (e *funcContentExtractor) Extract(ctx context.Context, path string, maxBytes int64) (string, error) {
+	return e.extract(ctx, path, maxBytes)
+}
+
+// extractSegments implements contentSegmentExtractor. Extractors without
+// location boundaries return their text as one passage.
+func (e *funcContentExtractor) extractSegments(ctx context.Context, path string, maxBytes int64) ([]contentSegment, error) {
+	if e.segments != nil {
+		return e.segments(ctx, path, maxBytes)
+	}
+	text, err := e.extract(ctx, path, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	return []contentSegment{{text: text, lines: e.rawText}}, nil
+}
+
+// contentSegmentExtractor is implemented by built-in extractors that can say
+// where text came from. Plugin extractors fall back to a single passage.
+type contentSegmentExtractor interface {
+	extractSegments(ctx context.Context, path string, maxBytes int64) ([]contentSegment, error)
+}
+
+type contentExtractorRegistry struct {
+	mu      sync.RWMutex
+	builtin map[string]ContentExtractor
+	// registered keeps plugin extractors in registration order. Later
+	// registrations win, and any registration wins over a built-in, so a plugin
+	// can ship a better parser for a format Wox already understands.
+	registered []ContentExtractor
+	fallback   ContentExtractor
+}
+
+var contentExtractors = newContentExtractorRegistry()
+
+func newContentExtractorRegistry() *contentExtractorRegistry {
+	registry := &contentExtractorRegistry{
+		builtin:  make(map[string]ContentExtractor),
+		fallback: plainTextContentExtractor,
+	}
+	for _, extractor := range builtinContentExtractors() {
+		for _, extension := range extractor.Extensions() {
+			registry.builtin[normalizeContentExtractorExtension(extension)] = extractor
+		}
+	}
+	return registry
+}
+
+// RegisterContentExtractor adds or replaces (by Name) a content extractor.
+// Files that were already indexed keep their text until they change or the
+// content index is rebuilt.
+func RegisterContentExtractor(extractor ContentExtractor) error {
+	if extractor == nil {
+		return fmt.Errorf("content extractor is nil")
+	}
+	name := strings.TrimSpace(extractor.Name())
+	if name == "" {
+		return fmt.Errorf("content extractor name is empty")
+	}
+	if len(extractor.Extensions()) == 0 {
+		return fmt.Errorf("content extractor %s has no extensions", name)
+	}
+
+	contentExtractors.mu.Lock()
+	defer contentExtractors.mu.Unlock()
+	contentExtractors.removeLocked(name)
+	contentExtractors.registered = append(contentExtractors.registered, extractor)
+	return nil
+}
+
+// UnregisterContentExtractor removes a plugin extractor. Built-in extractors
+// for the same extensions take over again.
+func UnregisterContentExtractor(name string) {
+	contentExtractors.mu.Lock()
+	defer contentExtractors.mu.Unlock()
+	contentExtractors.removeLocked(strings.TrimSpace(name))
+}
+
+func (r *contentExtractorRegistry) removeLocked(name string) {
+	kept := r.registered[:0]
+	for _, extractor := range r.registered {
+		if extractor.Name() != name {
+			kept = append(kept, extractor)
+		}
+	}
+	clear(r.registered[len(kept):])
+	r.registered = kept
+}
+
+// lookup returns the extractor for an extension, falling back to plain text
+// so any whitelisted source extension keeps working without registration.
+func (r *contentExtractorRegistry) lookup(extension string) ContentExtractor {
+	extension = normalizeContentExtractorExtension(extension)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for index := len(r.registered) - 1; index >= 0; index-- {
+		for _, candidate := range r.registered[index].Extensions() {
+			if normalizeContentExtractorExtension(candidate) == extension {
+				return r.registered[index]
+			}
+		}
+	}
+	if extractor, ok := r.builtin[extension]; ok {
+		return extractor
+	}
+	return r.fallback
+}
+
+func normalizeContentExtractorExtension(extension string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(extension), "."))
+}
+
+func lookupContentExtractor(path string) ContentExtractor {
+	return contentExtractors.lookup(contentNormalizeExtension(path))
+}
+
+// extractContentText returns plain text suitable for content indexing through
+// the extractor registered for the path's extension, bounded by its timeout.
+func extractContentText(ctx context.Context, path string, maxBytes int64) (string, error) {
+	if maxBytes <= 0 {
+		return "", nil
+	}
+	extractor := lookupContentExtractor(path)
+	return runContentExtractor(ctx, extractor, path, func(extractCtx context.Context) (string, error) {
+		return extractor.Extract(extractCtx, path, maxBytes)
+	})
+}
+
+// extractContentSegments mirrors extractContentText but keeps location
+// boundaries when the extractor knows them: lines for text files, pages for
+// PDF, slides for PPTX and sheets for XLSX.
+func extractContentSegments(ctx context.Context, path string, maxBytes int64) ([]contentSegment, error) {
+	if maxBytes <= 0 {
+		return nil, nil
+	}
+	extractor := lookupContentExtractor(path)
+	return runContentExtractor(ctx, extractor, path, func(extractCtx context.Context) ([]contentSegment, error) {
+		if segmentExtractor, ok := extractor.(contentSegmentExtractor); ok {
+			return segmentExtractor.extractSegments(extractCtx, path, maxBytes)
+		}
+		text, err := extractor.Extract(extractCtx, path, maxBytes)
+		if err != nil {
+			return nil, err
+		}
+		return []contentSegment{{text: text}}, nil
+	})
+}
+
+// runContentExtractor runs one extraction in its own goroutine so a parser
+// that ignores ctx, hangs or panics cannot block a crawl worker. A timed-out
+// goroutine is abandoned and finishes in the background.
+func runContentExtractor[T any](ctx context.Context, extractor ContentExtractor, path string, extract func(context.Context) (T, error)) (T, error) {
+	timeout := extractor.Limits().Timeout
+	if timeout <= 0 {
+		timeout = contentExtractorDefaultTimeout
+	}
+	extractCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type extractResult struct {
+		value T
+		err   error
+	}
+	done := make(chan extractResult, 1)
+	util.Go(extractCtx, fmt.Sprintf("content extractor %s", extractor.Name()), func() {
+		value, err := extract(extractCtx)
+		done <- extractResult{value: value, err: err}
+	}, func() {
+		done <- extractResult{err: fmt.Errorf("content extractor %s panicked on %s", extractor.Name(), path)}
+	})
+
+	select {
+	case result := <-done:
+		return result.value, result.err
+	case <-extractCtx.Done():
+		var zero T
+		return zero, fmt.Errorf("content extractor %s on %s: %w", extractor.Name(), path, extractCtx.Err())
+	}
+}
+
+// contentExtractionMaxBytes returns the extracted-text cap for one file. Raw
+// text files stay bounded by their file size, while parsed containers use the
+// configured cap because their text can legitimately be larger than the file.
+// Extractor limits can only tighten the cap; 0 means the file is skipped.
+func contentExtractionMaxBytes(path string, fileSize int64, maxBytes int64) int64 {
+	if maxBytes <= 0 {
+		return 0
+	}
+	extractor := lookupContentExtractor(path)
+	limits := extractor.Limits()
+	if limits.MaxFileBytes > 0 && fileSize > limits.MaxFileBytes {
+		return 0
+	}
+	if limits.MaxBytes > 0 && limits.MaxBytes < maxBytes {
+		maxBytes = limits.MaxBytes
+	}
+	if builtin, ok := extractor.(*funcContentExtractor); ok && builtin.rawText && fileSize < maxBytes {
+		return fileSize
+	}
+	return maxBytes
+}
@@ -0,0 +1,251 @@
+package filesearch
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExtractContentTextStripsHTMLMarkup(t *testing.T) {
+	path := writeTestContentFile(t, "page.html", `<html><head><title>Release notes</title><style>.hidden{color:red}</style></head>
+<body><p>Wox&nbsp;supports <b>bold</b>text</p><script>var secret = "token";</script><div>next&amp;block</div></body></html>`)
+
+	text, err := extractContentText(context.Background(), path, ContentDefaultMaxReadBytes)
+	if err != nil {
+		t.Fatalf("extractContentText: %v", err)
+	}
+	if text != "Release notes Wox supports boldtext next&block" {
+		t.Fatalf("unexpected html text %q", text)
+	}
+}
+
+func TestExtractContentTextParsesRTF(t *testing.T) {
+	path := writeTestContentFile(t, "letter.rtf", `{\rtf1\ansi\deff0{\fonttbl{\f0 Times New Roman;}}{\*\generator Writer;}
+{\info{\title Hidden title}}\f0 Dear team,\par Caf\'e9 budget \'8020\tab approved.\par}`)
+
+	text, err := extractContentText(context.Background(), path, ContentDefaultMaxReadBytes)
+	if err != nil {
+		t.Fatalf("extractContentText: %v", err)
+	}
+	if text != "Dear team, Café budget €20 approved." {
+		t.Fatalf("unexpected rtf text %q", text)
+	}
+}
+
+func TestExtractContentTextPrefersPlainEmailAlternative(t *testing.T) {
+	path := writeTestContentFile(t, "mail.eml", strings.Join([]string{
+		"From: Alice <alice@example.com>",
+		"To: bob@example.com",
+		"Subject: =?UTF-8?B?UXVhcnRhbHNiZXJpY2h0?=",
+		"MIME-Version: 1.0",
+		`Content-Type: multipart/mixed; boundary="outer"`,
+		"",
+		"--outer",
+		`Content-Type: multipart/alternative; boundary="inner"`,
+		"",
+		"--inner",
+		"Content-Type: text/plain; charset=iso-8859-1",
+		"Content-Transfer-Encoding: quoted-printable",
+		"",
+		"Gr=FC=DFe aus Berlin",
+		"--inner",
+		"Content-Type: text/html",
+		"",
+		"<p>html duplicate</p>",
+		"--inner--",
+		"--outer",
+		"Content-Type: text/plain",
+		`Content-Disposition: attachment; filename="notes.txt"`,
+		"",
+		"attachment body",
+		"--outer--",
+		"",
+	}, "\r\n"))
+
+	text, err := extractContentText(context.Background(), path, ContentDefaultMaxReadBytes)
+	if err != nil {
+		t.Fatalf("extractContentText: %v", err)
+	}
+	if text != "Quartalsbericht Alice <alice@example.com> bob@example.com Grüße aus Berlin" {
+		t.Fatalf("unexpected email text %q", text)
+	}
+
+	mbox := writeTestContentFile(t, "inbox.mbox", "From alice@example.com Sat Jan  3 01:05:34 2026\nSubject: first\n\nhello\n\nFrom bob@example.com Sat Jan  3 02:05:34 2026\nSubject: second\n\nworld\n")
+	text, err = extractContentText(context.Background(), mbox, ContentDefaultMaxReadBytes)
+	if err != nil || text != "first hello second world" {
+		t.Fatalf("unexpected mbox text %q err=%v", text, err)
+	}
+}
+
+func TestExtractContentTextReadsNotebooksAndSourceArchives(t *testing.T) {
+	notebook := writeTestContentFile(t, "analysis.ipynb", `{"cells":[
+{"cell_type":"markdown","source":["# Churn model\n","details"]},
+{"cell_type":"code","source":"print(total)","outputs":[{"output_type":"stream","text":["42\n"]},{"output_type":"display_data","data":{"image/png":"iVBORw0KGgo=","application/json":{"a":1},"text/plain":"<Figure>"}}]}
+],"metadata":{"kernelspec":{"name":"python3"}}}`)
+	text, err := extractContentText(context.Background(), notebook, ContentDefaultMaxReadBytes)
+	if err != nil {
+		t.Fatalf("extract notebook: %v", err)
+	}
+	if text != "# Churn model details print(total) 42 <Figure>" {
+		t.Fatalf("unexpected notebook text %q", text)
+	}
+
+	archive := filepath.Join(t.TempDir(), "src.zip")
+	writeTestZip(t, archive, map[string]string{
+		"project/main.go":   "package main // entrypoint",
+		"project/logo.png":  "\x89PNG",
+		"project/data.json": "{\"k\":\"\x00binary\"}",
+	})
+	text, err = extractContentText(context.Background(), archive, ContentDefaultMaxReadBytes)
+	if err != nil {
+		t.Fatalf("extract archive: %v", err)
+	}
+	if text != "project/main.go package main // entrypoint" {
+		t.Fatalf("unexpected archive text %q", text)
+	}
+}
+
+func TestSourceArchiveExtractionStopsOnContextAndSize(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "src.tgz")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatalf("create tgz: %v", err)
+	}
+	gzipWriter := gzip.NewWriter(f)
+	tarWriter := tar.NewWriter(gzipWriter)
+	body := strings.Repeat("x", 4096)
+	if err := tarWriter.WriteHeader(&tar.Header{Name: "main.go", Mode: 0o644, Size: int64(len(body)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatalf("write tar header: %v", err)
+	}
+	if _, err := tarWriter.Write([]byte(body)); err != nil {
+		t.Fatalf("write tar body: %v", err)
+	}
+	if err := errors.Join(tarWriter.Close(), gzipWriter.Close(), f.Close()); err != nil {
+		t.Fatalf("close tgz: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reader := &contentContextReader{ctx: ctx, reader: strings.NewReader(body)}
+	cancel()
+	if _, err := reader.Read(make([]byte, 16)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("read after cancel err=%v, want context.Canceled", err)
+	}
+	if _, err := extractSourceArchiveText(ctx, archive, ContentDefaultMaxReadBytes); !errors.Is(err, context.Canceled) {
+		t.Fatalf("extract after cancel err=%v, want context.Canceled", err)
+	}
+	if maxBytes := contentExtractionMaxBytes(archive, contentArchiveMaxFileBytes+1, ContentDefaultMaxReadBytes); maxBytes != 0 {
+		t.Fatalf("oversized archive max bytes = %d, want it skipped", maxBytes)
+	}
+}
+
+func TestExtractContentTextReadsOpenDocumentAndEPUB(t *testing.T) {
+	odt := filepath.Join(t.TempDir(), "report.odt")
+	writeTestZip(t, odt, map[string]string{
+		"mimetype":    "application/vnd.oasis.opendocument.text",
+		"content.xml": `<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"><office:body><office:text><text:p>Open document body</text:p></office:text></office:body></office:document-content>`,
+		"styles.xml":  `<styles>Ignored style text</styles>`,
+	})
+	text, err := extractContentText(context.Background(), odt, ContentDefaultMaxReadBytes)
+	if err != nil || text != "Open document body" {
+		t.Fatalf("unexpected odt text %q err=%v", text, err)
+	}
+
+	epub := filepath.Join(t.TempDir(), "book.epub")
+	writeTestZip(t, epub, map[string]string{
+		"META-INF/container.xml": `<container><rootfiles><rootfile full-path="OEBPS/content.opf"/></rootfiles></container>`,
+		"OEBPS/content.opf": `<package><manifest><item id="c2" href="chapter%202.xhtml" media-type="application/xhtml+xml"/><item id="c1" href="chapter1.xhtml" media-type="application/xhtml+xml"/></manifest>
+<spine><itemref idref="c1"/><itemref idref="c2"/></spine></package>`,
+		"OEBPS/chapter1.xhtml":   `<html><body><h1>Chapter one</h1><p>It begins.</p></body></html>`,
+		"OEBPS/chapter 2.xhtml":  `<html><body><h1>Chapter two</h1></body></html>`,
+		"OEBPS/unused.xhtml":     `<html><body>not in spine</body></html>`,
+		"OEBPS/images/cover.jpg": "binary",
+	})
+	text, err = extractContentText(context.Background(), epub, ContentDefaultMaxReadBytes)
+	if err != nil || text != "Chapter one It begins. Chapter two" {
+		t.Fatalf("unexpected epub text %q err=%v", text, err)
+	}
+}
+
+func TestBuiltinContentExtractorExtensionsAreIndexedByDefault(t *testing.T) {
+	defaults := ContentExtensionsFromList(ContentDefaultExtensions())
+	optIn := ContentExtensionsFromList(ContentOptInExtensions())
+	for extension := range optIn {
+		if defaults[extension] {
+			t.Errorf("opt-in extension %s must not be indexed by default", extension)
+		}
+	}
+	for _, extractor := range builtinContentExtractors() {
+		for _, extension := range extractor.Extensions() {
+			if !defaults[extension] && !optIn[extension] {
+				t.Errorf("extension %s of the %s extractor is missing from the default content extensions", extension, extractor.Name())
+			}
+		}
+	}
+}
+
+func TestRegisteredContentExtractorOverridesBuiltinAndHonoursLimits(t *testing.T) {
+	path := writeTestContentFile(t, "notes.rtf", `{\rtf1 builtin text}`)
+	large := writeTestContentFile(t, "large.rtf", strings.Repeat("x", 64))
+
+	extractor := NewContentExtractor("test-rtf", []string{".RTF"}, ContentExtractorLimits{MaxBytes: 8, MaxFileBytes: 32}, func(ctx context.Context, path string, maxBytes int64) (string, error) {
+		return strings.Repeat("p", int(maxBytes)), nil
+	})
+	if err := RegisterContentExtractor(extractor); err != nil {
+		t.Fatalf("RegisterContentExtractor: %v", err)
+	}
+	t.Cleanup(func() { UnregisterContentExtractor("test-rtf") })
+
+	if readBytes := contentExtractionMaxBytes(path, 20, ContentDefaultMaxReadBytes); readBytes != 8 {
+		t.Fatalf("expected extractor byte cap to tighten the configured cap, got %d", readBytes)
+	}
+	if readBytes := contentExtractionMaxBytes(large, 64, ContentDefaultMaxReadBytes); readBytes != 0 {
+		t.Fatalf("expected files over MaxFileBytes to be skipped, got %d", readBytes)
+	}
+	text, err := extractContentText(context.Background(), path, contentExtractionMaxBytes(path, 20, ContentDefaultMaxReadBytes))
+	if err != nil || text != "pppppppp" {
+		t.Fatalf("expected plugin extractor output, got %q err=%v", text, err)
+	}
+
+	UnregisterContentExtractor("test-rtf")
+	text, err = extractContentText(context.Background(), path, ContentDefaultMaxReadBytes)
+	if err != nil || text != "builtin text" {
+		t.Fatalf("expected builtin extractor after unregister, got %q err=%v", text, err)
+	}
+}
+
+func TestContentExtractorTimeoutAbandonsSlowExtractor(t *testing.T) {
+	path := writeTestContentFile(t, "slow.slowdoc", "ignored")
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	extractor := NewContentExtractor("test-slow", []string{"slowdoc"}, ContentExtractorLimits{Timeout: 20 * time.Millisecond}, func(ctx context.Context, path string, maxBytes int64) (string, error) {
+		<-release
+		return "too late", nil
+	})
+	if err := RegisterContentExtractor(extractor); err != nil {
+		t.Fatalf("RegisterContentExtractor: %v", err)
+	}
+	t.Cleanup(func() { UnregisterContentExtractor("test-slow") })
+
+	startedAt := time.Now()
+	if _, err := extractContentText(context.Background(), path, ContentDefaultMaxReadBytes); err == nil {
+		t.Fatal("expected timeout error from slow extractor")
+	}
+	if elapsed := time.Since(startedAt); elapsed > time.Second {
+		t.Fatalf("expected extraction to stop at the extractor timeout, took %s", elapsed)
+	}
+}
+
+func writeTestContentFile(t *testing.T, name string, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
@@ -203,7 +203,7 @@ func (h *ContentIndexHook) processUpsert(ctx context.Context, path string) {
 	}
 
 	readBytes := contentExtractionMaxBytes(path, info.Size(), h.maxReadBytes)
-	text, err := extractContentText(path, readBytes)
+	text, err := extractContentText(ctx, path, readBytes)
 	if err != nil {
 		return
 	}
@@ -3,6 +3,7 @@ package filesearch
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"hash/fnv"
 	"path/filepath"
@@ -15,6 +16,9 @@ import (
 type ContentSearchResult struct {
 	Path  string
 	Score int64
+	// Snippets explain the hit. They are filled by Engine.SearchContent from a
+	// fresh extraction because the FTS table does not store the original text.
+	Snippets []ContentSnippet
 }
 
 // ContentStats is a snapshot of content index statistics for display.
@@ -55,12 +59,17 @@ type preparedContentIndexDocument struct {
 	IndexedBytes int64
 }
 
+// ErrContentIndexNotReady means the content index is closed or still crawling,
+// so its file list is incomplete.
+var ErrContentIndexNotReady = errors.New("content index is not ready")
+
 // contentCrawlStateKey is the meta key for content crawl state.
 const contentCrawlStateKey = "content_crawl_state"
 
 const (
-	contentIndexSchemaVersionKey     = "content_index_schema_version"
-	currentContentIndexSchemaVersion = "3"
+	contentIndexSchemaVersionKey = "content_index_schema_version"
+	// Version 4 re-extracts HTML as visible text instead of raw markup.
+	currentContentIndexSchemaVersion = "4"
 )
 
 // IndexContent indexes or updates a file's content in the content index.
@@ -447,6 +456,30 @@ func (d *ContentSearchDB) ListContentEntryPaths(ctx context.Context) ([]string,
 	return paths, rows.Err()
 }
 
+// ListContentEntryHashes returns the content hash of every indexed path.
+// Semantic search uses it to re-embed only files whose text changed.
+func (d *ContentSearchDB) ListContentEntryHashes(ctx context.Context) (map[string]uint32, error) {
+	if d == nil || d.db == nil {
+		return nil, fmt.Errorf("content search db not open")
+	}
+	rows, err := d.db.QueryContext(ctx, `SELECT path, content_hash FROM content_entries`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hashes := map[string]uint32{}
+	for rows.Next() {
+		var p string
+		var hash int64
+		if err := rows.Scan(&p, &hash); err != nil {
+			return nil, err
+		}
+		hashes[p] = uint32(hash)
+	}
+	return hashes, rows.Err()
+}
+
 // ListContentEntryPathsUnderScope returns all indexed content paths that fall
 // under the given directory scope (the scope path itself plus any path with it
 // as a parent prefix). Used by the content hook to reconcile content entries
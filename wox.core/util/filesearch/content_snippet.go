@@ -0,0 +1,638 @@
+package filesearch
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	pdf "github.com/ledongthuc/pdf"
+)
+
+const (
+	// contentSnippetMaxCount keeps the preview focused on the first few places
+	// that explain why the file matched instead of dumping every occurrence.
+	contentSnippetMaxCount = 3
+	// contentSnippetContextLines is the number of neighbouring lines shown around
+	// a matched line in text files.
+	contentSnippetContextLines = 1
+	// contentSnippetMaxLineBytes windows long lines and collapsed document text
+	// around the match so minified files and PDFs do not flood the preview.
+	contentSnippetMaxLineBytes = 240
+	contentSnippetLeadBytes    = 80
+	// contentSnippetTimeBudget bounds how long one query spends re-reading hits.
+	// The content FTS table is contentless, so snippets require re-extracting the
+	// file; hits past the budget are still returned, just without snippets.
+	contentSnippetTimeBudget = 300 * time.Millisecond
+	// contentSnippetFileTimeout bounds one file within the budget, so a large
+	// PDF gives up on its snippets instead of starving every later hit.
+	contentSnippetFileTimeout = 100 * time.Millisecond
+	contentSnippetEllipsis    = "…"
+)
+
+// ContentSnippet is one excerpt that explains why a file matched a content query.
+type ContentSnippet struct {
+	// Text is the excerpt. Text files keep their line breaks; structured
+	// documents use whitespace-collapsed text around the match.
+	Text string
+	// Line is the 1-based line of the first match for text files, 0 otherwise.
+	Line int
+	// Page is the 1-based PDF page or PowerPoint slide, 0 otherwise.
+	Page int
+	// Sheet is the spreadsheet sheet name for XLSX hits.
+	Sheet string
+	// Matches are byte ranges in Text that matched query terms.
+	Matches []ContentSnippetMatch
+}
+
+// ContentSnippetMatch is a half-open byte range [Start, End) inside ContentSnippet.Text.
+type ContentSnippetMatch struct {
+	Start int
+	End   int
+}
+
+// contentSegment is extracted text with the location it came from. Snippets are
+// built per segment so a hit can point at a line, page or sheet.
+type contentSegment struct {
+	text  string
+	page  int
+	sheet string
+	// lines reports whether text keeps its original line breaks.
+	lines bool
+}
+
+// attachContentSnippets re-extracts each hit and fills its snippets until the
+// time budget runs out. Each file also has its own timeout. Missing, unreadable
+// or slow files simply keep no snippets.
+func attachContentSnippets(ctx context.Context, results []ContentSearchResult, query string, maxBytes int64) {
+	terms := contentSnippetTerms(query)
+	if len(terms) == 0 {
+		return
+	}
+
+	// The deadline also reaches the extractors, so one slow document cannot
+	// hold the query past the budget.
+	snippetCtx, cancel := context.WithTimeout(ctx, contentSnippetTimeBudget)
+	defer cancel()
+	for index := range results {
+		if snippetCtx.Err() != nil {
+			return
+		}
+		fileCtx, fileCancel := context.WithTimeout(snippetCtx, contentSnippetFileTimeout)
+		snippets, err := loadContentSnippets(fileCtx, results[index].Path, terms, maxBytes)
+		fileCancel()
+		if err != nil {
+			continue
+		}
+		results[index].Snippets = snippets
+	}
+}
+
+func loadContentSnippets(ctx context.Context, path string, terms []string, maxBytes int64) ([]ContentSnippet, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, nil
+	}
+	segments, err := extractContentSegments(ctx, path, contentExtractionMaxBytes(path, info.Size(), maxBytes))
+	if err != nil {
+		return nil, err
+	}
+	return buildContentSnippets(segments, terms), nil
+}
+
+// extractPDFSegments returns one segment per page with an embedded text layer.
+func extractPDFSegments(ctx context.Context, path string, maxBytes int64) ([]contentSegment, error) {
+	f, reader, err := pdf.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	remaining := maxBytes
+	segments := make([]contentSegment, 0)
+	fonts := map[string]*pdf.Font{}
+	for pageNum := 1; pageNum <= reader.NumPage() && remaining > 0; pageNum++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		page := reader.Page(pageNum)
+		if page.V.IsNull() {
+			continue
+		}
+		text, err := page.GetPlainText(fonts)
+		if err != nil {
+			return nil, fmt.Errorf("extract pdf page %d: %w", pageNum, err)
+		}
+		builder := newContentTextBuilder(remaining)
+		builder.AppendText(text)
+		pageText := builder.String()
+		remaining -= int64(len(pageText)) + 1
+		if pageText != "" {
+			segments = append(segments, contentSegment{text: pageText, page: pageNum})
+		}
+	}
+	return segments, nil
+}
+
+// extractPresentationSegments returns one segment per slide. Slides are ordered
+// by their part number, which is how PowerPoint names them on save.
+func extractPresentationSegments(ctx context.Context, path string, maxBytes int64) ([]contentSegment, error) {
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	type slidePart struct {
+		number int
+		file   *zip.File
+	}
+	slides := make([]slidePart, 0)
+	for _, file := range reader.File {
+		name := strings.TrimPrefix(file.Name, "/")
+		if !strings.HasPrefix(name, "ppt/slides/slide") || !strings.HasSuffix(name, ".xml") {
+			continue
+		}
+		number, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, "ppt/slides/slide"), ".xml"))
+		if err != nil {
+			continue
+		}
+		slides = append(slides, slidePart{number: number, file: file})
+	}
+	sort.Slice(slides, func(i, j int) bool { return slides[i].number < slides[j].number })
+
+	remaining := maxBytes
+	segments := make([]contentSegment, 0, len(slides))
+	for _, slide := range slides {
+		if remaining <= 0 {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		builder := newContentTextBuilder(remaining)
+		if err := appendOpenXMLFileText(slide.file, builder); err != nil {
+			return nil, err
+		}
+		text := builder.String()
+		remaining -= int64(len(text)) + 1
+		if text != "" {
+			segments = append(segments, contentSegment{text: text, page: slide.number})
+		}
+	}
+	return segments, nil
+}
+
+type openXMLWorkbook struct {
+	Sheets []struct {
+		Name  string `xml:"name,attr"`
+		RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type openXMLRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// extractSpreadsheetSegments returns one segment per worksheet, named after the
+// workbook tab. Shared strings are resolved per cell so a hit points at the
+// sheet that actually references the matching text.
+func extractSpreadsheetSegments(ctx context.Context, path string, maxBytes int64) ([]contentSegment, error) {
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	files := make(map[string]*zip.File, len(reader.File))
+	for _, file := range reader.File {
+		files[strings.TrimPrefix(file.Name, "/")] = file
+	}
+
+	sharedStrings, err := readSpreadsheetSharedStrings(files["xl/sharedStrings.xml"])
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := maxBytes
+	segments := make([]contentSegment, 0)
+	for _, sheet := range listSpreadsheetSheets(files) {
+		if remaining <= 0 {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		builder := newContentTextBuilder(remaining)
+		if err := appendSpreadsheetSheetText(sheet.file, sharedStrings, builder); err != nil {
+			return nil, err
+		}
+		text := builder.String()
+		remaining -= int64(len(text)) + 1
+		if text != "" {
+			segments = append(segments, contentSegment{text: text, sheet: sheet.name})
+		}
+	}
+	return segments, nil
+}
+
+type spreadsheetSheetPart struct {
+	name string
+	file *zip.File
+}
+
+// listSpreadsheetSheets resolves workbook tabs to worksheet parts. Workbooks
+// without readable metadata fall back to the worksheet part names.
+func listSpreadsheetSheets(files map[string]*zip.File) []spreadsheetSheetPart {
+	var workbook openXMLWorkbook
+	var relationships openXMLRelationships
+	workbookErr := decodeOpenXMLPart(files["xl/workbook.xml"], &workbook)
+	relationshipsErr := decodeOpenXMLPart(files["xl/_rels/workbook.xml.rels"], &relationships)
+	if workbookErr == nil && relationshipsErr == nil && len(workbook.Sheets) > 0 {
+		targets := make(map[string]string, len(relationships.Relationships))
+		for _, relationship := range relationships.Relationships {
+			target := relationship.Target
+			if strings.HasPrefix(target, "/") {
+				target = strings.TrimPrefix(target, "/")
+			} else {
+				target = path.Join("xl", target)
+			}
+			targets[relationship.ID] = target
+		}
+
+		sheets := make([]spreadsheetSheetPart, 0, len(workbook.Sheets))
+		for _, sheet := range workbook.Sheets {
+			if file := files[targets[sheet.RelID]]; file != nil {
+				sheets = append(sheets, spreadsheetSheetPart{name: sheet.Name, file: file})
+			}
+		}
+		if len(sheets) > 0 {
+			return sheets
+		}
+	}
+
+	sheets := make([]spreadsheetSheetPart, 0)
+	for name, file := range files {
+		if strings.HasPrefix(name, "xl/worksheets/sheet") && strings.HasSuffix(name, ".xml") {
+			sheets = append(sheets, spreadsheetSheetPart{name: strings.TrimSuffix(path.Base(name), ".xml"), file: file})
+		}
+	}
+	sort.Slice(sheets, func(i, j int) bool { return sheets[i].name < sheets[j].name })
+	return sheets
+}
+
+func decodeOpenXMLPart(file *zip.File, target any) error {
+	if file == nil {
+		return os.ErrNotExist
+	}
+	readCloser, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer readCloser.Close()
+	return xml.NewDecoder(io.LimitReader(readCloser, openXMLMaxEntryReadBytes)).Decode(target)
+}
+
+// readSpreadsheetSharedStrings returns the shared string table in index order.
+// Rich-text runs inside one <si> are concatenated; phonetic hints are skipped.
+func readSpreadsheetSharedStrings(file *zip.File) ([]string, error) {
+	if file == nil {
+		return nil, nil
+	}
+	readCloser, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer readCloser.Close()
+
+	var sharedStrings []string
+	var current strings.Builder
+	inText := false
+	inPhonetic := false
+	decoder := xml.NewDecoder(io.LimitReader(readCloser, openXMLMaxEntryReadBytes))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return sharedStrings, nil
+		}
+		if err != nil {
+			if isXMLUnexpectedEOF(err) {
+				return sharedStrings, nil
+			}
+			return nil, fmt.Errorf("parse %s: %w", file.Name, err)
+		}
+		switch element := token.(type) {
+		case xml.StartElement:
+			switch element.Name.Local {
+			case "si":
+				current.Reset()
+			case "t":
+				inText = true
+			case "rPh":
+				inPhonetic = true
+			}
+		case xml.EndElement:
+			switch element.Name.Local {
+			case "si":
+				sharedStrings = append(sharedStrings, current.String())
+			case "t":
+				inText = false
+			case "rPh":
+				inPhonetic = false
+			}
+		case xml.CharData:
+			if inText && !inPhonetic {
+				current.Write(element)
+			}
+		}
+	}
+}
+
+// appendSpreadsheetSheetText appends cell values from one worksheet part.
+func appendSpreadsheetSheetText(file *zip.File, sharedStrings []string, builder *contentTextBuilder) error {
+	readCloser, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer readCloser.Close()
+
+	cellType := ""
+	inValue := false
+	inInlineText := false
+	decoder := xml.NewDecoder(io.LimitReader(readCloser, openXMLMaxEntryReadBytes))
+	for !builder.Done() {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if isXMLUnexpectedEOF(err) {
+				return nil
+			}
+			return fmt.Errorf("parse %s: %w", file.Name, err)
+		}
+		switch element := token.(type) {
+		case xml.StartElement:
+			switch element.Name.Local {
+			case "c":
+				cellType = ""
+				for _, attr := range element.Attr {
+					if attr.Name.Local == "t" {
+						cellType = attr.Value
+					}
+				}
+			case "v":
+				inValue = true
+			case "t":
+				inInlineText = true
+			}
+		case xml.EndElement:
+			switch element.Name.Local {
+			case "v":
+				inValue = false
+			case "t":
+				inInlineText = false
+			}
+		case xml.CharData:
+			text := string(element)
+			if inValue && cellType == "s" {
+				index, err := strconv.Atoi(strings.TrimSpace(text))
+				if err != nil || index < 0 || index >= len(sharedStrings) {
+					continue
+				}
+				text = sharedStrings[index]
+			} else if !inValue && !inInlineText {
+				continue
+			}
+			builder.AppendText(text)
+			builder.AppendSeparator()
+		}
+	}
+	return nil
+}
+
+// contentSnippetTerms lowercases quoted phrases and content tokens from the
+// query. Longer terms come first so "report" wins over "re" when both match.
+func contentSnippetTerms(query string) []string {
+	parsed := parseQuotedSearchQuery(query)
+	candidates := make([]string, 0, len(parsed.phrases)+4)
+	for _, phrase := range parsed.phrases {
+		candidates = append(candidates, lowerContentSnippetTerm(strings.Join(strings.Fields(phrase), " ")))
+		candidates = append(candidates, strings.Fields(TokenizeForContentIndex(phrase))...)
+	}
+	candidates = append(candidates, strings.Fields(TokenizeForContentIndex(parsed.unquoted))...)
+
+	seen := make(map[string]bool, len(candidates))
+	terms := make([]string, 0, len(candidates))
+	for _, term := range candidates {
+		if term == "" || seen[term] {
+			continue
+		}
+		seen[term] = true
+		terms = append(terms, term)
+	}
+	sort.SliceStable(terms, func(i, j int) bool { return len(terms[i]) > len(terms[j]) })
+	return terms
+}
+
+// buildContentSnippets returns at most contentSnippetMaxCount excerpts across
+// all segments, in document order.
+func buildContentSnippets(segments []contentSegment, terms []string) []ContentSnippet {
+	snippets := make([]ContentSnippet, 0, contentSnippetMaxCount)
+	for _, segment := range segments {
+		remaining := contentSnippetMaxCount - len(snippets)
+		if remaining <= 0 {
+			break
+		}
+		if segment.lines {
+			snippets = append(snippets, buildLineContentSnippets(segment.text, terms, remaining)...)
+			continue
+		}
+		for _, snippet := range buildPassageContentSnippets(segment.text, terms, remaining) {
+			snippet.Page = segment.page
+			snippet.Sheet = segment.sheet
+			snippets = append(snippets, snippet)
+		}
+	}
+	if len(snippets) == 0 {
+		return nil
+	}
+	return snippets
+}
+
+// buildLineContentSnippets keeps each matched line with its neighbours.
+// Matches that fall inside an earlier snippet's context are not repeated.
+func buildLineContentSnippets(text string, terms []string, limit int) []ContentSnippet {
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	for index, line := range lines {
+		lines[index] = strings.TrimRight(line, "\r")
+	}
+
+	var snippets []ContentSnippet
+	lastIncluded := -1
+	for index, line := range lines {
+		if len(snippets) >= limit {
+			break
+		}
+		if index <= lastIncluded || len(findContentSnippetMatches(line, terms)) == 0 {
+			continue
+		}
+
+		first := max(index-contentSnippetContextLines, lastIncluded+1)
+		last := min(index+contentSnippetContextLines, len(lines)-1)
+		parts := make([]string, 0, last-first+1)
+		for lineIndex := first; lineIndex <= last; lineIndex++ {
+			parts = append(parts, windowContentSnippetText(lines[lineIndex], findContentSnippetMatches(lines[lineIndex], terms)))
+		}
+		snippetText := strings.Join(parts, "\n")
+		snippets = append(snippets, ContentSnippet{
+			Text:    snippetText,
+			Line:    index + 1,
+			Matches: findContentSnippetMatches(snippetText, terms),
+		})
+		lastIncluded = last
+	}
+	return snippets
+}
+
+// buildPassageContentSnippets windows whitespace-collapsed document text around
+// each match that is not already visible in the previous window.
+func buildPassageContentSnippets(text string, terms []string, limit int) []ContentSnippet {
+	var snippets []ContentSnippet
+	windowEnd := -1
+	for _, match := range findContentSnippetMatches(text, terms) {
+		if len(snippets) >= limit {
+			break
+		}
+		if match.Start < windowEnd {
+			continue
+		}
+		start, end := contentSnippetWindow(text, match)
+		snippetText := decorateContentSnippetWindow(text, start, end)
+		snippets = append(snippets, ContentSnippet{
+			Text:    snippetText,
+			Matches: findContentSnippetMatches(snippetText, terms),
+		})
+		windowEnd = end
+	}
+	return snippets
+}
+
+// windowContentSnippetText shortens a long line around its first match, or
+// from the start when the line only provides context.
+func windowContentSnippetText(text string, matches []ContentSnippetMatch) string {
+	if len(text) <= contentSnippetMaxLineBytes {
+		return text
+	}
+	anchor := ContentSnippetMatch{}
+	if len(matches) > 0 {
+		anchor = matches[0]
+	}
+	start, end := contentSnippetWindow(text, anchor)
+	return decorateContentSnippetWindow(text, start, end)
+}
+
+// contentSnippetWindow returns a rune-aligned byte window that starts a little
+// before the match and spans at most contentSnippetMaxLineBytes.
+func contentSnippetWindow(text string, match ContentSnippetMatch) (int, int) {
+	start := max(match.Start-contentSnippetLeadBytes, 0)
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	end := min(start+contentSnippetMaxLineBytes, len(text))
+	if end < match.End {
+		end = match.End
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	return start, end
+}
+
+func decorateContentSnippetWindow(text string, start int, end int) string {
+	window := strings.TrimSpace(text[start:end])
+	if start > 0 {
+		window = contentSnippetEllipsis + window
+	}
+	if end < len(text) {
+		window += contentSnippetEllipsis
+	}
+	return window
+}
+
+// findContentSnippetMatches returns non-overlapping, case-insensitive term
+// matches as byte ranges in the original text. Earlier matches win, and at the
+// same position the longer term wins.
+func findContentSnippetMatches(text string, terms []string) []ContentSnippetMatch {
+	if text == "" || len(terms) == 0 {
+		return nil
+	}
+	lowered, offsets := lowerContentSnippetText(text)
+
+	var candidates []ContentSnippetMatch
+	for _, term := range terms {
+		for from := 0; from < len(lowered); {
+			index := strings.Index(lowered[from:], term)
+			if index < 0 {
+				break
+			}
+			start := from + index
+			end := start + len(term)
+			candidates = append(candidates, ContentSnippetMatch{Start: offsets[start], End: offsets[end]})
+			from = end
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Start != candidates[j].Start {
+			return candidates[i].Start < candidates[j].Start
+		}
+		return candidates[i].End > candidates[j].End
+	})
+
+	var matches []ContentSnippetMatch
+	for _, candidate := range candidates {
+		if len(matches) > 0 && candidate.Start < matches[len(matches)-1].End {
+			continue
+		}
+		matches = append(matches, candidate)
+	}
+	return matches
+}
+
+// lowerContentSnippetText lowercases text rune by rune and records, for every
+// byte of the lowered string, the byte offset of the source rune. Lowercasing
+// can change a rune's encoded length, so offsets cannot be reused directly.
+func lowerContentSnippetText(text string) (string, []int) {
+	var lowered strings.Builder
+	lowered.Grow(len(text))
+	offsets := make([]int, 0, len(text)+1)
+	for offset, r := range text {
+		before := lowered.Len()
+		lowered.WriteRune(unicode.ToLower(r))
+		for range lowered.Len() - before {
+			offsets = append(offsets, offset)
+		}
+	}
+	offsets = append(offsets, len(text))
+	return lowered.String(), offsets
+}
+
+func lowerContentSnippetTerm(term string) string {
+	lowered, _ := lowerContentSnippetText(term)
+	return lowered
+}
@@ -0,0 +1,136 @@
+package filesearch
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContentSnippetsKeepMatchedLinesWithContext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	content := "alpha\nbeta\nthe Quarterly Report is ready\ngamma\ndelta\nepsilon\nreport archived\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	snippets, err := loadContentSnippets(context.Background(), path, contentSnippetTerms(`"quarterly report"`), ContentDefaultMaxReadBytes)
+	if err != nil {
+		t.Fatalf("loadContentSnippets: %v", err)
+	}
+	if len(snippets) != 2 {
+		t.Fatalf("expected two snippets, got %#v", snippets)
+	}
+
+	first := snippets[0]
+	if first.Line != 3 || first.Text != "beta\nthe Quarterly Report is ready\ngamma" {
+		t.Fatalf("expected matched line with one line of context, got line=%d text=%q", first.Line, first.Text)
+	}
+	if len(first.Matches) != 1 || first.Text[first.Matches[0].Start:first.Matches[0].End] != "Quarterly Report" {
+		t.Fatalf("expected phrase highlight to win over single tokens, got %#v", first.Matches)
+	}
+
+	second := snippets[1]
+	if second.Line != 7 || second.Text != "epsilon\nreport archived" {
+		t.Fatalf("expected second snippet at line 7, got line=%d text=%q", second.Line, second.Text)
+	}
+}
+
+func TestContentSnippetMatchesMapLoweredOffsetsBackToSource(t *testing.T) {
+	text := "İstanbul ÖZET raporu"
+	matches := findContentSnippetMatches(text, contentSnippetTerms("özet"))
+	if len(matches) != 1 || text[matches[0].Start:matches[0].End] != "ÖZET" {
+		t.Fatalf("expected case-insensitive match on the original bytes, got %#v", matches)
+	}
+}
+
+func TestContentSnippetsWindowLongPassages(t *testing.T) {
+	text := strings.Repeat("filler ", 100) + "needle" + strings.Repeat(" filler", 100)
+	snippets := buildContentSnippets([]contentSegment{{text: text, page: 4}}, contentSnippetTerms("needle"))
+	if len(snippets) != 1 {
+		t.Fatalf("expected one passage snippet, got %#v", snippets)
+	}
+	snippet := snippets[0]
+	if snippet.Page != 4 || snippet.Line != 0 {
+		t.Fatalf("expected page hint without line, got %#v", snippet)
+	}
+	if !strings.HasPrefix(snippet.Text, contentSnippetEllipsis) || !strings.HasSuffix(snippet.Text, contentSnippetEllipsis) {
+		t.Fatalf("expected windowed passage with ellipses, got %q", snippet.Text)
+	}
+	if len(snippet.Text) > contentSnippetMaxLineBytes+2*len(contentSnippetEllipsis) {
+		t.Fatalf("expected passage to stay within the window, got %d bytes", len(snippet.Text))
+	}
+	if len(snippet.Matches) != 1 || snippet.Text[snippet.Matches[0].Start:snippet.Matches[0].End] != "needle" {
+		t.Fatalf("expected match offsets inside the windowed text, got %#v", snippet.Matches)
+	}
+}
+
+func TestContentSnippetsResolveSpreadsheetSheetNames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "budget.xlsx")
+	writeTestZip(t, path, map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Summary" sheetId="1" r:id="rId1"/><sheet name="Travel" sheetId="2" r:id="rId2"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Target="/xl/worksheets/sheet2.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":     `<sst><si><t>Total</t></si><si><r><t>Hotel </t></r><r><t>invoice</t></r><rPh><t>ignored</t></rPh></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData><row><c t="s"><v>0</v></c><c><v>42</v></c></row></sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<worksheet><sheetData><row><c t="s"><v>1</v></c><c t="inlineStr"><is><t>Berlin</t></is></c></row></sheetData></worksheet>`,
+	})
+
+	snippets, err := loadContentSnippets(context.Background(), path, contentSnippetTerms("invoice"), ContentDefaultMaxReadBytes)
+	if err != nil {
+		t.Fatalf("loadContentSnippets: %v", err)
+	}
+	if len(snippets) != 1 || snippets[0].Sheet != "Travel" || snippets[0].Text != "Hotel invoice Berlin" {
+		t.Fatalf("expected hit on the Travel sheet, got %#v", snippets)
+	}
+}
+
+func writeTestZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create zip: %v", err)
+	}
+	defer f.Close()
+	writer := zip.NewWriter(f)
+	for name, content := range files {
+		entry, err := writer.Create(name)
+		if err != nil {
+			t.Fatalf("create zip entry %s: %v", name, err)
+		}
+		if _, err := entry.Write([]byte(content)); err != nil {
+			t.Fatalf("write zip entry %s: %v", name, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+}
+
+func TestAttachContentSnippetsGivesEachFileItsOwnTimeout(t *testing.T) {
+	extractor := NewContentExtractor("test-slow", []string{"slow"}, ContentExtractorLimits{}, func(ctx context.Context, path string, maxBytes int64) (string, error) {
+		time.Sleep(time.Second)
+		return "needle", nil
+	})
+	if err := RegisterContentExtractor(extractor); err != nil {
+		t.Fatalf("RegisterContentExtractor: %v", err)
+	}
+	t.Cleanup(func() { UnregisterContentExtractor("test-slow") })
+
+	results := []ContentSearchResult{
+		{Path: writeTestContentFile(t, "report.slow", "needle")},
+		{Path: writeTestContentFile(t, "notes.txt", "the needle is here")},
+	}
+	startedAt := time.Now()
+	attachContentSnippets(context.Background(), results, "needle", ContentDefaultMaxReadBytes)
+	if elapsed := time.Since(startedAt); elapsed > contentSnippetTimeBudget+100*time.Millisecond {
+		t.Fatalf("snippets took %s, want the query budget to hold", elapsed)
+	}
+	if len(results[0].Snippets) != 0 || len(results[1].Snippets) != 1 {
+		t.Fatalf("snippets = %+v, want only the fast file to have one", results)
+	}
+}
@@ -197,6 +197,7 @@ func ContentDefaultExtensions() []string {
 	return []string{
 		"txt", "md", "json", "yaml", "yml", "xml", "csv", "tsv",
 		"docx", "pptx", "xlsx", "pdf",
+		"odt", "ods", "odp", "epub", "rtf", "eml", "mbox", "ipynb", "htm", "xhtml",
 		"go", "py", "js", "ts", "tsx", "jsx", "rs", "c", "cpp", "h", "hpp",
 		"java", "rb", "php", "sh", "bat", "ps1",
 		"toml", "ini", "cfg", "conf",
@@ -206,6 +207,16 @@ func ContentDefaultExtensions() []string {
 	}
 }
 
+// contentArchiveExtensions are handled by the source-archive extractor.
+var contentArchiveExtensions = []string{"zip", "tar", "tgz", "gz"}
+
+// ContentOptInExtensions lists formats Wox can extract but does not index
+// unless the user adds them to the extension list. Archives are unpacked on
+// every crawl, which is too costly for a default.
+func ContentOptInExtensions() []string {
+	return append([]string(nil), contentArchiveExtensions...)
+}
+
 // ContentExtensionsFromList builds a set from a list of extension strings
 // (without leading dot, case-insensitive).
 func ContentExtensionsFromList(exts []string) map[string]bool {
@@ -25,6 +25,9 @@ type Engine struct {
 	policy          *policyState
 	statusListeners *util.HashMap[string, func(StatusSnapshot)]
 	contentHook     ContentHook
+	// contentMaxReadBytes remembers the extraction cap used by the content
+	// crawler and hook so snippets are built from the same indexed prefix.
+	contentMaxReadBytes int64
 }
 
 func NewEngine(ctx context.Context) (*Engine, error) {
@@ -937,6 +940,7 @@ func (e *Engine) SearchContent(ctx context.Context, query string, limit int) ([]
 		return nil, nil
 	}
 	contentDB := e.contentDB
+	maxReadBytes := e.contentMaxReadBytes
 	e.mu.RUnlock()
 
 	// Skip content search if crawl is not complete.
@@ -945,7 +949,51 @@ func (e *Engine) SearchContent(ctx context.Context, query string, limit int) ([]
 		return nil, nil
 	}
 
-	return contentDB.SearchContent(ctx, query, limit)
+	results, err := contentDB.SearchContent(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	if maxReadBytes <= 0 {
+		maxReadBytes = ContentDefaultMaxReadBytes
+	}
+	attachContentSnippets(ctx, results, query, maxReadBytes)
+	return results, nil
+}
+
+// ListContentHashes returns the content hash of every file in a complete
+// content index, or ErrContentIndexNotReady while it is missing or crawling.
+func (e *Engine) ListContentHashes(ctx context.Context) (map[string]uint32, error) {
+	if e == nil {
+		return nil, ErrContentIndexNotReady
+	}
+	e.mu.RLock()
+	if e.closed || e.contentDB == nil {
+		e.mu.RUnlock()
+		return nil, ErrContentIndexNotReady
+	}
+	contentDB := e.contentDB
+	e.mu.RUnlock()
+
+	crawlState, _ := contentDB.GetContentCrawlState(ctx)
+	if crawlState != "complete" {
+		return nil, ErrContentIndexNotReady
+	}
+	return contentDB.ListContentEntryHashes(ctx)
+}
+
+// ExtractContentText reads the indexable text of a file with the same
+// extractors and read limit as the content index.
+func (e *Engine) ExtractContentText(ctx context.Context, path string) (string, error) {
+	if e == nil {
+		return "", nil
+	}
+	e.mu.RLock()
+	maxReadBytes := e.contentMaxReadBytes
+	e.mu.RUnlock()
+	if maxReadBytes <= 0 {
+		maxReadBytes = ContentDefaultMaxReadBytes
+	}
+	return extractContentText(ctx, path, maxReadBytes)
 }
 
 // ContentStats returns statistics about the content index.
@@ -1014,6 +1062,9 @@ func (e *Engine) StartContentCrawl(ctx context.Context, roots []RootRecord, poli
 		return done
 	}
 	contentDB, err := e.ensureContentDBLocked(ctx)
+	if err == nil {
+		e.contentMaxReadBytes = maxReadBytes
+	}
 	e.mu.Unlock()
 	if err != nil {
 		util.GetLogger().Error(ctx, fmt.Sprintf("open content search database for crawl failed: %v", err))
@@ -1075,6 +1126,7 @@ func (e *Engine) StartContentHook(ctx context.Context, extensions map[string]boo
 
 	hook := NewContentIndexHook(contentDB, e.db, extensions, maxReadBytes)
 	e.contentHook = hook
+	e.contentMaxReadBytes = maxReadBytes
 
 	scanner := e.scanner
 	if scanner != nil {
@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"wox/util"
 )
@@ -27,7 +28,27 @@ func normalizeQuery(raw string) string {
 
 func normalizeSearchQuery(query SearchQuery) SearchQuery {
 	query.Raw = normalizeQuery(query.Raw)
-	query.wildcard = buildWildcardQuery(parseQuotedSearchQuery(query.Raw).unquoted)
+	structured := parseStructuredSearchQuery(query.Raw, time.Now())
+	if len(structured.groups) > 1 {
+		query.alternatives = make([]SearchQuery, 0, len(structured.groups))
+		for _, group := range structured.groups {
+			query.alternatives = append(query.alternatives, normalizeSearchQueryGroup(query, group))
+		}
+		return query
+	}
+
+	var group searchQueryGroup
+	if len(structured.groups) == 1 {
+		group = structured.groups[0]
+	}
+	return normalizeSearchQueryGroup(query, group)
+}
+
+func normalizeSearchQueryGroup(query SearchQuery, group searchQueryGroup) SearchQuery {
+	query.text = normalizeQuery(group.text)
+	query.filters = group.filters
+	query.alternatives = nil
+	query.wildcard = buildWildcardQuery(parseQuotedSearchQuery(query.text).unquoted)
 	query.plan = buildQueryPlan(query)
 	return query
 }
@@ -89,7 +110,7 @@ func buildSearchTerms(name string, path string, pinyinFull string, pinyinInitial
 }
 
 func matchSearchQuery(query SearchQuery, name string, path string, pinyinFull string, pinyinInitials string) (bool, int64) {
-	if query.Raw == "" {
+	if query.text == "" {
 		return false, 0
 	}
 	if query.plan != nil && !recordMatchesExactPhrases(query.plan, docRecord{Path: path}) {
@@ -102,7 +123,7 @@ func matchSearchQuery(query SearchQuery, name string, path string, pinyinFull st
 		return scorePathMatch(path, query.plan.pathQuery)
 	}
 	usePinyin := !query.DisablePinyin
-	return scoreSearchTerms(query.Raw, buildSearchTerms(name, path, pinyinFull, pinyinInitials, usePinyin), usePinyin)
+	return scoreSearchTerms(query.text, buildSearchTerms(name, path, pinyinFull, pinyinInitials, usePinyin), usePinyin)
 }
 
 func scoreSearchTerms(query string, terms []string, usePinyin bool) (bool, int64) {
@@ -35,6 +35,41 @@ func (p *SQLiteSearchProvider) Search(ctx context.Context, query SearchQuery, li
 	if p == nil || p.db == nil || strings.TrimSpace(query.Raw) == "" {
 		return nil, nil
 	}
+	if len(query.alternatives) > 0 {
+		return p.searchAlternatives(ctx, query.alternatives, limit)
+	}
+	return p.searchNormalized(ctx, query, limit)
+}
+
+// searchAlternatives runs each OR group through the regular recall path and
+// keeps the best score per path. Groups are recalled independently because
+// their text terms and filters usually pick different SQLite access paths.
+func (p *SQLiteSearchProvider) searchAlternatives(ctx context.Context, alternatives []SearchQuery, limit int) ([]SearchResult, error) {
+	bestByPath := map[string]SearchResult{}
+	for _, alternative := range alternatives {
+		results, err := p.searchNormalized(ctx, alternative, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range results {
+			if existing, ok := bestByPath[result.Path]; ok && existing.Score >= result.Score {
+				continue
+			}
+			bestByPath[result.Path] = result
+		}
+	}
+
+	merged := make([]SearchResult, 0, len(bestByPath))
+	for _, result := range bestByPath {
+		merged = append(merged, result)
+	}
+	return sortAndLimitResults(merged, limit), nil
+}
+
+func (p *SQLiteSearchProvider) searchNormalized(ctx context.Context, query SearchQuery, limit int) ([]SearchResult, error) {
+	if query.plan == nil {
+		return nil, nil
+	}
 
 	candidateLimit := defaultPreRerankLimit
 	if query.plan != nil && query.plan.preRerankLimit > 0 {
@@ -89,6 +124,9 @@ func (p *SQLiteSearchProvider) collectCandidateIDs(ctx context.Context, query Se
 	}
 
 	plan := query.plan
+	if plan.filterOnly {
+		return p.queryFilterOnlyIDs(ctx, plan.recallFilter, limit)
+	}
 	if len(plan.exactPhrases) > 0 {
 		exactIDs, err := p.collectExactPhraseCandidateIDs(ctx, plan, limit)
 		if err != nil {
@@ -113,7 +151,7 @@ func (p *SQLiteSearchProvider) collectUnquotedCandidateIDs(ctx context.Context,
 		return nil, nil
 	}
 	if plan.extensionOnly {
-		return p.queryIDsByExtension(ctx, plan.extension, limit)
+		return p.queryIDsByExtension(ctx, plan.recallFilter, plan.extension, limit)
 	}
 
 	if query.wildcard != nil {
@@ -142,9 +180,9 @@ func (p *SQLiteSearchProvider) collectExactPhraseCandidateIDs(ctx context.Contex
 			var nameIDs []int64
 			var err error
 			if utf8LenString(namePhrase) >= 3 {
-				nameIDs, err = p.queryFTSLiteralContainsIDs(ctx, "entries_name_fts", "normalized_name", namePhrase, limit)
+				nameIDs, err = p.queryFTSLiteralContainsIDs(ctx, plan.recallFilter, "entries_name_fts", "normalized_name", namePhrase, limit)
 			} else {
-				nameIDs, err = p.queryNameFallbackIDs(ctx, namePhrase, limit)
+				nameIDs, err = p.queryNameFallbackIDs(ctx, plan.recallFilter, namePhrase, limit)
 			}
 			if err != nil {
 				return nil, err
@@ -154,7 +192,7 @@ func (p *SQLiteSearchProvider) collectExactPhraseCandidateIDs(ctx context.Contex
 
 		pathPhrase := plan.exactPathPhrases[i]
 		if pathPhrase != "" {
-			pathIDs, err := p.queryPathFallbackIDs(ctx, pathPhrase, limit)
+			pathIDs, err := p.queryPathFallbackIDs(ctx, plan.recallFilter, pathPhrase, limit)
 			if err != nil {
 				return nil, err
 			}
@@ -171,14 +209,7 @@ func (p *SQLiteSearchProvider) collectOneCharacterCandidateIDs(ctx context.Conte
 		return nil, nil
 	}
 
-	prefix := plan.rawLettersDigits
-	return p.queryIDs(ctx, `
-		SELECT entry_id
-		FROM entries
-		WHERE name_key >= ? AND name_key < ?
-		ORDER BY name_key ASC, entry_id ASC
-		LIMIT ?
-	`, prefix, nextPrefixUpperBound(prefix), limit)
+	return p.queryNameKeyPrefixIDs(ctx, plan.recallFilter, plan.rawLettersDigits, limit)
 }
 
 func (p *SQLiteSearchProvider) collectTwoCharacterCandidateIDs(ctx context.Context, query SearchQuery, limit int) ([]int64, error) {
@@ -188,7 +219,7 @@ func (p *SQLiteSearchProvider) collectTwoCharacterCandidateIDs(ctx context.Conte
 	}
 
 	if plan.pathLike {
-		return p.queryPathFallbackIDs(ctx, plan.pathQuery, limit)
+		return p.queryPathFallbackIDs(ctx, plan.recallFilter, plan.pathQuery, limit)
 	}
 
 	if !plan.asciiLettersDigits || len(plan.rawLettersDigits) != 2 {
@@ -199,14 +230,7 @@ func (p *SQLiteSearchProvider) collectTwoCharacterCandidateIDs(ctx context.Conte
 	// indexer to maintain the expensive bigram side table. Tightening short
 	// queries to the same indexed name-key prefix path keeps response time fast
 	// while making the reduced recall explicit and predictable.
-	prefix := plan.rawLettersDigits
-	return p.queryIDs(ctx, `
-		SELECT entry_id
-		FROM entries
-		WHERE name_key >= ? AND name_key < ?
-		ORDER BY name_key ASC, entry_id ASC
-		LIMIT ?
-	`, prefix, nextPrefixUpperBound(prefix), limit)
+	return p.queryNameKeyPrefixIDs(ctx, plan.recallFilter, plan.rawLettersDigits, limit)
 }
 
 func (p *SQLiteSearchProvider) collectGeneralCandidateIDs(ctx context.Context, query SearchQuery, limit int) ([]int64, error) {
@@ -220,7 +244,7 @@ func (p *SQLiteSearchProvider) collectGeneralCandidateIDs(ctx context.Context, q
 	}
 
 	ids := make([]int64, 0, limit)
-	nameIDs, err := p.queryFTSLiteralContainsIDs(ctx, "entries_name_fts", "normalized_name", plan.nameTerm, limit)
+	nameIDs, err := p.queryFTSLiteralContainsIDs(ctx, plan.recallFilter, "entries_name_fts", "normalized_name", plan.nameTerm, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -232,7 +256,7 @@ func (p *SQLiteSearchProvider) collectGeneralCandidateIDs(ctx context.Context, q
 		// normal code trees. name_key is the cheap punctuation-insensitive recall
 		// path for queries like "maingo" against "main.go" without reintroducing
 		// the old ASCII pinyin payload.
-		nameKeyIDs, err := p.queryNameKeyPrefixIDs(ctx, plan.rawLettersDigits, limit)
+		nameKeyIDs, err := p.queryNameKeyPrefixIDs(ctx, plan.recallFilter, plan.rawLettersDigits, limit)
 		if err != nil {
 			return nil, err
 		}
@@ -244,13 +268,13 @@ func (p *SQLiteSearchProvider) collectGeneralCandidateIDs(ctx context.Context, q
 	// pinyin FTS tables. Otherwise disabling pinyin only affected non-file
 	// result filtering while filesearch still recalled pinyin-derived matches.
 	if plan.usePinyin && plan.asciiLettersDigits && len(plan.rawLettersDigits) >= 3 {
-		pinyinFullIDs, err := p.queryFTSLiteralContainsIDs(ctx, "entries_pinyin_full_fts", "pinyin_full", plan.rawLettersDigits, limit)
+		pinyinFullIDs, err := p.queryFTSLiteralContainsIDs(ctx, plan.recallFilter, "entries_pinyin_full_fts", "pinyin_full", plan.rawLettersDigits, limit)
 		if err != nil {
 			return nil, err
 		}
 		ids = append(ids, pinyinFullIDs...)
 
-		initialsIDs, err := p.queryFTSMatchIDs(ctx, "entries_initials_fts", plan.rawLettersDigits+"*", limit)
+		initialsIDs, err := p.queryFTSMatchIDs(ctx, plan.recallFilter, "entries_initials_fts", plan.rawLettersDigits+"*", limit)
 		if err != nil {
 			return nil, err
 		}
@@ -258,7 +282,7 @@ func (p *SQLiteSearchProvider) collectGeneralCandidateIDs(ctx context.Context, q
 	}
 
 	if plan.extension != "" {
-		extensionIDs, err := p.queryIDsByExtension(ctx, plan.extension, limit)
+		extensionIDs, err := p.queryIDsByExtension(ctx, plan.recallFilter, plan.extension, limit)
 		if err != nil {
 			return nil, err
 		}
@@ -268,18 +292,17 @@ func (p *SQLiteSearchProvider) collectGeneralCandidateIDs(ctx context.Context, q
 	return trimCandidateIDs(ids, limit), nil
 }
 
-func (p *SQLiteSearchProvider) queryNameKeyPrefixIDs(ctx context.Context, prefix string, limit int) ([]int64, error) {
+func (p *SQLiteSearchProvider) queryNameKeyPrefixIDs(ctx context.Context, filter sqliteRecallFilter, prefix string, limit int) ([]int64, error) {
 	prefix = strings.TrimSpace(prefix)
 	if prefix == "" {
 		return nil, nil
 	}
-	return p.queryIDs(ctx, `
+	return p.queryRecallIDs(ctx, filter, `
 		SELECT entry_id
 		FROM entries
 		WHERE name_key >= ? AND name_key < ?
 		ORDER BY name_key ASC, entry_id ASC
-		LIMIT ?
-	`, prefix, nextPrefixUpperBound(prefix), limit)
+	`, limit, prefix, nextPrefixUpperBound(prefix))
 }
 
 func (p *SQLiteSearchProvider) collectWildcardCandidateIDs(ctx context.Context, query SearchQuery, limit int) ([]int64, error) {
@@ -299,18 +322,18 @@ func (p *SQLiteSearchProvider) collectWildcardCandidateIDs(ctx context.Context,
 
 	if utf8LenString(literal) < 3 {
 		if plan.pathLike {
-			return p.queryPathWildcardFallbackIDs(ctx, wildcardRecallLikePattern(plan.pathQuery), limit)
+			return p.queryPathWildcardFallbackIDs(ctx, plan.recallFilter, wildcardRecallLikePattern(plan.pathQuery), limit)
 		}
 		if plan.extension != "" {
-			return p.queryIDsByExtension(ctx, plan.extension, limit)
+			return p.queryIDsByExtension(ctx, plan.recallFilter, plan.extension, limit)
 		}
-		return p.queryNameWildcardFallbackIDs(ctx, wildcardRecallLikePattern(plan.rawLower), limit)
+		return p.queryNameWildcardFallbackIDs(ctx, plan.recallFilter, wildcardRecallLikePattern(plan.rawLower), limit)
 	}
 
 	if plan.pathLike {
-		return p.queryDirectoryPathFTSLiteralContainsIDs(ctx, literal, limit)
+		return p.queryDirectoryPathFTSLiteralContainsIDs(ctx, plan.recallFilter, literal, limit)
 	}
-	return p.queryFTSLiteralContainsIDs(ctx, targetTable, targetColumn, literal, limit)
+	return p.queryFTSLiteralContainsIDs(ctx, plan.recallFilter, targetTable, targetColumn, literal, limit)
 }
 
 func (p *SQLiteSearchProvider) queryPathFTSIDs(ctx context.Context, plan *queryPlan, limit int) ([]int64, error) {
@@ -335,16 +358,16 @@ func (p *SQLiteSearchProvider) queryPathFTSIDs(ctx context.Context, plan *queryP
 		var ids []int64
 		var err error
 		if utf8LenString(segment) >= 3 {
-			ids, err = p.queryDirectoryPathFTSLiteralContainsIDs(ctx, segment, plan.perClauseLimit)
+			ids, err = p.queryDirectoryPathFTSLiteralContainsIDs(ctx, plan.recallFilter, segment, plan.perClauseLimit)
 			if err == nil && index == len(segments)-1 {
-				nameIDs, nameErr := p.queryFTSLiteralContainsIDs(ctx, "entries_name_fts", "normalized_name", segment, plan.perClauseLimit)
+				nameIDs, nameErr := p.queryFTSLiteralContainsIDs(ctx, plan.recallFilter, "entries_name_fts", "normalized_name", segment, plan.perClauseLimit)
 				if nameErr != nil {
 					return nil, nameErr
 				}
 				ids = append(ids, nameIDs...)
 			}
 		} else {
-			ids, err = p.queryPathFallbackIDs(ctx, segment, plan.perClauseLimit)
+			ids, err = p.queryPathFallbackIDs(ctx, plan.recallFilter, segment, plan.perClauseLimit)
 		}
 		if err != nil {
 			return nil, err
@@ -364,7 +387,7 @@ func (p *SQLiteSearchProvider) queryPathFTSIDs(ctx context.Context, plan *queryP
 	}
 
 	if len(plan.pathQuery) >= 3 {
-		fullPathIDs, err := p.queryDirectoryPathFTSLiteralContainsIDs(ctx, plan.pathQuery, limit)
+		fullPathIDs, err := p.queryDirectoryPathFTSLiteralContainsIDs(ctx, plan.recallFilter, plan.pathQuery, limit)
 		if err != nil {
 			return nil, err
 		}
@@ -376,18 +399,21 @@ func (p *SQLiteSearchProvider) queryPathFTSIDs(ctx context.Context, plan *queryP
 
 // queryDirectoryPathFTSLiteralContainsIDs recalls entries by matching directory
 // paths, then expanding each matched directory to its subtree.
-func (p *SQLiteSearchProvider) queryDirectoryPathFTSLiteralContainsIDs(ctx context.Context, term string, limit int) ([]int64, error) {
+func (p *SQLiteSearchProvider) queryDirectoryPathFTSLiteralContainsIDs(ctx context.Context, filter sqliteRecallFilter, term string, limit int) ([]int64, error) {
 	term = strings.TrimSpace(term)
 	if term == "" {
 		return nil, nil
 	}
-	return p.queryDirectoryPathFTSLikeIDs(ctx, "%"+term+"%", limit)
+	return p.queryDirectoryPathFTSLikeIDs(ctx, filter, "%"+term+"%", limit)
 }
 
 // queryDirectoryPathFTSLikeIDs keeps path FTS sparse by treating matched FTS
 // rows as directory anchors instead of final entry ids.
-func (p *SQLiteSearchProvider) queryDirectoryPathFTSLikeIDs(ctx context.Context, pattern string, limit int) ([]int64, error) {
-	ids, err := p.queryIDs(ctx, `
+func (p *SQLiteSearchProvider) queryDirectoryPathFTSLikeIDs(ctx context.Context, filter sqliteRecallFilter, pattern string, limit int) ([]int64, error) {
+	// The directory anchors stay unfiltered: a folder that fails size: or
+	// type:file can still contain children that pass, so the predicate is only
+	// applied to the expanded subtree rows.
+	ids, err := p.queryIDs(ctx, fmt.Sprintf(`
 		WITH matched_dirs(path, path_prefix, path_upper_bound) AS (
 			SELECT d.path, d.path || ?, d.path || ? || char(1114111)
 			FROM entries_path_fts f
@@ -398,98 +424,110 @@ func (p *SQLiteSearchProvider) queryDirectoryPathFTSLikeIDs(ctx context.Context,
 		SELECT e.entry_id
 		FROM entries e
 		INNER JOIN matched_dirs d ON e.path = d.path OR (e.path >= d.path_prefix AND e.path < d.path_upper_bound)
+		WHERE 1 = 1%s
 		ORDER BY e.entry_id ASC
 		LIMIT ?
-	`, string(filepath.Separator), string(filepath.Separator), pattern, limit, limit)
+	`, filter.andClause()), filter.withArgs([]any{string(filepath.Separator), string(filepath.Separator), pattern, limit}, limit)...)
 	if !isMissingFTSContentRowError(err) {
 		return ids, err
 	}
 
 	p.scheduleFTSRepair(ctx, "entries_path_fts", err)
-	fallbackIDs, fallbackErr := p.queryDirectoryPathFallbackIDs(ctx, pattern, limit)
+	fallbackIDs, fallbackErr := p.queryDirectoryPathFallbackIDs(ctx, filter, pattern, limit)
 	if fallbackErr != nil {
 		return nil, fmt.Errorf("fallback entries_path_fts LIKE query after stale FTS row: %w; original query error: %v", fallbackErr, err)
 	}
 	return fallbackIDs, nil
 }
 
-func (p *SQLiteSearchProvider) queryIDsByExtension(ctx context.Context, extension string, limit int) ([]int64, error) {
+func (p *SQLiteSearchProvider) queryIDsByExtension(ctx context.Context, filter sqliteRecallFilter, extension string, limit int) ([]int64, error) {
 	if strings.TrimSpace(extension) == "" {
 		return nil, nil
 	}
-	return p.queryIDs(ctx, `
+	return p.queryRecallIDs(ctx, filter, `
 		SELECT entry_id
 		FROM entries
 		WHERE extension = ?
 		ORDER BY entry_id ASC
-		LIMIT ?
-	`, extension, limit)
+	`, limit, extension)
 }
 
-func (p *SQLiteSearchProvider) queryNameFallbackIDs(ctx context.Context, term string, limit int) ([]int64, error) {
+func (p *SQLiteSearchProvider) queryNameFallbackIDs(ctx context.Context, filter sqliteRecallFilter, term string, limit int) ([]int64, error) {
 	term = strings.TrimSpace(term)
 	if term == "" {
 		return nil, nil
 	}
-	return p.queryIDs(ctx, `
+	return p.queryRecallIDs(ctx, filter, `
 		SELECT entry_id
 		FROM entries
 		WHERE normalized_name LIKE ? ESCAPE '\'
 		ORDER BY entry_id ASC
-		LIMIT ?
-	`, "%"+escapeLikePattern(term)+"%", limit)
+	`, limit, "%"+escapeLikePattern(term)+"%")
 }
 
-func (p *SQLiteSearchProvider) queryPathFallbackIDs(ctx context.Context, term string, limit int) ([]int64, error) {
+func (p *SQLiteSearchProvider) queryPathFallbackIDs(ctx context.Context, filter sqliteRecallFilter, term string, limit int) ([]int64, error) {
 	term = strings.TrimSpace(term)
 	if term == "" {
 		return nil, nil
 	}
-	return p.queryIDs(ctx, `
+	return p.queryRecallIDs(ctx, filter, `
 		SELECT entry_id
 		FROM entries
 		WHERE normalized_path LIKE ? ESCAPE '\'
 		ORDER BY entry_id ASC
-		LIMIT ?
-	`, "%"+escapeLikePattern(term)+"%", limit)
+	`, limit, "%"+escapeLikePattern(term)+"%")
 }
 
-func (p *SQLiteSearchProvider) queryNameWildcardFallbackIDs(ctx context.Context, pattern string, limit int) ([]int64, error) {
+func (p *SQLiteSearchProvider) queryNameWildcardFallbackIDs(ctx context.Context, filter sqliteRecallFilter, pattern string, limit int) ([]int64, error) {
 	pattern = strings.TrimSpace(pattern)
 	if pattern == "" {
 		return nil, nil
 	}
-	return p.queryIDs(ctx, `
+	return p.queryRecallIDs(ctx, filter, `
 		SELECT entry_id
 		FROM entries
 		WHERE normalized_name LIKE ?
 		ORDER BY entry_id ASC
-		LIMIT ?
-	`, pattern, limit)
+	`, limit, pattern)
 }
 
-func (p *SQLiteSearchProvider) queryPathWildcardFallbackIDs(ctx context.Context, pattern string, limit int) ([]int64, error) {
+func (p *SQLiteSearchProvider) queryPathWildcardFallbackIDs(ctx context.Context, filter sqliteRecallFilter, pattern string, limit int) ([]int64, error) {
 	pattern = strings.TrimSpace(pattern)
 	if pattern == "" {
 		return nil, nil
 	}
-	return p.queryIDs(ctx, `
+	return p.queryRecallIDs(ctx, filter, `
 		SELECT entry_id
 		FROM entries
 		WHERE normalized_path LIKE ?
 		ORDER BY entry_id ASC
+	`, limit, pattern)
+}
+
+// queryFilterOnlyIDs recalls operator-only queries straight from entries.
+// Newest rows win the candidate window because "ext:pdf" style searches are
+// almost always looking for something recent rather than the lowest entry ids.
+func (p *SQLiteSearchProvider) queryFilterOnlyIDs(ctx context.Context, filter sqliteRecallFilter, limit int) ([]int64, error) {
+	if filter.empty() {
+		return nil, nil
+	}
+	return p.queryIDs(ctx, fmt.Sprintf(`
+		SELECT e.entry_id
+		FROM entries e
+		WHERE %s
+		ORDER BY e.mtime DESC, e.entry_id ASC
 		LIMIT ?
-	`, pattern, limit)
+	`, filter.where), filter.withArgs(nil, limit)...)
 }
 
 // queryDirectoryPathFallbackIDs mirrors directory-path recall without FTS so
 // stale or unavailable path FTS rows do not break search results.
-func (p *SQLiteSearchProvider) queryDirectoryPathFallbackIDs(ctx context.Context, pattern string, limit int) ([]int64, error) {
+func (p *SQLiteSearchProvider) queryDirectoryPathFallbackIDs(ctx context.Context, filter sqliteRecallFilter, pattern string, limit int) ([]int64, error) {
 	pattern = strings.TrimSpace(pattern)
 	if pattern == "" {
 		return nil, nil
 	}
-	return p.queryIDs(ctx, `
+	return p.queryIDs(ctx, fmt.Sprintf(`
 		WITH matched_dirs(path, path_prefix, path_upper_bound) AS (
 			SELECT path, path || ?, path || ? || char(1114111)
 			FROM entries
@@ -500,12 +538,13 @@ func (p *SQLiteSearchProvider) queryDirectoryPathFallbackIDs(ctx context.Context
 		SELECT e.entry_id
 		FROM entries e
 		INNER JOIN matched_dirs d ON e.path = d.path OR (e.path >= d.path_prefix AND e.path < d.path_upper_bound)
+		WHERE 1 = 1%s
 		ORDER BY e.entry_id ASC
 		LIMIT ?
-	`, string(filepath.Separator), string(filepath.Separator), pattern, limit, limit)
+	`, filter.andClause()), filter.withArgs([]any{string(filepath.Separator), string(filepath.Separator), pattern, limit}, limit)...)
 }
 
-func (p *SQLiteSearchProvider) queryFTSLiteralContainsIDs(ctx context.Context, tableName string, columnName string, term string, limit int) ([]int64, error) {
+func (p *SQLiteSearchProvider) queryFTSLiteralContainsIDs(ctx context.Context, filter sqliteRecallFilter, tableName string, columnName string, term string, limit int) ([]int64, error) {
 	term = strings.TrimSpace(term)
 	if term == "" {
 		return nil, nil
@@ -515,65 +554,62 @@ func (p *SQLiteSearchProvider) queryFTSLiteralContainsIDs(ctx context.Context, t
 	// that optimization when ESCAPE is present. File search only treats '*' as a
 	// wildcard at the query language layer, so other LIKE metacharacters may
 	// over-recall here and the final scorer/wildcard matcher filters the rows.
-	return p.queryFTSLikeIDs(ctx, tableName, columnName, "%"+term+"%", limit)
+	return p.queryFTSLikeIDs(ctx, filter, tableName, columnName, "%"+term+"%", limit)
 }
 
-func (p *SQLiteSearchProvider) queryFTSLikeIDs(ctx context.Context, tableName string, columnName string, pattern string, limit int) ([]int64, error) {
-	ids, err := p.queryIDs(ctx, fmt.Sprintf(`
-		SELECT rowid
+func (p *SQLiteSearchProvider) queryFTSLikeIDs(ctx context.Context, filter sqliteRecallFilter, tableName string, columnName string, pattern string, limit int) ([]int64, error) {
+	ids, err := p.queryRecallIDs(ctx, filter, fmt.Sprintf(`
+		SELECT rowid AS entry_id
 		FROM %s
 		WHERE %s LIKE ?
-		LIMIT ?
-	`, tableName, columnName), pattern, limit)
+	`, tableName, columnName), limit, pattern)
 	if !isMissingFTSContentRowError(err) {
 		return ids, err
 	}
 
 	p.scheduleFTSRepair(ctx, tableName, err)
-	fallbackIDs, fallbackErr := p.queryFTSLikeFallbackIDs(ctx, tableName, pattern, limit)
+	fallbackIDs, fallbackErr := p.queryFTSLikeFallbackIDs(ctx, filter, tableName, pattern, limit)
 	if fallbackErr != nil {
 		return nil, fmt.Errorf("fallback %s LIKE query after stale FTS row: %w; original query error: %v", tableName, fallbackErr, err)
 	}
 	return fallbackIDs, nil
 }
 
-func (p *SQLiteSearchProvider) queryFTSMatchIDs(ctx context.Context, tableName string, expression string, limit int) ([]int64, error) {
-	ids, err := p.queryIDs(ctx, fmt.Sprintf(`
-		SELECT rowid
+func (p *SQLiteSearchProvider) queryFTSMatchIDs(ctx context.Context, filter sqliteRecallFilter, tableName string, expression string, limit int) ([]int64, error) {
+	ids, err := p.queryRecallIDs(ctx, filter, fmt.Sprintf(`
+		SELECT rowid AS entry_id
 		FROM %s
 		WHERE %s MATCH ?
-		LIMIT ?
-	`, tableName, tableName), expression, limit)
+	`, tableName, tableName), limit, expression)
 	if !isMissingFTSContentRowError(err) {
 		return ids, err
 	}
 
 	p.scheduleFTSRepair(ctx, tableName, err)
-	fallbackIDs, fallbackErr := p.queryFTSMatchFallbackIDs(ctx, tableName, expression, limit)
+	fallbackIDs, fallbackErr := p.queryFTSMatchFallbackIDs(ctx, filter, tableName, expression, limit)
 	if fallbackErr != nil {
 		return nil, fmt.Errorf("fallback %s MATCH query after stale FTS row: %w; original query error: %v", tableName, fallbackErr, err)
 	}
 	return fallbackIDs, nil
 }
 
-func (p *SQLiteSearchProvider) queryFTSLikeFallbackIDs(ctx context.Context, tableName string, pattern string, limit int) ([]int64, error) {
+func (p *SQLiteSearchProvider) queryFTSLikeFallbackIDs(ctx context.Context, filter sqliteRecallFilter, tableName string, pattern string, limit int) ([]int64, error) {
 	if tableName == "entries_path_fts" {
-		return p.queryDirectoryPathFallbackIDs(ctx, pattern, limit)
+		return p.queryDirectoryPathFallbackIDs(ctx, filter, pattern, limit)
 	}
 	columnName, ok := ftsContentColumn(tableName)
 	if !ok {
 		return nil, fmt.Errorf("unsupported FTS fallback table %q", tableName)
 	}
-	return p.queryIDs(ctx, fmt.Sprintf(`
+	return p.queryRecallIDs(ctx, filter, fmt.Sprintf(`
 		SELECT entry_id
 		FROM entries
 		WHERE %s LIKE ?
 		ORDER BY entry_id ASC
-		LIMIT ?
-	`, columnName), pattern, limit)
+	`, columnName), limit, pattern)
 }
 
-func (p *SQLiteSearchProvider) queryFTSMatchFallbackIDs(ctx context.Context, tableName string, expression string, limit int) ([]int64, error) {
+func (p *SQLiteSearchProvider) queryFTSMatchFallbackIDs(ctx context.Context, filter sqliteRecallFilter, tableName string, expression string, limit int) ([]int64, error) {
 	if tableName != "entries_initials_fts" {
 		return nil, fmt.Errorf("unsupported FTS MATCH fallback table %q", tableName)
 	}
@@ -585,13 +621,12 @@ func (p *SQLiteSearchProvider) queryFTSMatchFallbackIDs(ctx context.Context, tab
 	if prefix == "" {
 		return nil, nil
 	}
-	return p.queryIDs(ctx, `
+	return p.queryRecallIDs(ctx, filter, `
 		SELECT entry_id
 		FROM entries
 		WHERE pinyin_initials >= ? AND pinyin_initials < ?
 		ORDER BY pinyin_initials ASC, entry_id ASC
-		LIMIT ?
-	`, prefix, nextPrefixUpperBound(prefix), limit)
+	`, limit, prefix, nextPrefixUpperBound(prefix))
 }
 
 func ftsContentColumn(tableName string) (string, bool) {
@@ -653,6 +688,24 @@ func isMissingFTSContentRowError(err error) bool {
 	return strings.Contains(message, "fts5: missing row") && strings.Contains(message, "content table")
 }
 
+// queryRecallIDs runs one candidate recall statement. recallSQL must select a
+// single entry_id column and must not carry its own LIMIT: when the query has
+// structured filters the statement is wrapped so the predicate runs before the
+// limit, otherwise a broad name recall could fill the candidate window with
+// rows that fail size/date/type checks and hide the ones that pass.
+func (p *SQLiteSearchProvider) queryRecallIDs(ctx context.Context, filter sqliteRecallFilter, recallSQL string, limit int, args ...any) ([]int64, error) {
+	if filter.empty() {
+		return p.queryIDs(ctx, recallSQL+" LIMIT ?", append(append([]any(nil), args...), limit)...)
+	}
+	return p.queryIDs(ctx, fmt.Sprintf(`
+		SELECT recalled.entry_id
+		FROM (%s) recalled
+		INNER JOIN entries e ON e.entry_id = recalled.entry_id
+		WHERE %s
+		LIMIT ?
+	`, recallSQL, filter.where), filter.withArgs(args, limit)...)
+}
+
 func (p *SQLiteSearchProvider) queryIDs(ctx context.Context, query string, args ...any) ([]int64, error) {
 	rows, err := p.db.db.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -3,6 +3,8 @@ package filesearch
 import (
 	"context"
 	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 )
@@ -358,3 +360,73 @@ func TestScoreDocAgainstQueryWildcardWithQuotedPhraseRequiresBoth(t *testing.T)
 		t.Fatal("wildcard match should pass when quoted phrase is present")
 	}
 }
+
+func TestSQLiteSearchProviderAppliesStructuredFilters(t *testing.T) {
+	db, ctx := openTestFileSearchDB(t)
+	now := time.Now()
+	rootPath := filepath.Join(t.TempDir(), "root-sqlite-provider-filters")
+	root := RootRecord{
+		ID:        "root-sqlite-provider-filters",
+		Path:      rootPath,
+		Kind:      RootKindUser,
+		Status:    RootStatusIdle,
+		CreatedAt: now.UnixMilli(),
+		UpdatedAt: now.UnixMilli(),
+	}
+	mustInsertRoot(t, ctx, db, root)
+
+	workPath := filepath.Join(rootPath, "work")
+	newEntry := func(parent string, name string, isDir bool, size int64, age time.Duration) EntryRecord {
+		path := filepath.Join(parent, name)
+		return EntryRecord{
+			Path:           path,
+			RootID:         root.ID,
+			ParentPath:     parent,
+			Name:           name,
+			NormalizedName: normalizeIndexText(name),
+			NormalizedPath: normalizeIndexText(path),
+			IsDir:          isDir,
+			Mtime:          now.Add(-age).UnixMilli(),
+			Size:           size,
+			UpdatedAt:      now.UnixMilli(),
+		}
+	}
+	mustInsertEntrySnapshots(t, ctx, db,
+		newEntry(rootPath, "work", true, 4096, time.Hour),
+		newEntry(rootPath, "report-large.pdf", false, 20<<20, time.Hour),
+		newEntry(rootPath, "report-small.pdf", false, 2<<10, 30*24*time.Hour),
+		newEntry(rootPath, "report-draft.docx", false, 20<<20, time.Hour),
+		newEntry(workPath, "reports", true, 4096, time.Hour),
+		newEntry(workPath, "budget.xlsx", false, 1<<20, 2*time.Hour),
+	)
+
+	resultPaths := func(raw string) []string {
+		t.Helper()
+		results := searchSQLiteForTest(t, db, raw, 20)
+		paths := make([]string, 0, len(results))
+		for _, result := range results {
+			paths = append(paths, result.Path)
+		}
+		sort.Strings(paths)
+		return paths
+	}
+
+	cases := []struct {
+		raw      string
+		expected []string
+	}{
+		{raw: "report size:>10mb", expected: []string{filepath.Join(rootPath, "report-draft.docx"), filepath.Join(rootPath, "report-large.pdf")}},
+		{raw: "report size:>10mb !draft", expected: []string{filepath.Join(rootPath, "report-large.pdf")}},
+		{raw: "report modified:>7d", expected: []string{filepath.Join(rootPath, "report-small.pdf")}},
+		{raw: "ext:pdf;docx modified:<7d", expected: []string{filepath.Join(rootPath, "report-draft.docx"), filepath.Join(rootPath, "report-large.pdf")}},
+		{raw: "report type:dir", expected: []string{filepath.Join(workPath, "reports")}},
+		{raw: "parent:" + workPath, expected: []string{filepath.Join(workPath, "budget.xlsx"), filepath.Join(workPath, "reports")}},
+		{raw: "budget OR ext:pdf size:<1mb", expected: []string{filepath.Join(rootPath, "report-small.pdf"), filepath.Join(workPath, "budget.xlsx")}},
+	}
+	for _, tc := range cases {
+		paths := resultPaths(tc.raw)
+		if strings.Join(paths, "\n") != strings.Join(tc.expected, "\n") {
+			t.Fatalf("search %q: expected %#v, got %#v", tc.raw, tc.expected, paths)
+		}
+	}
+}
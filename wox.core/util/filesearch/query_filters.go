@@ -0,0 +1,544 @@
+package filesearch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"wox/util"
+)
+
+type searchFilterKind string
+
+const (
+	searchFilterKindSize      searchFilterKind = "size"
+	searchFilterKindModified  searchFilterKind = "modified"
+	searchFilterKindType      searchFilterKind = "type"
+	searchFilterKindExtension searchFilterKind = "ext"
+	searchFilterKindParent    searchFilterKind = "parent"
+	// searchFilterKindExcludeTerm is produced by a bare "!term". It is stored as
+	// a filter instead of query text because it only removes candidates and must
+	// never feed recall or scoring.
+	searchFilterKindExcludeTerm searchFilterKind = "exclude"
+)
+
+// searchFilter is one structured operator parsed out of the query text. Size
+// and modified bounds are half-open ranges [min, max) so relative durations,
+// calendar days and explicit comparisons share one SQL shape.
+type searchFilter struct {
+	kind       searchFilterKind
+	token      string
+	negated    bool
+	min        int64
+	max        int64
+	hasMin     bool
+	hasMax     bool
+	isDir      bool
+	extensions []string
+	path       string
+	term       string
+}
+
+// searchQueryGroup is one side of an OR expression. Free text keeps its
+// original spacing and quotes so the existing quoted-phrase and wildcard
+// handling still sees exactly what the user typed.
+type searchQueryGroup struct {
+	text    string
+	filters []searchFilter
+}
+
+type structuredSearchQuery struct {
+	groups []searchQueryGroup
+}
+
+const searchQueryOrOperator = "OR"
+
+// parseStructuredSearchQuery splits Everything-style operators out of raw.
+// Unknown keys and values that fail to parse stay in the free text, so plain
+// searches such as "c:\\work" or "note:2" keep their historical behavior.
+func parseStructuredSearchQuery(raw string, now time.Time) structuredSearchQuery {
+	tokens := splitSearchQueryTokens(raw)
+	if len(tokens) == 0 {
+		return structuredSearchQuery{}
+	}
+
+	var parsed structuredSearchQuery
+	var textParts []string
+	var filters []searchFilter
+	flushGroup := func() {
+		text := strings.Join(textParts, " ")
+		if strings.TrimSpace(text) != "" || len(filters) > 0 {
+			parsed.groups = append(parsed.groups, searchQueryGroup{text: text, filters: filters})
+		}
+		textParts = nil
+		filters = nil
+	}
+
+	for index, token := range tokens {
+		// A lone OR at either end is far more likely to be part of a name than an
+		// empty alternative, so only treat it as an operator between terms.
+		if (token == searchQueryOrOperator || token == "|") && index > 0 && index < len(tokens)-1 {
+			flushGroup()
+			continue
+		}
+		if filter, ok := parseSearchFilterToken(token, now); ok {
+			filters = append(filters, filter)
+			continue
+		}
+		textParts = append(textParts, token)
+	}
+	flushGroup()
+
+	return parsed
+}
+
+// splitSearchQueryTokens splits on whitespace outside double quotes. Quotes are
+// kept on the token so quoted phrases reach parseQuotedSearchQuery unchanged.
+func splitSearchQueryTokens(raw string) []string {
+	var tokens []string
+	var current strings.Builder
+	inQuote := false
+	for _, r := range raw {
+		if r == '"' {
+			inQuote = !inQuote
+			current.WriteRune(r)
+			continue
+		}
+		if !inQuote && (r == ' ' || r == '\t' || r == '\n' || r == '\r') {
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+			continue
+		}
+		current.WriteRune(r)
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+func parseSearchFilterToken(token string, now time.Time) (searchFilter, bool) {
+	body := token
+	negated := false
+	if strings.HasPrefix(body, "!") {
+		negated = true
+		body = body[1:]
+	}
+	if body == "" {
+		return searchFilter{}, false
+	}
+
+	key, value, hasKey := strings.Cut(body, ":")
+	if hasKey {
+		value = unquoteSearchFilterValue(value)
+		var (
+			filter searchFilter
+			ok     bool
+		)
+		switch strings.ToLower(key) {
+		case "size":
+			filter, ok = parseSizeSearchFilter(value)
+		case "modified", "dm", "datemodified":
+			filter, ok = parseModifiedSearchFilter(value, now)
+		case "type":
+			filter, ok = parseTypeSearchFilter(value)
+		case "ext":
+			filter, ok = parseExtensionSearchFilter(value)
+		case "parent":
+			filter, ok = parseParentSearchFilter(value)
+		}
+		if ok {
+			filter.token = token
+			filter.negated = negated
+			return filter, true
+		}
+	}
+
+	if !negated {
+		return searchFilter{}, false
+	}
+	term := unquoteSearchFilterValue(body)
+	if strings.TrimSpace(term) == "" {
+		return searchFilter{}, false
+	}
+	return searchFilter{
+		kind:    searchFilterKindExcludeTerm,
+		token:   token,
+		negated: true,
+		term:    term,
+	}, true
+}
+
+func unquoteSearchFilterValue(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		value = value[1 : len(value)-1]
+	}
+	return strings.TrimSpace(value)
+}
+
+func parseSizeSearchFilter(value string) (searchFilter, bool) {
+	filter := searchFilter{kind: searchFilterKindSize}
+	if lower, upper, isRange := strings.Cut(value, ".."); isRange {
+		minSize, minOK := parseSearchFilterSize(lower)
+		maxSize, maxOK := parseSearchFilterSize(upper)
+		if !minOK || !maxOK || minSize > maxSize {
+			return searchFilter{}, false
+		}
+		filter.min, filter.hasMin = minSize, true
+		filter.max, filter.hasMax = maxSize+1, true
+		return filter, true
+	}
+
+	operator, operand := splitSearchFilterOperator(value)
+	size, ok := parseSearchFilterSize(operand)
+	if !ok {
+		return searchFilter{}, false
+	}
+	switch operator {
+	case ">":
+		filter.min, filter.hasMin = size+1, true
+	case ">=":
+		filter.min, filter.hasMin = size, true
+	case "<":
+		filter.max, filter.hasMax = size, true
+	case "<=":
+		filter.max, filter.hasMax = size+1, true
+	default:
+		filter.min, filter.hasMin = size, true
+		filter.max, filter.hasMax = size+1, true
+	}
+	return filter, true
+}
+
+func parseSearchFilterSize(value string) (int64, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return 0, false
+	}
+
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix     string
+		multiplier int64
+	}{
+		{"tb", 1 << 40}, {"gb", 1 << 30}, {"mb", 1 << 20}, {"kb", 1 << 10},
+		{"t", 1 << 40}, {"g", 1 << 30}, {"m", 1 << 20}, {"k", 1 << 10}, {"b", 1},
+	} {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	number, ok := parseSearchFilterNumber(value)
+	if !ok {
+		return 0, false
+	}
+	return int64(number * float64(multiplier)), true
+}
+
+// parseSearchFilterNumber only accepts plain decimals. strconv.ParseFloat also
+// understands "inf", "nan" and exponents, which would turn ordinary words such
+// as "size:info" into nonsensical bounds instead of leaving them as text.
+func parseSearchFilterNumber(value string) (float64, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.Trim(value, "0123456789.") != "" || strings.Count(value, ".") > 1 {
+		return 0, false
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, false
+	}
+	return number, true
+}
+
+func parseModifiedSearchFilter(value string, now time.Time) (searchFilter, bool) {
+	filter := searchFilter{kind: searchFilterKindModified}
+	switch strings.ToLower(value) {
+	case "today":
+		start := startOfSearchFilterDay(now)
+		filter.min, filter.hasMin = start.UnixMilli(), true
+		return filter, true
+	case "yesterday":
+		start := startOfSearchFilterDay(now).AddDate(0, 0, -1)
+		filter.min, filter.hasMin = start.UnixMilli(), true
+		filter.max, filter.hasMax = start.AddDate(0, 0, 1).UnixMilli(), true
+		return filter, true
+	}
+
+	if lower, upper, isRange := strings.Cut(value, ".."); isRange {
+		lowerDay, lowerOK := parseSearchFilterDate(lower, now)
+		upperDay, upperOK := parseSearchFilterDate(upper, now)
+		if !lowerOK || !upperOK || upperDay.Before(lowerDay) {
+			return searchFilter{}, false
+		}
+		filter.min, filter.hasMin = lowerDay.UnixMilli(), true
+		filter.max, filter.hasMax = upperDay.AddDate(0, 0, 1).UnixMilli(), true
+		return filter, true
+	}
+
+	operator, operand := splitSearchFilterOperator(value)
+	if age, ok := parseSearchFilterAge(operand); ok {
+		// Relative values compare age, so "<7d" reads as "changed less than
+		// seven days ago" and selects newer entries, not older ones.
+		threshold := now.Add(-age).UnixMilli()
+		switch operator {
+		case ">", ">=":
+			filter.max, filter.hasMax = threshold, true
+		default:
+			filter.min, filter.hasMin = threshold, true
+		}
+		return filter, true
+	}
+
+	day, ok := parseSearchFilterDate(operand, now)
+	if !ok {
+		return searchFilter{}, false
+	}
+	dayStart := day.UnixMilli()
+	nextDayStart := day.AddDate(0, 0, 1).UnixMilli()
+	switch operator {
+	case ">":
+		filter.min, filter.hasMin = nextDayStart, true
+	case ">=":
+		filter.min, filter.hasMin = dayStart, true
+	case "<":
+		filter.max, filter.hasMax = dayStart, true
+	case "<=":
+		filter.max, filter.hasMax = nextDayStart, true
+	default:
+		filter.min, filter.hasMin = dayStart, true
+		filter.max, filter.hasMax = nextDayStart, true
+	}
+	return filter, true
+}
+
+func parseSearchFilterAge(value string) (time.Duration, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	for _, unit := range []struct {
+		suffix string
+		unit   time.Duration
+	}{
+		{"min", time.Minute}, {"mo", 30 * 24 * time.Hour}, {"h", time.Hour},
+		{"d", 24 * time.Hour}, {"w", 7 * 24 * time.Hour}, {"y", 365 * 24 * time.Hour},
+	} {
+		if !strings.HasSuffix(value, unit.suffix) {
+			continue
+		}
+		number, ok := parseSearchFilterNumber(strings.TrimSuffix(value, unit.suffix))
+		if !ok {
+			return 0, false
+		}
+		return time.Duration(number * float64(unit.unit)), true
+	}
+	return 0, false
+}
+
+func parseSearchFilterDate(value string, now time.Time) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{"2006-01-02", "2006/01/02"} {
+		parsed, err := time.ParseInLocation(layout, value, now.Location())
+		if err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func startOfSearchFilterDay(now time.Time) time.Time {
+	year, month, day := now.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+}
+
+func splitSearchFilterOperator(value string) (string, string) {
+	for _, operator := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(value, operator) {
+			return operator, strings.TrimSpace(value[len(operator):])
+		}
+	}
+	return "", value
+}
+
+func parseTypeSearchFilter(value string) (searchFilter, bool) {
+	switch strings.ToLower(value) {
+	case "dir", "folder", "directory":
+		return searchFilter{kind: searchFilterKindType, isDir: true}, true
+	case "file":
+		return searchFilter{kind: searchFilterKindType, isDir: false}, true
+	default:
+		return searchFilter{}, false
+	}
+}
+
+func parseExtensionSearchFilter(value string) (searchFilter, bool) {
+	parts := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ';' || r == ',' || r == '|'
+	})
+	extensions := make([]string, 0, len(parts))
+	for _, part := range parts {
+		extension := normalizeExtension(part)
+		if extension == "" {
+			continue
+		}
+		extensions = append(extensions, extension)
+	}
+	extensions = util.UniqueStrings(extensions)
+	if len(extensions) == 0 {
+		return searchFilter{}, false
+	}
+	return searchFilter{kind: searchFilterKindExtension, extensions: extensions}, true
+}
+
+func parseParentSearchFilter(value string) (searchFilter, bool) {
+	if value == "" {
+		return searchFilter{}, false
+	}
+	if value == "~" || strings.HasPrefix(value, "~/") || strings.HasPrefix(value, `~\`) {
+		homeDir, err := os.UserHomeDir()
+		if err != nil || homeDir == "" {
+			return searchFilter{}, false
+		}
+		value = filepath.Join(homeDir, value[1:])
+	}
+	if !filepath.IsAbs(value) {
+		return searchFilter{}, false
+	}
+	return searchFilter{kind: searchFilterKindParent, path: filepath.Clean(value)}, true
+}
+
+// sqlitePredicate renders the filter against the entries table alias "e".
+// Every column used here is already stored for reconcile or rerank, so
+// pushing the predicate into recall does not need a schema change.
+func (f searchFilter) sqlitePredicate() (string, []any) {
+	var (
+		predicate string
+		args      []any
+	)
+	switch f.kind {
+	case searchFilterKindSize, searchFilterKindModified:
+		column := "e.size"
+		if f.kind == searchFilterKindModified {
+			column = "e.mtime"
+		}
+		var bounds []string
+		if f.hasMin {
+			bounds = append(bounds, column+" >= ?")
+			args = append(args, f.min)
+		}
+		if f.hasMax {
+			bounds = append(bounds, column+" < ?")
+			args = append(args, f.max)
+		}
+		predicate = strings.Join(bounds, " AND ")
+		if f.kind == searchFilterKindSize {
+			// Directory sizes are filesystem block sizes, not content sizes, so a
+			// size operator only ever selects files, negated or not.
+			if f.negated {
+				return "(e.is_dir = 0 AND NOT (" + predicate + "))", args
+			}
+			return "(e.is_dir = 0 AND " + predicate + ")", args
+		}
+	case searchFilterKindType:
+		predicate = "e.is_dir = ?"
+		if f.isDir {
+			args = append(args, 1)
+		} else {
+			args = append(args, 0)
+		}
+	case searchFilterKindExtension:
+		placeholders := make([]string, 0, len(f.extensions))
+		for _, extension := range f.extensions {
+			placeholders = append(placeholders, "?")
+			args = append(args, extension)
+		}
+		predicate = fmt.Sprintf("e.extension IN (%s)", strings.Join(placeholders, ", "))
+	case searchFilterKindParent:
+		predicate = "e.parent_path = ?"
+		if util.IsWindows() {
+			predicate = "e.parent_path = ? COLLATE NOCASE"
+		}
+		args = append(args, f.path)
+	case searchFilterKindExcludeTerm:
+		column := "e.normalized_name"
+		term := normalizeIndexText(f.term)
+		if strings.ContainsAny(f.term, `/\`) {
+			column = "e.normalized_path"
+			term = normalizePathQuery(f.term)
+		}
+		predicate = column + ` LIKE ? ESCAPE '\'`
+		args = append(args, "%"+escapeLikePattern(term)+"%")
+	}
+	if predicate == "" {
+		return "", nil
+	}
+	if f.negated {
+		return "NOT (" + predicate + ")", args
+	}
+	return "(" + predicate + ")", args
+}
+
+// sqliteRecallFilter is the compiled metadata predicate for one query group.
+type sqliteRecallFilter struct {
+	where string
+	args  []any
+}
+
+func buildSQLiteRecallFilter(filters []searchFilter) sqliteRecallFilter {
+	predicates := make([]string, 0, len(filters))
+	var args []any
+	for _, filter := range filters {
+		predicate, predicateArgs := filter.sqlitePredicate()
+		if predicate == "" {
+			continue
+		}
+		predicates = append(predicates, predicate)
+		args = append(args, predicateArgs...)
+	}
+	return sqliteRecallFilter{
+		where: strings.Join(predicates, " AND "),
+		args:  args,
+	}
+}
+
+func (f sqliteRecallFilter) empty() bool {
+	return f.where == ""
+}
+
+// andClause appends the filter to a WHERE clause that already selects from
+// the entries table aliased as "e".
+func (f sqliteRecallFilter) andClause() string {
+	if f.empty() {
+		return ""
+	}
+	return " AND " + f.where
+}
+
+// withArgs builds the argument list for a statement whose placeholders are
+// ordered leading args, then the filter predicate, then trailing args.
+func (f sqliteRecallFilter) withArgs(leading []any, trailing ...any) []any {
+	args := make([]any, 0, len(leading)+len(f.args)+len(trailing))
+	args = append(args, leading...)
+	args = append(args, f.args...)
+	return append(args, trailing...)
+}
+
+// ActiveSearchFilters returns the structured operators recognized in raw, in
+// the order they were typed. Callers use it to show how a query was understood
+// without reimplementing the query language.
+func ActiveSearchFilters(raw string) []string {
+	parsed := parseStructuredSearchQuery(normalizeQuery(raw), time.Now())
+	var tokens []string
+	for _, group := range parsed.groups {
+		for _, filter := range group.filters {
+			tokens = append(tokens, filter.token)
+		}
+	}
+	return util.UniqueStrings(tokens)
+}
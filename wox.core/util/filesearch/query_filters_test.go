@@ -0,0 +1,89 @@
+package filesearch
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseStructuredSearchQuerySplitsOperatorsAndGroups(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.Local)
+	parsed := parseStructuredSearchQuery(`"quarterly report" size:>10mb !draft OR ext:pdf;.DOCX modified:<7d`, now)
+	if len(parsed.groups) != 2 {
+		t.Fatalf("expected two OR groups, got %#v", parsed.groups)
+	}
+
+	first := parsed.groups[0]
+	if first.text != `"quarterly report"` {
+		t.Fatalf("expected quoted phrase to stay in first group text, got %q", first.text)
+	}
+	if len(first.filters) != 2 {
+		t.Fatalf("expected size and exclude filters in first group, got %#v", first.filters)
+	}
+	if size := first.filters[0]; size.kind != searchFilterKindSize || !size.hasMin || size.min != 10<<20+1 || size.hasMax {
+		t.Fatalf("expected size:>10mb to become an exclusive lower bound, got %#v", size)
+	}
+	if exclude := first.filters[1]; exclude.kind != searchFilterKindExcludeTerm || exclude.term != "draft" || !exclude.negated {
+		t.Fatalf("expected !draft to become a negated exclude term, got %#v", exclude)
+	}
+
+	second := parsed.groups[1]
+	if second.text != "" {
+		t.Fatalf("expected operator-only second group, got text %q", second.text)
+	}
+	if ext := second.filters[0]; ext.kind != searchFilterKindExtension || !reflect.DeepEqual(ext.extensions, []string{"pdf", "docx"}) {
+		t.Fatalf("expected normalized extension list, got %#v", ext)
+	}
+	modified := second.filters[1]
+	if modified.kind != searchFilterKindModified || !modified.hasMin || modified.hasMax || modified.min != now.Add(-7*24*time.Hour).UnixMilli() {
+		t.Fatalf("expected modified:<7d to select entries newer than seven days, got %#v", modified)
+	}
+}
+
+func TestParseStructuredSearchQueryKeepsUnknownOperatorsAsText(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.Local)
+	for _, raw := range []string{"note:2", "size:huge", "type:image", `c:\work`, "OR readme", "readme OR", "modified:10m"} {
+		parsed := parseStructuredSearchQuery(raw, now)
+		if len(parsed.groups) != 1 || parsed.groups[0].text != raw || len(parsed.groups[0].filters) != 0 {
+			t.Fatalf("expected %q to stay plain text, got %#v", raw, parsed.groups)
+		}
+	}
+}
+
+func TestParseModifiedSearchFilterUsesCalendarDays(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.Local)
+	dayStart := time.Date(2026, 1, 2, 0, 0, 0, 0, time.Local).UnixMilli()
+	nextDayStart := time.Date(2026, 1, 3, 0, 0, 0, 0, time.Local).UnixMilli()
+
+	cases := []struct {
+		value  string
+		min    int64
+		max    int64
+		hasMin bool
+		hasMax bool
+	}{
+		{value: "2026-01-02", min: dayStart, max: nextDayStart, hasMin: true, hasMax: true},
+		{value: ">2026-01-02", min: nextDayStart, hasMin: true},
+		{value: "<=2026-01-02", max: nextDayStart, hasMax: true},
+		{value: "2026-01-02..2026-01-02", min: dayStart, max: nextDayStart, hasMin: true, hasMax: true},
+	}
+	for _, tc := range cases {
+		filter, ok := parseModifiedSearchFilter(tc.value, now)
+		if !ok {
+			t.Fatalf("expected modified:%s to parse", tc.value)
+		}
+		if filter.hasMin != tc.hasMin || filter.hasMax != tc.hasMax || filter.min != tc.min || filter.max != tc.max {
+			t.Fatalf("modified:%s: expected [%d,%d) min=%v max=%v, got %#v", tc.value, tc.min, tc.max, tc.hasMin, tc.hasMax, filter)
+		}
+	}
+}
+
+func TestActiveSearchFiltersListsRecognizedTokens(t *testing.T) {
+	parent := filepath.Join(t.TempDir(), "work")
+	tokens := ActiveSearchFilters("budget type:file parent:" + parent + " OR notes type:file size:1kb..2kb")
+	expected := []string{"type:file", "parent:" + parent, "size:1kb..2kb"}
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Fatalf("expected active filters %#v, got %#v", expected, tokens)
+	}
+}
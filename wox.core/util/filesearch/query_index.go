@@ -32,6 +32,14 @@ type queryPlan struct {
 	postIntersectionLimit int
 	preRerankLimit        int
 	shortQueryLength      int
+	// recallFilter carries structured operators such as size:, modified: and
+	// ext:. It is applied inside every SQLite recall query so candidate limits
+	// are spent on rows that can actually be returned.
+	recallFilter sqliteRecallFilter
+	// filterOnly marks queries made purely of operators, for example
+	// "ext:pdf modified:<7d". They recall straight from entries because there
+	// is no name or path term to drive FTS.
+	filterOnly bool
 }
 
 type docRecord struct {
@@ -45,19 +53,29 @@ const (
 	defaultPerClauseLimit        = 20000
 	defaultPostIntersectionLimit = 10000
 	defaultPreRerankLimit        = 4000
+	// filterOnlyQueryScore ranks operator-only matches like extension-only ones:
+	// every row satisfies the query equally, so ties fall back to name order.
+	filterOnlyQueryScore = 500
 )
 
 func buildQueryPlan(query SearchQuery) *queryPlan {
-	inputRaw := normalizeQuery(query.Raw)
-	if inputRaw == "" {
-		return nil
-	}
-
+	recallFilter := buildSQLiteRecallFilter(query.filters)
+	inputRaw := normalizeQuery(query.text)
 	quoted := parseQuotedSearchQuery(inputRaw)
 	raw := normalizeQuery(quoted.unquoted)
 	exactPhrases := normalizeExactPhrases(quoted.phrases)
 	if raw == "" && len(exactPhrases) == 0 {
-		return nil
+		if recallFilter.empty() {
+			return nil
+		}
+		return &queryPlan{
+			usePinyin:             !query.DisablePinyin,
+			perClauseLimit:        defaultPerClauseLimit,
+			postIntersectionLimit: defaultPostIntersectionLimit,
+			preRerankLimit:        defaultPreRerankLimit,
+			recallFilter:          recallFilter,
+			filterOnly:            true,
+		}
 	}
 	recallRaw := raw
 	if recallRaw == "" {
@@ -105,6 +123,7 @@ func buildQueryPlan(query SearchQuery) *queryPlan {
 		postIntersectionLimit: defaultPostIntersectionLimit,
 		preRerankLimit:        defaultPreRerankLimit,
 		shortQueryLength:      utf8Len(rawLower),
+		recallFilter:          recallFilter,
 	}
 
 	if plan.shortQueryLength <= 2 {
@@ -127,6 +146,9 @@ func scoreDocAgainstQuery(query SearchQuery, record docRecord) (bool, int64) {
 	if plan == nil {
 		return false, 0
 	}
+	if plan.filterOnly {
+		return true, filterOnlyQueryScore
+	}
 	if !recordMatchesExactPhrases(plan, record) {
 		return false, 0
 	}
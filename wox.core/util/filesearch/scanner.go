@@ -1845,6 +1845,22 @@ func (s *Scanner) handleChangeSignal(ctx context.Context, signal ChangeSignal) {
 			signal.FeedType,
 			strings.TrimSpace(signal.Reason),
 		))
+		if rootFound && isStrictSubtreeOfRoot(root, signal.Path) {
+			// Feature addition: recursive Linux feeds know which subtree lost
+			// history (inotify watch limit, queue overflow catch-up). Reconcile
+			// only that subtree and keep the root ready, otherwise every later
+			// concrete dirty path would be escalated to a full root reconcile.
+			s.enqueueDirtyWithContext(ctx, DirtySignal{
+				Kind:          DirtySignalKindPath,
+				SemanticKind:  ChangeSemanticKindRequiresRootReconcile,
+				RootID:        signal.RootID,
+				Path:          cleanDirtyQueuePath(signal.Path),
+				PathIsDir:     true,
+				PathTypeKnown: true,
+				At:            signal.At,
+			})
+			return
+		}
 		s.updateRootFeedState(ctx, signal.RootID, RootFeedStateDegraded)
 		s.enqueueDirtyWithContext(ctx, DirtySignal{
 			Kind:          DirtySignalKindRoot,
@@ -1874,6 +1890,14 @@ func (s *Scanner) handleChangeSignal(ctx context.Context, signal ChangeSignal) {
 	}
 }
 
+func isStrictSubtreeOfRoot(root RootRecord, path string) bool {
+	cleanPath := cleanDirtyQueuePath(path)
+	if cleanPath == "" || cleanPath == filepath.Clean(root.Path) {
+		return false
+	}
+	return pathWithinScope(root.Path, cleanPath)
+}
+
 func shouldKeepKnownFileDeltaScoped(signal ChangeSignal) bool {
 	if signal.Kind != ChangeSignalKindDirtyPath || !signal.PathTypeKnown || signal.PathIsDir {
 		return false
@@ -237,6 +237,67 @@ func TestScannerQueuesKnownFileRenameDeltaWhenRootIsDegraded(t *testing.T) {
 	}
 }
 
+func TestScannerQueuesSubtreeReconcileWithoutDegradingRoot(t *testing.T) {
+	db, ctx := openTestFileSearchDB(t)
+	now := time.Now().UnixMilli()
+	rootPath := filepath.Join(t.TempDir(), "root-subtree-reconcile")
+	subtreePath := filepath.Join(rootPath, "projects", "overflowed")
+	mustMkdirAll(t, subtreePath)
+
+	root := RootRecord{
+		ID:        "root-subtree-reconcile",
+		Path:      rootPath,
+		Kind:      RootKindUser,
+		Status:    RootStatusIdle,
+		FeedType:  RootFeedTypeInotify,
+		FeedState: RootFeedStateReady,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	mustInsertRoot(t, ctx, db, root)
+
+	scanner := NewScanner(db)
+	scanner.dirtyQueueConfig = DirtyQueueConfig{
+		DebounceWindow:               defaultDirtyDebounceWindow,
+		SiblingMergeThreshold:        8,
+		RootEscalationPathThreshold:  512,
+		RootEscalationDirectoryRatio: 0,
+	}
+	scanner.dirtyQueue = NewDirtyQueue(scanner.dirtyQueueConfig)
+
+	scanner.handleChangeSignal(ctx, ChangeSignal{
+		Kind:          ChangeSignalKindRequiresRootReconcile,
+		SemanticKind:  ChangeSemanticKindRequiresRootReconcile,
+		RootID:        root.ID,
+		FeedType:      RootFeedTypeInotify,
+		Path:          subtreePath,
+		PathIsDir:     true,
+		PathTypeKnown: true,
+		Reason:        "inotify watch limit reached",
+		At:            time.Now().Add(-3 * defaultDirtyDebounceWindow),
+	})
+
+	rootDirectoryCounts, _, _, err := scanner.loadDirtyQueueContext(ctx)
+	if err != nil {
+		t.Fatalf("load dirty queue context: %v", err)
+	}
+	batches := scanner.dirtyQueue.FlushReadyWithDebounce(time.Now(), rootDirectoryCounts, scanner.currentDirtyDebounceWindow())
+	if len(batches) != 1 {
+		t.Fatalf("expected one dirty batch, got %#v", batches)
+	}
+	if batches[0].Mode != ReconcileModeSubtree || len(batches[0].Paths) != 1 || batches[0].Paths[0] != subtreePath {
+		t.Fatalf("expected subtree reconcile of %q, got %s with paths=%#v", subtreePath, batches[0].Mode, batches[0].Paths)
+	}
+
+	rootAfter, err := db.FindRootByID(ctx, root.ID)
+	if err != nil {
+		t.Fatalf("find root after subtree reconcile signal: %v", err)
+	}
+	if rootAfter.FeedState != RootFeedStateReady {
+		t.Fatalf("expected subtree reconcile to keep root ready, got %q", rootAfter.FeedState)
+	}
+}
+
 func TestScannerDirtyDebounceWindowIsCappedByMaxPendingWait(t *testing.T) {
 	scanner := NewScanner(nil)
 	scanner.dirtyQueueConfig = DirtyQueueConfig{
@@ -175,6 +175,11 @@ func rootNeedsStartupReconcile(root RootRecord, now time.Time) bool {
 		return !ok || !feedCursorFresh(cursor, now, defaultFeedCursorSafeWindow)
 	case RootFeedTypeUSN:
 		return usnRootNeedsStartupReconcile(root, now)
+	case RootFeedTypeInotify, RootFeedTypeFanotify:
+		// Linux feeds cannot replay history, but a fresh cursor lets the feed's
+		// registration walk reconcile only entries whose ctime moved past it.
+		cursor, ok := decodeLinuxFeedCursor(root.FeedCursor)
+		return !ok || !feedCursorFresh(cursor, now, defaultFeedCursorSafeWindow)
 	default:
 		return true
 	}
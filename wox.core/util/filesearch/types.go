@@ -10,6 +10,13 @@ type SearchQuery struct {
 	DisablePinyin bool
 	wildcard      *wildcardQuery
 	plan          *queryPlan
+	// text is Raw with structured operators removed. Recall and scoring read
+	// it instead of Raw so "report size:>1mb" still ranks by "report" alone.
+	text    string
+	filters []searchFilter
+	// alternatives holds one normalized query per OR group. When set, the
+	// query itself carries no plan and results are the union of the groups.
+	alternatives []SearchQuery
 }
 
 type StatusSnapshot struct {
@@ -126,6 +133,8 @@ const (
 	RootFeedTypeFallback RootFeedType = "fallback"
 	RootFeedTypeFSEvents RootFeedType = "fsevents"
 	RootFeedTypeUSN      RootFeedType = "usn"
+	RootFeedTypeInotify  RootFeedType = "inotify"
+	RootFeedTypeFanotify RootFeedType = "fanotify"
 )
 
 type RootFeedState string
@@ -0,0 +1,52 @@
+package util
+
+import (
+	"strings"
+	"unicode"
+)
+
+// BuildFTSQuery turns user input into an FTS5 expression scoped to columns.
+// Quoted text becomes a phrase and every other word a prefix term, all
+// combined with AND, so partially typed words match while FTS5 operators and
+// syntax in indexed text can't break the query. Returns false when nothing
+// indexable is left.
+func BuildFTSQuery(search string, columns []string) (string, bool) {
+	var terms []string
+	appendTerm := func(text string, prefix bool) {
+		text = strings.TrimSuffix(strings.TrimSpace(text), "*")
+		if strings.IndexFunc(text, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsNumber(r) }) < 0 {
+			return
+		}
+		term := `"` + strings.ReplaceAll(text, `"`, `""`) + `"`
+		if prefix {
+			term += "*"
+		}
+		terms = append(terms, term)
+	}
+
+	for search != "" {
+		search = strings.TrimLeftFunc(search, unicode.IsSpace)
+		if strings.HasPrefix(search, `"`) {
+			phrase, rest, found := strings.Cut(search[1:], `"`)
+			if !found {
+				// An unterminated quote is usually a phrase still being typed.
+				appendTerm(phrase, true)
+				break
+			}
+			appendTerm(phrase, false)
+			search = rest
+			continue
+		}
+		end := strings.IndexFunc(search, func(r rune) bool { return unicode.IsSpace(r) || r == '"' })
+		if end < 0 {
+			end = len(search)
+		}
+		appendTerm(search[:end], true)
+		search = search[end:]
+	}
+
+	if len(terms) == 0 {
+		return "", false
+	}
+	return "{" + strings.Join(columns, " ") + "} : (" + strings.Join(terms, " ") + ")", true
+}
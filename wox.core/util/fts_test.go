@@ -0,0 +1,23 @@
+package util
+
+import "testing"
+
+func TestBuildFTSQuery(t *testing.T) {
+	cases := map[string]string{
+		`kube get`:            `{content alias} : ("kube"* "get"*)`,
+		`"get pods" ns`:       `{content alias} : ("get pods" "ns"*)`,
+		`say "hi`:             `{content alias} : ("say"* "hi"*)`,
+		`OR NOT* c-d`:         `{content alias} : ("OR"* "NOT"* "c-d"*)`,
+		`a"b c`:               `{content alias} : ("a"* "b c"*)`,
+		`  foo   `:            `{content alias} : ("foo"*)`,
+		`it's`:                `{content alias} : ("it's"*)`,
+		`":// #"`:             ``,
+		`https://example.com`: `{content alias} : ("https://example.com"*)`,
+	}
+	for search, want := range cases {
+		got, ok := BuildFTSQuery(search, []string{"content", "alias"})
+		if got != want || ok != (want != "") {
+			t.Errorf("BuildFTSQuery(%q) = %q, %v; want %q", search, got, ok, want)
+		}
+	}
+}
@@ -19,6 +19,9 @@ const holdBindingPrefix = "hold:"
 type Binding struct {
 	Trigger    TriggerMode
 	CombineKey string
+	// Steps are the keys that follow CombineKey in a sequence binding such as
+	// "ctrl+space, g, h". CombineKey is then the leader that gets registered.
+	Steps []string
 }
 
 // ParseBinding keeps trigger semantics in the saved hotkey string.
@@ -34,11 +37,20 @@ func ParseBinding(value string) (Binding, error) {
 		if combineKey == "" {
 			return Binding{}, fmt.Errorf("hold hotkey binding requires a hotkey")
 		}
+		if IsSequenceHotkeyString(combineKey) {
+			return Binding{}, fmt.Errorf("hold hotkey binding can't be a sequence: %s", combineKey)
+		}
 		return Binding{Trigger: TriggerHold, CombineKey: combineKey}, nil
 	}
 
 	if strings.Contains(trimmed, ":") {
 		return Binding{}, fmt.Errorf("unsupported hotkey binding: %s", trimmed)
 	}
+	if steps := SplitSequence(trimmed); len(steps) > 1 {
+		if err := ValidateSequence(trimmed); err != nil {
+			return Binding{}, err
+		}
+		return Binding{Trigger: TriggerPress, CombineKey: steps[0], Steps: steps[1:]}, nil
+	}
 	return Binding{Trigger: TriggerPress, CombineKey: trimmed}, nil
 }
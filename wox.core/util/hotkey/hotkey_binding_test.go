@@ -22,6 +22,19 @@ func TestParseBindingRecognizesHoldPrefix(t *testing.T) {
 	}
 }
 
+func TestParseBindingSplitsSequenceLeader(t *testing.T) {
+	binding, err := ParseBinding("ctrl+space, g, h")
+	if err != nil {
+		t.Fatalf("parse sequence binding: %v", err)
+	}
+	if binding.Trigger != TriggerPress || binding.CombineKey != "ctrl+space" || len(binding.Steps) != 2 || binding.Steps[1] != "h" {
+		t.Fatalf("expected ctrl+space leader with g, h steps, got %+v", binding)
+	}
+	if _, err := ParseBinding("hold:left_alt, g"); err == nil {
+		t.Fatalf("expected hold sequence binding to be rejected")
+	}
+}
+
 func TestParseBindingRejectsInvalidPrefixedBinding(t *testing.T) {
 	if _, err := ParseBinding("press:left_alt"); err == nil {
 		t.Fatalf("expected unsupported prefixed hotkey binding to be rejected")
@@ -11,6 +11,10 @@ const (
 	hotkeyKindHoldModifier   hotkeyKind = "holdModifier"
 	hotkeyKindPressModifier  hotkeyKind = "pressModifier"
 	hotkeyKindUnknown        hotkeyKind = ""
+
+	// hotkeyKindSequence is only a recording kind. A sequence registers its
+	// leader as one of the kinds above and matches the rest in hotkey_sequence.go.
+	hotkeyKindSequence hotkeyKind = "sequence"
 )
 
 type registerOptions struct {
@@ -106,6 +106,8 @@ func parseHotkeyKind(kind string) (hotkeyKind, bool) {
 		return hotkeyKindHoldModifier, true
 	case hotkeyKindPressModifier:
 		return hotkeyKindPressModifier, true
+	case hotkeyKindSequence:
+		return hotkeyKindSequence, true
 	default:
 		return hotkeyKindUnknown, false
 	}
@@ -252,6 +254,10 @@ type recordingRawState struct {
 	holdPendingKeys   []keyboard.Key
 	holdPendingTimer  *time.Timer
 	pressPendingTimer *time.Timer
+	// sequencePrefix is the last recorded hotkey that bare keys may extend
+	// into a sequence until sequenceDeadline.
+	sequencePrefix   string
+	sequenceDeadline int64
 }
 
 func newRecordingRawState(allowed map[hotkeyKind]bool, onRecorded func(recordedHotkey)) *recordingRawState {
@@ -336,12 +342,52 @@ func (s *recordingRawState) HandleEvent(event keyboard.RawKeyEvent) bool {
 			consume = true
 		}
 	}
+
+	if s.allowed[hotkeyKindSequence] {
+		if result, ok := s.extendSequenceLocked(event, now, len(recorded) > 0); ok {
+			recorded = append(recorded, result)
+			consume = true
+		}
+		s.trackSequencePrefixLocked(recorded, now)
+	}
 	s.mu.Unlock()
 
 	s.dispatchRecorded(recorded)
 	return consume
 }
 
+// extendSequenceLocked appends a bare key to the previously recorded hotkey.
+// Keys the settings recorder uses for its own controls never become steps.
+func (s *recordingRawState) extendSequenceLocked(event keyboard.RawKeyEvent, now int64, recordedOther bool) (recordedHotkey, bool) {
+	if recordedOther || s.sequencePrefix == "" || now > s.sequenceDeadline || event.Type != keyboard.EventTypeKeyDown || event.Key.IsModifier() {
+		return recordedHotkey{}, false
+	}
+	switch event.Key {
+	case keyboard.KeyEscape, keyboard.KeyReturn, keyboard.KeyTab, keyboard.KeyDelete:
+		return recordedHotkey{}, false
+	}
+	if len(s.currentGenericModifiersLocked()) > 0 {
+		return recordedHotkey{}, false
+	}
+	step := normalKeyString(event.Key)
+	steps := SplitSequence(s.sequencePrefix)
+	if step == "" || len(steps) >= sequenceMaxSteps {
+		return recordedHotkey{}, false
+	}
+	return recordedHotkey{Hotkey: JoinSequence(append(steps, step)), Kind: hotkeyKindSequence}, true
+}
+
+// trackSequencePrefixLocked remembers the newest hotkey that can lead a sequence.
+func (s *recordingRawState) trackSequencePrefixLocked(recorded []recordedHotkey, now int64) {
+	for _, result := range recorded {
+		switch result.Kind {
+		case hotkeyKindNormalCombo, hotkeyKindDoubleModifier, hotkeyKindCapsLockCombo, hotkeyKindSequence:
+			s.sequencePrefix = result.Hotkey
+			s.sequenceDeadline = now + sequenceStepTimeout.Milliseconds()
+		}
+	}
+}
+
 func (s *recordingRawState) updatePressedLocked(event keyboard.RawKeyEvent) {
 	if !isSpecificModifierKey(event.Key) {
 		return
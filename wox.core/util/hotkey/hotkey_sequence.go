@@ -0,0 +1,393 @@
+package hotkey
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"wox/util"
+	"wox/util/keyboard"
+)
+
+// sequenceMaxSteps caps a sequence at the leader plus three follow-up keys. Longer
+// chains are hard to remember and make the which-key overlay noisy.
+const sequenceMaxSteps = 4
+
+// sequenceStepTimeout is how long an active sequence waits for its next key.
+var sequenceStepTimeout = 2 * time.Second
+
+// SplitSequence splits a hotkey sequence such as "ctrl+space, g, h" into its
+// chords. A comma directly after "+" or at the start of a chord is the comma
+// key itself, so "ctrl+," stays a single chord. Plain hotkeys return one chord.
+func SplitSequence(combineKey string) []string {
+	steps := []string{}
+	var current strings.Builder
+	for _, r := range combineKey {
+		if r == ',' {
+			trimmed := strings.TrimSpace(current.String())
+			if trimmed != "" && !strings.HasSuffix(trimmed, "+") {
+				steps = append(steps, trimmed)
+				current.Reset()
+				continue
+			}
+		}
+		current.WriteRune(r)
+	}
+	if trimmed := strings.TrimSpace(current.String()); trimmed != "" {
+		steps = append(steps, trimmed)
+	}
+	return steps
+}
+
+// IsSequenceHotkeyString reports whether combineKey has follow-up keys after its leader.
+func IsSequenceHotkeyString(combineKey string) bool {
+	return len(SplitSequence(combineKey)) > 1
+}
+
+// JoinSequence is the inverse of SplitSequence and produces the canonical stored form.
+func JoinSequence(steps []string) string {
+	return strings.Join(steps, ", ")
+}
+
+// ValidateSequence checks the follow-up keys of a sequence. The leader is
+// registered like any other hotkey and is validated by the registration itself.
+func ValidateSequence(combineKey string) error {
+	steps := SplitSequence(combineKey)
+	if len(steps) < 2 {
+		return fmt.Errorf("hotkey sequence requires a leader and at least one key: %s", combineKey)
+	}
+	if len(steps) > sequenceMaxSteps {
+		return fmt.Errorf("hotkey sequence supports at most %d keys after the leader: %s", sequenceMaxSteps-1, combineKey)
+	}
+	for _, step := range steps[1:] {
+		if _, err := parseSequenceStep(step); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type sequenceStep struct {
+	modifiers keyboard.Modifier
+	key       keyboard.Key
+}
+
+func parseSequenceStep(step string) (sequenceStep, error) {
+	spec, err := (&Hotkey{}).parseCombineKey(step)
+	if err != nil {
+		return sequenceStep{}, err
+	}
+	if spec.isDoubleModifier() || spec.isCapsLockKey() || spec.isModifierChord() {
+		return sequenceStep{}, fmt.Errorf("hotkey sequence step must be a key with optional modifiers: %s", step)
+	}
+	if spec.key == keyboard.KeyEscape {
+		return sequenceStep{}, fmt.Errorf("escape cancels a hotkey sequence and can't be one of its keys")
+	}
+	return sequenceStep{modifiers: spec.modifiers, key: spec.key}, nil
+}
+
+// matches accepts the exact step modifiers, or the step modifiers plus leader
+// modifiers that are still held from the leader chord.
+func (s sequenceStep) matches(key keyboard.Key, modifiers keyboard.Modifier, leaderModifiers keyboard.Modifier) bool {
+	if key != s.key {
+		return false
+	}
+	return modifiers == s.modifiers || modifiers&^leaderModifiers == s.modifiers
+}
+
+// SequenceCandidate is one binding that continues after a shared leader.
+type SequenceCandidate struct {
+	// Steps are the chords after the leader, e.g. ["g", "h"].
+	Steps    []string
+	Label    string
+	Callback func()
+}
+
+// SequenceHint is one key that continues the active sequence.
+type SequenceHint struct {
+	Key   string
+	Label string
+	// Partial is set when more keys follow before a binding fires. Label is
+	// empty for a partial hint shared by several bindings.
+	Partial bool
+}
+
+// SequenceProgress reports the active sequence to the which-key overlay.
+type SequenceProgress struct {
+	Typed []string
+	Hints []SequenceHint
+	Done  bool
+}
+
+type sequenceOutcome int
+
+const (
+	sequenceOutcomeIgnored sequenceOutcome = iota
+	sequenceOutcomeAdvanced
+	sequenceOutcomeFired
+	sequenceOutcomeCancelled
+	sequenceOutcomeMismatched
+)
+
+type sequenceMatcherCandidate struct {
+	steps    []sequenceStep
+	keys     []string
+	label    string
+	callback func()
+}
+
+// sequenceMatcher narrows the candidates behind a leader one key at a time. It
+// holds no timers or listeners so the matching rules can be tested directly.
+type sequenceMatcher struct {
+	leaderModifiers keyboard.Modifier
+	typed           []string
+	candidates      []sequenceMatcherCandidate
+	pressed         map[keyboard.Key]bool
+}
+
+func newSequenceMatcher(leader string, candidates []SequenceCandidate) (*sequenceMatcher, error) {
+	leaderSpec, err := (&Hotkey{}).parseCombineKey(leader)
+	if err != nil {
+		return nil, err
+	}
+	matcher := &sequenceMatcher{
+		leaderModifiers: leaderSpec.modifiers,
+		typed:           []string{leader},
+		pressed:         map[keyboard.Key]bool{},
+	}
+	for _, candidate := range candidates {
+		if len(candidate.Steps) == 0 || candidate.Callback == nil {
+			continue
+		}
+		parsed := sequenceMatcherCandidate{label: candidate.Label, callback: candidate.Callback}
+		for _, step := range candidate.Steps {
+			sequenceStep, stepErr := parseSequenceStep(step)
+			if stepErr != nil {
+				return nil, stepErr
+			}
+			parsed.steps = append(parsed.steps, sequenceStep)
+			parsed.keys = append(parsed.keys, strings.TrimSpace(step))
+		}
+		matcher.candidates = append(matcher.candidates, parsed)
+	}
+	if len(matcher.candidates) == 0 {
+		return nil, fmt.Errorf("hotkey sequence has no follow-up keys: %s", leader)
+	}
+	return matcher, nil
+}
+
+// HandleEvent advances the matcher with one raw key event. The callback is only
+// returned together with sequenceOutcomeFired.
+func (m *sequenceMatcher) HandleEvent(event keyboard.RawKeyEvent) (sequenceOutcome, func()) {
+	if event.Key == keyboard.KeyUnknown {
+		return sequenceOutcomeIgnored, nil
+	}
+	if event.Key.IsModifier() {
+		m.pressed[event.Key] = event.Type == keyboard.EventTypeKeyDown
+		return sequenceOutcomeIgnored, nil
+	}
+	if event.Type != keyboard.EventTypeKeyDown {
+		return sequenceOutcomeIgnored, nil
+	}
+	if event.Key == keyboard.KeyEscape {
+		return sequenceOutcomeCancelled, nil
+	}
+
+	modifiers := event.Modifiers | m.pressedModifiers()
+	position := len(m.typed) - 1
+	remaining := []sequenceMatcherCandidate{}
+	for _, candidate := range m.candidates {
+		if position < len(candidate.steps) && candidate.steps[position].matches(event.Key, modifiers, m.leaderModifiers) {
+			remaining = append(remaining, candidate)
+		}
+	}
+	if len(remaining) == 0 {
+		return sequenceOutcomeMismatched, nil
+	}
+
+	m.typed = append(m.typed, remaining[0].keys[position])
+	m.candidates = remaining
+	for _, candidate := range remaining {
+		if len(candidate.steps) == position+1 {
+			return sequenceOutcomeFired, candidate.callback
+		}
+	}
+	return sequenceOutcomeAdvanced, nil
+}
+
+func (m *sequenceMatcher) pressedModifiers() keyboard.Modifier {
+	var modifiers keyboard.Modifier
+	for key, pressed := range m.pressed {
+		if !pressed {
+			continue
+		}
+		switch {
+		case modifierKeyMatchesRawEvent(keyboard.KeyCtrl, key):
+			modifiers |= keyboard.ModifierCtrl
+		case modifierKeyMatchesRawEvent(keyboard.KeyShift, key):
+			modifiers |= keyboard.ModifierShift
+		case modifierKeyMatchesRawEvent(keyboard.KeyAlt, key):
+			modifiers |= keyboard.ModifierAlt
+		case modifierKeyMatchesRawEvent(keyboard.KeySuper, key):
+			modifiers |= keyboard.ModifierSuper
+		}
+	}
+	return modifiers
+}
+
+// Progress lists the keys typed so far and one hint per distinct next key, in
+// candidate order.
+func (m *sequenceMatcher) Progress() SequenceProgress {
+	progress := SequenceProgress{Typed: append([]string(nil), m.typed...)}
+	position := len(m.typed) - 1
+	hintIndex := map[string]int{}
+	for _, candidate := range m.candidates {
+		if position >= len(candidate.keys) {
+			continue
+		}
+		key := candidate.keys[position]
+		normalized := strings.ToLower(strings.ReplaceAll(key, " ", ""))
+		if index, exists := hintIndex[normalized]; exists {
+			progress.Hints[index].Label = ""
+			progress.Hints[index].Partial = true
+			continue
+		}
+		hintIndex[normalized] = len(progress.Hints)
+		progress.Hints = append(progress.Hints, SequenceHint{
+			Key:     key,
+			Label:   candidate.label,
+			Partial: len(candidate.keys) > position+1,
+		})
+	}
+	return progress
+}
+
+type sequenceSession struct {
+	ctx        context.Context
+	matcher    *sequenceMatcher
+	onProgress func(SequenceProgress)
+	listener   keyboard.RawKeySubscription
+	timer      *time.Timer
+}
+
+var (
+	sequenceMu     sync.Mutex
+	activeSequence *sequenceSession
+)
+
+// StartSequence is called from a leader hotkey callback and waits for the keys
+// of one of the candidates. Matched keys are consumed where the platform
+// allows it, a key that matches no candidate ends the sequence and is passed
+// through, Escape cancels and each step times out after sequenceStepTimeout.
+// Only one sequence runs at a time; starting another replaces it. onProgress
+// receives every state change, ending with Done.
+func StartSequence(ctx context.Context, leader string, candidates []SequenceCandidate, onProgress func(SequenceProgress)) error {
+	matcher, err := newSequenceMatcher(leader, candidates)
+	if err != nil {
+		return err
+	}
+	CancelSequence()
+
+	session := &sequenceSession{ctx: ctx, matcher: matcher, onProgress: onProgress}
+	sequenceMu.Lock()
+	activeSequence = session
+	sequenceMu.Unlock()
+
+	listener, listenErr := addRawKeyListener(session.handleEvent)
+	sequenceMu.Lock()
+	if listenErr != nil {
+		if activeSequence == session {
+			activeSequence = nil
+		}
+		sequenceMu.Unlock()
+		return listenErr
+	}
+	if activeSequence != session {
+		sequenceMu.Unlock()
+		_ = listener.Close()
+		return nil
+	}
+	session.listener = listener
+	session.timer = time.AfterFunc(sequenceStepTimeout, func() {
+		session.finish(nil)
+	})
+	progress := matcher.Progress()
+	sequenceMu.Unlock()
+
+	util.GetLogger().Info(ctx, fmt.Sprintf("start hotkey sequence: %s", leader))
+	session.report(progress)
+	return nil
+}
+
+// CancelSequence ends the active sequence, if any, without firing a binding.
+func CancelSequence() {
+	sequenceMu.Lock()
+	session := activeSequence
+	sequenceMu.Unlock()
+	if session != nil {
+		session.finish(nil)
+	}
+}
+
+func (s *sequenceSession) handleEvent(event keyboard.RawKeyEvent) bool {
+	sequenceMu.Lock()
+	if activeSequence != s {
+		sequenceMu.Unlock()
+		return false
+	}
+	outcome, callback := s.matcher.HandleEvent(event)
+	switch outcome {
+	case sequenceOutcomeIgnored:
+		sequenceMu.Unlock()
+		return false
+	case sequenceOutcomeAdvanced:
+		if s.timer != nil {
+			s.timer.Reset(sequenceStepTimeout)
+		}
+		progress := s.matcher.Progress()
+		sequenceMu.Unlock()
+		s.report(progress)
+		return true
+	}
+	sequenceMu.Unlock()
+
+	if outcome == sequenceOutcomeFired {
+		util.GetLogger().Info(s.ctx, fmt.Sprintf("hotkey sequence fired: %s", JoinSequence(s.matcher.typed)))
+	}
+	s.finish(callback)
+	return outcome != sequenceOutcomeMismatched
+}
+
+// finish closes the session once. The raw listener is closed off the event
+// thread because some platforms reconcile their hook synchronously on close.
+func (s *sequenceSession) finish(callback func()) {
+	sequenceMu.Lock()
+	if activeSequence != s {
+		sequenceMu.Unlock()
+		return
+	}
+	activeSequence = nil
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	listener := s.listener
+	typed := append([]string(nil), s.matcher.typed...)
+	sequenceMu.Unlock()
+
+	if listener != nil {
+		util.Go(s.ctx, "close hotkey sequence listener", func() {
+			_ = listener.Close()
+		})
+	}
+	s.report(SequenceProgress{Typed: typed, Done: true})
+	if callback != nil {
+		util.Go(s.ctx, "hotkey sequence callback", callback)
+	}
+}
+
+func (s *sequenceSession) report(progress SequenceProgress) {
+	if s.onProgress != nil {
+		s.onProgress(progress)
+	}
+}
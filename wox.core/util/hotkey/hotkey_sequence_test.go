@@ -0,0 +1,170 @@
+package hotkey
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+	"wox/util/keyboard"
+)
+
+func TestSplitSequenceKeepsCommaKeyInsideChord(t *testing.T) {
+	cases := map[string][]string{
+		"ctrl+space":         {"ctrl+space"},
+		"ctrl+space, g, h":   {"ctrl+space", "g", "h"},
+		"ctrl+space,g":       {"ctrl+space", "g"},
+		"ctrl+,":             {"ctrl+,"},
+		"ctrl+space, ctrl+,": {"ctrl+space", "ctrl+,"},
+		"ctrl+space, ,":      {"ctrl+space", ","},
+		"  ":                 {},
+	}
+	for input, want := range cases {
+		if got := SplitSequence(input); !reflect.DeepEqual(got, want) {
+			t.Fatalf("SplitSequence(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestValidateSequenceRejectsSpecialSteps(t *testing.T) {
+	if err := ValidateSequence("ctrl+space, g, shift+h"); err != nil {
+		t.Fatalf("expected valid sequence, got %v", err)
+	}
+	for _, invalid := range []string{"ctrl+space", "ctrl+space, ctrl+ctrl", "ctrl+space, capslock+g", "ctrl+space, escape", "ctrl+space, a, b, c, d"} {
+		if err := ValidateSequence(invalid); err == nil {
+			t.Fatalf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestSequenceMatcherFiresAfterLastStep(t *testing.T) {
+	fired := ""
+	matcher, err := newSequenceMatcher("ctrl+space", []SequenceCandidate{
+		{Steps: []string{"g", "h"}, Label: "GitHub", Callback: func() { fired = "github" }},
+		{Steps: []string{"g", "l"}, Label: "GitLab", Callback: func() { fired = "gitlab" }},
+		{Steps: []string{"t"}, Label: "Translate", Callback: func() { fired = "translate" }},
+	})
+	if err != nil {
+		t.Fatalf("new matcher: %v", err)
+	}
+
+	progress := matcher.Progress()
+	want := []SequenceHint{{Key: "g", Partial: true}, {Key: "t", Label: "Translate"}}
+	if !reflect.DeepEqual(progress.Hints, want) {
+		t.Fatalf("initial hints = %+v, want %+v", progress.Hints, want)
+	}
+
+	// The leader modifier is usually still held when the first key arrives.
+	outcome, _ := matcher.HandleEvent(sequenceKeyEvent(keyboard.KeyG, keyboard.ModifierCtrl))
+	if outcome != sequenceOutcomeAdvanced {
+		t.Fatalf("outcome after g = %v, want advanced", outcome)
+	}
+	progress = matcher.Progress()
+	if !reflect.DeepEqual(progress.Typed, []string{"ctrl+space", "g"}) || len(progress.Hints) != 2 || progress.Hints[0].Label != "GitHub" {
+		t.Fatalf("progress after g = %+v", progress)
+	}
+
+	outcome, callback := matcher.HandleEvent(sequenceKeyEvent(keyboard.KeyL, 0))
+	if outcome != sequenceOutcomeFired || callback == nil {
+		t.Fatalf("outcome after l = %v, want fired", outcome)
+	}
+	callback()
+	if fired != "gitlab" {
+		t.Fatalf("fired %q, want gitlab", fired)
+	}
+}
+
+func TestSequenceMatcherEndsOnMismatchAndEscape(t *testing.T) {
+	newMatcher := func() *sequenceMatcher {
+		matcher, err := newSequenceMatcher("ctrl+space", []SequenceCandidate{{Steps: []string{"shift+g"}, Callback: func() {}}})
+		if err != nil {
+			t.Fatalf("new matcher: %v", err)
+		}
+		return matcher
+	}
+
+	matcher := newMatcher()
+	if outcome, _ := matcher.HandleEvent(sequenceKeyEvent(keyboard.KeyG, 0)); outcome != sequenceOutcomeMismatched {
+		t.Fatalf("bare g outcome = %v, want mismatched", outcome)
+	}
+
+	matcher = newMatcher()
+	if outcome, _ := matcher.HandleEvent(keyboard.RawKeyEvent{Type: keyboard.EventTypeKeyDown, Key: keyboard.KeyLeftShift}); outcome != sequenceOutcomeIgnored {
+		t.Fatalf("modifier outcome = %v, want ignored", outcome)
+	}
+	if outcome, _ := matcher.HandleEvent(sequenceKeyEvent(keyboard.KeyG, 0)); outcome != sequenceOutcomeFired {
+		t.Fatalf("shift+g outcome = %v, want fired from tracked shift", outcome)
+	}
+
+	matcher = newMatcher()
+	if outcome, _ := matcher.HandleEvent(sequenceKeyEvent(keyboard.KeyEscape, 0)); outcome != sequenceOutcomeCancelled {
+		t.Fatalf("escape outcome = %v, want cancelled", outcome)
+	}
+}
+
+func TestStartSequenceTimesOutWithoutFiring(t *testing.T) {
+	restoreListener := replaceRawKeyListenerForTest(t, func(handler keyboard.RawKeyHandler) (keyboard.RawKeySubscription, error) {
+		return noopRawKeySubscription{}, nil
+	})
+	defer restoreListener()
+	previousTimeout := sequenceStepTimeout
+	sequenceStepTimeout = 20 * time.Millisecond
+	defer func() { sequenceStepTimeout = previousTimeout }()
+
+	var mu sync.Mutex
+	reports := []SequenceProgress{}
+	done := make(chan struct{})
+	err := StartSequence(t.Context(), "ctrl+space", []SequenceCandidate{{Steps: []string{"g"}, Callback: func() {
+		t.Errorf("sequence callback fired after timeout")
+	}}}, func(progress SequenceProgress) {
+		mu.Lock()
+		reports = append(reports, progress)
+		mu.Unlock()
+		if progress.Done {
+			close(done)
+		}
+	})
+	if err != nil {
+		t.Fatalf("start sequence: %v", err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("expected sequence to time out")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reports) != 2 || len(reports[0].Hints) != 1 || reports[0].Done {
+		t.Fatalf("reports = %+v, want hints then done", reports)
+	}
+}
+
+func TestRecordingSessionExtendsComboIntoSequence(t *testing.T) {
+	state := newRecordingRawState(map[hotkeyKind]bool{hotkeyKindNormalCombo: true, hotkeyKindSequence: true}, nil)
+	recorded := []recordedHotkey{}
+	state.onRecorded = func(result recordedHotkey) {
+		recorded = append(recorded, result)
+	}
+
+	state.HandleEvent(keyboard.RawKeyEvent{Type: keyboard.EventTypeKeyDown, Key: keyboard.KeyLeftCtrl})
+	state.HandleEvent(keyboard.RawKeyEvent{Type: keyboard.EventTypeKeyDown, Key: keyboard.KeySpace})
+	state.HandleEvent(keyboard.RawKeyEvent{Type: keyboard.EventTypeKeyUp, Key: keyboard.KeyLeftCtrl})
+	if !state.HandleEvent(keyboard.RawKeyEvent{Type: keyboard.EventTypeKeyDown, Key: keyboard.KeyG}) {
+		t.Fatalf("expected sequence step to be consumed")
+	}
+	state.HandleEvent(keyboard.RawKeyEvent{Type: keyboard.EventTypeKeyDown, Key: keyboard.KeyH})
+	state.HandleEvent(keyboard.RawKeyEvent{Type: keyboard.EventTypeKeyDown, Key: keyboard.KeyEscape})
+
+	want := []recordedHotkey{
+		{Hotkey: "ctrl+space", Kind: hotkeyKindNormalCombo},
+		{Hotkey: "ctrl+space, g", Kind: hotkeyKindSequence},
+		{Hotkey: "ctrl+space, g, h", Kind: hotkeyKindSequence},
+	}
+	if !reflect.DeepEqual(recorded, want) {
+		t.Fatalf("recorded = %+v, want %+v", recorded, want)
+	}
+}
+
+func sequenceKeyEvent(key keyboard.Key, modifiers keyboard.Modifier) keyboard.RawKeyEvent {
+	return keyboard.RawKeyEvent{Type: keyboard.EventTypeKeyDown, Key: key, Modifiers: modifiers}
+}
@@ -0,0 +1,257 @@
+package recordingexport
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+type Format string
+
+const (
+	FormatMP4  Format = "mp4"
+	FormatGIF  Format = "gif"
+	FormatWebP Format = "webp"
+)
+
+// ErrFFmpegUnavailable is returned for exports that only ffmpeg can do, like
+// anything that reads an MP4 or writes WebP.
+var ErrFFmpegUnavailable = errors.New("ffmpeg is unavailable")
+
+var errTrimEndReached = errors.New("trim end reached")
+
+// FormatFromPath maps a file extension to its export format.
+func FormatFromPath(path string) (Format, bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".mp4":
+		return FormatMP4, true
+	case ".gif":
+		return FormatGIF, true
+	case ".webp":
+		return FormatWebP, true
+	default:
+		return "", false
+	}
+}
+
+// Options describe one export of a finished recording.
+type Options struct {
+	Format Format
+	// Start and End trim the source, a zero End keeps everything after Start
+	Start time.Duration
+	End   time.Duration
+	// FPS and MaxWidth downsample the output, zero keeps the source value
+	FPS      int
+	MaxWidth int
+}
+
+// DefaultOptions are the settings used when a recording is saved in another
+// format without asking for details. Animated formats are downsampled because
+// a full resolution 30fps GIF of a screen is usually too big to share.
+func DefaultOptions(format Format) Options {
+	switch format {
+	case FormatGIF:
+		return Options{Format: format, FPS: 15, MaxWidth: 960}
+	case FormatWebP:
+		return Options{Format: format, FPS: 20, MaxWidth: 1280}
+	default:
+		return Options{Format: FormatMP4}
+	}
+}
+
+func (o Options) validate() error {
+	switch o.Format {
+	case FormatMP4, FormatGIF, FormatWebP:
+	default:
+		return fmt.Errorf("unsupported export format: %s", o.Format)
+	}
+	if o.Start < 0 || o.End < 0 {
+		return errors.New("trim range must not be negative")
+	}
+	if o.End > 0 && o.End <= o.Start {
+		return errors.New("trim end must be after trim start")
+	}
+	if o.FPS < 0 || o.FPS > 60 {
+		return fmt.Errorf("unsupported export fps: %d", o.FPS)
+	}
+	if o.MaxWidth < 0 {
+		return fmt.Errorf("unsupported export width: %d", o.MaxWidth)
+	}
+	return nil
+}
+
+// Export writes a trimmed and downsampled copy of the recording at source to
+// target. ffmpeg is used when it is available, otherwise GIF sources can still
+// be exported to GIF by the pure Go pipeline. target is replaced atomically so
+// a failed export never leaves a partial file behind.
+func Export(ctx context.Context, source, target string, options Options) error {
+	if err := options.validate(); err != nil {
+		return err
+	}
+	if source == "" || target == "" {
+		return errors.New("export source and target paths are required")
+	}
+	sourcePath, sourceErr := filepath.Abs(source)
+	targetPath, targetErr := filepath.Abs(target)
+	if sourceErr == nil && targetErr == nil {
+		samePath := filepath.Clean(sourcePath) == filepath.Clean(targetPath)
+		if runtime.GOOS == "windows" {
+			samePath = strings.EqualFold(filepath.Clean(sourcePath), filepath.Clean(targetPath))
+		}
+		if samePath {
+			return errors.New("export target must differ from the source recording")
+		}
+	}
+	if _, err := os.Stat(source); err != nil {
+		return fmt.Errorf("failed to read source recording: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	sibling, err := os.CreateTemp(filepath.Dir(target), ".wox-recording-export-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	siblingPath := sibling.Name()
+	defer os.Remove(siblingPath)
+
+	sourceFormat, _ := FormatFromPath(source)
+	ffmpegPath, ffmpegErr := FFmpegPath()
+	switch {
+	case ffmpegErr == nil:
+		sibling.Close()
+		err = exportWithFFmpeg(ctx, ffmpegPath, source, siblingPath, options)
+	case sourceFormat == FormatGIF && options.Format == FormatGIF:
+		err = exportGIF(ctx, source, sibling, options)
+		if closeErr := sibling.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to flush export file: %w", closeErr)
+		}
+	default:
+		sibling.Close()
+		err = ErrFFmpegUnavailable
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.Rename(siblingPath, target); err != nil {
+		return fmt.Errorf("failed to publish export file: %w", err)
+	}
+	return nil
+}
+
+func exportWithFFmpeg(ctx context.Context, ffmpegPath, source, target string, options Options) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, ffmpegPath, ffmpegArguments(source, target, options)...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ffmpeg export failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// exportGIF is the ffmpeg free path, frames outside of the trim range are
+// dropped, frames that fall into an already written fps slot only extend the
+// delay of that slot.
+func exportGIF(ctx context.Context, source string, target *os.File, options Options) error {
+	input, err := os.Open(source)
+	if err != nil {
+		return fmt.Errorf("failed to open source recording: %w", err)
+	}
+	defer input.Close()
+
+	var writer *GIFWriter
+	var pending *image.Paletted
+	var pendingDelay time.Duration
+	pendingSlot := int64(-1)
+	elapsed := time.Duration(0)
+	readErr := ReadGIF(input, func(canvas *image.RGBA, delay time.Duration) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		frameStart, frameEnd := elapsed, elapsed+delay
+		elapsed = frameEnd
+		if options.End > 0 && frameStart > options.End {
+			return errTrimEndReached
+		}
+		visibleStart := max(frameStart, options.Start)
+		visibleEnd := frameEnd
+		if options.End > 0 {
+			visibleEnd = min(frameEnd, options.End)
+		}
+		// zero delay frames are still shown for one render tick by viewers,
+		// keep them when they are inside the range
+		if visibleEnd < visibleStart || (visibleEnd == visibleStart && delay > 0) {
+			return nil
+		}
+		visible := visibleEnd - visibleStart
+
+		slot := int64(-1)
+		if options.FPS > 0 {
+			slot = int64((visibleStart - options.Start) * time.Duration(options.FPS) / time.Second)
+		}
+		if pending != nil && slot >= 0 && slot == pendingSlot {
+			pendingDelay += visible
+			return nil
+		}
+
+		frame := Quantize(ScaleFrame(canvas, options.MaxWidth))
+		if writer == nil {
+			created, err := NewGIFWriter(target, frame.Bounds().Dx(), frame.Bounds().Dy())
+			if err != nil {
+				return err
+			}
+			writer = created
+		}
+		if pending != nil {
+			if err := writer.WriteFrame(pending, pendingDelay); err != nil {
+				return err
+			}
+		}
+		pending = frame
+		pendingDelay = visible
+		pendingSlot = slot
+		return nil
+	})
+	if readErr != nil && !errors.Is(readErr, errTrimEndReached) {
+		return fmt.Errorf("failed to export gif: %w", readErr)
+	}
+	if writer == nil || pending == nil {
+		return errors.New("trim range doesn't contain any frame")
+	}
+	if err := writer.WriteFrame(pending, pendingDelay); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// scaledSize keeps the aspect ratio and never upscales.
+func scaledSize(width, height, maxWidth int) (int, int) {
+	if maxWidth <= 0 || width <= maxWidth {
+		return width, height
+	}
+	return maxWidth, max(1, height*maxWidth/width)
+}
+
+// ScaleFrame downsamples frame to at most maxWidth. frame itself is returned
+// when no scaling is needed, so the result must not be kept across frames.
+func ScaleFrame(frame *image.RGBA, maxWidth int) *image.RGBA {
+	width, height := scaledSize(frame.Bounds().Dx(), frame.Bounds().Dy(), maxWidth)
+	if frame.Bounds().Dx() == width && frame.Bounds().Dy() == height {
+		return frame
+	}
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	xdraw.ApproxBiLinear.Scale(scaled, scaled.Bounds(), frame, frame.Bounds(), xdraw.Src, nil)
+	return scaled
+}
@@ -0,0 +1,125 @@
+package recordingexport
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestGIF(t *testing.T, path string, colors []color.RGBA, delay time.Duration) {
+	t.Helper()
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	writer, err := NewGIFWriter(file, 40, 20)
+	require.NoError(t, err)
+	for _, c := range colors {
+		frame := image.NewRGBA(image.Rect(0, 0, 40, 20))
+		for i := 0; i < len(frame.Pix); i += 4 {
+			frame.Pix[i], frame.Pix[i+1], frame.Pix[i+2], frame.Pix[i+3] = c.R, c.G, c.B, c.A
+		}
+		require.NoError(t, writer.WriteFrame(Quantize(frame), delay))
+	}
+	require.NoError(t, writer.Close())
+}
+
+func TestGIFWriterOutputDecodesWithStandardLibrary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clip.gif")
+	colors := []color.RGBA{{R: 255, A: 255}, {G: 255, A: 255}, {B: 255, A: 255}}
+	writeTestGIF(t, path, colors, 100*time.Millisecond)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	decoded, err := gif.DecodeAll(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, decoded.Image, 3)
+	assert.Equal(t, 0, decoded.LoopCount)
+	assert.Equal(t, []int{10, 10, 10}, decoded.Delay)
+	for i, frame := range decoded.Image {
+		r, g, b, _ := frame.At(5, 5).RGBA()
+		assert.Equal(t, colors[i], color.RGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: 255})
+	}
+
+	frames := 0
+	require.NoError(t, ReadGIF(bytes.NewReader(data), func(canvas *image.RGBA, delay time.Duration) error {
+		assert.Equal(t, 100*time.Millisecond, delay)
+		assert.Equal(t, colors[frames], canvas.RGBAAt(39, 19))
+		frames++
+		return nil
+	}))
+	assert.Equal(t, 3, frames)
+}
+
+func TestGIFWriterKeepsFractionalDelays(t *testing.T) {
+	writer := &GIFWriter{}
+	total := 0
+	for i := 0; i < 15; i++ {
+		total += int(writer.centiseconds(time.Second / 15))
+	}
+	assert.Equal(t, 100, total, "15 frames at 15fps must last one second")
+}
+
+func TestExportGIFTrimsAndDownsamples(t *testing.T) {
+	root := t.TempDir()
+	source := filepath.Join(root, "source.gif")
+	colors := make([]color.RGBA, 10)
+	for i := range colors {
+		colors[i] = color.RGBA{R: uint8(i * 25), G: 80, B: 160, A: 255}
+	}
+	// 10 frames of 100ms, one second in total
+	writeTestGIF(t, source, colors, 100*time.Millisecond)
+
+	target, err := os.Create(filepath.Join(root, "target.gif"))
+	require.NoError(t, err)
+	err = exportGIF(context.Background(), source, target, Options{
+		Format: FormatGIF, Start: 200 * time.Millisecond, End: 800 * time.Millisecond, FPS: 5, MaxWidth: 20,
+	})
+	require.NoError(t, err)
+	require.NoError(t, target.Close())
+
+	data, err := os.ReadFile(target.Name())
+	require.NoError(t, err)
+	decoded, err := gif.DecodeAll(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, 20, decoded.Config.Width)
+	assert.Equal(t, 10, decoded.Config.Height)
+	// 600ms at 5fps is three 200ms frames
+	assert.Equal(t, []int{20, 20, 20}, decoded.Delay)
+	r, _, _, _ := decoded.Image[0].At(1, 1).RGBA()
+	assert.InDelta(t, 50, int(r>>8), 8, "first frame must come from the trim start")
+}
+
+func TestExportRejectsInvalidOptions(t *testing.T) {
+	ctx := context.Background()
+	assert.Error(t, Export(ctx, "a.mp4", "b.gif", Options{Format: "avi"}))
+	assert.Error(t, Export(ctx, "a.mp4", "b.gif", Options{Format: FormatGIF, Start: time.Second, End: time.Second}))
+	assert.Error(t, Export(ctx, "a.gif", "a.gif", Options{Format: FormatGIF}))
+}
+
+func TestFFmpegArgumentsBuildPaletteGIF(t *testing.T) {
+	arguments := ffmpegArguments("in.mp4", "out.tmp", Options{Format: FormatGIF, Start: 1500 * time.Millisecond, End: 4 * time.Second, FPS: 12, MaxWidth: 640})
+	joined := strings.Join(arguments, " ")
+
+	assert.Contains(t, joined, "-ss 1.500 -to 4.000 -i in.mp4")
+	assert.Contains(t, joined, "fps=12,scale='min(640,iw)':-2:flags=lanczos,split[a][b];[a]palettegen")
+	assert.Contains(t, joined, "paletteuse")
+	assert.Contains(t, joined, "-loop 0 -f gif")
+	assert.Equal(t, "out.tmp", arguments[len(arguments)-1])
+
+	webp := strings.Join(ffmpegArguments("in.mp4", "out.tmp", Options{Format: FormatWebP}), " ")
+	assert.NotContains(t, webp, "-ss")
+	assert.NotContains(t, webp, "-vf")
+	assert.Contains(t, webp, "-c:v libwebp")
+	assert.Contains(t, webp, "-f webp")
+}
@@ -0,0 +1,82 @@
+package recordingexport
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+	"wox/util"
+)
+
+// FFmpegPath prefers the packaged, version-pinned runtime and retains a development fallback.
+func FFmpegPath() (string, error) {
+	executable := "ffmpeg"
+	if runtime.GOOS == "windows" {
+		executable += ".exe"
+	}
+	dataRoot := util.GetLocation().GetWoxDataDirectory()
+	if dataRoot != "" {
+		packaged := filepath.Join(util.GetLocation().GetOthersDirectory(), "recording", runtime.GOOS+"-"+runtime.GOARCH, executable)
+		if util.IsFileExists(packaged) {
+			if runtime.GOOS != "windows" {
+				if err := os.Chmod(packaged, 0o755); err != nil {
+					return "", fmt.Errorf("make packaged recording runtime executable: %w", err)
+				}
+			}
+			return packaged, nil
+		}
+	}
+	return exec.LookPath("ffmpeg")
+}
+
+// ffmpegArguments builds one ffmpeg run that trims, downsamples and encodes
+// source into target. The output muxer is always explicit because target is
+// a sibling temp file without the final extension.
+func ffmpegArguments(source, target string, options Options) []string {
+	arguments := []string{"-hide_banner", "-loglevel", "error"}
+	// input seeking is fast and frame accurate for re-encoded outputs
+	if options.Start > 0 {
+		arguments = append(arguments, "-ss", formatSeconds(options.Start))
+	}
+	if options.End > 0 {
+		arguments = append(arguments, "-to", formatSeconds(options.End))
+	}
+	arguments = append(arguments, "-i", source, "-an")
+
+	filters := []string{}
+	if options.FPS > 0 {
+		filters = append(filters, "fps="+strconv.Itoa(options.FPS))
+	}
+	if options.MaxWidth > 0 {
+		// never upscale, -2 keeps the height even for yuv420p
+		filters = append(filters, fmt.Sprintf("scale='min(%d,iw)':-2:flags=lanczos", options.MaxWidth))
+	}
+
+	switch options.Format {
+	case FormatGIF:
+		// generating the palette from the clip itself instead of the fixed web
+		// palette is what keeps UI gradients and anti-aliased text readable
+		graph := strings.Join(append(filters, "split[a][b]"), ",") +
+			";[a]palettegen=stats_mode=diff[p];[b][p]paletteuse=dither=bayer:bayer_scale=5:diff_mode=rectangle"
+		arguments = append(arguments, "-filter_complex", graph, "-loop", "0", "-f", "gif")
+	case FormatWebP:
+		if len(filters) > 0 {
+			arguments = append(arguments, "-vf", strings.Join(filters, ","))
+		}
+		arguments = append(arguments, "-c:v", "libwebp", "-lossless", "0", "-q:v", "75", "-compression_level", "4", "-loop", "0", "-f", "webp")
+	default:
+		if len(filters) > 0 {
+			arguments = append(arguments, "-vf", strings.Join(filters, ","))
+		}
+		arguments = append(arguments, "-c:v", "libx264", "-preset", "veryfast", "-crf", "23", "-pix_fmt", "yuv420p", "-movflags", "+faststart", "-f", "mp4")
+	}
+	return append(arguments, "-y", target)
+}
+
+func formatSeconds(duration time.Duration) string {
+	return strconv.FormatFloat(duration.Seconds(), 'f', 3, 64)
+}
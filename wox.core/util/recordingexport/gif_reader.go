@@ -0,0 +1,286 @@
+package recordingexport
+
+import (
+	"bufio"
+	"compress/lzw"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"time"
+)
+
+// ReadGIF decodes a GIF one frame at a time and calls onFrame with the fully
+// composited canvas and how long it is shown. image/gif.DecodeAll keeps every
+// frame in memory, a long recording can easily need gigabytes that way.
+//
+// The canvas is reused between calls, onFrame must copy it if it keeps it.
+func ReadGIF(r io.Reader, onFrame func(canvas *image.RGBA, delay time.Duration) error) error {
+	reader := bufio.NewReader(r)
+	var header [13]byte
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		return fmt.Errorf("failed to read gif header: %w", err)
+	}
+	if string(header[:6]) != "GIF89a" && string(header[:6]) != "GIF87a" {
+		return errors.New("not a gif file")
+	}
+	width := int(binary.LittleEndian.Uint16(header[6:8]))
+	height := int(binary.LittleEndian.Uint16(header[8:10]))
+	if width == 0 || height == 0 {
+		return errors.New("gif has an empty canvas")
+	}
+
+	var globalPalette color.Palette
+	if header[10]&0x80 != 0 {
+		palette, err := readGIFColorTable(reader, int(header[10]&0x07))
+		if err != nil {
+			return err
+		}
+		globalPalette = palette
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, width, height))
+	var previous *image.RGBA
+	delay := time.Duration(0)
+	disposal := byte(0)
+	transparentIndex := -1
+
+	for {
+		blockType, err := reader.ReadByte()
+		if err != nil {
+			return fmt.Errorf("failed to read gif block: %w", err)
+		}
+
+		switch blockType {
+		case 0x21:
+			label, err := reader.ReadByte()
+			if err != nil {
+				return fmt.Errorf("failed to read gif extension: %w", err)
+			}
+			if label == 0xf9 {
+				var control [6]byte
+				if _, err := io.ReadFull(reader, control[:]); err != nil {
+					return fmt.Errorf("failed to read gif graphic control: %w", err)
+				}
+				disposal = (control[1] >> 2) & 0x07
+				delay = time.Duration(binary.LittleEndian.Uint16(control[2:4])) * 10 * time.Millisecond
+				transparentIndex = -1
+				if control[1]&0x01 != 0 {
+					transparentIndex = int(control[4])
+				}
+				continue
+			}
+			if err := skipGIFSubBlocks(reader); err != nil {
+				return err
+			}
+		case 0x2c:
+			frame, err := readGIFImage(reader, globalPalette)
+			if err != nil {
+				return err
+			}
+			if disposal == 3 {
+				previous = cloneRGBA(previous, canvas)
+			}
+			drawGIFFrame(canvas, frame, transparentIndex)
+			if err := onFrame(canvas, delay); err != nil {
+				return err
+			}
+
+			// disposal applies after the frame was shown
+			switch disposal {
+			case 2:
+				clearRect(canvas, frame.Bounds())
+			case 3:
+				if previous != nil {
+					copy(canvas.Pix, previous.Pix)
+				}
+			}
+			delay = 0
+			disposal = 0
+			transparentIndex = -1
+		case 0x3b:
+			return nil
+		default:
+			return fmt.Errorf("unknown gif block: 0x%02x", blockType)
+		}
+	}
+}
+
+func readGIFColorTable(reader *bufio.Reader, sizeBits int) (color.Palette, error) {
+	count := 1 << (sizeBits + 1)
+	table := make([]byte, 3*count)
+	if _, err := io.ReadFull(reader, table); err != nil {
+		return nil, fmt.Errorf("failed to read gif color table: %w", err)
+	}
+	palette := make(color.Palette, count)
+	for i := range palette {
+		palette[i] = color.RGBA{R: table[3*i], G: table[3*i+1], B: table[3*i+2], A: 0xff}
+	}
+	return palette, nil
+}
+
+func readGIFImage(reader *bufio.Reader, globalPalette color.Palette) (*image.Paletted, error) {
+	var descriptor [9]byte
+	if _, err := io.ReadFull(reader, descriptor[:]); err != nil {
+		return nil, fmt.Errorf("failed to read gif image descriptor: %w", err)
+	}
+	left := int(binary.LittleEndian.Uint16(descriptor[0:2]))
+	top := int(binary.LittleEndian.Uint16(descriptor[2:4]))
+	width := int(binary.LittleEndian.Uint16(descriptor[4:6]))
+	height := int(binary.LittleEndian.Uint16(descriptor[6:8]))
+	flags := descriptor[8]
+
+	palette := globalPalette
+	if flags&0x80 != 0 {
+		localPalette, err := readGIFColorTable(reader, int(flags&0x07))
+		if err != nil {
+			return nil, err
+		}
+		palette = localPalette
+	}
+	if len(palette) == 0 {
+		return nil, errors.New("gif frame has no color table")
+	}
+
+	litWidth, err := reader.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gif lzw code size: %w", err)
+	}
+	if litWidth < 2 || litWidth > 8 {
+		return nil, fmt.Errorf("invalid gif lzw code size: %d", litWidth)
+	}
+
+	frame := image.NewPaletted(image.Rect(left, top, left+width, top+height), palette)
+	blocks := &gifBlockReader{r: reader}
+	decompressor := lzw.NewReader(blocks, lzw.LSB, int(litWidth))
+	_, readErr := io.ReadFull(decompressor, frame.Pix)
+	decompressor.Close()
+	if readErr != nil {
+		return nil, fmt.Errorf("failed to decode gif frame: %w", readErr)
+	}
+	// encoders may leave padding or the end code in the last sub-block
+	if err := blocks.drain(); err != nil {
+		return nil, err
+	}
+
+	if flags&0x40 != 0 {
+		deinterlaceGIF(frame)
+	}
+	for _, index := range frame.Pix {
+		if int(index) >= len(palette) {
+			return nil, errors.New("gif frame uses a color outside of its palette")
+		}
+	}
+	return frame, nil
+}
+
+func drawGIFFrame(canvas *image.RGBA, frame *image.Paletted, transparentIndex int) {
+	bounds := frame.Bounds().Intersect(canvas.Bounds())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			index := frame.ColorIndexAt(x, y)
+			if int(index) == transparentIndex {
+				continue
+			}
+			r, g, b, _ := frame.Palette[index].RGBA()
+			offset := canvas.PixOffset(x, y)
+			canvas.Pix[offset+0] = byte(r >> 8)
+			canvas.Pix[offset+1] = byte(g >> 8)
+			canvas.Pix[offset+2] = byte(b >> 8)
+			canvas.Pix[offset+3] = 0xff
+		}
+	}
+}
+
+func clearRect(canvas *image.RGBA, rect image.Rectangle) {
+	rect = rect.Intersect(canvas.Bounds())
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		offset := canvas.PixOffset(rect.Min.X, y)
+		clear(canvas.Pix[offset : offset+4*rect.Dx()])
+	}
+}
+
+func cloneRGBA(target *image.RGBA, source *image.RGBA) *image.RGBA {
+	if target == nil || target.Bounds() != source.Bounds() {
+		target = image.NewRGBA(source.Bounds())
+	}
+	copy(target.Pix, source.Pix)
+	return target
+}
+
+func deinterlaceGIF(frame *image.Paletted) {
+	width, height := frame.Rect.Dx(), frame.Rect.Dy()
+	interlaced := make([]byte, len(frame.Pix))
+	copy(interlaced, frame.Pix)
+	row := 0
+	for _, pass := range []struct{ start, step int }{{0, 8}, {4, 8}, {2, 4}, {1, 2}} {
+		for y := pass.start; y < height; y += pass.step {
+			copy(frame.Pix[y*frame.Stride:y*frame.Stride+width], interlaced[row*width:(row+1)*width])
+			row++
+		}
+	}
+}
+
+func skipGIFSubBlocks(reader *bufio.Reader) error {
+	for {
+		size, err := reader.ReadByte()
+		if err != nil {
+			return fmt.Errorf("failed to read gif sub-block: %w", err)
+		}
+		if size == 0 {
+			return nil
+		}
+		if _, err := reader.Discard(int(size)); err != nil {
+			return fmt.Errorf("failed to skip gif sub-block: %w", err)
+		}
+	}
+}
+
+// gifBlockReader joins the sub-blocks of one image back into the LZW stream.
+type gifBlockReader struct {
+	r         *bufio.Reader
+	remaining int
+	done      bool
+}
+
+func (b *gifBlockReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	for b.remaining == 0 {
+		if b.done {
+			return 0, io.EOF
+		}
+		size, err := b.r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if size == 0 {
+			b.done = true
+			return 0, io.EOF
+		}
+		b.remaining = int(size)
+	}
+	if len(p) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.r.Read(p)
+	b.remaining -= n
+	return n, err
+}
+
+func (b *gifBlockReader) drain() error {
+	if b.remaining > 0 {
+		if _, err := b.r.Discard(b.remaining); err != nil {
+			return fmt.Errorf("failed to skip gif frame data: %w", err)
+		}
+		b.remaining = 0
+	}
+	if b.done {
+		return nil
+	}
+	b.done = true
+	return skipGIFSubBlocks(b.r)
+}
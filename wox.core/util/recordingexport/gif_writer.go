@@ -0,0 +1,192 @@
+package recordingexport
+
+import (
+	"bufio"
+	"compress/lzw"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"time"
+)
+
+// gifMinFrameDelay is the shortest delay browsers honour, smaller values are
+// clamped to 100ms by most viewers which makes short clips play in slow motion.
+const gifMinFrameDelay = 2
+
+// GIFWriter streams an endlessly looping GIF89a one frame at a time.
+// image/gif.EncodeAll needs every frame in memory, which is not an option for
+// a recording that can run for minutes.
+type GIFWriter struct {
+	w      *bufio.Writer
+	width  int
+	height int
+	// remainder keeps the sub-centisecond part of earlier delays so rounding
+	// doesn't make long clips drift from their real duration
+	remainder time.Duration
+	err       error
+}
+
+// NewGIFWriter writes the GIF header and loop extension for a width x height canvas.
+func NewGIFWriter(w io.Writer, width, height int) (*GIFWriter, error) {
+	if width <= 0 || height <= 0 || width > 0xffff || height > 0xffff {
+		return nil, fmt.Errorf("invalid gif size: %dx%d", width, height)
+	}
+
+	writer := &GIFWriter{w: bufio.NewWriter(w), width: width, height: height}
+	writer.write([]byte("GIF89a"))
+	writer.writeUint16(uint16(width))
+	writer.writeUint16(uint16(height))
+	// no global color table, every frame carries its own optimized palette
+	writer.write([]byte{0x00, 0x00, 0x00})
+	// NETSCAPE2.0 application extension, loop count 0 means forever
+	writer.write([]byte{0x21, 0xff, 0x0b})
+	writer.write([]byte("NETSCAPE2.0"))
+	writer.write([]byte{0x03, 0x01, 0x00, 0x00, 0x00})
+	if writer.err != nil {
+		return nil, writer.err
+	}
+	return writer, nil
+}
+
+// WriteFrame appends one frame shown for delay. Frames smaller than the canvas
+// are placed at their bounds origin.
+func (g *GIFWriter) WriteFrame(frame *image.Paletted, delay time.Duration) error {
+	if g.err != nil {
+		return g.err
+	}
+	bounds := frame.Bounds()
+	if bounds.Empty() || !bounds.In(image.Rect(0, 0, g.width, g.height)) {
+		return fmt.Errorf("gif frame bounds %v are outside of the %dx%d canvas", bounds, g.width, g.height)
+	}
+	if len(frame.Palette) == 0 || len(frame.Palette) > 256 {
+		return fmt.Errorf("gif frame palette must have 1 to 256 colors, got %d", len(frame.Palette))
+	}
+
+	// graphic control extension: keep the previous frame (disposal 1), no transparency
+	g.write([]byte{0x21, 0xf9, 0x04, 0x04})
+	g.writeUint16(g.centiseconds(delay))
+	g.write([]byte{0x00, 0x00})
+
+	paletteBits := 1
+	for 1<<paletteBits < len(frame.Palette) {
+		paletteBits++
+	}
+	g.write([]byte{0x2c})
+	g.writeUint16(uint16(bounds.Min.X))
+	g.writeUint16(uint16(bounds.Min.Y))
+	g.writeUint16(uint16(bounds.Dx()))
+	g.writeUint16(uint16(bounds.Dy()))
+	g.write([]byte{0x80 | byte(paletteBits-1)})
+
+	colorTable := make([]byte, 3*(1<<paletteBits))
+	for i, c := range frame.Palette {
+		r, gr, b, _ := c.RGBA()
+		colorTable[3*i+0] = byte(r >> 8)
+		colorTable[3*i+1] = byte(gr >> 8)
+		colorTable[3*i+2] = byte(b >> 8)
+	}
+	g.write(colorTable)
+
+	// LZW needs at least 2 bits even for 2 color palettes
+	litWidth := max(2, paletteBits)
+	g.write([]byte{byte(litWidth)})
+	if g.err != nil {
+		return g.err
+	}
+	blocks := &gifBlockWriter{w: g.w}
+	compressor := lzw.NewWriter(blocks, lzw.LSB, litWidth)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		offset := frame.PixOffset(bounds.Min.X, y)
+		if _, err := compressor.Write(frame.Pix[offset : offset+bounds.Dx()]); err != nil {
+			g.err = err
+			return err
+		}
+	}
+	if err := compressor.Close(); err != nil {
+		g.err = err
+		return err
+	}
+	if err := blocks.close(); err != nil {
+		g.err = err
+		return err
+	}
+	return g.err
+}
+
+// Close writes the GIF trailer and flushes buffered data, the underlying
+// writer is left open.
+func (g *GIFWriter) Close() error {
+	if g.err != nil {
+		return g.err
+	}
+	g.write([]byte{0x3b})
+	if g.err != nil {
+		return g.err
+	}
+	g.err = errors.New("gif writer is closed")
+	return g.w.Flush()
+}
+
+func (g *GIFWriter) centiseconds(delay time.Duration) uint16 {
+	total := delay + g.remainder
+	value := int64((total + 5*time.Millisecond) / (10 * time.Millisecond))
+	value = min(max(value, gifMinFrameDelay), 0xffff)
+	// clamped frames don't carry their difference into the next one
+	g.remainder = max(total-time.Duration(value)*10*time.Millisecond, -5*time.Millisecond)
+	return uint16(value)
+}
+
+func (g *GIFWriter) write(data []byte) {
+	if g.err != nil {
+		return
+	}
+	_, g.err = g.w.Write(data)
+}
+
+func (g *GIFWriter) writeUint16(value uint16) {
+	var buf [2]byte
+	binary.LittleEndian.PutUint16(buf[:], value)
+	g.write(buf[:])
+}
+
+// gifBlockWriter splits the LZW stream into the 255 byte sub-blocks GIF requires.
+type gifBlockWriter struct {
+	w   *bufio.Writer
+	buf [256]byte
+	n   int
+}
+
+func (b *gifBlockWriter) Write(data []byte) (int, error) {
+	written := 0
+	for len(data) > 0 {
+		copied := copy(b.buf[1+b.n:], data)
+		b.n += copied
+		written += copied
+		data = data[copied:]
+		if b.n == 255 {
+			if err := b.flush(); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+func (b *gifBlockWriter) flush() error {
+	if b.n == 0 {
+		return nil
+	}
+	b.buf[0] = byte(b.n)
+	_, err := b.w.Write(b.buf[:1+b.n])
+	b.n = 0
+	return err
+}
+
+func (b *gifBlockWriter) close() error {
+	if err := b.flush(); err != nil {
+		return err
+	}
+	return b.w.WriteByte(0x00)
+}
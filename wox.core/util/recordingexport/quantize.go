@@ -0,0 +1,196 @@
+package recordingexport
+
+import (
+	"image"
+	"image/color"
+	"slices"
+)
+
+// quantizeBits is the per channel precision of the color histogram, 5 bits
+// gives 32768 buckets which is enough for UI recordings and keeps the
+// nearest-color cache small.
+const quantizeBits = 5
+
+const quantizeBuckets = 1 << (3 * quantizeBits)
+
+type quantizeBucket struct {
+	key   int
+	count int
+	r     int
+	g     int
+	b     int
+}
+
+// Quantize converts one frame to a paletted image with its own median cut
+// palette and Floyd-Steinberg dithering. A per-frame palette is what makes
+// GIFs of UI recordings look close to the source, one shared palette washes
+// out everything that only shows up in a few frames.
+func Quantize(frame *image.RGBA) *image.Paletted {
+	bounds := frame.Bounds()
+	histogram := make([]quantizeBucket, quantizeBuckets)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		offset := frame.PixOffset(bounds.Min.X, y)
+		for x := 0; x < bounds.Dx(); x++ {
+			r, g, b := int(frame.Pix[offset]), int(frame.Pix[offset+1]), int(frame.Pix[offset+2])
+			bucket := &histogram[quantizeKey(r, g, b)]
+			bucket.count++
+			bucket.r += r
+			bucket.g += g
+			bucket.b += b
+			offset += 4
+		}
+	}
+
+	buckets := make([]quantizeBucket, 0, 1024)
+	for key, bucket := range histogram {
+		if bucket.count > 0 {
+			bucket.key = key
+			buckets = append(buckets, bucket)
+		}
+	}
+	palette := medianCutPalette(buckets, 256)
+
+	paletted := image.NewPaletted(bounds, palette)
+	ditherFrame(paletted, frame)
+	return paletted
+}
+
+func quantizeKey(r, g, b int) int {
+	shift := 8 - quantizeBits
+	return (r>>shift)<<(2*quantizeBits) | (g>>shift)<<quantizeBits | b>>shift
+}
+
+// medianCutPalette splits the histogram boxes on their widest channel at the
+// pixel weighted median until there are maxColors boxes.
+func medianCutPalette(buckets []quantizeBucket, maxColors int) color.Palette {
+	if len(buckets) == 0 {
+		return color.Palette{color.RGBA{A: 0xff}}
+	}
+
+	boxes := [][]quantizeBucket{buckets}
+	for len(boxes) < maxColors {
+		splitIndex, splitChannel, widest := -1, 0, 0
+		for i, box := range boxes {
+			if len(box) < 2 {
+				continue
+			}
+			channel, width := quantizeBoxWidestChannel(box)
+			if width > widest {
+				splitIndex, splitChannel, widest = i, channel, width
+			}
+		}
+		if splitIndex < 0 {
+			break
+		}
+
+		box := boxes[splitIndex]
+		slices.SortFunc(box, func(a, b quantizeBucket) int {
+			return quantizeBucketChannel(a, splitChannel) - quantizeBucketChannel(b, splitChannel)
+		})
+		total := 0
+		for _, bucket := range box {
+			total += bucket.count
+		}
+		median, seen := len(box)-1, 0
+		for i, bucket := range box[:len(box)-1] {
+			seen += bucket.count
+			if seen*2 >= total {
+				median = i + 1
+				break
+			}
+		}
+		boxes[splitIndex] = box[:median]
+		boxes = append(boxes, box[median:])
+	}
+
+	palette := make(color.Palette, 0, len(boxes))
+	for _, box := range boxes {
+		count, r, g, b := 0, 0, 0, 0
+		for _, bucket := range box {
+			count += bucket.count
+			r += bucket.r
+			g += bucket.g
+			b += bucket.b
+		}
+		palette = append(palette, color.RGBA{R: uint8(r / count), G: uint8(g / count), B: uint8(b / count), A: 0xff})
+	}
+	return palette
+}
+
+func quantizeBoxWidestChannel(box []quantizeBucket) (int, int) {
+	widestChannel, widest := 0, -1
+	for channel := 0; channel < 3; channel++ {
+		low, high := 1<<quantizeBits, -1
+		for _, bucket := range box {
+			value := quantizeBucketChannel(bucket, channel)
+			low = min(low, value)
+			high = max(high, value)
+		}
+		if high-low > widest {
+			widestChannel, widest = channel, high-low
+		}
+	}
+	return widestChannel, widest
+}
+
+func quantizeBucketChannel(bucket quantizeBucket, channel int) int {
+	return (bucket.key >> ((2 - channel) * quantizeBits)) & (1<<quantizeBits - 1)
+}
+
+// ditherFrame maps frame into target with Floyd-Steinberg error diffusion.
+// draw.FloydSteinberg does a linear palette search per pixel, which is far too
+// slow for full recordings, so nearest colors are cached per histogram bucket.
+func ditherFrame(target *image.Paletted, frame *image.RGBA) {
+	bounds := frame.Bounds()
+	width := bounds.Dx()
+	palette := make([][3]int, len(target.Palette))
+	for i, c := range target.Palette {
+		r, g, b, _ := c.RGBA()
+		palette[i] = [3]int{int(r >> 8), int(g >> 8), int(b >> 8)}
+	}
+	cache := make([]int16, quantizeBuckets)
+	for i := range cache {
+		cache[i] = -1
+	}
+	nearest := func(r, g, b int) int {
+		key := quantizeKey(r, g, b)
+		if cached := cache[key]; cached >= 0 {
+			return int(cached)
+		}
+		best, bestDistance := 0, 1<<30
+		for i, c := range palette {
+			dr, dg, db := r-c[0], g-c[1], b-c[2]
+			if distance := dr*dr + dg*dg + db*db; distance < bestDistance {
+				best, bestDistance = i, distance
+			}
+		}
+		cache[key] = int16(best)
+		return best
+	}
+
+	// errors are stored in 1/16 units for the current and the next row
+	current := make([][3]int, width+2)
+	next := make([][3]int, width+2)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		offset := frame.PixOffset(bounds.Min.X, y)
+		targetOffset := target.PixOffset(bounds.Min.X, y)
+		for x := 0; x < width; x++ {
+			var value [3]int
+			for channel := 0; channel < 3; channel++ {
+				value[channel] = min(255, max(0, int(frame.Pix[offset+channel])+current[x+1][channel]/16))
+			}
+			index := nearest(value[0], value[1], value[2])
+			target.Pix[targetOffset+x] = uint8(index)
+			for channel := 0; channel < 3; channel++ {
+				diff := value[channel] - palette[index][channel]
+				current[x+2][channel] += diff * 7
+				next[x][channel] += diff * 3
+				next[x+1][channel] += diff * 5
+				next[x+2][channel] += diff
+			}
+			offset += 4
+		}
+		current, next = next, current
+		clear(next)
+	}
+}
@@ -5,6 +5,9 @@ package window
 import (
 	"errors"
 	"image"
+	"os/exec"
+	"strconv"
+	"strings"
 )
 
 func GetActiveWindowIcon() (image.Image, error) {
@@ -74,10 +77,48 @@ func IsProcessIdentityRunning(identity string) bool {
 	return false
 }
 
+// ActivateWindowByPid raises the first top-level window owned by pid. There is no
+// portable activation API on Linux, so this goes through wmctrl (EWMH) and falls
+// back to xdotool; both only reach X11 and XWayland windows.
 func ActivateWindowByPid(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+
+	if output, err := exec.Command("wmctrl", "-lp").Output(); err == nil {
+		for _, windowId := range findWmctrlWindowIdsByPid(string(output), pid) {
+			if exec.Command("wmctrl", "-ia", windowId).Run() == nil {
+				return true
+			}
+		}
+	}
+
+	output, err := exec.Command("xdotool", "search", "--onlyvisible", "--pid", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return false
+	}
+	for _, windowId := range strings.Fields(string(output)) {
+		if exec.Command("xdotool", "windowactivate", windowId).Run() == nil {
+			return true
+		}
+	}
 	return false
 }
 
+// findWmctrlWindowIdsByPid parses `wmctrl -lp` lines ("<id> <desktop> <pid> <host> <title>").
+func findWmctrlWindowIdsByPid(output string, pid int) []string {
+	pidText := strconv.Itoa(pid)
+	windowIds := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[2] != pidText {
+			continue
+		}
+		windowIds = append(windowIds, fields[0])
+	}
+	return windowIds
+}
+
 // ActivateWindow is not implemented on Linux yet.
 func ActivateWindow(managedWindow ManagedWindow) bool {
 	return false
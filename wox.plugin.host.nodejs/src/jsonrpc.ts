@@ -127,6 +127,8 @@ export async function handleRequestFromWox(ctx: Context, request: PluginJsonRpcR
       return onLLMStream(ctx, request)
     case "onMRURestore":
       return onMRURestore(ctx, request)
+    case "onHotkey":
+      return onHotkey(ctx, request)
     default:
       logger.info(ctx, `unknown method handler: ${request.Method}`)
       throw new Error(`unknown method handler: ${request.Method}`)
@@ -252,6 +254,17 @@ async function onUnload(ctx: Context, request: PluginJsonRpcRequest) {
   await plugin.API.unloadCallbacks.get(callbackId)?.(ctx)
 }
 
+async function onHotkey(ctx: Context, request: PluginJsonRpcRequest) {
+  const plugin = pluginInstances.get(request.PluginId)
+  if (plugin === undefined || plugin === null) {
+    logger.error(ctx, `plugin not found: ${request.PluginName}, forget to load plugin?`)
+    throw new Error(`plugin not found: ${request.PluginName}, forget to load plugin?`)
+  }
+
+  const callbackId = request.Params.CallbackId
+  await plugin.API.hotkeyCallbacks.get(callbackId)?.(ctx)
+}
+
 async function onEnterPluginQuery(ctx: Context, request: PluginJsonRpcRequest) {
   const plugin = pluginInstances.get(request.PluginId)
   if (plugin === undefined || plugin === null) {
@@ -4,9 +4,11 @@ import {
   CopyParams,
   MapString,
   PublicAPI,
+  PluginHotkey,
   PushAttentionRequest,
   Query,
   RefreshQueryParam,
+  RegisterHotkeyResult,
   Result,
   ResultAction,
   ScreenshotOption,
@@ -38,6 +40,7 @@ export class PluginAPI implements PublicAPI {
   leavePluginQueryCallbacks: Map<string, (ctx: Context) => Promise<void> | void>
   llmStreamCallbacks: Map<string, AI.ChatStreamFunc>
   mruRestoreCallbacks: Map<string, (ctx: Context, mruData: MRUData) => Promise<Result | null>>
+  hotkeyCallbacks: Map<string, (ctx: Context) => Promise<void> | void>
 
   constructor(ws: WebSocket, pluginId: string, pluginName: string) {
     this.ws = ws
@@ -51,6 +54,7 @@ export class PluginAPI implements PublicAPI {
     this.leavePluginQueryCallbacks = new Map<string, (ctx: Context) => Promise<void> | void>()
     this.llmStreamCallbacks = new Map<string, AI.ChatStreamFunc>()
     this.mruRestoreCallbacks = new Map<string, (ctx: Context, mruData: MRUData) => Promise<Result | null>>()
+    this.hotkeyCallbacks = new Map<string, (ctx: Context) => Promise<void> | void>()
   }
 
   async invokeMethod(ctx: Context, method: string, params: { [key: string]: string }): Promise<unknown> {
@@ -327,4 +331,25 @@ export class PluginAPI implements PublicAPI {
       option: JSON.stringify(option)
     })) as ScreenshotResult
   }
+
+  async RegisterHotkey(ctx: Context, hotkey: PluginHotkey): Promise<RegisterHotkeyResult> {
+    // Hotkey ids are unique per plugin, so the id doubles as the callback id and
+    // re-registering a hotkey replaces its callback.
+    this.hotkeyCallbacks.set(hotkey.Id, hotkey.Callback)
+    const result = ((await this.invokeMethod(ctx, "RegisterHotkey", {
+      callbackId: hotkey.Id,
+      hotkey: JSON.stringify({ Id: hotkey.Id, Name: hotkey.Name, Hotkey: hotkey.Hotkey })
+    })) as RegisterHotkeyResult | undefined) ?? { Success: false, Hotkey: "", ErrMsg: "invalid RegisterHotkey response" }
+    // A conflicting hotkey stays registered so the user can rebind it, only an
+    // outright rejection drops the callback.
+    if (!result.Success && !result.Hotkey) {
+      this.hotkeyCallbacks.delete(hotkey.Id)
+    }
+    return result
+  }
+
+  async UnregisterHotkey(ctx: Context, id: string): Promise<void> {
+    this.hotkeyCallbacks.delete(id)
+    await this.invokeMethod(ctx, "UnregisterHotkey", { id })
+  }
 }
@@ -83,6 +83,8 @@ async def handle_request_from_wox(ctx: Context, request: Dict[str, Any], ws: web
         return await on_mru_restore(ctx, request)
     elif method == "onLLMStream":
         return await on_llm_stream(ctx, request)
+    elif method == "onHotkey":
+        return await on_hotkey(ctx, request)
     else:
         await logger.info(ctx.get_trace_id(), f"unknown method handler: {method}")
         raise Exception(f"unknown method handler: {method}")
@@ -719,6 +721,44 @@ async def on_unload(ctx: Context, request: Dict[str, Any]) -> None:
         raise e
 
 
+async def on_hotkey(ctx: Context, request: Dict[str, Any]) -> None:
+    """Handle hotkey callback"""
+    plugin_id = request.get("PluginId")
+    if not plugin_id:
+        raise Exception("PluginId is required")
+
+    params = request.get("Params", {})
+    callback_id = params.get("CallbackId")
+
+    if not callback_id:
+        raise Exception("CallbackId is required")
+
+    plugin_instance = plugin_instances.get(plugin_id)
+    if not plugin_instance:
+        raise Exception(f"plugin instance not found: {plugin_id}")
+
+    if not plugin_instance.api:
+        raise Exception(f"plugin API not found: {plugin_id}")
+
+    from .plugin_api import PluginAPI
+
+    api = plugin_instance.api
+    if not isinstance(api, PluginAPI):
+        raise Exception(f"Invalid API type for plugin: {plugin_id}")
+
+    callback = api.hotkey_callbacks.get(callback_id)
+    if not callback:
+        raise Exception(f"hotkey callback not found: {callback_id}")
+
+    try:
+        result = callback(ctx)
+        if inspect.isawaitable(result):
+            await result
+    except Exception as e:
+        await logger.error(ctx.get_trace_id(), f"hotkey callback error: {str(e)}")
+        raise e
+
+
 async def on_enter_plugin_query(ctx: Context, request: Dict[str, Any]) -> None:
     plugin_id = request.get("PluginId")
     if not plugin_id:
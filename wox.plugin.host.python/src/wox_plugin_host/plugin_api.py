@@ -14,10 +14,12 @@ from wox_plugin import (
     LogLevel,
     MetadataCommand,
     MRUData,
+    PluginHotkey,
     PluginSettingDefinitionItem,
     PublicAPI,
     Query,
     RefreshQueryParam,
+    RegisterHotkeyResult,
     Result,
     ResultActionType,
     ScreenshotOption,
@@ -49,6 +51,7 @@ class PluginAPI(PublicAPI):
         self.leave_plugin_query_callbacks: Dict[str, Callable[[Context], Awaitable[None] | None]] = {}
         self.llm_stream_callbacks: Dict[str, ChatStreamCallback] = {}
         self.mru_restore_callbacks: Dict[str, Callable[[Context, MRUData], Optional[Result] | Awaitable[Optional[Result]]]] = {}
+        self.hotkey_callbacks: Dict[str, Callable[[Context], Awaitable[None] | None]] = {}
 
     async def invoke_method(self, ctx: Context, method: str, params: Dict[str, Any]) -> Any:
         """Invoke a method on Wox"""
@@ -398,3 +401,34 @@ class PluginAPI(PublicAPI):
             screenshot_path=str(response.get("ScreenshotPath", "") or ""),
             errmsg=str(response.get("ErrMsg", "") or ""),
         )
+
+    async def register_hotkey(self, ctx: Context, hotkey: PluginHotkey) -> RegisterHotkeyResult:
+        """Register a global hotkey"""
+        # Hotkey ids are unique per plugin, so the id doubles as the callback id and
+        # re-registering a hotkey replaces its callback.
+        self.hotkey_callbacks[hotkey.id] = hotkey.callback
+        response = await self.invoke_method(
+            ctx,
+            "RegisterHotkey",
+            {"callbackId": hotkey.id, "hotkey": json.dumps(hotkey.to_dict())},
+        )
+
+        if not isinstance(response, dict):
+            self.hotkey_callbacks.pop(hotkey.id, None)
+            return RegisterHotkeyResult(success=False, errmsg="invalid RegisterHotkey response")
+
+        result = RegisterHotkeyResult(
+            success=bool(response.get("Success", False)),
+            hotkey=str(response.get("Hotkey", "") or ""),
+            errmsg=str(response.get("ErrMsg", "") or ""),
+        )
+        # A conflicting hotkey stays registered so the user can rebind it, only an
+        # outright rejection drops the callback.
+        if not result.success and not result.hotkey:
+            self.hotkey_callbacks.pop(hotkey.id, None)
+        return result
+
+    async def unregister_hotkey(self, ctx: Context, id: str) -> None:
+        """Release a global hotkey"""
+        self.hotkey_callbacks.pop(id, None)
+        await self.invoke_method(ctx, "UnregisterHotkey", {"id": id})
@@ -1374,6 +1374,36 @@ export interface ScreenshotResult {
   ErrMsg: string
 }
 
+/**
+ * A global hotkey owned by the plugin.
+ * Hotkey is the default binding, users can rebind or disable it in the plugin settings.
+ * Requires the `globalHotkey` permission in plugin.json.
+ */
+export interface PluginHotkey {
+  /**
+   * Unique id within the plugin, registering an existing id replaces it.
+   */
+  Id: string
+  Name: string
+  /**
+   * Default hotkey, e.g. "ctrl+alt+t" or a sequence like "ctrl+space, t".
+   */
+  Hotkey: string
+  Callback: (ctx: Context) => Promise<void> | void
+}
+
+/**
+ * Result of registering a plugin hotkey.
+ * Hotkey is the binding in effect after user rebinds, empty when the user disabled it.
+ * A hotkey that conflicts with another binding returns Success false but stays listed
+ * in the plugin settings, so the user can pick another one.
+ */
+export interface RegisterHotkeyResult {
+  Success: boolean
+  Hotkey: string
+  ErrMsg: string
+}
+
 /**
  * Controls how a plugin setting is persisted.
  * Requires Wox >= 2.4.0.
@@ -1645,6 +1675,20 @@ export interface PublicAPI {
    * @param option Screenshot options
    */
   Screenshot: (ctx: Context, option: ScreenshotOption) => Promise<ScreenshotResult>
+
+  /**
+   * Register a global hotkey, released automatically when the plugin is unloaded.
+   * @param ctx Context
+   * @param hotkey Hotkey with its default binding and callback
+   */
+  RegisterHotkey: (ctx: Context, hotkey: PluginHotkey) => Promise<RegisterHotkeyResult>
+
+  /**
+   * Release a hotkey registered by RegisterHotkey.
+   * @param ctx Context
+   * @param id Hotkey id
+   */
+  UnregisterHotkey: (ctx: Context, id: string) => Promise<void>
 }
 
 /**
@@ -79,6 +79,8 @@ Methods for interacting with Wox:
 - `CopyParams`: Parameters for clipboard operations
 - `ScreenshotOption`: Options for the screenshot workflow
 - `ScreenshotResult`: Result returned by the screenshot workflow
+- `PluginHotkey`: Global hotkey registered by the plugin
+- `RegisterHotkeyResult`: Result returned by hotkey registration
 
 #### Result Models (`models/result.py`)
 - `Result`: Search result with title, icon, preview, actions
@@ -219,7 +221,7 @@ settings = [
 
 from typing import List
 
-from .api import ChatStreamCallback, PublicAPI, ScreenshotOption, ScreenshotResult, SetSettingOption, SetSettingResult
+from .api import ChatStreamCallback, PluginHotkey, PublicAPI, RegisterHotkeyResult, ScreenshotOption, ScreenshotResult, SetSettingOption, SetSettingResult
 from .models.ai import (
     AIModel,
     ChatStreamData,
@@ -297,6 +299,8 @@ __all__: List[str] = [
     "ChatStreamCallback",
     "ScreenshotOption",
     "ScreenshotResult",
+    "PluginHotkey",
+    "RegisterHotkeyResult",
     "SetSettingOption",
     "SetSettingResult",
     "PushAttentionRequest",
@@ -87,6 +87,47 @@ class SetSettingResult:
     errmsg: str = ""
 
 
+@dataclass
+class PluginHotkey:
+    """
+    A global hotkey owned by the plugin.
+
+    hotkey is the default binding, e.g. "ctrl+alt+t" or a sequence like
+    "ctrl+space, t". Users can rebind or disable it in the plugin settings.
+    Requires the globalHotkey permission in plugin.json.
+    """
+
+    id: str
+    name: str
+    hotkey: str
+    callback: Callable[[Context], Awaitable[None] | None]
+
+    def to_dict(self) -> Dict[str, str]:
+        """Convert Pythonic field names to the public API JSON fields expected by Wox core."""
+
+        return {
+            "Id": self.id,
+            "Name": self.name,
+            "Hotkey": self.hotkey,
+        }
+
+
+@dataclass
+class RegisterHotkeyResult:
+    """
+    Result of registering a plugin hotkey.
+
+    hotkey is the binding in effect after user rebinds, empty when the user
+    disabled it. A hotkey that conflicts with another binding returns
+    success False but stays listed in the plugin settings, so the user can
+    pick another one.
+    """
+
+    success: bool = False
+    hotkey: str = ""
+    errmsg: str = ""
+
+
 class PublicAPI(Protocol):
     """
     Public API interface for Wox plugins.
@@ -108,6 +149,7 @@ class PublicAPI(Protocol):
         - Commands: register_query_commands
         - Clipboard: copy
         - Screenshot: screenshot
+        - Hotkeys: register_hotkey, unregister_hotkey
 
     Example:
         class MyPlugin:
@@ -749,3 +791,28 @@ class PublicAPI(Protocol):
             ScreenshotResult: success state, saved PNG path, and error message
         """
         ...
+
+    async def register_hotkey(self, ctx: Context, hotkey: PluginHotkey) -> RegisterHotkeyResult:
+        """
+        Register a global hotkey, released automatically when the plugin is unloaded.
+
+        Registering an existing id replaces it.
+
+        Args:
+            ctx: Context
+            hotkey: Hotkey with its default binding and callback
+
+        Returns:
+            RegisterHotkeyResult: success state, effective hotkey, and error message
+        """
+        ...
+
+    async def unregister_hotkey(self, ctx: Context, id: str) -> None:
+        """
+        Release a hotkey registered by register_hotkey.
+
+        Args:
+            ctx: Context
+            id: Hotkey id
+        """
+        ...